@@ -1,29 +1,222 @@
 package main
 
 import (
-	//"fmt"
+	"cmp"
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"io"
 	"log/slog"
+	"maps"
+	"net"
 	"net/http"
 	"os"
+	"os/signal"
+	"slices"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"syscall"
+	"time"
+	"url-shortener/internal/backup"
+	"url-shortener/internal/clickstream"
 	"url-shortener/internal/config"
+	"url-shortener/internal/diagnostics"
+	"url-shortener/internal/errreport"
+	"url-shortener/internal/errreport/sentry"
+	"url-shortener/internal/eventbus"
+	"url-shortener/internal/grpc-server/interceptor"
+	"url-shortener/internal/grpc-server/shortener"
+	"url-shortener/internal/http-server/handlers/admin"
+	auditList "url-shortener/internal/http-server/handlers/audit/list"
+	"url-shortener/internal/http-server/handlers/auth/login"
+	"url-shortener/internal/http-server/handlers/auth/oidccallback"
+	"url-shortener/internal/http-server/handlers/auth/oidclogin"
+	backupCreate "url-shortener/internal/http-server/handlers/backup/create"
+	backupDownload "url-shortener/internal/http-server/handlers/backup/download"
+	backupList "url-shortener/internal/http-server/handlers/backup/list"
+	backupRestore "url-shortener/internal/http-server/handlers/backup/restore"
+	campaignsCreate "url-shortener/internal/http-server/handlers/campaigns/create"
+	campaignsDelete "url-shortener/internal/http-server/handlers/campaigns/delete"
+	campaignsGet "url-shortener/internal/http-server/handlers/campaigns/get"
+	campaignsList "url-shortener/internal/http-server/handlers/campaigns/list"
+	campaignsStats "url-shortener/internal/http-server/handlers/campaigns/stats"
+	campaignsUpdate "url-shortener/internal/http-server/handlers/campaigns/update"
+	"url-shortener/internal/http-server/handlers/docs/spec"
+	"url-shortener/internal/http-server/handlers/docs/ui"
+	"url-shortener/internal/http-server/handlers/health/live"
+	"url-shortener/internal/http-server/handlers/health/ready"
+	keysCreate "url-shortener/internal/http-server/handlers/keys/create"
+	keysList "url-shortener/internal/http-server/handlers/keys/list"
+	keysRevoke "url-shortener/internal/http-server/handlers/keys/revoke"
+	"url-shortener/internal/http-server/handlers/quota/usage"
+	"url-shortener/internal/http-server/handlers/url/access"
+	"url-shortener/internal/http-server/handlers/url/available"
+	"url-shortener/internal/http-server/handlers/url/batch"
+	"url-shortener/internal/http-server/handlers/url/block"
+	"url-shortener/internal/http-server/handlers/url/csvexport"
+	"url-shortener/internal/http-server/handlers/url/csvimport"
+	"url-shortener/internal/http-server/handlers/url/delete"
+	"url-shortener/internal/http-server/handlers/url/events"
+	"url-shortener/internal/http-server/handlers/url/expand"
+	"url-shortener/internal/http-server/handlers/url/feed"
+	"url-shortener/internal/http-server/handlers/url/geo"
+	"url-shortener/internal/http-server/handlers/url/list"
+	"url-shortener/internal/http-server/handlers/url/preview"
+	"url-shortener/internal/http-server/handlers/url/qr"
 	"url-shortener/internal/http-server/handlers/url/redirect"
+	"url-shortener/internal/http-server/handlers/url/referrers"
+	"url-shortener/internal/http-server/handlers/url/rename"
+	"url-shortener/internal/http-server/handlers/url/restore"
 	"url-shortener/internal/http-server/handlers/url/save"
+	"url-shortener/internal/http-server/handlers/url/stats"
+	"url-shortener/internal/http-server/handlers/url/statsexport"
+	"url-shortener/internal/http-server/handlers/url/suggest"
+	"url-shortener/internal/http-server/handlers/url/update"
+	usersCreate "url-shortener/internal/http-server/handlers/users/create"
+	usersErase "url-shortener/internal/http-server/handlers/users/erase"
+	"url-shortener/internal/http-server/handlers/wellknown/favicon"
+	"url-shortener/internal/http-server/handlers/wellknown/robots"
+	mwAccessLog "url-shortener/internal/http-server/middleware/accesslog"
+	mwAnonymous "url-shortener/internal/http-server/middleware/anonymous"
+	mwAudit "url-shortener/internal/http-server/middleware/audit"
+	mwAuth "url-shortener/internal/http-server/middleware/auth"
+	mwBodyLimit "url-shortener/internal/http-server/middleware/bodylimit"
+	mwCompress "url-shortener/internal/http-server/middleware/compress"
+	mwDeprecation "url-shortener/internal/http-server/middleware/deprecation"
+	mwIdempotency "url-shortener/internal/http-server/middleware/idempotency"
 	mwLogger "url-shortener/internal/http-server/middleware/logger"
+	mwMetrics "url-shortener/internal/http-server/middleware/metrics"
+	mwRateLimit "url-shortener/internal/http-server/middleware/ratelimit"
+	mwTracing "url-shortener/internal/http-server/middleware/tracing"
+	mwWellKnown "url-shortener/internal/http-server/middleware/wellknown"
+	"url-shortener/internal/http-server/openapi"
+	"url-shortener/internal/lib/alias"
+	"url-shortener/internal/lib/botdetect"
+	"url-shortener/internal/lib/captcha"
+	"url-shortener/internal/lib/domain"
+	"url-shortener/internal/lib/encrypt"
+	"url-shortener/internal/lib/errorpage"
+	"url-shortener/internal/lib/geoip"
+	"url-shortener/internal/lib/jwt"
+	"url-shortener/internal/lib/listener"
 	"url-shortener/internal/lib/logger/handlers/slogpretty"
+	"url-shortener/internal/lib/logger/rotate"
 	"url-shortener/internal/lib/logger/sl"
+	"url-shortener/internal/lib/metadata"
+	"url-shortener/internal/lib/privacy"
+	"url-shortener/internal/lib/quota"
+	"url-shortener/internal/lib/ratelimit"
+	"url-shortener/internal/lib/reputation"
+	"url-shortener/internal/lib/shorturl"
+	"url-shortener/internal/linkcheck"
+	"url-shortener/internal/metrics"
+	"url-shortener/internal/oidc"
+	"url-shortener/internal/reaper"
+	"url-shortener/internal/scanner"
+	"url-shortener/internal/scheduler"
+	"url-shortener/internal/storage/anonymized"
+	"url-shortener/internal/storage/audited"
+	"url-shortener/internal/storage/cached"
+	"url-shortener/internal/storage/casefolded"
+	"url-shortener/internal/storage/encrypted"
+	"url-shortener/internal/storage/instrumented"
+	"url-shortener/internal/storage/memory"
+	"url-shortener/internal/storage/notifying"
+	"url-shortener/internal/storage/postgres"
+	"url-shortener/internal/storage/redis"
+	"url-shortener/internal/storage/rediscache"
+	"url-shortener/internal/storage/signed"
 	"url-shortener/internal/storage/sqlite"
+	"url-shortener/internal/storage/traced"
+	"url-shortener/internal/telegram"
+	"url-shortener/internal/tracing"
+	"url-shortener/internal/users"
+	"url-shortener/internal/webhook"
 
 	"github.com/go-chi/chi/v5"
 	"github.com/go-chi/chi/v5/middleware"
+	"github.com/go-chi/cors"
 	"github.com/joho/godotenv"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"golang.org/x/crypto/acme/autocert"
+	"golang.org/x/crypto/bcrypt"
+	"google.golang.org/grpc"
 )
 
+// Storage is everything the HTTP handlers need from a storage backend.
+type Storage interface {
+	save.URLSaver
+	batch.URLsSaver
+	redirect.URLGetter
+	redirect.ClickSaver
+	redirect.ClickLimiter
+	update.URLUpdater
+	delete.URLDeleter
+	restore.URLRestorer
+	rename.URLRenamer
+	block.URLBlocker
+	list.URLLister
+	stats.StatsGetter
+	access.OwnerGetter
+	referrers.ReferrersGetter
+	geo.CountriesGetter
+	statsexport.ClickLister
+	available.AliasChecker
+	reaper.ExpiredDeleter
+	scanner.URLStore
+	linkcheck.URLStore
+	mwAuth.KeyGetter
+	keysCreate.KeySaver
+	keysList.KeyLister
+	keysRevoke.KeyRevoker
+	campaignsCreate.CampaignSaver
+	campaignsList.CampaignLister
+	campaignsUpdate.CampaignUpdater
+	campaignsDelete.CampaignDeleter
+	campaignsStats.Storage
+	users.UserGetter
+	usersCreate.UserSaver
+	usersErase.UserDataDeleter
+	ready.Pinger
+	alias.IDSource
+	quota.Counter
+	audited.EntrySaver
+	auditList.AuditLister
+	mwIdempotency.Store
+	io.Closer
+}
+
+// vacuumer gives back the space of deleted rows.
+type vacuumer interface {
+	Vacuum(ctx context.Context) error
+}
+
+// clickRoller sums up the clicks of the days before the one before falls on.
+type clickRoller interface {
+	RollupClicks(ctx context.Context, before time.Time) error
+}
+
+// idempotencyPruner removes Idempotency-Key records that expired by now.
+type idempotencyPruner interface {
+	PruneIdempotencyKeys(ctx context.Context, now time.Time) (int64, error)
+}
+
 const (
 	envLocal = "local"
 	envDev   = "dev"
 	envProd  = "prod"
 )
 
+// clickStreamBuffer is how many clicks a live click stream can fall behind
+// by before it starts losing them.
+const clickStreamBuffer = 64
+
+// apiVersionedAt is when the API moved under openapi.Prefix; its routes at
+// the root are deprecated since then.
+var apiVersionedAt = time.Date(2026, time.October, 16, 0, 0, 0, 0, time.UTC)
+
 func main() {
 
 	err := godotenv.Load()
@@ -33,93 +226,1132 @@ func main() {
 
 	cfg := config.MustLoad()
 
-	log := setupLogger(cfg.Env)
+	log, closeLog, err := setupLogger(cfg)
+	if err != nil {
+		slog.Error("failed to set up logging", sl.Err(err))
+		os.Exit(1)
+	}
+	defer closeLog()
+
+	reporter, err := setupErrorReport(log, cfg)
+	if err != nil {
+		log.Error("failed to init error reporting", sl.Err(err))
+		os.Exit(1)
+	}
+	if reporter != nil {
+		// The reporter itself logs with the plain logger, so its own
+		// failures are not reported in a loop.
+		log = slog.New(errreport.NewHandler(log.Handler(), reporter))
+	}
+
+	anonymizer, err := setupPrivacy(cfg.Privacy)
+	if err != nil {
+		log.Error("failed to init privacy mode", sl.Err(err))
+		os.Exit(1)
+	}
+	if anonymizer != nil {
+		// Outermost, so error reports get the anonymized attributes too.
+		log = slog.New(anonymizer.Handler(log.Handler()))
+	}
 
 	log.Info(
 		"starting url-shortener",
 		slog.String("env", cfg.Env),
 		slog.String("my_pet", "url-shortener"),
 	)
-	log.Debug("debage messages are enebled")
-	log.Error("Error message are enebled")
 
-	storage, err := sqlite.New(cfg.StoragePath)
+	storage, err := setupStorage(cfg)
 	if err != nil {
 		log.Error("failed to init storage", sl.Err(err))
 		os.Exit(1)
 	}
 
-	_ = storage
+	var signer *alias.Signer
+	if cfg.Alias.Signing.Key != "" {
+		signer, err = alias.NewSigner([]byte(cfg.Alias.Signing.Key), cfg.Alias.Signing.Length, cfg.Alias.CaseInsensitive)
+		if err != nil {
+			log.Error("invalid alias signing settings", sl.Err(err))
+			os.Exit(1)
+		}
+	}
+
+	aliases, err := alias.New(alias.Rules{
+		MinLength: cfg.Alias.MinLength,
+		MaxLength: cfg.Alias.MaxLength,
+		Pattern:   cfg.Alias.Pattern,
+		Reserved:  slices.Concat(alias.Routes, cfg.Alias.Reserved),
+		Signer:    signer,
+	})
+	if err != nil {
+		log.Error("invalid alias rules", sl.Err(err))
+		os.Exit(1)
+	}
+
+	links, err := shorturl.New(cfg.BaseURL, cfg.ShortDomains)
+	if err != nil {
+		log.Error("invalid base url or short domains", sl.Err(err))
+		os.Exit(1)
+	}
+
+	// Links may not point back at the service. Without a base URL or short
+	// domains short links are made from the Host of the request, so that
+	// is the service too.
+	self := slices.Concat(links.Hosts(), cfg.TLS.Domains)
+	domains, err := domain.New(domain.Rules{
+		Allow:           cfg.Domains.Allow,
+		Block:           cfg.Domains.Block,
+		Self:            self,
+		SelfRequestHost: cfg.BaseURL == "" && len(cfg.ShortDomains) == 0,
+	})
+	if err != nil {
+		log.Error("invalid domain rules", sl.Err(err))
+		os.Exit(1)
+	}
+
+	// Maintenance jobs work on the backend itself, past every decorator,
+	// and only on backends that need them.
+	dbVacuumer, _ := storage.(vacuumer)
+	keyPruner, _ := storage.(idempotencyPruner)
+	roller, _ := storage.(clickRoller)
+
+	// Snapshots are taken of the backend itself, past every decorator.
+	snapshotter, canBackUp := storage.(backup.Snapshotter)
+	if cfg.Backup.Dir != "" && !canBackUp {
+		log.Error("storage does not support backups", slog.String("type", cfg.Storage.Type))
+		os.Exit(1)
+	}
+
+	m := metrics.New()
+	storage = instrumented.New(storage, m)
+
+	var tracerProvider *sdktrace.TracerProvider
+	if cfg.Tracing.Enabled {
+		tracerProvider, err = tracing.New(context.Background(), tracing.Options{
+			Endpoint:    cfg.Tracing.Endpoint,
+			Insecure:    cfg.Tracing.Insecure,
+			ServiceName: cfg.Tracing.ServiceName,
+			SampleRatio: cfg.Tracing.SampleRatio,
+		})
+		if err != nil {
+			log.Error("failed to set up tracing", sl.Err(err))
+			os.Exit(1)
+		}
+		storage = traced.New(storage, tracerProvider.Tracer("url-shortener/storage"))
+	}
+	var caches []backup.Flusher
+	if cfg.Cache.RedisAddr != "" {
+		redisCache, err := rediscache.New(log, storage, rediscache.Options{
+			Addr:     cfg.Cache.RedisAddr,
+			Password: cfg.Cache.RedisPassword,
+			DB:       cfg.Cache.RedisDB,
+			TTL:      cfg.Cache.RedisTTL,
+		})
+		if err != nil {
+			log.Error("failed to connect to redis cache", sl.Err(err))
+			os.Exit(1)
+		}
+		storage = redisCache
+		caches = append(caches, redisCache)
+	}
+	if cfg.Cache.Size > 0 {
+		memoryCache := cached.New(storage, cfg.Cache.Size, cfg.Cache.TTL)
+		storage = memoryCache
+		caches = append(caches, memoryCache)
+	}
+
+	var backups *backup.Manager
+	if cfg.Backup.Dir != "" {
+		// A restore replaces links behind the caches' back, so they are
+		// emptied after it.
+		backups = backup.New(log, snapshotter, cfg.Backup.Dir, cfg.Backup.Keep, caches...)
+	}
+	if cfg.Encryption.Key != "" {
+		cipher, err := setupEncryption(cfg.Encryption)
+		if err != nil {
+			log.Error("failed to init encryption", sl.Err(err))
+			os.Exit(1)
+		}
+		// Above the caches, so Redis holds ciphertext too.
+		storage = encrypted.New(storage, cipher)
+	}
+
+	dispatcher, err := setupWebhooks(log, cfg.Webhooks)
+	if err != nil {
+		log.Error("invalid webhook settings", sl.Err(err))
+		os.Exit(1)
+	}
+	publisher, err := setupEventBus(log, cfg.EventBus)
+	if err != nil {
+		log.Error("failed to set up event bus", sl.Err(err))
+		os.Exit(1)
+	}
+	hub := clickstream.New(clickStreamBuffer)
+	notifiers := []notifying.Notifier{hub}
+	// The dead-link checker reports broken links itself; the hub streams
+	// clicks only.
+	var linkNotifiers []linkcheck.Notifier
+	if dispatcher != nil {
+		notifiers = append(notifiers, dispatcher)
+		linkNotifiers = append(linkNotifiers, dispatcher)
+	}
+	if publisher != nil {
+		notifiers = append(notifiers, publisher)
+		linkNotifiers = append(linkNotifiers, publisher)
+	}
+	storage = notifying.New(storage, notifiers...)
+	if anonymizer != nil {
+		// Above notifying, so webhooks and the event bus get the scrubbed
+		// referrers too.
+		storage = anonymized.New(storage, anonymizer)
+	}
+	storage = audited.New(log, storage)
+	if cfg.Alias.CaseInsensitive {
+		storage = casefolded.New(storage)
+	}
+	if signer != nil && cfg.Alias.Signing.Enforce {
+		// Outermost, so forged aliases never reach the caches or the
+		// database.
+		storage = signed.New(storage, signer)
+	}
+
+	generator, err := alias.NewGenerator(cfg.Alias.Strategy, cfg.Alias.GeneratedLength, cfg.Alias.Alphabet, storage, aliases, alias.NewFilter(alias.FilterRules{
+		BadWords:   cfg.Alias.FilterBadWords,
+		ExtraWords: cfg.Alias.BadWords,
+		Confusable: cfg.Alias.SkipConfusable,
+	}))
+	if err != nil {
+		log.Error("invalid alias generator settings", sl.Err(err))
+		os.Exit(1)
+	}
+	if signer != nil {
+		generator = alias.NewSigned(generator, signer)
+	}
+
+	var fetcher save.MetadataFetcher
+	if cfg.Metadata.Enabled {
+		fetcher = metadata.New(metadata.NewClient(cfg.Metadata.Timeout))
+	}
+
+	// Suggested aliases are words anyway, so only bad words are filtered.
+	suggester := alias.NewSuggester(aliases, alias.NewFilter(alias.FilterRules{
+		BadWords:   cfg.Alias.FilterBadWords,
+		ExtraWords: cfg.Alias.BadWords,
+	}))
+
+	checker, err := setupURLChecker(cfg.Reputation)
+	if err != nil {
+		log.Error("failed to set up url reputation checks", sl.Err(err))
+		os.Exit(1)
+	}
+
+	quotas := quota.New(storage, quota.Limits{
+		MaxLinks:  cfg.Quota.MaxLinks,
+		MaxPerDay: cfg.Quota.MaxPerDay,
+	})
+
+	var locator redirect.Locator
+	if cfg.GeoIP.Database != "" {
+		geoDB, err := geoip.Open(cfg.GeoIP.Database)
+		if err != nil {
+			log.Error("failed to open geoip database", sl.Err(err))
+			os.Exit(1)
+		}
+		defer geoDB.Close()
+		locator = geoDB
+	}
+
+	bots, err := botdetect.New(cfg.Bots.ListFile)
+	if err != nil {
+		log.Error("failed to load bot list", sl.Err(err))
+		os.Exit(1)
+	}
+
+	errorPages, err := errorpage.New(cfg.Redirect.ErrorTemplate)
+	if err != nil {
+		log.Error("failed to load error page template", sl.Err(err))
+		os.Exit(1)
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	var background sync.WaitGroup
+
+	// Errors logged while shutting down are reported too, so the reporter
+	// stops only once everything else has.
+	reportCtx, stopReporting := context.WithCancel(context.Background())
+	reportDone := make(chan struct{})
+	if reporter != nil {
+		go func() {
+			defer close(reportDone)
+			reporter.Run(reportCtx)
+		}()
+	} else {
+		close(reportDone)
+	}
+	jobs := scheduler.New(log, m)
+	jobs.Add(scheduler.Job{
+		Name:     "purge_expired",
+		Interval: cfg.Reaper.Interval,
+		Run:      reaper.New(log, storage, cfg.Reaper.DeletedGrace).Reap,
+	})
+	if dbVacuumer != nil {
+		jobs.Add(scheduler.Job{
+			Name:     "vacuum",
+			Interval: cfg.Scheduler.VacuumInterval,
+			Run:      dbVacuumer.Vacuum,
+		})
+	}
+	if keyPruner != nil && cfg.Idempotency.TTL > 0 {
+		jobs.Add(scheduler.Job{
+			Name:     "prune_idempotency_keys",
+			Interval: cfg.Scheduler.IdempotencyInterval,
+			Run: func(ctx context.Context) error {
+				n, err := keyPruner.PruneIdempotencyKeys(ctx, time.Now())
+				if n > 0 {
+					log.Info("expired idempotency keys removed", slog.Int64("count", n))
+				}
+				return err
+			},
+		})
+	}
+	if roller != nil {
+		jobs.Add(scheduler.Job{
+			Name:     "rollup_clicks",
+			Interval: cfg.Scheduler.RollupInterval,
+			Run: func(ctx context.Context) error {
+				return roller.RollupClicks(ctx, time.Now())
+			},
+		})
+	}
+	jobs.Add(scheduler.Job{
+		Name:     "check_links",
+		Interval: cfg.LinkCheck.Interval,
+		Run: linkcheck.New(log, storage, metadata.NewClient(cfg.LinkCheck.Timeout), linkcheck.Options{
+			Concurrency:  cfg.LinkCheck.Concurrency,
+			MaxRedirects: cfg.LinkCheck.MaxRedirects,
+		}, linkNotifiers...).Check,
+	})
+	background.Go(func() {
+		jobs.Run(ctx)
+	})
+	if dispatcher != nil {
+		background.Go(func() {
+			dispatcher.Run(ctx)
+		})
+	}
+	if publisher != nil {
+		background.Go(func() {
+			publisher.Run(ctx)
+		})
+	}
+	if checker != nil && cfg.Reputation.RescanInterval > 0 {
+		background.Go(func() {
+			scanner.New(log, storage, checker, cfg.Reputation.RescanInterval).Run(ctx)
+		})
+	}
+
+	if backups != nil && cfg.Backup.Interval > 0 {
+		background.Go(func() {
+			backups.Run(ctx, cfg.Backup.Interval)
+		})
+	}
+
+	accessLog, closeAccessLog, err := setupAccessLog(cfg.AccessLog)
+	if err != nil {
+		log.Error("failed to open access log", sl.Err(err))
+		os.Exit(1)
+	}
+	defer closeAccessLog()
+	if accessLog != nil && anonymizer != nil {
+		accessLog = slog.New(anonymizer.Handler(accessLog.Handler()))
+	}
 
 	router := chi.NewRouter()
 
 	router.Use(middleware.RequestID)
-	router.Use(middleware.Logger)
+	if tracerProvider != nil {
+		router.Use(mwTracing.New(log, tracerProvider, tracing.Propagator()))
+	}
+	router.Use(mwMetrics.New(log, m))
+	if anonymizer == nil {
+		// It prints the client address as is.
+		router.Use(middleware.Logger)
+	}
 	router.Use(mwLogger.New(log))
 	router.Use(middleware.Recoverer)
+	if reporter != nil && anonymizer != nil {
+		router.Use(errreport.Recoverer(anonymizedReports(reporter, anonymizer)))
+	} else if reporter != nil {
+		router.Use(errreport.Recoverer(reporter))
+	}
+	if cfg.Compression.Enabled {
+		compress, err := mwCompress.New(mwCompress.Options{
+			MinSize:      cfg.Compression.MinSize,
+			GzipLevel:    cfg.Compression.GzipLevel,
+			DeflateLevel: cfg.Compression.DeflateLevel,
+			Types:        cfg.Compression.Types,
+		})
+		if err != nil {
+			log.Error("invalid compression settings", sl.Err(err))
+			os.Exit(1)
+		}
+		router.Use(compress)
+	}
+	// CSV imports are limited to csvimport.MaxFileSize by their handler.
+	router.Use(mwBodyLimit.New(cfg.HTTPServer.MaxBodySize, "/urls/import", openapi.Prefix+"/urls/import"))
+	if len(cfg.CORS.AllowedOrigins) > 0 {
+		// Answers preflight requests before they reach auth.
+		router.Use(cors.Handler(cors.Options{
+			AllowedOrigins:   cfg.CORS.AllowedOrigins,
+			AllowedMethods:   cfg.CORS.AllowedMethods,
+			AllowedHeaders:   cfg.CORS.AllowedHeaders,
+			ExposedHeaders:   cfg.CORS.ExposedHeaders,
+			AllowCredentials: cfg.CORS.AllowCredentials,
+			MaxAge:           int(cfg.CORS.MaxAge.Seconds()),
+		}))
+	}
+	// Crawlers and browsers ask for these on their own; without built-in
+	// answers they would be looked up as aliases.
+	router.Use(mwWellKnown.New(map[string]http.Handler{
+		"/robots.txt":  robots.New(openapi.Prefix),
+		"/favicon.ico": favicon.New(),
+	}))
 	router.Use(middleware.URLFormat)
 
-	router.Route("/url", func(r chi.Router) {
-		r.Use(middleware.BasicAuth("url-shortener", map[string]string{
-			cfg.HTTPServer.User: cfg.HTTPServer.Password,	
-		}))
+	// Users from the config and the htpasswd file are built in; everyone
+	// else is stored in the users table.
+	accounts, err := setupAccounts(log, cfg.HTTPServer)
+	if err != nil {
+		log.Error("failed to load users", sl.Err(err))
+		os.Exit(1)
+	}
+	authenticator := users.New(accounts, storage)
+
+	tokens := jwt.New(cfg.JWT.Secret, cfg.JWT.TTL)
+
+	// With OIDC, ID tokens from the provider are accepted next to our own.
+	var tokenParser mwAuth.TokenParser = tokens
+	sso := setupOIDC(cfg)
+	if sso != nil {
+		tokenParser = mwAuth.TokenParsers{tokens, sso}
+	}
+	ssoKey := oidc.StateKey(cfg.JWT.Secret)
+
+	authMiddleware := mwAuth.New(log, tokenParser, storage, authenticator, cfg.Auth.BasicFallback)
+	// API keys cannot be used to manage API keys or users.
+	sessionAuthMiddleware := mwAuth.New(log, tokenParser, nil, authenticator, cfg.Auth.BasicFallback)
+
+	router.Handle("/metrics", m.Handler())
+
+	doc, err := openapi.New()
+	if err != nil {
+		log.Error("failed to build openapi spec", sl.Err(err))
+		os.Exit(1)
+	}
+	// URLFormat strips the extension, so this serves /openapi.json.
+	router.Get("/openapi", spec.New(doc))
+	if cfg.HTTPServer.SwaggerUI {
+		router.Get("/docs", ui.New("/openapi.json"))
+	}
+
+	router.Get("/admin", http.RedirectHandler("/admin/", http.StatusMovedPermanently).ServeHTTP)
+	router.Handle("/admin/*", admin.New("/admin", admin.Options{SSO: sso != nil}))
+
+	// shuttingDown fails the readiness probe once the server starts stopping.
+	var shuttingDown atomic.Bool
+	router.Get("/healthz", live.New())
+	router.Get("/readyz", ready.New(log, storage, &shuttingDown))
+
+	saveLimit := rateLimit(log, cfg.RateLimit.Save)
+	// Replays are not counted against the rate limit, and a 429 is not
+	// kept as the response to replay.
+	saveMiddlewares := slices.Clone(saveLimit)
+	if cfg.Idempotency.TTL > 0 {
+		saveMiddlewares = append(saveMiddlewares, mwIdempotency.New(log, storage, cfg.Idempotency.TTL))
+	}
+	redirectLimit := rateLimit(log, cfg.RateLimit.Redirect)
+	loginLimit := rateLimit(log, cfg.RateLimit.Login)
+
+	saveHandler := save.New(log, storage, aliases, domains, generator, fetcher, checker, quotas, links)
+
+	// Creating links needs an editor, or, with anonymous access, a solved
+	// captcha.
+	saveAuth := chi.Chain(authMiddleware, mwAudit.Actor, mwAuth.EditorOnly).Handler
+	if cfg.Anonymous.Enabled {
+		saveAuth = mwAnonymous.New(log, setupCaptcha(cfg.Anonymous.Captcha), cfg.Anonymous.LinkTTL, saveAuth,
+			append(rateLimit(log, cfg.Anonymous.RateLimit), mwAudit.Actor)...)
+	}
+
+	if cfg.Telegram.Token != "" {
+		bot := telegram.New(log, &http.Client{Timeout: cfg.Telegram.PollTimeout + 10*time.Second}, authenticator, middleware.RequestID(saveHandler), telegram.Options{
+			APIURL:      cfg.Telegram.APIURL,
+			Token:       cfg.Telegram.Token,
+			Users:       telegramUsers(cfg.Telegram.Users),
+			PollTimeout: cfg.Telegram.PollTimeout,
+		})
+		background.Go(func() {
+			bot.Run(ctx)
+		})
+	}
+
+	// api registers the JSON API; it is served under openapi.Prefix.
+	api := func(r chi.Router) {
+		r.With(loginLimit...).Post("/auth/login", login.New(log, tokens, authenticator))
+		if sso != nil {
+			r.Get("/auth/oidc/login", oidclogin.New(log, sso, ssoKey))
+			r.Get("/auth/oidc/callback", oidccallback.New(log, sso, storage, tokens, ssoKey))
+		}
+
+		r.Route("/url", func(r chi.Router) {
+			r.With(saveAuth).With(saveMiddlewares...).Post("/", saveHandler)
+
+			r.Group(func(r chi.Router) {
+				r.Use(authMiddleware)
+				r.Use(mwAudit.Actor)
+
+				// Viewers can read links and their stats but not change them.
+				edit := r.With(mwAuth.EditorOnly)
+				edit.With(saveLimit...).Post("/batch", batch.New(log, storage, aliases, domains, generator, checker, quotas))
+				r.With(mwAuth.AdminOnly).Post("/{alias}/block", block.New(log, storage))
+				edit.Patch("/{alias}", update.New(log, storage, domains))
+				edit.Delete("/{alias}", delete.New(log, storage))
+				edit.Post("/{alias}/restore", restore.New(log, storage))
+				edit.Post("/{alias}/rename", rename.New(log, storage, aliases, cfg.Redirect.RenameForward))
+				r.Get("/{alias}/stats", stats.New(log, storage, storage, links))
+				r.Get("/{alias}/stats/export", statsexport.New(log, storage))
+				r.Get("/{alias}/referrers", referrers.New(log, storage, storage))
+				r.Get("/{alias}/geo", geo.New(log, storage, storage))
+				r.Get("/{alias}/events", events.New(log, hub, storage, storage))
+				r.Get("/{alias}/qr", qr.New(log, storage))
+			})
+		})
+
+		r.Route("/urls", func(r chi.Router) {
+			if cfg.Feed.Enabled && cfg.Feed.Public {
+				r.Get("/feed", feed.New(log, storage, links, cfg.Feed.Size, true))
+			}
+
+			r.Group(func(r chi.Router) {
+				r.Use(authMiddleware)
+				r.Use(mwAudit.Actor)
 
-		r.Post("/", save.New(log, storage))
-		//TODO: поместить DELETE /url/{id} сюда
+				r.Get("/", list.New(log, storage, links))
+				r.Get("/export", csvexport.New(log, storage))
+				if cfg.Feed.Enabled && !cfg.Feed.Public {
+					r.Get("/feed", feed.New(log, storage, links, cfg.Feed.Size, false))
+				}
+				r.With(mwAuth.EditorOnly).With(saveLimit...).Post("/import", csvimport.New(log, storage, aliases, domains, generator, checker, quotas))
+			})
+		})
+
+		r.With(authMiddleware).Get("/quota", usage.New(log, quotas))
+		r.With(authMiddleware).Get("/alias/{alias}/available", available.New(log, aliases, storage))
+		r.With(authMiddleware).Get("/suggest", suggest.New(log, suggester, aliases, storage, fetcher))
+		// Anyone can follow a link, so anyone can expand it.
+		r.With(redirectLimit...).Get("/expand/{alias}", expand.New(log, storage, links))
+		r.With(authMiddleware, mwAuth.AdminOnly).Get("/audit", auditList.New(log, storage))
+		r.With(authMiddleware, mwAuth.AdminOnly).Get("/events", events.New(log, hub, storage, storage))
+
+		r.Route("/campaigns", func(r chi.Router) {
+			r.Use(authMiddleware)
+			r.Use(mwAudit.Actor)
+
+			edit := r.With(mwAuth.EditorOnly)
+			edit.Post("/", campaignsCreate.New(log, storage, links))
+			r.Get("/", campaignsList.New(log, storage))
+			r.Get("/{id}", campaignsGet.New(log, storage))
+			edit.Put("/{id}", campaignsUpdate.New(log, storage, links))
+			edit.Delete("/{id}", campaignsDelete.New(log, storage))
+			r.Get("/{id}/stats", campaignsStats.New(log, storage, links))
+		})
+
+		r.Route("/keys", func(r chi.Router) {
+			r.Use(sessionAuthMiddleware)
+			r.Use(mwAudit.Actor)
+
+			r.Post("/", keysCreate.New(log, storage))
+			r.Get("/", keysList.New(log, storage))
+			r.Delete("/{id}", keysRevoke.New(log, storage))
+		})
+
+		r.Route("/users", func(r chi.Router) {
+			r.Use(sessionAuthMiddleware)
+			r.Use(mwAudit.Actor)
+			r.Use(mwAuth.AdminOnly)
+
+			r.Post("/", usersCreate.New(log, storage))
+			r.Delete("/{id}/data", usersErase.New(log, storage))
+		})
+
+		if backups != nil {
+			r.Route("/backups", func(r chi.Router) {
+				r.Use(sessionAuthMiddleware)
+				r.Use(mwAuth.AdminOnly)
+
+				r.Post("/", backupCreate.New(log, backups))
+				r.Get("/", backupList.New(log, backups))
+				r.Get("/{name}", backupDownload.New(log, backups))
+				r.Post("/{name}/restore", backupRestore.New(log, backups))
+			})
+		}
+	}
+
+	router.Route(openapi.Prefix, func(r chi.Router) {
+		api(r)
+		// GET /shorten came after versioning and has no unversioned route.
+		r.With(authMiddleware, mwAuth.EditorOnly, mwAudit.Actor).With(saveLimit...).Get("/shorten", saveHandler)
+	})
+	// The API used to be served at the root. Those routes keep working
+	// for existing clients but point them to their successors.
+	router.Group(func(r chi.Router) {
+		r.Use(mwDeprecation.New(openapi.Prefix, apiVersionedAt))
+		api(r)
 	})
 
-	router.Get("/{alias}", redirect.New(log, storage))
+	redirectHandler := redirect.New(log, storage, storage, storage, cfg.Legal.NoticeURL, cfg.Redirect.Type, cfg.Redirect.CacheMaxAge, locator, bots, redirect.Fallbacks{
+		NotActive: cfg.Redirect.NotActiveURL,
+		Expired:   cfg.Redirect.ExpiredURL,
+		Errors:    errorPages,
+	}, links)
+
+	redirectMiddlewares := slices.Clone(redirectLimit)
+	if accessLog != nil {
+		redirectMiddlewares = append(redirectMiddlewares, mwAccessLog.New(accessLog))
+	}
 
-	log.Info("starting server", slog.String("address", cfg.HTTPServer.Address))
+	previewHandler := preview.New(log, storage, links)
+	router.With(redirectLimit...).Get("/{alias}+", previewHandler)
+	router.With(redirectMiddlewares...).With(preview.Query(previewHandler)).Get("/{alias}", redirectHandler)
+	// POST lets clients send the password of a protected link in a form.
+	router.With(redirectMiddlewares...).Post("/{alias}", redirectHandler)
+
+	log.Info("starting server",
+		slog.Any("addresses", cfg.HTTPServer.Addresses()),
+		slog.Bool("tls", cfg.TLS.Enabled()),
+	)
 
 	srv := &http.Server{
-		Addr:         cfg.HTTPServer.Address,
-		Handler:      router,
-		ReadTimeout:  cfg.HTTPServer.Timeout,
-		WriteTimeout: cfg.HTTPServer.Timeout,
-		IdleTimeout:  cfg.HTTPServer.IdleTimeout,
+		Addr:              cfg.HTTPServer.Address,
+		Handler:           router,
+		ReadTimeout:       cmp.Or(cfg.HTTPServer.ReadTimeout, cfg.HTTPServer.Timeout),
+		ReadHeaderTimeout: cfg.HTTPServer.ReadHeaderTimeout,
+		WriteTimeout:      cmp.Or(cfg.HTTPServer.WriteTimeout, cfg.HTTPServer.Timeout),
+		IdleTimeout:       cfg.HTTPServer.IdleTimeout,
+		MaxHeaderBytes:    cfg.HTTPServer.MaxHeaderBytes,
+	}
+	// Click streams never end on their own; they are closed as the server
+	// stops so that Shutdown does not wait for them.
+	srv.RegisterOnShutdown(hub.Close)
+	redirectSrv := setupTLS(srv, cfg.TLS)
+
+	var grpcServer *grpc.Server
+	if cfg.GRPCServer.Address != "" {
+		grpcServer = grpc.NewServer(grpc.ChainUnaryInterceptor(
+			interceptor.Logger(log),
+			interceptor.Auth(log, tokenParser, storage, authenticator),
+			interceptor.EditorOnly(shortener.EditMethods...),
+			interceptor.Audit(),
+		))
+		shortener.Register(grpcServer, log, storage, aliases, domains, generator, checker, quotas)
+
+		lis, err := listener.Listen(cfg.GRPCServer.Address, cfg.HTTPServer.SocketFileMode())
+		if err != nil {
+			log.Error("failed to listen for grpc", sl.Err(err))
+			os.Exit(1)
+		}
+
+		log.Info("starting grpc server", slog.String("address", cfg.GRPCServer.Address))
+
+		go func() {
+			if err := grpcServer.Serve(lis); err != nil {
+				log.Error("failed to serve grpc", sl.Err(err))
+				os.Exit(1)
+			}
+		}()
+	}
+
+	// Every address is served by srv, so Shutdown stops them all.
+	listeners := make([]net.Listener, 0, len(cfg.HTTPServer.Addresses()))
+	for _, addr := range cfg.HTTPServer.Addresses() {
+		l, err := listener.Listen(addr, cfg.HTTPServer.SocketFileMode())
+		if err != nil {
+			log.Error("faild to start server", slog.String("address", addr), sl.Err(err))
+			os.Exit(1)
+		}
+		listeners = append(listeners, l)
+	}
+	for _, l := range listeners {
+		go func() {
+			var err error
+			if cfg.TLS.Enabled() {
+				// With autocert the certificate comes from srv.TLSConfig
+				// and the file names are empty.
+				err = srv.ServeTLS(l, cfg.TLS.CertFile, cfg.TLS.KeyFile)
+			} else {
+				err = srv.Serve(l)
+			}
+			if err != nil && !errors.Is(err, http.ErrServerClosed) {
+				log.Error("faild to serve", slog.String("address", l.Addr().String()), sl.Err(err))
+				os.Exit(1)
+			}
+		}()
+	}
+
+	var diagnosticsSrv *http.Server
+	if cfg.Diagnostics.Address != "" {
+		// Profiles can take longer than the API timeouts allow.
+		diagnosticsSrv = &http.Server{
+			Handler:           diagnostics.NewHandler(),
+			ReadHeaderTimeout: cfg.HTTPServer.ReadHeaderTimeout,
+		}
+
+		lis, err := listener.Listen(cfg.Diagnostics.Address, cfg.HTTPServer.SocketFileMode())
+		if err != nil {
+			log.Error("failed to listen for diagnostics", sl.Err(err))
+			os.Exit(1)
+		}
+
+		log.Info("starting diagnostics server", slog.String("address", cfg.Diagnostics.Address))
+
+		go func() {
+			if err := diagnosticsSrv.Serve(lis); err != nil && !errors.Is(err, http.ErrServerClosed) {
+				log.Error("failed to serve diagnostics", sl.Err(err))
+				os.Exit(1)
+			}
+		}()
 	}
 
-	if err := srv.ListenAndServe(); err != nil {
-		log.Error("faild to start server")
+	if redirectSrv != nil {
+		log.Info("starting https redirect server", slog.String("address", redirectSrv.Addr))
+
+		go func() {
+			if err := redirectSrv.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+				log.Error("failed to start https redirect server", sl.Err(err))
+				os.Exit(1)
+			}
+		}()
 	}
 
-	log.Error("server stopped")
+	<-ctx.Done()
 
-	//остановка на 1:54:00
+	log.Info("stopping server")
+	shuttingDown.Store(true)
 
-	//TODO: run server
+	// Shutdown closes the listeners at once and then waits for in-flight
+	// requests; whatever is still running after the timeout is cut off.
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), cfg.HTTPServer.ShutdownTimeout)
+	defer cancel()
+
+	if err := srv.Shutdown(shutdownCtx); err != nil {
+		log.Error("failed to stop server gracefully", sl.Err(err))
+	}
+	if redirectSrv != nil {
+		if err := redirectSrv.Shutdown(shutdownCtx); err != nil {
+			log.Error("failed to stop https redirect server gracefully", sl.Err(err))
+		}
+	}
+	if diagnosticsSrv != nil {
+		// A running CPU profile or trace would hold Shutdown up.
+		_ = diagnosticsSrv.Close()
+	}
+
+	if grpcServer != nil {
+		stopGRPC(shutdownCtx, grpcServer)
+	}
 
-	//TODO: доделать хендлер DELETE
+	background.Wait()
+
+	if err := storage.Close(); err != nil {
+		log.Error("failed to close storage", sl.Err(err))
+	}
+
+	if tracerProvider != nil {
+		// Sends the spans that are still queued.
+		if err := tracerProvider.Shutdown(shutdownCtx); err != nil {
+			log.Error("failed to flush traces", sl.Err(err))
+		}
+	}
+
+	stopReporting()
+	<-reportDone
+
+	log.Info("server stopped")
 }
 
-func setupLogger(env string) *slog.Logger {
-	var log *slog.Logger
-	switch env {
-	case envLocal:
-		log = setupPrettySlog()
+// setupTLS prepares srv for HTTPS, if cfg enables it, and returns the plain
+// HTTP server that redirects to it; nil means there is none.
+func setupTLS(srv *http.Server, cfg config.TLS) *http.Server {
+	if !cfg.Enabled() {
+		return nil
+	}
+
+	var redirectHandler http.Handler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		redirectToHTTPS(w, r, srv.Addr)
+	})
+
+	if cfg.Autocert {
+		manager := &autocert.Manager{
+			Prompt:     autocert.AcceptTOS,
+			HostPolicy: autocert.HostWhitelist(cfg.Domains...),
+			Cache:      autocert.DirCache(cfg.CacheDir),
+			Email:      cfg.Email,
+		}
+		srv.TLSConfig = manager.TLSConfig()
+		// Answers http-01 challenges and passes the rest on.
+		redirectHandler = manager.HTTPHandler(redirectHandler)
+	} else {
+		srv.TLSConfig = &tls.Config{}
+	}
+	srv.TLSConfig.MinVersion = tls.VersionTLS12
+
+	if cfg.RedirectAddress == "" {
+		return nil
+	}
+
+	return &http.Server{
+		Addr:              cfg.RedirectAddress,
+		Handler:           redirectHandler,
+		ReadTimeout:       srv.ReadTimeout,
+		ReadHeaderTimeout: srv.ReadHeaderTimeout,
+		WriteTimeout:      srv.WriteTimeout,
+		IdleTimeout:       srv.IdleTimeout,
+		MaxHeaderBytes:    srv.MaxHeaderBytes,
+	}
+}
+
+// redirectToHTTPS sends the client to the same URL on the HTTPS server
+// listening on httpsAddr.
+func redirectToHTTPS(w http.ResponseWriter, r *http.Request, httpsAddr string) {
+	host, _, err := net.SplitHostPort(r.Host)
+	if err != nil {
+		host = r.Host
+	}
+	if _, port, err := net.SplitHostPort(httpsAddr); err == nil && port != "443" {
+		host = net.JoinHostPort(host, port)
+	}
+
+	http.Redirect(w, r, "https://"+host+r.URL.RequestURI(), http.StatusMovedPermanently)
+}
+
+// stopGRPC waits for running calls like srv.Shutdown does and cuts them
+// off once ctx is done.
+func stopGRPC(ctx context.Context, s *grpc.Server) {
+	stopped := make(chan struct{})
+	go func() {
+		s.GracefulStop()
+		close(stopped)
+	}()
+
+	select {
+	case <-stopped:
+	case <-ctx.Done():
+		s.Stop()
+	}
+}
+
+// rateLimit returns the rate limit middleware for limit, or nothing if the
+// limit is disabled.
+func rateLimit(log *slog.Logger, limit config.Limit) []func(http.Handler) http.Handler {
+	if limit.RPS <= 0 {
+		return nil
+	}
+
+	return []func(http.Handler) http.Handler{
+		mwRateLimit.New(log, ratelimit.New(limit.RPS, limit.Burst)),
+	}
+}
+
+func setupStorage(cfg *config.Config) (Storage, error) {
+	switch cfg.Storage.Type {
+	case config.StoragePostgres:
+		return postgres.New(cfg.Postgres.DSN, postgres.Options{
+			MaxOpenConns:    cfg.Postgres.MaxOpenConns,
+			MaxIdleConns:    cfg.Postgres.MaxIdleConns,
+			ConnMaxLifetime: cfg.Postgres.ConnMaxLifetime,
+		})
+	case config.StorageRedis:
+		return redis.New(redis.Options{
+			Addr:     cfg.Redis.Addr,
+			Password: cfg.Redis.Password,
+			DB:       cfg.Redis.DB,
+			PoolSize: cfg.Redis.PoolSize,
+			TTL:      cfg.Redis.TTL,
+		})
+	case config.StorageMemory:
+		return memory.New(), nil
+	default:
+		return sqlite.New(cfg.StoragePath, sqlite.Options{
+			JournalMode:  cfg.SQLite.JournalMode,
+			BusyTimeout:  cfg.SQLite.BusyTimeout,
+			Synchronous:  cfg.SQLite.Synchronous,
+			MaxOpenConns: cfg.SQLite.MaxOpenConns,
+		})
+	}
+}
 
+// setupWebhooks returns nil when no endpoints are configured.
+func setupWebhooks(log *slog.Logger, cfg config.Webhooks) (*webhook.Dispatcher, error) {
+	if len(cfg.Endpoints) == 0 {
+		return nil, nil
+	}
+
+	endpoints := make([]webhook.Endpoint, len(cfg.Endpoints))
+	for i, e := range cfg.Endpoints {
+		endpoints[i] = webhook.Endpoint{URL: e.URL, Secret: e.Secret, Events: e.Events}
+	}
+
+	return webhook.New(log, &http.Client{Timeout: cfg.Timeout}, endpoints, webhook.Options{
+		MaxAttempts: cfg.MaxAttempts,
+		Backoff:     cfg.Backoff,
+		QueueSize:   cfg.QueueSize,
+	})
+}
+
+// setupEventBus returns nil when no provider is configured.
+func setupEventBus(log *slog.Logger, cfg config.EventBus) (*eventbus.Publisher, error) {
+	var sender eventbus.Sender
+	switch cfg.Provider {
+	case config.EventBusKafka:
+		sender = eventbus.NewKafka(cfg.Brokers, cfg.Topic)
+	case config.EventBusNATS:
+		nats, err := eventbus.NewNATS(cfg.URL, cfg.Subject)
+		if err != nil {
+			return nil, err
+		}
+		sender = nats
+	default:
+		return nil, nil
+	}
+
+	return eventbus.New(log, sender, eventbus.Options{
+		Events:    cfg.Events,
+		Timeout:   cfg.Timeout,
+		QueueSize: cfg.QueueSize,
+	})
+}
+
+func telegramUsers(users []config.TelegramUser) map[int64]string {
+	m := make(map[int64]string, len(users))
+	for _, u := range users {
+		m[u.ID] = u.Username
+	}
+	return m
+}
+
+// setupURLChecker returns nil when reputation checks are disabled.
+func setupURLChecker(cfg config.Reputation) (save.URLChecker, error) {
+	switch cfg.Provider {
+	case config.ReputationBlocklist:
+		blocklist, err := reputation.LoadBlocklist(cfg.BlocklistPath)
+		if err != nil {
+			return nil, err
+		}
+		return blocklist, nil
+	case config.ReputationSafeBrowsing:
+		client := &http.Client{Timeout: cfg.Timeout}
+		return reputation.NewSafeBrowsing(client, reputation.SafeBrowsingURL, cfg.SafeBrowsingKey), nil
+	default:
+		return nil, nil
+	}
+}
+
+// setupAccessLog returns nil when the access log is disabled. The returned
+// func closes the log file, if any.
+func setupAccessLog(cfg config.AccessLog) (*slog.Logger, func(), error) {
+	if !cfg.Enabled {
+		return nil, func() {}, nil
+	}
+
+	if cfg.Path == "" {
+		return slog.New(slog.NewJSONHandler(os.Stdout, nil)), func() {}, nil
+	}
+
+	f, err := os.OpenFile(cfg.Path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o644)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return slog.New(slog.NewJSONHandler(f, nil)), func() { _ = f.Close() }, nil
+}
+
+// setupPrivacy returns the anonymizer for logs and stored data; nil means
+// the privacy mode is off.
+func setupPrivacy(cfg config.Privacy) (*privacy.Anonymizer, error) {
+	if !cfg.Enabled {
+		return nil, nil
+	}
+
+	return privacy.New(privacy.Options{
+		IPMode:       cfg.IPMode,
+		IPSalt:       cfg.IPSalt,
+		SecretParams: cfg.SecretParams,
+	})
+}
+
+func setupAccounts(log *slog.Logger, cfg config.HTTPServer) ([]users.Account, error) {
+	var accounts []users.Account
+	if cfg.User != "" {
+		hash := cfg.PasswordHash
+		if cfg.Password != "" {
+			log.Warn("http_server.password is kept in plain text, use http_server.password_hash instead")
+
+			h, err := bcrypt.GenerateFromPassword([]byte(cfg.Password), bcrypt.DefaultCost)
+			if err != nil {
+				return nil, err
+			}
+			hash = string(h)
+		}
+		accounts = append(accounts, users.Account{Username: cfg.User, PasswordHash: hash, Role: "admin"})
+	}
+	for _, u := range cfg.Users {
+		accounts = append(accounts, users.Account{Username: u.Name, PasswordHash: u.PasswordHash, Role: u.Role})
+	}
+	if cfg.HtpasswdFile != "" {
+		fromFile, err := users.LoadHtpasswd(cfg.HtpasswdFile)
+		if err != nil {
+			return nil, err
+		}
+		for _, acc := range fromFile {
+			if slices.ContainsFunc(accounts, func(a users.Account) bool { return a.Username == acc.Username }) {
+				return nil, fmt.Errorf("user %s is in both the config and %s", acc.Username, cfg.HtpasswdFile)
+			}
+			accounts = append(accounts, acc)
+		}
+	}
+
+	return accounts, nil
+}
+
+func setupOIDC(cfg *config.Config) *oidc.Provider {
+	o := cfg.Auth.OIDC
+	if o.Issuer == "" {
+		return nil
+	}
+
+	redirectURL := o.RedirectURL
+	if redirectURL == "" {
+		redirectURL = strings.TrimSuffix(cfg.BaseURL, "/") + openapi.Prefix + "/auth/oidc/callback"
+	}
+
+	return oidc.New(&http.Client{Timeout: o.Timeout}, oidc.Options{
+		Issuer:         o.Issuer,
+		ClientID:       o.ClientID,
+		ClientSecret:   o.ClientSecret,
+		RedirectURL:    redirectURL,
+		Scopes:         o.Scopes,
+		UsernameClaim:  o.UsernameClaim,
+		RoleClaim:      o.RoleClaim,
+		AdminValues:    o.AdminValues,
+		EditorValues:   o.EditorValues,
+		AllowedDomains: o.AllowedDomains,
+	})
+}
+
+func setupCaptcha(cfg config.Captcha) *captcha.Verifier {
+	endpoint := cfg.VerifyURL
+	if endpoint == "" {
+		endpoint = captcha.Endpoint(cfg.Provider)
+	}
+
+	return captcha.New(&http.Client{Timeout: cfg.Timeout}, endpoint, cfg.Secret)
+}
+
+func setupEncryption(cfg config.Encryption) (*encrypt.Cipher, error) {
+	key, err := encrypt.ParseKey(cfg.Key)
+	if err != nil {
+		return nil, err
+	}
+
+	return encrypt.New(key)
+}
+
+// anonymizedReports strips the client addresses and the secrets in the
+// referrer from the requests panics are reported with.
+func anonymizedReports(reporter errreport.Reporter, anonymizer *privacy.Anonymizer) errreport.Reporter {
+	return errreport.ReporterFunc(func(e errreport.Event) {
+		if e.Request != nil {
+			r := *e.Request
+			r.RemoteAddr = anonymizer.IP(r.RemoteAddr)
+			r.Headers = maps.Clone(r.Headers)
+			maps.DeleteFunc(r.Headers, func(name, _ string) bool {
+				return name == "X-Forwarded-For" || name == "X-Real-Ip"
+			})
+			if referrer, ok := r.Headers["Referer"]; ok {
+				r.Headers["Referer"] = anonymizer.URL(referrer)
+			}
+			e.Request = &r
+		}
+		reporter.Report(e)
+	})
+}
+
+// setupErrorReport returns the client that sends errors to cfg.ErrorReport.DSN;
+// nil means reporting is disabled.
+func setupErrorReport(log *slog.Logger, cfg *config.Config) (*sentry.Client, error) {
+	if cfg.ErrorReport.DSN == "" {
+		return nil, nil
+	}
+
+	build := diagnostics.ReadBuildInfo()
+	return sentry.New(log, &http.Client{Timeout: cfg.ErrorReport.Timeout}, cfg.ErrorReport.DSN, sentry.Options{
+		Environment: cmp.Or(cfg.ErrorReport.Environment, cfg.Env),
+		Release:     cmp.Or(build.Version, build.Commit),
+		QueueSize:   cfg.ErrorReport.QueueSize,
+	})
+}
+
+// setupLogger returns the application logger for cfg.Env as overridden by
+// cfg.Log, and a function that closes its file.
+func setupLogger(cfg *config.Config) (*slog.Logger, func(), error) {
+	format, level := config.LogFormatJSON, slog.LevelInfo
+	switch cfg.Env {
+	case envLocal:
+		format, level = config.LogFormatPretty, slog.LevelDebug
 	case envDev:
-		log = slog.New(
-			slog.NewJSONHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelDebug}),
-		)
+		level = slog.LevelDebug
+	}
+
+	format = cmp.Or(cfg.Log.Format, format)
+	if cfg.Log.Level != "" {
+		// Validated by config.
+		_ = level.UnmarshalText([]byte(cfg.Log.Level))
+	}
+
+	var (
+		out      io.Writer = os.Stdout
+		closeLog           = func() {}
+	)
+	if cfg.Log.Path != "" {
+		w, err := rotate.Open(cfg.Log.Path, rotate.Options{
+			MaxSize:    cfg.Log.Rotation.MaxSize,
+			Interval:   cfg.Log.Rotation.Interval,
+			MaxBackups: cfg.Log.Rotation.MaxBackups,
+			MaxAge:     cfg.Log.Rotation.MaxAge,
+		})
+		if err != nil {
+			return nil, nil, err
+		}
+		out, closeLog = w, func() { _ = w.Close() }
+	}
 
-	case envProd:
-		log = slog.New(
-			slog.NewJSONHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelInfo}),
-		)
+	opts := &slog.HandlerOptions{Level: level}
+	switch format {
+	case config.LogFormatPretty:
+		return setupPrettySlog(out, opts), closeLog, nil
+	case config.LogFormatText:
+		return slog.New(slog.NewTextHandler(out, opts)), closeLog, nil
+	default:
+		return slog.New(slog.NewJSONHandler(out, opts)), closeLog, nil
 	}
-	return log
 }
 
-func setupPrettySlog() *slog.Logger {
+func setupPrettySlog(out io.Writer, slogOpts *slog.HandlerOptions) *slog.Logger {
 	opts := slogpretty.PrettyHandlerOptions{
-		SlogOpts: &slog.HandlerOptions{
-			Level: slog.LevelDebug,
-		},
+		SlogOpts: slogOpts,
 	}
 
-	handler := opts.NewPrettyHandler(os.Stdout)
+	handler := opts.NewPrettyHandler(out)
 
 	return slog.New(handler)
 }
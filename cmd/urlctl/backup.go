@@ -0,0 +1,48 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"url-shortener/internal/config"
+	"url-shortener/internal/storage/sqlite"
+)
+
+// runSnapshot backs the SQLite database up to a file or restores it from
+// one, depending on cmd. Both work while the server is running, but the
+// server's caches do not see a restore until it is restarted.
+func runSnapshot(ctx context.Context, cmd string, args []string) error {
+	if len(args) != 1 {
+		return fmt.Errorf("usage: urlctl %s <file>", cmd)
+	}
+
+	cfg, err := config.Load(os.Getenv("CONFIG_PATH"))
+	if err != nil {
+		return err
+	}
+	if cfg.Storage.Type != config.StorageSQLite {
+		return errors.New("backup and restore are only supported for sqlite storage")
+	}
+
+	s, err := sqlite.Open(cfg.StoragePath, sqliteOptions(cfg))
+	if err != nil {
+		return err
+	}
+	defer s.Close()
+
+	if cmd == "backup" {
+		if err := s.Backup(ctx, args[0]); err != nil {
+			return err
+		}
+		fmt.Printf("backed up %s to %s\n", cfg.StoragePath, args[0])
+		return nil
+	}
+
+	if err := s.Restore(ctx, args[0]); err != nil {
+		return err
+	}
+	fmt.Printf("restored %s from %s\n", cfg.StoragePath, args[0])
+
+	return nil
+}
@@ -0,0 +1,35 @@
+package main
+
+import (
+	"context"
+	"time"
+)
+
+// client is what the subcommands need; it is implemented on top of the
+// storage directly and on top of the HTTP API.
+type client interface {
+	Add(ctx context.Context, longURL, alias string) (string, error)
+	Delete(ctx context.Context, alias string) error
+	List(ctx context.Context, limit, offset int) ([]link, error)
+	Stats(ctx context.Context, alias string, days int, includeBots bool) (linkStats, error)
+}
+
+type link struct {
+	Alias     string
+	URL       string
+	Owner     string
+	CreatedAt time.Time
+	// ExpiresAt is zero for links that never expire.
+	ExpiresAt time.Time
+}
+
+type linkStats struct {
+	Total      int64
+	LastAccess time.Time
+	Daily      []dailyClicks
+}
+
+type dailyClicks struct {
+	Date   string
+	Clicks int64
+}
@@ -0,0 +1,181 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+	"url-shortener/internal/http-server/handlers/auth/login"
+	"url-shortener/internal/http-server/handlers/url/list"
+	"url-shortener/internal/http-server/handlers/url/save"
+	"url-shortener/internal/http-server/handlers/url/stats"
+	"url-shortener/internal/http-server/openapi"
+	resp "url-shortener/internal/lib/api/response"
+)
+
+// httpClient talks to a running server through its API, with the rights of
+// the user it logs in as.
+type httpClient struct {
+	baseURL string
+	token   string
+	apiKey  string
+	client  *http.Client
+}
+
+// newHTTPClient authenticates with apiKey or token if given, and otherwise
+// logs in as user to get a token.
+func newHTTPClient(ctx context.Context, baseURL, apiKey, token, user, password string) (*httpClient, error) {
+	const op = "urlctl.newHTTPClient"
+
+	c := &httpClient{
+		baseURL: baseURL,
+		token:   token,
+		apiKey:  apiKey,
+		client:  &http.Client{Timeout: 30 * time.Second},
+	}
+
+	if c.apiKey == "" && c.token == "" {
+		var res login.Response
+		err := c.do(ctx, http.MethodPost, "/auth/login", login.Request{
+			Username: user,
+			Password: password,
+		}, &res)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", op, err)
+		}
+		c.token = res.Token
+	}
+
+	return c, nil
+}
+
+func (c *httpClient) Add(ctx context.Context, longURL, alias string) (string, error) {
+	const op = "urlctl.httpClient.Add"
+
+	var res save.Response
+	if err := c.do(ctx, http.MethodPost, "/url", save.Request{URL: longURL, Alias: alias}, &res); err != nil {
+		return "", fmt.Errorf("%s: %w", op, err)
+	}
+
+	return res.Alias, nil
+}
+
+func (c *httpClient) Delete(ctx context.Context, alias string) error {
+	const op = "urlctl.httpClient.Delete"
+
+	if err := c.do(ctx, http.MethodDelete, "/url/"+url.PathEscape(alias), nil, nil); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	return nil
+}
+
+func (c *httpClient) List(ctx context.Context, limit, offset int) ([]link, error) {
+	const op = "urlctl.httpClient.List"
+
+	query := url.Values{}
+	query.Set("limit", strconv.Itoa(limit))
+	query.Set("offset", strconv.Itoa(offset))
+
+	var res list.Response
+	if err := c.do(ctx, http.MethodGet, "/urls?"+query.Encode(), nil, &res); err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	links := make([]link, 0, len(res.URLs))
+	for _, u := range res.URLs {
+		l := link{
+			Alias:     u.Alias,
+			URL:       u.URL,
+			Owner:     u.Owner,
+			CreatedAt: u.CreatedAt,
+		}
+		if u.ExpiresAt != nil {
+			l.ExpiresAt = *u.ExpiresAt
+		}
+		links = append(links, l)
+	}
+
+	return links, nil
+}
+
+func (c *httpClient) Stats(ctx context.Context, alias string, days int, includeBots bool) (linkStats, error) {
+	const op = "urlctl.httpClient.Stats"
+
+	path := "/url/" + url.PathEscape(alias) + "/stats?days=" + strconv.Itoa(days) + "&include_bots=" + strconv.FormatBool(includeBots)
+
+	var res stats.Response
+	if err := c.do(ctx, http.MethodGet, path, nil, &res); err != nil {
+		return linkStats{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	s := linkStats{Total: res.TotalClicks}
+	if res.LastAccess != nil {
+		s.LastAccess = *res.LastAccess
+	}
+	for _, d := range res.Daily {
+		s.Daily = append(s.Daily, dailyClicks{Date: d.Date, Clicks: d.Clicks})
+	}
+
+	return s, nil
+}
+
+// do sends body as JSON and decodes the JSON answer into res unless it is
+// nil. Some handlers report errors with status 200, so the status field of
+// the answer is checked as well as the HTTP status.
+func (c *httpClient) do(ctx context.Context, method, path string, body any, res any) error {
+	var reader io.Reader
+	if body != nil {
+		b, err := json.Marshal(body)
+		if err != nil {
+			return err
+		}
+		reader = bytes.NewReader(b)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, c.baseURL+openapi.Prefix+path, reader)
+	if err != nil {
+		return err
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if c.apiKey != "" {
+		req.Header.Set("X-Api-Key", c.apiKey)
+	} else if c.token != "" {
+		req.Header.Set("Authorization", "Bearer "+c.token)
+	}
+
+	r, err := c.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer r.Body.Close()
+
+	data, err := io.ReadAll(r.Body)
+	if err != nil {
+		return err
+	}
+
+	var status resp.Response
+	if err := json.Unmarshal(data, &status); err != nil {
+		return fmt.Errorf("unexpected response: %s", r.Status)
+	}
+	if status.Status != resp.StatusOk {
+		if status.Error == "" {
+			return fmt.Errorf("unexpected response: %s", r.Status)
+		}
+		return fmt.Errorf("%s: %s", r.Status, status.Error)
+	}
+
+	if res == nil {
+		return nil
+	}
+
+	return json.Unmarshal(data, res)
+}
@@ -0,0 +1,276 @@
+// Command urlctl manages links without curl. It works on the storage
+// directly, using the same config as the server, or through the HTTP API
+// of a running server when -api is given.
+package main
+
+import (
+	"bufio"
+	"context"
+	"encoding/csv"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"text/tabwriter"
+	"time"
+	"url-shortener/internal/config"
+
+	"github.com/joho/godotenv"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// pageSize is the page used by export; it is the largest page GET /urls
+// serves.
+const pageSize = 100
+
+const usage = `usage: urlctl [flags] <command> [args]
+
+commands:
+  add [-alias alias] <url>     create a link
+  delete <alias>               delete a link
+  list [-limit n] [-offset n]  list links, newest first
+  stats [-days n] [-bots] <alias>
+                               show click statistics, with -bots counting
+                               crawlers and scripts too
+  export                       write all links to stdout as CSV
+  migrate up|down|status       apply, roll back (-steps n) or list schema
+                               migrations; the server applies them on start
+  backup <file>                copy the sqlite database to file while it
+                               stays in use
+  restore <file>               replace the sqlite database with file
+  hash-password                read a password from stdin and print its
+                               bcrypt hash for http_server.password_hash
+
+Without -api urlctl opens the storage from the config named by CONFIG_PATH
+(or from environment variables) and acts as an admin.
+
+flags:
+`
+
+func main() {
+	if err := run(); err != nil {
+		fmt.Fprintln(os.Stderr, "urlctl:", err)
+		os.Exit(1)
+	}
+}
+
+func run() error {
+	_ = godotenv.Load()
+
+	api := flag.String("api", os.Getenv("URLCTL_API"), "base URL of a running server, e.g. http://localhost:8082")
+	apiKey := flag.String("api-key", os.Getenv("URLCTL_API_KEY"), "API key for -api")
+	token := flag.String("token", os.Getenv("URLCTL_TOKEN"), "bearer token for -api")
+	user := flag.String("user", os.Getenv("URLCTL_USER"), "user to log in as with -api")
+	password := flag.String("password", os.Getenv("URLCTL_PASSWORD"), "password of -user")
+	flag.Usage = func() {
+		fmt.Fprint(flag.CommandLine.Output(), usage)
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+
+	if flag.NArg() == 0 {
+		flag.Usage()
+		return errors.New("no command given")
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	// Migrations need the storage as it is, not migrated and not through
+	// the API; so do backups, which copy its file.
+	switch flag.Arg(0) {
+	case "migrate":
+		return runMigrate(ctx, flag.Args()[1:])
+	case "backup", "restore":
+		return runSnapshot(ctx, flag.Arg(0), flag.Args()[1:])
+	case "hash-password":
+		return runHashPassword(os.Stdin)
+	}
+
+	var c client
+	if *api != "" {
+		hc, err := newHTTPClient(ctx, *api, *apiKey, *token, *user, *password)
+		if err != nil {
+			return err
+		}
+		c = hc
+	} else {
+		cfg, err := config.Load(os.Getenv("CONFIG_PATH"))
+		if err != nil {
+			return err
+		}
+
+		sc, err := newStorageClient(cfg)
+		if err != nil {
+			return err
+		}
+		defer sc.Close()
+		c = sc
+	}
+
+	cmd, args := flag.Arg(0), flag.Args()[1:]
+	switch cmd {
+	case "add":
+		return runAdd(ctx, c, args)
+	case "delete":
+		return runDelete(ctx, c, args)
+	case "list":
+		return runList(ctx, c, args)
+	case "stats":
+		return runStats(ctx, c, args)
+	case "export":
+		return runExport(ctx, c, args)
+	default:
+		flag.Usage()
+		return fmt.Errorf("unknown command: %s", cmd)
+	}
+}
+
+// runHashPassword reads the password from stdin rather than the arguments,
+// which would leave it in the shell history.
+func runHashPassword(in io.Reader) error {
+	password, err := bufio.NewReader(in).ReadString('\n')
+	if err != nil && !errors.Is(err, io.EOF) {
+		return err
+	}
+	password = strings.TrimRight(password, "\r\n")
+	if password == "" {
+		return errors.New("usage: echo -n password | urlctl hash-password")
+	}
+
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	if err != nil {
+		return err
+	}
+
+	fmt.Println(string(hash))
+
+	return nil
+}
+
+func runAdd(ctx context.Context, c client, args []string) error {
+	fs := flag.NewFlagSet("add", flag.ExitOnError)
+	alias := fs.String("alias", "", "alias to use instead of a random one")
+	_ = fs.Parse(args)
+
+	if fs.NArg() != 1 {
+		return errors.New("usage: urlctl add [-alias alias] <url>")
+	}
+
+	created, err := c.Add(ctx, fs.Arg(0), *alias)
+	if err != nil {
+		return err
+	}
+
+	fmt.Println(created)
+
+	return nil
+}
+
+func runDelete(ctx context.Context, c client, args []string) error {
+	if len(args) != 1 {
+		return errors.New("usage: urlctl delete <alias>")
+	}
+
+	return c.Delete(ctx, args[0])
+}
+
+func runList(ctx context.Context, c client, args []string) error {
+	fs := flag.NewFlagSet("list", flag.ExitOnError)
+	limit := fs.Int("limit", 20, "number of links to show")
+	offset := fs.Int("offset", 0, "number of links to skip")
+	_ = fs.Parse(args)
+
+	links, err := c.List(ctx, *limit, *offset)
+	if err != nil {
+		return err
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(w, "ALIAS\tURL\tOWNER\tCREATED\tEXPIRES")
+	for _, l := range links {
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n", l.Alias, l.URL, l.Owner, formatTime(l.CreatedAt), formatTime(l.ExpiresAt))
+	}
+
+	return w.Flush()
+}
+
+func runStats(ctx context.Context, c client, args []string) error {
+	fs := flag.NewFlagSet("stats", flag.ExitOnError)
+	days := fs.Int("days", 30, "number of days to show")
+	bots := fs.Bool("bots", false, "count clicks of bots too")
+	_ = fs.Parse(args)
+
+	if fs.NArg() != 1 {
+		return errors.New("usage: urlctl stats [-days n] [-bots] <alias>")
+	}
+
+	s, err := c.Stats(ctx, fs.Arg(0), *days, *bots)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("total clicks: %d\n", s.Total)
+	fmt.Printf("last access:  %s\n", formatTime(s.LastAccess))
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(w, "DATE\tCLICKS")
+	for _, d := range s.Daily {
+		fmt.Fprintf(w, "%s\t%d\n", d.Date, d.Clicks)
+	}
+
+	return w.Flush()
+}
+
+func runExport(ctx context.Context, c client, _ []string) error {
+	return writeCSV(ctx, c, os.Stdout)
+}
+
+// writeCSV pages through every link, oldest pages last, so a link created
+// during the export may be listed twice; nothing is skipped.
+func writeCSV(ctx context.Context, c client, out io.Writer) error {
+	w := csv.NewWriter(out)
+	if err := w.Write([]string{"alias", "url", "owner", "created_at", "expires_at"}); err != nil {
+		return err
+	}
+
+	for offset := 0; ; offset += pageSize {
+		links, err := c.List(ctx, pageSize, offset)
+		if err != nil {
+			return err
+		}
+
+		for _, l := range links {
+			err := w.Write([]string{l.Alias, l.URL, l.Owner, formatRFC3339(l.CreatedAt), formatRFC3339(l.ExpiresAt)})
+			if err != nil {
+				return err
+			}
+		}
+
+		if len(links) < pageSize {
+			break
+		}
+	}
+
+	w.Flush()
+
+	return w.Error()
+}
+
+func formatTime(t time.Time) string {
+	if t.IsZero() {
+		return "-"
+	}
+	return t.Local().Format(time.DateTime)
+}
+
+func formatRFC3339(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.UTC().Format(time.RFC3339)
+}
@@ -0,0 +1,99 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"text/tabwriter"
+	"url-shortener/internal/config"
+	"url-shortener/internal/storage/migrate"
+	"url-shortener/internal/storage/postgres"
+	"url-shortener/internal/storage/sqlite"
+)
+
+// migratable is a storage with a versioned schema.
+type migratable interface {
+	Migrator(ctx context.Context) (*migrate.Migrator, error)
+	Close() error
+}
+
+// openMigratable opens the storage without migrating it, which the server
+// and the other commands do on start.
+func openMigratable(cfg *config.Config) (migratable, error) {
+	switch cfg.Storage.Type {
+	case config.StoragePostgres:
+		return postgres.Open(cfg.Postgres.DSN, postgres.Options{
+			MaxOpenConns:    cfg.Postgres.MaxOpenConns,
+			MaxIdleConns:    cfg.Postgres.MaxIdleConns,
+			ConnMaxLifetime: cfg.Postgres.ConnMaxLifetime,
+		})
+	case config.StorageSQLite:
+		return sqlite.Open(cfg.StoragePath, sqliteOptions(cfg))
+	default:
+		return nil, fmt.Errorf("%s storage has no schema to migrate", cfg.Storage.Type)
+	}
+}
+
+func runMigrate(ctx context.Context, args []string) error {
+	const usage = "usage: urlctl migrate up | down [-steps n] | status"
+
+	if len(args) == 0 {
+		return errors.New(usage)
+	}
+
+	cfg, err := config.Load(os.Getenv("CONFIG_PATH"))
+	if err != nil {
+		return err
+	}
+
+	s, err := openMigratable(cfg)
+	if err != nil {
+		return err
+	}
+	defer s.Close()
+
+	m, err := s.Migrator(ctx)
+	if err != nil {
+		return err
+	}
+
+	switch args[0] {
+	case "up":
+		n, err := m.Up(ctx)
+		fmt.Printf("applied %d migrations\n", n)
+		return err
+	case "down":
+		fs := flag.NewFlagSet("migrate down", flag.ExitOnError)
+		steps := fs.Int("steps", 1, "number of migrations to roll back")
+		_ = fs.Parse(args[1:])
+
+		n, err := m.Down(ctx, *steps)
+		fmt.Printf("rolled back %d migrations\n", n)
+		return err
+	case "status":
+		statuses, err := m.Status(ctx)
+		if err != nil {
+			return err
+		}
+
+		w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+		fmt.Fprintln(w, "VERSION\tNAME\tAPPLIED")
+		for _, st := range statuses {
+			name := st.Name
+			if name == "" {
+				name = "(unknown, applied by a newer build)"
+			}
+			applied := "pending"
+			if !st.AppliedAt.IsZero() {
+				applied = formatTime(st.AppliedAt)
+			}
+			fmt.Fprintf(w, "%04d\t%s\t%s\n", st.Version, name, applied)
+		}
+
+		return w.Flush()
+	default:
+		return errors.New(usage)
+	}
+}
@@ -0,0 +1,222 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"time"
+	"url-shortener/internal/config"
+	"url-shortener/internal/lib/alias"
+	"url-shortener/internal/lib/encrypt"
+	"url-shortener/internal/storage"
+	"url-shortener/internal/storage/casefolded"
+	"url-shortener/internal/storage/encrypted"
+	"url-shortener/internal/storage/instrumented"
+	"url-shortener/internal/storage/postgres"
+	"url-shortener/internal/storage/redis"
+	"url-shortener/internal/storage/sqlite"
+)
+
+const maxRetries = 5
+
+// Storage is the part of a storage backend urlctl works with.
+type Storage interface {
+	SaveURL(ctx context.Context, u storage.URL) (int64, error)
+	NextURLID(ctx context.Context) (int64, error)
+	DeleteURL(ctx context.Context, alias string, owner string) error
+	ListURLs(ctx context.Context, filter storage.ListFilter, limit int, offset int, desc bool) ([]storage.URL, error)
+	GetStats(ctx context.Context, alias string, since time.Time, includeBots bool) (storage.Stats, error)
+	Close() error
+}
+
+// storageClient works on the storage directly, bypassing the server, with
+// admin rights: it sees and deletes links of every user.
+type storageClient struct {
+	storage   Storage
+	aliases   *alias.Validator
+	generator alias.Generator
+}
+
+func newStorageClient(cfg *config.Config) (*storageClient, error) {
+	var signer *alias.Signer
+	if cfg.Alias.Signing.Key != "" {
+		var err error
+		signer, err = alias.NewSigner([]byte(cfg.Alias.Signing.Key), cfg.Alias.Signing.Length, cfg.Alias.CaseInsensitive)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	aliases, err := alias.New(alias.Rules{
+		MinLength: cfg.Alias.MinLength,
+		MaxLength: cfg.Alias.MaxLength,
+		Pattern:   cfg.Alias.Pattern,
+		Reserved:  slices.Concat(alias.Routes, cfg.Alias.Reserved),
+		Signer:    signer,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	s, err := openStorage(cfg)
+	if err != nil {
+		return nil, err
+	}
+	// Every backend openStorage returns is a full instrumented.Backend.
+	if cfg.Encryption.Key != "" {
+		key, err := encrypt.ParseKey(cfg.Encryption.Key)
+		if err != nil {
+			s.Close()
+			return nil, err
+		}
+		cipher, err := encrypt.New(key)
+		if err != nil {
+			s.Close()
+			return nil, err
+		}
+		s = encrypted.New(s.(instrumented.Backend), cipher)
+	}
+	if cfg.Alias.CaseInsensitive {
+		s = casefolded.New(s.(instrumented.Backend))
+	}
+
+	generator, err := alias.NewGenerator(cfg.Alias.Strategy, cfg.Alias.GeneratedLength, cfg.Alias.Alphabet, s, aliases, alias.NewFilter(alias.FilterRules{
+		BadWords:   cfg.Alias.FilterBadWords,
+		ExtraWords: cfg.Alias.BadWords,
+		Confusable: cfg.Alias.SkipConfusable,
+	}))
+	if err != nil {
+		s.Close()
+		return nil, err
+	}
+	if signer != nil {
+		generator = alias.NewSigned(generator, signer)
+	}
+
+	return &storageClient{storage: s, aliases: aliases, generator: generator}, nil
+}
+
+func openStorage(cfg *config.Config) (Storage, error) {
+	switch cfg.Storage.Type {
+	case config.StoragePostgres:
+		return postgres.New(cfg.Postgres.DSN, postgres.Options{
+			MaxOpenConns:    cfg.Postgres.MaxOpenConns,
+			MaxIdleConns:    cfg.Postgres.MaxIdleConns,
+			ConnMaxLifetime: cfg.Postgres.ConnMaxLifetime,
+		})
+	case config.StorageRedis:
+		return redis.New(redis.Options{
+			Addr:     cfg.Redis.Addr,
+			Password: cfg.Redis.Password,
+			DB:       cfg.Redis.DB,
+			PoolSize: cfg.Redis.PoolSize,
+			TTL:      cfg.Redis.TTL,
+		})
+	case config.StorageMemory:
+		// Nothing would outlive the command, so there is nothing to manage.
+		return nil, errors.New("memory storage cannot be managed offline, use -api")
+	default:
+		return sqlite.New(cfg.StoragePath, sqliteOptions(cfg))
+	}
+}
+
+// sqliteOptions are the connection settings of the server, so that urlctl
+// waits for its locks instead of failing while it is running.
+func sqliteOptions(cfg *config.Config) sqlite.Options {
+	return sqlite.Options{
+		JournalMode:  cfg.SQLite.JournalMode,
+		BusyTimeout:  cfg.SQLite.BusyTimeout,
+		Synchronous:  cfg.SQLite.Synchronous,
+		MaxOpenConns: cfg.SQLite.MaxOpenConns,
+	}
+}
+
+func (c *storageClient) Add(ctx context.Context, longURL, alias string) (string, error) {
+	const op = "urlctl.storageClient.Add"
+
+	u := storage.URL{URL: longURL, Alias: alias}
+	if u.Alias != "" {
+		if err := c.aliases.Validate(u.Alias); err != nil {
+			return "", fmt.Errorf("%s: %w", op, err)
+		}
+		u.Alias = c.aliases.Sign(u.Alias)
+		if _, err := c.storage.SaveURL(ctx, u); err != nil {
+			return "", fmt.Errorf("%s: %w", op, err)
+		}
+		return u.Alias, nil
+	}
+
+	for i := 0; i < maxRetries; i++ {
+		var err error
+		u.Alias, err = c.generator.Generate(ctx)
+		if err != nil {
+			return "", fmt.Errorf("%s: %w", op, err)
+		}
+
+		_, err = c.storage.SaveURL(ctx, u)
+		if err == nil {
+			return u.Alias, nil
+		}
+		if !errors.Is(err, storage.ErrUrlExists) {
+			return "", fmt.Errorf("%s: %w", op, err)
+		}
+	}
+
+	return "", fmt.Errorf("%s: failed to generate unique alias", op)
+}
+
+func (c *storageClient) Delete(ctx context.Context, alias string) error {
+	const op = "urlctl.storageClient.Delete"
+
+	if err := c.storage.DeleteURL(ctx, alias, ""); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	return nil
+}
+
+func (c *storageClient) List(ctx context.Context, limit, offset int) ([]link, error) {
+	const op = "urlctl.storageClient.List"
+
+	urls, err := c.storage.ListURLs(ctx, storage.ListFilter{}, limit, offset, true)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	links := make([]link, 0, len(urls))
+	for _, u := range urls {
+		links = append(links, link{
+			Alias:     u.Alias,
+			URL:       u.URL,
+			Owner:     u.Owner,
+			CreatedAt: u.CreatedAt,
+			ExpiresAt: u.ExpiresAt,
+		})
+	}
+
+	return links, nil
+}
+
+func (c *storageClient) Stats(ctx context.Context, alias string, days int, includeBots bool) (linkStats, error) {
+	const op = "urlctl.storageClient.Stats"
+
+	today := time.Now().UTC().Truncate(24 * time.Hour)
+	since := today.AddDate(0, 0, -(days - 1))
+
+	s, err := c.storage.GetStats(ctx, alias, since, includeBots)
+	if err != nil {
+		return linkStats{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	res := linkStats{Total: s.Total, LastAccess: s.LastAccess}
+	for _, d := range s.Daily {
+		res.Daily = append(res.Daily, dailyClicks{Date: d.Date, Clicks: d.Clicks})
+	}
+
+	return res, nil
+}
+
+func (c *storageClient) Close() error {
+	return c.storage.Close()
+}
@@ -0,0 +1,216 @@
+// Package backup keeps snapshots of the database in a directory, taken on
+// demand or every interval.
+package backup
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+
+	"url-shortener/internal/lib/logger/sl"
+)
+
+var ErrNotFound = errors.New("backup not found")
+
+// Snapshotter copies the database to a file and back.
+type Snapshotter interface {
+	Backup(ctx context.Context, path string) error
+	Restore(ctx context.Context, path string) error
+}
+
+// Flusher is a cache in front of the database that must forget everything
+// once the database is restored.
+type Flusher interface {
+	Flush(ctx context.Context) error
+}
+
+// Snapshot is a backup file in the directory.
+type Snapshot struct {
+	Name      string
+	Size      int64
+	CreatedAt time.Time
+}
+
+const (
+	prefix     = "url-shortener-"
+	suffix     = ".db"
+	timeLayout = "20060102-150405.000"
+)
+
+// names only matches files the manager created, so that names taken from
+// requests cannot point elsewhere.
+var names = regexp.MustCompile(`^url-shortener-\d{8}-\d{6}\.\d{3}\.db$`)
+
+// Manager takes, lists and restores snapshots in dir.
+type Manager struct {
+	log         *slog.Logger
+	snapshotter Snapshotter
+	dir         string
+	// keep is how many snapshots scheduled backups leave; 0 keeps all.
+	keep int
+	// caches are flushed after every restore.
+	caches []Flusher
+}
+
+func New(log *slog.Logger, snapshotter Snapshotter, dir string, keep int, caches ...Flusher) *Manager {
+	return &Manager{
+		log:         log.With(slog.String("component", "backup")),
+		snapshotter: snapshotter,
+		dir:         dir,
+		keep:        keep,
+		caches:      caches,
+	}
+}
+
+// Create takes a snapshot.
+func (m *Manager) Create(ctx context.Context) (Snapshot, error) {
+	const op = "backup.Create"
+
+	if err := os.MkdirAll(m.dir, 0o750); err != nil {
+		return Snapshot{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	name := prefix + time.Now().UTC().Format(timeLayout) + suffix
+	if err := m.snapshotter.Backup(ctx, filepath.Join(m.dir, name)); err != nil {
+		return Snapshot{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	snapshot, err := m.stat(name)
+	if err != nil {
+		return Snapshot{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return snapshot, nil
+}
+
+// List returns the snapshots, newest first.
+func (m *Manager) List() ([]Snapshot, error) {
+	const op = "backup.List"
+
+	entries, err := os.ReadDir(m.dir)
+	if errors.Is(err, os.ErrNotExist) {
+		return []Snapshot{}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	snapshots := []Snapshot{}
+	for _, e := range entries {
+		if e.IsDir() || !names.MatchString(e.Name()) {
+			continue
+		}
+		snapshot, err := m.stat(e.Name())
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", op, err)
+		}
+		snapshots = append(snapshots, snapshot)
+	}
+	slices.SortFunc(snapshots, func(a, b Snapshot) int { return strings.Compare(b.Name, a.Name) })
+
+	return snapshots, nil
+}
+
+// Path returns the file of the snapshot name.
+func (m *Manager) Path(name string) (string, error) {
+	if !names.MatchString(name) {
+		return "", ErrNotFound
+	}
+
+	path := filepath.Join(m.dir, name)
+	if _, err := os.Stat(path); err != nil {
+		return "", ErrNotFound
+	}
+
+	return path, nil
+}
+
+// Restore replaces the database with the snapshot name, then flushes the
+// caches so that they do not serve links from before the restore. A cache
+// that fails to flush is only logged: the restore has happened, and the
+// cache forgets the links when their TTL runs out.
+func (m *Manager) Restore(ctx context.Context, name string) error {
+	const op = "backup.Restore"
+
+	path, err := m.Path(name)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	if err := m.snapshotter.Restore(ctx, path); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	for _, cache := range m.caches {
+		if err := cache.Flush(context.WithoutCancel(ctx)); err != nil {
+			m.log.Error("failed to flush cache after restore", sl.Err(err))
+		}
+	}
+
+	return nil
+}
+
+// Run takes a snapshot every interval, then removes all but the newest
+// keep snapshots, until ctx is cancelled.
+func (m *Manager) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			snapshot, err := m.Create(ctx)
+			if err != nil {
+				m.log.Error("failed to back up", sl.Err(err))
+				continue
+			}
+			m.log.Info("backed up", slog.String("name", snapshot.Name), slog.Int64("size", snapshot.Size))
+
+			if err := m.prune(); err != nil {
+				m.log.Error("failed to remove old backups", sl.Err(err))
+			}
+		}
+	}
+}
+
+func (m *Manager) prune() error {
+	if m.keep <= 0 {
+		return nil
+	}
+
+	snapshots, err := m.List()
+	if err != nil {
+		return err
+	}
+
+	for _, s := range snapshots[min(m.keep, len(snapshots)):] {
+		if err := os.Remove(filepath.Join(m.dir, s.Name)); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (m *Manager) stat(name string) (Snapshot, error) {
+	info, err := os.Stat(filepath.Join(m.dir, name))
+	if err != nil {
+		return Snapshot{}, err
+	}
+
+	createdAt, err := time.Parse(timeLayout, strings.TrimSuffix(strings.TrimPrefix(name, prefix), suffix))
+	if err != nil {
+		return Snapshot{}, err
+	}
+
+	return Snapshot{Name: name, Size: info.Size(), CreatedAt: createdAt}, nil
+}
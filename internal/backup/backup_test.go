@@ -0,0 +1,122 @@
+package backup_test
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"url-shortener/internal/backup"
+	"url-shortener/internal/lib/logger/handlers/slogdiscard"
+)
+
+// fileSnapshotter copies a single in-memory "database" to files and back.
+type fileSnapshotter struct {
+	data     string
+	restored string
+}
+
+func (s *fileSnapshotter) Backup(_ context.Context, path string) error {
+	return os.WriteFile(path, []byte(s.data), 0o600)
+}
+
+func (s *fileSnapshotter) Restore(_ context.Context, path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	s.restored = string(data)
+	return nil
+}
+
+// countingFlusher counts the times it was flushed.
+type countingFlusher struct {
+	flushes int
+	err     error
+}
+
+func (f *countingFlusher) Flush(_ context.Context) error {
+	f.flushes++
+	return f.err
+}
+
+func TestManager(t *testing.T) {
+	ctx := context.Background()
+	dir := filepath.Join(t.TempDir(), "backups")
+	snapshotter := &fileSnapshotter{data: "v1"}
+	cache := &countingFlusher{}
+	// A cache failing to flush does not fail the restore.
+	brokenCache := &countingFlusher{err: errors.New("connection refused")}
+	m := backup.New(slogdiscard.NewDiscardLogger(), snapshotter, dir, 0, brokenCache, cache)
+
+	list, err := m.List()
+	require.NoError(t, err)
+	require.Empty(t, list)
+
+	first, err := m.Create(ctx)
+	require.NoError(t, err)
+	require.Equal(t, int64(2), first.Size)
+	require.WithinDuration(t, time.Now(), first.CreatedAt, time.Minute)
+
+	// Names have millisecond precision.
+	time.Sleep(2 * time.Millisecond)
+	snapshotter.data = "v22"
+	second, err := m.Create(ctx)
+	require.NoError(t, err)
+
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "notes.txt"), []byte("x"), 0o600))
+
+	list, err = m.List()
+	require.NoError(t, err)
+	require.Equal(t, []backup.Snapshot{second, first}, list)
+
+	require.Zero(t, cache.flushes)
+	require.NoError(t, m.Restore(ctx, first.Name))
+	require.Equal(t, "v1", snapshotter.restored)
+	require.Equal(t, 1, cache.flushes)
+	require.Equal(t, 1, brokenCache.flushes)
+
+	for _, name := range []string{"notes.txt", "../backups/" + first.Name, "url-shortener-20000101-000000.000.db"} {
+		_, err := m.Path(name)
+		require.ErrorIs(t, err, backup.ErrNotFound, name)
+		require.ErrorIs(t, m.Restore(ctx, name), backup.ErrNotFound, name)
+	}
+	// Caches are left alone when nothing was restored.
+	require.Equal(t, 1, cache.flushes)
+}
+
+func TestManager_Run(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	m := backup.New(slogdiscard.NewDiscardLogger(), &fileSnapshotter{data: "v1"}, t.TempDir(), 2)
+
+	done := make(chan struct{})
+	go func() {
+		m.Run(ctx, 5*time.Millisecond)
+		close(done)
+	}()
+
+	// The oldest snapshots are removed once there are more than two, so
+	// the first one eventually goes.
+	var first string
+	require.Eventually(t, func() bool {
+		list, err := m.List()
+		require.NoError(t, err)
+		if first == "" && len(list) > 0 {
+			first = list[len(list)-1].Name
+		}
+		return len(list) == 2 && list[1].Name != first
+	}, 5*time.Second, 5*time.Millisecond)
+
+	cancel()
+	<-done
+
+	list, err := m.List()
+	require.NoError(t, err)
+	require.Len(t, list, 2)
+}
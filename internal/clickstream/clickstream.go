@@ -0,0 +1,89 @@
+// Package clickstream fans click events out to live subscribers, such as
+// Server-Sent Events connections. It keeps no history: a subscriber only
+// gets the clicks made while it is subscribed.
+package clickstream
+
+import (
+	"sync"
+
+	"url-shortener/internal/webhook"
+)
+
+// Hub receives link events as a notifier of the notifying storage and
+// passes the clicks on to subscribers.
+type Hub struct {
+	buffer int
+
+	mu     sync.Mutex
+	subs   map[*subscription]struct{}
+	closed bool
+}
+
+type subscription struct {
+	alias string
+	ch    chan webhook.Event
+}
+
+// New returns a hub whose subscribers can fall behind by buffer clicks;
+// further clicks are dropped for them until they catch up.
+func New(buffer int) *Hub {
+	return &Hub{
+		buffer: max(buffer, 1),
+		subs:   make(map[*subscription]struct{}),
+	}
+}
+
+// Notify passes a link_clicked event to the subscribers of its alias and
+// of all links without blocking. Other events are ignored.
+func (h *Hub) Notify(event webhook.Event) {
+	if event.Type != webhook.EventLinkClicked {
+		return
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	for sub := range h.subs {
+		if sub.alias != "" && sub.alias != event.Alias {
+			continue
+		}
+		select {
+		case sub.ch <- event:
+		default:
+		}
+	}
+}
+
+// Subscribe returns the clicks on alias, or on every link if alias is
+// empty. The channel is closed when the hub is. cancel ends the
+// subscription and must be called once it is no longer read.
+func (h *Hub) Subscribe(alias string) (clicks <-chan webhook.Event, cancel func()) {
+	sub := &subscription{alias: alias, ch: make(chan webhook.Event, h.buffer)}
+
+	h.mu.Lock()
+	if h.closed {
+		close(sub.ch)
+	} else {
+		h.subs[sub] = struct{}{}
+	}
+	h.mu.Unlock()
+
+	return sub.ch, func() {
+		h.mu.Lock()
+		delete(h.subs, sub)
+		h.mu.Unlock()
+	}
+}
+
+// Close closes the channels of all subscribers, so streams end before the
+// server shuts down instead of holding it up.
+func (h *Hub) Close() {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	for sub := range h.subs {
+		close(sub.ch)
+	}
+	clear(h.subs)
+	h.closed = true
+}
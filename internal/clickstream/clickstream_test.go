@@ -0,0 +1,58 @@
+package clickstream_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"url-shortener/internal/clickstream"
+	"url-shortener/internal/webhook"
+)
+
+func TestHub(t *testing.T) {
+	hub := clickstream.New(2)
+
+	one, cancelOne := hub.Subscribe("one")
+	all, cancelAll := hub.Subscribe("")
+
+	hub.Notify(webhook.Event{Type: webhook.EventLinkClicked, Alias: "one"})
+	hub.Notify(webhook.Event{Type: webhook.EventLinkClicked, Alias: "two"})
+	// Only clicks are streamed.
+	hub.Notify(webhook.Event{Type: webhook.EventLinkCreated, Alias: "one"})
+
+	require.Equal(t, "one", (<-one).Alias)
+	require.Empty(t, one)
+	require.Equal(t, "one", (<-all).Alias)
+	require.Equal(t, "two", (<-all).Alias)
+
+	// A subscriber that falls behind loses clicks instead of blocking.
+	for range 3 {
+		hub.Notify(webhook.Event{Type: webhook.EventLinkClicked, Alias: "one"})
+	}
+	require.Len(t, one, 2)
+
+	// Nothing is sent after cancel.
+	for range 2 {
+		<-all
+	}
+	cancelAll()
+	hub.Notify(webhook.Event{Type: webhook.EventLinkClicked, Alias: "two"})
+	require.Empty(t, all)
+	cancelOne()
+}
+
+func TestHub_Close(t *testing.T) {
+	hub := clickstream.New(1)
+
+	clicks, cancel := hub.Subscribe("one")
+	defer cancel()
+
+	hub.Close()
+	_, ok := <-clicks
+	require.False(t, ok)
+
+	clicks, cancel = hub.Subscribe("")
+	defer cancel()
+	_, ok = <-clicks
+	require.False(t, ok)
+}
@@ -1,43 +1,1013 @@
 package config
 
 import (
+	"errors"
+	"fmt"
+	"io/fs"
 	"log"
+	"log/slog"
+	"net"
 	"os"
+	"slices"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/ilyakaznacheev/cleanenv"
+	"golang.org/x/crypto/bcrypt"
+
+	"url-shortener/internal/lib/alias"
+	"url-shortener/internal/lib/captcha"
+	"url-shortener/internal/lib/encrypt"
 )
 
+// Every field can be overridden by an environment variable named after its
+// YAML path, e.g. HTTP_SERVER_ADDRESS for http_server.address. Variables
+// take precedence over the config file.
 type Config struct {
-	Env         string `yaml:"env" env-default:"local"`
-	StoragePath string `yaml:"storage_path" env-required:"true"`
-	HTTPServer  `yaml:"http_server"`
+	Env         string `yaml:"env" env:"ENV" env-default:"local"`
+	StoragePath string `yaml:"storage_path" env:"STORAGE_PATH"`
+	// BaseURL is the public address of the service, e.g. https://sho.rt,
+	// that short_url in responses starts with; empty takes it from the
+	// request.
+	BaseURL string `yaml:"base_url" env:"BASE_URL"`
+	// ShortDomains are the host names the service answers on, the first
+	// being the default. Links are saved on one of them and redirect only
+	// on it; empty serves every link on any host.
+	ShortDomains []string `yaml:"short_domains" env:"SHORT_DOMAINS" env-separator:","`
+	Storage      `yaml:"storage"`
+	HTTPServer   `yaml:"http_server"`
+	GRPCServer   `yaml:"grpc_server"`
+	Legal        `yaml:"legal"`
+	Reaper       `yaml:"reaper"`
+	Scheduler    `yaml:"scheduler"`
+	Backup       `yaml:"backup"`
+	Auth         `yaml:"auth"`
+	RateLimit    `yaml:"rate_limit"`
+	Quota        `yaml:"quota"`
+	Anonymous    `yaml:"anonymous"`
+	Idempotency  `yaml:"idempotency"`
+	Alias        `yaml:"alias"`
+	Domains      `yaml:"domains"`
+	Redirect     `yaml:"redirect"`
+	GeoIP        `yaml:"geoip"`
+	Bots         `yaml:"bots"`
+	Metadata     `yaml:"metadata"`
+	Reputation   `yaml:"reputation"`
+	LinkCheck    `yaml:"link_check"`
+	Webhooks     `yaml:"webhooks"`
+	EventBus     `yaml:"event_bus"`
+	Telegram     `yaml:"telegram"`
+	Feed         `yaml:"feed"`
+	Cache        `yaml:"cache"`
+	AccessLog    `yaml:"access_log"`
+	Tracing      `yaml:"tracing"`
+	CORS         `yaml:"cors"`
+	Diagnostics  `yaml:"diagnostics"`
+	ErrorReport  `yaml:"error_report"`
+	Log          `yaml:"log"`
+	Privacy      `yaml:"privacy"`
+	Encryption   `yaml:"encryption"`
+}
+
+const (
+	StorageSQLite   = "sqlite"
+	StoragePostgres = "postgres"
+	StorageRedis    = "redis"
+	StorageMemory   = "memory"
+)
+
+type Storage struct {
+	Type     string `yaml:"type" env:"STORAGE_TYPE" env-default:"sqlite"`
+	SQLite   `yaml:"sqlite"`
+	Postgres `yaml:"postgres"`
+	Redis    `yaml:"redis"`
+}
+
+// SQLite tunes the connections to the database at storage_path. The
+// defaults let reads go on during writes and make concurrent writers wait
+// for each other instead of failing with "database is locked".
+type SQLite struct {
+	// JournalMode is DELETE, TRUNCATE, PERSIST, MEMORY, WAL or OFF.
+	JournalMode string `yaml:"journal_mode" env:"STORAGE_SQLITE_JOURNAL_MODE" env-default:"WAL"`
+	// BusyTimeout is how long a query waits for a lock held by another
+	// connection.
+	BusyTimeout time.Duration `yaml:"busy_timeout" env:"STORAGE_SQLITE_BUSY_TIMEOUT" env-default:"5s"`
+	// Synchronous is OFF, NORMAL, FULL or EXTRA; NORMAL is safe with WAL.
+	Synchronous  string `yaml:"synchronous" env:"STORAGE_SQLITE_SYNCHRONOUS" env-default:"NORMAL"`
+	MaxOpenConns int    `yaml:"max_open_conns" env:"STORAGE_SQLITE_MAX_OPEN_CONNS" env-default:"0"`
+}
+
+// POSTGRES_DSN and REDIS_PASSWORD are kept as aliases of the full names.
+type Postgres struct {
+	DSN             string        `yaml:"dsn" env:"STORAGE_POSTGRES_DSN,POSTGRES_DSN"`
+	MaxOpenConns    int           `yaml:"max_open_conns" env:"STORAGE_POSTGRES_MAX_OPEN_CONNS" env-default:"10"`
+	MaxIdleConns    int           `yaml:"max_idle_conns" env:"STORAGE_POSTGRES_MAX_IDLE_CONNS" env-default:"5"`
+	ConnMaxLifetime time.Duration `yaml:"conn_max_lifetime" env:"STORAGE_POSTGRES_CONN_MAX_LIFETIME" env-default:"30m"`
+}
+
+type Redis struct {
+	Addr     string `yaml:"addr" env:"STORAGE_REDIS_ADDR" env-default:"localhost:6379"`
+	Password string `yaml:"password" env:"STORAGE_REDIS_PASSWORD,REDIS_PASSWORD"`
+	DB       int    `yaml:"db" env:"STORAGE_REDIS_DB" env-default:"0"`
+	PoolSize int    `yaml:"pool_size" env:"STORAGE_REDIS_POOL_SIZE" env-default:"10"`
+	// TTL is applied to every saved alias; 0 keeps links forever.
+	TTL time.Duration `yaml:"ttl" env:"STORAGE_REDIS_TTL" env-default:"0s"`
 }
 
 type HTTPServer struct {
-	Address     string        `yaml:"address" env-default:"localhost:8080"`
-	Timeout     time.Duration `yaml:"timeout" env-default:"4s"`
-	IdleTimeout time.Duration `yaml:"idle_timeout" env-default:"60s"`
-	User        string        `yaml: user env-required: "true"`
-	Password    string        `yaml: password env-required: "true" env: "HTTP_SERVER_PASSWORD"`
+	Address string `yaml:"address" env:"HTTP_SERVER_ADDRESS" env-default:"localhost:8080"`
+	// Listen are more addresses served next to Address with the same
+	// routes: TCP host:port or unix:/path/to.sock for a Unix socket, e.g.
+	// for a reverse proxy on the same machine.
+	Listen []string `yaml:"listen" env:"HTTP_SERVER_LISTEN" env-separator:","`
+	// SocketMode is the octal file mode of Unix sockets.
+	SocketMode string `yaml:"socket_mode" env:"HTTP_SERVER_SOCKET_MODE" env-default:"0660"`
+	// Timeout is the read and write timeout unless ReadTimeout or
+	// WriteTimeout is set.
+	Timeout time.Duration `yaml:"timeout" env:"HTTP_SERVER_TIMEOUT" env-default:"4s"`
+	// ReadTimeout bounds reading a whole request, ReadHeaderTimeout its
+	// headers alone; slow clients are cut off once either runs out.
+	ReadTimeout       time.Duration `yaml:"read_timeout" env:"HTTP_SERVER_READ_TIMEOUT"`
+	ReadHeaderTimeout time.Duration `yaml:"read_header_timeout" env:"HTTP_SERVER_READ_HEADER_TIMEOUT" env-default:"2s"`
+	WriteTimeout      time.Duration `yaml:"write_timeout" env:"HTTP_SERVER_WRITE_TIMEOUT"`
+	IdleTimeout       time.Duration `yaml:"idle_timeout" env:"HTTP_SERVER_IDLE_TIMEOUT" env-default:"60s"`
+	// MaxHeaderBytes limits the request line and headers.
+	MaxHeaderBytes int `yaml:"max_header_bytes" env:"HTTP_SERVER_MAX_HEADER_BYTES" env-default:"65536"`
+	// MaxBodySize limits request bodies, in bytes, except for CSV imports,
+	// which have a limit of their own.
+	MaxBodySize int64 `yaml:"max_body_size" env:"HTTP_SERVER_MAX_BODY_SIZE" env-default:"1048576"`
+
+	// User is a built-in admin; Password is kept for compatibility, prefer
+	// PasswordHash, a bcrypt hash of the password (urlctl hash-password).
+	User         string `yaml:"user" env:"HTTP_SERVER_USER"`
+	Password     string `yaml:"password" env:"HTTP_SERVER_PASSWORD"`
+	PasswordHash string `yaml:"password_hash" env:"HTTP_SERVER_PASSWORD_HASH"`
+	// Users are more built-in accounts.
+	Users []Account `yaml:"users"`
+	// HtpasswdFile holds more built-in admins, made with htpasswd -B. It
+	// is read at start.
+	HtpasswdFile string `yaml:"htpasswd_file" env:"HTTP_SERVER_HTPASSWD_FILE"`
+
+	// ShutdownTimeout is how long in-flight requests may run after
+	// SIGINT/SIGTERM before they are cut off.
+	ShutdownTimeout time.Duration `yaml:"shutdown_timeout" env:"HTTP_SERVER_SHUTDOWN_TIMEOUT" env-default:"10s"`
+	// SwaggerUI serves the API docs at /docs; /openapi.json is always served.
+	SwaggerUI   bool `yaml:"swagger_ui" env:"HTTP_SERVER_SWAGGER_UI" env-default:"false"`
+	TLS         `yaml:"tls"`
+	Compression `yaml:"compression"`
+}
+
+// Compression compresses responses of Types with gzip or deflate for
+// clients that accept either.
+type Compression struct {
+	Enabled bool `yaml:"enabled" env:"HTTP_SERVER_COMPRESSION_ENABLED" env-default:"true"`
+	// MinSize is the smallest body, in bytes, worth compressing.
+	MinSize int `yaml:"min_size" env:"HTTP_SERVER_COMPRESSION_MIN_SIZE" env-default:"1024"`
+	// GzipLevel and DeflateLevel go from 1, the fastest, to 9, the
+	// smallest output.
+	GzipLevel    int      `yaml:"gzip_level" env:"HTTP_SERVER_COMPRESSION_GZIP_LEVEL" env-default:"5"`
+	DeflateLevel int      `yaml:"deflate_level" env:"HTTP_SERVER_COMPRESSION_DEFLATE_LEVEL" env-default:"5"`
+	Types        []string `yaml:"types" env:"HTTP_SERVER_COMPRESSION_TYPES" env-separator:"," env-default:"application/json,text/csv,text/plain,text/html,image/svg+xml"`
+}
+
+// TLS serves HTTPS with either a certificate from files or one obtained
+// from Let's Encrypt; with neither the server speaks plain HTTP.
+type TLS struct {
+	CertFile string `yaml:"cert_file" env:"HTTP_SERVER_TLS_CERT_FILE"`
+	KeyFile  string `yaml:"key_file" env:"HTTP_SERVER_TLS_KEY_FILE"`
+	// Autocert obtains and renews certificates for Domains from Let's
+	// Encrypt; the server has to be reachable from the internet on :443.
+	Autocert bool     `yaml:"autocert" env:"HTTP_SERVER_TLS_AUTOCERT" env-default:"false"`
+	Domains  []string `yaml:"domains" env:"HTTP_SERVER_TLS_DOMAINS" env-separator:","`
+	// Email is given to Let's Encrypt for notices about certificates.
+	Email string `yaml:"email" env:"HTTP_SERVER_TLS_EMAIL"`
+	// CacheDir keeps obtained certificates across restarts.
+	CacheDir string `yaml:"cache_dir" env:"HTTP_SERVER_TLS_CACHE_DIR" env-default:"./storage/autocert"`
+	// RedirectAddress is a plain HTTP listener, usually :80, that
+	// redirects to HTTPS and answers ACME http-01 challenges; empty
+	// disables it.
+	RedirectAddress string `yaml:"redirect_address" env:"HTTP_SERVER_TLS_REDIRECT_ADDRESS"`
+}
+
+// Addresses returns Address followed by Listen.
+func (s HTTPServer) Addresses() []string {
+	return append([]string{s.Address}, s.Listen...)
+}
+
+// SocketFileMode returns SocketMode, which Load has validated.
+func (s HTTPServer) SocketFileMode() fs.FileMode {
+	mode, _ := strconv.ParseUint(s.SocketMode, 8, 32)
+	return fs.FileMode(mode)
+}
+
+// Enabled reports whether the server is to speak HTTPS.
+func (t TLS) Enabled() bool {
+	return t.Autocert || t.CertFile != ""
+}
+
+type GRPCServer struct {
+	// Address of the gRPC server; empty disables it.
+	Address string `yaml:"address" env:"GRPC_SERVER_ADDRESS"`
+}
+
+type Legal struct {
+	// NoticeURL is sent with 451 responses for aliases blocked after a takedown.
+	NoticeURL string `yaml:"notice_url" env:"LEGAL_NOTICE_URL"`
+}
+
+type Auth struct {
+	JWT `yaml:"jwt"`
+	// BasicFallback keeps accepting http_server.user/password via Basic Auth
+	// next to bearer tokens.
+	BasicFallback bool `yaml:"basic_fallback" env:"AUTH_BASIC_FALLBACK" env-default:"false"`
+	OIDC          `yaml:"oidc"`
+}
+
+// OIDC signs users in with an OpenID Connect provider; an empty Issuer
+// disables it.
+type OIDC struct {
+	Issuer       string `yaml:"issuer" env:"AUTH_OIDC_ISSUER"`
+	ClientID     string `yaml:"client_id" env:"AUTH_OIDC_CLIENT_ID"`
+	ClientSecret string `yaml:"client_secret" env:"AUTH_OIDC_CLIENT_SECRET"`
+	// RedirectURL is the callback registered with the provider; empty
+	// derives it from base_url.
+	RedirectURL string   `yaml:"redirect_url" env:"AUTH_OIDC_REDIRECT_URL"`
+	Scopes      []string `yaml:"scopes" env:"AUTH_OIDC_SCOPES" env-separator:"," env-default:"email,profile"`
+	// UsernameClaim names local users, e.g. email or preferred_username.
+	UsernameClaim string `yaml:"username_claim" env:"AUTH_OIDC_USERNAME_CLAIM" env-default:"email"`
+	// RoleClaim holds the groups or roles of the user, e.g. groups or
+	// realm_access.roles; users with one of AdminValues are admins. If
+	// EditorValues are set, users with none of them are viewers, otherwise
+	// everyone else is an editor.
+	RoleClaim    string   `yaml:"role_claim" env:"AUTH_OIDC_ROLE_CLAIM"`
+	AdminValues  []string `yaml:"admin_values" env:"AUTH_OIDC_ADMIN_VALUES" env-separator:","`
+	EditorValues []string `yaml:"editor_values" env:"AUTH_OIDC_EDITOR_VALUES" env-separator:","`
+	// AllowedDomains limits sign-in to emails of these domains.
+	AllowedDomains []string      `yaml:"allowed_domains" env:"AUTH_OIDC_ALLOWED_DOMAINS" env-separator:","`
+	Timeout        time.Duration `yaml:"timeout" env:"AUTH_OIDC_TIMEOUT" env-default:"10s"`
+}
+
+// JWT_SECRET is kept as an alias of AUTH_JWT_SECRET.
+type JWT struct {
+	Secret string        `yaml:"secret" env:"AUTH_JWT_SECRET,JWT_SECRET"`
+	TTL    time.Duration `yaml:"ttl" env:"AUTH_JWT_TTL" env-default:"1h"`
+}
+
+// RateLimit limits requests per user or, without one, per client IP. The
+// client IP is taken from the address of the connection only, as forwarding
+// headers can be forged: behind a reverse proxy all clients share one
+// bucket.
+type RateLimit struct {
+	// Save limits POST /url per user.
+	Save Limit `yaml:"save" env-prefix:"RATE_LIMIT_SAVE_"`
+	// Redirect limits GET /{alias} per client IP.
+	Redirect Limit `yaml:"redirect" env-prefix:"RATE_LIMIT_REDIRECT_"`
+	// Login limits POST /auth/login per client IP, to slow down password
+	// guessing.
+	Login Limit `yaml:"login" env-prefix:"RATE_LIMIT_LOGIN_"`
+}
+
+// Limit is a token bucket: RPS requests per second on average with bursts
+// of up to Burst requests. RPS 0 disables the limit.
+type Limit struct {
+	RPS   float64 `yaml:"rps" env:"RPS"`
+	Burst int     `yaml:"burst" env:"BURST"`
+}
+
+// Anonymous lets visitors without an account create links with POST /url
+// once they solve a captcha.
+type Anonymous struct {
+	Enabled bool `yaml:"enabled" env:"ANONYMOUS_ENABLED" env-default:"false"`
+	// LinkTTL is how long anonymous links live; ttl and expires_at can
+	// only make it shorter.
+	LinkTTL time.Duration `yaml:"link_ttl" env:"ANONYMOUS_LINK_TTL" env-default:"168h"`
+	// RateLimit limits anonymous links per client IP, on top of
+	// rate_limit.save. It is required.
+	RateLimit Limit   `yaml:"rate_limit" env-prefix:"ANONYMOUS_RATE_LIMIT_"`
+	Captcha   Captcha `yaml:"captcha"`
+}
+
+// Captcha verifies the tokens of a captcha widget on the client.
+type Captcha struct {
+	// Provider is "hcaptcha", "recaptcha" or "turnstile".
+	Provider string `yaml:"provider" env:"ANONYMOUS_CAPTCHA_PROVIDER"`
+	// Secret is the secret key of the site, not the public site key.
+	Secret string `yaml:"secret" env:"ANONYMOUS_CAPTCHA_SECRET"`
+	// VerifyURL replaces the siteverify endpoint of the provider, e.g. for
+	// a compatible self-hosted service.
+	VerifyURL string        `yaml:"verify_url" env:"ANONYMOUS_CAPTCHA_VERIFY_URL"`
+	Timeout   time.Duration `yaml:"timeout" env:"ANONYMOUS_CAPTCHA_TIMEOUT" env-default:"5s"`
+}
+
+// Alias restricts aliases chosen by users and sets how the others are
+// generated. Generated aliases are not checked against the restrictions.
+type Alias struct {
+	// MinLength and MaxLength count characters; 0 means no limit.
+	MinLength int `yaml:"min_length" env:"ALIAS_MIN_LENGTH" env-default:"3"`
+	MaxLength int `yaml:"max_length" env:"ALIAS_MAX_LENGTH" env-default:"64"`
+	// Pattern is a regular expression the whole alias must match.
+	Pattern string `yaml:"pattern" env:"ALIAS_PATTERN" env-default:"[A-Za-z0-9_-]+"`
+	// Reserved words cannot be used as aliases, in any case. The first
+	// segments of the service's own routes are always reserved.
+	Reserved []string `yaml:"reserved" env:"ALIAS_RESERVED" env-separator:"," env-default:"admin,api,static,assets,login,logout,help"`
+
+	// Strategy is "random" or "sequential"; sequential aliases encode a
+	// number from the storage's link ID sequence and never collide.
+	Strategy string `yaml:"strategy" env:"ALIAS_STRATEGY" env-default:"random"`
+	// GeneratedLength is the length of random aliases. Alphabet is used by
+	// both strategies; dropping look-alike characters such as 0/O and 1/l
+	// makes aliases easier to retype.
+	GeneratedLength int    `yaml:"generated_length" env:"ALIAS_GENERATED_LENGTH" env-default:"6"`
+	Alphabet        string `yaml:"alphabet" env:"ALIAS_ALPHABET" env-default:"ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz0123456789"`
+	// FilterBadWords regenerates aliases containing a word of the built-in
+	// list or of BadWords, also spelled with digits for letters.
+	FilterBadWords bool     `yaml:"filter_bad_words" env:"ALIAS_FILTER_BAD_WORDS" env-default:"true"`
+	BadWords       []string `yaml:"bad_words" env:"ALIAS_BAD_WORDS" env-separator:","`
+	// SkipConfusable regenerates aliases with characters that are easy to
+	// misread or mishear, such as 0/O, 1/l/I and rn, which looks like m.
+	SkipConfusable bool `yaml:"skip_confusable" env:"ALIAS_SKIP_CONFUSABLE" env-default:"true"`
+	// CaseInsensitive saves and looks up aliases in lower case, so /Abc and
+	// /abc are the same link.
+	CaseInsensitive bool `yaml:"case_insensitive" env:"ALIAS_CASE_INSENSITIVE" env-default:"false"`
+
+	Signing AliasSigning `yaml:"signing"`
+}
+
+// AliasSigning appends an HMAC signature to every new alias, so that
+// guessed or forged aliases are rejected without a storage lookup.
+type AliasSigning struct {
+	// Key is the HMAC secret, at least 16 characters; empty disables
+	// signing. Changing it breaks every signed link.
+	Key string `yaml:"key" env:"ALIAS_SIGNING_KEY"`
+	// Length is the number of signature characters, 4 to 16.
+	Length int `yaml:"length" env:"ALIAS_SIGNING_LENGTH" env-default:"6"`
+	// Enforce answers unsigned aliases with 404. Turn it off while links
+	// created before signing are still in use.
+	Enforce bool `yaml:"enforce" env:"ALIAS_SIGNING_ENFORCE" env-default:"true"`
+}
+
+// Domains restricts the hosts links may point to. Entries are host names
+// where * matches any characters, e.g. *.example.com for all subdomains.
+type Domains struct {
+	// Allow, if not empty, lists the only hosts that can be shortened.
+	Allow []string `yaml:"allow" env:"DOMAINS_ALLOW" env-separator:","`
+	// Block lists hosts that cannot be shortened; it wins over Allow.
+	Block []string `yaml:"block" env:"DOMAINS_BLOCK" env-separator:","`
+}
+
+type Redirect struct {
+	// Type is the HTTP status of redirects for links created without a
+	// redirect_type: 301 or 308 for permanent links, 302 or 307 for links
+	// that may change.
+	Type int `yaml:"type" env:"REDIRECT_TYPE" env-default:"302"`
+	// RenameForward is how long an alias renamed with keep_redirect keeps
+	// redirecting to the new one; 0 disables such forwards.
+	RenameForward time.Duration `yaml:"rename_forward" env:"REDIRECT_RENAME_FORWARD" env-default:"720h"`
+	// CacheMaxAge is how long browsers and CDNs may cache redirects of
+	// links created without a cache_max_age; 0 sends no Cache-Control.
+	CacheMaxAge time.Duration `yaml:"cache_max_age" env:"REDIRECT_CACHE_MAX_AGE" env-default:"0s"`
+	// NotActiveURL and ExpiredURL are pages visitors of links outside their
+	// activation window or expiry are redirected to; empty answers with an
+	// error instead.
+	NotActiveURL string `yaml:"not_active_url" env:"REDIRECT_NOT_ACTIVE_URL"`
+	ExpiredURL   string `yaml:"expired_url" env:"REDIRECT_EXPIRED_URL"`
+	// ErrorTemplate is an html/template file for the error pages browsers
+	// get for unknown and unavailable links; empty uses the built-in page.
+	ErrorTemplate string `yaml:"error_template" env:"REDIRECT_ERROR_TEMPLATE"`
+}
+
+// GeoIP resolves visitors' countries for links with geo targets and click
+// stats.
+type GeoIP struct {
+	// Database is a MaxMind GeoIP2 or GeoLite2 Country or City database,
+	// the City edition adding cities to clicks; empty disables geo
+	// targeting and every visitor gets the default URL.
+	Database string `yaml:"database" env:"GEOIP_DATABASE"`
+}
+
+// Bots tells the clicks of crawlers and scripts, which stats leave out
+// unless asked for, by their User-Agent.
+type Bots struct {
+	// ListFile adds User-Agent substrings of known bots, one per line, to
+	// the built-in ones.
+	ListFile string `yaml:"list_file" env:"BOTS_LIST_FILE"`
+}
+
+// Metadata controls fetching of the target page's title and Open Graph
+// tags when a link is saved.
+type Metadata struct {
+	Enabled bool `yaml:"enabled" env:"METADATA_ENABLED" env-default:"false"`
+	// Timeout bounds the whole fetch, so a slow site delays saving the
+	// link by at most this much.
+	Timeout time.Duration `yaml:"timeout" env:"METADATA_TIMEOUT" env-default:"3s"`
+}
+
+const (
+	ReputationBlocklist    = "blocklist"
+	ReputationSafeBrowsing = "safebrowsing"
+)
+
+// Reputation screens link targets for malware and phishing.
+type Reputation struct {
+	// Provider is "blocklist", "safebrowsing" or empty to disable screening.
+	Provider string `yaml:"provider" env:"REPUTATION_PROVIDER"`
+	// BlocklistPath is a file with one domain or URL prefix per line.
+	BlocklistPath string `yaml:"blocklist_path" env:"REPUTATION_BLOCKLIST_PATH"`
+	// SafeBrowsingKey is a Google Safe Browsing API key.
+	SafeBrowsingKey string        `yaml:"safe_browsing_key" env:"REPUTATION_SAFE_BROWSING_KEY"`
+	Timeout         time.Duration `yaml:"timeout" env:"REPUTATION_TIMEOUT" env-default:"3s"`
+	// RescanInterval is how often all links are checked again; 0 disables
+	// rescans.
+	RescanInterval time.Duration `yaml:"rescan_interval" env:"REPUTATION_RESCAN_INTERVAL" env-default:"0s"`
+}
+
+// LinkCheck periodically requests the destination of every link to find
+// the ones that stopped working.
+type LinkCheck struct {
+	// Interval is how often all links are checked; 0 disables checks.
+	Interval time.Duration `yaml:"interval" env:"LINK_CHECK_INTERVAL" env-default:"0s"`
+	// Timeout limits a single request, redirects included.
+	Timeout time.Duration `yaml:"timeout" env:"LINK_CHECK_TIMEOUT" env-default:"10s"`
+	// Concurrency is how many destinations are requested at once.
+	Concurrency int `yaml:"concurrency" env:"LINK_CHECK_CONCURRENCY" env-default:"8"`
+	// MaxRedirects is how many redirects a destination may take before it
+	// counts as a redirect loop.
+	MaxRedirects int `yaml:"max_redirects" env:"LINK_CHECK_MAX_REDIRECTS" env-default:"10"`
+}
+
+// Webhooks POST link events to external endpoints; with no endpoints
+// nothing is sent.
+type Webhooks struct {
+	Endpoints []Webhook `yaml:"endpoints"`
+	// Timeout limits a single delivery attempt.
+	Timeout time.Duration `yaml:"timeout" env:"WEBHOOKS_TIMEOUT" env-default:"5s"`
+	// MaxAttempts counts the first attempt; retries wait Backoff, doubled
+	// after every failure.
+	MaxAttempts int           `yaml:"max_attempts" env:"WEBHOOKS_MAX_ATTEMPTS" env-default:"5"`
+	Backoff     time.Duration `yaml:"backoff" env:"WEBHOOKS_BACKOFF" env-default:"1s"`
+	// QueueSize is how many deliveries can wait; events beyond it are dropped.
+	QueueSize int `yaml:"queue_size" env:"WEBHOOKS_QUEUE_SIZE" env-default:"1000"`
+}
+
+type Webhook struct {
+	URL string `yaml:"url"`
+	// Secret signs deliveries with HMAC-SHA256; empty sends them unsigned.
+	Secret string `yaml:"secret"`
+	// Events the endpoint receives: link_created, link_deleted,
+	// link_clicked, link_expired, link_broken. Empty means all of them.
+	Events []string `yaml:"events"`
+}
+
+const (
+	EventBusKafka = "kafka"
+	EventBusNATS  = "nats"
+)
+
+// EventBus publishes link events as JSON to a message bus for downstream
+// analytics.
+type EventBus struct {
+	// Provider is "kafka", "nats" or empty to publish nothing.
+	Provider string `yaml:"provider" env:"EVENT_BUS_PROVIDER"`
+	// Brokers are the Kafka bootstrap servers, host:port.
+	Brokers []string `yaml:"brokers" env:"EVENT_BUS_BROKERS" env-separator:","`
+	// Topic is the Kafka topic; messages are keyed by alias.
+	Topic string `yaml:"topic" env:"EVENT_BUS_TOPIC" env-default:"url-shortener.events"`
+	// URL is the NATS server, e.g. nats://localhost:4222.
+	URL string `yaml:"url" env:"EVENT_BUS_URL"`
+	// Subject prefixes the NATS subjects; an event goes to
+	// "<subject>.<event type>".
+	Subject string `yaml:"subject" env:"EVENT_BUS_SUBJECT" env-default:"url-shortener"`
+	// Events to publish, as for webhooks. Empty means all of them.
+	Events    []string      `yaml:"events" env:"EVENT_BUS_EVENTS" env-separator:"," env-default:"link_created,link_clicked"`
+	Timeout   time.Duration `yaml:"timeout" env:"EVENT_BUS_TIMEOUT" env-default:"5s"`
+	QueueSize int           `yaml:"queue_size" env:"EVENT_BUS_QUEUE_SIZE" env-default:"10000"`
+}
+
+// Telegram runs a bot that shortens links sent to it; without a token
+// there is no bot.
+type Telegram struct {
+	Token string `yaml:"token" env:"TELEGRAM_TOKEN"`
+	// Users may use the bot; their links are saved for the user they are
+	// mapped to. Others are told their ID so an admin can add them.
+	Users []TelegramUser `yaml:"users"`
+	// PollTimeout is how long a request for new messages waits.
+	PollTimeout time.Duration `yaml:"poll_timeout" env:"TELEGRAM_POLL_TIMEOUT" env-default:"30s"`
+	// APIURL is the Bot API server, for a self-hosted one.
+	APIURL string `yaml:"api_url" env:"TELEGRAM_API_URL" env-default:"https://api.telegram.org"`
+}
+
+type TelegramUser struct {
+	// ID is the numeric Telegram user ID.
+	ID       int64  `yaml:"id"`
+	Username string `yaml:"username"`
+}
+
+// Feed serves the newest links as RSS or Atom at /urls/feed, so they can
+// be followed in a feed reader. Password-protected, blocked and inactive
+// links are never listed.
+type Feed struct {
+	Enabled bool `yaml:"enabled" env:"FEED_ENABLED" env-default:"false"`
+	// Public serves the feed without authentication, with the links of
+	// every user; otherwise it needs a login and lists what the user may
+	// see.
+	Public bool `yaml:"public" env:"FEED_PUBLIC" env-default:"false"`
+	// Size is how many of the newest links the feed holds, 1 to 100.
+	Size int `yaml:"size" env:"FEED_SIZE" env-default:"20"`
+}
+
+// CORS lets browser frontends on other origins call the API.
+type CORS struct {
+	// AllowedOrigins are origins such as https://dashboard.example.com,
+	// with at most one * matching any characters; empty disables CORS.
+	AllowedOrigins []string `yaml:"allowed_origins" env:"CORS_ALLOWED_ORIGINS" env-separator:","`
+	AllowedMethods []string `yaml:"allowed_methods" env:"CORS_ALLOWED_METHODS" env-separator:"," env-default:"GET,POST,PATCH,DELETE"`
+	AllowedHeaders []string `yaml:"allowed_headers" env:"CORS_ALLOWED_HEADERS" env-separator:"," env-default:"Authorization,Content-Type,X-Api-Key"`
+	// ExposedHeaders are response headers scripts may read.
+	ExposedHeaders []string `yaml:"exposed_headers" env:"CORS_EXPOSED_HEADERS" env-separator:"," env-default:"Retry-After,Content-Disposition"`
+	// AllowCredentials lets browsers send cookies and Basic Auth; it
+	// cannot be used with the * origin.
+	AllowCredentials bool `yaml:"allow_credentials" env:"CORS_ALLOW_CREDENTIALS" env-default:"false"`
+	// MaxAge is how long browsers may cache a preflight response.
+	MaxAge time.Duration `yaml:"max_age" env:"CORS_MAX_AGE" env-default:"10m"`
+}
+
+// Diagnostics serves pprof profiles, expvar and build information on an
+// address of its own; empty Address disables it. Profiles reveal the
+// internals of the service, so Address has to be a loopback or private
+// one or a Unix socket unless AllowPublic is set.
+type Diagnostics struct {
+	Address     string `yaml:"address" env:"DIAGNOSTICS_ADDRESS"`
+	AllowPublic bool   `yaml:"allow_public" env:"DIAGNOSTICS_ALLOW_PUBLIC" env-default:"false"`
+}
+
+// ErrorReport sends error log records and panics to Sentry or a service
+// compatible with it, such as GlitchTip; empty DSN disables it.
+type ErrorReport struct {
+	DSN string `yaml:"dsn" env:"ERROR_REPORT_DSN"`
+	// Environment tags the events; empty uses Env.
+	Environment string        `yaml:"environment" env:"ERROR_REPORT_ENVIRONMENT"`
+	Timeout     time.Duration `yaml:"timeout" env:"ERROR_REPORT_TIMEOUT" env-default:"5s"`
+	QueueSize   int           `yaml:"queue_size" env:"ERROR_REPORT_QUEUE_SIZE" env-default:"100"`
+}
+
+// Log formats.
+const (
+	LogFormatPretty = "pretty"
+	LogFormatText   = "text"
+	LogFormatJSON   = "json"
+)
+
+// Log is where and how the application logs are written. Empty Format and
+// Level keep what Env picks: pretty at debug level for local, JSON at debug
+// level for dev and at info level for prod.
+type Log struct {
+	Format string `yaml:"format" env:"LOG_FORMAT"`
+	// Level is debug, info, warn or error.
+	Level string `yaml:"level" env:"LOG_LEVEL"`
+	// Path is a file the logs are appended to; empty writes them to stdout.
+	Path     string      `yaml:"path" env:"LOG_PATH"`
+	Rotation LogRotation `yaml:"rotation"`
+}
+
+// LogRotation moves the log file aside when it grows over MaxSize bytes or
+// every Interval; zero disables either.
+type LogRotation struct {
+	MaxSize  int64         `yaml:"max_size" env:"LOG_ROTATION_MAX_SIZE" env-default:"104857600"`
+	Interval time.Duration `yaml:"interval" env:"LOG_ROTATION_INTERVAL" env-default:"0s"`
+	// MaxBackups is how many old files are kept and MaxAge for how long;
+	// zero keeps them all.
+	MaxBackups int           `yaml:"max_backups" env:"LOG_ROTATION_MAX_BACKUPS" env-default:"7"`
+	MaxAge     time.Duration `yaml:"max_age" env:"LOG_ROTATION_MAX_AGE" env-default:"0s"`
+}
+
+// Privacy strips personal data before it is logged or stored: client IPs
+// are anonymized and secrets in URL query strings redacted.
+type Privacy struct {
+	Enabled bool `yaml:"enabled" env:"PRIVACY_ENABLED" env-default:"false"`
+	// IPMode is truncate, which keeps the network part of an address, or
+	// hash.
+	IPMode string `yaml:"ip_mode" env:"PRIVACY_IP_MODE" env-default:"truncate"`
+	// IPSalt keys the hash; empty picks a random one at start.
+	IPSalt string `yaml:"ip_salt" env:"PRIVACY_IP_SALT"`
+	// SecretParams are query parameters redacted on top of the built-in
+	// ones, such as token, key and password.
+	SecretParams []string `yaml:"secret_params" env:"PRIVACY_SECRET_PARAMS" env-separator:","`
 }
 
+// Account is a built-in user that is not stored in the database.
+type Account struct {
+	Name         string `yaml:"name"`
+	PasswordHash string `yaml:"password_hash"`
+	// Role is admin, the default, editor or viewer; user, the name editors
+	// had before viewers existed, is still accepted.
+	Role string `yaml:"role"`
+}
+
+// Encryption encrypts destination URLs in storage with AES-GCM.
+type Encryption struct {
+	// Key is a base64 encoded 16, 24 or 32 byte key; empty disables
+	// encryption. Losing it makes the stored links unreadable.
+	Key string `yaml:"key" env:"ENCRYPTION_KEY"`
+}
+
+// validateAccounts checks the built-in users and defaults their role to
+// admin.
+func validateAccounts(cfg *HTTPServer) error {
+	if cfg.User == "" && len(cfg.Users) == 0 && cfg.HtpasswdFile == "" {
+		return errors.New("no users configured: set http_server.user, http_server.users or http_server.htpasswd_file")
+	}
+
+	names := map[string]bool{}
+	if cfg.User != "" {
+		switch {
+		case cfg.Password == "" && cfg.PasswordHash == "":
+			return errors.New("http_server.password_hash is required with http_server.user")
+		case cfg.Password != "" && cfg.PasswordHash != "":
+			return errors.New("http_server.password and http_server.password_hash are mutually exclusive")
+		case cfg.PasswordHash != "" && !bcryptHash(cfg.PasswordHash):
+			return errors.New("invalid http_server.password_hash: not a bcrypt hash")
+		}
+		names[cfg.User] = true
+	} else if cfg.Password != "" || cfg.PasswordHash != "" {
+		return errors.New("http_server.user is required with a password")
+	}
+
+	for i := range cfg.Users {
+		u := &cfg.Users[i]
+		if u.Name == "" {
+			return errors.New("http_server.users: name is required")
+		}
+		if names[u.Name] {
+			return fmt.Errorf("http_server.users: duplicate user %s", u.Name)
+		}
+		names[u.Name] = true
+		if !bcryptHash(u.PasswordHash) {
+			return fmt.Errorf("http_server.users: invalid password_hash of %s: not a bcrypt hash", u.Name)
+		}
+		switch u.Role {
+		case "":
+			u.Role = "admin"
+		case "admin", "editor", "viewer", "user":
+		default:
+			return fmt.Errorf("http_server.users: invalid role of %s: %s", u.Name, u.Role)
+		}
+	}
+
+	return nil
+}
+
+func bcryptHash(s string) bool {
+	_, err := bcrypt.Cost([]byte(s))
+	return err == nil
+}
+
+// private reports whether addr, a host:port or unix:/path, cannot be
+// reached from the internet. Host names other than localhost are not
+// resolved and count as public.
+func private(addr string) bool {
+	if strings.HasPrefix(addr, "unix:") {
+		return true
+	}
+
+	host, _, err := net.SplitHostPort(addr)
+	if err != nil {
+		return false
+	}
+	if host == "localhost" {
+		return true
+	}
+	ip := net.ParseIP(host)
+	return ip != nil && (ip.IsLoopback() || ip.IsPrivate())
+}
+
+// Tracing exports OpenTelemetry spans of HTTP requests and storage calls.
+type Tracing struct {
+	Enabled bool `yaml:"enabled" env:"TRACING_ENABLED" env-default:"false"`
+	// Endpoint is the host:port of an OTLP/gRPC collector.
+	Endpoint    string  `yaml:"endpoint" env:"TRACING_ENDPOINT" env-default:"localhost:4317"`
+	Insecure    bool    `yaml:"insecure" env:"TRACING_INSECURE" env-default:"false"`
+	ServiceName string  `yaml:"service_name" env:"TRACING_SERVICE_NAME" env-default:"url-shortener"`
+	SampleRatio float64 `yaml:"sample_ratio" env:"TRACING_SAMPLE_RATIO" env-default:"1"`
+}
+
+// AccessLog is a record per redirect, kept apart from the application
+// logs.
+type AccessLog struct {
+	Enabled bool `yaml:"enabled" env:"ACCESS_LOG_ENABLED" env-default:"false"`
+	// Path is a file the records are appended to as JSON lines; empty
+	// writes them to stdout.
+	Path string `yaml:"path" env:"ACCESS_LOG_PATH"`
+}
+
+// Cache keeps hot links in front of the storage, in process memory and,
+// for several instances, in Redis. Every instance has its own memory
+// cache, so a link changed on another instance may be served as it was for
+// up to TTL; the Redis cache is shared and always up to date.
+type Cache struct {
+	// Size is how many links are kept in memory; 0 disables the cache.
+	Size int           `yaml:"size" env:"CACHE_SIZE" env-default:"0"`
+	TTL  time.Duration `yaml:"ttl" env:"CACHE_TTL" env-default:"1m"`
+
+	// RedisAddr is the Redis server of the shared cache; empty disables it.
+	RedisAddr     string        `yaml:"redis_addr" env:"CACHE_REDIS_ADDR"`
+	RedisPassword string        `yaml:"redis_password" env:"CACHE_REDIS_PASSWORD"`
+	RedisDB       int           `yaml:"redis_db" env:"CACHE_REDIS_DB" env-default:"0"`
+	RedisTTL      time.Duration `yaml:"redis_ttl" env:"CACHE_REDIS_TTL" env-default:"10m"`
+}
+
+// Quota limits the links of every user and API key; 0 is unlimited.
+type Quota struct {
+	// MaxLinks is how many links one may own at a time.
+	MaxLinks int64 `yaml:"max_links" env:"QUOTA_MAX_LINKS" env-default:"0"`
+	// MaxPerDay is how many links one may create per UTC day.
+	MaxPerDay int64 `yaml:"max_per_day" env:"QUOTA_MAX_PER_DAY" env-default:"0"`
+}
+
+// Idempotency lets clients retry POST /url with the same Idempotency-Key
+// header without creating the link twice.
+type Idempotency struct {
+	// TTL is how long the response to a key is replayed; 0 ignores the
+	// header.
+	TTL time.Duration `yaml:"ttl" env:"IDEMPOTENCY_TTL" env-default:"24h"`
+}
+
+type Reaper struct {
+	// Interval between purges of expired links; 0 disables the reaper.
+	Interval time.Duration `yaml:"interval" env:"REAPER_INTERVAL" env-default:"1m"`
+	// DeletedGrace is how long deleted links can be restored and keep
+	// their aliases before the reaper purges them; 0 keeps them forever.
+	DeletedGrace time.Duration `yaml:"deleted_grace" env:"REAPER_DELETED_GRACE" env-default:"720h"`
+}
+
+// Scheduler sets how often maintenance jobs run; 0 disables a job. Expired
+// links are purged every reaper.interval.
+type Scheduler struct {
+	// VacuumInterval is how often the database gives back the space of
+	// deleted rows; SQLite and PostgreSQL only.
+	VacuumInterval time.Duration `yaml:"vacuum_interval" env:"SCHEDULER_VACUUM_INTERVAL" env-default:"24h"`
+	// IdempotencyInterval is how often expired Idempotency-Key responses
+	// are removed; Redis expires them by itself.
+	IdempotencyInterval time.Duration `yaml:"idempotency_interval" env:"SCHEDULER_IDEMPOTENCY_INTERVAL" env-default:"1h"`
+	// RollupInterval is how often clicks of past days are summed up into
+	// daily counts for GetStats. Only days that ended are summed up, so
+	// runs after the first one of a day do nothing.
+	RollupInterval time.Duration `yaml:"rollup_interval" env:"SCHEDULER_ROLLUP_INTERVAL" env-default:"1h"`
+}
+
+// Backup keeps snapshots of the SQLite database; other storages have
+// their own tools, such as pg_dump.
+type Backup struct {
+	// Dir is where snapshots are written; empty disables backups.
+	Dir string `yaml:"dir" env:"BACKUP_DIR"`
+	// Interval between scheduled snapshots; 0 takes them on demand only.
+	Interval time.Duration `yaml:"interval" env:"BACKUP_INTERVAL" env-default:"0s"`
+	// Keep is how many snapshots scheduled backups leave; 0 keeps all.
+	Keep int `yaml:"keep" env:"BACKUP_KEEP" env-default:"7"`
+}
+
+// MustLoad reads the config file named by CONFIG_PATH and applies
+// environment overrides on top. Without CONFIG_PATH the config is read from
+// the environment alone.
 func MustLoad() *Config {
-	configPath := os.Getenv("CONFIG_PATH")
+	cfg, err := Load(os.Getenv("CONFIG_PATH"))
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	return cfg
+}
+
+// Load is MustLoad with the config path passed explicitly; an empty path
+// means environment variables only.
+func Load(configPath string) (*Config, error) {
+	var cfg Config
+
 	if configPath == "" {
-		log.Fatal("CONFIG_PATH is not set")
+		if err := cleanenv.ReadEnv(&cfg); err != nil {
+			return nil, fmt.Errorf("cant read config from env: %w", err)
+		}
+	} else {
+		// check is file exists
+		if _, err := os.Stat(configPath); os.IsNotExist(err) {
+			return nil, fmt.Errorf("config file does not exist: %s", configPath)
+		}
+
+		if err := cleanenv.ReadConfig(configPath, &cfg); err != nil {
+			return nil, fmt.Errorf("cant read config: %w", err)
+		}
 	}
 
-	// check is file exists
-	if _, err := os.Stat(configPath); os.IsNotExist(err) {
-		log.Fatal("config file does not exist: %s", configPath)
+	switch cfg.Storage.Type {
+	case StorageSQLite:
+		if cfg.StoragePath == "" {
+			return nil, errors.New("storage_path is required for sqlite storage")
+		}
+		switch strings.ToUpper(cfg.SQLite.JournalMode) {
+		case "DELETE", "TRUNCATE", "PERSIST", "MEMORY", "WAL", "OFF":
+		default:
+			return nil, fmt.Errorf("unknown sqlite journal mode: %s", cfg.SQLite.JournalMode)
+		}
+		switch strings.ToUpper(cfg.SQLite.Synchronous) {
+		case "OFF", "NORMAL", "FULL", "EXTRA":
+		default:
+			return nil, fmt.Errorf("unknown sqlite synchronous mode: %s", cfg.SQLite.Synchronous)
+		}
+		if cfg.SQLite.BusyTimeout < 0 || cfg.SQLite.MaxOpenConns < 0 {
+			return nil, errors.New("sqlite busy_timeout and max_open_conns cannot be negative")
+		}
+	case StoragePostgres:
+		if cfg.Postgres.DSN == "" {
+			return nil, errors.New("storage.postgres.dsn is required for postgres storage")
+		}
+	case StorageRedis, StorageMemory:
+	default:
+		return nil, fmt.Errorf("unknown storage type: %s", cfg.Storage.Type)
 	}
 
-	var cfg Config
+	switch cfg.Alias.Strategy {
+	case "random", "sequential":
+	default:
+		return nil, fmt.Errorf("unknown alias strategy: %s", cfg.Alias.Strategy)
+	}
+	// Sequential numbers would collide once their aliases are lower-cased.
+	if cfg.Alias.CaseInsensitive && cfg.Alias.Strategy == "sequential" && hasCaseVariants(cfg.Alias.Alphabet) {
+		return nil, errors.New("alias.case_insensitive with the sequential strategy needs an alphabet without both cases of a letter")
+	}
+	if cfg.Alias.Signing.Key != "" {
+		if len(cfg.Alias.Signing.Key) < 16 {
+			return nil, errors.New("alias.signing.key must be at least 16 characters")
+		}
+		if cfg.Alias.Signing.Length < alias.MinSignatureLength || cfg.Alias.Signing.Length > alias.MaxSignatureLength {
+			return nil, fmt.Errorf("alias.signing.length must be %d to %d", alias.MinSignatureLength, alias.MaxSignatureLength)
+		}
+	}
+
+	switch cfg.Redirect.Type {
+	case 301, 302, 307, 308:
+	default:
+		return nil, fmt.Errorf("unknown redirect type: %d", cfg.Redirect.Type)
+	}
+
+	switch cfg.Reputation.Provider {
+	case "":
+	case ReputationBlocklist:
+		if cfg.Reputation.BlocklistPath == "" {
+			return nil, errors.New("reputation.blocklist_path is required for blocklist provider")
+		}
+	case ReputationSafeBrowsing:
+		if cfg.Reputation.SafeBrowsingKey == "" {
+			return nil, errors.New("reputation.safe_browsing_key is required for safebrowsing provider")
+		}
+	default:
+		return nil, fmt.Errorf("unknown reputation provider: %s", cfg.Reputation.Provider)
+	}
+
+	if cfg.LinkCheck.Interval > 0 && (cfg.LinkCheck.Concurrency < 1 || cfg.LinkCheck.MaxRedirects < 1) {
+		return nil, errors.New("link_check.concurrency and max_redirects must be positive")
+	}
+
+	switch cfg.EventBus.Provider {
+	case "":
+	case EventBusKafka:
+		if len(cfg.EventBus.Brokers) == 0 {
+			return nil, errors.New("event_bus.brokers is required for kafka provider")
+		}
+	case EventBusNATS:
+		if cfg.EventBus.URL == "" {
+			return nil, errors.New("event_bus.url is required for nats provider")
+		}
+	default:
+		return nil, fmt.Errorf("unknown event bus provider: %s", cfg.EventBus.Provider)
+	}
+
+	if (cfg.TLS.CertFile == "") != (cfg.TLS.KeyFile == "") {
+		return nil, errors.New("http_server.tls.cert_file and key_file must be set together")
+	}
+	if cfg.TLS.Autocert {
+		if cfg.TLS.CertFile != "" {
+			return nil, errors.New("http_server.tls.autocert cannot be combined with cert_file")
+		}
+		if len(cfg.TLS.Domains) == 0 {
+			return nil, errors.New("http_server.tls.domains is required for autocert")
+		}
+	}
+
+	if cfg.HTTPServer.Timeout < 0 || cfg.HTTPServer.ReadTimeout < 0 || cfg.HTTPServer.ReadHeaderTimeout < 0 ||
+		cfg.HTTPServer.WriteTimeout < 0 || cfg.HTTPServer.IdleTimeout < 0 {
+		return nil, errors.New("http_server timeouts cannot be negative")
+	}
+	if mode, err := strconv.ParseUint(cfg.HTTPServer.SocketMode, 8, 32); err != nil || mode > 0o777 {
+		return nil, fmt.Errorf("invalid http_server.socket_mode: %s", cfg.HTTPServer.SocketMode)
+	}
+	if slices.Contains(cfg.HTTPServer.Addresses(), "") {
+		return nil, errors.New("http_server.address and listen cannot be empty")
+	}
+	if cfg.HTTPServer.MaxHeaderBytes <= 0 || cfg.HTTPServer.MaxBodySize <= 0 {
+		return nil, errors.New("http_server.max_header_bytes and max_body_size must be positive")
+	}
+
+	if cfg.Diagnostics.Address != "" && !cfg.Diagnostics.AllowPublic && !private(cfg.Diagnostics.Address) {
+		return nil, fmt.Errorf("diagnostics.address %s may be public; use a loopback or private address or set diagnostics.allow_public", cfg.Diagnostics.Address)
+	}
+
+	switch cfg.Log.Format {
+	case "", LogFormatPretty, LogFormatText, LogFormatJSON:
+	default:
+		return nil, fmt.Errorf("invalid log.format: %s", cfg.Log.Format)
+	}
+	if cfg.Log.Level != "" {
+		var level slog.Level
+		if err := level.UnmarshalText([]byte(cfg.Log.Level)); err != nil {
+			return nil, fmt.Errorf("invalid log.level: %s", cfg.Log.Level)
+		}
+	}
+	if r := cfg.Log.Rotation; r.MaxSize < 0 || r.Interval < 0 || r.MaxBackups < 0 || r.MaxAge < 0 {
+		return nil, errors.New("log.rotation limits cannot be negative")
+	}
+
+	if cfg.Privacy.Enabled && cfg.Privacy.IPMode != "truncate" && cfg.Privacy.IPMode != "hash" {
+		return nil, fmt.Errorf("invalid privacy.ip_mode: %s", cfg.Privacy.IPMode)
+	}
+
+	if cfg.Encryption.Key != "" {
+		key, err := encrypt.ParseKey(cfg.Encryption.Key)
+		if err != nil {
+			return nil, errors.New("invalid encryption.key: not base64")
+		}
+		if len(key) != 16 && len(key) != 24 && len(key) != 32 {
+			return nil, fmt.Errorf("invalid encryption.key: %d bytes, want 16, 24 or 32", len(key))
+		}
+	}
+
+	if cfg.Quota.MaxLinks < 0 || cfg.Quota.MaxPerDay < 0 {
+		return nil, errors.New("quota limits cannot be negative")
+	}
+
+	if cfg.Feed.Enabled && (cfg.Feed.Size < 1 || cfg.Feed.Size > 100) {
+		return nil, errors.New("feed.size must be from 1 to 100")
+	}
+
+	if cfg.Anonymous.Enabled {
+		if captcha.Endpoint(cfg.Anonymous.Captcha.Provider) == "" {
+			return nil, fmt.Errorf("unknown anonymous.captcha.provider: %q", cfg.Anonymous.Captcha.Provider)
+		}
+		if cfg.Anonymous.Captcha.Secret == "" {
+			return nil, errors.New("anonymous.captcha.secret is required")
+		}
+		if cfg.Anonymous.RateLimit.RPS <= 0 {
+			return nil, errors.New("anonymous.rate_limit.rps is required")
+		}
+		if cfg.Anonymous.LinkTTL <= 0 {
+			return nil, errors.New("anonymous.link_ttl must be positive")
+		}
+	}
+
+	if cfg.Backup.Dir != "" && cfg.Storage.Type != StorageSQLite {
+		return nil, errors.New("backup is only supported for sqlite storage")
+	}
+	if cfg.Backup.Interval > 0 && cfg.Backup.Dir == "" {
+		return nil, errors.New("backup.dir is required for scheduled backups")
+	}
+
+	if err := validateAccounts(&cfg.HTTPServer); err != nil {
+		return nil, err
+	}
+
+	if cfg.JWT.Secret == "" {
+		return nil, errors.New("auth.jwt.secret is required")
+	}
+
+	if o := cfg.Auth.OIDC; o.Issuer != "" {
+		if o.ClientID == "" {
+			return nil, errors.New("auth.oidc.client_id is required")
+		}
+		if o.RedirectURL == "" && cfg.BaseURL == "" {
+			return nil, errors.New("auth.oidc.redirect_url is required without base_url")
+		}
+		if o.UsernameClaim == "" {
+			return nil, errors.New("auth.oidc.username_claim is required")
+		}
+		if o.RoleClaim != "" && len(o.AdminValues) == 0 {
+			return nil, errors.New("auth.oidc.admin_values is required with role_claim")
+		}
+		if o.RoleClaim == "" && len(o.EditorValues) > 0 {
+			return nil, errors.New("auth.oidc.editor_values needs role_claim")
+		}
+	}
+
+	return &cfg, nil
+}
 
-	if err := cleanenv.ReadConfig(configPath, &cfg); err != nil {
-		log.Fatal("cant read config: %s", err)
+// hasCaseVariants reports whether alphabet has a letter in both cases.
+func hasCaseVariants(alphabet string) bool {
+	seen := make(map[rune]bool)
+	for _, c := range strings.ToLower(alphabet) {
+		if seen[c] {
+			return true
+		}
+		seen[c] = true
 	}
 
-	return &cfg
+	return false
 }
@@ -0,0 +1,545 @@
+package config_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"url-shortener/internal/config"
+)
+
+const testConfig = `
+env: "local"
+storage_path: "./storage.db"
+http_server:
+  address: "localhost:8082"
+  user: "myuser"
+  password: "mypass"
+auth:
+  jwt:
+    secret: "file-secret"
+rate_limit:
+  save:
+    rps: 5
+    burst: 20
+`
+
+func TestLoad_EnvOverridesFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	require.NoError(t, os.WriteFile(path, []byte(testConfig), 0o600))
+
+	t.Setenv("HTTP_SERVER_ADDRESS", "0.0.0.0:9000")
+	t.Setenv("STORAGE_PATH", "/data/storage.db")
+	t.Setenv("RATE_LIMIT_SAVE_BURST", "50")
+	t.Setenv("RATE_LIMIT_LOGIN_RPS", "0.5")
+	t.Setenv("JWT_SECRET", "env-secret")
+
+	cfg, err := config.Load(path)
+	require.NoError(t, err)
+
+	require.Equal(t, "0.0.0.0:9000", cfg.HTTPServer.Address)
+	require.Equal(t, "/data/storage.db", cfg.StoragePath)
+	require.Equal(t, 50, cfg.RateLimit.Save.Burst)
+	require.Equal(t, 5.0, cfg.RateLimit.Save.RPS)
+	require.Equal(t, 0.5, cfg.RateLimit.Login.RPS)
+	require.Equal(t, "env-secret", cfg.JWT.Secret)
+	require.Equal(t, "myuser", cfg.HTTPServer.User)
+	require.Equal(t, 4*time.Second, cfg.HTTPServer.Timeout)
+	require.Equal(t, 302, cfg.Redirect.Type)
+	require.Equal(t, "WAL", cfg.SQLite.JournalMode)
+	require.Equal(t, 5*time.Second, cfg.SQLite.BusyTimeout)
+}
+
+func TestLoad_EnvOnly(t *testing.T) {
+	t.Setenv("STORAGE_TYPE", "memory")
+	t.Setenv("HTTP_SERVER_USER", "admin")
+	t.Setenv("HTTP_SERVER_PASSWORD", "secret")
+	t.Setenv("AUTH_JWT_SECRET", "jwt-secret")
+	t.Setenv("REAPER_INTERVAL", "5m")
+	t.Setenv("ALIAS_ALPHABET", "abcdefghjkmnpqrstuvwxyz23456789")
+	t.Setenv("ALIAS_RESERVED", "admin,login")
+
+	cfg, err := config.Load("")
+	require.NoError(t, err)
+
+	require.Equal(t, config.StorageMemory, cfg.Storage.Type)
+	require.Equal(t, "admin", cfg.HTTPServer.User)
+	require.Equal(t, "localhost:8080", cfg.HTTPServer.Address)
+	require.Equal(t, 5*time.Minute, cfg.Reaper.Interval)
+	require.Equal(t, "abcdefghjkmnpqrstuvwxyz23456789", cfg.Alias.Alphabet)
+	require.Equal(t, 6, cfg.Alias.GeneratedLength)
+	require.Equal(t, []string{"admin", "login"}, cfg.Alias.Reserved)
+}
+
+func TestLoad_MissingRequired(t *testing.T) {
+	t.Setenv("STORAGE_TYPE", "memory")
+	t.Setenv("AUTH_JWT_SECRET", "jwt-secret")
+
+	_, err := config.Load("")
+	require.Error(t, err)
+}
+
+func TestLoad_Accounts(t *testing.T) {
+	const hash = "$2a$10$8vS9rcRJS2QDe7hREB051uI5YKy7f4/ZtKbxFSUK1uKRu/cYp8qKC"
+
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	require.NoError(t, os.WriteFile(path, []byte(`
+storage:
+  type: memory
+auth:
+  jwt:
+    secret: jwt-secret
+http_server:
+  user: admin
+  password_hash: "`+hash+`"
+  users:
+    - name: alice
+      password_hash: "`+hash+`"
+    - name: bob
+      password_hash: "`+hash+`"
+      role: viewer
+`), 0o600))
+
+	cfg, err := config.Load(path)
+	require.NoError(t, err)
+	require.Equal(t, []config.Account{
+		{Name: "alice", PasswordHash: hash, Role: "admin"},
+		{Name: "bob", PasswordHash: hash, Role: "viewer"},
+	}, cfg.HTTPServer.Users)
+
+	t.Setenv("HTTP_SERVER_PASSWORD", "secret")
+	_, err = config.Load(path)
+	require.EqualError(t, err, "http_server.password and http_server.password_hash are mutually exclusive")
+}
+
+func TestLoad_InvalidAccounts(t *testing.T) {
+	t.Setenv("STORAGE_TYPE", "memory")
+	t.Setenv("AUTH_JWT_SECRET", "jwt-secret")
+
+	_, err := config.Load("")
+	require.EqualError(t, err, "no users configured: set http_server.user, http_server.users or http_server.htpasswd_file")
+
+	t.Setenv("HTTP_SERVER_USER", "admin")
+	_, err = config.Load("")
+	require.EqualError(t, err, "http_server.password_hash is required with http_server.user")
+
+	t.Setenv("HTTP_SERVER_PASSWORD_HASH", "mypass")
+	_, err = config.Load("")
+	require.EqualError(t, err, "invalid http_server.password_hash: not a bcrypt hash")
+}
+
+func TestLoad_InvalidRedirectType(t *testing.T) {
+	t.Setenv("STORAGE_TYPE", "memory")
+	t.Setenv("HTTP_SERVER_USER", "admin")
+	t.Setenv("HTTP_SERVER_PASSWORD", "secret")
+	t.Setenv("AUTH_JWT_SECRET", "jwt-secret")
+	t.Setenv("REDIRECT_TYPE", "303")
+
+	_, err := config.Load("")
+	require.EqualError(t, err, "unknown redirect type: 303")
+}
+
+func TestLoad_SafeBrowsingWithoutKey(t *testing.T) {
+	t.Setenv("STORAGE_TYPE", "memory")
+	t.Setenv("HTTP_SERVER_USER", "admin")
+	t.Setenv("HTTP_SERVER_PASSWORD", "secret")
+	t.Setenv("AUTH_JWT_SECRET", "jwt-secret")
+	t.Setenv("REPUTATION_PROVIDER", "safebrowsing")
+
+	_, err := config.Load("")
+	require.EqualError(t, err, "reputation.safe_browsing_key is required for safebrowsing provider")
+}
+
+func TestLoad_InvalidEventBus(t *testing.T) {
+	t.Setenv("STORAGE_TYPE", "memory")
+	t.Setenv("HTTP_SERVER_USER", "admin")
+	t.Setenv("HTTP_SERVER_PASSWORD", "secret")
+	t.Setenv("AUTH_JWT_SECRET", "jwt-secret")
+
+	t.Setenv("EVENT_BUS_PROVIDER", "kafka")
+	_, err := config.Load("")
+	require.EqualError(t, err, "event_bus.brokers is required for kafka provider")
+
+	t.Setenv("EVENT_BUS_PROVIDER", "nats")
+	_, err = config.Load("")
+	require.EqualError(t, err, "event_bus.url is required for nats provider")
+
+	t.Setenv("EVENT_BUS_PROVIDER", "rabbitmq")
+	_, err = config.Load("")
+	require.EqualError(t, err, "unknown event bus provider: rabbitmq")
+}
+
+func TestLoad_InvalidLinkCheck(t *testing.T) {
+	t.Setenv("STORAGE_TYPE", "memory")
+	t.Setenv("HTTP_SERVER_USER", "admin")
+	t.Setenv("HTTP_SERVER_PASSWORD", "secret")
+	t.Setenv("AUTH_JWT_SECRET", "jwt-secret")
+	t.Setenv("LINK_CHECK_CONCURRENCY", "0")
+
+	// Disabled checks need no valid settings.
+	_, err := config.Load("")
+	require.NoError(t, err)
+
+	t.Setenv("LINK_CHECK_INTERVAL", "24h")
+	_, err = config.Load("")
+	require.EqualError(t, err, "link_check.concurrency and max_redirects must be positive")
+}
+
+func TestLoad_Listen(t *testing.T) {
+	t.Setenv("STORAGE_TYPE", "memory")
+	t.Setenv("HTTP_SERVER_USER", "admin")
+	t.Setenv("HTTP_SERVER_PASSWORD", "secret")
+	t.Setenv("AUTH_JWT_SECRET", "jwt-secret")
+	t.Setenv("HTTP_SERVER_ADDRESS", "0.0.0.0:8082")
+	t.Setenv("HTTP_SERVER_LISTEN", "unix:/run/url-shortener.sock,[::]:8082")
+	t.Setenv("HTTP_SERVER_SOCKET_MODE", "0666")
+
+	cfg, err := config.Load("")
+	require.NoError(t, err)
+	require.Equal(t, []string{"0.0.0.0:8082", "unix:/run/url-shortener.sock", "[::]:8082"}, cfg.HTTPServer.Addresses())
+	require.Equal(t, os.FileMode(0o666), cfg.HTTPServer.SocketFileMode())
+}
+
+func TestLoad_InvalidHTTPServer(t *testing.T) {
+	t.Setenv("STORAGE_TYPE", "memory")
+	t.Setenv("HTTP_SERVER_USER", "admin")
+	t.Setenv("HTTP_SERVER_PASSWORD", "secret")
+	t.Setenv("AUTH_JWT_SECRET", "jwt-secret")
+
+	t.Setenv("HTTP_SERVER_READ_TIMEOUT", "-1s")
+	_, err := config.Load("")
+	require.EqualError(t, err, "http_server timeouts cannot be negative")
+
+	t.Setenv("HTTP_SERVER_READ_TIMEOUT", "10s")
+	t.Setenv("HTTP_SERVER_MAX_BODY_SIZE", "0")
+	_, err = config.Load("")
+	require.EqualError(t, err, "http_server.max_header_bytes and max_body_size must be positive")
+
+	t.Setenv("HTTP_SERVER_MAX_BODY_SIZE", "1024")
+	t.Setenv("HTTP_SERVER_SOCKET_MODE", "0680")
+	_, err = config.Load("")
+	require.EqualError(t, err, "invalid http_server.socket_mode: 0680")
+}
+
+func TestLoad_Diagnostics(t *testing.T) {
+	t.Setenv("STORAGE_TYPE", "memory")
+	t.Setenv("HTTP_SERVER_USER", "admin")
+	t.Setenv("HTTP_SERVER_PASSWORD", "secret")
+	t.Setenv("AUTH_JWT_SECRET", "jwt-secret")
+
+	for _, addr := range []string{"localhost:6060", "127.0.0.1:6060", "[::1]:6060", "10.0.0.5:6060", "unix:/run/debug.sock"} {
+		t.Setenv("DIAGNOSTICS_ADDRESS", addr)
+		_, err := config.Load("")
+		require.NoError(t, err, addr)
+	}
+
+	for _, addr := range []string{":6060", "0.0.0.0:6060", "203.0.113.7:6060", "sho.rt:6060"} {
+		t.Setenv("DIAGNOSTICS_ADDRESS", addr)
+		_, err := config.Load("")
+		require.ErrorContains(t, err, "may be public", addr)
+	}
+
+	t.Setenv("DIAGNOSTICS_ALLOW_PUBLIC", "true")
+	_, err := config.Load("")
+	require.NoError(t, err)
+}
+
+func TestLoad_Log(t *testing.T) {
+	t.Setenv("STORAGE_TYPE", "memory")
+	t.Setenv("HTTP_SERVER_USER", "admin")
+	t.Setenv("HTTP_SERVER_PASSWORD", "secret")
+	t.Setenv("AUTH_JWT_SECRET", "jwt-secret")
+
+	t.Setenv("LOG_FORMAT", "text")
+	t.Setenv("LOG_LEVEL", "warn")
+	cfg, err := config.Load("")
+	require.NoError(t, err)
+	require.Equal(t, config.LogFormatText, cfg.Log.Format)
+	require.Equal(t, int64(100<<20), cfg.Log.Rotation.MaxSize)
+	require.Equal(t, 7, cfg.Log.Rotation.MaxBackups)
+
+	t.Setenv("LOG_FORMAT", "xml")
+	_, err = config.Load("")
+	require.EqualError(t, err, "invalid log.format: xml")
+
+	t.Setenv("LOG_FORMAT", "json")
+	t.Setenv("LOG_LEVEL", "verbose")
+	_, err = config.Load("")
+	require.EqualError(t, err, "invalid log.level: verbose")
+
+	t.Setenv("LOG_LEVEL", "")
+	t.Setenv("LOG_ROTATION_MAX_BACKUPS", "-1")
+	_, err = config.Load("")
+	require.EqualError(t, err, "log.rotation limits cannot be negative")
+}
+
+func TestLoad_Privacy(t *testing.T) {
+	t.Setenv("STORAGE_TYPE", "memory")
+	t.Setenv("HTTP_SERVER_USER", "admin")
+	t.Setenv("HTTP_SERVER_PASSWORD", "secret")
+	t.Setenv("AUTH_JWT_SECRET", "jwt-secret")
+	t.Setenv("PRIVACY_ENABLED", "true")
+	t.Setenv("PRIVACY_SECRET_PARAMS", "invite,ref")
+
+	cfg, err := config.Load("")
+	require.NoError(t, err)
+	require.Equal(t, "truncate", cfg.Privacy.IPMode)
+	require.Equal(t, []string{"invite", "ref"}, cfg.Privacy.SecretParams)
+
+	t.Setenv("PRIVACY_IP_MODE", "drop")
+	_, err = config.Load("")
+	require.EqualError(t, err, "invalid privacy.ip_mode: drop")
+}
+
+func TestLoad_Encryption(t *testing.T) {
+	t.Setenv("STORAGE_TYPE", "memory")
+	t.Setenv("HTTP_SERVER_USER", "admin")
+	t.Setenv("HTTP_SERVER_PASSWORD", "secret")
+	t.Setenv("AUTH_JWT_SECRET", "jwt-secret")
+	t.Setenv("ENCRYPTION_KEY", "MDEyMzQ1Njc4OWFiY2RlZjAxMjM0NTY3ODlhYmNkZWY=")
+
+	_, err := config.Load("")
+	require.NoError(t, err)
+
+	t.Setenv("ENCRYPTION_KEY", "c2hvcnQ=")
+	_, err = config.Load("")
+	require.EqualError(t, err, "invalid encryption.key: 5 bytes, want 16, 24 or 32")
+
+	t.Setenv("ENCRYPTION_KEY", "not base64!")
+	_, err = config.Load("")
+	require.EqualError(t, err, "invalid encryption.key: not base64")
+}
+
+func TestLoad_Anonymous(t *testing.T) {
+	t.Setenv("STORAGE_TYPE", "memory")
+	t.Setenv("HTTP_SERVER_USER", "admin")
+	t.Setenv("HTTP_SERVER_PASSWORD", "secret")
+	t.Setenv("AUTH_JWT_SECRET", "jwt-secret")
+	t.Setenv("ANONYMOUS_ENABLED", "true")
+
+	_, err := config.Load("")
+	require.EqualError(t, err, `unknown anonymous.captcha.provider: ""`)
+
+	t.Setenv("ANONYMOUS_CAPTCHA_PROVIDER", "turnstile")
+	t.Setenv("ANONYMOUS_CAPTCHA_SECRET", "captcha-secret")
+	_, err = config.Load("")
+	require.EqualError(t, err, "anonymous.rate_limit.rps is required")
+
+	t.Setenv("ANONYMOUS_RATE_LIMIT_RPS", "0.1")
+	t.Setenv("ANONYMOUS_RATE_LIMIT_BURST", "3")
+	cfg, err := config.Load("")
+	require.NoError(t, err)
+	require.Equal(t, 168*time.Hour, cfg.Anonymous.LinkTTL)
+	require.Equal(t, config.Limit{RPS: 0.1, Burst: 3}, cfg.Anonymous.RateLimit)
+}
+
+func TestLoad_AliasSigning(t *testing.T) {
+	t.Setenv("STORAGE_TYPE", "memory")
+	t.Setenv("HTTP_SERVER_USER", "admin")
+	t.Setenv("HTTP_SERVER_PASSWORD", "secret")
+	t.Setenv("AUTH_JWT_SECRET", "jwt-secret")
+	t.Setenv("ALIAS_SIGNING_KEY", "0123456789abcdef")
+
+	cfg, err := config.Load("")
+	require.NoError(t, err)
+	require.Equal(t, 6, cfg.Alias.Signing.Length)
+	require.True(t, cfg.Alias.Signing.Enforce)
+
+	t.Setenv("ALIAS_SIGNING_LENGTH", "20")
+	_, err = config.Load("")
+	require.EqualError(t, err, "alias.signing.length must be 4 to 16")
+
+	t.Setenv("ALIAS_SIGNING_KEY", "short")
+	_, err = config.Load("")
+	require.EqualError(t, err, "alias.signing.key must be at least 16 characters")
+}
+
+func TestLoad_OIDC(t *testing.T) {
+	t.Setenv("STORAGE_TYPE", "memory")
+	t.Setenv("HTTP_SERVER_USER", "admin")
+	t.Setenv("HTTP_SERVER_PASSWORD", "secret")
+	t.Setenv("AUTH_JWT_SECRET", "jwt-secret")
+	t.Setenv("AUTH_OIDC_ISSUER", "https://accounts.example.com")
+
+	_, err := config.Load("")
+	require.EqualError(t, err, "auth.oidc.client_id is required")
+
+	t.Setenv("AUTH_OIDC_CLIENT_ID", "shortener")
+	_, err = config.Load("")
+	require.EqualError(t, err, "auth.oidc.redirect_url is required without base_url")
+
+	t.Setenv("BASE_URL", "https://sho.rt")
+	t.Setenv("AUTH_OIDC_ROLE_CLAIM", "groups")
+	_, err = config.Load("")
+	require.EqualError(t, err, "auth.oidc.admin_values is required with role_claim")
+
+	t.Setenv("AUTH_OIDC_ADMIN_VALUES", "shortener-admins,ops")
+	t.Setenv("AUTH_OIDC_EDITOR_VALUES", "staff")
+	cfg, err := config.Load("")
+	require.NoError(t, err)
+	require.Equal(t, []string{"email", "profile"}, cfg.Auth.OIDC.Scopes)
+	require.Equal(t, "email", cfg.Auth.OIDC.UsernameClaim)
+	require.Equal(t, []string{"shortener-admins", "ops"}, cfg.Auth.OIDC.AdminValues)
+	require.Equal(t, []string{"staff"}, cfg.Auth.OIDC.EditorValues)
+	require.Equal(t, 10*time.Second, cfg.Auth.OIDC.Timeout)
+}
+
+func TestLoad_NegativeQuota(t *testing.T) {
+	t.Setenv("STORAGE_TYPE", "memory")
+	t.Setenv("HTTP_SERVER_USER", "admin")
+	t.Setenv("HTTP_SERVER_PASSWORD", "secret")
+	t.Setenv("AUTH_JWT_SECRET", "jwt-secret")
+	t.Setenv("QUOTA_MAX_PER_DAY", "-1")
+
+	_, err := config.Load("")
+	require.EqualError(t, err, "quota limits cannot be negative")
+}
+
+func TestLoad_InvalidFeed(t *testing.T) {
+	t.Setenv("STORAGE_TYPE", "memory")
+	t.Setenv("HTTP_SERVER_USER", "admin")
+	t.Setenv("HTTP_SERVER_PASSWORD", "secret")
+	t.Setenv("AUTH_JWT_SECRET", "jwt-secret")
+	t.Setenv("FEED_ENABLED", "true")
+	t.Setenv("FEED_SIZE", "0")
+
+	_, err := config.Load("")
+	require.EqualError(t, err, "feed.size must be from 1 to 100")
+}
+
+func TestLoad_CaseInsensitiveSequential(t *testing.T) {
+	t.Setenv("STORAGE_TYPE", "memory")
+	t.Setenv("HTTP_SERVER_USER", "admin")
+	t.Setenv("HTTP_SERVER_PASSWORD", "secret")
+	t.Setenv("AUTH_JWT_SECRET", "jwt-secret")
+	t.Setenv("ALIAS_STRATEGY", "sequential")
+	t.Setenv("ALIAS_CASE_INSENSITIVE", "true")
+
+	_, err := config.Load("")
+	require.EqualError(t, err, "alias.case_insensitive with the sequential strategy needs an alphabet without both cases of a letter")
+
+	t.Setenv("ALIAS_ALPHABET", "0123456789abcdefghijklmnopqrstuvwxyz")
+
+	_, err = config.Load("")
+	require.NoError(t, err)
+}
+
+func TestLoad_InvalidSQLite(t *testing.T) {
+	cases := []struct {
+		name    string
+		env     map[string]string
+		wantErr string
+	}{
+		{
+			name:    "Journal mode",
+			env:     map[string]string{"STORAGE_SQLITE_JOURNAL_MODE": "fast"},
+			wantErr: "unknown sqlite journal mode: fast",
+		},
+		{
+			name:    "Synchronous",
+			env:     map[string]string{"STORAGE_SQLITE_SYNCHRONOUS": "sometimes"},
+			wantErr: "unknown sqlite synchronous mode: sometimes",
+		},
+		{
+			name:    "Negative busy timeout",
+			env:     map[string]string{"STORAGE_SQLITE_BUSY_TIMEOUT": "-1s"},
+			wantErr: "sqlite busy_timeout and max_open_conns cannot be negative",
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Setenv("STORAGE_PATH", "./storage.db")
+			t.Setenv("HTTP_SERVER_USER", "admin")
+			t.Setenv("HTTP_SERVER_PASSWORD", "secret")
+			t.Setenv("AUTH_JWT_SECRET", "jwt-secret")
+			for k, v := range tc.env {
+				t.Setenv(k, v)
+			}
+
+			_, err := config.Load("")
+			require.EqualError(t, err, tc.wantErr)
+		})
+	}
+}
+
+func TestLoad_InvalidBackup(t *testing.T) {
+	cases := []struct {
+		name    string
+		env     map[string]string
+		wantErr string
+	}{
+		{
+			name:    "Not sqlite",
+			env:     map[string]string{"BACKUP_DIR": "./backups"},
+			wantErr: "backup is only supported for sqlite storage",
+		},
+		{
+			name:    "Interval without dir",
+			env:     map[string]string{"BACKUP_INTERVAL": "24h"},
+			wantErr: "backup.dir is required for scheduled backups",
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Setenv("STORAGE_TYPE", "memory")
+			t.Setenv("HTTP_SERVER_USER", "admin")
+			t.Setenv("HTTP_SERVER_PASSWORD", "secret")
+			t.Setenv("AUTH_JWT_SECRET", "jwt-secret")
+			for k, v := range tc.env {
+				t.Setenv(k, v)
+			}
+
+			_, err := config.Load("")
+			require.EqualError(t, err, tc.wantErr)
+		})
+	}
+}
+
+func TestLoad_InvalidTLS(t *testing.T) {
+	cases := []struct {
+		name    string
+		env     map[string]string
+		wantErr string
+	}{
+		{
+			name:    "Cert without key",
+			env:     map[string]string{"HTTP_SERVER_TLS_CERT_FILE": "cert.pem"},
+			wantErr: "http_server.tls.cert_file and key_file must be set together",
+		},
+		{
+			name:    "Autocert without domains",
+			env:     map[string]string{"HTTP_SERVER_TLS_AUTOCERT": "true"},
+			wantErr: "http_server.tls.domains is required for autocert",
+		},
+		{
+			name: "Autocert with files",
+			env: map[string]string{
+				"HTTP_SERVER_TLS_AUTOCERT":  "true",
+				"HTTP_SERVER_TLS_DOMAINS":   "sho.rt",
+				"HTTP_SERVER_TLS_CERT_FILE": "cert.pem",
+				"HTTP_SERVER_TLS_KEY_FILE":  "key.pem",
+			},
+			wantErr: "http_server.tls.autocert cannot be combined with cert_file",
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Setenv("STORAGE_TYPE", "memory")
+			t.Setenv("HTTP_SERVER_USER", "admin")
+			t.Setenv("HTTP_SERVER_PASSWORD", "secret")
+			t.Setenv("AUTH_JWT_SECRET", "jwt-secret")
+			for k, v := range tc.env {
+				t.Setenv(k, v)
+			}
+
+			_, err := config.Load("")
+			require.EqualError(t, err, tc.wantErr)
+		})
+	}
+}
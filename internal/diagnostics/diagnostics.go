@@ -0,0 +1,80 @@
+// Package diagnostics serves runtime profiles, expvar variables and build
+// information for operators. It is meant for a listener of its own that is
+// not reachable from the internet.
+package diagnostics
+
+import (
+	"expvar"
+	"net/http"
+	"net/http/pprof"
+	"runtime"
+	"runtime/debug"
+
+	"github.com/go-chi/render"
+)
+
+// Version is the release of the binary, set at build time with
+// -ldflags "-X url-shortener/internal/diagnostics.Version=v1.2.3". Without
+// it the module version recorded by the go tool is reported.
+var Version string
+
+// BuildInfo describes the running binary.
+type BuildInfo struct {
+	Version    string `json:"version,omitempty"`
+	Commit     string `json:"commit,omitempty"`
+	CommitTime string `json:"commit_time,omitempty"`
+	// Modified reports uncommitted changes in the tree it was built from.
+	Modified  bool   `json:"modified,omitempty"`
+	GoVersion string `json:"go_version"`
+	OS        string `json:"os"`
+	Arch      string `json:"arch"`
+}
+
+// ReadBuildInfo returns what the go tool recorded in the binary; the
+// commit is only known for builds from a VCS checkout.
+func ReadBuildInfo() BuildInfo {
+	info := BuildInfo{
+		Version:   Version,
+		GoVersion: runtime.Version(),
+		OS:        runtime.GOOS,
+		Arch:      runtime.GOARCH,
+	}
+
+	bi, ok := debug.ReadBuildInfo()
+	if !ok {
+		return info
+	}
+	if info.Version == "" && bi.Main.Version != "(devel)" {
+		info.Version = bi.Main.Version
+	}
+	for _, s := range bi.Settings {
+		switch s.Key {
+		case "vcs.revision":
+			info.Commit = s.Value
+		case "vcs.time":
+			info.CommitTime = s.Value
+		case "vcs.modified":
+			info.Modified = s.Value == "true"
+		}
+	}
+
+	return info
+}
+
+// NewHandler serves net/http/pprof under /debug/pprof/, expvar at
+// /debug/vars and BuildInfo as JSON at /debug/buildinfo.
+func NewHandler() http.Handler {
+	mux := http.NewServeMux()
+
+	mux.HandleFunc("/debug/pprof/", pprof.Index)
+	mux.HandleFunc("/debug/pprof/cmdline", pprof.Cmdline)
+	mux.HandleFunc("/debug/pprof/profile", pprof.Profile)
+	mux.HandleFunc("/debug/pprof/symbol", pprof.Symbol)
+	mux.HandleFunc("/debug/pprof/trace", pprof.Trace)
+	mux.Handle("GET /debug/vars", expvar.Handler())
+	mux.HandleFunc("GET /debug/buildinfo", func(w http.ResponseWriter, r *http.Request) {
+		render.JSON(w, r, ReadBuildInfo())
+	})
+
+	return mux
+}
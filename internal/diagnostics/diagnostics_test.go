@@ -0,0 +1,39 @@
+package diagnostics_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"runtime"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"url-shortener/internal/diagnostics"
+)
+
+func TestNewHandler(t *testing.T) {
+	diagnostics.Version = "v1.2.3"
+	t.Cleanup(func() { diagnostics.Version = "" })
+
+	handler := diagnostics.NewHandler()
+
+	rr := httptest.NewRecorder()
+	handler.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/debug/buildinfo", nil))
+	require.Equal(t, http.StatusOK, rr.Code)
+
+	var info diagnostics.BuildInfo
+	require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &info))
+	require.Equal(t, "v1.2.3", info.Version)
+	require.Equal(t, runtime.Version(), info.GoVersion)
+
+	for _, path := range []string{"/debug/pprof/", "/debug/pprof/goroutine?debug=1", "/debug/vars"} {
+		rr := httptest.NewRecorder()
+		handler.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, path, nil))
+		require.Equal(t, http.StatusOK, rr.Code, path)
+	}
+
+	rr = httptest.NewRecorder()
+	handler.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/url", nil))
+	require.Equal(t, http.StatusNotFound, rr.Code)
+}
@@ -0,0 +1,211 @@
+// Package errreport forwards errors to an error tracker such as Sentry:
+// log records of level Error that carry an error attribute (sl.Err) and
+// panics in HTTP handlers.
+package errreport
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"runtime/debug"
+	"slices"
+	"time"
+
+	"github.com/go-chi/chi/v5/middleware"
+)
+
+// LevelPanic is the level of events for panics.
+const LevelPanic = slog.LevelError + 4
+
+// errorKey is the key sl.Err gives error attributes.
+const errorKey = "error"
+
+// Event is one error to report.
+type Event struct {
+	Time  time.Time
+	Level slog.Level
+	// Message is the log message, "panic" for panics.
+	Message string
+	// Error is the error attribute of the log record or the panic value.
+	Error string
+	// Attrs are the other attributes of the record, such as op and
+	// request_id; keys of grouped attributes are joined with dots.
+	Attrs map[string]string
+	// Request and Stack are set for panics in HTTP handlers.
+	Request *Request
+	Stack   string
+}
+
+// Request is the HTTP request a panic happened in. Headers that carry
+// credentials are left out.
+type Request struct {
+	Method     string
+	URL        string
+	RemoteAddr string
+	Headers    map[string]string
+}
+
+//go:generate go run github.com/vektra/mockery/v2@latest --name=Reporter
+type Reporter interface {
+	// Report queues e; it must not block.
+	Report(e Event)
+}
+
+// ReporterFunc lets an ordinary function be a Reporter.
+type ReporterFunc func(e Event)
+
+func (f ReporterFunc) Report(e Event) {
+	f(e)
+}
+
+// Handler is a slog.Handler that passes every record on to the next
+// handler and reports error records with an error attribute, whatever the
+// level of the next handler.
+type Handler struct {
+	next     slog.Handler
+	reporter Reporter
+	// attrs were added with WithAttrs, their keys already prefixed.
+	attrs  []slog.Attr
+	prefix string
+}
+
+func NewHandler(next slog.Handler, reporter Reporter) *Handler {
+	return &Handler{next: next, reporter: reporter}
+}
+
+func (h *Handler) Enabled(ctx context.Context, level slog.Level) bool {
+	return level >= slog.LevelError || h.next.Enabled(ctx, level)
+}
+
+func (h *Handler) Handle(ctx context.Context, r slog.Record) error {
+	if r.Level >= slog.LevelError {
+		h.report(r)
+	}
+	if !h.next.Enabled(ctx, r.Level) {
+		return nil
+	}
+	return h.next.Handle(ctx, r)
+}
+
+func (h *Handler) report(r slog.Record) {
+	attrs := make(map[string]string, len(h.attrs)+r.NumAttrs())
+	for _, a := range h.attrs {
+		flatten(attrs, "", a)
+	}
+	r.Attrs(func(a slog.Attr) bool {
+		flatten(attrs, h.prefix, a)
+		return true
+	})
+
+	err, ok := attrs[h.prefix+errorKey]
+	if !ok {
+		return
+	}
+	delete(attrs, h.prefix+errorKey)
+
+	h.reporter.Report(Event{
+		Time:    r.Time,
+		Level:   r.Level,
+		Message: r.Message,
+		Error:   err,
+		Attrs:   attrs,
+	})
+}
+
+func flatten(attrs map[string]string, prefix string, a slog.Attr) {
+	a.Value = a.Value.Resolve()
+	if a.Value.Kind() != slog.KindGroup {
+		attrs[prefix+a.Key] = a.Value.String()
+		return
+	}
+	if a.Key != "" {
+		prefix += a.Key + "."
+	}
+	for _, ga := range a.Value.Group() {
+		flatten(attrs, prefix, ga)
+	}
+}
+
+func (h *Handler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	h2 := *h
+	h2.next = h.next.WithAttrs(attrs)
+	h2.attrs = slices.Clip(h.attrs)
+	for _, a := range attrs {
+		a.Key = h.prefix + a.Key
+		h2.attrs = append(h2.attrs, a)
+	}
+	return &h2
+}
+
+func (h *Handler) WithGroup(name string) slog.Handler {
+	if name == "" {
+		return h
+	}
+	h2 := *h
+	h2.next = h.next.WithGroup(name)
+	h2.prefix = h.prefix + name + "."
+	return &h2
+}
+
+// secretHeaders are not reported.
+var secretHeaders = []string{"Authorization", "Cookie", "X-Api-Key", "Proxy-Authorization"}
+
+// Recoverer returns a middleware that reports panics of the handlers it
+// wraps and then panics again, so it has to be mounted after
+// middleware.Recoverer, which answers them with 500.
+func Recoverer(reporter Reporter) func(next http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		fn := func(w http.ResponseWriter, r *http.Request) {
+			defer func() {
+				rvr := recover()
+				if rvr == nil {
+					return
+				}
+				if rvr != http.ErrAbortHandler {
+					reporter.Report(panicEvent(r, rvr))
+				}
+				panic(rvr)
+			}()
+
+			next.ServeHTTP(w, r)
+		}
+
+		return http.HandlerFunc(fn)
+	}
+}
+
+func panicEvent(r *http.Request, rvr any) Event {
+	headers := make(map[string]string, len(r.Header))
+	for name, values := range r.Header {
+		if slices.Contains(secretHeaders, name) {
+			continue
+		}
+		headers[name] = values[0]
+	}
+
+	scheme := "http"
+	if r.TLS != nil {
+		scheme = "https"
+	}
+
+	e := Event{
+		Time:    time.Now(),
+		Level:   LevelPanic,
+		Message: "panic",
+		Error:   fmt.Sprint(rvr),
+		Attrs:   map[string]string{},
+		Request: &Request{
+			Method:     r.Method,
+			URL:        scheme + "://" + r.Host + r.URL.Path,
+			RemoteAddr: r.RemoteAddr,
+			Headers:    headers,
+		},
+		Stack: string(debug.Stack()),
+	}
+	if id := middleware.GetReqID(r.Context()); id != "" {
+		e.Attrs["request_id"] = id
+	}
+
+	return e
+}
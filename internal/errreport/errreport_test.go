@@ -0,0 +1,78 @@
+package errreport_test
+
+import (
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"url-shortener/internal/errreport"
+	"url-shortener/internal/errreport/mocks"
+	"url-shortener/internal/lib/logger/sl"
+)
+
+func TestHandler(t *testing.T) {
+	reporter := mocks.NewReporter(t)
+
+	var got errreport.Event
+	reporter.On("Report", mock.Anything).Run(func(args mock.Arguments) {
+		got = args.Get(0).(errreport.Event)
+	}).Once()
+
+	next := slog.NewTextHandler(io.Discard, &slog.HandlerOptions{Level: slog.LevelWarn})
+	log := slog.New(errreport.NewHandler(next, reporter)).
+		With(slog.String("op", "handlers.url.save.New"), slog.String("request_id", "req-1"))
+
+	log.Info("not an error")
+	log.Error("no error attribute")
+	log.Warn("warning", sl.Err(errors.New("ignored")))
+	log.Error("failed to add url", sl.Err(errors.New("disk full")), slog.Group("url", slog.String("alias", "abc")))
+
+	require.Equal(t, slog.LevelError, got.Level)
+	require.Equal(t, "failed to add url", got.Message)
+	require.Equal(t, "disk full", got.Error)
+	require.Equal(t, map[string]string{
+		"op":         "handlers.url.save.New",
+		"request_id": "req-1",
+		"url.alias":  "abc",
+	}, got.Attrs)
+}
+
+func TestRecoverer(t *testing.T) {
+	reporter := mocks.NewReporter(t)
+
+	var got errreport.Event
+	reporter.On("Report", mock.Anything).Run(func(args mock.Arguments) {
+		got = args.Get(0).(errreport.Event)
+	}).Once()
+
+	handler := middleware.RequestID(middleware.Recoverer(errreport.Recoverer(reporter)(
+		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			panic("boom")
+		}),
+	)))
+
+	req := httptest.NewRequest(http.MethodGet, "/abc?secret=1", nil)
+	req.Header.Set("Authorization", "Bearer token")
+	req.Header.Set("User-Agent", "test")
+	rr := httptest.NewRecorder()
+	handler.ServeHTTP(rr, req)
+
+	require.Equal(t, http.StatusInternalServerError, rr.Code)
+	require.Equal(t, errreport.LevelPanic, got.Level)
+	require.Equal(t, "boom", got.Error)
+	require.NotEmpty(t, got.Attrs["request_id"])
+	require.Contains(t, got.Stack, "errreport_test")
+	require.Equal(t, &errreport.Request{
+		Method:     http.MethodGet,
+		URL:        "http://example.com/abc",
+		RemoteAddr: "192.0.2.1:1234",
+		Headers:    map[string]string{"User-Agent": "test"},
+	}, got.Request)
+}
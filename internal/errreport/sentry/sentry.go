@@ -0,0 +1,295 @@
+// Package sentry sends errreport events to Sentry or a service that accepts
+// its envelope API, such as GlitchTip. Events are queued in memory and sent
+// by a background worker; events that do not fit in the queue are dropped.
+package sentry
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"os"
+	"slices"
+	"strings"
+	"time"
+
+	"url-shortener/internal/errreport"
+	"url-shortener/internal/lib/logger/sl"
+)
+
+const (
+	clientName = "url-shortener/1.0"
+
+	// flushTimeout is how long events still queued when Run stops have to
+	// be sent.
+	flushTimeout = 5 * time.Second
+)
+
+type Options struct {
+	// Environment and Release are attached to every event.
+	Environment string
+	Release     string
+	QueueSize   int
+}
+
+// Client sends events to the project of a DSN.
+type Client struct {
+	log      *slog.Logger
+	client   *http.Client
+	dsn      string
+	endpoint string
+	auth     string
+	opts     Options
+	server   string
+	queue    chan errreport.Event
+}
+
+// New returns a client for dsn, which looks like
+// https://<key>@<host>/<project>. log must not report errors to the client
+// itself; failures are logged as warnings anyway.
+func New(log *slog.Logger, client *http.Client, dsn string, opts Options) (*Client, error) {
+	const op = "errreport.sentry.New"
+
+	u, err := url.Parse(dsn)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" || u.User == nil {
+		return nil, fmt.Errorf("%s: invalid dsn", op)
+	}
+	key := u.User.Username()
+	prefix, project, _ := cutLast(strings.TrimSuffix(u.Path, "/"), "/")
+	if key == "" || project == "" {
+		return nil, fmt.Errorf("%s: dsn must have a key and a project", op)
+	}
+
+	server, _ := os.Hostname()
+
+	return &Client{
+		log:      log.With(slog.String("component", "errreport")),
+		client:   client,
+		dsn:      dsn,
+		endpoint: u.Scheme + "://" + u.Host + prefix + "/api/" + project + "/envelope/",
+		auth:     "Sentry sentry_version=7, sentry_client=" + clientName + ", sentry_key=" + key,
+		opts:     opts,
+		server:   server,
+		queue:    make(chan errreport.Event, max(opts.QueueSize, 1)),
+	}, nil
+}
+
+func cutLast(s, sep string) (before, after string, found bool) {
+	if i := strings.LastIndex(s, sep); i >= 0 {
+		return s[:i], s[i+len(sep):], true
+	}
+	return "", s, false
+}
+
+// Report queues e without blocking.
+func (c *Client) Report(e errreport.Event) {
+	select {
+	case c.queue <- e:
+	default:
+		c.log.Warn("error report queue is full, event dropped", slog.String("message", e.Message))
+	}
+}
+
+// Run sends queued events until ctx is cancelled, then for up to five
+// seconds more sends the events still queued.
+func (c *Client) Run(ctx context.Context) {
+	for {
+		select {
+		case <-ctx.Done():
+			c.flush()
+			return
+		case e := <-c.queue:
+			c.send(ctx, e)
+		}
+	}
+}
+
+func (c *Client) flush() {
+	ctx, cancel := context.WithTimeout(context.Background(), flushTimeout)
+	defer cancel()
+
+	for {
+		select {
+		case e := <-c.queue:
+			c.send(ctx, e)
+		default:
+			return
+		}
+		if ctx.Err() != nil {
+			return
+		}
+	}
+}
+
+func (c *Client) send(ctx context.Context, e errreport.Event) {
+	id := newID()
+	body, err := c.envelope(id, e)
+	if err != nil {
+		c.log.Warn("failed to encode error report", sl.Err(err))
+		return
+	}
+
+	if err := c.post(ctx, body); err != nil {
+		c.log.Warn("failed to send error report", slog.String("event_id", id), sl.Err(err))
+	}
+}
+
+func (c *Client) post(ctx context.Context, body []byte) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.endpoint, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/x-sentry-envelope")
+	req.Header.Set("User-Agent", clientName)
+	req.Header.Set("X-Sentry-Auth", c.auth)
+
+	res, err := c.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+	// Drain a little of the body so the connection can be reused.
+	_, _ = io.Copy(io.Discard, io.LimitReader(res.Body, 64<<10))
+
+	if res.StatusCode < 200 || res.StatusCode > 299 {
+		return fmt.Errorf("unexpected status %d", res.StatusCode)
+	}
+	return nil
+}
+
+type event struct {
+	EventID     string            `json:"event_id"`
+	Timestamp   string            `json:"timestamp"`
+	Platform    string            `json:"platform"`
+	Level       string            `json:"level"`
+	Logger      string            `json:"logger"`
+	Message     string            `json:"message"`
+	Environment string            `json:"environment,omitempty"`
+	Release     string            `json:"release,omitempty"`
+	ServerName  string            `json:"server_name,omitempty"`
+	Exception   *exceptions       `json:"exception,omitempty"`
+	Tags        map[string]string `json:"tags,omitempty"`
+	Extra       map[string]string `json:"extra,omitempty"`
+	Request     *request          `json:"request,omitempty"`
+}
+
+type exceptions struct {
+	Values []exception `json:"values"`
+}
+
+type exception struct {
+	Type  string `json:"type"`
+	Value string `json:"value"`
+}
+
+type request struct {
+	Method  string            `json:"method"`
+	URL     string            `json:"url"`
+	Headers map[string]string `json:"headers,omitempty"`
+	Env     map[string]string `json:"env,omitempty"`
+}
+
+// tagKeys are attributes sent as tags, which Sentry can search by, rather
+// than as extra data.
+var tagKeys = []string{"op", "request_id", "component", "alias"}
+
+// envelope encodes e as an envelope with one event item.
+func (c *Client) envelope(id string, e errreport.Event) ([]byte, error) {
+	ev := event{
+		EventID:     id,
+		Timestamp:   e.Time.UTC().Format(time.RFC3339Nano),
+		Platform:    "go",
+		Level:       level(e.Level),
+		Logger:      "slog",
+		Message:     e.Message,
+		Environment: c.opts.Environment,
+		Release:     c.opts.Release,
+		ServerName:  c.server,
+		Tags:        map[string]string{},
+		Extra:       map[string]string{},
+	}
+
+	for k, v := range e.Attrs {
+		if isTag(k) {
+			ev.Tags[k] = v
+		} else {
+			ev.Extra[k] = v
+		}
+	}
+
+	// Events are grouped by the exception type, so the op, which names
+	// where the error happened, makes a better one than the message.
+	typ := e.Attrs["op"]
+	if typ == "" {
+		typ = e.Message
+	}
+	ev.Exception = &exceptions{Values: []exception{{Type: typ, Value: e.Error}}}
+
+	if e.Stack != "" {
+		ev.Extra["stack"] = e.Stack
+	}
+	if r := e.Request; r != nil {
+		ev.Request = &request{
+			Method:  r.Method,
+			URL:     r.URL,
+			Headers: r.Headers,
+			Env:     map[string]string{"REMOTE_ADDR": r.RemoteAddr},
+		}
+	}
+
+	payload, err := json.Marshal(ev)
+	if err != nil {
+		return nil, err
+	}
+	header, err := json.Marshal(map[string]string{
+		"event_id": id,
+		"sent_at":  time.Now().UTC().Format(time.RFC3339Nano),
+		"dsn":      c.dsn,
+	})
+	if err != nil {
+		return nil, err
+	}
+	item, err := json.Marshal(map[string]any{"type": "event", "length": len(payload)})
+	if err != nil {
+		return nil, err
+	}
+
+	var buf bytes.Buffer
+	for _, line := range [][]byte{header, item, payload} {
+		buf.Write(line)
+		buf.WriteByte('\n')
+	}
+	return buf.Bytes(), nil
+}
+
+func isTag(key string) bool {
+	return slices.Contains(tagKeys, key)
+}
+
+func level(l slog.Level) string {
+	switch {
+	case l >= errreport.LevelPanic:
+		return "fatal"
+	case l >= slog.LevelError:
+		return "error"
+	case l >= slog.LevelWarn:
+		return "warning"
+	case l >= slog.LevelInfo:
+		return "info"
+	default:
+		return "debug"
+	}
+}
+
+func newID() string {
+	b := make([]byte, 16)
+	_, _ = rand.Read(b)
+
+	return hex.EncodeToString(b)
+}
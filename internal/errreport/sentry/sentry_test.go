@@ -0,0 +1,99 @@
+package sentry_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"url-shortener/internal/errreport"
+	"url-shortener/internal/errreport/sentry"
+)
+
+func TestClient(t *testing.T) {
+	type received struct {
+		path string
+		auth string
+		body []byte
+	}
+	requests := make(chan received, 1)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		requests <- received{path: r.URL.Path, auth: r.Header.Get("X-Sentry-Auth"), body: body}
+	}))
+	defer srv.Close()
+
+	dsn := strings.Replace(srv.URL, "://", "://public@", 1) + "/sentry/42"
+	log := slog.New(slog.NewTextHandler(io.Discard, nil))
+	client, err := sentry.New(log, srv.Client(), dsn, sentry.Options{Environment: "prod", Release: "v1.2.3", QueueSize: 1})
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go client.Run(ctx)
+
+	client.Report(errreport.Event{
+		Time:    time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC),
+		Level:   slog.LevelError,
+		Message: "failed to add url",
+		Error:   "disk full",
+		Attrs:   map[string]string{"op": "handlers.url.save.New", "request_id": "req-1", "url": "https://example.com"},
+	})
+
+	var got received
+	select {
+	case got = <-requests:
+	case <-time.After(5 * time.Second):
+		t.Fatal("no event sent")
+	}
+
+	require.Equal(t, "/sentry/api/42/envelope/", got.path)
+	require.Contains(t, got.auth, "sentry_version=7")
+	require.Contains(t, got.auth, "sentry_key=public")
+
+	lines := bytes.Split(bytes.TrimSpace(got.body), []byte("\n"))
+	require.Len(t, lines, 3)
+
+	var event struct {
+		EventID     string            `json:"event_id"`
+		Level       string            `json:"level"`
+		Message     string            `json:"message"`
+		Environment string            `json:"environment"`
+		Release     string            `json:"release"`
+		Tags        map[string]string `json:"tags"`
+		Extra       map[string]string `json:"extra"`
+		Exception   struct {
+			Values []struct {
+				Type  string `json:"type"`
+				Value string `json:"value"`
+			} `json:"values"`
+		} `json:"exception"`
+	}
+	require.NoError(t, json.Unmarshal(lines[2], &event))
+	require.Len(t, event.EventID, 32)
+	require.Equal(t, "error", event.Level)
+	require.Equal(t, "failed to add url", event.Message)
+	require.Equal(t, "prod", event.Environment)
+	require.Equal(t, "v1.2.3", event.Release)
+	require.Equal(t, map[string]string{"op": "handlers.url.save.New", "request_id": "req-1"}, event.Tags)
+	require.Equal(t, map[string]string{"url": "https://example.com"}, event.Extra)
+	require.Len(t, event.Exception.Values, 1)
+	require.Equal(t, "handlers.url.save.New", event.Exception.Values[0].Type)
+	require.Equal(t, "disk full", event.Exception.Values[0].Value)
+}
+
+func TestNew_InvalidDSN(t *testing.T) {
+	log := slog.New(slog.NewTextHandler(io.Discard, nil))
+	for _, dsn := range []string{"not a url", "https://sentry.io/42", "https://key@sentry.io", "ftp://key@sentry.io/42"} {
+		_, err := sentry.New(log, http.DefaultClient, dsn, sentry.Options{})
+		require.Error(t, err, dsn)
+	}
+}
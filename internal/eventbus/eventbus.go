@@ -0,0 +1,119 @@
+// Package eventbus publishes link events to a message bus, Kafka or NATS,
+// for downstream analytics. Events are queued in memory and published by
+// one background worker in the order they happened; events still queued
+// when the service stops are lost.
+package eventbus
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"slices"
+	"time"
+
+	"url-shortener/internal/lib/logger/sl"
+	"url-shortener/internal/webhook"
+)
+
+// HeaderEvent is the message header that holds the event type, so
+// consumers can route messages without decoding them.
+const HeaderEvent = "event"
+
+//go:generate go run github.com/vektra/mockery/v2@latest --name=Sender
+type Sender interface {
+	// Send publishes the JSON body of event.
+	Send(ctx context.Context, event webhook.Event, body []byte) error
+	Close() error
+}
+
+type Options struct {
+	// Events to publish; empty means all of them.
+	Events []string
+	// Timeout limits publishing a single event.
+	Timeout time.Duration
+	// QueueSize is how many events can wait; more are dropped.
+	QueueSize int
+}
+
+// Publisher queues events and publishes them with a sender.
+type Publisher struct {
+	log    *slog.Logger
+	sender Sender
+	opts   Options
+	queue  chan webhook.Event
+}
+
+// New returns a publisher to sender. It fails if opts.Events has an
+// unknown event.
+func New(log *slog.Logger, sender Sender, opts Options) (*Publisher, error) {
+	const op = "eventbus.New"
+
+	for _, event := range opts.Events {
+		if !slices.Contains(webhook.Events, event) {
+			return nil, fmt.Errorf("%s: unknown event %q", op, event)
+		}
+	}
+
+	return &Publisher{
+		log:    log.With(slog.String("component", "eventbus")),
+		sender: sender,
+		opts:   opts,
+		queue:  make(chan webhook.Event, max(opts.QueueSize, 1)),
+	}, nil
+}
+
+// Notify queues event without blocking if the publisher is subscribed to
+// it. ID and Time are filled in if empty.
+func (p *Publisher) Notify(event webhook.Event) {
+	if len(p.opts.Events) > 0 && !slices.Contains(p.opts.Events, event.Type) {
+		return
+	}
+	event.Stamp()
+
+	select {
+	case p.queue <- event:
+	default:
+		p.log.Warn("event bus queue is full, event dropped",
+			slog.String("event", event.Type), slog.String("alias", event.Alias))
+	}
+}
+
+// Run publishes queued events until ctx is cancelled and then closes the
+// sender.
+func (p *Publisher) Run(ctx context.Context) {
+	defer func() {
+		if err := p.sender.Close(); err != nil {
+			p.log.Error("failed to close event bus connection", sl.Err(err))
+		}
+	}()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case event := <-p.queue:
+			p.publish(ctx, event)
+		}
+	}
+}
+
+func (p *Publisher) publish(ctx context.Context, event webhook.Event) {
+	log := p.log.With(slog.String("event", event.Type), slog.String("id", event.ID))
+
+	body, err := json.Marshal(event)
+	if err != nil {
+		log.Error("failed to encode event", sl.Err(err))
+		return
+	}
+
+	if p.opts.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, p.opts.Timeout)
+		defer cancel()
+	}
+
+	if err := p.sender.Send(ctx, event, body); err != nil {
+		log.Error("failed to publish event", sl.Err(err))
+	}
+}
@@ -0,0 +1,107 @@
+package eventbus_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"url-shortener/internal/eventbus"
+	"url-shortener/internal/eventbus/mocks"
+	"url-shortener/internal/lib/logger/handlers/slogdiscard"
+	"url-shortener/internal/webhook"
+)
+
+func TestPublisher(t *testing.T) {
+	senderMock := mocks.NewSender(t)
+
+	p, err := eventbus.New(slogdiscard.NewDiscardLogger(), senderMock, eventbus.Options{
+		Events:    []string{webhook.EventLinkCreated, webhook.EventLinkClicked},
+		Timeout:   time.Second,
+		QueueSize: 10,
+	})
+	require.NoError(t, err)
+
+	sent := make(chan webhook.Event, 10)
+	senderMock.On("Send", mock.Anything, mock.Anything, mock.Anything).
+		Run(func(args mock.Arguments) {
+			ctx := args.Get(0).(context.Context)
+			_, ok := ctx.Deadline()
+			require.True(t, ok)
+
+			var body webhook.Event
+			require.NoError(t, json.Unmarshal(args.Get(2).([]byte), &body))
+			require.Equal(t, args.Get(1), body)
+			sent <- body
+		}).
+		Return(nil).
+		Times(2)
+	senderMock.On("Close").Return(nil).Once()
+
+	p.Notify(webhook.Event{Type: webhook.EventLinkCreated, Alias: "one", URL: "https://example.com"})
+	// Not subscribed.
+	p.Notify(webhook.Event{Type: webhook.EventLinkDeleted, Alias: "one"})
+	p.Notify(webhook.Event{Type: webhook.EventLinkClicked, Alias: "one", Referrer: "https://example.org/"})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		p.Run(ctx)
+		close(done)
+	}()
+
+	created, clicked := <-sent, <-sent
+	require.Equal(t, webhook.EventLinkCreated, created.Type)
+	require.Equal(t, webhook.EventLinkClicked, clicked.Type)
+	require.Equal(t, "https://example.org/", clicked.Referrer)
+	require.NotEmpty(t, clicked.ID)
+	require.NotEqual(t, created.ID, clicked.ID)
+	require.False(t, clicked.Time.IsZero())
+
+	cancel()
+	<-done
+}
+
+func TestPublisher_SendError(t *testing.T) {
+	senderMock := mocks.NewSender(t)
+
+	p, err := eventbus.New(slogdiscard.NewDiscardLogger(), senderMock, eventbus.Options{QueueSize: 10})
+	require.NoError(t, err)
+
+	// A failed event is dropped and the next one still goes out.
+	sent := make(chan string, 2)
+	senderMock.On("Send", mock.Anything, mock.Anything, mock.Anything).
+		Run(func(args mock.Arguments) { sent <- args.Get(1).(webhook.Event).Alias }).
+		Return(errors.New("broker down")).
+		Once()
+	senderMock.On("Send", mock.Anything, mock.Anything, mock.Anything).
+		Run(func(args mock.Arguments) { sent <- args.Get(1).(webhook.Event).Alias }).
+		Return(nil).
+		Once()
+	senderMock.On("Close").Return(nil).Once()
+
+	p.Notify(webhook.Event{Type: webhook.EventLinkDeleted, Alias: "one"})
+	p.Notify(webhook.Event{Type: webhook.EventLinkExpired, Alias: "two"})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		p.Run(ctx)
+		close(done)
+	}()
+
+	require.Equal(t, "one", <-sent)
+	require.Equal(t, "two", <-sent)
+
+	cancel()
+	<-done
+}
+
+func TestNew_UnknownEvent(t *testing.T) {
+	_, err := eventbus.New(slogdiscard.NewDiscardLogger(), mocks.NewSender(t), eventbus.Options{Events: []string{"link_viewed"}})
+	require.Error(t, err)
+}
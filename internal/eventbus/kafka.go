@@ -0,0 +1,43 @@
+package eventbus
+
+import (
+	"context"
+	"time"
+
+	"github.com/segmentio/kafka-go"
+
+	"url-shortener/internal/webhook"
+)
+
+// Kafka sends events to a Kafka topic, keyed by alias so that the events
+// of a link stay in order on one partition.
+type Kafka struct {
+	writer *kafka.Writer
+}
+
+// NewKafka returns a sender to topic on the cluster of brokers (host:port).
+// It connects on the first event.
+func NewKafka(brokers []string, topic string) *Kafka {
+	return &Kafka{writer: &kafka.Writer{
+		Addr:         kafka.TCP(brokers...),
+		Topic:        topic,
+		Balancer:     &kafka.Hash{},
+		RequiredAcks: kafka.RequireAll,
+		// Events are written one at a time, so waiting for a batch to fill
+		// up would only slow them down.
+		BatchTimeout: 10 * time.Millisecond,
+	}}
+}
+
+func (k *Kafka) Send(ctx context.Context, event webhook.Event, body []byte) error {
+	return k.writer.WriteMessages(ctx, kafka.Message{
+		Key:     []byte(event.Alias),
+		Value:   body,
+		Headers: []kafka.Header{{Key: HeaderEvent, Value: []byte(event.Type)}},
+		Time:    event.Time,
+	})
+}
+
+func (k *Kafka) Close() error {
+	return k.writer.Close()
+}
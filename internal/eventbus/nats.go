@@ -0,0 +1,53 @@
+package eventbus
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/nats-io/nats.go"
+
+	"url-shortener/internal/webhook"
+)
+
+// NATS sends every event to the subject "<subject>.<event type>", so
+// consumers can subscribe to one type or, with "<subject>.>", to all.
+type NATS struct {
+	conn    *nats.Conn
+	subject string
+}
+
+// NewNATS connects to the NATS server at url. The connection is kept up
+// for as long as the sender is open.
+func NewNATS(url, subject string) (*NATS, error) {
+	const op = "eventbus.NewNATS"
+
+	conn, err := nats.Connect(url, nats.Name("url-shortener"), nats.MaxReconnects(-1))
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return &NATS{conn: conn, subject: subject}, nil
+}
+
+func (n *NATS) Send(ctx context.Context, event webhook.Event, body []byte) error {
+	msg := nats.NewMsg(n.subject + "." + event.Type)
+	msg.Data = body
+	msg.Header.Set(HeaderEvent, event.Type)
+	msg.Header.Set(nats.MsgIdHdr, event.ID)
+
+	if err := n.conn.PublishMsg(msg); err != nil {
+		return err
+	}
+
+	// Publishing only buffers the message; the flush reports whether the
+	// server got it.
+	if _, ok := ctx.Deadline(); !ok {
+		return n.conn.Flush()
+	}
+	return n.conn.FlushWithContext(ctx)
+}
+
+// Close sends the buffered messages and closes the connection.
+func (n *NATS) Close() error {
+	return n.conn.Drain()
+}
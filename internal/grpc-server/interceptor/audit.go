@@ -0,0 +1,30 @@
+package interceptor
+
+import (
+	"context"
+	"net"
+
+	"url-shortener/internal/http-server/middleware/auth"
+	"url-shortener/internal/storage/audited"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/peer"
+)
+
+// Audit attributes the changes a call makes to the authenticated user and
+// the peer address, like the HTTP audit middleware. It goes after Auth.
+func Audit() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		user, _ := auth.UserFromContext(ctx)
+
+		actor := audited.Actor{User: user.Username}
+		if p, ok := peer.FromContext(ctx); ok {
+			actor.IP = p.Addr.String()
+			if host, _, err := net.SplitHostPort(actor.IP); err == nil {
+				actor.IP = host
+			}
+		}
+
+		return handler(audited.WithActor(ctx, actor), req)
+	}
+}
@@ -0,0 +1,76 @@
+package interceptor
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"strings"
+	"url-shortener/internal/http-server/middleware/auth"
+	"url-shortener/internal/lib/apikey"
+	"url-shortener/internal/lib/logger/sl"
+	"url-shortener/internal/storage"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// Auth authenticates calls the way the HTTP auth middleware does, with an
+// "authorization: Bearer <jwt>" or "x-api-key" metadata entry, and puts the
+// user into the context for auth.UserFromContext.
+func Auth(
+	log *slog.Logger,
+	tokens auth.TokenParser,
+	keys auth.KeyGetter,
+	userAuth auth.UserAuthenticator,
+) grpc.UnaryServerInterceptor {
+	log = log.With(slog.String("component", "grpc/interceptor/auth"))
+
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		md, _ := metadata.FromIncomingContext(ctx)
+
+		if key := first(md, "x-api-key"); key != "" {
+			k, err := keys.GetAPIKeyByHash(ctx, apikey.Hash(key))
+			if err != nil && !errors.Is(err, storage.ErrKeyNotFound) {
+				log.Error("failed to get api key", sl.Err(err), slog.String("method", info.FullMethod))
+				return nil, status.Error(codes.Internal, "internal error")
+			}
+			if err != nil || k.Revoked() {
+				log.Info("invalid api key", slog.String("method", info.FullMethod))
+				return nil, status.Error(codes.Unauthenticated, "unauthorized")
+			}
+
+			user, err := userAuth.GetUser(ctx, k.Owner)
+			if errors.Is(err, storage.ErrUserNotFound) {
+				log.Info("api key owner not found", slog.String("method", info.FullMethod))
+				return nil, status.Error(codes.Unauthenticated, "unauthorized")
+			}
+			if err != nil {
+				log.Error("failed to get api key owner", sl.Err(err), slog.String("method", info.FullMethod))
+				return nil, status.Error(codes.Internal, "internal error")
+			}
+
+			return handler(auth.WithUser(ctx, user), req)
+		}
+
+		if token, ok := strings.CutPrefix(first(md, "authorization"), "Bearer "); ok {
+			username, role, err := tokens.Parse(token)
+			if err != nil {
+				log.Info("invalid token", sl.Err(err), slog.String("method", info.FullMethod))
+				return nil, status.Error(codes.Unauthenticated, "unauthorized")
+			}
+
+			return handler(auth.WithUser(ctx, storage.User{Username: username, Role: role}), req)
+		}
+
+		return nil, status.Error(codes.Unauthenticated, "unauthorized")
+	}
+}
+
+func first(md metadata.MD, key string) string {
+	if v := md.Get(key); len(v) > 0 {
+		return v[0]
+	}
+	return ""
+}
@@ -0,0 +1,100 @@
+package interceptor_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+
+	"url-shortener/internal/grpc-server/interceptor"
+	"url-shortener/internal/http-server/middleware/auth"
+	"url-shortener/internal/http-server/middleware/auth/mocks"
+	"url-shortener/internal/lib/apikey"
+	"url-shortener/internal/lib/logger/handlers/slogdiscard"
+	"url-shortener/internal/storage"
+)
+
+func TestAuth(t *testing.T) {
+	cases := []struct {
+		name     string
+		metadata metadata.MD
+		setup    func(tokens *mocks.TokenParser, keys *mocks.KeyGetter, users *mocks.UserAuthenticator)
+		code     codes.Code
+		user     string
+	}{
+		{
+			name:     "Bearer Token",
+			metadata: metadata.Pairs("authorization", "Bearer good"),
+			setup: func(tokens *mocks.TokenParser, _ *mocks.KeyGetter, _ *mocks.UserAuthenticator) {
+				tokens.On("Parse", "good").Return("alice", storage.RoleUser, nil).Once()
+			},
+			code: codes.OK,
+			user: "alice",
+		},
+		{
+			name:     "Invalid Token",
+			metadata: metadata.Pairs("authorization", "Bearer bad"),
+			setup: func(tokens *mocks.TokenParser, _ *mocks.KeyGetter, _ *mocks.UserAuthenticator) {
+				tokens.On("Parse", "bad").Return("", "", errors.New("invalid")).Once()
+			},
+			code: codes.Unauthenticated,
+		},
+		{
+			name:     "API Key",
+			metadata: metadata.Pairs("x-api-key", "key"),
+			setup: func(_ *mocks.TokenParser, keys *mocks.KeyGetter, users *mocks.UserAuthenticator) {
+				keys.On("GetAPIKeyByHash", mock.Anything, apikey.Hash("key")).
+					Return(storage.APIKey{ID: 1, Owner: "bob"}, nil).Once()
+				users.On("GetUser", mock.Anything, "bob").
+					Return(storage.User{Username: "bob", Role: storage.RoleUser}, nil).Once()
+			},
+			code: codes.OK,
+			user: "bob",
+		},
+		{
+			name:     "Unknown API Key",
+			metadata: metadata.Pairs("x-api-key", "key"),
+			setup: func(_ *mocks.TokenParser, keys *mocks.KeyGetter, _ *mocks.UserAuthenticator) {
+				keys.On("GetAPIKeyByHash", mock.Anything, apikey.Hash("key")).
+					Return(storage.APIKey{}, storage.ErrKeyNotFound).Once()
+			},
+			code: codes.Unauthenticated,
+		},
+		{
+			name: "No Credentials",
+			code: codes.Unauthenticated,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			tokens := mocks.NewTokenParser(t)
+			keys := mocks.NewKeyGetter(t)
+			users := mocks.NewUserAuthenticator(t)
+			if tc.setup != nil {
+				tc.setup(tokens, keys, users)
+			}
+
+			intercept := interceptor.Auth(slogdiscard.NewDiscardLogger(), tokens, keys, users)
+
+			var got string
+			handler := func(ctx context.Context, _ any) (any, error) {
+				user, _ := auth.UserFromContext(ctx)
+				got = user.Username
+				return nil, nil
+			}
+
+			ctx := metadata.NewIncomingContext(context.Background(), tc.metadata)
+			_, err := intercept(ctx, nil, &grpc.UnaryServerInfo{FullMethod: "/shortener.v1.URLShortener/ListURLs"}, handler)
+
+			require.Equal(t, tc.code, status.Code(err))
+			require.Equal(t, tc.user, got)
+		})
+	}
+}
@@ -0,0 +1,34 @@
+package interceptor
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+)
+
+// Logger logs every call with its status code, like the HTTP logger
+// middleware does for requests.
+func Logger(log *slog.Logger) grpc.UnaryServerInterceptor {
+	log = log.With(slog.String("component", "grpc/interceptor/logger"))
+
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		entry := log.With(slog.String("method", info.FullMethod))
+		if p, ok := peer.FromContext(ctx); ok {
+			entry = entry.With(slog.String("remote_addr", p.Addr.String()))
+		}
+
+		t1 := time.Now()
+		res, err := handler(ctx, req)
+
+		entry.Info("call completed",
+			slog.String("code", status.Code(err).String()),
+			slog.String("duration", time.Since(t1).String()),
+		)
+
+		return res, err
+	}
+}
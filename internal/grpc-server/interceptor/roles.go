@@ -0,0 +1,26 @@
+package interceptor
+
+import (
+	"context"
+	"slices"
+
+	"url-shortener/internal/http-server/middleware/auth"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// EditorOnly rejects calls of methods, given by full name, from viewers,
+// like the HTTP auth.EditorOnly middleware. It goes after Auth.
+func EditorOnly(methods ...string) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		if slices.Contains(methods, info.FullMethod) {
+			if user, _ := auth.UserFromContext(ctx); !user.CanEdit() {
+				return nil, status.Error(codes.PermissionDenied, "forbidden")
+			}
+		}
+
+		return handler(ctx, req)
+	}
+}
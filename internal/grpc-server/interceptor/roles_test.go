@@ -0,0 +1,52 @@
+package interceptor_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"url-shortener/internal/grpc-server/interceptor"
+	"url-shortener/internal/http-server/middleware/auth"
+	"url-shortener/internal/storage"
+)
+
+func TestEditorOnly(t *testing.T) {
+	const (
+		save = "/shortener.v1.URLShortener/SaveURL"
+		list = "/shortener.v1.URLShortener/ListURLs"
+	)
+
+	cases := []struct {
+		name   string
+		role   string
+		method string
+		code   codes.Code
+	}{
+		{name: "Editor saves", role: storage.RoleEditor, method: save, code: codes.OK},
+		{name: "Admin saves", role: storage.RoleAdmin, method: save, code: codes.OK},
+		{name: "Viewer saves", role: storage.RoleViewer, method: save, code: codes.PermissionDenied},
+		{name: "Viewer lists", role: storage.RoleViewer, method: list, code: codes.OK},
+	}
+
+	intercept := interceptor.EditorOnly(save)
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			called := false
+			handler := func(context.Context, any) (any, error) {
+				called = true
+				return nil, nil
+			}
+
+			ctx := auth.WithUser(context.Background(), storage.User{Username: "alice", Role: tc.role})
+			_, err := intercept(ctx, nil, &grpc.UnaryServerInfo{FullMethod: tc.method}, handler)
+
+			require.Equal(t, tc.code, status.Code(err))
+			require.Equal(t, tc.code == codes.OK, called)
+		})
+	}
+}
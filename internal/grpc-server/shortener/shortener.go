@@ -0,0 +1,312 @@
+package shortener
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+	shortenerv1 "url-shortener/api/proto/shortener/v1"
+	"url-shortener/internal/http-server/middleware/auth"
+	"url-shortener/internal/lib/alias"
+	"url-shortener/internal/lib/domain"
+	"url-shortener/internal/lib/logger/sl"
+	"url-shortener/internal/lib/quota"
+	"url-shortener/internal/storage"
+
+	"github.com/go-playground/validator/v10"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+const (
+	maxRetries   = 5
+	defaultLimit = 20
+	maxLimit     = 100
+)
+
+// EditMethods are the methods that change links, which viewers may not
+// call; see interceptor.EditorOnly.
+var EditMethods = []string{
+	shortenerv1.URLShortener_SaveURL_FullMethodName,
+	shortenerv1.URLShortener_DeleteURL_FullMethodName,
+}
+
+//go:generate go run github.com/vektra/mockery/v2@latest --name=URLStorage
+type URLStorage interface {
+	SaveURL(ctx context.Context, u storage.URL) (int64, error)
+	GetURL(ctx context.Context, alias string) (storage.URL, error)
+	DeleteURL(ctx context.Context, alias string, owner string) error
+	ListURLs(ctx context.Context, filter storage.ListFilter, limit int, offset int, desc bool) ([]storage.URL, error)
+}
+
+// AliasGenerator makes aliases for links saved without one.
+type AliasGenerator interface {
+	Generate(ctx context.Context) (string, error)
+}
+
+// URLChecker reports a threat type for each unsafe URL, empty for the rest.
+type URLChecker interface {
+	Check(ctx context.Context, urls []string) ([]string, error)
+}
+
+// QuotaChecker tells whether a user or an API key may create n more links.
+type QuotaChecker interface {
+	Check(ctx context.Context, owner string, apiKeyID int64, n int) (quota.Usage, error)
+}
+
+// Server implements the URLShortener gRPC service on top of the same
+// storage as the HTTP handlers. It expects the user to be put into the
+// context by interceptor.Auth.
+type Server struct {
+	shortenerv1.UnimplementedURLShortenerServer
+	log       *slog.Logger
+	storage   URLStorage
+	aliases   *alias.Validator
+	domains   *domain.Policy
+	generator AliasGenerator
+	checker   URLChecker
+	quotas    QuotaChecker
+}
+
+// Register adds the service to gRPC. checker may be nil to save links
+// without screening them, and quotas to let everyone create any number of
+// links.
+func Register(
+	gRPC *grpc.Server,
+	log *slog.Logger,
+	urlStorage URLStorage,
+	aliases *alias.Validator,
+	domains *domain.Policy,
+	generator AliasGenerator,
+	checker URLChecker,
+	quotas QuotaChecker,
+) {
+	shortenerv1.RegisterURLShortenerServer(gRPC, &Server{
+		log:       log,
+		storage:   urlStorage,
+		aliases:   aliases,
+		domains:   domains,
+		generator: generator,
+		checker:   checker,
+		quotas:    quotas,
+	})
+}
+
+func (s *Server) SaveURL(ctx context.Context, req *shortenerv1.SaveURLRequest) (*shortenerv1.SaveURLResponse, error) {
+	const op = "grpc.shortener.SaveURL"
+
+	log := s.log.With(slog.String("op", op))
+
+	if err := validator.New().Var(req.GetUrl(), "required,url"); err != nil {
+		return nil, status.Error(codes.InvalidArgument, "invalid url")
+	}
+	// :authority is the gRPC counterpart of the Host header.
+	var authority string
+	if md, ok := metadata.FromIncomingContext(ctx); ok && len(md.Get(":authority")) > 0 {
+		authority = md.Get(":authority")[0]
+	}
+	if err := s.domains.Check(req.GetUrl(), authority); err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	if s.checker != nil {
+		threats, err := s.checker.Check(ctx, []string{req.GetUrl()})
+		if err != nil {
+			// Like the HTTP API, save the link when the check itself fails.
+			log.Error("failed to check url reputation", sl.Err(err))
+		} else if threats[0] != "" {
+			log.Warn("unsafe url rejected", slog.String("url", req.GetUrl()), slog.String("threat", threats[0]))
+			return nil, status.Error(codes.InvalidArgument, "url is flagged as unsafe: "+threats[0])
+		}
+	}
+
+	user, _ := auth.UserFromContext(ctx)
+	u := storage.URL{URL: req.GetUrl(), Alias: req.GetAlias(), Owner: user.Username}
+
+	if u.Alias != "" {
+		if err := s.aliases.Validate(u.Alias); err != nil {
+			return nil, status.Error(codes.InvalidArgument, err.Error())
+		}
+	}
+
+	if s.quotas != nil {
+		keyID, _ := auth.APIKeyIDFromContext(ctx)
+		usage, err := s.quotas.Check(ctx, auth.OwnerFromContext(ctx), keyID, 1)
+		if errors.Is(err, quota.ErrExceeded) {
+			log.Info("quota exceeded", slog.Int64("links", usage.Links), slog.Int64("today", usage.Today))
+			return nil, status.Error(codes.ResourceExhausted, quotaMessage(usage))
+		}
+		if err != nil {
+			log.Error("failed to check quota", sl.Err(err))
+			return nil, status.Error(codes.Internal, "failed to save url")
+		}
+	}
+
+	if u.Alias != "" {
+		u.Alias = s.aliases.Sign(u.Alias)
+
+		_, err := s.storage.SaveURL(ctx, u)
+		if errors.Is(err, storage.ErrUrlExists) {
+			return nil, status.Error(codes.AlreadyExists, "url already exists")
+		}
+		if err != nil {
+			log.Error("failed to save url", sl.Err(err))
+			return nil, status.Error(codes.Internal, "failed to save url")
+		}
+
+		log.Info("url added", slog.String("alias", u.Alias))
+
+		return &shortenerv1.SaveURLResponse{Alias: u.Alias}, nil
+	}
+
+	for i := 0; i < maxRetries; i++ {
+		var err error
+		u.Alias, err = s.generator.Generate(ctx)
+		if err != nil {
+			log.Error("failed to generate alias", sl.Err(err))
+			return nil, status.Error(codes.Internal, "failed to save url")
+		}
+
+		_, err = s.storage.SaveURL(ctx, u)
+		if err == nil {
+			log.Info("url added", slog.String("alias", u.Alias))
+			return &shortenerv1.SaveURLResponse{Alias: u.Alias}, nil
+		}
+		if !errors.Is(err, storage.ErrUrlExists) {
+			log.Error("failed to save url", sl.Err(err))
+			return nil, status.Error(codes.Internal, "failed to save url")
+		}
+
+		log.Info("alias collision, retrying", slog.String("alias", u.Alias), slog.Int("attempt", i+1))
+	}
+
+	log.Error("failed to generate unique alias after retries", slog.Int("max_retries", maxRetries))
+
+	return nil, status.Error(codes.Internal, "failed to generate unique alias")
+}
+
+// quotaMessage tells how much of the quota is used up, leaving out the
+// limits that are not set.
+func quotaMessage(usage quota.Usage) string {
+	msg := "quota exceeded"
+	if usage.MaxLinks > 0 {
+		msg += fmt.Sprintf(": %d of %d links", usage.Links, usage.MaxLinks)
+	}
+	if usage.MaxPerDay > 0 {
+		sep := ","
+		if usage.MaxLinks == 0 {
+			sep = ":"
+		}
+		msg += fmt.Sprintf("%s %d of %d today", sep, usage.Today, usage.MaxPerDay)
+	}
+
+	return msg
+}
+
+// GetURL returns a link the caller owns; admins and viewers can get any
+// link.
+func (s *Server) GetURL(ctx context.Context, req *shortenerv1.GetURLRequest) (*shortenerv1.GetURLResponse, error) {
+	const op = "grpc.shortener.GetURL"
+
+	log := s.log.With(slog.String("op", op))
+
+	if req.GetAlias() == "" {
+		return nil, status.Error(codes.InvalidArgument, "alias is required")
+	}
+
+	u, err := s.storage.GetURL(ctx, req.GetAlias())
+	switch {
+	case errors.Is(err, storage.ErrUrlNotFound):
+		return nil, status.Error(codes.NotFound, "url not found")
+	case errors.Is(err, storage.ErrUrlBlocked):
+		return nil, status.Error(codes.FailedPrecondition, "url blocked")
+	case errors.Is(err, storage.ErrUrlUnsafe):
+		return nil, status.Error(codes.FailedPrecondition, "url flagged as unsafe")
+	case errors.Is(err, storage.ErrUrlExpired):
+		return nil, status.Error(codes.FailedPrecondition, "url expired")
+	case errors.Is(err, storage.ErrUrlExhausted):
+		return nil, status.Error(codes.FailedPrecondition, "url click limit reached")
+	case err != nil:
+		log.Error("failed to get url", sl.Err(err))
+		return nil, status.Error(codes.Internal, "internal error")
+	}
+
+	if owner := auth.ViewerFromContext(ctx); owner != "" && u.Owner != owner {
+		return nil, status.Error(codes.NotFound, "url not found")
+	}
+
+	return &shortenerv1.GetURLResponse{Url: toProto(u)}, nil
+}
+
+func (s *Server) DeleteURL(ctx context.Context, req *shortenerv1.DeleteURLRequest) (*shortenerv1.DeleteURLResponse, error) {
+	const op = "grpc.shortener.DeleteURL"
+
+	log := s.log.With(slog.String("op", op))
+
+	if req.GetAlias() == "" {
+		return nil, status.Error(codes.InvalidArgument, "alias is required")
+	}
+
+	err := s.storage.DeleteURL(ctx, req.GetAlias(), auth.OwnerFromContext(ctx))
+	if errors.Is(err, storage.ErrUrlNotFound) {
+		return nil, status.Error(codes.NotFound, "url not found")
+	}
+	if err != nil {
+		log.Error("failed to delete url", sl.Err(err))
+		return nil, status.Error(codes.Internal, "internal error")
+	}
+
+	log.Info("url deleted", slog.String("alias", req.GetAlias()))
+
+	return &shortenerv1.DeleteURLResponse{}, nil
+}
+
+func (s *Server) ListURLs(ctx context.Context, req *shortenerv1.ListURLsRequest) (*shortenerv1.ListURLsResponse, error) {
+	const op = "grpc.shortener.ListURLs"
+
+	log := s.log.With(slog.String("op", op))
+
+	limit := int(req.GetLimit())
+	if limit == 0 {
+		limit = defaultLimit
+	}
+	if limit < 0 || limit > maxLimit {
+		return nil, status.Error(codes.InvalidArgument, "invalid limit")
+	}
+	if req.GetOffset() < 0 {
+		return nil, status.Error(codes.InvalidArgument, "invalid offset")
+	}
+
+	urls, err := s.storage.ListURLs(ctx, storage.ListFilter{Owner: auth.ViewerFromContext(ctx)}, limit, int(req.GetOffset()), !req.GetAscending())
+	if err != nil {
+		log.Error("failed to list urls", sl.Err(err))
+		return nil, status.Error(codes.Internal, "failed to list urls")
+	}
+
+	res := &shortenerv1.ListURLsResponse{Urls: make([]*shortenerv1.URL, 0, len(urls))}
+	for _, u := range urls {
+		res.Urls = append(res.Urls, toProto(u))
+	}
+
+	return res, nil
+}
+
+func toProto(u storage.URL) *shortenerv1.URL {
+	return &shortenerv1.URL{
+		Alias:     u.Alias,
+		Url:       u.URL,
+		Owner:     u.Owner,
+		CreatedAt: timestamp(u.CreatedAt),
+		ExpiresAt: timestamp(u.ExpiresAt),
+	}
+}
+
+func timestamp(t time.Time) *timestamppb.Timestamp {
+	if t.IsZero() {
+		return nil
+	}
+	return timestamppb.New(t)
+}
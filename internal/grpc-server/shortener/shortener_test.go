@@ -0,0 +1,200 @@
+package shortener_test
+
+import (
+	"context"
+	"errors"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+
+	shortenerv1 "url-shortener/api/proto/shortener/v1"
+	"url-shortener/internal/grpc-server/shortener"
+	"url-shortener/internal/grpc-server/shortener/mocks"
+	"url-shortener/internal/http-server/middleware/auth"
+	"url-shortener/internal/lib/alias"
+	"url-shortener/internal/lib/domain"
+	"url-shortener/internal/lib/logger/handlers/slogdiscard"
+	"url-shortener/internal/lib/quota"
+	"url-shortener/internal/lib/reputation"
+	"url-shortener/internal/storage"
+)
+
+// newClient serves the service over an in-memory listener, acting as user.
+// quotas may be nil.
+func newClient(t *testing.T, urlStorage shortener.URLStorage, user storage.User, quotas shortener.QuotaChecker) shortenerv1.URLShortenerClient {
+	t.Helper()
+
+	lis := bufconn.Listen(1 << 20)
+	srv := grpc.NewServer(grpc.UnaryInterceptor(
+		func(ctx context.Context, req any, _ *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+			return handler(auth.WithUser(ctx, user), req)
+		},
+	))
+	aliases, err := alias.New(alias.Rules{MinLength: 3, Reserved: []string{"admin"}})
+	require.NoError(t, err)
+	domains, err := domain.New(domain.Rules{})
+	require.NoError(t, err)
+	shortener.Register(srv, slogdiscard.NewDiscardLogger(), urlStorage, aliases, domains, alias.DefaultRandom(), reputation.NewBlocklist([]string{"malware.example"}), quotas)
+	go func() { _ = srv.Serve(lis) }()
+	t.Cleanup(srv.Stop)
+
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return lis.DialContext(ctx)
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = conn.Close() })
+
+	return shortenerv1.NewURLShortenerClient(conn)
+}
+
+var alice = storage.User{Username: "alice", Role: storage.RoleUser}
+
+func TestServer_SaveURL(t *testing.T) {
+	cases := []struct {
+		name      string
+		url       string
+		alias     string
+		mockError error
+		code      codes.Code
+	}{
+		{name: "Success", url: "https://example.com", alias: "example", code: codes.OK},
+		{name: "Random Alias", url: "https://example.com", code: codes.OK},
+		{name: "Invalid URL", url: "not a url", alias: "example", code: codes.InvalidArgument},
+		{name: "Reserved Alias", url: "https://example.com", alias: "admin", code: codes.InvalidArgument},
+		{name: "Unsafe URL", url: "https://malware.example/login", alias: "example", code: codes.InvalidArgument},
+		{name: "Exists", url: "https://example.com", alias: "example", mockError: storage.ErrUrlExists, code: codes.AlreadyExists},
+		{name: "Storage Error", url: "https://example.com", alias: "example", mockError: errors.New("boom"), code: codes.Internal},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			storageMock := mocks.NewURLStorage(t)
+			if tc.code != codes.InvalidArgument {
+				storageMock.On("SaveURL", mock.Anything, mock.MatchedBy(func(u storage.URL) bool {
+					return u.URL == tc.url && u.Owner == "alice" && (tc.alias == "" || u.Alias == tc.alias)
+				})).Return(int64(1), tc.mockError).Once()
+			}
+
+			client := newClient(t, storageMock, alice, nil)
+
+			res, err := client.SaveURL(context.Background(), &shortenerv1.SaveURLRequest{Url: tc.url, Alias: tc.alias})
+			require.Equal(t, tc.code, status.Code(err))
+			if tc.code == codes.OK {
+				require.NotEmpty(t, res.GetAlias())
+			}
+		})
+	}
+}
+
+type quotaFunc func(ctx context.Context, owner string, apiKeyID int64, n int) (quota.Usage, error)
+
+func (f quotaFunc) Check(ctx context.Context, owner string, apiKeyID int64, n int) (quota.Usage, error) {
+	return f(ctx, owner, apiKeyID, n)
+}
+
+func TestServer_SaveURL_Quota(t *testing.T) {
+	// No link is saved, so the storage mock expects no call.
+	storageMock := mocks.NewURLStorage(t)
+	quotas := quotaFunc(func(_ context.Context, owner string, _ int64, n int) (quota.Usage, error) {
+		require.Equal(t, "alice", owner)
+		require.Equal(t, 1, n)
+		return quota.Usage{Links: 2, MaxLinks: 2, Today: 1, MaxPerDay: 5}, quota.ErrExceeded
+	})
+
+	client := newClient(t, storageMock, alice, quotas)
+
+	for _, a := range []string{"example", ""} {
+		_, err := client.SaveURL(context.Background(), &shortenerv1.SaveURLRequest{Url: "https://example.com", Alias: a})
+		require.Equal(t, codes.ResourceExhausted, status.Code(err))
+		require.Equal(t, "quota exceeded: 2 of 2 links, 1 of 5 today", status.Convert(err).Message())
+	}
+}
+
+func TestServer_GetURL(t *testing.T) {
+	createdAt := time.Date(2025, 3, 1, 10, 0, 0, 0, time.UTC)
+
+	cases := []struct {
+		name      string
+		user      storage.User
+		url       storage.URL
+		mockError error
+		code      codes.Code
+	}{
+		{
+			name: "Own Link",
+			user: alice,
+			url:  storage.URL{Alias: "a", URL: "https://example.com", Owner: "alice", CreatedAt: createdAt},
+			code: codes.OK,
+		},
+		{
+			name: "Someone Else's Link",
+			user: alice,
+			url:  storage.URL{Alias: "a", URL: "https://example.com", Owner: "bob"},
+			code: codes.NotFound,
+		},
+		{
+			name: "Admin",
+			user: storage.User{Username: "root", Role: storage.RoleAdmin},
+			url:  storage.URL{Alias: "a", URL: "https://example.com", Owner: "bob"},
+			code: codes.OK,
+		},
+		{name: "Not Found", user: alice, mockError: storage.ErrUrlNotFound, code: codes.NotFound},
+		{name: "Expired", user: alice, mockError: storage.ErrUrlExpired, code: codes.FailedPrecondition},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			storageMock := mocks.NewURLStorage(t)
+			storageMock.On("GetURL", mock.Anything, "a").Return(tc.url, tc.mockError).Once()
+
+			client := newClient(t, storageMock, tc.user, nil)
+
+			res, err := client.GetURL(context.Background(), &shortenerv1.GetURLRequest{Alias: "a"})
+			require.Equal(t, tc.code, status.Code(err))
+			if tc.code == codes.OK {
+				require.Equal(t, tc.url.URL, res.GetUrl().GetUrl())
+				require.Nil(t, res.GetUrl().GetExpiresAt())
+			}
+		})
+	}
+}
+
+func TestServer_DeleteURL(t *testing.T) {
+	storageMock := mocks.NewURLStorage(t)
+	storageMock.On("DeleteURL", mock.Anything, "a", "alice").Return(nil).Once()
+	storageMock.On("DeleteURL", mock.Anything, "b", "alice").Return(storage.ErrUrlNotFound).Once()
+
+	client := newClient(t, storageMock, alice, nil)
+
+	_, err := client.DeleteURL(context.Background(), &shortenerv1.DeleteURLRequest{Alias: "a"})
+	require.NoError(t, err)
+
+	_, err = client.DeleteURL(context.Background(), &shortenerv1.DeleteURLRequest{Alias: "b"})
+	require.Equal(t, codes.NotFound, status.Code(err))
+}
+
+func TestServer_ListURLs(t *testing.T) {
+	storageMock := mocks.NewURLStorage(t)
+	storageMock.On("ListURLs", mock.Anything, storage.ListFilter{Owner: "alice"}, 20, 0, true).
+		Return([]storage.URL{{Alias: "a", URL: "https://example.com", Owner: "alice"}}, nil).Once()
+
+	client := newClient(t, storageMock, alice, nil)
+
+	res, err := client.ListURLs(context.Background(), &shortenerv1.ListURLsRequest{})
+	require.NoError(t, err)
+	require.Len(t, res.GetUrls(), 1)
+
+	_, err = client.ListURLs(context.Background(), &shortenerv1.ListURLsRequest{Limit: 1000})
+	require.Equal(t, codes.InvalidArgument, status.Code(err))
+}
@@ -0,0 +1,45 @@
+package admin
+
+import (
+	"embed"
+	"encoding/json"
+	"io/fs"
+	"net/http"
+)
+
+//go:embed static
+var static embed.FS
+
+// Options are the server features the dashboard adapts to; it reads them
+// from config.json.
+type Options struct {
+	// SSO shows a button that signs in through /auth/oidc/login.
+	SSO bool `json:"sso"`
+}
+
+// New serves the admin dashboard mounted at prefix, e.g. /admin. The
+// dashboard is a static page: it signs in through /auth/login and manages
+// links with the same API calls as any other client, so it needs no
+// handlers of its own and sees only what the signed-in user may see.
+func New(prefix string, opts Options) http.Handler {
+	assets, err := fs.Sub(static, "static")
+	if err != nil {
+		// static is embedded at build time, so this cannot happen.
+		panic(err)
+	}
+
+	files := http.StripPrefix(prefix, http.FileServer(http.FS(assets)))
+	config, _ := json.Marshal(opts)
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Cache-Control", "no-cache")
+		w.Header().Set("X-Frame-Options", "DENY")
+		w.Header().Set("Content-Security-Policy", "default-src 'self'; img-src 'self' data:")
+		if r.URL.Path == prefix+"/config.json" {
+			w.Header().Set("Content-Type", "application/json")
+			_, _ = w.Write(config)
+			return
+		}
+		files.ServeHTTP(w, r)
+	})
+}
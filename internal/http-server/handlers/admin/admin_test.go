@@ -0,0 +1,65 @@
+package admin_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/require"
+
+	"url-shortener/internal/http-server/handlers/admin"
+)
+
+func TestAdmin(t *testing.T) {
+	cases := []struct {
+		name        string
+		path        string
+		status      int
+		contentType string
+		contains    string
+	}{
+		{
+			name:        "Index",
+			path:        "/admin/",
+			status:      http.StatusOK,
+			contentType: "text/html; charset=utf-8",
+			contains:    "<title>url-shortener admin</title>",
+		},
+		{
+			name:        "Script",
+			path:        "/admin/app.js",
+			status:      http.StatusOK,
+			contentType: "text/javascript; charset=utf-8",
+			contains:    `fetch(apiPrefix + "/auth/login"`,
+		},
+		{
+			name:        "Config",
+			path:        "/admin/config.json",
+			status:      http.StatusOK,
+			contentType: "application/json",
+			contains:    `{"sso":true}`,
+		},
+		{
+			name:   "Missing",
+			path:   "/admin/missing.js",
+			status: http.StatusNotFound,
+		},
+	}
+
+	r := chi.NewRouter()
+	r.Handle("/admin/*", admin.New("/admin", admin.Options{SSO: true}))
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			rr := httptest.NewRecorder()
+			r.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, tc.path, nil))
+
+			require.Equal(t, tc.status, rr.Code)
+			if tc.contentType != "" {
+				require.Equal(t, tc.contentType, rr.Header().Get("Content-Type"))
+				require.Contains(t, rr.Body.String(), tc.contains)
+			}
+		})
+	}
+}
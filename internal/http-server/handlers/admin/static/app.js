@@ -0,0 +1,224 @@
+"use strict";
+
+// The dashboard talks to the regular HTTP API with a JWT from /auth/login,
+// kept for the browser tab only. Single sign-on hands the JWT back in the
+// URL fragment of the redirect to the dashboard.
+const apiPrefix = "/api/v1";
+const pageSize = 20;
+let offset = 0;
+
+const $ = (id) => document.getElementById(id);
+
+function token() {
+  return sessionStorage.getItem("token");
+}
+
+async function api(method, path, body) {
+  const headers = { Authorization: "Bearer " + token() };
+  if (body !== undefined) {
+    headers["Content-Type"] = "application/json";
+  }
+
+  const res = await fetch(apiPrefix + path, {
+    method,
+    headers,
+    body: body === undefined ? undefined : JSON.stringify(body),
+  });
+  if (res.status === 401) {
+    signOut();
+    throw new Error("session expired, sign in again");
+  }
+
+  const data = await res.json();
+  if (!res.ok || data.status === "Error") {
+    throw new Error(data.error || res.statusText);
+  }
+  return data;
+}
+
+function showError(err) {
+  $("error").textContent = err ? err.message : "";
+  $("error").hidden = !err;
+}
+
+function show() {
+  const signedIn = token() !== null;
+  $("login-view").hidden = signedIn;
+  $("links-view").hidden = !signedIn;
+  $("logout").hidden = !signedIn;
+  $("user").hidden = !signedIn;
+  $("user").textContent = sessionStorage.getItem("user") || "";
+  // Viewers can look at links and stats but not change them.
+  document.body.classList.toggle("viewer", sessionStorage.getItem("role") === "viewer");
+  if (signedIn) {
+    loadLinks().catch(showError);
+  }
+}
+
+function signOut() {
+  sessionStorage.clear();
+  show();
+}
+
+function cell(text, className) {
+  const td = document.createElement("td");
+  td.textContent = text;
+  if (className) {
+    td.className = className;
+  }
+  return td;
+}
+
+function button(label, className, onClick) {
+  const b = document.createElement("button");
+  b.type = "button";
+  b.className = className;
+  b.textContent = label;
+  b.addEventListener("click", () => onClick().catch(showError));
+  return b;
+}
+
+async function loadLinks() {
+  const data = await api("GET", `/urls?limit=${pageSize}&offset=${offset}`);
+  const rows = data.urls.map((u) => {
+    const tr = document.createElement("tr");
+
+    const link = document.createElement("a");
+    link.href = u.short_url;
+    link.textContent = "/" + u.alias;
+    const short = document.createElement("td");
+    short.append(link);
+
+    const actions = document.createElement("td");
+    actions.className = "actions";
+    actions.append(
+      button("Stats", "", () => loadStats(u.alias)),
+      button("Delete", "edit", async () => {
+        if (!confirm(`Delete /${u.alias}?`)) {
+          return;
+        }
+        await api("DELETE", "/url/" + encodeURIComponent(u.alias));
+        await loadLinks();
+      }),
+    );
+
+    tr.append(short, cell(u.url, "url"), cell(new Date(u.created_at).toLocaleString()), actions);
+    return tr;
+  });
+
+  $("links").replaceChildren(...rows);
+  $("prev").disabled = offset === 0;
+  $("next").disabled = data.urls.length < pageSize;
+  showError(null);
+}
+
+async function loadStats(alias) {
+  const data = await api("GET", `/url/${encodeURIComponent(alias)}/stats`);
+  $("stats-alias").textContent = "/" + alias;
+  $("stats-total").textContent = data.total_clicks;
+  $("stats-last").textContent = data.last_access ? new Date(data.last_access).toLocaleString() : "never";
+
+  const max = Math.max(1, ...data.daily.map((d) => d.clicks));
+  const bars = data.daily.map((d) => {
+    const bar = document.createElement("div");
+    bar.style.height = (100 * d.clicks) / max + "%";
+    bar.title = `${d.date}: ${d.clicks}`;
+    return bar;
+  });
+  $("stats-chart").replaceChildren(...bars);
+  $("stats").hidden = false;
+  showError(null);
+}
+
+$("login").addEventListener("submit", async (e) => {
+  e.preventDefault();
+  const form = new FormData(e.target);
+  try {
+    const res = await fetch(apiPrefix + "/auth/login", {
+      method: "POST",
+      headers: { "Content-Type": "application/json" },
+      body: JSON.stringify({ username: form.get("username"), password: form.get("password") }),
+    });
+    const data = await res.json();
+    if (!res.ok) {
+      throw new Error(data.error || res.statusText);
+    }
+    sessionStorage.setItem("token", data.token);
+    sessionStorage.setItem("user", form.get("username"));
+    sessionStorage.setItem("role", data.role || "");
+    e.target.reset();
+    showError(null);
+    show();
+  } catch (err) {
+    showError(err);
+  }
+});
+
+$("create").addEventListener("submit", async (e) => {
+  e.preventDefault();
+  const form = new FormData(e.target);
+  const body = { url: form.get("url") };
+  if (form.get("alias")) {
+    body.alias = form.get("alias");
+  }
+  try {
+    const data = await api("POST", "/url", body);
+    $("created").textContent = "Created " + data.short_url;
+    $("created").hidden = false;
+    e.target.reset();
+    $("suggestions").hidden = true;
+    offset = 0;
+    await loadLinks();
+  } catch (err) {
+    showError(err);
+  }
+});
+
+// Readable free aliases for the URL typed in; a click picks one.
+$("suggest").addEventListener("click", async () => {
+  const form = $("create");
+  if (!form.url.reportValidity()) {
+    return;
+  }
+  try {
+    const data = await api("GET", "/suggest?url=" + encodeURIComponent(form.url.value));
+    $("suggestions").replaceChildren(...data.suggestions.map((alias) =>
+      button(alias, "suggestion", async () => {
+        form.alias.value = alias;
+      })));
+    $("suggestions").hidden = data.suggestions.length === 0;
+  } catch (err) {
+    showError(err);
+  }
+});
+
+$("prev").addEventListener("click", () => {
+  offset = Math.max(0, offset - pageSize);
+  loadLinks().catch(showError);
+});
+$("next").addEventListener("click", () => {
+  offset += pageSize;
+  loadLinks().catch(showError);
+});
+$("logout").addEventListener("click", signOut);
+
+function takeSSOToken() {
+  const params = new URLSearchParams(location.hash.slice(1));
+  if (!params.has("token")) {
+    return;
+  }
+  sessionStorage.setItem("token", params.get("token"));
+  sessionStorage.setItem("user", params.get("user") || "");
+  sessionStorage.setItem("role", params.get("role") || "");
+  history.replaceState(null, "", location.pathname + location.search);
+}
+
+fetch("config.json")
+  .then((res) => res.json())
+  .then((config) => {
+    $("sso").hidden = !config.sso;
+  })
+  .catch(() => {});
+
+takeSSOToken();
+show();
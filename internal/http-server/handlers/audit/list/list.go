@@ -0,0 +1,131 @@
+package list
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"time"
+	resp "url-shortener/internal/lib/api/response"
+	"url-shortener/internal/lib/logger/sl"
+	"url-shortener/internal/storage"
+
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/go-chi/render"
+)
+
+type Entry struct {
+	ID       int64     `json:"id"`
+	Time     time.Time `json:"time"`
+	Action   string    `json:"action"`
+	Target   string    `json:"target"`
+	Actor    string    `json:"actor,omitempty"`
+	APIKeyID int64     `json:"api_key_id,omitempty"`
+	IP       string    `json:"ip,omitempty"`
+	// Before and After are JSON snapshots of the target.
+	Before json.RawMessage `json:"before,omitempty"`
+	After  json.RawMessage `json:"after,omitempty"`
+}
+
+type Response struct {
+	resp.Response
+	Entries []Entry `json:"entries"`
+	Limit   int     `json:"limit"`
+	Offset  int     `json:"offset"`
+}
+
+const (
+	defaultLimit = 50
+	maxLimit     = 500
+)
+
+//go:generate go run github.com/vektra/mockery/v2@latest --name=AuditLister
+type AuditLister interface {
+	ListAuditLog(ctx context.Context, filter storage.AuditFilter, limit int, offset int) ([]storage.AuditEntry, error)
+}
+
+// New lists the audit log, newest first. It is meant for admins.
+//
+// Query params: limit (default 50, max 500), offset, and the filters
+// action, target (an alias, API key id or username) and actor.
+func New(log *slog.Logger, auditLister AuditLister) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.audit.list.New"
+
+		log := log.With(
+			slog.String("op", op),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+
+		query := r.URL.Query()
+
+		limit, err := intParam(query.Get("limit"), defaultLimit)
+		if err != nil || limit <= 0 || limit > maxLimit {
+			log.Info("invalid limit", slog.String("limit", query.Get("limit")))
+			render.Status(r, http.StatusBadRequest)
+			render.JSON(w, r, resp.Error("invalid limit"))
+			return
+		}
+
+		offset, err := intParam(query.Get("offset"), 0)
+		if err != nil || offset < 0 {
+			log.Info("invalid offset", slog.String("offset", query.Get("offset")))
+			render.Status(r, http.StatusBadRequest)
+			render.JSON(w, r, resp.Error("invalid offset"))
+			return
+		}
+
+		filter := storage.AuditFilter{
+			Action: query.Get("action"),
+			Target: query.Get("target"),
+			Actor:  query.Get("actor"),
+		}
+
+		entries, err := auditLister.ListAuditLog(r.Context(), filter, limit, offset)
+		if err != nil {
+			log.Error("failed to list audit log", sl.Err(err))
+			render.Status(r, http.StatusInternalServerError)
+			render.JSON(w, r, resp.Error("failed to list audit log"))
+			return
+		}
+
+		res := Response{
+			Response: resp.OK(),
+			Entries:  make([]Entry, 0, len(entries)),
+			Limit:    limit,
+			Offset:   offset,
+		}
+		for _, e := range entries {
+			res.Entries = append(res.Entries, Entry{
+				ID:       e.ID,
+				Time:     e.Time,
+				Action:   e.Action,
+				Target:   e.Target,
+				Actor:    e.Actor,
+				APIKeyID: e.APIKeyID,
+				IP:       e.IP,
+				Before:   raw(e.Before),
+				After:    raw(e.After),
+			})
+		}
+
+		render.JSON(w, r, res)
+	}
+}
+
+// raw keeps empty snapshots out of the response.
+func raw(snapshot string) json.RawMessage {
+	if snapshot == "" {
+		return nil
+	}
+	return json.RawMessage(snapshot)
+}
+
+func intParam(value string, def int) (int, error) {
+	if value == "" {
+		return def, nil
+	}
+
+	return strconv.Atoi(value)
+}
@@ -0,0 +1,109 @@
+package list_test
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"url-shortener/internal/http-server/handlers/audit/list"
+	"url-shortener/internal/http-server/handlers/audit/list/mocks"
+	"url-shortener/internal/lib/logger/handlers/slogdiscard"
+	"url-shortener/internal/storage"
+)
+
+func TestListHandler(t *testing.T) {
+	entries := []storage.AuditEntry{
+		{
+			ID:     2,
+			Time:   time.Date(2025, 3, 1, 10, 0, 0, 0, time.UTC),
+			Action: storage.AuditLinkUpdate,
+			Target: "example",
+			Actor:  "alice",
+			IP:     "203.0.113.7",
+			Before: `{"url":"https://example.com"}`,
+			After:  `{"url":"https://example.org"}`,
+		},
+		{ID: 1, Action: storage.AuditKeyRevoke, Target: "3", Actor: "alice"},
+	}
+
+	cases := []struct {
+		name      string
+		query     string
+		filter    storage.AuditFilter
+		limit     int
+		offset    int
+		status    int
+		respError string
+		mockError error
+	}{
+		{
+			name:   "Success",
+			filter: storage.AuditFilter{},
+			limit:  50,
+			status: http.StatusOK,
+		},
+		{
+			name:   "Filtered",
+			query:  "?target=example&actor=alice&action=link.update&limit=10&offset=5",
+			filter: storage.AuditFilter{Action: storage.AuditLinkUpdate, Target: "example", Actor: "alice"},
+			limit:  10,
+			offset: 5,
+			status: http.StatusOK,
+		},
+		{
+			name:      "Invalid limit",
+			query:     "?limit=1000",
+			status:    http.StatusBadRequest,
+			respError: "invalid limit",
+		},
+		{
+			name:      "Invalid offset",
+			query:     "?offset=-1",
+			status:    http.StatusBadRequest,
+			respError: "invalid offset",
+		},
+		{
+			name:      "ListAuditLog Error",
+			limit:     50,
+			status:    http.StatusInternalServerError,
+			respError: "failed to list audit log",
+			mockError: errors.New("unexpected error"),
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			auditListerMock := mocks.NewAuditLister(t)
+			if tc.limit > 0 {
+				auditListerMock.On("ListAuditLog", mock.Anything, tc.filter, tc.limit, tc.offset).
+					Return(entries, tc.mockError).Once()
+			}
+
+			handler := list.New(slogdiscard.NewDiscardLogger(), auditListerMock)
+
+			req := httptest.NewRequest(http.MethodGet, "/audit"+tc.query, nil)
+			rr := httptest.NewRecorder()
+			handler.ServeHTTP(rr, req)
+
+			require.Equal(t, tc.status, rr.Code)
+
+			var resp list.Response
+			require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &resp))
+
+			require.Equal(t, tc.respError, resp.Error)
+
+			if tc.respError == "" {
+				require.Len(t, resp.Entries, 2)
+				require.JSONEq(t, entries[0].Before, string(resp.Entries[0].Before))
+				require.JSONEq(t, entries[0].After, string(resp.Entries[0].After))
+				require.Nil(t, resp.Entries[1].Before)
+			}
+		})
+	}
+}
@@ -0,0 +1,102 @@
+package login
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+	"time"
+	resp "url-shortener/internal/lib/api/response"
+	"url-shortener/internal/lib/logger/sl"
+	"url-shortener/internal/storage"
+	"url-shortener/internal/users"
+
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/go-chi/render"
+	"github.com/go-playground/validator/v10"
+)
+
+type Request struct {
+	Username string `json:"username" validate:"required"`
+	Password string `json:"password" validate:"required"`
+}
+
+type Response struct {
+	resp.Response
+	Token     string    `json:"token,omitempty"`
+	ExpiresAt time.Time `json:"expires_at,omitempty"`
+	// Role tells clients what the token allows, e.g. to hide edit
+	// controls from viewers.
+	Role string `json:"role,omitempty"`
+}
+
+//go:generate go run github.com/vektra/mockery/v2@latest --name=TokenIssuer
+type TokenIssuer interface {
+	Issue(subject string, role string, now time.Time) (string, time.Time, error)
+}
+
+//go:generate go run github.com/vektra/mockery/v2@latest --name=Authenticator
+type Authenticator interface {
+	Authenticate(ctx context.Context, username string, password string) (storage.User, error)
+}
+
+// New exchanges a username and password for a signed access token to be
+// sent as "Authorization: Bearer <token>".
+func New(log *slog.Logger, tokenIssuer TokenIssuer, authenticator Authenticator) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.auth.login.New"
+
+		log := log.With(
+			slog.String("op", op),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+
+		var req Request
+		if err := render.DecodeJSON(r.Body, &req); err != nil {
+			log.Error("failed to decode request body", sl.Err(err))
+			render.Status(r, http.StatusBadRequest)
+			render.JSON(w, r, resp.Error("failed to decode request"))
+			return
+		}
+
+		if err := validator.New().Struct(req); err != nil {
+			validateErr := err.(validator.ValidationErrors)
+
+			log.Error("invalid request", sl.Err(err))
+			render.Status(r, http.StatusBadRequest)
+			render.JSON(w, r, resp.ValidationError(validateErr))
+			return
+		}
+
+		user, err := authenticator.Authenticate(r.Context(), req.Username, req.Password)
+		if errors.Is(err, users.ErrInvalidCredentials) {
+			log.Info("invalid credentials", slog.String("username", req.Username))
+			render.Status(r, http.StatusUnauthorized)
+			render.JSON(w, r, resp.Error("invalid credentials"))
+			return
+		}
+		if err != nil {
+			log.Error("failed to authenticate user", sl.Err(err))
+			render.Status(r, http.StatusInternalServerError)
+			render.JSON(w, r, resp.Error("internal error"))
+			return
+		}
+
+		token, expiresAt, err := tokenIssuer.Issue(user.Username, user.Role, time.Now())
+		if err != nil {
+			log.Error("failed to issue token", sl.Err(err))
+			render.Status(r, http.StatusInternalServerError)
+			render.JSON(w, r, resp.Error("internal error"))
+			return
+		}
+
+		log.Info("token issued", slog.String("username", req.Username))
+
+		render.JSON(w, r, Response{
+			Response:  resp.OK(),
+			Token:     token,
+			ExpiresAt: expiresAt,
+			Role:      user.Role,
+		})
+	}
+}
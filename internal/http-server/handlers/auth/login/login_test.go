@@ -0,0 +1,110 @@
+package login_test
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"url-shortener/internal/http-server/handlers/auth/login"
+	"url-shortener/internal/http-server/handlers/auth/login/mocks"
+	"url-shortener/internal/lib/logger/handlers/slogdiscard"
+	"url-shortener/internal/storage"
+	"url-shortener/internal/users"
+)
+
+func TestLoginHandler(t *testing.T) {
+	cases := []struct {
+		name      string
+		input     string
+		wantCode  int
+		respError string
+		authError error
+		skipAuth  bool
+		issue     bool
+		mockError error
+	}{
+		{
+			name:     "Success",
+			input:    `{"username": "myuser", "password": "mypass"}`,
+			wantCode: http.StatusOK,
+			issue:    true,
+		},
+		{
+			name:      "Invalid credentials",
+			input:     `{"username": "myuser", "password": "wrong"}`,
+			wantCode:  http.StatusUnauthorized,
+			respError: "invalid credentials",
+			authError: users.ErrInvalidCredentials,
+		},
+		{
+			name:      "Authenticate Error",
+			input:     `{"username": "myuser", "password": "mypass"}`,
+			wantCode:  http.StatusInternalServerError,
+			respError: "internal error",
+			authError: errors.New("unexpected error"),
+		},
+		{
+			name:      "Empty password",
+			input:     `{"username": "myuser"}`,
+			wantCode:  http.StatusBadRequest,
+			respError: "field Password is a required field",
+			skipAuth:  true,
+		},
+		{
+			name:      "Issue Error",
+			input:     `{"username": "myuser", "password": "mypass"}`,
+			wantCode:  http.StatusInternalServerError,
+			respError: "internal error",
+			issue:     true,
+			mockError: errors.New("unexpected error"),
+		},
+	}
+
+	expiresAt := time.Date(2030, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			issuerMock := mocks.NewTokenIssuer(t)
+			authenticatorMock := mocks.NewAuthenticator(t)
+
+			if !tc.skipAuth {
+				var user storage.User
+				if tc.authError == nil {
+					user = storage.User{Username: "myuser", Role: storage.RoleUser}
+				}
+				authenticatorMock.On("Authenticate", mock.Anything, "myuser", mock.AnythingOfType("string")).
+					Return(user, tc.authError).Once()
+			}
+			if tc.issue {
+				issuerMock.On("Issue", "myuser", storage.RoleUser, mock.AnythingOfType("time.Time")).
+					Return("token", expiresAt, tc.mockError).Once()
+			}
+
+			handler := login.New(slogdiscard.NewDiscardLogger(), issuerMock, authenticatorMock)
+
+			req := httptest.NewRequest(http.MethodPost, "/auth/login", strings.NewReader(tc.input))
+			rr := httptest.NewRecorder()
+			handler.ServeHTTP(rr, req)
+
+			require.Equal(t, tc.wantCode, rr.Code)
+
+			var resp login.Response
+			require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &resp))
+
+			require.Equal(t, tc.respError, resp.Error)
+
+			if tc.respError == "" {
+				require.Equal(t, "token", resp.Token)
+				require.True(t, expiresAt.Equal(resp.ExpiresAt))
+				require.Equal(t, storage.RoleUser, resp.Role)
+			}
+		})
+	}
+}
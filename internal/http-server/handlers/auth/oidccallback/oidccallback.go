@@ -0,0 +1,143 @@
+package oidccallback
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/go-chi/render"
+
+	"url-shortener/internal/http-server/handlers/auth/oidclogin"
+	resp "url-shortener/internal/lib/api/response"
+	"url-shortener/internal/lib/logger/sl"
+	"url-shortener/internal/oidc"
+	"url-shortener/internal/storage"
+)
+
+//go:generate go run github.com/vektra/mockery/v2@latest --name=Exchanger
+type Exchanger interface {
+	Exchange(ctx context.Context, code string, s oidc.State) (oidc.Identity, error)
+}
+
+//go:generate go run github.com/vektra/mockery/v2@latest --name=UserProvisioner
+type UserProvisioner interface {
+	GetUser(ctx context.Context, username string) (storage.User, error)
+	SaveUser(ctx context.Context, user storage.User) (int64, error)
+}
+
+//go:generate go run github.com/vektra/mockery/v2@latest --name=TokenIssuer
+type TokenIssuer interface {
+	Issue(subject string, role string, now time.Time) (string, time.Time, error)
+}
+
+// New finishes a login started by oidclogin: it redeems the code from the
+// identity provider, creates the local user on first sign-in and sends
+// the user back to the page the login was started for with an access
+// token in the URL fragment, which browsers do not send to servers.
+func New(log *slog.Logger, provider Exchanger, users UserProvisioner, tokenIssuer TokenIssuer, key []byte) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.auth.oidccallback.New"
+
+		log := log.With(
+			slog.String("op", op),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+
+		// The state is good for one attempt.
+		http.SetCookie(w, &http.Cookie{Name: oidclogin.CookieName, Path: "/", MaxAge: -1, HttpOnly: true})
+
+		cookie, err := r.Cookie(oidclogin.CookieName)
+		if err != nil {
+			log.Info("login state missing")
+			render.Status(r, http.StatusBadRequest)
+			render.JSON(w, r, resp.Error("login expired, try again"))
+			return
+		}
+		state, err := oidc.OpenState(cookie.Value, key, time.Now())
+		if err != nil {
+			log.Info("invalid login state", sl.Err(err))
+			render.Status(r, http.StatusBadRequest)
+			render.JSON(w, r, resp.Error("login expired, try again"))
+			return
+		}
+
+		query := r.URL.Query()
+		if query.Get("state") != state.State {
+			log.Info("login state mismatch")
+			render.Status(r, http.StatusBadRequest)
+			render.JSON(w, r, resp.Error("invalid state"))
+			return
+		}
+		if e := query.Get("error"); e != "" {
+			log.Info("login refused by identity provider", slog.String("error", e))
+			render.Status(r, http.StatusUnauthorized)
+			render.JSON(w, r, resp.Error("login failed: "+e))
+			return
+		}
+
+		id, err := provider.Exchange(r.Context(), query.Get("code"), state)
+		switch {
+		case errors.Is(err, oidc.ErrNotAllowed):
+			log.Info("user not allowed", sl.Err(err))
+			render.Status(r, http.StatusForbidden)
+			render.JSON(w, r, resp.Error("forbidden"))
+			return
+		case errors.Is(err, oidc.ErrInvalidToken):
+			log.Warn("invalid id token", sl.Err(err))
+			render.Status(r, http.StatusUnauthorized)
+			render.JSON(w, r, resp.Error("login failed"))
+			return
+		case err != nil:
+			log.Error("failed to exchange code", sl.Err(err))
+			render.Status(r, http.StatusBadGateway)
+			render.JSON(w, r, resp.Error("identity provider unavailable"))
+			return
+		}
+
+		// API keys act as their owner, so the owner has to exist.
+		_, err = users.GetUser(r.Context(), id.Username)
+		if errors.Is(err, storage.ErrUserNotFound) {
+			_, err = users.SaveUser(r.Context(), storage.User{Username: id.Username, Role: id.Role})
+			if errors.Is(err, storage.ErrUserExists) {
+				err = nil
+			}
+			if err == nil {
+				log.Info("user created", slog.String("username", id.Username), slog.String("role", id.Role))
+			}
+		}
+		if err != nil {
+			log.Error("failed to provision user", sl.Err(err))
+			render.Status(r, http.StatusInternalServerError)
+			render.JSON(w, r, resp.Error("internal error"))
+			return
+		}
+
+		token, expiresAt, err := tokenIssuer.Issue(id.Username, id.Role, time.Now())
+		if err != nil {
+			log.Error("failed to issue token", sl.Err(err))
+			render.Status(r, http.StatusInternalServerError)
+			render.JSON(w, r, resp.Error("internal error"))
+			return
+		}
+
+		log.Info("token issued", slog.String("username", id.Username))
+
+		redirect := state.Redirect
+		if !oidclogin.LocalPath(redirect) {
+			redirect = oidclogin.DefaultRedirect
+		}
+		fragment := url.Values{
+			"token":      {token},
+			"user":       {id.Username},
+			"role":       {id.Role},
+			"expires_at": {strconv.FormatInt(expiresAt.Unix(), 10)},
+		}
+		w.Header().Set("Cache-Control", "no-store")
+		http.Redirect(w, r, redirect+"#"+fragment.Encode(), http.StatusFound)
+	}
+}
@@ -0,0 +1,204 @@
+package oidccallback_test
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"url-shortener/internal/http-server/handlers/auth/oidccallback"
+	"url-shortener/internal/http-server/handlers/auth/oidccallback/mocks"
+	"url-shortener/internal/http-server/handlers/auth/oidclogin"
+	"url-shortener/internal/lib/logger/handlers/slogdiscard"
+	"url-shortener/internal/oidc"
+	"url-shortener/internal/storage"
+)
+
+func TestOIDCCallbackHandler(t *testing.T) {
+	key := []byte("test-key")
+	expiresAt := time.Date(2030, 1, 1, 0, 0, 0, 0, time.UTC)
+	alice := oidc.Identity{Username: "alice@example.com", Role: storage.RoleAdmin}
+
+	cases := []struct {
+		name          string
+		cookie        string
+		query         string
+		wantCode      int
+		respError     string
+		skipExchange  bool
+		exchangeError error
+		getUserError  error
+		saveUser      bool
+		saveUserError error
+		issue         bool
+	}{
+		{
+			name:     "Existing user",
+			wantCode: http.StatusFound,
+			issue:    true,
+		},
+		{
+			name:         "First login",
+			wantCode:     http.StatusFound,
+			getUserError: storage.ErrUserNotFound,
+			saveUser:     true,
+			issue:        true,
+		},
+		{
+			name:          "Concurrent first login",
+			wantCode:      http.StatusFound,
+			getUserError:  storage.ErrUserNotFound,
+			saveUser:      true,
+			saveUserError: storage.ErrUserExists,
+			issue:         true,
+		},
+		{
+			name:         "No cookie",
+			cookie:       "-",
+			wantCode:     http.StatusBadRequest,
+			respError:    "login expired, try again",
+			skipExchange: true,
+		},
+		{
+			name:         "Forged cookie",
+			cookie:       oidc.NewState("/admin/", time.Now()).Seal([]byte("other-key")),
+			wantCode:     http.StatusBadRequest,
+			respError:    "login expired, try again",
+			skipExchange: true,
+		},
+		{
+			name:         "State mismatch",
+			query:        "?state=other&code=abc",
+			wantCode:     http.StatusBadRequest,
+			respError:    "invalid state",
+			skipExchange: true,
+		},
+		{
+			name:         "Provider error",
+			query:        "?state=%s&error=access_denied",
+			wantCode:     http.StatusUnauthorized,
+			respError:    "login failed: access_denied",
+			skipExchange: true,
+		},
+		{
+			name:          "Not allowed",
+			wantCode:      http.StatusForbidden,
+			respError:     "forbidden",
+			exchangeError: oidc.ErrNotAllowed,
+		},
+		{
+			name:          "Invalid token",
+			wantCode:      http.StatusUnauthorized,
+			respError:     "login failed",
+			exchangeError: oidc.ErrInvalidToken,
+		},
+		{
+			name:          "Exchange Error",
+			wantCode:      http.StatusBadGateway,
+			respError:     "identity provider unavailable",
+			exchangeError: errors.New("connection refused"),
+		},
+		{
+			name:         "GetUser Error",
+			wantCode:     http.StatusInternalServerError,
+			respError:    "internal error",
+			getUserError: errors.New("unexpected error"),
+		},
+		{
+			name:          "SaveUser Error",
+			wantCode:      http.StatusInternalServerError,
+			respError:     "internal error",
+			getUserError:  storage.ErrUserNotFound,
+			saveUser:      true,
+			saveUserError: errors.New("unexpected error"),
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			providerMock := mocks.NewExchanger(t)
+			usersMock := mocks.NewUserProvisioner(t)
+			issuerMock := mocks.NewTokenIssuer(t)
+
+			state := oidc.NewState("/admin/", time.Now())
+
+			if !tc.skipExchange {
+				id := alice
+				if tc.exchangeError != nil {
+					id = oidc.Identity{}
+				}
+				sameLogin := mock.MatchedBy(func(s oidc.State) bool {
+					return s.State == state.State && s.Nonce == state.Nonce && s.Verifier == state.Verifier
+				})
+				providerMock.On("Exchange", mock.Anything, "abc", sameLogin).Return(id, tc.exchangeError).Once()
+			}
+			if !tc.skipExchange && tc.exchangeError == nil {
+				usersMock.On("GetUser", mock.Anything, alice.Username).
+					Return(storage.User{}, tc.getUserError).Once()
+			}
+			if tc.saveUser {
+				usersMock.On("SaveUser", mock.Anything, storage.User{Username: alice.Username, Role: alice.Role}).
+					Return(int64(0), tc.saveUserError).Once()
+			}
+			if tc.issue {
+				issuerMock.On("Issue", alice.Username, alice.Role, mock.AnythingOfType("time.Time")).
+					Return("signed.jwt.token", expiresAt, nil).Once()
+			}
+
+			handler := oidccallback.New(slogdiscard.NewDiscardLogger(), providerMock, usersMock, issuerMock, key)
+
+			query := tc.query
+			if query == "" {
+				query = "?state=%s&code=abc"
+			}
+			if strings.Contains(query, "%s") {
+				query = fmt.Sprintf(query, state.State)
+			}
+			req := httptest.NewRequest(http.MethodGet, "/auth/oidc/callback"+query, nil)
+			switch tc.cookie {
+			case "-":
+			case "":
+				req.AddCookie(&http.Cookie{Name: oidclogin.CookieName, Value: state.Seal(key)})
+			default:
+				req.AddCookie(&http.Cookie{Name: oidclogin.CookieName, Value: tc.cookie})
+			}
+
+			rr := httptest.NewRecorder()
+			handler.ServeHTTP(rr, req)
+
+			require.Equal(t, tc.wantCode, rr.Code)
+
+			cookies := rr.Result().Cookies()
+			require.Len(t, cookies, 1)
+			require.Equal(t, oidclogin.CookieName, cookies[0].Name)
+			require.Negative(t, cookies[0].MaxAge)
+
+			if tc.wantCode != http.StatusFound {
+				var resp map[string]any
+				require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &resp))
+				require.Equal(t, tc.respError, resp["error"])
+				return
+			}
+
+			location, err := url.Parse(rr.Header().Get("Location"))
+			require.NoError(t, err)
+			require.Equal(t, "/admin/", location.Path)
+
+			fragment, err := url.ParseQuery(location.Fragment)
+			require.NoError(t, err)
+			require.Equal(t, "signed.jwt.token", fragment.Get("token"))
+			require.Equal(t, alice.Username, fragment.Get("user"))
+			require.Equal(t, alice.Role, fragment.Get("role"))
+			require.Equal(t, fmt.Sprint(expiresAt.Unix()), fragment.Get("expires_at"))
+			require.Equal(t, "no-store", rr.Header().Get("Cache-Control"))
+		})
+	}
+}
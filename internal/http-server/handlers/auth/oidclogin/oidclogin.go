@@ -0,0 +1,84 @@
+package oidclogin
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/go-chi/render"
+
+	resp "url-shortener/internal/lib/api/response"
+	"url-shortener/internal/lib/logger/sl"
+	"url-shortener/internal/oidc"
+)
+
+// CookieName is the cookie that carries the login state to the callback.
+const CookieName = "oidc_login"
+
+// DefaultRedirect is where users land after signing in unless the login
+// asks for another page.
+const DefaultRedirect = "/admin/"
+
+//go:generate go run github.com/vektra/mockery/v2@latest --name=AuthURLer
+type AuthURLer interface {
+	AuthURL(ctx context.Context, s oidc.State) (string, error)
+}
+
+// New sends the user to the sign-in page of the identity provider. The
+// redirect query parameter is the page of this service to return to, a
+// path such as /admin/.
+func New(log *slog.Logger, provider AuthURLer, key []byte) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.auth.oidclogin.New"
+
+		log := log.With(
+			slog.String("op", op),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+
+		redirect := r.URL.Query().Get("redirect")
+		if redirect == "" {
+			redirect = DefaultRedirect
+		}
+		if !LocalPath(redirect) {
+			log.Info("invalid redirect", slog.String("redirect", redirect))
+			render.Status(r, http.StatusBadRequest)
+			render.JSON(w, r, resp.Error("invalid redirect"))
+			return
+		}
+
+		state := oidc.NewState(redirect, time.Now())
+		target, err := provider.AuthURL(r.Context(), state)
+		if err != nil {
+			log.Error("failed to reach identity provider", sl.Err(err))
+			render.Status(r, http.StatusBadGateway)
+			render.JSON(w, r, resp.Error("identity provider unavailable"))
+			return
+		}
+
+		http.SetCookie(w, &http.Cookie{
+			Name:     CookieName,
+			Value:    state.Seal(key),
+			Path:     "/",
+			MaxAge:   int(oidc.StateTTL.Seconds()),
+			HttpOnly: true,
+			Secure:   r.TLS != nil,
+			// Lax, so the cookie comes back with the redirect from the
+			// provider.
+			SameSite: http.SameSiteLaxMode,
+		})
+		http.Redirect(w, r, target, http.StatusFound)
+	}
+}
+
+// LocalPath reports whether redirect is a path on this service rather
+// than another site.
+func LocalPath(redirect string) bool {
+	return strings.HasPrefix(redirect, "/") &&
+		!strings.HasPrefix(redirect, "//") &&
+		!strings.HasPrefix(redirect, "/\\") &&
+		!strings.ContainsAny(redirect, "\r\n")
+}
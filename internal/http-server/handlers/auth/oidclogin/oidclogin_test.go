@@ -0,0 +1,99 @@
+package oidclogin_test
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"url-shortener/internal/http-server/handlers/auth/oidclogin"
+	"url-shortener/internal/http-server/handlers/auth/oidclogin/mocks"
+	"url-shortener/internal/lib/logger/handlers/slogdiscard"
+	"url-shortener/internal/oidc"
+)
+
+func TestOIDCLoginHandler(t *testing.T) {
+	key := []byte("test-key")
+
+	cases := []struct {
+		name         string
+		query        string
+		wantCode     int
+		wantRedirect string
+		skipProvider bool
+		mockError    error
+	}{
+		{
+			name:         "Success",
+			wantCode:     http.StatusFound,
+			wantRedirect: oidclogin.DefaultRedirect,
+		},
+		{
+			name:         "Custom redirect",
+			query:        "?redirect=/admin/links",
+			wantCode:     http.StatusFound,
+			wantRedirect: "/admin/links",
+		},
+		{
+			name:         "Foreign redirect",
+			query:        "?redirect=//evil.example.com/",
+			wantCode:     http.StatusBadRequest,
+			skipProvider: true,
+		},
+		{
+			name:      "Provider Error",
+			wantCode:  http.StatusBadGateway,
+			mockError: errors.New("connection refused"),
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			providerMock := mocks.NewAuthURLer(t)
+
+			if !tc.skipProvider {
+				url := "https://idp.example.com/auth?state=x"
+				if tc.mockError != nil {
+					url = ""
+				}
+				providerMock.On("AuthURL", mock.Anything, mock.AnythingOfType("oidc.State")).
+					Return(url, tc.mockError).Once()
+			}
+
+			handler := oidclogin.New(slogdiscard.NewDiscardLogger(), providerMock, key)
+
+			req := httptest.NewRequest(http.MethodGet, "/auth/oidc/login"+tc.query, nil)
+			rr := httptest.NewRecorder()
+			handler.ServeHTTP(rr, req)
+
+			require.Equal(t, tc.wantCode, rr.Code)
+			if tc.wantCode != http.StatusFound {
+				require.Empty(t, rr.Result().Cookies())
+				return
+			}
+
+			require.Equal(t, "https://idp.example.com/auth?state=x", rr.Header().Get("Location"))
+
+			cookies := rr.Result().Cookies()
+			require.Len(t, cookies, 1)
+			require.Equal(t, oidclogin.CookieName, cookies[0].Name)
+			require.True(t, cookies[0].HttpOnly)
+
+			state, err := oidc.OpenState(cookies[0].Value, key, time.Now())
+			require.NoError(t, err)
+			require.Equal(t, tc.wantRedirect, state.Redirect)
+		})
+	}
+}
+
+func TestLocalPath(t *testing.T) {
+	require.True(t, oidclogin.LocalPath("/admin/"))
+	require.False(t, oidclogin.LocalPath("https://evil.example.com/"))
+	require.False(t, oidclogin.LocalPath("//evil.example.com/"))
+	require.False(t, oidclogin.LocalPath(`/\evil.example.com/`))
+	require.False(t, oidclogin.LocalPath("admin"))
+}
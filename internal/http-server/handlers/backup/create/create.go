@@ -0,0 +1,56 @@
+package create
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"time"
+	"url-shortener/internal/backup"
+	resp "url-shortener/internal/lib/api/response"
+	"url-shortener/internal/lib/logger/sl"
+
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/go-chi/render"
+)
+
+type Response struct {
+	resp.Response
+	Name      string    `json:"name"`
+	Size      int64     `json:"size"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+//go:generate go run github.com/vektra/mockery/v2@latest --name=BackupCreator
+type BackupCreator interface {
+	Create(ctx context.Context) (backup.Snapshot, error)
+}
+
+// New takes a snapshot of the database while it stays in use.
+func New(log *slog.Logger, backupCreator BackupCreator) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.backup.create.New"
+
+		log := log.With(
+			slog.String("op", op),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+
+		snapshot, err := backupCreator.Create(r.Context())
+		if err != nil {
+			log.Error("failed to create backup", sl.Err(err))
+			render.Status(r, http.StatusInternalServerError)
+			render.JSON(w, r, resp.Error("failed to create backup"))
+			return
+		}
+
+		log.Info("backup created", slog.String("name", snapshot.Name), slog.Int64("size", snapshot.Size))
+
+		render.Status(r, http.StatusCreated)
+		render.JSON(w, r, Response{
+			Response:  resp.OK(),
+			Name:      snapshot.Name,
+			Size:      snapshot.Size,
+			CreatedAt: snapshot.CreatedAt,
+		})
+	}
+}
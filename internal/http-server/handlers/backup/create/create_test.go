@@ -0,0 +1,69 @@
+package create_test
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"url-shortener/internal/backup"
+	"url-shortener/internal/http-server/handlers/backup/create"
+	"url-shortener/internal/http-server/handlers/backup/create/mocks"
+	"url-shortener/internal/lib/logger/handlers/slogdiscard"
+)
+
+func TestCreateHandler(t *testing.T) {
+	snapshot := backup.Snapshot{
+		Name:      "url-shortener-20260102-030405.000.db",
+		Size:      4096,
+		CreatedAt: time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC),
+	}
+
+	cases := []struct {
+		name      string
+		status    int
+		respError string
+		mockError error
+	}{
+		{
+			name:   "Success",
+			status: http.StatusCreated,
+		},
+		{
+			name:      "Create Error",
+			status:    http.StatusInternalServerError,
+			respError: "failed to create backup",
+			mockError: errors.New("unexpected error"),
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			backupCreatorMock := mocks.NewBackupCreator(t)
+			backupCreatorMock.On("Create", mock.Anything).Return(snapshot, tc.mockError).Once()
+
+			handler := create.New(slogdiscard.NewDiscardLogger(), backupCreatorMock)
+
+			req := httptest.NewRequest(http.MethodPost, "/backups", nil)
+			rr := httptest.NewRecorder()
+			handler.ServeHTTP(rr, req)
+
+			require.Equal(t, tc.status, rr.Code)
+
+			var resp create.Response
+			require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &resp))
+
+			require.Equal(t, tc.respError, resp.Error)
+			if tc.respError == "" {
+				require.Equal(t, snapshot.Name, resp.Name)
+				require.Equal(t, snapshot.Size, resp.Size)
+				require.True(t, snapshot.CreatedAt.Equal(resp.CreatedAt))
+			}
+		})
+	}
+}
@@ -0,0 +1,71 @@
+package download
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"os"
+	"url-shortener/internal/backup"
+	resp "url-shortener/internal/lib/api/response"
+	"url-shortener/internal/lib/logger/sl"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/go-chi/render"
+)
+
+//go:generate go run github.com/vektra/mockery/v2@latest --name=PathGetter
+type PathGetter interface {
+	Path(name string) (string, error)
+}
+
+// New sends the snapshot {name} as a file, e.g. to keep it off the server.
+func New(log *slog.Logger, pathGetter PathGetter) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.backup.download.New"
+
+		log := log.With(
+			slog.String("op", op),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+
+		name := chi.URLParam(r, "name")
+		// URLFormat takes the .db extension off the path.
+		if format, _ := r.Context().Value(middleware.URLFormatCtxKey).(string); format != "" {
+			name += "." + format
+		}
+
+		path, err := pathGetter.Path(name)
+		if errors.Is(err, backup.ErrNotFound) {
+			log.Info("backup not found", slog.String("name", name))
+			render.Status(r, http.StatusNotFound)
+			render.JSON(w, r, resp.Error("backup not found"))
+			return
+		}
+
+		var f *os.File
+		if err == nil {
+			f, err = os.Open(path)
+		}
+		if err != nil {
+			log.Error("failed to open backup", sl.Err(err))
+			render.Status(r, http.StatusInternalServerError)
+			render.JSON(w, r, resp.Error("failed to open backup"))
+			return
+		}
+		defer f.Close()
+
+		info, err := f.Stat()
+		if err != nil {
+			log.Error("failed to open backup", sl.Err(err))
+			render.Status(r, http.StatusInternalServerError)
+			render.JSON(w, r, resp.Error("failed to open backup"))
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/vnd.sqlite3")
+		w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", name))
+		http.ServeContent(w, r, name, info.ModTime(), f)
+	}
+}
@@ -0,0 +1,88 @@
+package download_test
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/stretchr/testify/require"
+
+	"url-shortener/internal/backup"
+	"url-shortener/internal/http-server/handlers/backup/download"
+	"url-shortener/internal/http-server/handlers/backup/download/mocks"
+	resp "url-shortener/internal/lib/api/response"
+	"url-shortener/internal/lib/logger/handlers/slogdiscard"
+)
+
+const name = "url-shortener-20260102-030405.000.db"
+
+func TestDownloadHandler(t *testing.T) {
+	path := filepath.Join(t.TempDir(), name)
+	require.NoError(t, os.WriteFile(path, []byte("SQLite format 3\x00"), 0o600))
+
+	cases := []struct {
+		name      string
+		path      string
+		status    int
+		respError string
+		mockError error
+	}{
+		{
+			name:   "Success",
+			path:   path,
+			status: http.StatusOK,
+		},
+		{
+			name:      "Not found",
+			status:    http.StatusNotFound,
+			respError: "backup not found",
+			mockError: backup.ErrNotFound,
+		},
+		{
+			name:      "Missing file",
+			path:      filepath.Join(t.TempDir(), name),
+			status:    http.StatusInternalServerError,
+			respError: "failed to open backup",
+		},
+		{
+			name:      "Path Error",
+			status:    http.StatusInternalServerError,
+			respError: "failed to open backup",
+			mockError: errors.New("unexpected error"),
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			pathGetterMock := mocks.NewPathGetter(t)
+			pathGetterMock.On("Path", name).Return(tc.path, tc.mockError).Once()
+
+			r := chi.NewRouter()
+			r.Use(middleware.URLFormat)
+			r.Get("/backups/{name}", download.New(slogdiscard.NewDiscardLogger(), pathGetterMock))
+
+			req := httptest.NewRequest(http.MethodGet, "/backups/"+name, nil)
+			rr := httptest.NewRecorder()
+			r.ServeHTTP(rr, req)
+
+			require.Equal(t, tc.status, rr.Code)
+
+			if tc.respError == "" {
+				require.Equal(t, `attachment; filename="`+name+`"`, rr.Header().Get("Content-Disposition"))
+				require.Equal(t, "SQLite format 3\x00", rr.Body.String())
+				return
+			}
+
+			var body resp.Response
+			require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &body))
+
+			require.Equal(t, tc.respError, body.Error)
+		})
+	}
+}
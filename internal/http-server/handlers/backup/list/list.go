@@ -0,0 +1,59 @@
+package list
+
+import (
+	"log/slog"
+	"net/http"
+	"time"
+	"url-shortener/internal/backup"
+	resp "url-shortener/internal/lib/api/response"
+	"url-shortener/internal/lib/logger/sl"
+
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/go-chi/render"
+)
+
+type Backup struct {
+	Name      string    `json:"name"`
+	Size      int64     `json:"size"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type Response struct {
+	resp.Response
+	Backups []Backup `json:"backups"`
+}
+
+//go:generate go run github.com/vektra/mockery/v2@latest --name=BackupLister
+type BackupLister interface {
+	List() ([]backup.Snapshot, error)
+}
+
+// New lists the snapshots in the backup directory, newest first.
+func New(log *slog.Logger, backupLister BackupLister) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.backup.list.New"
+
+		log := log.With(
+			slog.String("op", op),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+
+		snapshots, err := backupLister.List()
+		if err != nil {
+			log.Error("failed to list backups", sl.Err(err))
+			render.Status(r, http.StatusInternalServerError)
+			render.JSON(w, r, resp.Error("failed to list backups"))
+			return
+		}
+
+		res := Response{
+			Response: resp.OK(),
+			Backups:  make([]Backup, 0, len(snapshots)),
+		}
+		for _, s := range snapshots {
+			res.Backups = append(res.Backups, Backup{Name: s.Name, Size: s.Size, CreatedAt: s.CreatedAt})
+		}
+
+		render.JSON(w, r, res)
+	}
+}
@@ -0,0 +1,79 @@
+package list_test
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"url-shortener/internal/backup"
+	"url-shortener/internal/http-server/handlers/backup/list"
+	"url-shortener/internal/http-server/handlers/backup/list/mocks"
+	"url-shortener/internal/lib/logger/handlers/slogdiscard"
+)
+
+func TestListHandler(t *testing.T) {
+	createdAt := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	cases := []struct {
+		name      string
+		snapshots []backup.Snapshot
+		status    int
+		respError string
+		mockError error
+		want      []string
+	}{
+		{
+			name: "Success",
+			snapshots: []backup.Snapshot{
+				{Name: "url-shortener-20260102-030405.000.db", Size: 4096, CreatedAt: createdAt},
+				{Name: "url-shortener-20260101-030405.000.db", Size: 2048, CreatedAt: createdAt.AddDate(0, 0, -1)},
+			},
+			status: http.StatusOK,
+			want:   []string{"url-shortener-20260102-030405.000.db", "url-shortener-20260101-030405.000.db"},
+		},
+		{
+			name:      "Empty",
+			snapshots: []backup.Snapshot{},
+			status:    http.StatusOK,
+			want:      []string{},
+		},
+		{
+			name:      "List Error",
+			status:    http.StatusInternalServerError,
+			respError: "failed to list backups",
+			mockError: errors.New("unexpected error"),
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			backupListerMock := mocks.NewBackupLister(t)
+			backupListerMock.On("List").Return(tc.snapshots, tc.mockError).Once()
+
+			handler := list.New(slogdiscard.NewDiscardLogger(), backupListerMock)
+
+			req := httptest.NewRequest(http.MethodGet, "/backups", nil)
+			rr := httptest.NewRecorder()
+			handler.ServeHTTP(rr, req)
+
+			require.Equal(t, tc.status, rr.Code)
+
+			var resp list.Response
+			require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &resp))
+
+			require.Equal(t, tc.respError, resp.Error)
+			if tc.want != nil {
+				names := []string{}
+				for _, b := range resp.Backups {
+					names = append(names, b.Name)
+				}
+				require.Equal(t, tc.want, names)
+			}
+		})
+	}
+}
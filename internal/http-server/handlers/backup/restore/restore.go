@@ -0,0 +1,53 @@
+package restore
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+	"url-shortener/internal/backup"
+	resp "url-shortener/internal/lib/api/response"
+	"url-shortener/internal/lib/logger/sl"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/go-chi/render"
+)
+
+//go:generate go run github.com/vektra/mockery/v2@latest --name=BackupRestorer
+type BackupRestorer interface {
+	Restore(ctx context.Context, name string) error
+}
+
+// New replaces the database with the snapshot {name}. Everything changed
+// since the snapshot was taken is lost.
+func New(log *slog.Logger, backupRestorer BackupRestorer) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.backup.restore.New"
+
+		log := log.With(
+			slog.String("op", op),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+
+		name := chi.URLParam(r, "name")
+
+		err := backupRestorer.Restore(r.Context(), name)
+		if errors.Is(err, backup.ErrNotFound) {
+			log.Info("backup not found", slog.String("name", name))
+			render.Status(r, http.StatusNotFound)
+			render.JSON(w, r, resp.Error("backup not found"))
+			return
+		}
+		if err != nil {
+			log.Error("failed to restore backup", sl.Err(err))
+			render.Status(r, http.StatusInternalServerError)
+			render.JSON(w, r, resp.Error("failed to restore backup"))
+			return
+		}
+
+		log.Warn("database restored from backup", slog.String("name", name))
+
+		render.JSON(w, r, resp.OK())
+	}
+}
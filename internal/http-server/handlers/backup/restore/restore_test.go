@@ -0,0 +1,69 @@
+package restore_test
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"url-shortener/internal/backup"
+	"url-shortener/internal/http-server/handlers/backup/restore"
+	"url-shortener/internal/http-server/handlers/backup/restore/mocks"
+	resp "url-shortener/internal/lib/api/response"
+	"url-shortener/internal/lib/logger/handlers/slogdiscard"
+)
+
+func TestRestoreHandler(t *testing.T) {
+	const name = "url-shortener-20260102-030405.000.db"
+
+	cases := []struct {
+		name      string
+		status    int
+		respError string
+		mockError error
+	}{
+		{
+			name:   "Success",
+			status: http.StatusOK,
+		},
+		{
+			name:      "Not found",
+			status:    http.StatusNotFound,
+			respError: "backup not found",
+			mockError: fmt.Errorf("backup.Restore: %w", backup.ErrNotFound),
+		},
+		{
+			name:      "Restore Error",
+			status:    http.StatusInternalServerError,
+			respError: "failed to restore backup",
+			mockError: errors.New("unexpected error"),
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			backupRestorerMock := mocks.NewBackupRestorer(t)
+			backupRestorerMock.On("Restore", mock.Anything, name).Return(tc.mockError).Once()
+
+			r := chi.NewRouter()
+			r.Post("/backups/{name}/restore", restore.New(slogdiscard.NewDiscardLogger(), backupRestorerMock))
+
+			req := httptest.NewRequest(http.MethodPost, "/backups/"+name+"/restore", nil)
+			rr := httptest.NewRecorder()
+			r.ServeHTTP(rr, req)
+
+			require.Equal(t, tc.status, rr.Code)
+
+			var body resp.Response
+			require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &body))
+
+			require.Equal(t, tc.respError, body.Error)
+		})
+	}
+}
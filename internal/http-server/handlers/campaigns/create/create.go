@@ -0,0 +1,116 @@
+package create
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"time"
+	"url-shortener/internal/http-server/handlers/campaigns/list"
+	"url-shortener/internal/http-server/handlers/url/save"
+	"url-shortener/internal/http-server/middleware/auth"
+	resp "url-shortener/internal/lib/api/response"
+	"url-shortener/internal/lib/logger/sl"
+	"url-shortener/internal/lib/shorturl"
+	"url-shortener/internal/storage"
+
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/go-chi/render"
+	"github.com/go-playground/validator/v10"
+)
+
+// Request is the name and the settings of a campaign; updates send the
+// same fields.
+type Request struct {
+	Name string `json:"name" validate:"required,max=100"`
+	// UTM, ExpiresAt and Domain are given to new links of the campaign
+	// that do not set their own.
+	UTM       *save.UTM  `json:"utm,omitempty"`
+	ExpiresAt *time.Time `json:"expires_at,omitempty"`
+	Domain    string     `json:"domain,omitempty"`
+}
+
+type Response struct {
+	resp.Response
+	list.Campaign
+}
+
+//go:generate go run github.com/vektra/mockery/v2@latest --name=CampaignSaver
+type CampaignSaver interface {
+	SaveCampaign(ctx context.Context, c storage.Campaign) (int64, error)
+}
+
+// New creates a campaign owned by the authenticated user. Links join it
+// with the campaign field when they are created.
+func New(log *slog.Logger, campaignSaver CampaignSaver, links *shorturl.Builder) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.campaigns.create.New"
+
+		log := log.With(
+			slog.String("op", op),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+
+		var req Request
+		if err := render.DecodeJSON(r.Body, &req); err != nil {
+			log.Error("failed to decode request body", sl.Err(err))
+			render.Status(r, http.StatusBadRequest)
+			render.JSON(w, r, resp.Error("failed to decode request"))
+			return
+		}
+
+		c, errResp, ok := Build(req, links, time.Now())
+		if !ok {
+			log.Info("invalid request", slog.String("error", errResp.Error))
+			render.Status(r, http.StatusBadRequest)
+			render.JSON(w, r, errResp)
+			return
+		}
+
+		user, _ := auth.UserFromContext(r.Context())
+		c.Owner = user.Username
+
+		id, err := campaignSaver.SaveCampaign(r.Context(), c)
+		if err != nil {
+			log.Error("failed to save campaign", sl.Err(err))
+			render.Status(r, http.StatusInternalServerError)
+			render.JSON(w, r, resp.Error("failed to create campaign"))
+			return
+		}
+		c.ID, c.CreatedAt = id, time.Now().UTC()
+
+		log.Info("campaign created", slog.Int64("id", c.ID), slog.String("name", c.Name))
+
+		render.Status(r, http.StatusCreated)
+		render.JSON(w, r, Response{
+			Response: resp.OK(),
+			Campaign: list.FromStorage(c),
+		})
+	}
+}
+
+// Build checks req and returns the campaign it describes. If req is
+// invalid, it returns the error response to send instead.
+func Build(req Request, links *shorturl.Builder, now time.Time) (storage.Campaign, resp.Response, bool) {
+	if err := validator.New().Struct(req); err != nil {
+		return storage.Campaign{}, resp.ValidationError(err.(validator.ValidationErrors)), false
+	}
+
+	c := storage.Campaign{Name: req.Name}
+	if req.UTM != nil {
+		c.UTM = storage.UTM(*req.UTM)
+	}
+	if req.ExpiresAt != nil {
+		if !req.ExpiresAt.After(now) {
+			return storage.Campaign{}, resp.Error("field expires_at must be in the future"), false
+		}
+		c.ExpiresAt = req.ExpiresAt.UTC()
+	}
+
+	domain, err := links.Domain(req.Domain)
+	if err != nil {
+		return storage.Campaign{}, resp.InvalidField("domain", "short_domain", "field domain must be one of the short domains of the service"), false
+	}
+	c.Domain = domain
+
+	return c, resp.Response{}, true
+}
@@ -0,0 +1,100 @@
+package create_test
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"url-shortener/internal/http-server/handlers/campaigns/create"
+	"url-shortener/internal/http-server/handlers/campaigns/create/mocks"
+	"url-shortener/internal/http-server/middleware/auth"
+	"url-shortener/internal/lib/logger/handlers/slogdiscard"
+	"url-shortener/internal/lib/shorturl"
+	"url-shortener/internal/storage"
+)
+
+func TestCreateHandler(t *testing.T) {
+	cases := []struct {
+		name      string
+		input     string
+		status    int
+		respError string
+		save      bool
+		mockError error
+	}{
+		{
+			name:   "Success",
+			input:  `{"name": "spring", "utm": {"source": "newsletter"}, "expires_at": "2999-01-01T00:00:00Z", "domain": "GO.acme.com"}`,
+			status: http.StatusCreated,
+			save:   true,
+		},
+		{
+			name:      "Empty name",
+			input:     `{}`,
+			status:    http.StatusBadRequest,
+			respError: "field Name is a required field",
+		},
+		{
+			name:      "Expiry in the past",
+			input:     `{"name": "spring", "expires_at": "2000-01-01T00:00:00Z"}`,
+			status:    http.StatusBadRequest,
+			respError: "field expires_at must be in the future",
+		},
+		{
+			name:      "Unknown domain",
+			input:     `{"name": "spring", "domain": "evil.com"}`,
+			status:    http.StatusBadRequest,
+			respError: "field domain must be one of the short domains of the service",
+		},
+		{
+			name:      "SaveCampaign Error",
+			input:     `{"name": "spring"}`,
+			status:    http.StatusInternalServerError,
+			respError: "failed to create campaign",
+			save:      true,
+			mockError: errors.New("unexpected error"),
+		},
+	}
+
+	links, err := shorturl.New("https://sho.rt", []string{"sho.rt", "go.acme.com"})
+	require.NoError(t, err)
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			campaignSaverMock := mocks.NewCampaignSaver(t)
+
+			if tc.save {
+				campaignSaverMock.On("SaveCampaign", mock.Anything, mock.MatchedBy(func(c storage.Campaign) bool {
+					return c.Name == "spring" && c.Owner == "alice"
+				})).Return(int64(7), tc.mockError).Once()
+			}
+
+			handler := create.New(slogdiscard.NewDiscardLogger(), campaignSaverMock, links)
+
+			req := httptest.NewRequest(http.MethodPost, "/campaigns", strings.NewReader(tc.input))
+			req = req.WithContext(auth.WithUser(req.Context(), storage.User{Username: "alice", Role: storage.RoleUser}))
+			rr := httptest.NewRecorder()
+			handler.ServeHTTP(rr, req)
+
+			require.Equal(t, tc.status, rr.Code)
+
+			var resp create.Response
+			require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &resp))
+
+			require.Equal(t, tc.respError, resp.Error)
+
+			if tc.name == "Success" {
+				require.Equal(t, int64(7), resp.ID)
+				require.Equal(t, "go.acme.com", resp.Domain)
+				require.Equal(t, "newsletter", resp.UTM.Source)
+				require.NotNil(t, resp.ExpiresAt)
+			}
+		})
+	}
+}
@@ -0,0 +1,62 @@
+package delete
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"url-shortener/internal/http-server/middleware/auth"
+	resp "url-shortener/internal/lib/api/response"
+	"url-shortener/internal/lib/logger/sl"
+	"url-shortener/internal/storage"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/go-chi/render"
+)
+
+//go:generate go run github.com/vektra/mockery/v2@latest --name=CampaignDeleter
+type CampaignDeleter interface {
+	DeleteCampaign(ctx context.Context, id int64, owner string) error
+}
+
+// New deletes the campaign {id}. Its links are kept and leave the
+// campaign. Users can only delete their own campaigns, admins can delete
+// any.
+func New(log *slog.Logger, campaignDeleter CampaignDeleter) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.campaigns.delete.New"
+
+		log := log.With(
+			slog.String("op", op),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+
+		id, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+		if err != nil || id <= 0 {
+			log.Info("invalid campaign id", slog.String("id", chi.URLParam(r, "id")))
+			render.Status(r, http.StatusBadRequest)
+			render.JSON(w, r, resp.Error("invalid request"))
+			return
+		}
+
+		err = campaignDeleter.DeleteCampaign(r.Context(), id, auth.OwnerFromContext(r.Context()))
+		if errors.Is(err, storage.ErrCampaignNotFound) {
+			log.Info("campaign not found", slog.Int64("id", id))
+			render.Status(r, http.StatusNotFound)
+			render.JSON(w, r, resp.Error("campaign not found"))
+			return
+		}
+		if err != nil {
+			log.Error("failed to delete campaign", sl.Err(err))
+			render.Status(r, http.StatusInternalServerError)
+			render.JSON(w, r, resp.Error("failed to delete campaign"))
+			return
+		}
+
+		log.Info("campaign deleted", slog.Int64("id", id))
+
+		render.JSON(w, r, resp.OK())
+	}
+}
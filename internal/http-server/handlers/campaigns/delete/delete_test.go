@@ -0,0 +1,87 @@
+package delete_test
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"url-shortener/internal/http-server/handlers/campaigns/delete"
+	"url-shortener/internal/http-server/handlers/campaigns/delete/mocks"
+	"url-shortener/internal/http-server/middleware/auth"
+	resp "url-shortener/internal/lib/api/response"
+	"url-shortener/internal/lib/logger/handlers/slogdiscard"
+	"url-shortener/internal/storage"
+)
+
+func TestDeleteHandler(t *testing.T) {
+	cases := []struct {
+		name      string
+		id        string
+		status    int
+		respError string
+		delete    bool
+		mockError error
+	}{
+		{
+			name:   "Success",
+			id:     "1",
+			status: http.StatusOK,
+			delete: true,
+		},
+		{
+			name:      "Not found",
+			id:        "1",
+			status:    http.StatusNotFound,
+			respError: "campaign not found",
+			delete:    true,
+			mockError: storage.ErrCampaignNotFound,
+		},
+		{
+			name:      "Invalid id",
+			id:        "abc",
+			status:    http.StatusBadRequest,
+			respError: "invalid request",
+		},
+		{
+			name:      "DeleteCampaign Error",
+			id:        "1",
+			status:    http.StatusInternalServerError,
+			respError: "failed to delete campaign",
+			delete:    true,
+			mockError: errors.New("unexpected error"),
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			campaignDeleterMock := mocks.NewCampaignDeleter(t)
+
+			if tc.delete {
+				campaignDeleterMock.On("DeleteCampaign", mock.Anything, int64(1), "alice").
+					Return(tc.mockError).
+					Once()
+			}
+
+			r := chi.NewRouter()
+			r.Delete("/campaigns/{id}", delete.New(slogdiscard.NewDiscardLogger(), campaignDeleterMock))
+
+			req := httptest.NewRequest(http.MethodDelete, "/campaigns/"+tc.id, nil)
+			req = req.WithContext(auth.WithUser(req.Context(), storage.User{Username: "alice", Role: storage.RoleUser}))
+			rr := httptest.NewRecorder()
+			r.ServeHTTP(rr, req)
+
+			require.Equal(t, tc.status, rr.Code)
+
+			var body resp.Response
+			require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &body))
+
+			require.Equal(t, tc.respError, body.Error)
+		})
+	}
+}
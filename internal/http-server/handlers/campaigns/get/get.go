@@ -0,0 +1,68 @@
+package get
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"url-shortener/internal/http-server/handlers/campaigns/list"
+	"url-shortener/internal/http-server/middleware/auth"
+	resp "url-shortener/internal/lib/api/response"
+	"url-shortener/internal/lib/logger/sl"
+	"url-shortener/internal/storage"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/go-chi/render"
+)
+
+type Response struct {
+	resp.Response
+	list.Campaign
+}
+
+//go:generate go run github.com/vektra/mockery/v2@latest --name=CampaignGetter
+type CampaignGetter interface {
+	GetCampaign(ctx context.Context, id int64, owner string) (storage.Campaign, error)
+}
+
+// New returns the campaign {id}. Editors can only see their own
+// campaigns, admins and viewers can see any.
+func New(log *slog.Logger, campaignGetter CampaignGetter) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.campaigns.get.New"
+
+		log := log.With(
+			slog.String("op", op),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+
+		id, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+		if err != nil || id <= 0 {
+			log.Info("invalid campaign id", slog.String("id", chi.URLParam(r, "id")))
+			render.Status(r, http.StatusBadRequest)
+			render.JSON(w, r, resp.Error("invalid request"))
+			return
+		}
+
+		c, err := campaignGetter.GetCampaign(r.Context(), id, auth.ViewerFromContext(r.Context()))
+		if errors.Is(err, storage.ErrCampaignNotFound) {
+			log.Info("campaign not found", slog.Int64("id", id))
+			render.Status(r, http.StatusNotFound)
+			render.JSON(w, r, resp.Error("campaign not found"))
+			return
+		}
+		if err != nil {
+			log.Error("failed to get campaign", sl.Err(err))
+			render.Status(r, http.StatusInternalServerError)
+			render.JSON(w, r, resp.Error("failed to get campaign"))
+			return
+		}
+
+		render.JSON(w, r, Response{
+			Response: resp.OK(),
+			Campaign: list.FromStorage(c),
+		})
+	}
+}
@@ -0,0 +1,89 @@
+package get_test
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"url-shortener/internal/http-server/handlers/campaigns/get"
+	"url-shortener/internal/http-server/handlers/campaigns/get/mocks"
+	"url-shortener/internal/http-server/middleware/auth"
+	"url-shortener/internal/lib/logger/handlers/slogdiscard"
+	"url-shortener/internal/storage"
+)
+
+func TestGetHandler(t *testing.T) {
+	cases := []struct {
+		name      string
+		id        string
+		status    int
+		respError string
+		get       bool
+		mockError error
+	}{
+		{
+			name:   "Success",
+			id:     "1",
+			status: http.StatusOK,
+			get:    true,
+		},
+		{
+			name:      "Not found",
+			id:        "1",
+			status:    http.StatusNotFound,
+			respError: "campaign not found",
+			get:       true,
+			mockError: storage.ErrCampaignNotFound,
+		},
+		{
+			name:      "Invalid id",
+			id:        "abc",
+			status:    http.StatusBadRequest,
+			respError: "invalid request",
+		},
+		{
+			name:      "GetCampaign Error",
+			id:        "1",
+			status:    http.StatusInternalServerError,
+			respError: "failed to get campaign",
+			get:       true,
+			mockError: errors.New("unexpected error"),
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			campaignGetterMock := mocks.NewCampaignGetter(t)
+
+			if tc.get {
+				campaignGetterMock.On("GetCampaign", mock.Anything, int64(1), "alice").
+					Return(storage.Campaign{ID: 1, Name: "spring", Owner: "alice"}, tc.mockError).
+					Once()
+			}
+
+			r := chi.NewRouter()
+			r.Get("/campaigns/{id}", get.New(slogdiscard.NewDiscardLogger(), campaignGetterMock))
+
+			req := httptest.NewRequest(http.MethodGet, "/campaigns/"+tc.id, nil)
+			req = req.WithContext(auth.WithUser(req.Context(), storage.User{Username: "alice", Role: storage.RoleUser}))
+			rr := httptest.NewRecorder()
+			r.ServeHTTP(rr, req)
+
+			require.Equal(t, tc.status, rr.Code)
+
+			var body get.Response
+			require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &body))
+
+			require.Equal(t, tc.respError, body.Error)
+			if tc.respError == "" {
+				require.Equal(t, "spring", body.Name)
+			}
+		})
+	}
+}
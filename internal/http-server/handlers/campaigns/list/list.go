@@ -0,0 +1,92 @@
+package list
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"time"
+	"url-shortener/internal/http-server/handlers/url/save"
+	"url-shortener/internal/http-server/middleware/auth"
+	resp "url-shortener/internal/lib/api/response"
+	"url-shortener/internal/lib/logger/sl"
+	"url-shortener/internal/storage"
+
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/go-chi/render"
+)
+
+// Campaign is a campaign as the campaign handlers return it.
+type Campaign struct {
+	ID    int64  `json:"id"`
+	Name  string `json:"name"`
+	Owner string `json:"owner,omitempty"`
+	// UTM, ExpiresAt and Domain are given to new links of the campaign
+	// that do not set their own.
+	UTM       *save.UTM  `json:"utm,omitempty"`
+	ExpiresAt *time.Time `json:"expires_at,omitempty"`
+	Domain    string     `json:"domain,omitempty"`
+	CreatedAt time.Time  `json:"created_at"`
+}
+
+// FromStorage converts a stored campaign for a response.
+func FromStorage(c storage.Campaign) Campaign {
+	res := Campaign{
+		ID:        c.ID,
+		Name:      c.Name,
+		Owner:     c.Owner,
+		Domain:    c.Domain,
+		CreatedAt: c.CreatedAt,
+	}
+	if c.UTM != (storage.UTM{}) {
+		utm := save.UTM(c.UTM)
+		res.UTM = &utm
+	}
+	if !c.ExpiresAt.IsZero() {
+		res.ExpiresAt = &c.ExpiresAt
+	}
+
+	return res
+}
+
+type Response struct {
+	resp.Response
+	Campaigns []Campaign `json:"campaigns"`
+}
+
+//go:generate go run github.com/vektra/mockery/v2@latest --name=CampaignLister
+type CampaignLister interface {
+	ListCampaigns(ctx context.Context, owner string) ([]storage.Campaign, error)
+}
+
+// New lists campaigns ordered by id. Editors see their own campaigns,
+// admins and viewers see everyone's.
+func New(log *slog.Logger, campaignLister CampaignLister) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.campaigns.list.New"
+
+		log := log.With(
+			slog.String("op", op),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+
+		campaigns, err := campaignLister.ListCampaigns(r.Context(), auth.ViewerFromContext(r.Context()))
+		if err != nil {
+			log.Error("failed to list campaigns", sl.Err(err))
+			render.Status(r, http.StatusInternalServerError)
+			render.JSON(w, r, resp.Error("failed to list campaigns"))
+			return
+		}
+
+		res := Response{
+			Response:  resp.OK(),
+			Campaigns: make([]Campaign, 0, len(campaigns)),
+		}
+		for _, c := range campaigns {
+			res.Campaigns = append(res.Campaigns, FromStorage(c))
+		}
+
+		log.Info("campaigns listed", slog.Int("count", len(campaigns)))
+
+		render.JSON(w, r, res)
+	}
+}
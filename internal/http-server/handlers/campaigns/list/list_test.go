@@ -0,0 +1,80 @@
+package list_test
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"url-shortener/internal/http-server/handlers/campaigns/list"
+	"url-shortener/internal/http-server/handlers/campaigns/list/mocks"
+	"url-shortener/internal/http-server/middleware/auth"
+	"url-shortener/internal/lib/logger/handlers/slogdiscard"
+	"url-shortener/internal/storage"
+)
+
+func TestListHandler(t *testing.T) {
+	cases := []struct {
+		name      string
+		user      storage.User
+		owner     string
+		campaigns []storage.Campaign
+		status    int
+		respError string
+		mockError error
+	}{
+		{
+			name:  "Success",
+			user:  storage.User{Username: "alice", Role: storage.RoleUser},
+			owner: "alice",
+			campaigns: []storage.Campaign{
+				{ID: 1, Name: "spring", Owner: "alice", UTM: storage.UTM{Source: "newsletter"}},
+				{ID: 2, Name: "summer", Owner: "alice"},
+			},
+			status: http.StatusOK,
+		},
+		{
+			name:   "Viewer sees everyone's",
+			user:   storage.User{Username: "bob", Role: storage.RoleViewer},
+			status: http.StatusOK,
+		},
+		{
+			name:      "ListCampaigns Error",
+			user:      storage.User{Username: "alice", Role: storage.RoleUser},
+			owner:     "alice",
+			status:    http.StatusInternalServerError,
+			respError: "failed to list campaigns",
+			mockError: errors.New("unexpected error"),
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			campaignListerMock := mocks.NewCampaignLister(t)
+			campaignListerMock.On("ListCampaigns", mock.Anything, tc.owner).Return(tc.campaigns, tc.mockError).Once()
+
+			handler := list.New(slogdiscard.NewDiscardLogger(), campaignListerMock)
+
+			req := httptest.NewRequest(http.MethodGet, "/campaigns", nil)
+			req = req.WithContext(auth.WithUser(req.Context(), tc.user))
+			rr := httptest.NewRecorder()
+			handler.ServeHTTP(rr, req)
+
+			require.Equal(t, tc.status, rr.Code)
+
+			var resp list.Response
+			require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &resp))
+
+			require.Equal(t, tc.respError, resp.Error)
+			require.Len(t, resp.Campaigns, len(tc.campaigns))
+			if len(tc.campaigns) > 0 {
+				require.Equal(t, "newsletter", resp.Campaigns[0].UTM.Source)
+				require.Nil(t, resp.Campaigns[1].UTM)
+			}
+		})
+	}
+}
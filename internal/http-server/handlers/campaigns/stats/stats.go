@@ -0,0 +1,201 @@
+package stats
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"log/slog"
+	"maps"
+	"net/http"
+	"slices"
+	"strconv"
+	"time"
+	urlStats "url-shortener/internal/http-server/handlers/url/stats"
+	"url-shortener/internal/http-server/middleware/auth"
+	resp "url-shortener/internal/lib/api/response"
+	"url-shortener/internal/lib/logger/sl"
+	"url-shortener/internal/lib/shorturl"
+	"url-shortener/internal/storage"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/go-chi/render"
+)
+
+// LinkClicks counts the clicks on one link of the campaign.
+type LinkClicks struct {
+	Alias    string `json:"alias"`
+	ShortURL string `json:"short_url"`
+	Clicks   int64  `json:"clicks"`
+}
+
+type Response struct {
+	resp.Response
+	ID          int64                  `json:"id"`
+	Name        string                 `json:"name"`
+	TotalClicks int64                  `json:"total_clicks"`
+	LastAccess  *time.Time             `json:"last_access,omitempty"`
+	Daily       []urlStats.DailyClicks `json:"daily"`
+	// Links are ordered by clicks, most clicked first.
+	Links []LinkClicks `json:"links"`
+}
+
+const (
+	defaultDays = 30
+	maxDays     = 366
+
+	// pageSize is how many links of the campaign are read at a time.
+	pageSize = 500
+)
+
+//go:generate go run github.com/vektra/mockery/v2@latest --name=CampaignGetter
+type CampaignGetter interface {
+	GetCampaign(ctx context.Context, id int64, owner string) (storage.Campaign, error)
+}
+
+//go:generate go run github.com/vektra/mockery/v2@latest --name=URLLister
+type URLLister interface {
+	ListURLs(ctx context.Context, filter storage.ListFilter, limit int, offset int, desc bool) ([]storage.URL, error)
+}
+
+//go:generate go run github.com/vektra/mockery/v2@latest --name=StatsGetter
+type StatsGetter interface {
+	GetStats(ctx context.Context, alias string, since time.Time, includeBots bool) (storage.Stats, error)
+}
+
+// Storage is what the campaign stats are read from.
+type Storage interface {
+	CampaignGetter
+	URLLister
+	StatsGetter
+}
+
+// New returns click statistics for the campaign {id}, summed over its
+// links: total clicks, last access and a daily breakdown for the last
+// `days` days (30 by default, today included), as well as the total of
+// each link. Clicks of bots are counted only with include_bots=true.
+// Editors can only see their own campaigns, admins and viewers can see
+// any.
+func New(log *slog.Logger, store Storage, links *shorturl.Builder) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.campaigns.stats.New"
+
+		log := log.With(
+			slog.String("op", op),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+
+		id, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+		if err != nil || id <= 0 {
+			log.Info("invalid campaign id", slog.String("id", chi.URLParam(r, "id")))
+			render.Status(r, http.StatusBadRequest)
+			render.JSON(w, r, resp.Error("invalid request"))
+			return
+		}
+
+		days := defaultDays
+		if v := r.URL.Query().Get("days"); v != "" {
+			n, err := strconv.Atoi(v)
+			if err != nil || n <= 0 || n > maxDays {
+				log.Info("invalid days", slog.String("days", v))
+				render.Status(r, http.StatusBadRequest)
+				render.JSON(w, r, resp.Error("invalid days"))
+				return
+			}
+			days = n
+		}
+
+		includeBots := false
+		if v := r.URL.Query().Get("include_bots"); v != "" {
+			b, err := strconv.ParseBool(v)
+			if err != nil {
+				log.Info("invalid include_bots", slog.String("include_bots", v))
+				render.Status(r, http.StatusBadRequest)
+				render.JSON(w, r, resp.Error("invalid include_bots"))
+				return
+			}
+			includeBots = b
+		}
+
+		c, err := store.GetCampaign(r.Context(), id, auth.ViewerFromContext(r.Context()))
+		if errors.Is(err, storage.ErrCampaignNotFound) {
+			log.Info("campaign not found", slog.Int64("id", id))
+			render.Status(r, http.StatusNotFound)
+			render.JSON(w, r, resp.Error("campaign not found"))
+			return
+		}
+		if err != nil {
+			log.Error("failed to get campaign", sl.Err(err))
+			render.Status(r, http.StatusInternalServerError)
+			render.JSON(w, r, resp.Error("failed to get stats"))
+			return
+		}
+
+		today := time.Now().UTC().Truncate(24 * time.Hour)
+		since := today.AddDate(0, 0, -(days - 1))
+
+		res := Response{
+			Response: resp.OK(),
+			ID:       c.ID,
+			Name:     c.Name,
+			Daily:    []urlStats.DailyClicks{},
+			Links:    []LinkClicks{},
+		}
+		daily := make(map[string]int64)
+		var lastAccess time.Time
+
+		filter := storage.ListFilter{CampaignID: c.ID}
+		for offset := 0; ; offset += pageSize {
+			urls, err := store.ListURLs(r.Context(), filter, pageSize, offset, false)
+			if err != nil {
+				log.Error("failed to list campaign urls", sl.Err(err))
+				render.Status(r, http.StatusInternalServerError)
+				render.JSON(w, r, resp.Error("failed to get stats"))
+				return
+			}
+
+			for _, u := range urls {
+				stats, err := store.GetStats(r.Context(), u.Alias, since, includeBots)
+				if errors.Is(err, storage.ErrUrlNotFound) {
+					// Deleted since it was listed.
+					continue
+				}
+				if err != nil {
+					log.Error("failed to get stats", slog.String("alias", u.Alias), sl.Err(err))
+					render.Status(r, http.StatusInternalServerError)
+					render.JSON(w, r, resp.Error("failed to get stats"))
+					return
+				}
+
+				res.TotalClicks += stats.Total
+				if stats.LastAccess.After(lastAccess) {
+					lastAccess = stats.LastAccess
+				}
+				for _, d := range stats.Daily {
+					daily[d.Date] += d.Clicks
+				}
+				res.Links = append(res.Links, LinkClicks{
+					Alias:    u.Alias,
+					ShortURL: links.URL(r, u.Domain, u.Alias),
+					Clicks:   stats.Total,
+				})
+			}
+
+			if len(urls) < pageSize {
+				break
+			}
+		}
+
+		if !lastAccess.IsZero() {
+			res.LastAccess = &lastAccess
+		}
+		for _, date := range slices.Sorted(maps.Keys(daily)) {
+			res.Daily = append(res.Daily, urlStats.DailyClicks{Date: date, Clicks: daily[date]})
+		}
+		slices.SortStableFunc(res.Links, func(a, b LinkClicks) int {
+			return cmp.Compare(b.Clicks, a.Clicks)
+		})
+
+		render.JSON(w, r, res)
+	}
+}
@@ -0,0 +1,93 @@
+package stats_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"url-shortener/internal/http-server/handlers/campaigns/stats"
+	"url-shortener/internal/http-server/handlers/campaigns/stats/mocks"
+	"url-shortener/internal/http-server/middleware/auth"
+	"url-shortener/internal/lib/logger/handlers/slogdiscard"
+	"url-shortener/internal/lib/shorturl"
+	"url-shortener/internal/storage"
+)
+
+type store struct {
+	*mocks.CampaignGetter
+	*mocks.URLLister
+	*mocks.StatsGetter
+}
+
+func TestStatsHandler(t *testing.T) {
+	earlier := time.Date(2025, 3, 1, 10, 0, 0, 0, time.UTC)
+	later := time.Date(2025, 3, 2, 12, 0, 0, 0, time.UTC)
+
+	s := store{mocks.NewCampaignGetter(t), mocks.NewURLLister(t), mocks.NewStatsGetter(t)}
+	s.CampaignGetter.On("GetCampaign", mock.Anything, int64(3), "alice").
+		Return(storage.Campaign{ID: 3, Name: "spring", Owner: "alice"}, nil).Once()
+	s.URLLister.On("ListURLs", mock.Anything, storage.ListFilter{CampaignID: 3}, 500, 0, false).
+		Return([]storage.URL{{Alias: "a"}, {Alias: "b", Domain: "go.acme.com"}, {Alias: "gone"}}, nil).Once()
+	s.StatsGetter.On("GetStats", mock.Anything, "a", mock.Anything, false).Return(storage.Stats{
+		Total:      2,
+		LastAccess: earlier,
+		Daily:      []storage.DailyClicks{{Date: "2025-03-01", Clicks: 2}},
+	}, nil).Once()
+	s.StatsGetter.On("GetStats", mock.Anything, "b", mock.Anything, false).Return(storage.Stats{
+		Total:      5,
+		LastAccess: later,
+		Daily:      []storage.DailyClicks{{Date: "2025-03-01", Clicks: 1}, {Date: "2025-03-02", Clicks: 4}},
+	}, nil).Once()
+	s.StatsGetter.On("GetStats", mock.Anything, "gone", mock.Anything, false).
+		Return(storage.Stats{}, storage.ErrUrlNotFound).Once()
+
+	links, err := shorturl.New("https://sho.rt", []string{"sho.rt", "go.acme.com"})
+	require.NoError(t, err)
+
+	r := chi.NewRouter()
+	r.Get("/campaigns/{id}/stats", stats.New(slogdiscard.NewDiscardLogger(), s, links))
+
+	req := httptest.NewRequest(http.MethodGet, "/campaigns/3/stats", nil)
+	req = req.WithContext(auth.WithUser(req.Context(), storage.User{Username: "alice", Role: storage.RoleUser}))
+	rr := httptest.NewRecorder()
+	r.ServeHTTP(rr, req)
+
+	require.Equal(t, http.StatusOK, rr.Code)
+
+	var res stats.Response
+	require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &res))
+
+	require.Equal(t, "spring", res.Name)
+	require.Equal(t, int64(7), res.TotalClicks)
+	require.Equal(t, later, *res.LastAccess)
+	require.Len(t, res.Daily, 2)
+	require.Equal(t, int64(3), res.Daily[0].Clicks)
+	require.Equal(t, int64(4), res.Daily[1].Clicks)
+	require.Equal(t, []stats.LinkClicks{
+		{Alias: "b", ShortURL: "https://go.acme.com/b", Clicks: 5},
+		{Alias: "a", ShortURL: "https://sho.rt/a", Clicks: 2},
+	}, res.Links)
+}
+
+func TestStatsHandler_NotFound(t *testing.T) {
+	s := store{mocks.NewCampaignGetter(t), mocks.NewURLLister(t), mocks.NewStatsGetter(t)}
+	s.CampaignGetter.On("GetCampaign", mock.Anything, int64(3), "alice").
+		Return(storage.Campaign{}, storage.ErrCampaignNotFound).Once()
+
+	r := chi.NewRouter()
+	r.Get("/campaigns/{id}/stats", stats.New(slogdiscard.NewDiscardLogger(), s, nil))
+
+	req := httptest.NewRequest(http.MethodGet, "/campaigns/3/stats", nil)
+	req = req.WithContext(auth.WithUser(req.Context(), storage.User{Username: "alice", Role: storage.RoleUser}))
+	rr := httptest.NewRecorder()
+	r.ServeHTTP(rr, req)
+
+	require.Equal(t, http.StatusNotFound, rr.Code)
+	require.Contains(t, rr.Body.String(), "campaign not found")
+}
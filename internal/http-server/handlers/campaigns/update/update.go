@@ -0,0 +1,82 @@
+package update
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"time"
+	"url-shortener/internal/http-server/handlers/campaigns/create"
+	"url-shortener/internal/http-server/middleware/auth"
+	resp "url-shortener/internal/lib/api/response"
+	"url-shortener/internal/lib/logger/sl"
+	"url-shortener/internal/lib/shorturl"
+	"url-shortener/internal/storage"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/go-chi/render"
+)
+
+//go:generate go run github.com/vektra/mockery/v2@latest --name=CampaignUpdater
+type CampaignUpdater interface {
+	UpdateCampaign(ctx context.Context, c storage.Campaign, owner string) error
+}
+
+// New replaces the name and the settings of the campaign {id} with those
+// of a create.Request. Links already in the campaign keep theirs. Users
+// can only update their own campaigns, admins can update any.
+func New(log *slog.Logger, campaignUpdater CampaignUpdater, links *shorturl.Builder) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.campaigns.update.New"
+
+		log := log.With(
+			slog.String("op", op),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+
+		id, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+		if err != nil || id <= 0 {
+			log.Info("invalid campaign id", slog.String("id", chi.URLParam(r, "id")))
+			render.Status(r, http.StatusBadRequest)
+			render.JSON(w, r, resp.Error("invalid request"))
+			return
+		}
+
+		var req create.Request
+		if err := render.DecodeJSON(r.Body, &req); err != nil {
+			log.Error("failed to decode request body", sl.Err(err))
+			render.Status(r, http.StatusBadRequest)
+			render.JSON(w, r, resp.Error("failed to decode request"))
+			return
+		}
+
+		c, errResp, ok := create.Build(req, links, time.Now())
+		if !ok {
+			log.Info("invalid request", slog.String("error", errResp.Error))
+			render.Status(r, http.StatusBadRequest)
+			render.JSON(w, r, errResp)
+			return
+		}
+		c.ID = id
+
+		err = campaignUpdater.UpdateCampaign(r.Context(), c, auth.OwnerFromContext(r.Context()))
+		if errors.Is(err, storage.ErrCampaignNotFound) {
+			log.Info("campaign not found", slog.Int64("id", id))
+			render.Status(r, http.StatusNotFound)
+			render.JSON(w, r, resp.Error("campaign not found"))
+			return
+		}
+		if err != nil {
+			log.Error("failed to update campaign", sl.Err(err))
+			render.Status(r, http.StatusInternalServerError)
+			render.JSON(w, r, resp.Error("failed to update campaign"))
+			return
+		}
+
+		log.Info("campaign updated", slog.Int64("id", id))
+
+		render.JSON(w, r, resp.OK())
+	}
+}
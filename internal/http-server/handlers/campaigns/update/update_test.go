@@ -0,0 +1,106 @@
+package update_test
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"url-shortener/internal/http-server/handlers/campaigns/update"
+	"url-shortener/internal/http-server/handlers/campaigns/update/mocks"
+	"url-shortener/internal/http-server/middleware/auth"
+	resp "url-shortener/internal/lib/api/response"
+	"url-shortener/internal/lib/logger/handlers/slogdiscard"
+	"url-shortener/internal/lib/shorturl"
+	"url-shortener/internal/storage"
+)
+
+func TestUpdateHandler(t *testing.T) {
+	cases := []struct {
+		name      string
+		id        string
+		input     string
+		status    int
+		respError string
+		update    bool
+		mockError error
+	}{
+		{
+			name:   "Success",
+			id:     "1",
+			input:  `{"name": "autumn", "utm": {"campaign": "autumn"}}`,
+			status: http.StatusOK,
+			update: true,
+		},
+		{
+			name:      "Not found",
+			id:        "1",
+			input:     `{"name": "autumn", "utm": {"campaign": "autumn"}}`,
+			status:    http.StatusNotFound,
+			respError: "campaign not found",
+			update:    true,
+			mockError: storage.ErrCampaignNotFound,
+		},
+		{
+			name:      "Invalid id",
+			id:        "abc",
+			input:     `{"name": "autumn"}`,
+			status:    http.StatusBadRequest,
+			respError: "invalid request",
+		},
+		{
+			name:      "Empty name",
+			id:        "1",
+			input:     `{"utm": {"campaign": "autumn"}}`,
+			status:    http.StatusBadRequest,
+			respError: "field Name is a required field",
+		},
+		{
+			name:      "UpdateCampaign Error",
+			id:        "1",
+			input:     `{"name": "autumn", "utm": {"campaign": "autumn"}}`,
+			status:    http.StatusInternalServerError,
+			respError: "failed to update campaign",
+			update:    true,
+			mockError: errors.New("unexpected error"),
+		},
+	}
+
+	links, err := shorturl.New("https://sho.rt", nil)
+	require.NoError(t, err)
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			campaignUpdaterMock := mocks.NewCampaignUpdater(t)
+
+			if tc.update {
+				campaignUpdaterMock.On("UpdateCampaign", mock.Anything, storage.Campaign{
+					ID:   1,
+					Name: "autumn",
+					UTM:  storage.UTM{Campaign: "autumn"},
+				}, "alice").Return(tc.mockError).Once()
+			}
+
+			r := chi.NewRouter()
+			r.Put("/campaigns/{id}", update.New(slogdiscard.NewDiscardLogger(), campaignUpdaterMock, links))
+
+			req := httptest.NewRequest(http.MethodPut, "/campaigns/"+tc.id, strings.NewReader(tc.input))
+			req = req.WithContext(auth.WithUser(req.Context(), storage.User{Username: "alice", Role: storage.RoleUser}))
+			rr := httptest.NewRecorder()
+			r.ServeHTTP(rr, req)
+
+			require.Equal(t, tc.status, rr.Code)
+
+			var body resp.Response
+			require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &body))
+
+			require.Equal(t, tc.respError, body.Error)
+		})
+	}
+}
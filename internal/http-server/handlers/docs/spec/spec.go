@@ -0,0 +1,15 @@
+package spec
+
+import (
+	"net/http"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/go-chi/render"
+)
+
+// New serves the OpenAPI document as JSON.
+func New(doc *openapi3.T) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		render.JSON(w, r, doc)
+	}
+}
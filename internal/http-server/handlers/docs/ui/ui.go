@@ -0,0 +1,32 @@
+package ui
+
+import (
+	"html/template"
+	"net/http"
+)
+
+// Swagger UI is loaded from a CDN so its assets do not end up in the binary.
+var page = template.Must(template.New("docs").Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+  <meta charset="utf-8">
+  <title>url-shortener API</title>
+  <link rel="stylesheet" href="https://unpkg.com/swagger-ui-dist@5/swagger-ui.css">
+</head>
+<body>
+  <div id="swagger-ui"></div>
+  <script src="https://unpkg.com/swagger-ui-dist@5/swagger-ui-bundle.js"></script>
+  <script>
+    SwaggerUIBundle({url: {{.}}, dom_id: "#swagger-ui"});
+  </script>
+</body>
+</html>
+`))
+
+// New serves Swagger UI for the OpenAPI document at specURL.
+func New(specURL string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		_ = page.Execute(w, specURL)
+	}
+}
@@ -0,0 +1,16 @@
+package live
+
+import (
+	"net/http"
+	resp "url-shortener/internal/lib/api/response"
+
+	"github.com/go-chi/render"
+)
+
+// New reports that the process is up. It does not touch the storage, so an
+// orchestrator restarts the service only when it stops serving HTTP at all.
+func New() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		render.JSON(w, r, resp.OK())
+	}
+}
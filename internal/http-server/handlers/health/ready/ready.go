@@ -0,0 +1,55 @@
+package ready
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"sync/atomic"
+	"time"
+	resp "url-shortener/internal/lib/api/response"
+	"url-shortener/internal/lib/logger/sl"
+
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/go-chi/render"
+)
+
+// pingTimeout bounds the storage check so a hung connection fails the probe
+// instead of stalling it.
+const pingTimeout = 2 * time.Second
+
+//go:generate go run github.com/vektra/mockery/v2@latest --name=Pinger
+type Pinger interface {
+	Ping(ctx context.Context) error
+}
+
+// New reports whether the service can take traffic: the storage answers a
+// ping and the server is not shutting down. Once shuttingDown is set the
+// probe fails, so the orchestrator stops routing requests here.
+func New(log *slog.Logger, pinger Pinger, shuttingDown *atomic.Bool) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.health.ready.New"
+
+		log := log.With(
+			slog.String("op", op),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+
+		if shuttingDown.Load() {
+			render.Status(r, http.StatusServiceUnavailable)
+			render.JSON(w, r, resp.Error("shutting down"))
+			return
+		}
+
+		ctx, cancel := context.WithTimeout(r.Context(), pingTimeout)
+		defer cancel()
+
+		if err := pinger.Ping(ctx); err != nil {
+			log.Error("storage is unavailable", sl.Err(err))
+			render.Status(r, http.StatusServiceUnavailable)
+			render.JSON(w, r, resp.Error("storage is unavailable"))
+			return
+		}
+
+		render.JSON(w, r, resp.OK())
+	}
+}
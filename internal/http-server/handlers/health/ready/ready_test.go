@@ -0,0 +1,69 @@
+package ready_test
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"url-shortener/internal/http-server/handlers/health/ready"
+	"url-shortener/internal/http-server/handlers/health/ready/mocks"
+	resp "url-shortener/internal/lib/api/response"
+	"url-shortener/internal/lib/logger/handlers/slogdiscard"
+)
+
+func TestReadyHandler(t *testing.T) {
+	cases := []struct {
+		name         string
+		shuttingDown bool
+		pingError    error
+		status       int
+		respError    string
+	}{
+		{
+			name:   "Ready",
+			status: http.StatusOK,
+		},
+		{
+			name:      "Storage Down",
+			pingError: errors.New("connection refused"),
+			status:    http.StatusServiceUnavailable,
+			respError: "storage is unavailable",
+		},
+		{
+			name:         "Shutting Down",
+			shuttingDown: true,
+			status:       http.StatusServiceUnavailable,
+			respError:    "shutting down",
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			pingerMock := mocks.NewPinger(t)
+			if !tc.shuttingDown {
+				pingerMock.On("Ping", mock.Anything).Return(tc.pingError).Once()
+			}
+
+			var shuttingDown atomic.Bool
+			shuttingDown.Store(tc.shuttingDown)
+
+			handler := ready.New(slogdiscard.NewDiscardLogger(), pingerMock, &shuttingDown)
+
+			req := httptest.NewRequest(http.MethodGet, "/readyz", nil)
+			rr := httptest.NewRecorder()
+			handler.ServeHTTP(rr, req)
+
+			require.Equal(t, tc.status, rr.Code)
+
+			var res resp.Response
+			require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &res))
+			require.Equal(t, tc.respError, res.Error)
+		})
+	}
+}
@@ -0,0 +1,98 @@
+package create
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"time"
+	"url-shortener/internal/http-server/middleware/auth"
+	resp "url-shortener/internal/lib/api/response"
+	"url-shortener/internal/lib/apikey"
+	"url-shortener/internal/lib/logger/sl"
+	"url-shortener/internal/storage"
+
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/go-chi/render"
+	"github.com/go-playground/validator/v10"
+)
+
+type Request struct {
+	Name string `json:"name" validate:"required,max=100"`
+}
+
+type Response struct {
+	resp.Response
+	ID   int64  `json:"id,omitempty"`
+	Name string `json:"name,omitempty"`
+	// Key is shown only once, right after creation.
+	Key       string    `json:"key,omitempty"`
+	CreatedAt time.Time `json:"created_at,omitempty"`
+}
+
+//go:generate go run github.com/vektra/mockery/v2@latest --name=KeySaver
+type KeySaver interface {
+	SaveAPIKey(ctx context.Context, key storage.APIKey) (int64, error)
+}
+
+// New creates an API key owned by the authenticated user. Only a hash of
+// the key is stored, so the response is the only place the key appears.
+func New(log *slog.Logger, keySaver KeySaver) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.keys.create.New"
+
+		log := log.With(
+			slog.String("op", op),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+
+		var req Request
+		if err := render.DecodeJSON(r.Body, &req); err != nil {
+			log.Error("failed to decode request body", sl.Err(err))
+			render.Status(r, http.StatusBadRequest)
+			render.JSON(w, r, resp.Error("failed to decode request"))
+			return
+		}
+
+		if err := validator.New().Struct(req); err != nil {
+			validateErr := err.(validator.ValidationErrors)
+
+			log.Error("invalid request", sl.Err(err))
+			render.Status(r, http.StatusBadRequest)
+			render.JSON(w, r, resp.ValidationError(validateErr))
+			return
+		}
+
+		key, err := apikey.Generate()
+		if err != nil {
+			log.Error("failed to generate api key", sl.Err(err))
+			render.Status(r, http.StatusInternalServerError)
+			render.JSON(w, r, resp.Error("failed to create api key"))
+			return
+		}
+
+		user, _ := auth.UserFromContext(r.Context())
+
+		id, err := keySaver.SaveAPIKey(r.Context(), storage.APIKey{
+			Name:  req.Name,
+			Owner: user.Username,
+			Hash:  apikey.Hash(key),
+		})
+		if err != nil {
+			log.Error("failed to save api key", sl.Err(err))
+			render.Status(r, http.StatusInternalServerError)
+			render.JSON(w, r, resp.Error("failed to create api key"))
+			return
+		}
+
+		log.Info("api key created", slog.Int64("id", id), slog.String("name", req.Name))
+
+		render.Status(r, http.StatusCreated)
+		render.JSON(w, r, Response{
+			Response:  resp.OK(),
+			ID:        id,
+			Name:      req.Name,
+			Key:       key,
+			CreatedAt: time.Now().UTC(),
+		})
+	}
+}
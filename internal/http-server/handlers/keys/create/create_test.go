@@ -0,0 +1,86 @@
+package create_test
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"url-shortener/internal/http-server/handlers/keys/create"
+	"url-shortener/internal/http-server/handlers/keys/create/mocks"
+	"url-shortener/internal/http-server/middleware/auth"
+	"url-shortener/internal/lib/apikey"
+	"url-shortener/internal/lib/logger/handlers/slogdiscard"
+	"url-shortener/internal/storage"
+)
+
+func TestCreateHandler(t *testing.T) {
+	cases := []struct {
+		name      string
+		input     string
+		status    int
+		respError string
+		save      bool
+		mockError error
+	}{
+		{
+			name:   "Success",
+			input:  `{"name": "ci"}`,
+			status: http.StatusCreated,
+			save:   true,
+		},
+		{
+			name:      "Empty name",
+			input:     `{}`,
+			status:    http.StatusBadRequest,
+			respError: "field Name is a required field",
+		},
+		{
+			name:      "SaveAPIKey Error",
+			input:     `{"name": "ci"}`,
+			status:    http.StatusInternalServerError,
+			respError: "failed to create api key",
+			save:      true,
+			mockError: errors.New("unexpected error"),
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			keySaverMock := mocks.NewKeySaver(t)
+
+			var savedHash string
+			if tc.save {
+				keySaverMock.On("SaveAPIKey", mock.Anything, mock.MatchedBy(func(k storage.APIKey) bool {
+					savedHash = k.Hash
+					return k.Name == "ci" && k.Owner == "alice" && k.Hash != ""
+				})).Return(int64(1), tc.mockError).Once()
+			}
+
+			handler := create.New(slogdiscard.NewDiscardLogger(), keySaverMock)
+
+			req := httptest.NewRequest(http.MethodPost, "/keys", strings.NewReader(tc.input))
+			req = req.WithContext(auth.WithUser(req.Context(), storage.User{Username: "alice", Role: storage.RoleUser}))
+			rr := httptest.NewRecorder()
+			handler.ServeHTTP(rr, req)
+
+			require.Equal(t, tc.status, rr.Code)
+
+			var resp create.Response
+			require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &resp))
+
+			require.Equal(t, tc.respError, resp.Error)
+
+			if tc.respError == "" {
+				require.Equal(t, int64(1), resp.ID)
+				require.NotEmpty(t, resp.Key)
+				require.Equal(t, savedHash, apikey.Hash(resp.Key))
+			}
+		})
+	}
+}
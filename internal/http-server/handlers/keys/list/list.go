@@ -0,0 +1,75 @@
+package list
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"time"
+	"url-shortener/internal/http-server/middleware/auth"
+	resp "url-shortener/internal/lib/api/response"
+	"url-shortener/internal/lib/logger/sl"
+	"url-shortener/internal/storage"
+
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/go-chi/render"
+)
+
+type Key struct {
+	ID        int64      `json:"id"`
+	Name      string     `json:"name"`
+	Owner     string     `json:"owner,omitempty"`
+	CreatedAt time.Time  `json:"created_at"`
+	RevokedAt *time.Time `json:"revoked_at,omitempty"`
+}
+
+type Response struct {
+	resp.Response
+	Keys []Key `json:"keys"`
+}
+
+//go:generate go run github.com/vektra/mockery/v2@latest --name=KeyLister
+type KeyLister interface {
+	ListAPIKeys(ctx context.Context, owner string) ([]storage.APIKey, error)
+}
+
+// New lists API keys, revoked ones included. Key values are never returned.
+// Users see their own keys, admins see everyone's.
+func New(log *slog.Logger, keyLister KeyLister) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.keys.list.New"
+
+		log := log.With(
+			slog.String("op", op),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+
+		keys, err := keyLister.ListAPIKeys(r.Context(), auth.OwnerFromContext(r.Context()))
+		if err != nil {
+			log.Error("failed to list api keys", sl.Err(err))
+			render.Status(r, http.StatusInternalServerError)
+			render.JSON(w, r, resp.Error("failed to list api keys"))
+			return
+		}
+
+		res := Response{
+			Response: resp.OK(),
+			Keys:     make([]Key, 0, len(keys)),
+		}
+		for _, k := range keys {
+			item := Key{
+				ID:        k.ID,
+				Name:      k.Name,
+				Owner:     k.Owner,
+				CreatedAt: k.CreatedAt,
+			}
+			if k.Revoked() {
+				item.RevokedAt = &k.RevokedAt
+			}
+			res.Keys = append(res.Keys, item)
+		}
+
+		log.Info("api keys listed", slog.Int("count", len(keys)))
+
+		render.JSON(w, r, res)
+	}
+}
@@ -0,0 +1,75 @@
+package list_test
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"url-shortener/internal/http-server/handlers/keys/list"
+	"url-shortener/internal/http-server/handlers/keys/list/mocks"
+	"url-shortener/internal/http-server/middleware/auth"
+	"url-shortener/internal/lib/logger/handlers/slogdiscard"
+	"url-shortener/internal/storage"
+)
+
+func TestListHandler(t *testing.T) {
+	revokedAt := time.Date(2025, 3, 1, 10, 0, 0, 0, time.UTC)
+
+	cases := []struct {
+		name      string
+		keys      []storage.APIKey
+		status    int
+		respError string
+		mockError error
+	}{
+		{
+			name: "Success",
+			keys: []storage.APIKey{
+				{ID: 1, Name: "ci", Owner: "myuser", Hash: "secret-hash"},
+				{ID: 2, Name: "old", RevokedAt: revokedAt},
+			},
+			status: http.StatusOK,
+		},
+		{
+			name:      "ListAPIKeys Error",
+			status:    http.StatusInternalServerError,
+			respError: "failed to list api keys",
+			mockError: errors.New("unexpected error"),
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			keyListerMock := mocks.NewKeyLister(t)
+			keyListerMock.On("ListAPIKeys", mock.Anything, "alice").Return(tc.keys, tc.mockError).Once()
+
+			handler := list.New(slogdiscard.NewDiscardLogger(), keyListerMock)
+
+			req := httptest.NewRequest(http.MethodGet, "/keys", nil)
+			req = req.WithContext(auth.WithUser(req.Context(), storage.User{Username: "alice", Role: storage.RoleUser}))
+			rr := httptest.NewRecorder()
+			handler.ServeHTTP(rr, req)
+
+			require.Equal(t, tc.status, rr.Code)
+			require.NotContains(t, rr.Body.String(), "secret-hash")
+
+			var resp list.Response
+			require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &resp))
+
+			require.Equal(t, tc.respError, resp.Error)
+
+			if tc.respError == "" {
+				require.Len(t, resp.Keys, 2)
+				require.Nil(t, resp.Keys[0].RevokedAt)
+				require.NotNil(t, resp.Keys[1].RevokedAt)
+				require.True(t, revokedAt.Equal(*resp.Keys[1].RevokedAt))
+			}
+		})
+	}
+}
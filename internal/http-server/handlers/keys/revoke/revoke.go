@@ -0,0 +1,61 @@
+package revoke
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"url-shortener/internal/http-server/middleware/auth"
+	resp "url-shortener/internal/lib/api/response"
+	"url-shortener/internal/lib/logger/sl"
+	"url-shortener/internal/storage"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/go-chi/render"
+)
+
+//go:generate go run github.com/vektra/mockery/v2@latest --name=KeyRevoker
+type KeyRevoker interface {
+	RevokeAPIKey(ctx context.Context, id int64, owner string) error
+}
+
+// New revokes the API key {id}. Links created with the key are kept. Users
+// can only revoke their own keys, admins can revoke any.
+func New(log *slog.Logger, keyRevoker KeyRevoker) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.keys.revoke.New"
+
+		log := log.With(
+			slog.String("op", op),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+
+		id, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+		if err != nil || id <= 0 {
+			log.Info("invalid key id", slog.String("id", chi.URLParam(r, "id")))
+			render.Status(r, http.StatusBadRequest)
+			render.JSON(w, r, resp.Error("invalid request"))
+			return
+		}
+
+		err = keyRevoker.RevokeAPIKey(r.Context(), id, auth.OwnerFromContext(r.Context()))
+		if errors.Is(err, storage.ErrKeyNotFound) {
+			log.Info("api key not found", slog.Int64("id", id))
+			render.Status(r, http.StatusNotFound)
+			render.JSON(w, r, resp.Error("api key not found"))
+			return
+		}
+		if err != nil {
+			log.Error("failed to revoke api key", sl.Err(err))
+			render.Status(r, http.StatusInternalServerError)
+			render.JSON(w, r, resp.Error("failed to revoke api key"))
+			return
+		}
+
+		log.Info("api key revoked", slog.Int64("id", id))
+
+		render.JSON(w, r, resp.OK())
+	}
+}
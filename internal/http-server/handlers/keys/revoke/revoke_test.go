@@ -0,0 +1,87 @@
+package revoke_test
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"url-shortener/internal/http-server/handlers/keys/revoke"
+	"url-shortener/internal/http-server/handlers/keys/revoke/mocks"
+	"url-shortener/internal/http-server/middleware/auth"
+	resp "url-shortener/internal/lib/api/response"
+	"url-shortener/internal/lib/logger/handlers/slogdiscard"
+	"url-shortener/internal/storage"
+)
+
+func TestRevokeHandler(t *testing.T) {
+	cases := []struct {
+		name      string
+		id        string
+		status    int
+		respError string
+		revoke    bool
+		mockError error
+	}{
+		{
+			name:   "Success",
+			id:     "1",
+			status: http.StatusOK,
+			revoke: true,
+		},
+		{
+			name:      "Not found",
+			id:        "1",
+			status:    http.StatusNotFound,
+			respError: "api key not found",
+			revoke:    true,
+			mockError: storage.ErrKeyNotFound,
+		},
+		{
+			name:      "Invalid id",
+			id:        "abc",
+			status:    http.StatusBadRequest,
+			respError: "invalid request",
+		},
+		{
+			name:      "RevokeAPIKey Error",
+			id:        "1",
+			status:    http.StatusInternalServerError,
+			respError: "failed to revoke api key",
+			revoke:    true,
+			mockError: errors.New("unexpected error"),
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			keyRevokerMock := mocks.NewKeyRevoker(t)
+
+			if tc.revoke {
+				keyRevokerMock.On("RevokeAPIKey", mock.Anything, int64(1), "alice").
+					Return(tc.mockError).
+					Once()
+			}
+
+			r := chi.NewRouter()
+			r.Delete("/keys/{id}", revoke.New(slogdiscard.NewDiscardLogger(), keyRevokerMock))
+
+			req := httptest.NewRequest(http.MethodDelete, "/keys/"+tc.id, nil)
+			req = req.WithContext(auth.WithUser(req.Context(), storage.User{Username: "alice", Role: storage.RoleUser}))
+			rr := httptest.NewRecorder()
+			r.ServeHTTP(rr, req)
+
+			require.Equal(t, tc.status, rr.Code)
+
+			var body resp.Response
+			require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &body))
+
+			require.Equal(t, tc.respError, body.Error)
+		})
+	}
+}
@@ -0,0 +1,67 @@
+package usage
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"url-shortener/internal/http-server/middleware/auth"
+	resp "url-shortener/internal/lib/api/response"
+	"url-shortener/internal/lib/logger/sl"
+	"url-shortener/internal/lib/quota"
+
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/go-chi/render"
+)
+
+type Response struct {
+	resp.Response
+	quota.Usage
+	// RemainingLinks and RemainingToday are left out for unlimited quotas.
+	RemainingLinks *int64 `json:"remaining_links,omitempty"`
+	RemainingToday *int64 `json:"remaining_today,omitempty"`
+}
+
+//go:generate go run github.com/vektra/mockery/v2@latest --name=UsageGetter
+type UsageGetter interface {
+	Usage(ctx context.Context, owner string, apiKeyID int64) (quota.Usage, error)
+}
+
+// New reports the quota of the caller: of the API key the request was
+// authenticated with, or else of the user.
+func New(log *slog.Logger, usageGetter UsageGetter) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.quota.usage.New"
+
+		log := log.With(
+			slog.String("op", op),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+
+		keyID, _ := auth.APIKeyIDFromContext(r.Context())
+
+		usage, err := usageGetter.Usage(r.Context(), auth.OwnerFromContext(r.Context()), keyID)
+		if err != nil {
+			log.Error("failed to get quota usage", sl.Err(err))
+			render.Status(r, http.StatusInternalServerError)
+			render.JSON(w, r, resp.Error("failed to get quota usage"))
+			return
+		}
+
+		res := Response{Response: resp.OK(), Usage: usage}
+		if usage.MaxLinks > 0 {
+			res.RemainingLinks = remaining(usage.MaxLinks, usage.Links)
+		}
+		if usage.MaxPerDay > 0 {
+			res.RemainingToday = remaining(usage.MaxPerDay, usage.Today)
+		}
+
+		render.JSON(w, r, res)
+	}
+}
+
+// remaining is limit-used, never below 0: lowering a limit leaves existing
+// links in place.
+func remaining(limit, used int64) *int64 {
+	n := max(limit-used, 0)
+	return &n
+}
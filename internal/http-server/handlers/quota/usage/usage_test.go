@@ -0,0 +1,78 @@
+package usage_test
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"url-shortener/internal/http-server/handlers/quota/usage"
+	"url-shortener/internal/http-server/handlers/quota/usage/mocks"
+	"url-shortener/internal/http-server/middleware/auth"
+	"url-shortener/internal/lib/logger/handlers/slogdiscard"
+	"url-shortener/internal/lib/quota"
+	"url-shortener/internal/storage"
+)
+
+func TestUsageHandler(t *testing.T) {
+	cases := []struct {
+		name           string
+		usage          quota.Usage
+		status         int
+		respError      string
+		mockError      error
+		remainingLinks *int64
+		remainingToday *int64
+	}{
+		{
+			name:           "Limited",
+			usage:          quota.Usage{Links: 7, MaxLinks: 10, Today: 3, MaxPerDay: 2},
+			status:         http.StatusOK,
+			remainingLinks: ptr(3),
+			remainingToday: ptr(0),
+		},
+		{
+			name:   "Unlimited",
+			usage:  quota.Usage{},
+			status: http.StatusOK,
+		},
+		{
+			name:      "Usage Error",
+			status:    http.StatusInternalServerError,
+			respError: "failed to get quota usage",
+			mockError: errors.New("unexpected error"),
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			usageGetterMock := mocks.NewUsageGetter(t)
+			usageGetterMock.On("Usage", mock.Anything, "alice", int64(0)).Return(tc.usage, tc.mockError).Once()
+
+			handler := usage.New(slogdiscard.NewDiscardLogger(), usageGetterMock)
+
+			req := httptest.NewRequest(http.MethodGet, "/quota", nil)
+			req = req.WithContext(auth.WithUser(req.Context(), storage.User{Username: "alice", Role: storage.RoleUser}))
+			rr := httptest.NewRecorder()
+			handler.ServeHTTP(rr, req)
+
+			require.Equal(t, tc.status, rr.Code)
+
+			var resp usage.Response
+			require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &resp))
+
+			require.Equal(t, tc.respError, resp.Error)
+			require.Equal(t, tc.usage, resp.Usage)
+			require.Equal(t, tc.remainingLinks, resp.RemainingLinks)
+			require.Equal(t, tc.remainingToday, resp.RemainingToday)
+		})
+	}
+}
+
+func ptr(n int64) *int64 {
+	return &n
+}
@@ -0,0 +1,42 @@
+package access
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+	"url-shortener/internal/http-server/middleware/auth"
+	resp "url-shortener/internal/lib/api/response"
+	"url-shortener/internal/lib/logger/sl"
+	"url-shortener/internal/storage"
+
+	"github.com/go-chi/render"
+)
+
+//go:generate go run github.com/vektra/mockery/v2@latest --name=OwnerGetter
+type OwnerGetter interface {
+	GetOwner(ctx context.Context, alias string) (string, error)
+}
+
+// CanView reports whether the caller may read the clicks and stats of
+// alias: admins and viewers may read every link, other users only their
+// own. Otherwise it answers 404, so links of other users look the same as
+// missing ones, as they do in the link list.
+func CanView(w http.ResponseWriter, r *http.Request, log *slog.Logger, ownerGetter OwnerGetter, alias string) bool {
+	owner, err := ownerGetter.GetOwner(r.Context(), alias)
+	if err != nil && !errors.Is(err, storage.ErrUrlNotFound) {
+		log.Error("failed to get url owner", sl.Err(err))
+		render.Status(r, http.StatusInternalServerError)
+		render.JSON(w, r, resp.Error("internal error"))
+		return false
+	}
+
+	if viewer := auth.ViewerFromContext(r.Context()); err != nil || viewer != "" && owner != viewer {
+		log.Info("url not found", slog.String("alias", alias))
+		render.Status(r, http.StatusNotFound)
+		render.JSON(w, r, resp.Error("url not found"))
+		return false
+	}
+
+	return true
+}
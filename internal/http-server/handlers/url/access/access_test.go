@@ -0,0 +1,104 @@
+package access_test
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"url-shortener/internal/http-server/handlers/url/access"
+	"url-shortener/internal/http-server/handlers/url/access/mocks"
+	"url-shortener/internal/http-server/middleware/auth"
+	resp "url-shortener/internal/lib/api/response"
+	"url-shortener/internal/lib/logger/handlers/slogdiscard"
+	"url-shortener/internal/storage"
+)
+
+func TestCanView(t *testing.T) {
+	cases := []struct {
+		name      string
+		user      storage.User
+		owner     string
+		mockError error
+		allowed   bool
+		status    int
+		respError string
+	}{
+		{
+			name:    "Owner",
+			user:    storage.User{Username: "alice", Role: storage.RoleEditor},
+			owner:   "alice",
+			allowed: true,
+		},
+		{
+			name:    "Admin",
+			user:    storage.User{Username: "root", Role: storage.RoleAdmin},
+			owner:   "alice",
+			allowed: true,
+		},
+		{
+			name:    "Viewer",
+			user:    storage.User{Username: "carol", Role: storage.RoleViewer},
+			owner:   "alice",
+			allowed: true,
+		},
+		{
+			name:      "Other user",
+			user:      storage.User{Username: "bob", Role: storage.RoleEditor},
+			owner:     "alice",
+			status:    http.StatusNotFound,
+			respError: "url not found",
+		},
+		{
+			name:      "Link without owner",
+			user:      storage.User{Username: "bob", Role: storage.RoleEditor},
+			status:    http.StatusNotFound,
+			respError: "url not found",
+		},
+		{
+			name:      "Not found",
+			user:      storage.User{Username: "root", Role: storage.RoleAdmin},
+			mockError: storage.ErrUrlNotFound,
+			status:    http.StatusNotFound,
+			respError: "url not found",
+		},
+		{
+			name:      "GetOwner Error",
+			user:      storage.User{Username: "alice", Role: storage.RoleEditor},
+			mockError: errors.New("unexpected error"),
+			status:    http.StatusInternalServerError,
+			respError: "internal error",
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			ownerGetterMock := mocks.NewOwnerGetter(t)
+			ownerGetterMock.On("GetOwner", mock.Anything, "test_alias").
+				Return(tc.owner, tc.mockError).
+				Once()
+
+			req := httptest.NewRequest(http.MethodGet, "/test_alias/stats", nil)
+			req = req.WithContext(auth.WithUser(req.Context(), tc.user))
+			rr := httptest.NewRecorder()
+
+			allowed := access.CanView(rr, req, slogdiscard.NewDiscardLogger(), ownerGetterMock, "test_alias")
+			require.Equal(t, tc.allowed, allowed)
+
+			if tc.allowed {
+				require.Zero(t, rr.Body.Len())
+				return
+			}
+
+			require.Equal(t, tc.status, rr.Code)
+
+			var res resp.Response
+			require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &res))
+			require.Equal(t, tc.respError, res.Error)
+		})
+	}
+}
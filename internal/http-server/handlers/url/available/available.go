@@ -0,0 +1,96 @@
+package available
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+	"url-shortener/internal/lib/alias"
+	resp "url-shortener/internal/lib/api/response"
+	"url-shortener/internal/lib/logger/sl"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/go-chi/render"
+)
+
+// ReasonTaken is reported when another link already holds the alias. Aliases
+// breaking a validation rule report the rule instead, e.g. "reserved".
+const ReasonTaken = "taken"
+
+type Response struct {
+	resp.Response
+	Alias     string `json:"alias"`
+	Available bool   `json:"available"`
+	Reason    string `json:"reason,omitempty"`
+	Message   string `json:"message,omitempty"`
+}
+
+//go:generate go run github.com/vektra/mockery/v2@latest --name=AliasChecker
+type AliasChecker interface {
+	AliasExists(ctx context.Context, alias string) (bool, error)
+}
+
+// New tells whether alias could be chosen for a new link right now, so UIs
+// can check it while the user types. An alias is free if it passes the
+// same rules as on save and no link holds it; deleted links keep their
+// alias until purged.
+func New(log *slog.Logger, aliases *alias.Validator, aliasChecker AliasChecker) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.url.available.New"
+
+		log := log.With(
+			slog.String("op", op),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+
+		a := chi.URLParam(r, "alias")
+		if a == "" {
+			log.Info("alias is empty")
+			render.Status(r, http.StatusBadRequest)
+			render.JSON(w, r, resp.Error("invalid request"))
+			return
+		}
+
+		if err := aliases.Validate(a); err != nil {
+			var validationErr *alias.ValidationError
+			if !errors.As(err, &validationErr) {
+				log.Error("failed to validate alias", sl.Err(err))
+				render.Status(r, http.StatusInternalServerError)
+				render.JSON(w, r, resp.Error("failed to check alias"))
+				return
+			}
+
+			render.JSON(w, r, Response{
+				Response: resp.OK(),
+				Alias:    a,
+				Reason:   validationErr.Rule,
+				Message:  validationErr.Message,
+			})
+			return
+		}
+
+		// With signed aliases the link would be saved under the signed one.
+		a = aliases.Sign(a)
+
+		exists, err := aliasChecker.AliasExists(r.Context(), a)
+		if err != nil {
+			log.Error("failed to check alias", sl.Err(err))
+			render.Status(r, http.StatusInternalServerError)
+			render.JSON(w, r, resp.Error("failed to check alias"))
+			return
+		}
+
+		res := Response{
+			Response:  resp.OK(),
+			Alias:     a,
+			Available: !exists,
+		}
+		if exists {
+			res.Reason = ReasonTaken
+			res.Message = "alias is already taken"
+		}
+
+		render.JSON(w, r, res)
+	}
+}
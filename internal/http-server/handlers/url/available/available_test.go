@@ -0,0 +1,101 @@
+package available_test
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"url-shortener/internal/http-server/handlers/url/available"
+	"url-shortener/internal/http-server/handlers/url/available/mocks"
+	"url-shortener/internal/lib/alias"
+	"url-shortener/internal/lib/logger/handlers/slogdiscard"
+)
+
+func TestAvailableHandler(t *testing.T) {
+	cases := []struct {
+		name      string
+		alias     string
+		exists    bool
+		mockError error
+		noCheck   bool
+		status    int
+		available bool
+		reason    string
+		respError string
+	}{
+		{
+			name:      "Free",
+			alias:     "my_alias",
+			status:    http.StatusOK,
+			available: true,
+		},
+		{
+			name:   "Taken",
+			alias:  "my_alias",
+			exists: true,
+			status: http.StatusOK,
+			reason: available.ReasonTaken,
+		},
+		{
+			name:    "Reserved",
+			alias:   "Admin",
+			noCheck: true,
+			status:  http.StatusOK,
+			reason:  alias.RuleReserved,
+		},
+		{
+			name:    "Too short",
+			alias:   "ab",
+			noCheck: true,
+			status:  http.StatusOK,
+			reason:  alias.RuleMinLength,
+		},
+		{
+			name:      "AliasExists Error",
+			alias:     "my_alias",
+			mockError: errors.New("unexpected error"),
+			status:    http.StatusInternalServerError,
+			respError: "failed to check alias",
+		},
+	}
+
+	aliases, err := alias.New(alias.Rules{MinLength: 3, Reserved: []string{"admin"}})
+	require.NoError(t, err)
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			aliasCheckerMock := mocks.NewAliasChecker(t)
+
+			if !tc.noCheck {
+				aliasCheckerMock.On("AliasExists", mock.Anything, tc.alias).
+					Return(tc.exists, tc.mockError).
+					Once()
+			}
+
+			r := chi.NewRouter()
+			r.Get("/alias/{alias}/available", available.New(slogdiscard.NewDiscardLogger(), aliases, aliasCheckerMock))
+
+			req := httptest.NewRequest(http.MethodGet, "/alias/"+tc.alias+"/available", nil)
+			rr := httptest.NewRecorder()
+			r.ServeHTTP(rr, req)
+
+			require.Equal(t, tc.status, rr.Code)
+
+			var body available.Response
+			require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &body))
+
+			require.Equal(t, tc.respError, body.Error)
+			require.Equal(t, tc.available, body.Available)
+			require.Equal(t, tc.reason, body.Reason)
+			if tc.respError == "" {
+				require.Equal(t, tc.alias, body.Alias)
+			}
+		})
+	}
+}
@@ -0,0 +1,216 @@
+package batch
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+	"url-shortener/internal/http-server/middleware/auth"
+	"url-shortener/internal/lib/alias"
+	resp "url-shortener/internal/lib/api/response"
+	"url-shortener/internal/lib/bulk"
+	"url-shortener/internal/lib/domain"
+	"url-shortener/internal/lib/logger/sl"
+	"url-shortener/internal/lib/quota"
+	"url-shortener/internal/storage"
+
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/go-chi/render"
+	"github.com/go-playground/validator/v10"
+)
+
+type Item struct {
+	URL   string `json:"url" validate:"required,url"`
+	Alias string `json:"alias,omitempty"`
+}
+
+// Result is the outcome for the item at the same index of the request.
+type Result struct {
+	URL   string `json:"url"`
+	Alias string `json:"alias,omitempty"`
+	Error string `json:"error,omitempty"`
+}
+
+type Response struct {
+	resp.Response
+	Results []Result `json:"results,omitempty"`
+	Saved   int      `json:"saved"`
+	Failed  int      `json:"failed"`
+	// Quota is the quota used up so far when the batch does not fit in it.
+	Quota *quota.Usage `json:"quota,omitempty"`
+}
+
+// MaxItems is the largest batch accepted in one request.
+const MaxItems = 1000
+
+//go:generate go run github.com/vektra/mockery/v2@latest --name=URLsSaver
+type URLsSaver interface {
+	SaveURLs(ctx context.Context, urls []storage.URL) ([]error, error)
+}
+
+// URLChecker reports a threat type for each unsafe URL, empty for the rest.
+type URLChecker interface {
+	Check(ctx context.Context, urls []string) ([]string, error)
+}
+
+// QuotaChecker tells whether a user or an API key may create n more links.
+type QuotaChecker interface {
+	Check(ctx context.Context, owner string, apiKeyID int64, n int) (quota.Usage, error)
+}
+
+// New creates links from a JSON array of {url, alias} objects in one storage
+// call. Invalid items and taken aliases fail individually and do not stop
+// the rest of the batch; the response lists a result for every item in
+// request order. Items flagged by checker fail like invalid ones; checker
+// may be nil, and if it fails, the items are saved anyway. With quotas set,
+// the valid items must fit in the quota as a whole, or none of them is
+// saved.
+func New(
+	log *slog.Logger,
+	urlsSaver URLsSaver,
+	aliases *alias.Validator,
+	domains *domain.Policy,
+	generator bulk.AliasGenerator,
+	checker URLChecker,
+	quotas QuotaChecker,
+) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.url.batch.New"
+
+		log := log.With(
+			slog.String("op", op),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+
+		var items []Item
+		if err := render.DecodeJSON(r.Body, &items); err != nil {
+			log.Error("failed to decode request body", sl.Err(err))
+			render.Status(r, http.StatusBadRequest)
+			render.JSON(w, r, resp.Error("failed to decode request"))
+			return
+		}
+
+		if len(items) == 0 || len(items) > MaxItems {
+			log.Info("invalid batch size", slog.Int("size", len(items)))
+			render.Status(r, http.StatusBadRequest)
+			render.JSON(w, r, resp.Error("batch must contain from 1 to 1000 items"))
+			return
+		}
+
+		var owner string
+		if user, ok := auth.UserFromContext(r.Context()); ok {
+			owner = user.Username
+		}
+		keyID, _ := auth.APIKeyIDFromContext(r.Context())
+
+		results := make([]Result, len(items))
+		// index maps urls back to the items they were made of.
+		urls := make([]storage.URL, 0, len(items))
+		index := make([]int, 0, len(items))
+
+		validate := validator.New()
+		for i, item := range items {
+			results[i] = Result{URL: item.URL, Alias: item.Alias}
+
+			if err := validate.Struct(item); err != nil {
+				results[i].Error = resp.ValidationError(err.(validator.ValidationErrors)).Error
+				continue
+			}
+			if item.Alias != "" {
+				if err := aliases.Validate(item.Alias); err != nil {
+					results[i].Error = err.Error()
+					continue
+				}
+			}
+			if err := domains.Check(item.URL, r.Host); err != nil {
+				results[i].Error = err.Error()
+				continue
+			}
+
+			urls = append(urls, storage.URL{URL: item.URL, Alias: aliases.Sign(item.Alias), Owner: owner, APIKeyID: keyID})
+			index = append(index, i)
+		}
+
+		if checker != nil && len(urls) > 0 {
+			targets := make([]string, len(urls))
+			for j, u := range urls {
+				targets[j] = u.URL
+			}
+
+			threats, err := checker.Check(r.Context(), targets)
+			if err != nil {
+				log.Error("failed to check url reputation", sl.Err(err))
+			} else {
+				kept := 0
+				for j, threat := range threats {
+					if threat != "" {
+						log.Warn("unsafe url rejected", slog.String("url", targets[j]), slog.String("threat", threat))
+						results[index[j]].Error = "url is flagged as unsafe: " + threat
+						continue
+					}
+					urls[kept], index[kept] = urls[j], index[j]
+					kept++
+				}
+				urls, index = urls[:kept], index[:kept]
+			}
+		}
+
+		if quotas != nil && len(urls) > 0 {
+			usage, err := quotas.Check(r.Context(), auth.OwnerFromContext(r.Context()), keyID, len(urls))
+			if errors.Is(err, quota.ErrExceeded) {
+				log.Info("quota exceeded", slog.Int("items", len(urls)), slog.Int64("links", usage.Links), slog.Int64("today", usage.Today))
+				render.Status(r, http.StatusForbidden)
+				render.JSON(w, r, Response{Response: resp.Error("quota exceeded"), Quota: &usage})
+				return
+			}
+			if err != nil {
+				log.Error("failed to check quota", sl.Err(err))
+				render.Status(r, http.StatusInternalServerError)
+				render.JSON(w, r, resp.Error("failed to save urls"))
+				return
+			}
+		}
+
+		errs, err := bulk.Save(r.Context(), urlsSaver, generator, urls)
+		if err != nil {
+			log.Error("failed to save urls", sl.Err(err))
+			render.Status(r, http.StatusInternalServerError)
+			render.JSON(w, r, resp.Error("failed to save urls"))
+			return
+		}
+
+		for j, i := range index {
+			results[i].Alias = urls[j].Alias
+			results[i].Error = errorMessage(log, errs[j])
+		}
+
+		res := Response{Response: resp.OK(), Results: results}
+		for _, result := range results {
+			if result.Error == "" {
+				res.Saved++
+			} else {
+				res.Failed++
+			}
+		}
+
+		log.Info("urls added", slog.Int("saved", res.Saved), slog.Int("failed", res.Failed))
+
+		render.JSON(w, r, res)
+	}
+}
+
+// errorMessage turns the outcome of saving a link into the text reported
+// to the client.
+func errorMessage(log *slog.Logger, err error) string {
+	switch {
+	case err == nil:
+		return ""
+	case errors.Is(err, storage.ErrUrlExists):
+		return "url already exists"
+	case errors.Is(err, bulk.ErrAliasGeneration):
+		return "failed to generate unique alias"
+	default:
+		log.Error("failed to save url", sl.Err(err))
+		return "failed to save url"
+	}
+}
@@ -0,0 +1,154 @@
+package batch_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"url-shortener/internal/http-server/handlers/url/batch"
+	"url-shortener/internal/http-server/handlers/url/batch/mocks"
+	"url-shortener/internal/http-server/middleware/auth"
+	"url-shortener/internal/lib/alias"
+	"url-shortener/internal/lib/domain"
+	"url-shortener/internal/lib/logger/handlers/slogdiscard"
+	"url-shortener/internal/lib/reputation"
+	"url-shortener/internal/storage"
+)
+
+func serve(t *testing.T, saver batch.URLsSaver, body string) (*httptest.ResponseRecorder, batch.Response) {
+	t.Helper()
+
+	aliases, err := alias.New(alias.Rules{MinLength: 3, Reserved: []string{"admin"}})
+	require.NoError(t, err)
+
+	domains, err := domain.New(domain.Rules{Block: []string{"evil.com"}})
+	require.NoError(t, err)
+
+	checker := reputation.NewBlocklist([]string{"malware.example"})
+
+	handler := batch.New(slogdiscard.NewDiscardLogger(), saver, aliases, domains, alias.DefaultRandom(), checker, nil)
+
+	req := httptest.NewRequest(http.MethodPost, "/url/batch", bytes.NewReader([]byte(body)))
+	req = req.WithContext(auth.WithUser(req.Context(), storage.User{Username: "alice", Role: storage.RoleUser}))
+	rr := httptest.NewRecorder()
+	handler.ServeHTTP(rr, req)
+
+	var res batch.Response
+	require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &res))
+
+	return rr, res
+}
+
+func TestBatchHandler_PerItemResults(t *testing.T) {
+	saverMock := mocks.NewURLsSaver(t)
+	saverMock.On("SaveURLs", mock.Anything, mock.MatchedBy(func(urls []storage.URL) bool {
+		return len(urls) == 3 &&
+			urls[0].Alias == "taken" && urls[1].Alias == "mine" && urls[2].Alias != "" &&
+			urls[0].Owner == "alice"
+	})).Return([]error{storage.ErrUrlExists, nil, nil}, nil).Once()
+
+	rr, res := serve(t, saverMock, `[
+		{"url": "https://example.com/1", "alias": "taken"},
+		{"url": "https://example.com/2", "alias": "mine"},
+		{"url": "https://example.com/3"},
+		{"url": "not a url"},
+		{"url": "https://example.com/5", "alias": "admin"},
+		{"url": "https://evil.com/6"}
+	]`)
+
+	require.Equal(t, http.StatusOK, rr.Code)
+	require.Equal(t, 2, res.Saved)
+	require.Equal(t, 4, res.Failed)
+	require.Len(t, res.Results, 6)
+	require.Equal(t, "url already exists", res.Results[0].Error)
+	require.Equal(t, "mine", res.Results[1].Alias)
+	require.Empty(t, res.Results[1].Error)
+	require.NotEmpty(t, res.Results[2].Alias)
+	require.Empty(t, res.Results[2].Error)
+	require.NotEmpty(t, res.Results[3].Error)
+	require.Equal(t, "field alias is reserved", res.Results[4].Error)
+	require.Equal(t, "domain not allowed: evil.com", res.Results[5].Error)
+}
+
+func TestBatchHandler_RetriesGeneratedAliases(t *testing.T) {
+	saverMock := mocks.NewURLsSaver(t)
+	saverMock.On("SaveURLs", mock.Anything, mock.MatchedBy(func(urls []storage.URL) bool { return len(urls) == 2 })).
+		Return([]error{nil, storage.ErrUrlExists}, nil).Once()
+	saverMock.On("SaveURLs", mock.Anything, mock.MatchedBy(func(urls []storage.URL) bool {
+		return len(urls) == 1 && urls[0].URL == "https://example.com/2"
+	})).Return([]error{nil}, nil).Once()
+
+	_, res := serve(t, saverMock, `[{"url": "https://example.com/1"}, {"url": "https://example.com/2"}]`)
+
+	require.Equal(t, 2, res.Saved)
+	require.Equal(t, 0, res.Failed)
+}
+
+func TestBatchHandler_UnsafeItems(t *testing.T) {
+	saverMock := mocks.NewURLsSaver(t)
+	saverMock.On("SaveURLs", mock.Anything, mock.MatchedBy(func(urls []storage.URL) bool {
+		return len(urls) == 1 && urls[0].URL == "https://example.com/2"
+	})).Return([]error{nil}, nil).Once()
+
+	_, res := serve(t, saverMock, `[
+		{"url": "https://malware.example/1", "alias": "bad"},
+		{"url": "https://example.com/2", "alias": "good"},
+		{"url": "https://cdn.malware.example/3"}
+	]`)
+
+	require.Equal(t, 1, res.Saved)
+	require.Equal(t, 2, res.Failed)
+	require.Equal(t, "url is flagged as unsafe: BLOCKLISTED", res.Results[0].Error)
+	require.Empty(t, res.Results[1].Error)
+	require.Equal(t, "url is flagged as unsafe: BLOCKLISTED", res.Results[2].Error)
+}
+
+func TestBatchHandler_Errors(t *testing.T) {
+	cases := []struct {
+		name      string
+		body      string
+		status    int
+		respError string
+		mockError error
+	}{
+		{
+			name:      "Not An Array",
+			body:      `{"url": "https://example.com"}`,
+			status:    http.StatusBadRequest,
+			respError: "failed to decode request",
+		},
+		{
+			name:      "Empty",
+			body:      `[]`,
+			status:    http.StatusBadRequest,
+			respError: "batch must contain from 1 to 1000 items",
+		},
+		{
+			name:      "Storage Error",
+			body:      `[{"url": "https://example.com", "alias": "abc"}]`,
+			status:    http.StatusInternalServerError,
+			respError: "failed to save urls",
+			mockError: errors.New("unexpected error"),
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			saverMock := mocks.NewURLsSaver(t)
+			if tc.mockError != nil {
+				saverMock.On("SaveURLs", mock.Anything, mock.Anything).Return(nil, tc.mockError).Once()
+			}
+
+			rr, res := serve(t, saverMock, tc.body)
+
+			require.Equal(t, tc.status, rr.Code)
+			require.Equal(t, tc.respError, res.Error)
+		})
+	}
+}
@@ -0,0 +1,82 @@
+package block
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+	resp "url-shortener/internal/lib/api/response"
+	"url-shortener/internal/lib/logger/sl"
+	"url-shortener/internal/storage"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/go-chi/render"
+	"github.com/go-playground/validator/v10"
+)
+
+type Request struct {
+	Reason    string `json:"reason" validate:"required"`
+	Reference string `json:"reference,omitempty"`
+}
+
+//go:generate go run github.com/vektra/mockery/v2@latest --name=URLBlocker
+type URLBlocker interface {
+	BlockURL(ctx context.Context, alias string, reason string, reference string) error
+}
+
+// New marks an alias as blocked for legal reasons (e.g. after a takedown
+// notice). Blocked aliases answer with 451 instead of redirecting.
+func New(log *slog.Logger, urlBlocker URLBlocker) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.url.block.New"
+
+		log := log.With(
+			slog.String("op", op),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+
+		alias := chi.URLParam(r, "alias")
+		if alias == "" {
+			log.Info("alias is empty")
+			render.JSON(w, r, resp.Error("invalid request"))
+			return
+		}
+
+		var req Request
+		if err := render.DecodeJSON(r.Body, &req); err != nil {
+			log.Error("failed to decode request body", sl.Err(err))
+			render.JSON(w, r, resp.Error("failed to decode request"))
+			return
+		}
+
+		if err := validator.New().Struct(req); err != nil {
+			validateErr := err.(validator.ValidationErrors)
+
+			log.Error("invalide request", sl.Err(err))
+			render.JSON(w, r, resp.ValidationError(validateErr))
+			return
+		}
+
+		err := urlBlocker.BlockURL(r.Context(), alias, req.Reason, req.Reference)
+		if errors.Is(err, storage.ErrUrlNotFound) {
+			log.Info("url not found", slog.String("alias", alias))
+			render.Status(r, http.StatusNotFound)
+			render.JSON(w, r, resp.Error("url not found"))
+			return
+		}
+		if err != nil {
+			log.Error("failed to block url", sl.Err(err))
+			render.Status(r, http.StatusInternalServerError)
+			render.JSON(w, r, resp.Error("failed to block url"))
+			return
+		}
+
+		log.Info("url blocked for legal reasons",
+			slog.String("alias", alias),
+			slog.String("reference", req.Reference),
+		)
+
+		render.JSON(w, r, resp.OK())
+	}
+}
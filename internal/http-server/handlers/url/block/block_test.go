@@ -0,0 +1,94 @@
+package block_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"url-shortener/internal/http-server/handlers/url/block"
+	"url-shortener/internal/http-server/handlers/url/block/mocks"
+	resp "url-shortener/internal/lib/api/response"
+	"url-shortener/internal/lib/logger/handlers/slogdiscard"
+	"url-shortener/internal/storage"
+)
+
+func TestBlockHandler(t *testing.T) {
+	cases := []struct {
+		name      string
+		alias     string
+		body      string
+		status    int
+		respError string
+		mockError error
+		callMock  bool
+	}{
+		{
+			name:     "Success",
+			alias:    "test_alias",
+			body:     `{"reason": "DMCA takedown", "reference": "case-42"}`,
+			status:   http.StatusOK,
+			callMock: true,
+		},
+		{
+			name:      "Missing reason",
+			alias:     "test_alias",
+			body:      `{"reference": "case-42"}`,
+			status:    http.StatusOK,
+			respError: "field Reason is a required field",
+		},
+		{
+			name:      "Not found",
+			alias:     "missing",
+			body:      `{"reason": "DMCA takedown"}`,
+			status:    http.StatusNotFound,
+			respError: "url not found",
+			mockError: storage.ErrUrlNotFound,
+			callMock:  true,
+		},
+		{
+			name:      "BlockURL Error",
+			alias:     "test_alias",
+			body:      `{"reason": "DMCA takedown"}`,
+			status:    http.StatusInternalServerError,
+			respError: "failed to block url",
+			mockError: errors.New("unexpected error"),
+			callMock:  true,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			urlBlockerMock := mocks.NewURLBlocker(t)
+
+			if tc.callMock {
+				var req block.Request
+				require.NoError(t, json.Unmarshal([]byte(tc.body), &req))
+
+				urlBlockerMock.On("BlockURL", mock.Anything, tc.alias, req.Reason, req.Reference).
+					Return(tc.mockError).
+					Once()
+			}
+
+			r := chi.NewRouter()
+			r.Post("/{alias}/block", block.New(slogdiscard.NewDiscardLogger(), urlBlockerMock))
+
+			req := httptest.NewRequest(http.MethodPost, "/"+tc.alias+"/block", bytes.NewReader([]byte(tc.body)))
+			rr := httptest.NewRecorder()
+			r.ServeHTTP(rr, req)
+
+			require.Equal(t, tc.status, rr.Code)
+
+			var body resp.Response
+			require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &body))
+
+			require.Equal(t, tc.respError, body.Error)
+		})
+	}
+}
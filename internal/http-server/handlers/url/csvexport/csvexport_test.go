@@ -0,0 +1,69 @@
+package csvexport_test
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"url-shortener/internal/http-server/handlers/url/csvexport"
+	"url-shortener/internal/http-server/handlers/url/csvexport/mocks"
+	"url-shortener/internal/http-server/middleware/auth"
+	"url-shortener/internal/lib/logger/handlers/slogdiscard"
+	"url-shortener/internal/storage"
+)
+
+func TestExportHandler(t *testing.T) {
+	createdAt := time.Date(2025, 3, 1, 10, 0, 0, 0, time.UTC)
+
+	// Two pages: a full one and the rest.
+	firstPage := make([]storage.URL, 500)
+	for i := range firstPage {
+		firstPage[i] = storage.URL{Alias: fmt.Sprintf("a%d", i), URL: "https://example.com", Owner: "alice", CreatedAt: createdAt}
+	}
+	secondPage := []storage.URL{
+		{Alias: "last", URL: "https://example.org", Owner: "alice", CreatedAt: createdAt, ExpiresAt: createdAt.Add(time.Hour)},
+	}
+
+	listerMock := mocks.NewURLLister(t)
+	listerMock.On("ListURLs", mock.Anything, storage.ListFilter{Owner: "alice"}, 500, 0, false).Return(firstPage, nil).Once()
+	listerMock.On("ListURLs", mock.Anything, storage.ListFilter{Owner: "alice"}, 500, 500, false).Return(secondPage, nil).Once()
+
+	handler := csvexport.New(slogdiscard.NewDiscardLogger(), listerMock)
+
+	req := httptest.NewRequest(http.MethodGet, "/urls/export", nil)
+	req = req.WithContext(auth.WithUser(req.Context(), storage.User{Username: "alice", Role: storage.RoleUser}))
+	rr := httptest.NewRecorder()
+	handler.ServeHTTP(rr, req)
+
+	require.Equal(t, http.StatusOK, rr.Code)
+	require.Equal(t, "text/csv; charset=utf-8", rr.Header().Get("Content-Type"))
+
+	records, err := csv.NewReader(rr.Body).ReadAll()
+	require.NoError(t, err)
+	require.Len(t, records, 502)
+	require.Equal(t, csvexport.Header, records[0])
+	require.Equal(t, []string{"a0", "https://example.com", "alice", "2025-03-01T10:00:00Z", ""}, records[1])
+	require.Equal(t, []string{"last", "https://example.org", "alice", "2025-03-01T10:00:00Z", "2025-03-01T11:00:00Z"}, records[501])
+}
+
+func TestExportHandler_StorageError(t *testing.T) {
+	listerMock := mocks.NewURLLister(t)
+	listerMock.On("ListURLs", mock.Anything, storage.ListFilter{}, 500, 0, false).Return(nil, errors.New("unexpected error")).Once()
+
+	handler := csvexport.New(slogdiscard.NewDiscardLogger(), listerMock)
+
+	req := httptest.NewRequest(http.MethodGet, "/urls/export", nil)
+	req = req.WithContext(auth.WithUser(req.Context(), storage.User{Username: "root", Role: storage.RoleAdmin}))
+	rr := httptest.NewRecorder()
+	handler.ServeHTTP(rr, req)
+
+	require.Equal(t, http.StatusInternalServerError, rr.Code)
+	require.Contains(t, rr.Body.String(), "failed to export urls")
+}
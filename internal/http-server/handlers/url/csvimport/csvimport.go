@@ -0,0 +1,351 @@
+package csvimport
+
+import (
+	"context"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+	"url-shortener/internal/http-server/middleware/auth"
+	"url-shortener/internal/lib/alias"
+	resp "url-shortener/internal/lib/api/response"
+	"url-shortener/internal/lib/bulk"
+	"url-shortener/internal/lib/domain"
+	"url-shortener/internal/lib/logger/sl"
+	"url-shortener/internal/lib/quota"
+	"url-shortener/internal/storage"
+
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/go-chi/render"
+	"github.com/go-playground/validator/v10"
+)
+
+// Result is the outcome for one data row of the file; Line is its line
+// number, the header being line 1.
+type Result struct {
+	Line  int    `json:"line"`
+	URL   string `json:"url"`
+	Alias string `json:"alias,omitempty"`
+	Error string `json:"error,omitempty"`
+}
+
+type Response struct {
+	resp.Response
+	Results []Result `json:"results,omitempty"`
+	Saved   int      `json:"saved"`
+	Failed  int      `json:"failed"`
+	// Quota is the quota used up so far when the file does not fit in it.
+	Quota *quota.Usage `json:"quota,omitempty"`
+}
+
+const (
+	// MaxFileSize limits the uploaded request body.
+	MaxFileSize = 10 << 20
+	// MaxRows limits the number of data rows in one file.
+	MaxRows = 10000
+	// chunkSize is how many links are saved in one storage call.
+	chunkSize = 1000
+)
+
+//go:generate go run github.com/vektra/mockery/v2@latest --name=URLsSaver
+type URLsSaver interface {
+	SaveURLs(ctx context.Context, urls []storage.URL) ([]error, error)
+}
+
+// URLChecker reports a threat type for each unsafe URL, empty for the rest.
+type URLChecker interface {
+	Check(ctx context.Context, urls []string) ([]string, error)
+}
+
+// QuotaChecker tells whether a user or an API key may create n more links.
+type QuotaChecker interface {
+	Check(ctx context.Context, owner string, apiKeyID int64, n int) (quota.Usage, error)
+}
+
+// New imports links from a CSV file uploaded as the "file" field of a
+// multipart form. The first row names the columns: "url" is required,
+// "alias", "expires_at" (RFC 3339) and "clicks", the clicks the link had in
+// another shortener, are optional, and "owner" is honoured for admins
+// only; other columns, such as those of csvexport, are ignored.
+// Bad rows are reported individually and do not stop the import; so are
+// rows flagged by checker, which may be nil and, if it fails, lets every
+// row through. With quotas set, the valid rows must fit in the quota as a
+// whole, or none of them is imported.
+func New(
+	log *slog.Logger,
+	urlsSaver URLsSaver,
+	aliases *alias.Validator,
+	domains *domain.Policy,
+	generator bulk.AliasGenerator,
+	checker URLChecker,
+	quotas QuotaChecker,
+) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.url.csvimport.New"
+
+		log := log.With(
+			slog.String("op", op),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+
+		r.Body = http.MaxBytesReader(w, r.Body, MaxFileSize)
+
+		file, _, err := r.FormFile("file")
+		if err != nil {
+			log.Info("failed to read uploaded file", sl.Err(err))
+			render.Status(r, http.StatusBadRequest)
+			render.JSON(w, r, resp.Error("field file with a csv file is required"))
+			return
+		}
+		defer file.Close()
+
+		user, _ := auth.UserFromContext(r.Context())
+		keyID, _ := auth.APIKeyIDFromContext(r.Context())
+
+		rows, err := parse(file, user, keyID, aliases, domains, r.Host, time.Now())
+		if err != nil {
+			log.Info("invalid csv", sl.Err(err))
+			render.Status(r, http.StatusBadRequest)
+			render.JSON(w, r, resp.Error(err.Error()))
+			return
+		}
+
+		results := make([]Result, len(rows))
+		// valid holds the indexes of rows that can be saved.
+		valid := make([]int, 0, len(rows))
+		for i, row := range rows {
+			results[i] = Result{Line: row.line, URL: row.url.URL, Alias: row.url.Alias, Error: row.err}
+			if row.err == "" {
+				valid = append(valid, i)
+			}
+		}
+
+		if checker != nil && len(valid) > 0 {
+			targets := make([]string, len(valid))
+			for j, i := range valid {
+				targets[j] = rows[i].url.URL
+			}
+
+			threats, err := checker.Check(r.Context(), targets)
+			if err != nil {
+				log.Error("failed to check url reputation", sl.Err(err))
+			} else {
+				kept := valid[:0]
+				for j, threat := range threats {
+					if threat != "" {
+						log.Warn("unsafe url rejected", slog.String("url", targets[j]), slog.String("threat", threat))
+						results[valid[j]].Error = "url is flagged as unsafe: " + threat
+						continue
+					}
+					kept = append(kept, valid[j])
+				}
+				valid = kept
+			}
+		}
+
+		if quotas != nil && len(valid) > 0 {
+			usage, err := quotas.Check(r.Context(), auth.OwnerFromContext(r.Context()), keyID, len(valid))
+			if errors.Is(err, quota.ErrExceeded) {
+				log.Info("quota exceeded", slog.Int("rows", len(valid)), slog.Int64("links", usage.Links), slog.Int64("today", usage.Today))
+				render.Status(r, http.StatusForbidden)
+				render.JSON(w, r, Response{Response: resp.Error("quota exceeded"), Quota: &usage})
+				return
+			}
+			if err != nil {
+				log.Error("failed to check quota", sl.Err(err))
+				render.Status(r, http.StatusInternalServerError)
+				render.JSON(w, r, resp.Error("failed to import urls"))
+				return
+			}
+		}
+
+		for start := 0; start < len(valid); start += chunkSize {
+			chunk := valid[start:min(start+chunkSize, len(valid))]
+
+			urls := make([]storage.URL, len(chunk))
+			for j, i := range chunk {
+				urls[j] = rows[i].url
+			}
+
+			errs, err := bulk.Save(r.Context(), urlsSaver, generator, urls)
+			if err != nil {
+				// Earlier chunks are already saved; report them and mark the
+				// rest as failed instead of pretending nothing happened.
+				log.Error("failed to save urls", sl.Err(err))
+				for _, i := range valid[start:] {
+					results[i].Error = "failed to save url"
+				}
+				break
+			}
+
+			for j, i := range chunk {
+				results[i].Alias = urls[j].Alias
+				results[i].Error = errorMessage(log, errs[j])
+			}
+		}
+
+		res := Response{Response: resp.OK(), Results: results}
+		for _, result := range results {
+			if result.Error == "" {
+				res.Saved++
+			} else {
+				res.Failed++
+			}
+		}
+
+		log.Info("urls imported", slog.Int("saved", res.Saved), slog.Int("failed", res.Failed))
+
+		render.JSON(w, r, res)
+	}
+}
+
+type row struct {
+	line int
+	url  storage.URL
+	// err is the reason the row cannot be imported.
+	err string
+}
+
+// parse reads the whole file. Rows with bad values are returned with err
+// set; a malformed file fails as a whole.
+func parse(file io.Reader, user storage.User, keyID int64, aliases *alias.Validator, domains *domain.Policy, host string, now time.Time) ([]row, error) {
+	cr := csv.NewReader(file)
+	cr.TrimLeadingSpace = true
+
+	header, err := cr.Read()
+	if errors.Is(err, io.EOF) {
+		return nil, errors.New("file is empty")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("invalid csv: %w", err)
+	}
+
+	columns := make(map[string]int, len(header))
+	for i, name := range header {
+		columns[strings.ToLower(strings.TrimSpace(name))] = i
+	}
+	if _, ok := columns["url"]; !ok {
+		return nil, errors.New("column url is required")
+	}
+
+	field := func(record []string, name string) string {
+		i, ok := columns[name]
+		if !ok || i >= len(record) {
+			return ""
+		}
+		return strings.TrimSpace(record[i])
+	}
+
+	validate := validator.New()
+
+	var rows []row
+	for {
+		record, err := cr.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+
+		line, _ := cr.FieldPos(0)
+		r := row{line: line}
+
+		var parseErr *csv.ParseError
+		switch {
+		case errors.As(err, &parseErr) && errors.Is(parseErr.Err, csv.ErrFieldCount):
+			r.err = "wrong number of fields"
+		case err != nil:
+			return nil, fmt.Errorf("invalid csv: %w", err)
+		}
+
+		if len(rows) == MaxRows {
+			return nil, fmt.Errorf("file must contain at most %d rows", MaxRows)
+		}
+
+		r.url = storage.URL{
+			URL:      field(record, "url"),
+			Alias:    field(record, "alias"),
+			Owner:    user.Username,
+			APIKeyID: keyID,
+		}
+		if owner := field(record, "owner"); owner != "" && user.IsAdmin() {
+			r.url.Owner = owner
+		}
+
+		if r.err == "" {
+			r.err = check(validate, aliases, domains, host, &r.url, field(record, "expires_at"), now)
+		}
+		if r.err == "" {
+			r.url.ImportedClicks, r.err = parseClicks(field(record, "clicks"))
+		}
+		if r.err == "" {
+			r.url.Alias = aliases.Sign(r.url.Alias)
+		}
+
+		rows = append(rows, r)
+	}
+
+	return rows, nil
+}
+
+// check validates the url and alias of a row and sets its expiration; host
+// is the one the file was uploaded to.
+func check(validate *validator.Validate, aliases *alias.Validator, domains *domain.Policy, host string, u *storage.URL, expiresAt string, now time.Time) string {
+	if err := validate.Var(u.URL, "required,url"); err != nil {
+		return "field url is not valid"
+	}
+	if err := domains.Check(u.URL, host); err != nil {
+		return err.Error()
+	}
+	if u.Alias != "" {
+		if err := aliases.Validate(u.Alias); err != nil {
+			return err.Error()
+		}
+	}
+
+	if expiresAt == "" {
+		return ""
+	}
+
+	t, err := time.Parse(time.RFC3339, expiresAt)
+	if err != nil {
+		return "field expires_at must be an RFC 3339 time"
+	}
+	if !t.After(now) {
+		return "link has expired"
+	}
+	u.ExpiresAt = t.UTC()
+
+	return ""
+}
+
+// parseClicks reads the clicks column, which may be empty.
+func parseClicks(v string) (int64, string) {
+	if v == "" {
+		return 0, ""
+	}
+
+	n, err := strconv.ParseInt(v, 10, 64)
+	if err != nil || n < 0 {
+		return 0, "field clicks must be a non-negative integer"
+	}
+
+	return n, ""
+}
+
+func errorMessage(log *slog.Logger, err error) string {
+	switch {
+	case err == nil:
+		return ""
+	case errors.Is(err, storage.ErrUrlExists):
+		return "url already exists"
+	case errors.Is(err, bulk.ErrAliasGeneration):
+		return "failed to generate unique alias"
+	default:
+		log.Error("failed to save url", sl.Err(err))
+		return "failed to save url"
+	}
+}
@@ -0,0 +1,158 @@
+package csvimport_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"url-shortener/internal/http-server/handlers/url/csvimport"
+	"url-shortener/internal/http-server/handlers/url/csvimport/mocks"
+	"url-shortener/internal/http-server/middleware/auth"
+	"url-shortener/internal/lib/alias"
+	"url-shortener/internal/lib/domain"
+	"url-shortener/internal/lib/logger/handlers/slogdiscard"
+	"url-shortener/internal/lib/reputation"
+	"url-shortener/internal/storage"
+)
+
+func upload(t *testing.T, saver csvimport.URLsSaver, user storage.User, content string) (*httptest.ResponseRecorder, csvimport.Response) {
+	t.Helper()
+
+	var body bytes.Buffer
+	mw := multipart.NewWriter(&body)
+	fw, err := mw.CreateFormFile("file", "urls.csv")
+	require.NoError(t, err)
+	_, err = fw.Write([]byte(content))
+	require.NoError(t, err)
+	require.NoError(t, mw.Close())
+
+	req := httptest.NewRequest(http.MethodPost, "/urls/import", &body)
+	req.Header.Set("Content-Type", mw.FormDataContentType())
+	req = req.WithContext(auth.WithUser(req.Context(), user))
+	rr := httptest.NewRecorder()
+	aliases, err := alias.New(alias.Rules{Reserved: []string{"admin"}})
+	require.NoError(t, err)
+	domains, err := domain.New(domain.Rules{})
+	require.NoError(t, err)
+	checker := reputation.NewBlocklist([]string{"malware.example"})
+	csvimport.New(slogdiscard.NewDiscardLogger(), saver, aliases, domains, alias.DefaultRandom(), checker, nil).ServeHTTP(rr, req)
+
+	var res csvimport.Response
+	require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &res))
+
+	return rr, res
+}
+
+var alice = storage.User{Username: "alice", Role: storage.RoleUser}
+
+func TestImportHandler(t *testing.T) {
+	saverMock := mocks.NewURLsSaver(t)
+	saverMock.On("SaveURLs", mock.Anything, mock.MatchedBy(func(urls []storage.URL) bool {
+		return len(urls) == 3 &&
+			urls[0].Alias == "one" && urls[0].Owner == "alice" &&
+			urls[1].Alias == "taken" &&
+			urls[2].Alias != "" && !urls[2].ExpiresAt.IsZero()
+	})).Return([]error{nil, storage.ErrUrlExists, nil}, nil).Once()
+
+	rr, res := upload(t, saverMock, alice, `alias,url,owner,created_at,expires_at
+one,https://example.com/1,bob,2025-03-01T10:00:00Z,
+taken,https://example.com/2,,,
+,https://example.com/3,,,2099-01-01T00:00:00Z
+bad,not a url,,,
+old,https://example.com/5,,,2000-01-01T00:00:00Z
+short,https://example.com/6
+admin,https://example.com/7,,,
+`)
+
+	require.Equal(t, http.StatusOK, rr.Code)
+	require.Equal(t, 2, res.Saved)
+	require.Equal(t, 5, res.Failed)
+	require.Len(t, res.Results, 7)
+
+	require.Equal(t, 2, res.Results[0].Line)
+	require.Empty(t, res.Results[0].Error)
+	require.Equal(t, "url already exists", res.Results[1].Error)
+	require.NotEmpty(t, res.Results[2].Alias)
+	require.Equal(t, "field url is not valid", res.Results[3].Error)
+	require.Equal(t, "link has expired", res.Results[4].Error)
+	require.Equal(t, 7, res.Results[5].Line)
+	require.Equal(t, "wrong number of fields", res.Results[5].Error)
+	require.Equal(t, "field alias is reserved", res.Results[6].Error)
+}
+
+func TestImportHandler_AdminKeepsOwner(t *testing.T) {
+	saverMock := mocks.NewURLsSaver(t)
+	saverMock.On("SaveURLs", mock.Anything, mock.MatchedBy(func(urls []storage.URL) bool {
+		return len(urls) == 2 && urls[0].Owner == "bob" && urls[1].Owner == "root"
+	})).Return([]error{nil, nil}, nil).Once()
+
+	_, res := upload(t, saverMock, storage.User{Username: "root", Role: storage.RoleAdmin}, "url,owner\nhttps://example.com/1,bob\nhttps://example.com/2,\n")
+
+	require.Equal(t, 2, res.Saved)
+}
+
+func TestImportHandler_Clicks(t *testing.T) {
+	saverMock := mocks.NewURLsSaver(t)
+	saverMock.On("SaveURLs", mock.Anything, mock.MatchedBy(func(urls []storage.URL) bool {
+		return len(urls) == 3 &&
+			urls[0].Alias == "old" && urls[0].ImportedClicks == 1234 &&
+			urls[1].Alias == "taken" && urls[1].ImportedClicks == 5 &&
+			urls[2].Alias == "new" && urls[2].ImportedClicks == 0
+	})).Return([]error{nil, storage.ErrUrlExists, nil}, nil).Once()
+
+	_, res := upload(t, saverMock, alice, `alias,url,clicks
+old,https://example.com/1,1234
+taken,https://example.com/2,5
+new,https://example.com/3,
+negative,https://example.com/4,-1
+text,https://example.com/5,many
+`)
+
+	require.Equal(t, 2, res.Saved)
+	require.Equal(t, 3, res.Failed)
+	require.Empty(t, res.Results[0].Error)
+	require.Equal(t, "url already exists", res.Results[1].Error)
+	require.Empty(t, res.Results[2].Error)
+	require.Equal(t, "field clicks must be a non-negative integer", res.Results[3].Error)
+	require.Equal(t, "field clicks must be a non-negative integer", res.Results[4].Error)
+}
+
+func TestImportHandler_UnsafeRows(t *testing.T) {
+	saverMock := mocks.NewURLsSaver(t)
+	saverMock.On("SaveURLs", mock.Anything, mock.MatchedBy(func(urls []storage.URL) bool {
+		return len(urls) == 1 && urls[0].URL == "https://example.com/2"
+	})).Return([]error{nil}, nil).Once()
+
+	_, res := upload(t, saverMock, alice, "url\nhttps://malware.example/1\nhttps://example.com/2\n")
+
+	require.Equal(t, 1, res.Saved)
+	require.Equal(t, 1, res.Failed)
+	require.Equal(t, "url is flagged as unsafe: BLOCKLISTED", res.Results[0].Error)
+	require.Empty(t, res.Results[1].Error)
+}
+
+func TestImportHandler_InvalidFile(t *testing.T) {
+	cases := []struct {
+		name      string
+		content   string
+		respError string
+	}{
+		{name: "Empty", content: "", respError: "file is empty"},
+		{name: "No URL Column", content: "alias,target\na,https://example.com\n", respError: "column url is required"},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			rr, res := upload(t, mocks.NewURLsSaver(t), alice, tc.content)
+
+			require.Equal(t, http.StatusBadRequest, rr.Code)
+			require.Equal(t, tc.respError, res.Error)
+		})
+	}
+}
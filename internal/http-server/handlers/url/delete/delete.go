@@ -0,0 +1,60 @@
+package delete
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+	"url-shortener/internal/http-server/middleware/auth"
+	resp "url-shortener/internal/lib/api/response"
+	"url-shortener/internal/lib/logger/sl"
+	"url-shortener/internal/storage"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/go-chi/render"
+)
+
+//go:generate go run github.com/vektra/mockery/v2@latest --name=URLDeleter
+type URLDeleter interface {
+	DeleteURL(ctx context.Context, alias string, owner string) error
+}
+
+// New deletes an alias. Users can only delete their own links, admins can
+// delete any.
+func New(log *slog.Logger, urlDeleter URLDeleter) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.url.delete.New"
+
+		log := log.With(
+			slog.String("op", op),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+
+		alias := chi.URLParam(r, "alias")
+		if alias == "" {
+			log.Info("alias is empty")
+			render.Status(r, http.StatusBadRequest)
+			render.JSON(w, r, resp.Error("invalid request"))
+			return
+		}
+
+		err := urlDeleter.DeleteURL(r.Context(), alias, auth.OwnerFromContext(r.Context()))
+		if errors.Is(err, storage.ErrUrlNotFound) {
+			log.Info("url not found", slog.String("alias", alias))
+			render.Status(r, http.StatusNotFound)
+			render.JSON(w, r, resp.Error("url not found"))
+			return
+		}
+		if err != nil {
+			log.Error("failed to delete url", sl.Err(err))
+			render.Status(r, http.StatusInternalServerError)
+			render.JSON(w, r, resp.Error("failed to delete url"))
+			return
+		}
+
+		log.Info("url deleted", slog.String("alias", alias))
+
+		render.JSON(w, r, resp.OK())
+	}
+}
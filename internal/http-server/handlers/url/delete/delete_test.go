@@ -0,0 +1,75 @@
+package delete_test
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"url-shortener/internal/http-server/handlers/url/delete"
+	"url-shortener/internal/http-server/handlers/url/delete/mocks"
+	"url-shortener/internal/http-server/middleware/auth"
+	resp "url-shortener/internal/lib/api/response"
+	"url-shortener/internal/lib/logger/handlers/slogdiscard"
+	"url-shortener/internal/storage"
+)
+
+func TestDeleteHandler(t *testing.T) {
+	cases := []struct {
+		name      string
+		alias     string
+		status    int
+		respError string
+		mockError error
+	}{
+		{
+			name:   "Success",
+			alias:  "test_alias",
+			status: http.StatusOK,
+		},
+		{
+			name:      "Not found",
+			alias:     "missing",
+			status:    http.StatusNotFound,
+			respError: "url not found",
+			mockError: storage.ErrUrlNotFound,
+		},
+		{
+			name:      "DeleteURL Error",
+			alias:     "test_alias",
+			status:    http.StatusInternalServerError,
+			respError: "failed to delete url",
+			mockError: errors.New("unexpected error"),
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			urlDeleterMock := mocks.NewURLDeleter(t)
+
+			urlDeleterMock.On("DeleteURL", mock.Anything, tc.alias, "alice").
+				Return(tc.mockError).
+				Once()
+
+			r := chi.NewRouter()
+			r.Delete("/{alias}", delete.New(slogdiscard.NewDiscardLogger(), urlDeleterMock))
+
+			req := httptest.NewRequest(http.MethodDelete, "/"+tc.alias, nil)
+			req = req.WithContext(auth.WithUser(req.Context(), storage.User{Username: "alice", Role: storage.RoleUser}))
+			rr := httptest.NewRecorder()
+			r.ServeHTTP(rr, req)
+
+			require.Equal(t, tc.status, rr.Code)
+
+			var body resp.Response
+			require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &body))
+
+			require.Equal(t, tc.respError, body.Error)
+		})
+	}
+}
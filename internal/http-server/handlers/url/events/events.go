@@ -0,0 +1,176 @@
+package events
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"time"
+	"url-shortener/internal/http-server/handlers/url/access"
+	resp "url-shortener/internal/lib/api/response"
+	"url-shortener/internal/lib/logger/sl"
+	"url-shortener/internal/storage"
+	"url-shortener/internal/webhook"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/go-chi/render"
+)
+
+// Click is the data of a "click" event.
+type Click struct {
+	Alias     string    `json:"alias"`
+	ClickedAt time.Time `json:"clicked_at"`
+	Referrer  string    `json:"referrer,omitempty"`
+	UserAgent string    `json:"user_agent,omitempty"`
+	Country   string    `json:"country,omitempty"`
+	Bot       bool      `json:"bot,omitempty"`
+}
+
+// keepAlive is how often a comment is sent on a quiet stream, so proxies
+// do not close it as idle.
+const keepAlive = 15 * time.Second
+
+//go:generate go run github.com/vektra/mockery/v2@latest --name=Subscriber
+type Subscriber interface {
+	Subscribe(alias string) (clicks <-chan webhook.Event, cancel func())
+}
+
+//go:generate go run github.com/vektra/mockery/v2@latest --name=URLGetter
+type URLGetter interface {
+	GetURL(ctx context.Context, alias string) (storage.URL, error)
+}
+
+// New streams the clicks on {alias} as Server-Sent Events while the client
+// stays connected. Without an alias route parameter it streams the clicks
+// on every link, so that route must be admin only. Users other than admins
+// and viewers can follow their own links only. Clicks of bots are sent
+// only with include_bots=true.
+func New(log *slog.Logger, subscriber Subscriber, urlGetter URLGetter, ownerGetter access.OwnerGetter) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.url.events.New"
+
+		log := log.With(
+			slog.String("op", op),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+
+		includeBots := false
+		if v := r.URL.Query().Get("include_bots"); v != "" {
+			b, err := strconv.ParseBool(v)
+			if err != nil {
+				log.Info("invalid include_bots", slog.String("include_bots", v))
+				render.Status(r, http.StatusBadRequest)
+				render.JSON(w, r, resp.Error("invalid include_bots"))
+				return
+			}
+			includeBots = b
+		}
+
+		alias := chi.URLParam(r, "alias")
+		if alias != "" {
+			u, err := urlGetter.GetURL(r.Context(), alias)
+			if errors.Is(err, storage.ErrUrlNotFound) {
+				log.Info("url not found", slog.String("alias", alias))
+				render.Status(r, http.StatusNotFound)
+				render.JSON(w, r, resp.Error("url not found"))
+				return
+			}
+			// Links that cannot be followed right now still exist and may
+			// come back, by a new expiry or click limit.
+			if err != nil && !inactive(err) {
+				log.Error("failed to get url", sl.Err(err))
+				render.Status(r, http.StatusInternalServerError)
+				render.JSON(w, r, resp.Error("internal error"))
+				return
+			}
+			if !access.CanView(w, r, log, ownerGetter, alias) {
+				return
+			}
+			if err == nil {
+				// The stored alias, in case aliases are case-insensitive.
+				alias = u.Alias
+			}
+		}
+
+		// Subscribing before the headers are sent means a client sees every
+		// click made after its request returns.
+		clicks, cancel := subscriber.Subscribe(alias)
+		defer cancel()
+
+		rc := http.NewResponseController(w)
+		// The server write timeout would cut the stream off.
+		if err := rc.SetWriteDeadline(time.Time{}); err != nil && !errors.Is(err, http.ErrNotSupported) {
+			log.Error("failed to clear write deadline", sl.Err(err))
+		}
+
+		w.Header().Set("Content-Type", "text/event-stream")
+		w.Header().Set("Cache-Control", "no-cache")
+		// Keeps nginx from buffering the stream.
+		w.Header().Set("X-Accel-Buffering", "no")
+		w.WriteHeader(http.StatusOK)
+		if err := rc.Flush(); err != nil {
+			log.Error("streaming is not supported", sl.Err(err))
+			return
+		}
+
+		log.Info("click stream opened", slog.String("alias", alias))
+
+		ticker := time.NewTicker(keepAlive)
+		defer ticker.Stop()
+
+		for {
+			var err error
+			select {
+			case <-r.Context().Done():
+				log.Info("click stream closed by client")
+				return
+			case e, ok := <-clicks:
+				if !ok {
+					log.Info("click stream closed by server")
+					return
+				}
+				if e.Bot && !includeBots {
+					continue
+				}
+				err = writeClick(w, e)
+			case <-ticker.C:
+				_, err = fmt.Fprint(w, ": keep-alive\n\n")
+			}
+			if err == nil {
+				err = rc.Flush()
+			}
+			if err != nil {
+				log.Info("click stream aborted", sl.Err(err))
+				return
+			}
+		}
+	}
+}
+
+func inactive(err error) bool {
+	return errors.Is(err, storage.ErrUrlExpired) ||
+		errors.Is(err, storage.ErrUrlExhausted) ||
+		errors.Is(err, storage.ErrUrlBlocked) ||
+		errors.Is(err, storage.ErrUrlUnsafe)
+}
+
+func writeClick(w http.ResponseWriter, e webhook.Event) error {
+	data, err := json.Marshal(Click{
+		Alias:     e.Alias,
+		ClickedAt: e.Time,
+		Referrer:  e.Referrer,
+		UserAgent: e.UserAgent,
+		Country:   e.Country,
+		Bot:       e.Bot,
+	})
+	if err != nil {
+		return err
+	}
+
+	_, err = fmt.Fprintf(w, "event: click\ndata: %s\n\n", data)
+	return err
+}
@@ -0,0 +1,188 @@
+package events_test
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"url-shortener/internal/http-server/handlers/url/access"
+	accessMocks "url-shortener/internal/http-server/handlers/url/access/mocks"
+	"url-shortener/internal/http-server/handlers/url/events"
+	"url-shortener/internal/http-server/handlers/url/events/mocks"
+	"url-shortener/internal/http-server/middleware/auth"
+	resp "url-shortener/internal/lib/api/response"
+	"url-shortener/internal/lib/logger/handlers/slogdiscard"
+	"url-shortener/internal/storage"
+	"url-shortener/internal/webhook"
+)
+
+// closedStream returns a subscription holding clicks that ends after them,
+// as when the server shuts down.
+func closedStream(clicks ...webhook.Event) <-chan webhook.Event {
+	ch := make(chan webhook.Event, len(clicks))
+	for _, c := range clicks {
+		ch <- c
+	}
+	close(ch)
+	return ch
+}
+
+// readEvents returns the data of every "click" event in an SSE body.
+func readEvents(t *testing.T, body string) []events.Click {
+	t.Helper()
+
+	var clicks []events.Click
+	sc := bufio.NewScanner(strings.NewReader(body))
+	for sc.Scan() {
+		if sc.Text() != "event: click" {
+			continue
+		}
+		require.True(t, sc.Scan())
+		data, ok := strings.CutPrefix(sc.Text(), "data: ")
+		require.True(t, ok)
+
+		var c events.Click
+		require.NoError(t, json.Unmarshal([]byte(data), &c))
+		clicks = append(clicks, c)
+	}
+	return clicks
+}
+
+func serve(subscriber events.Subscriber, getter events.URLGetter, ownerGetter access.OwnerGetter, user storage.User, target string) *httptest.ResponseRecorder {
+	r := chi.NewRouter()
+	h := events.New(slogdiscard.NewDiscardLogger(), subscriber, getter, ownerGetter)
+	r.Get("/events", h)
+	r.Get("/{alias}/events", h)
+
+	req := httptest.NewRequest(http.MethodGet, target, nil)
+	req = req.WithContext(auth.WithUser(req.Context(), user))
+
+	rr := httptest.NewRecorder()
+	r.ServeHTTP(rr, req)
+	return rr
+}
+
+func TestEventsHandler(t *testing.T) {
+	clickedAt := time.Date(2025, 3, 1, 10, 0, 0, 0, time.UTC)
+	clicks := []webhook.Event{
+		{Type: webhook.EventLinkClicked, Alias: "test_alias", Time: clickedAt, Referrer: "https://example.com/", Country: "DE"},
+		{Type: webhook.EventLinkClicked, Alias: "test_alias", Time: clickedAt, UserAgent: "Googlebot", Bot: true},
+	}
+
+	cases := []struct {
+		name      string
+		target    string
+		alias     string
+		callGet   bool
+		user      storage.User
+		owner     string
+		clicks    []webhook.Event
+		want      []events.Click
+		getError  error
+		status    int
+		respError string
+	}{
+		{
+			name:    "Link",
+			target:  "/Test_Alias/events",
+			alias:   "test_alias",
+			callGet: true,
+			clicks:  clicks,
+			status:  http.StatusOK,
+			want: []events.Click{
+				{Alias: "test_alias", ClickedAt: clickedAt, Referrer: "https://example.com/", Country: "DE"},
+			},
+		},
+		{
+			name:   "All links with bots",
+			target: "/events?include_bots=true",
+			clicks: clicks,
+			status: http.StatusOK,
+			want: []events.Click{
+				{Alias: "test_alias", ClickedAt: clickedAt, Referrer: "https://example.com/", Country: "DE"},
+				{Alias: "test_alias", ClickedAt: clickedAt, UserAgent: "Googlebot", Bot: true},
+			},
+		},
+		{
+			name:     "Expired link",
+			target:   "/test_alias/events",
+			alias:    "test_alias",
+			callGet:  true,
+			getError: storage.ErrUrlExpired,
+			status:   http.StatusOK,
+		},
+		{
+			name:      "Invalid include_bots",
+			target:    "/events?include_bots=maybe",
+			status:    http.StatusBadRequest,
+			respError: "invalid include_bots",
+		},
+		{
+			name:      "Not found",
+			target:    "/missing/events",
+			callGet:   true,
+			getError:  storage.ErrUrlNotFound,
+			status:    http.StatusNotFound,
+			respError: "url not found",
+		},
+		{
+			name:      "Other user's link",
+			target:    "/test_alias/events",
+			alias:     "test_alias",
+			callGet:   true,
+			user:      storage.User{Username: "bob", Role: storage.RoleEditor},
+			owner:     "alice",
+			status:    http.StatusNotFound,
+			respError: "url not found",
+		},
+		{
+			name:      "GetURL Error",
+			target:    "/test_alias/events",
+			callGet:   true,
+			getError:  errors.New("unexpected error"),
+			status:    http.StatusInternalServerError,
+			respError: "internal error",
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			subscriberMock := mocks.NewSubscriber(t)
+			getterMock := mocks.NewURLGetter(t)
+			ownerGetterMock := accessMocks.NewOwnerGetter(t)
+			ownerGetterMock.On("GetOwner", mock.Anything, mock.Anything).Return(tc.owner, nil).Maybe()
+
+			if tc.callGet {
+				getterMock.On("GetURL", mock.Anything, mock.Anything).
+					Return(storage.URL{Alias: tc.alias}, tc.getError).
+					Once()
+			}
+			if tc.status == http.StatusOK {
+				subscriberMock.On("Subscribe", tc.alias).Return(closedStream(tc.clicks...), func() {}).Once()
+			}
+
+			rr := serve(subscriberMock, getterMock, ownerGetterMock, tc.user, tc.target)
+
+			require.Equal(t, tc.status, rr.Code)
+
+			if tc.respError != "" {
+				var res resp.Response
+				require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &res))
+				require.Equal(t, tc.respError, res.Error)
+				return
+			}
+
+			require.Equal(t, "text/event-stream", rr.Header().Get("Content-Type"))
+			require.Equal(t, tc.want, readEvents(t, rr.Body.String()))
+		})
+	}
+}
@@ -0,0 +1,123 @@
+package expand
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+	"time"
+	resp "url-shortener/internal/lib/api/response"
+	"url-shortener/internal/lib/logger/sl"
+	"url-shortener/internal/lib/shorturl"
+	"url-shortener/internal/storage"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/go-chi/render"
+)
+
+// Link statuses. Only active links redirect right now; links outside their
+// activation window are still returned in full since they will or did.
+const (
+	StatusActive       = "active"
+	StatusNotActiveYet = "not_active_yet"
+	StatusEnded        = "ended"
+	StatusExpired      = "expired"
+	StatusExhausted    = "exhausted"
+	StatusBlocked      = "blocked"
+	StatusUnsafe       = "unsafe"
+)
+
+type Response struct {
+	resp.Response
+	Alias      string `json:"alias"`
+	LinkStatus string `json:"link_status"`
+	// The fields below are empty for expired, exhausted and blocked links.
+	ShortURL  string     `json:"short_url,omitempty"`
+	URL       string     `json:"url,omitempty"`
+	CreatedAt *time.Time `json:"created_at,omitempty"`
+	ExpiresAt *time.Time `json:"expires_at,omitempty"`
+	// Protected links need a password to be followed; their URL is not
+	// disclosed.
+	Protected bool `json:"protected,omitempty"`
+}
+
+//go:generate go run github.com/vektra/mockery/v2@latest --name=URLGetter
+type URLGetter interface {
+	GetURL(ctx context.Context, alias string) (storage.URL, error)
+}
+
+// New resolves a short link without redirecting or counting a click, so
+// tools can find out where a link leads. Like the redirect itself, it
+// needs no authentication and discloses neither the destination of
+// password-protected links nor that of links that no longer work.
+func New(log *slog.Logger, urlGetter URLGetter, links *shorturl.Builder) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.url.expand.New"
+
+		log := log.With(
+			slog.String("op", op),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+
+		alias := chi.URLParam(r, "alias")
+		if alias == "" {
+			log.Info("alias is empty")
+			render.Status(r, http.StatusBadRequest)
+			render.JSON(w, r, resp.Error("invalid request"))
+			return
+		}
+
+		u, err := urlGetter.GetURL(r.Context(), alias)
+		if errors.Is(err, storage.ErrUrlNotFound) {
+			log.Info("url not found", slog.String("alias", alias))
+			render.Status(r, http.StatusNotFound)
+			render.JSON(w, r, resp.Error("url not found"))
+			return
+		}
+
+		res := Response{
+			Response: resp.OK(),
+			Alias:    alias,
+		}
+		switch {
+		case errors.Is(err, storage.ErrUrlExpired):
+			res.LinkStatus = StatusExpired
+		case errors.Is(err, storage.ErrUrlExhausted):
+			res.LinkStatus = StatusExhausted
+		case errors.Is(err, storage.ErrUrlBlocked):
+			res.LinkStatus = StatusBlocked
+		case errors.Is(err, storage.ErrUrlUnsafe):
+			res.LinkStatus = StatusUnsafe
+		case err != nil:
+			log.Error("failed to get url", sl.Err(err))
+			render.Status(r, http.StatusInternalServerError)
+			render.JSON(w, r, resp.Error("internal error"))
+			return
+		default:
+			now := time.Now()
+			switch {
+			case u.NotActiveYet(now):
+				res.LinkStatus = StatusNotActiveYet
+			case u.Ended(now):
+				res.LinkStatus = StatusEnded
+			default:
+				res.LinkStatus = StatusActive
+			}
+
+			res.ShortURL = links.URL(r, u.Domain, alias)
+			res.CreatedAt = &u.CreatedAt
+			if !u.ExpiresAt.IsZero() {
+				res.ExpiresAt = &u.ExpiresAt
+			}
+			res.Protected = u.PasswordHash != ""
+			if !res.Protected {
+				res.URL = u.URL
+			}
+		}
+
+		log.Info("url expanded", slog.String("alias", alias), slog.String("link_status", res.LinkStatus))
+
+		render.JSON(w, r, res)
+	}
+}
@@ -0,0 +1,119 @@
+package expand_test
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"url-shortener/internal/http-server/handlers/url/expand"
+	"url-shortener/internal/http-server/handlers/url/expand/mocks"
+	"url-shortener/internal/lib/logger/handlers/slogdiscard"
+	"url-shortener/internal/lib/shorturl"
+	"url-shortener/internal/storage"
+)
+
+func TestExpandHandler(t *testing.T) {
+	const alias = "test_alias"
+
+	created := time.Date(2025, 3, 1, 12, 0, 0, 0, time.UTC)
+
+	cases := []struct {
+		name       string
+		url        storage.URL
+		mockError  error
+		status     int
+		respError  string
+		linkStatus string
+		wantURL    string
+	}{
+		{
+			name:       "Active",
+			url:        storage.URL{Alias: alias, URL: "https://example.com", CreatedAt: created},
+			status:     http.StatusOK,
+			linkStatus: expand.StatusActive,
+			wantURL:    "https://example.com",
+		},
+		{
+			name:       "Protected",
+			url:        storage.URL{Alias: alias, URL: "https://secret.example.com", CreatedAt: created, PasswordHash: "hash"},
+			status:     http.StatusOK,
+			linkStatus: expand.StatusActive,
+		},
+		{
+			name:       "Not active yet",
+			url:        storage.URL{Alias: alias, URL: "https://example.com", CreatedAt: created, ActiveFrom: time.Now().Add(time.Hour)},
+			status:     http.StatusOK,
+			linkStatus: expand.StatusNotActiveYet,
+			wantURL:    "https://example.com",
+		},
+		{
+			name:       "Expired",
+			mockError:  storage.ErrUrlExpired,
+			status:     http.StatusOK,
+			linkStatus: expand.StatusExpired,
+		},
+		{
+			name:       "Blocked",
+			mockError:  storage.ErrUrlBlocked,
+			status:     http.StatusOK,
+			linkStatus: expand.StatusBlocked,
+		},
+		{
+			name:      "Not found",
+			mockError: storage.ErrUrlNotFound,
+			status:    http.StatusNotFound,
+			respError: "url not found",
+		},
+		{
+			name:      "GetURL Error",
+			mockError: errors.New("unexpected error"),
+			status:    http.StatusInternalServerError,
+			respError: "internal error",
+		},
+	}
+
+	links, err := shorturl.New("https://sho.rt", nil)
+	require.NoError(t, err)
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			urlGetterMock := mocks.NewURLGetter(t)
+
+			urlGetterMock.On("GetURL", mock.Anything, alias).
+				Return(tc.url, tc.mockError).
+				Once()
+
+			r := chi.NewRouter()
+			r.Get("/expand/{alias}", expand.New(slogdiscard.NewDiscardLogger(), urlGetterMock, links))
+
+			req := httptest.NewRequest(http.MethodGet, "/expand/"+alias, nil)
+			rr := httptest.NewRecorder()
+			r.ServeHTTP(rr, req)
+
+			require.Equal(t, tc.status, rr.Code)
+
+			var body expand.Response
+			require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &body))
+
+			require.Equal(t, tc.respError, body.Error)
+			require.Equal(t, tc.linkStatus, body.LinkStatus)
+			require.Equal(t, tc.wantURL, body.URL)
+
+			if tc.url.CreatedAt.IsZero() {
+				require.Empty(t, body.ShortURL)
+				require.Nil(t, body.CreatedAt)
+			} else {
+				require.Equal(t, "https://sho.rt/"+alias, body.ShortURL)
+				require.True(t, created.Equal(*body.CreatedAt))
+				require.Equal(t, tc.url.PasswordHash != "", body.Protected)
+			}
+		})
+	}
+}
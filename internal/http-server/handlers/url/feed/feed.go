@@ -0,0 +1,203 @@
+package feed
+
+import (
+	"context"
+	"encoding/xml"
+	"log/slog"
+	"net/http"
+	"time"
+	"url-shortener/internal/http-server/middleware/auth"
+	resp "url-shortener/internal/lib/api/response"
+	"url-shortener/internal/lib/logger/sl"
+	"url-shortener/internal/lib/shorturl"
+	"url-shortener/internal/storage"
+
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/go-chi/render"
+)
+
+// Formats of the feed.
+const (
+	FormatRSS  = "rss"
+	FormatAtom = "atom"
+)
+
+// title names the feed in readers.
+const title = "Short links"
+
+//go:generate go run github.com/vektra/mockery/v2@latest --name=URLLister
+type URLLister interface {
+	ListURLs(ctx context.Context, filter storage.ListFilter, limit int, offset int, desc bool) ([]storage.URL, error)
+}
+
+type rss struct {
+	XMLName xml.Name   `xml:"rss"`
+	Version string     `xml:"version,attr"`
+	Channel rssChannel `xml:"channel"`
+}
+
+type rssChannel struct {
+	Title       string    `xml:"title"`
+	Link        string    `xml:"link"`
+	Description string    `xml:"description"`
+	Items       []rssItem `xml:"item"`
+}
+
+type rssItem struct {
+	Title   string `xml:"title"`
+	Link    string `xml:"link"`
+	GUID    string `xml:"guid"`
+	PubDate string `xml:"pubDate"`
+}
+
+type atomFeed struct {
+	XMLName xml.Name    `xml:"http://www.w3.org/2005/Atom feed"`
+	Title   string      `xml:"title"`
+	ID      string      `xml:"id"`
+	Updated string      `xml:"updated"`
+	Link    atomLink    `xml:"link"`
+	Author  atomAuthor  `xml:"author"`
+	Entries []atomEntry `xml:"entry"`
+}
+
+type atomEntry struct {
+	Title     string   `xml:"title"`
+	ID        string   `xml:"id"`
+	Link      atomLink `xml:"link"`
+	Published string   `xml:"published"`
+	Updated   string   `xml:"updated"`
+}
+
+type atomLink struct {
+	Href string `xml:"href,attr"`
+}
+
+type atomAuthor struct {
+	Name string `xml:"name"`
+}
+
+// New serves the newest `size` links of the caller (everyone's for admins
+// and viewers, and for anybody if public is set, as the feed then needs no
+// login) as an RSS 2.0 or Atom feed, picked by format=rss|atom (RSS by
+// default). Links protected by a password, blocked, expired or outside
+// their activation window are left out, see storage.ListFilter.Public.
+// An entry is titled with the page title of the link, or its alias without
+// one, points to the short URL and is dated by the creation of the link.
+func New(log *slog.Logger, urlLister URLLister, links *shorturl.Builder, size int, public bool) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.url.feed.New"
+
+		log := log.With(
+			slog.String("op", op),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+
+		format := r.URL.Query().Get("format")
+		switch format {
+		case "":
+			format = FormatRSS
+		case FormatRSS, FormatAtom:
+		default:
+			log.Info("invalid format", slog.String("format", format))
+			render.Status(r, http.StatusBadRequest)
+			render.JSON(w, r, resp.Error("invalid format"))
+			return
+		}
+
+		filter := storage.ListFilter{Public: true}
+		if !public {
+			filter.Owner = auth.ViewerFromContext(r.Context())
+		}
+
+		urls, err := urlLister.ListURLs(r.Context(), filter, size, 0, true)
+		if err != nil {
+			log.Error("failed to list urls", sl.Err(err))
+			render.Status(r, http.StatusInternalServerError)
+			render.JSON(w, r, resp.Error("failed to list urls"))
+			return
+		}
+
+		// The feed links to the service itself.
+		home := links.URL(r, "", "")
+
+		var doc any
+		if format == FormatAtom {
+			w.Header().Set("Content-Type", "application/atom+xml; charset=utf-8")
+			doc = atom(urls, links, r, home)
+		} else {
+			w.Header().Set("Content-Type", "application/rss+xml; charset=utf-8")
+			doc = channel(urls, links, r, home)
+		}
+
+		_, _ = w.Write([]byte(xml.Header))
+		enc := xml.NewEncoder(w)
+		enc.Indent("", "  ")
+		if err := enc.Encode(doc); err != nil {
+			log.Info("feed aborted", sl.Err(err))
+			return
+		}
+
+		log.Info("feed served", slog.String("format", format), slog.Int("count", len(urls)))
+	}
+}
+
+func channel(urls []storage.URL, links *shorturl.Builder, r *http.Request, home string) rss {
+	doc := rss{
+		Version: "2.0",
+		Channel: rssChannel{
+			Title:       title,
+			Link:        home,
+			Description: "The newest short links",
+			Items:       make([]rssItem, 0, len(urls)),
+		},
+	}
+	for _, u := range urls {
+		link := links.URL(r, u.Domain, u.Alias)
+		doc.Channel.Items = append(doc.Channel.Items, rssItem{
+			Title:   entryTitle(u),
+			Link:    link,
+			GUID:    link,
+			PubDate: u.CreatedAt.UTC().Format(time.RFC1123Z),
+		})
+	}
+
+	return doc
+}
+
+func atom(urls []storage.URL, links *shorturl.Builder, r *http.Request, home string) atomFeed {
+	// A feed without entries was last updated when it was asked for.
+	updated := time.Now()
+	if len(urls) > 0 {
+		updated = urls[0].CreatedAt
+	}
+
+	doc := atomFeed{
+		Title:   title,
+		ID:      home,
+		Updated: updated.UTC().Format(time.RFC3339),
+		Link:    atomLink{Href: home},
+		Author:  atomAuthor{Name: "url-shortener"},
+		Entries: make([]atomEntry, 0, len(urls)),
+	}
+	for _, u := range urls {
+		link := links.URL(r, u.Domain, u.Alias)
+		created := u.CreatedAt.UTC().Format(time.RFC3339)
+		doc.Entries = append(doc.Entries, atomEntry{
+			Title:     entryTitle(u),
+			ID:        link,
+			Link:      atomLink{Href: link},
+			Published: created,
+			Updated:   created,
+		})
+	}
+
+	return doc
+}
+
+func entryTitle(u storage.URL) string {
+	if u.Metadata.Title != "" {
+		return u.Metadata.Title
+	}
+
+	return u.Alias
+}
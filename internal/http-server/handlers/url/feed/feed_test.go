@@ -0,0 +1,196 @@
+package feed_test
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"url-shortener/internal/http-server/handlers/url/feed"
+	"url-shortener/internal/http-server/handlers/url/feed/mocks"
+	"url-shortener/internal/http-server/middleware/auth"
+	resp "url-shortener/internal/lib/api/response"
+	"url-shortener/internal/lib/logger/handlers/slogdiscard"
+	"url-shortener/internal/lib/shorturl"
+	"url-shortener/internal/storage"
+)
+
+type rss struct {
+	Channel struct {
+		Title string `xml:"title"`
+		Items []struct {
+			Title   string `xml:"title"`
+			Link    string `xml:"link"`
+			PubDate string `xml:"pubDate"`
+		} `xml:"item"`
+	} `xml:"channel"`
+}
+
+type atom struct {
+	XMLName xml.Name `xml:"http://www.w3.org/2005/Atom feed"`
+	Updated string   `xml:"updated"`
+	Entries []struct {
+		Title string `xml:"title"`
+		Link  struct {
+			Href string `xml:"href,attr"`
+		} `xml:"link"`
+		Published string `xml:"published"`
+	} `xml:"entry"`
+}
+
+var (
+	createdAt = time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	// urls are the newest first, as the feed asks for them.
+	urls = []storage.URL{
+		{Alias: "second", Domain: "go.example.com", CreatedAt: createdAt.Add(time.Hour), Metadata: storage.Metadata{Title: "Example page"}},
+		{Alias: "first", CreatedAt: createdAt},
+	}
+)
+
+func serve(t *testing.T, lister feed.URLLister, user storage.User, target string, public bool) *httptest.ResponseRecorder {
+	t.Helper()
+
+	links, err := shorturl.New("https://sho.rt", []string{"sho.rt", "go.example.com"})
+	require.NoError(t, err)
+
+	req := httptest.NewRequest(http.MethodGet, target, nil)
+	if user.Username != "" {
+		req = req.WithContext(auth.WithUser(req.Context(), user))
+	}
+
+	rr := httptest.NewRecorder()
+	feed.New(slogdiscard.NewDiscardLogger(), lister, links, 20, public).ServeHTTP(rr, req)
+	return rr
+}
+
+func TestFeedHandler_RSS(t *testing.T) {
+	listerMock := mocks.NewURLLister(t)
+	listerMock.On("ListURLs", mock.Anything, storage.ListFilter{Owner: "alice", Public: true}, 20, 0, true).Return(urls, nil).Once()
+
+	rr := serve(t, listerMock, storage.User{Username: "alice", Role: storage.RoleEditor}, "/urls/feed", false)
+
+	require.Equal(t, http.StatusOK, rr.Code)
+	require.Equal(t, "application/rss+xml; charset=utf-8", rr.Header().Get("Content-Type"))
+
+	var doc rss
+	require.NoError(t, xml.Unmarshal(rr.Body.Bytes(), &doc))
+	require.Equal(t, "Short links", doc.Channel.Title)
+	require.Len(t, doc.Channel.Items, 2)
+
+	require.Equal(t, "Example page", doc.Channel.Items[0].Title)
+	require.Equal(t, "https://go.example.com/second", doc.Channel.Items[0].Link)
+	require.Equal(t, "Thu, 02 Jan 2025 04:04:05 +0000", doc.Channel.Items[0].PubDate)
+
+	require.Equal(t, "first", doc.Channel.Items[1].Title)
+	require.Equal(t, "https://sho.rt/first", doc.Channel.Items[1].Link)
+	pubDate, err := time.Parse(time.RFC1123Z, doc.Channel.Items[1].PubDate)
+	require.NoError(t, err)
+	require.True(t, createdAt.Equal(pubDate))
+}
+
+func TestFeedHandler_Atom(t *testing.T) {
+	listerMock := mocks.NewURLLister(t)
+	// Admins follow everyone's links.
+	listerMock.On("ListURLs", mock.Anything, storage.ListFilter{Public: true}, 20, 0, true).Return(urls, nil).Once()
+
+	rr := serve(t, listerMock, storage.User{Username: "root", Role: storage.RoleAdmin}, "/urls/feed?format=atom", false)
+
+	require.Equal(t, http.StatusOK, rr.Code)
+	require.Equal(t, "application/atom+xml; charset=utf-8", rr.Header().Get("Content-Type"))
+
+	var doc atom
+	require.NoError(t, xml.Unmarshal(rr.Body.Bytes(), &doc))
+	require.Equal(t, "2025-01-02T04:04:05Z", doc.Updated)
+	require.Len(t, doc.Entries, 2)
+
+	require.Equal(t, "Example page", doc.Entries[0].Title)
+	require.Equal(t, "https://go.example.com/second", doc.Entries[0].Link.Href)
+	require.Equal(t, "2025-01-02T04:04:05Z", doc.Entries[0].Published)
+
+	require.Equal(t, "first", doc.Entries[1].Title)
+	require.Equal(t, "https://sho.rt/first", doc.Entries[1].Link.Href)
+	require.Equal(t, "2025-01-02T03:04:05Z", doc.Entries[1].Published)
+}
+
+func TestFeedHandler_Public(t *testing.T) {
+	cases := []struct {
+		name string
+		user storage.User
+	}{
+		{
+			name: "Without user",
+		},
+		{
+			// A public feed is the same for everyone.
+			name: "Editor",
+			user: storage.User{Username: "alice", Role: storage.RoleEditor},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			listerMock := mocks.NewURLLister(t)
+			listerMock.On("ListURLs", mock.Anything, storage.ListFilter{Public: true}, 20, 0, true).Return(urls, nil).Once()
+
+			rr := serve(t, listerMock, tc.user, "/urls/feed", true)
+
+			require.Equal(t, http.StatusOK, rr.Code)
+
+			var doc rss
+			require.NoError(t, xml.Unmarshal(rr.Body.Bytes(), &doc))
+			require.Len(t, doc.Channel.Items, 2)
+		})
+	}
+}
+
+func TestFeedHandler_Errors(t *testing.T) {
+	cases := []struct {
+		name      string
+		query     string
+		status    int
+		respError string
+		mockError error
+		callMock  bool
+	}{
+		{
+			name:      "Invalid format",
+			query:     "?format=json",
+			status:    http.StatusBadRequest,
+			respError: "invalid format",
+		},
+		{
+			name:      "ListURLs Error",
+			status:    http.StatusInternalServerError,
+			respError: "failed to list urls",
+			mockError: errors.New("unexpected error"),
+			callMock:  true,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			listerMock := mocks.NewURLLister(t)
+
+			if tc.callMock {
+				listerMock.On("ListURLs", mock.Anything, storage.ListFilter{Owner: "alice", Public: true}, 20, 0, true).
+					Return(nil, tc.mockError).
+					Once()
+			}
+
+			rr := serve(t, listerMock, storage.User{Username: "alice", Role: storage.RoleEditor}, "/urls/feed"+tc.query, false)
+
+			require.Equal(t, tc.status, rr.Code)
+
+			var res resp.Response
+			require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &res))
+			require.Equal(t, tc.respError, res.Error)
+		})
+	}
+}
@@ -0,0 +1,105 @@
+package geo
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"url-shortener/internal/http-server/handlers/url/access"
+	resp "url-shortener/internal/lib/api/response"
+	"url-shortener/internal/lib/logger/sl"
+	"url-shortener/internal/storage"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/go-chi/render"
+)
+
+// CountryClicks counts the clicks from one country, by its ISO 3166-1
+// alpha-2 code.
+type CountryClicks struct {
+	Country string `json:"country"`
+	Clicks  int64  `json:"clicks"`
+}
+
+type Response struct {
+	resp.Response
+	Alias     string          `json:"alias"`
+	Countries []CountryClicks `json:"countries"`
+	// Unknown counts clicks whose country could not be resolved, and all
+	// clicks while no GeoIP database is configured.
+	Unknown int64 `json:"unknown_clicks"`
+}
+
+//go:generate go run github.com/vektra/mockery/v2@latest --name=CountriesGetter
+type CountriesGetter interface {
+	GetCountries(ctx context.Context, alias string, includeBots bool) ([]storage.CountryClicks, error)
+}
+
+// New returns the clicks on an alias per visitor country, the most clicked
+// first. Clicks of bots are counted only with include_bots=true. Users
+// other than admins and viewers get the countries of their own links only.
+func New(log *slog.Logger, countriesGetter CountriesGetter, ownerGetter access.OwnerGetter) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.url.geo.New"
+
+		log := log.With(
+			slog.String("op", op),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+
+		alias := chi.URLParam(r, "alias")
+		if alias == "" {
+			log.Info("alias is empty")
+			render.Status(r, http.StatusBadRequest)
+			render.JSON(w, r, resp.Error("invalid request"))
+			return
+		}
+
+		includeBots := false
+		if v := r.URL.Query().Get("include_bots"); v != "" {
+			b, err := strconv.ParseBool(v)
+			if err != nil {
+				log.Info("invalid include_bots", slog.String("include_bots", v))
+				render.Status(r, http.StatusBadRequest)
+				render.JSON(w, r, resp.Error("invalid include_bots"))
+				return
+			}
+			includeBots = b
+		}
+
+		if !access.CanView(w, r, log, ownerGetter, alias) {
+			return
+		}
+
+		countries, err := countriesGetter.GetCountries(r.Context(), alias, includeBots)
+		if errors.Is(err, storage.ErrUrlNotFound) {
+			log.Info("url not found", slog.String("alias", alias))
+			render.Status(r, http.StatusNotFound)
+			render.JSON(w, r, resp.Error("url not found"))
+			return
+		}
+		if err != nil {
+			log.Error("failed to get countries", sl.Err(err))
+			render.Status(r, http.StatusInternalServerError)
+			render.JSON(w, r, resp.Error("failed to get countries"))
+			return
+		}
+
+		res := Response{
+			Response:  resp.OK(),
+			Alias:     alias,
+			Countries: make([]CountryClicks, 0, len(countries)),
+		}
+		for _, c := range countries {
+			if c.Country == "" {
+				res.Unknown += c.Clicks
+				continue
+			}
+			res.Countries = append(res.Countries, CountryClicks{Country: c.Country, Clicks: c.Clicks})
+		}
+
+		render.JSON(w, r, res)
+	}
+}
@@ -0,0 +1,126 @@
+package geo_test
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	accessMocks "url-shortener/internal/http-server/handlers/url/access/mocks"
+	"url-shortener/internal/http-server/handlers/url/geo"
+	"url-shortener/internal/http-server/handlers/url/geo/mocks"
+	"url-shortener/internal/lib/logger/handlers/slogdiscard"
+	"url-shortener/internal/storage"
+)
+
+func TestGeoHandler(t *testing.T) {
+	cases := []struct {
+		name          string
+		alias         string
+		query         string
+		includeBots   bool
+		status        int
+		respError     string
+		mockCountries []storage.CountryClicks
+		mockError     error
+		callMock      bool
+		want          []geo.CountryClicks
+		unknown       int64
+	}{
+		{
+			name:   "Success",
+			alias:  "test_alias",
+			status: http.StatusOK,
+			mockCountries: []storage.CountryClicks{
+				{Country: "DE", Clicks: 5},
+				{Country: "", Clicks: 3},
+				{Country: "FR", Clicks: 1},
+			},
+			callMock: true,
+			want: []geo.CountryClicks{
+				{Country: "DE", Clicks: 5},
+				{Country: "FR", Clicks: 1},
+			},
+			unknown: 3,
+		},
+		{
+			name:          "Include bots",
+			alias:         "test_alias",
+			query:         "?include_bots=true",
+			includeBots:   true,
+			status:        http.StatusOK,
+			mockCountries: []storage.CountryClicks{{Country: "US", Clicks: 2}},
+			callMock:      true,
+			want:          []geo.CountryClicks{{Country: "US", Clicks: 2}},
+		},
+		{
+			name:     "No clicks",
+			alias:    "test_alias",
+			status:   http.StatusOK,
+			callMock: true,
+			want:     []geo.CountryClicks{},
+		},
+		{
+			name:      "Invalid include_bots",
+			alias:     "test_alias",
+			query:     "?include_bots=maybe",
+			status:    http.StatusBadRequest,
+			respError: "invalid include_bots",
+		},
+		{
+			name:      "Not found",
+			alias:     "missing",
+			status:    http.StatusNotFound,
+			respError: "url not found",
+			mockError: storage.ErrUrlNotFound,
+			callMock:  true,
+		},
+		{
+			name:      "GetCountries Error",
+			alias:     "test_alias",
+			status:    http.StatusInternalServerError,
+			respError: "failed to get countries",
+			mockError: errors.New("unexpected error"),
+			callMock:  true,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			getterMock := mocks.NewCountriesGetter(t)
+			ownerGetterMock := accessMocks.NewOwnerGetter(t)
+			ownerGetterMock.On("GetOwner", mock.Anything, tc.alias).Return("", nil).Maybe()
+
+			if tc.callMock {
+				getterMock.On("GetCountries", mock.Anything, tc.alias, tc.includeBots).
+					Return(tc.mockCountries, tc.mockError).
+					Once()
+			}
+
+			r := chi.NewRouter()
+			r.Get("/{alias}/geo", geo.New(slogdiscard.NewDiscardLogger(), getterMock, ownerGetterMock))
+
+			req := httptest.NewRequest(http.MethodGet, "/"+tc.alias+"/geo"+tc.query, nil)
+			rr := httptest.NewRecorder()
+			r.ServeHTTP(rr, req)
+
+			require.Equal(t, tc.status, rr.Code)
+
+			var resp geo.Response
+			require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &resp))
+
+			require.Equal(t, tc.respError, resp.Error)
+
+			if tc.respError == "" {
+				require.Equal(t, tc.alias, resp.Alias)
+				require.Equal(t, tc.want, resp.Countries)
+				require.Equal(t, tc.unknown, resp.Unknown)
+			}
+		})
+	}
+}
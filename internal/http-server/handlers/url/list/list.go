@@ -0,0 +1,250 @@
+package list
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"time"
+	"url-shortener/internal/http-server/middleware/auth"
+	resp "url-shortener/internal/lib/api/response"
+	"url-shortener/internal/lib/logger/sl"
+	"url-shortener/internal/lib/shorturl"
+	"url-shortener/internal/lib/tag"
+	"url-shortener/internal/storage"
+
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/go-chi/render"
+)
+
+type URL struct {
+	Alias    string `json:"alias"`
+	ShortURL string `json:"short_url"`
+	// Domain is the short domain of the link, empty for the default one.
+	Domain    string     `json:"domain,omitempty"`
+	URL       string     `json:"url"`
+	CreatedAt time.Time  `json:"created_at"`
+	ExpiresAt *time.Time `json:"expires_at,omitempty"`
+	APIKeyID  int64      `json:"api_key_id,omitempty"`
+	Owner     string     `json:"owner,omitempty"`
+	// Title, Description and Image describe the target page when metadata
+	// fetching is enabled.
+	Title       string   `json:"title,omitempty"`
+	Description string   `json:"description,omitempty"`
+	Image       string   `json:"image,omitempty"`
+	Tags        []string `json:"tags,omitempty"`
+	Campaign    int64    `json:"campaign,omitempty"`
+	// Health is the outcome of the last dead-link check, if any.
+	Health *Health `json:"health,omitempty"`
+}
+
+type Health struct {
+	// Status is ok, broken or redirect_loop.
+	Status     string    `json:"status"`
+	StatusCode int       `json:"status_code,omitempty"`
+	CheckedAt  time.Time `json:"checked_at"`
+}
+
+type Response struct {
+	resp.Response
+	URLs   []URL `json:"urls"`
+	Limit  int   `json:"limit"`
+	Offset int   `json:"offset"`
+}
+
+const (
+	defaultLimit = 20
+	maxLimit     = 100
+)
+
+//go:generate go run github.com/vektra/mockery/v2@latest --name=URLLister
+type URLLister interface {
+	ListURLs(ctx context.Context, filter storage.ListFilter, limit int, offset int, desc bool) ([]storage.URL, error)
+}
+
+// New lists saved links page by page. Editors see their own links, admins
+// and viewers see everyone's.
+//
+// Query params: limit (default 20, max 100), offset, and order=asc|desc
+// by creation date (newest first by default). The list can be filtered by
+// url (a part of the original URL, case-insensitive), tag, owner (admins
+// and viewers only) and created_from/created_to (RFC 3339 or YYYY-MM-DD; a date in
+// created_to includes the whole day), health (ok, broken or
+// redirect_loop, the outcome of the last dead-link check) and campaign
+// (a campaign id).
+func New(log *slog.Logger, urlLister URLLister, links *shorturl.Builder) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.url.list.New"
+
+		log := log.With(
+			slog.String("op", op),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+
+		query := r.URL.Query()
+
+		limit, err := intParam(query.Get("limit"), defaultLimit)
+		if err != nil || limit <= 0 || limit > maxLimit {
+			log.Info("invalid limit", slog.String("limit", query.Get("limit")))
+			render.Status(r, http.StatusBadRequest)
+			render.JSON(w, r, resp.Error("invalid limit"))
+			return
+		}
+
+		offset, err := intParam(query.Get("offset"), 0)
+		if err != nil || offset < 0 {
+			log.Info("invalid offset", slog.String("offset", query.Get("offset")))
+			render.Status(r, http.StatusBadRequest)
+			render.JSON(w, r, resp.Error("invalid offset"))
+			return
+		}
+
+		var desc bool
+		switch query.Get("order") {
+		case "", "desc":
+			desc = true
+		case "asc":
+			desc = false
+		default:
+			log.Info("invalid order", slog.String("order", query.Get("order")))
+			render.Status(r, http.StatusBadRequest)
+			render.JSON(w, r, resp.Error("invalid order"))
+			return
+		}
+
+		filter := storage.ListFilter{
+			Owner:       auth.ViewerFromContext(r.Context()),
+			URLContains: query.Get("url"),
+		}
+
+		if owner := query.Get("owner"); owner != "" {
+			if filter.Owner != "" && owner != filter.Owner {
+				log.Info("owner filter is for admins only", slog.String("owner", owner))
+				render.Status(r, http.StatusForbidden)
+				render.JSON(w, r, resp.Error("only admins can filter by owner"))
+				return
+			}
+			filter.Owner = owner
+		}
+
+		if t := query.Get("tag"); t != "" {
+			if filter.Tag, err = tag.Normalize(t); err != nil {
+				log.Info("invalid tag", slog.String("tag", t))
+				render.Status(r, http.StatusBadRequest)
+				render.JSON(w, r, resp.Error("invalid tag"))
+				return
+			}
+		}
+
+		if v := query.Get("campaign"); v != "" {
+			id, err := strconv.ParseInt(v, 10, 64)
+			if err != nil || id <= 0 {
+				log.Info("invalid campaign", slog.String("campaign", v))
+				render.Status(r, http.StatusBadRequest)
+				render.JSON(w, r, resp.Error("invalid campaign"))
+				return
+			}
+			filter.CampaignID = id
+		}
+
+		switch h := query.Get("health"); h {
+		case "", storage.HealthOK, storage.HealthBroken, storage.HealthRedirectLoop:
+			filter.Health = h
+		default:
+			log.Info("invalid health", slog.String("health", h))
+			render.Status(r, http.StatusBadRequest)
+			render.JSON(w, r, resp.Error("invalid health"))
+			return
+		}
+
+		if filter.CreatedFrom, err = timeParam(query.Get("created_from"), false); err != nil {
+			log.Info("invalid created_from", slog.String("created_from", query.Get("created_from")))
+			render.Status(r, http.StatusBadRequest)
+			render.JSON(w, r, resp.Error("invalid created_from"))
+			return
+		}
+		if filter.CreatedTo, err = timeParam(query.Get("created_to"), true); err != nil {
+			log.Info("invalid created_to", slog.String("created_to", query.Get("created_to")))
+			render.Status(r, http.StatusBadRequest)
+			render.JSON(w, r, resp.Error("invalid created_to"))
+			return
+		}
+
+		urls, err := urlLister.ListURLs(r.Context(), filter, limit, offset, desc)
+		if err != nil {
+			log.Error("failed to list urls", sl.Err(err))
+			render.Status(r, http.StatusInternalServerError)
+			render.JSON(w, r, resp.Error("failed to list urls"))
+			return
+		}
+
+		res := Response{
+			Response: resp.OK(),
+			URLs:     make([]URL, 0, len(urls)),
+			Limit:    limit,
+			Offset:   offset,
+		}
+		for _, u := range urls {
+			item := URL{
+				Alias:     u.Alias,
+				ShortURL:  links.URL(r, u.Domain, u.Alias),
+				Domain:    u.Domain,
+				URL:       u.URL,
+				CreatedAt: u.CreatedAt,
+				APIKeyID:  u.APIKeyID,
+				Owner:     u.Owner,
+
+				Title:       u.Metadata.Title,
+				Description: u.Metadata.Description,
+				Image:       u.Metadata.Image,
+				Tags:        u.Tags,
+				Campaign:    u.CampaignID,
+			}
+			if !u.ExpiresAt.IsZero() {
+				item.ExpiresAt = &u.ExpiresAt
+			}
+			if u.Health.Status != "" {
+				item.Health = &Health{
+					Status:     u.Health.Status,
+					StatusCode: u.Health.StatusCode,
+					CheckedAt:  u.Health.CheckedAt,
+				}
+			}
+			res.URLs = append(res.URLs, item)
+		}
+
+		log.Info("urls listed", slog.Int("count", len(urls)))
+
+		render.JSON(w, r, res)
+	}
+}
+
+// timeParam parses an RFC 3339 time or a YYYY-MM-DD date, taken as
+// midnight UTC; with endOfDay a date means the midnight that ends it.
+func timeParam(value string, endOfDay bool) (time.Time, error) {
+	if value == "" {
+		return time.Time{}, nil
+	}
+
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, nil
+	}
+
+	t, err := time.Parse(time.DateOnly, value)
+	if err != nil {
+		return time.Time{}, err
+	}
+	if endOfDay {
+		t = t.AddDate(0, 0, 1)
+	}
+
+	return t, nil
+}
+
+func intParam(value string, def int) (int, error) {
+	if value == "" {
+		return def, nil
+	}
+
+	return strconv.Atoi(value)
+}
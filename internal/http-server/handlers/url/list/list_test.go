@@ -0,0 +1,249 @@
+package list_test
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"url-shortener/internal/http-server/handlers/url/list"
+	"url-shortener/internal/http-server/handlers/url/list/mocks"
+	"url-shortener/internal/http-server/middleware/auth"
+	"url-shortener/internal/lib/logger/handlers/slogdiscard"
+	"url-shortener/internal/lib/shorturl"
+	"url-shortener/internal/storage"
+)
+
+func TestListHandler(t *testing.T) {
+	createdAt := time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	urls := []storage.URL{
+		{Alias: "second", URL: "https://example.com/2", CreatedAt: createdAt.Add(time.Hour), Tags: []string{"promo"}},
+		{Alias: "first", URL: "https://example.com/1", CreatedAt: createdAt, Health: storage.Health{
+			Status: storage.HealthBroken, StatusCode: http.StatusNotFound, CheckedAt: createdAt.Add(time.Hour),
+		}},
+	}
+
+	cases := []struct {
+		name      string
+		query     string
+		limit     int
+		offset    int
+		desc      bool
+		admin     bool
+		filter    storage.ListFilter
+		status    int
+		respError string
+		mockURLs  []storage.URL
+		mockError error
+		callMock  bool
+	}{
+		{
+			name:     "Defaults",
+			query:    "",
+			limit:    20,
+			offset:   0,
+			desc:     true,
+			filter:   storage.ListFilter{Owner: "alice"},
+			status:   http.StatusOK,
+			mockURLs: urls,
+			callMock: true,
+		},
+		{
+			name:     "Admin sees everyone",
+			query:    "",
+			limit:    20,
+			desc:     true,
+			admin:    true,
+			filter:   storage.ListFilter{},
+			status:   http.StatusOK,
+			mockURLs: urls,
+			callMock: true,
+		},
+		{
+			name:     "Paged ascending",
+			query:    "?limit=1&offset=1&order=asc",
+			limit:    1,
+			offset:   1,
+			desc:     false,
+			filter:   storage.ListFilter{Owner: "alice"},
+			status:   http.StatusOK,
+			mockURLs: urls[1:],
+			callMock: true,
+		},
+		{
+			name:  "Filtered",
+			query: "?url=Example.com&created_from=2025-01-01&created_to=2025-01-31T12:00:00Z",
+			limit: 20,
+			desc:  true,
+			filter: storage.ListFilter{
+				Owner:       "alice",
+				URLContains: "Example.com",
+				CreatedFrom: time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC),
+				CreatedTo:   time.Date(2025, 1, 31, 12, 0, 0, 0, time.UTC),
+			},
+			status:   http.StatusOK,
+			mockURLs: urls,
+			callMock: true,
+		},
+		{
+			name:     "Whole last day",
+			query:    "?created_to=2025-01-31&owner=alice",
+			limit:    20,
+			desc:     true,
+			filter:   storage.ListFilter{Owner: "alice", CreatedTo: time.Date(2025, 2, 1, 0, 0, 0, 0, time.UTC)},
+			status:   http.StatusOK,
+			mockURLs: urls,
+			callMock: true,
+		},
+		{
+			name:     "Admin filters by owner",
+			query:    "?owner=bob",
+			limit:    20,
+			desc:     true,
+			admin:    true,
+			filter:   storage.ListFilter{Owner: "bob"},
+			status:   http.StatusOK,
+			mockURLs: urls,
+			callMock: true,
+		},
+		{
+			name:      "Owner filter for users",
+			query:     "?owner=bob",
+			status:    http.StatusForbidden,
+			respError: "only admins can filter by owner",
+		},
+		{
+			name:     "By tag",
+			query:    "?tag=Spring-Sale",
+			limit:    20,
+			desc:     true,
+			filter:   storage.ListFilter{Owner: "alice", Tag: "spring-sale"},
+			status:   http.StatusOK,
+			mockURLs: urls,
+			callMock: true,
+		},
+		{
+			name:     "By health",
+			query:    "?health=broken",
+			limit:    20,
+			desc:     true,
+			filter:   storage.ListFilter{Owner: "alice", Health: storage.HealthBroken},
+			status:   http.StatusOK,
+			mockURLs: urls[1:],
+			callMock: true,
+		},
+		{
+			name:      "Invalid health",
+			query:     "?health=dead",
+			status:    http.StatusBadRequest,
+			respError: "invalid health",
+		},
+		{
+			name:      "Invalid tag",
+			query:     "?tag=spring%20sale",
+			status:    http.StatusBadRequest,
+			respError: "invalid tag",
+		},
+		{
+			name:      "Invalid created_from",
+			query:     "?created_from=yesterday",
+			status:    http.StatusBadRequest,
+			respError: "invalid created_from",
+		},
+		{
+			name:      "Invalid limit",
+			query:     "?limit=abc",
+			status:    http.StatusBadRequest,
+			respError: "invalid limit",
+		},
+		{
+			name:      "Limit too big",
+			query:     "?limit=1000",
+			status:    http.StatusBadRequest,
+			respError: "invalid limit",
+		},
+		{
+			name:      "Negative offset",
+			query:     "?offset=-1",
+			status:    http.StatusBadRequest,
+			respError: "invalid offset",
+		},
+		{
+			name:      "Invalid order",
+			query:     "?order=sideways",
+			status:    http.StatusBadRequest,
+			respError: "invalid order",
+		},
+		{
+			name:      "ListURLs Error",
+			query:     "",
+			limit:     20,
+			desc:      true,
+			filter:    storage.ListFilter{Owner: "alice"},
+			status:    http.StatusInternalServerError,
+			respError: "failed to list urls",
+			mockError: errors.New("unexpected error"),
+			callMock:  true,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			urlListerMock := mocks.NewURLLister(t)
+
+			if tc.callMock {
+				urlListerMock.On("ListURLs", mock.Anything, tc.filter, tc.limit, tc.offset, tc.desc).
+					Return(tc.mockURLs, tc.mockError).
+					Once()
+			}
+
+			// Without a base URL short URLs take the request's host.
+			links, err := shorturl.New("", nil)
+			require.NoError(t, err)
+
+			handler := list.New(slogdiscard.NewDiscardLogger(), urlListerMock, links)
+
+			user := storage.User{Username: "alice", Role: storage.RoleUser}
+			if tc.admin {
+				user.Role = storage.RoleAdmin
+			}
+
+			req := httptest.NewRequest(http.MethodGet, "/urls"+tc.query, nil)
+			req = req.WithContext(auth.WithUser(req.Context(), user))
+			rr := httptest.NewRecorder()
+			handler.ServeHTTP(rr, req)
+
+			require.Equal(t, tc.status, rr.Code)
+
+			var resp list.Response
+			require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &resp))
+
+			require.Equal(t, tc.respError, resp.Error)
+
+			if tc.respError == "" {
+				require.Len(t, resp.URLs, len(tc.mockURLs))
+				for i, u := range tc.mockURLs {
+					require.Equal(t, u.Alias, resp.URLs[i].Alias)
+					require.Equal(t, "http://example.com/"+u.Alias, resp.URLs[i].ShortURL)
+					require.Equal(t, u.URL, resp.URLs[i].URL)
+					require.True(t, u.CreatedAt.Equal(resp.URLs[i].CreatedAt))
+					require.Equal(t, u.Tags, resp.URLs[i].Tags)
+					if u.Health.Status == "" {
+						require.Nil(t, resp.URLs[i].Health)
+					} else {
+						require.Equal(t, u.Health.Status, resp.URLs[i].Health.Status)
+						require.Equal(t, u.Health.StatusCode, resp.URLs[i].Health.StatusCode)
+					}
+				}
+				require.Equal(t, tc.limit, resp.Limit)
+				require.Equal(t, tc.offset, resp.Offset)
+			}
+		})
+	}
+}
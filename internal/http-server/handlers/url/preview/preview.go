@@ -0,0 +1,158 @@
+package preview
+
+import (
+	"context"
+	"errors"
+	"html/template"
+	"log/slog"
+	"net/http"
+	"net/url"
+	resp "url-shortener/internal/lib/api/response"
+	"url-shortener/internal/lib/logger/sl"
+	"url-shortener/internal/lib/shorturl"
+	"url-shortener/internal/storage"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/go-chi/render"
+)
+
+var page = template.Must(template.New("preview").Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+  <meta charset="utf-8">
+  <meta name="viewport" content="width=device-width, initial-scale=1">
+  <meta name="robots" content="noindex">
+  <title>Link preview</title>
+  <style>
+    body { font-family: sans-serif; max-width: 40em; margin: 4em auto; padding: 0 1em; }
+    .host { font-size: 1.5em; font-weight: bold; }
+    .url { word-break: break-all; color: #555; }
+    .continue { display: inline-block; margin-top: 1em; padding: .5em 1.5em; }
+  </style>
+</head>
+<body>
+  <h1>You are leaving via a short link</h1>
+{{- if .Protected}}
+  <p>The link is protected with a password. Its destination is shown only after the password is entered.</p>
+  <form method="post" action="{{.Link}}">
+    <input type="password" name="password" placeholder="Password" required autofocus>
+    <button class="continue" type="submit">Continue</button>
+  </form>
+{{- else}}
+  <p>This link leads to</p>
+  <p class="host">{{.Host}}</p>
+  <p class="url">{{.URL}}</p>
+  <a class="continue" href="{{.Link}}" rel="noreferrer">Continue</a>
+{{- end}}
+</body>
+</html>
+`))
+
+type pageData struct {
+	// Link is the short link itself: following it does the redirect.
+	Link      string
+	URL       string
+	Host      string
+	Protected bool
+}
+
+//go:generate go run github.com/vektra/mockery/v2@latest --name=URLGetter
+type URLGetter interface {
+	GetURL(ctx context.Context, alias string) (storage.URL, error)
+}
+
+// New renders an interstitial page that shows where a short link leads
+// and a "Continue" button instead of redirecting. Nothing is counted until
+// the visitor continues. The destination of password-protected links is not
+// disclosed; the page asks for the password instead. Like redirects, links
+// are not found on hosts other than their short domain.
+func New(log *slog.Logger, urlGetter URLGetter, links *shorturl.Builder) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.url.preview.New"
+
+		log := log.With(
+			slog.String("op", op),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+
+		alias := chi.URLParam(r, "alias")
+		if alias == "" {
+			log.Info("alias is empty")
+			render.Status(r, http.StatusBadRequest)
+			render.JSON(w, r, resp.Error("invalid request"))
+			return
+		}
+
+		u, err := urlGetter.GetURL(r.Context(), alias)
+		if err == nil && !links.Serves(r, u.Domain) {
+			err = storage.ErrUrlNotFound
+		}
+		switch {
+		case errors.Is(err, storage.ErrUrlNotFound):
+			log.Info("url not found", slog.String("alias", alias))
+			render.Status(r, http.StatusNotFound)
+			render.JSON(w, r, resp.Error("url not found"))
+			return
+		case errors.Is(err, storage.ErrUrlBlocked):
+			log.Info("url blocked for legal reasons", slog.String("alias", alias))
+			render.Status(r, http.StatusUnavailableForLegalReasons)
+			render.JSON(w, r, resp.Error("unavailable for legal reasons"))
+			return
+		case errors.Is(err, storage.ErrUrlUnsafe):
+			log.Info("url flagged as unsafe", slog.String("alias", alias))
+			render.Status(r, http.StatusForbidden)
+			render.JSON(w, r, resp.Error("url flagged as unsafe"))
+			return
+		case errors.Is(err, storage.ErrUrlExpired):
+			log.Info("url expired", slog.String("alias", alias))
+			render.Status(r, http.StatusGone)
+			render.JSON(w, r, resp.Error("url expired"))
+			return
+		case errors.Is(err, storage.ErrUrlExhausted):
+			log.Info("url click limit reached", slog.String("alias", alias))
+			render.Status(r, http.StatusGone)
+			render.JSON(w, r, resp.Error("url click limit reached"))
+			return
+		case err != nil:
+			log.Error("failed to get url", sl.Err(err))
+			render.Status(r, http.StatusInternalServerError)
+			render.JSON(w, r, resp.Error("internal error"))
+			return
+		}
+
+		data := pageData{
+			Link:      "/" + url.PathEscape(alias),
+			Protected: u.PasswordHash != "",
+		}
+		if !data.Protected {
+			data.URL = u.URL
+			if parsed, err := url.Parse(u.URL); err == nil {
+				data.Host = parsed.Hostname()
+			}
+		}
+
+		log.Info("preview shown", slog.String("alias", alias))
+
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		w.Header().Set("Cache-Control", "no-store")
+		if err := page.Execute(w, data); err != nil {
+			log.Error("failed to render preview", sl.Err(err))
+		}
+	}
+}
+
+// Query serves preview instead of the wrapped handler for requests with
+// ?preview=1, so that GET /{alias}?preview=1 works like GET /{alias}+.
+func Query(preview http.Handler) func(next http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.URL.Query().Get("preview") == "1" {
+				preview.ServeHTTP(w, r)
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
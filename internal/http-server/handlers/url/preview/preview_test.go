@@ -0,0 +1,119 @@
+package preview_test
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"url-shortener/internal/http-server/handlers/url/preview"
+	"url-shortener/internal/http-server/handlers/url/preview/mocks"
+	"url-shortener/internal/lib/logger/handlers/slogdiscard"
+	"url-shortener/internal/lib/shorturl"
+	"url-shortener/internal/storage"
+)
+
+func TestPreviewHandler(t *testing.T) {
+	const alias = "test_alias"
+
+	cases := []struct {
+		name      string
+		url       storage.URL
+		mockError error
+		wantCode  int
+		contains  []string
+		excludes  []string
+	}{
+		{
+			name:     "Success",
+			url:      storage.URL{Alias: alias, URL: "https://example.com/a?b=<c>"},
+			wantCode: http.StatusOK,
+			contains: []string{"example.com", "https://example.com/a?b=&lt;c&gt;", `href="/test_alias"`},
+		},
+		{
+			name:     "Protected",
+			url:      storage.URL{Alias: alias, URL: "https://secret.example.com", PasswordHash: "hash"},
+			wantCode: http.StatusOK,
+			contains: []string{`action="/test_alias"`, `name="password"`},
+			excludes: []string{"secret.example.com"},
+		},
+		{
+			name:      "Not found",
+			mockError: storage.ErrUrlNotFound,
+			wantCode:  http.StatusNotFound,
+		},
+		{
+			name:      "Expired",
+			mockError: storage.ErrUrlExpired,
+			wantCode:  http.StatusGone,
+		},
+		{
+			name:      "Blocked",
+			mockError: storage.ErrUrlBlocked,
+			wantCode:  http.StatusUnavailableForLegalReasons,
+		},
+		{
+			name:      "GetURL Error",
+			mockError: errors.New("unexpected error"),
+			wantCode:  http.StatusInternalServerError,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			urlGetterMock := mocks.NewURLGetter(t)
+			urlGetterMock.On("GetURL", mock.Anything, alias).Return(tc.url, tc.mockError).Once()
+
+			r := chi.NewRouter()
+			r.Get("/{alias}+", preview.New(slogdiscard.NewDiscardLogger(), urlGetterMock, newLinks(t)))
+
+			rr := httptest.NewRecorder()
+			r.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/"+alias+"+", nil))
+
+			require.Equal(t, tc.wantCode, rr.Code)
+
+			body := rr.Body.String()
+			for _, s := range tc.contains {
+				require.Contains(t, body, s)
+			}
+			for _, s := range tc.excludes {
+				require.NotContains(t, body, s)
+			}
+		})
+	}
+}
+
+func TestQuery(t *testing.T) {
+	previewHandler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+	redirectHandler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusFound)
+	})
+
+	r := chi.NewRouter()
+	r.With(preview.Query(previewHandler)).Get("/{alias}", redirectHandler)
+
+	for query, want := range map[string]int{
+		"":           http.StatusFound,
+		"?preview=1": http.StatusOK,
+		"?preview=0": http.StatusFound,
+	} {
+		rr := httptest.NewRecorder()
+		r.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/alias"+query, nil))
+		require.Equal(t, want, rr.Code, query)
+	}
+}
+
+func newLinks(t *testing.T) *shorturl.Builder {
+	t.Helper()
+
+	links, err := shorturl.New("", nil)
+	require.NoError(t, err)
+
+	return links
+}
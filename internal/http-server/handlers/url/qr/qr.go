@@ -0,0 +1,161 @@
+package qr
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+	resp "url-shortener/internal/lib/api/response"
+	"url-shortener/internal/lib/logger/sl"
+	"url-shortener/internal/storage"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/go-chi/render"
+	"github.com/skip2/go-qrcode"
+)
+
+const (
+	defaultSize = 256
+	minSize     = 64
+	maxSize     = 1024
+
+	formatPNG = "png"
+	formatSVG = "svg"
+)
+
+//go:generate go run github.com/vektra/mockery/v2@latest --name=URLGetter
+type URLGetter interface {
+	GetURL(ctx context.Context, alias string) (storage.URL, error)
+}
+
+// New renders a QR code that points at the short URL of an alias.
+//
+// Query params: size in pixels (default 256, 64..1024) and format=png|svg
+// (png by default).
+func New(log *slog.Logger, urlGetter URLGetter) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.url.qr.New"
+
+		log := log.With(
+			slog.String("op", op),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+
+		alias := chi.URLParam(r, "alias")
+		if alias == "" {
+			log.Info("alias is empty")
+			render.Status(r, http.StatusBadRequest)
+			render.JSON(w, r, resp.Error("invalid request"))
+			return
+		}
+
+		query := r.URL.Query()
+
+		size := defaultSize
+		if v := query.Get("size"); v != "" {
+			n, err := strconv.Atoi(v)
+			if err != nil || n < minSize || n > maxSize {
+				log.Info("invalid size", slog.String("size", v))
+				render.Status(r, http.StatusBadRequest)
+				render.JSON(w, r, resp.Error("invalid size"))
+				return
+			}
+			size = n
+		}
+
+		format := query.Get("format")
+		if format == "" {
+			format = formatPNG
+		}
+		if format != formatPNG && format != formatSVG {
+			log.Info("invalid format", slog.String("format", format))
+			render.Status(r, http.StatusBadRequest)
+			render.JSON(w, r, resp.Error("invalid format"))
+			return
+		}
+
+		// Blocked or expired links still get a code: it is the redirect
+		// that decides what the visitor sees.
+		_, err := urlGetter.GetURL(r.Context(), alias)
+		if errors.Is(err, storage.ErrUrlNotFound) {
+			log.Info("url not found", slog.String("alias", alias))
+			render.Status(r, http.StatusNotFound)
+			render.JSON(w, r, resp.Error("url not found"))
+			return
+		}
+		if err != nil &&
+			!errors.Is(err, storage.ErrUrlBlocked) &&
+			!errors.Is(err, storage.ErrUrlUnsafe) &&
+			!errors.Is(err, storage.ErrUrlExpired) &&
+			!errors.Is(err, storage.ErrUrlExhausted) {
+			log.Error("failed to get url", sl.Err(err))
+			render.Status(r, http.StatusInternalServerError)
+			render.JSON(w, r, resp.Error("internal error"))
+			return
+		}
+
+		code, err := qrcode.New(shortURL(r, alias), qrcode.Medium)
+		if err != nil {
+			log.Error("failed to encode qr code", sl.Err(err))
+			render.Status(r, http.StatusInternalServerError)
+			render.JSON(w, r, resp.Error("internal error"))
+			return
+		}
+
+		if format == formatSVG {
+			w.Header().Set("Content-Type", "image/svg+xml")
+			_, _ = w.Write([]byte(svg(code.Bitmap(), size)))
+			return
+		}
+
+		png, err := code.PNG(size)
+		if err != nil {
+			log.Error("failed to render qr code", sl.Err(err))
+			render.Status(r, http.StatusInternalServerError)
+			render.JSON(w, r, resp.Error("internal error"))
+			return
+		}
+
+		w.Header().Set("Content-Type", "image/png")
+		_, _ = w.Write(png)
+	}
+}
+
+// shortURL builds the public link for alias from the incoming request.
+func shortURL(r *http.Request, alias string) string {
+	scheme := "http"
+	if r.TLS != nil {
+		scheme = "https"
+	}
+	if proto := r.Header.Get("X-Forwarded-Proto"); proto != "" {
+		scheme = proto
+	}
+
+	return fmt.Sprintf("%s://%s/%s", scheme, r.Host, alias)
+}
+
+// svg draws the QR bitmap (quiet zone included) as one path of unit squares
+// scaled to size pixels.
+func svg(bitmap [][]bool, size int) string {
+	var b strings.Builder
+
+	fmt.Fprintf(&b,
+		`<svg xmlns="http://www.w3.org/2000/svg" width="%[1]d" height="%[1]d" viewBox="0 0 %[2]d %[2]d" shape-rendering="crispEdges">`,
+		size, len(bitmap),
+	)
+	fmt.Fprintf(&b, `<rect width="100%%" height="100%%" fill="#fff"/><path fill="#000" d="`)
+	for y, row := range bitmap {
+		for x, black := range row {
+			if black {
+				fmt.Fprintf(&b, "M%d %dh1v1h-1z", x, y)
+			}
+		}
+	}
+	b.WriteString(`"/></svg>`)
+
+	return b.String()
+}
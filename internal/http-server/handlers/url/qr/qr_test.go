@@ -0,0 +1,110 @@
+package qr_test
+
+import (
+	"bytes"
+	"errors"
+	"image/png"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"url-shortener/internal/http-server/handlers/url/qr"
+	"url-shortener/internal/http-server/handlers/url/qr/mocks"
+	"url-shortener/internal/lib/logger/handlers/slogdiscard"
+	"url-shortener/internal/storage"
+)
+
+func TestQRHandler(t *testing.T) {
+	cases := []struct {
+		name        string
+		query       string
+		mockError   error
+		skipMock    bool
+		wantCode    int
+		contentType string
+	}{
+		{
+			name:        "PNG",
+			query:       "?size=128",
+			wantCode:    http.StatusOK,
+			contentType: "image/png",
+		},
+		{
+			name:        "SVG",
+			query:       "?format=svg",
+			wantCode:    http.StatusOK,
+			contentType: "image/svg+xml",
+		},
+		{
+			name:        "Blocked link",
+			mockError:   storage.ErrUrlBlocked,
+			wantCode:    http.StatusOK,
+			contentType: "image/png",
+		},
+		{
+			name:      "Not found",
+			mockError: storage.ErrUrlNotFound,
+			wantCode:  http.StatusNotFound,
+		},
+		{
+			name:      "GetURL Error",
+			mockError: errors.New("unexpected error"),
+			wantCode:  http.StatusInternalServerError,
+		},
+		{
+			name:     "Invalid size",
+			query:    "?size=5000",
+			skipMock: true,
+			wantCode: http.StatusBadRequest,
+		},
+		{
+			name:     "Invalid format",
+			query:    "?format=gif",
+			skipMock: true,
+			wantCode: http.StatusBadRequest,
+		},
+	}
+
+	const alias = "test_alias"
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			urlGetterMock := mocks.NewURLGetter(t)
+
+			if !tc.skipMock {
+				urlGetterMock.On("GetURL", mock.Anything, alias).
+					Return(storage.URL{Alias: alias, URL: "https://google.com"}, tc.mockError).Once()
+			}
+
+			r := chi.NewRouter()
+			r.Get("/url/{alias}/qr", qr.New(slogdiscard.NewDiscardLogger(), urlGetterMock))
+
+			req := httptest.NewRequest(http.MethodGet, "/url/"+alias+"/qr"+tc.query, nil)
+			rr := httptest.NewRecorder()
+			r.ServeHTTP(rr, req)
+
+			require.Equal(t, tc.wantCode, rr.Code)
+
+			if tc.contentType == "" {
+				return
+			}
+
+			require.Equal(t, tc.contentType, rr.Header().Get("Content-Type"))
+
+			if tc.contentType == "image/png" {
+				img, err := png.Decode(bytes.NewReader(rr.Body.Bytes()))
+				require.NoError(t, err)
+				if strings.Contains(tc.query, "size=128") {
+					require.Equal(t, 128, img.Bounds().Dx())
+				}
+			} else {
+				require.True(t, strings.HasPrefix(rr.Body.String(), "<svg"))
+			}
+		})
+	}
+}
@@ -2,7 +2,13 @@
 
 package mocks
 
-import mock "github.com/stretchr/testify/mock"
+import (
+	context "context"
+
+	mock "github.com/stretchr/testify/mock"
+
+	storage "url-shortener/internal/storage"
+)
 
 // URLGetter is an autogenerated mock type for the URLGetter type
 type URLGetter struct {
@@ -17,27 +23,84 @@ func (_m *URLGetter) EXPECT() *URLGetter_Expecter {
 	return &URLGetter_Expecter{mock: &_m.Mock}
 }
 
-// GetURL provides a mock function with given fields: alias
-func (_m *URLGetter) GetURL(alias string) (string, error) {
-	ret := _m.Called(alias)
+// GetLegalBlock provides a mock function with given fields: ctx, alias
+func (_m *URLGetter) GetLegalBlock(ctx context.Context, alias string) (storage.LegalBlock, error) {
+	ret := _m.Called(ctx, alias)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetLegalBlock")
+	}
+
+	var r0 storage.LegalBlock
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) (storage.LegalBlock, error)); ok {
+		return rf(ctx, alias)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string) storage.LegalBlock); ok {
+		r0 = rf(ctx, alias)
+	} else {
+		r0 = ret.Get(0).(storage.LegalBlock)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, alias)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// URLGetter_GetLegalBlock_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GetLegalBlock'
+type URLGetter_GetLegalBlock_Call struct {
+	*mock.Call
+}
+
+// GetLegalBlock is a helper method to define mock.On call
+//   - ctx context.Context
+//   - alias string
+func (_e *URLGetter_Expecter) GetLegalBlock(ctx interface{}, alias interface{}) *URLGetter_GetLegalBlock_Call {
+	return &URLGetter_GetLegalBlock_Call{Call: _e.mock.On("GetLegalBlock", ctx, alias)}
+}
+
+func (_c *URLGetter_GetLegalBlock_Call) Run(run func(ctx context.Context, alias string)) *URLGetter_GetLegalBlock_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(string))
+	})
+	return _c
+}
+
+func (_c *URLGetter_GetLegalBlock_Call) Return(_a0 storage.LegalBlock, _a1 error) *URLGetter_GetLegalBlock_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *URLGetter_GetLegalBlock_Call) RunAndReturn(run func(context.Context, string) (storage.LegalBlock, error)) *URLGetter_GetLegalBlock_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// GetURL provides a mock function with given fields: ctx, alias
+func (_m *URLGetter) GetURL(ctx context.Context, alias string) (storage.URL, error) {
+	ret := _m.Called(ctx, alias)
 
 	if len(ret) == 0 {
 		panic("no return value specified for GetURL")
 	}
 
-	var r0 string
+	var r0 storage.URL
 	var r1 error
-	if rf, ok := ret.Get(0).(func(string) (string, error)); ok {
-		return rf(alias)
+	if rf, ok := ret.Get(0).(func(context.Context, string) (storage.URL, error)); ok {
+		return rf(ctx, alias)
 	}
-	if rf, ok := ret.Get(0).(func(string) string); ok {
-		r0 = rf(alias)
+	if rf, ok := ret.Get(0).(func(context.Context, string) storage.URL); ok {
+		r0 = rf(ctx, alias)
 	} else {
-		r0 = ret.Get(0).(string)
+		r0 = ret.Get(0).(storage.URL)
 	}
 
-	if rf, ok := ret.Get(1).(func(string) error); ok {
-		r1 = rf(alias)
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, alias)
 	} else {
 		r1 = ret.Error(1)
 	}
@@ -51,24 +114,25 @@ type URLGetter_GetURL_Call struct {
 }
 
 // GetURL is a helper method to define mock.On call
+//   - ctx context.Context
 //   - alias string
-func (_e *URLGetter_Expecter) GetURL(alias interface{}) *URLGetter_GetURL_Call {
-	return &URLGetter_GetURL_Call{Call: _e.mock.On("GetURL", alias)}
+func (_e *URLGetter_Expecter) GetURL(ctx interface{}, alias interface{}) *URLGetter_GetURL_Call {
+	return &URLGetter_GetURL_Call{Call: _e.mock.On("GetURL", ctx, alias)}
 }
 
-func (_c *URLGetter_GetURL_Call) Run(run func(alias string)) *URLGetter_GetURL_Call {
+func (_c *URLGetter_GetURL_Call) Run(run func(ctx context.Context, alias string)) *URLGetter_GetURL_Call {
 	_c.Call.Run(func(args mock.Arguments) {
-		run(args[0].(string))
+		run(args[0].(context.Context), args[1].(string))
 	})
 	return _c
 }
 
-func (_c *URLGetter_GetURL_Call) Return(_a0 string, _a1 error) *URLGetter_GetURL_Call {
+func (_c *URLGetter_GetURL_Call) Return(_a0 storage.URL, _a1 error) *URLGetter_GetURL_Call {
 	_c.Call.Return(_a0, _a1)
 	return _c
 }
 
-func (_c *URLGetter_GetURL_Call) RunAndReturn(run func(string) (string, error)) *URLGetter_GetURL_Call {
+func (_c *URLGetter_GetURL_Call) RunAndReturn(run func(context.Context, string) (storage.URL, error)) *URLGetter_GetURL_Call {
 	_c.Call.Return(run)
 	return _c
 }
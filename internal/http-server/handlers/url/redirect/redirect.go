@@ -1,24 +1,157 @@
 package redirect
 
 import (
+	"context"
 	"errors"
+	"fmt"
+	"html/template"
 	"log/slog"
+	"math/rand/v2"
+	"net"
 	"net/http"
+	"time"
 	"url-shortener/internal/lib/api/response"
+	"url-shortener/internal/lib/device"
+	"url-shortener/internal/lib/errorpage"
 	"url-shortener/internal/lib/logger/sl"
+	"url-shortener/internal/lib/shorturl"
+	"url-shortener/internal/lib/utm"
 	"url-shortener/internal/storage"
 
 	"github.com/go-chi/chi/v5"
 	"github.com/go-chi/chi/v5/middleware"
 	"github.com/go-chi/render"
+	"golang.org/x/crypto/bcrypt"
 )
 
+// LegalBlockResponse is returned with 451 for aliases taken down for legal reasons.
+type LegalBlockResponse struct {
+	response.Response
+	Reason    string `json:"reason,omitempty"`
+	Reference string `json:"reference,omitempty"`
+	NoticeURL string `json:"notice_url,omitempty"`
+}
+
+// Fallbacks are pages visitors are redirected to instead of getting an
+// error; empty fields keep the error.
+type Fallbacks struct {
+	// Errors, if set, renders errors as HTML pages for clients that ask
+	// for HTML, i.e. browsers; API clients keep getting JSON.
+	Errors ErrorPages
+	// NotActive receives visitors of links whose activation window has
+	// not started yet.
+	NotActive string
+	// Expired receives visitors of expired links and of links whose
+	// activation window is over.
+	Expired string
+}
+
 //go:generate go run github.com/vektra/mockery/v2@latest --name=URLGetter
 type URLGetter interface {
-	GetURL(alias string) (string, error)
+	GetURL(ctx context.Context, alias string) (storage.URL, error)
+	GetLegalBlock(ctx context.Context, alias string) (storage.LegalBlock, error)
+}
+
+//go:generate go run github.com/vektra/mockery/v2@latest --name=ClickSaver
+type ClickSaver interface {
+	SaveClick(ctx context.Context, click storage.Click) error
+}
+
+//go:generate go run github.com/vektra/mockery/v2@latest --name=ClickLimiter
+type ClickLimiter interface {
+	ConsumeClick(ctx context.Context, alias string) error
+}
+
+//go:generate go run github.com/vektra/mockery/v2@latest --name=ErrorPages
+type ErrorPages interface {
+	Render(w http.ResponseWriter, status int, alias string, message string) error
+}
+
+//go:generate go run github.com/vektra/mockery/v2@latest --name=Locator
+type Locator interface {
+	Locate(ip net.IP) (country string, city string, err error)
+}
+
+//go:generate go run github.com/vektra/mockery/v2@latest --name=BotDetector
+type BotDetector interface {
+	IsBot(userAgent string) bool
+	IsSocialCrawler(userAgent string) bool
 }
 
-func New(log *slog.Logger, urlGetter URLGetter) http.HandlerFunc {
+var previewPage = template.Must(template.New("preview").Parse(`<!DOCTYPE html>
+<html>
+<head>
+  <meta charset="utf-8">
+  <meta name="robots" content="noindex">
+  <title>{{.Title}}</title>
+  <meta property="og:type" content="website">
+  <meta property="og:url" content="{{.URL}}">
+{{- with .Title}}
+  <meta property="og:title" content="{{.}}">
+  <meta name="twitter:title" content="{{.}}">
+{{- end}}
+{{- with .Description}}
+  <meta name="description" content="{{.}}">
+  <meta property="og:description" content="{{.}}">
+  <meta name="twitter:description" content="{{.}}">
+{{- end}}
+{{- with .Image}}
+  <meta property="og:image" content="{{.}}">
+  <meta name="twitter:image" content="{{.}}">
+  <meta name="twitter:card" content="summary_large_image">
+{{- else}}
+  <meta name="twitter:card" content="summary">
+{{- end}}
+</head>
+<body>
+  <a href="{{.URL}}">{{or .Title .URL}}</a>
+</body>
+</html>
+`))
+
+// preview is what previewPage is executed with.
+type preview struct {
+	storage.Metadata
+	// URL is the short link itself.
+	URL string
+}
+
+// New returns the redirect handler. Every successful redirect is recorded
+// with clickSaver; links with max_clicks are counted by clickLimiter first.
+// Password-protected links answer 401 until the password is passed in the
+// "password" query param or POST form field. noticeURL, if set, is
+// advertised to clients of legally blocked aliases (RFC 7725 "blocked-by"
+// link). redirectType is the status used for links without their own
+// redirect type, and cacheMaxAge how long redirects of links without a
+// cache lifetime of their own may be cached. Visitors of links with device
+// targets are sent to the target for their kind of device; otherwise
+// visitors of links with geo targets are sent to the target for the country
+// locator resolves their address to. Without locator geo targets are
+// ignored. The rest of the visitors of split-test links are spread between
+// the link's variants by weight, and their clicks record the variant
+// served. Clicks record the visitor's country and city, if locator knows
+// them, and are marked as those of bots if bots, when set, says so by the
+// User-Agent. Links outside their activation window, like expired links,
+// answer with an error or redirect to the matching page of fallbacks.
+// Browsers get errors for unknown and unavailable links as HTML pages
+// rendered by fallbacks.Errors. Links are not found on hosts other than the
+// short domain links assigns them to. Social network crawlers, as told by
+// bots, get a page with the Open Graph tags of links that have them instead
+// of the redirect, before the password and click limit are checked; these
+// requests are not counted as clicks.
+func New(
+	log *slog.Logger,
+	urlGetter URLGetter,
+	clickSaver ClickSaver,
+	clickLimiter ClickLimiter,
+	noticeURL string,
+	redirectType int,
+	cacheMaxAge time.Duration,
+	locator Locator,
+	bots BotDetector,
+	fallbacks Fallbacks,
+	links *shorturl.Builder,
+) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		const op = "handlers.url.redirect.New"
 
@@ -34,20 +167,298 @@ func New(log *slog.Logger, urlGetter URLGetter) http.HandlerFunc {
 			return
 		}
 
-		resURL, err := urlGetter.GetURL(alias)
+		u, err := urlGetter.GetURL(r.Context(), alias)
+		if err == nil && !links.Serves(r, u.Domain) {
+			log.Info("url is on another short domain", slog.String("alias", alias), slog.String("host", r.Host))
+			err = storage.ErrUrlNotFound
+		}
 		if err != nil {
 			if errors.Is(err, storage.ErrUrlNotFound) {
 				log.Info("Url not found", "alias", alias)
+				if htmlPage(log, w, r, fallbacks.Errors, http.StatusNotFound, alias, "url not found") {
+					return
+				}
 				render.JSON(w, r, response.Error("Url not found"))
 				return
 			}
 
+			if errors.Is(err, storage.ErrUrlBlocked) {
+				legalBlock(log, w, r, urlGetter, alias, noticeURL, fallbacks.Errors)
+				return
+			}
+
+			if errors.Is(err, storage.ErrUrlUnsafe) {
+				log.Info("url flagged as unsafe", slog.String("alias", alias))
+				fail(log, w, r, fallbacks.Errors, http.StatusForbidden, alias, "url flagged as unsafe")
+				return
+			}
+
+			if errors.Is(err, storage.ErrUrlExpired) {
+				expired(log, w, r, alias, fallbacks)
+				return
+			}
+
+			if errors.Is(err, storage.ErrUrlExhausted) {
+				exhausted(log, w, r, alias, fallbacks.Errors)
+				return
+			}
+
 			log.Error("faild to get url", sl.Err(err))
 			render.JSON(w, r, response.Error("internal error"))
 			return
 		}
 
-		log.Info("Got url", slog.String("url", resURL))
-		http.Redirect(w, r, resURL, http.StatusFound)
+		now := time.Now()
+		if u.NotActiveYet(now) {
+			log.Info("url not active yet", slog.String("alias", alias))
+			if fallbacks.NotActive != "" {
+				http.Redirect(w, r, fallbacks.NotActive, http.StatusFound)
+				return
+			}
+			fail(log, w, r, fallbacks.Errors, http.StatusForbidden, alias, "url is not active yet")
+			return
+		}
+		if u.Ended(now) {
+			expired(log, w, r, alias, fallbacks)
+			return
+		}
+
+		branded := u.OpenGraph != (storage.Metadata{})
+		if branded || len(u.DeviceTargets) > 0 {
+			// The same link leads elsewhere on another device, and
+			// crawlers get a preview instead of the redirect.
+			w.Header().Add("Vary", "User-Agent")
+		}
+		if branded && bots != nil && bots.IsSocialCrawler(r.UserAgent()) {
+			log.Info("preview served to social crawler", slog.String("alias", alias))
+			w.Header().Set("Content-Type", "text/html; charset=utf-8")
+			err := previewPage.Execute(w, preview{Metadata: u.OpenGraph, URL: links.URL(r, u.Domain, alias)})
+			if err != nil {
+				log.Error("failed to render preview", sl.Err(err))
+			}
+			return
+		}
+
+		if u.PasswordHash != "" {
+			// Only a form body is read: a password in the query string
+			// would end up in access logs, proxies and browser history.
+			password := r.PostFormValue("password")
+			if password == "" {
+				log.Info("password required", slog.String("alias", alias))
+				render.Status(r, http.StatusUnauthorized)
+				render.JSON(w, r, response.Error("password required"))
+				return
+			}
+
+			if bcrypt.CompareHashAndPassword([]byte(u.PasswordHash), []byte(password)) != nil {
+				log.Info("invalid password", slog.String("alias", alias))
+				render.Status(r, http.StatusUnauthorized)
+				render.JSON(w, r, response.Error("invalid password"))
+				return
+			}
+		}
+
+		if u.MaxClicks > 0 {
+			// GetURL may race with other requests, so the limit is
+			// enforced by the atomic counter.
+			err := clickLimiter.ConsumeClick(r.Context(), alias)
+			if errors.Is(err, storage.ErrUrlExhausted) || errors.Is(err, storage.ErrUrlNotFound) {
+				exhausted(log, w, r, alias, fallbacks.Errors)
+				return
+			}
+			if err != nil {
+				log.Error("failed to consume click", sl.Err(err))
+				render.JSON(w, r, response.Error("internal error"))
+				return
+			}
+		}
+
+		log.Info("Got url", slog.String("url", u.URL))
+
+		country, city := locate(log, r, locator)
+
+		target, variant := u.URL, ""
+		if t, ok := device.Target(u.DeviceTargets, r.UserAgent()); ok {
+			log.Info("device target chosen")
+			target = t
+		} else if t, ok := u.GeoTargets[country]; ok && country != "" {
+			log.Info("geo target chosen", slog.String("country", country))
+			target = t
+		} else if total := u.Variants.TotalWeight(); total > 0 {
+			v := u.Variants.Pick(rand.IntN(total))
+			log.Info("variant chosen", slog.String("variant", v.Name))
+			target, variant = v.URL, v.Name
+		}
+
+		err = clickSaver.SaveClick(r.Context(), storage.Click{
+			Alias:     alias,
+			ClickedAt: time.Now(),
+			Referrer:  r.Referer(),
+			UserAgent: r.UserAgent(),
+			Variant:   variant,
+			Bot:       bots != nil && bots.IsBot(r.UserAgent()),
+			Country:   country,
+			City:      city,
+		})
+		if err != nil {
+			// Losing a click must not break the redirect itself.
+			log.Error("failed to save click", sl.Err(err))
+		}
+
+		code := redirectType
+		if u.RedirectType != 0 {
+			code = u.RedirectType
+		}
+
+		if cc := cacheControl(u, cacheMaxAge, now); cc != "" {
+			w.Header().Set("Cache-Control", cc)
+		}
+
+		http.Redirect(w, r, utm.Append(target, alias, u.UTM), code)
+	}
+}
+
+// cacheControl returns the Cache-Control header for a redirect of u, ""
+// for none. Links that have to see every visit, to count it against
+// max_clicks, to check the password or to pick a target for the visitor,
+// are never cached, and the rest are not cached past their expiry.
+func cacheControl(u storage.URL, cacheMaxAge time.Duration, now time.Time) string {
+	maxAge := cacheMaxAge
+	if u.CacheMaxAge != 0 {
+		maxAge = time.Duration(u.CacheMaxAge) * time.Second
+	}
+	if maxAge == 0 {
+		return ""
+	}
+
+	if maxAge < 0 || u.MaxClicks > 0 || u.PasswordHash != "" ||
+		len(u.DeviceTargets) > 0 || len(u.GeoTargets) > 0 || len(u.Variants) > 0 {
+		return "no-store"
+	}
+	for _, end := range []time.Time{u.ExpiresAt, u.ActiveUntil} {
+		if !end.IsZero() {
+			maxAge = min(maxAge, end.Sub(now))
+		}
+	}
+	if maxAge < time.Second {
+		return "no-store"
+	}
+
+	return fmt.Sprintf("public, max-age=%d", int64(maxAge/time.Second))
+}
+
+// locate resolves the visitor's country and city; both are "" without
+// locator or if it does not know the address.
+func locate(log *slog.Logger, r *http.Request, locator Locator) (string, string) {
+	if locator == nil {
+		return "", ""
+	}
+
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		host = r.RemoteAddr
 	}
+
+	ip := net.ParseIP(host)
+	if ip == nil {
+		return "", ""
+	}
+
+	country, city, err := locator.Locate(ip)
+	if err != nil {
+		log.Error("failed to resolve location", sl.Err(err))
+		return "", ""
+	}
+
+	return country, city
+}
+
+func expired(log *slog.Logger, w http.ResponseWriter, r *http.Request, alias string, fallbacks Fallbacks) {
+	log.Info("url expired", slog.String("alias", alias))
+	if fallbacks.Expired != "" {
+		http.Redirect(w, r, fallbacks.Expired, http.StatusFound)
+		return
+	}
+	fail(log, w, r, fallbacks.Errors, http.StatusGone, alias, "url expired")
+}
+
+func exhausted(log *slog.Logger, w http.ResponseWriter, r *http.Request, alias string, pages ErrorPages) {
+	log.Info("url click limit reached", slog.String("alias", alias))
+	fail(log, w, r, pages, http.StatusGone, alias, "url click limit reached")
+}
+
+// fail answers with an error page or, for API clients, the JSON error.
+func fail(
+	log *slog.Logger,
+	w http.ResponseWriter,
+	r *http.Request,
+	pages ErrorPages,
+	status int,
+	alias string,
+	message string,
+) {
+	if htmlPage(log, w, r, pages, status, alias, message) {
+		return
+	}
+	render.Status(r, status)
+	render.JSON(w, r, response.Error(message))
+}
+
+// htmlPage renders the error page if there are pages and the client
+// prefers HTML; false means the caller has to answer with JSON.
+func htmlPage(
+	log *slog.Logger,
+	w http.ResponseWriter,
+	r *http.Request,
+	pages ErrorPages,
+	status int,
+	alias string,
+	message string,
+) bool {
+	if pages == nil || !errorpage.Accepts(r) {
+		return false
+	}
+	if err := pages.Render(w, status, alias, message); err != nil {
+		// The status is already sent, so there is nothing else to do.
+		log.Error("failed to render error page", sl.Err(err))
+	}
+	return true
+}
+
+func legalBlock(
+	log *slog.Logger,
+	w http.ResponseWriter,
+	r *http.Request,
+	urlGetter URLGetter,
+	alias string,
+	noticeURL string,
+	pages ErrorPages,
+) {
+	block, err := urlGetter.GetLegalBlock(r.Context(), alias)
+	if err != nil {
+		log.Error("failed to get legal block", sl.Err(err))
+		render.JSON(w, r, response.Error("internal error"))
+		return
+	}
+
+	log.Info("url blocked for legal reasons",
+		slog.String("alias", alias),
+		slog.String("reference", block.Reference),
+	)
+
+	if noticeURL != "" {
+		w.Header().Set("Link", fmt.Sprintf("<%s>; rel=\"blocked-by\"", noticeURL))
+	}
+
+	if htmlPage(log, w, r, pages, http.StatusUnavailableForLegalReasons, alias, "unavailable for legal reasons") {
+		return
+	}
+
+	render.Status(r, http.StatusUnavailableForLegalReasons)
+	render.JSON(w, r, LegalBlockResponse{
+		Response:  response.Error("unavailable for legal reasons"),
+		Reason:    block.Reason,
+		Reference: block.Reference,
+		NoticeURL: noticeURL,
+	})
 }
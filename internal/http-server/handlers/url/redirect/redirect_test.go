@@ -1,44 +1,69 @@
 package redirect_test
 
 import (
+	"encoding/json"
+	"errors"
+	"net"
+	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/bcrypt"
 
 	"url-shortener/internal/http-server/handlers/url/redirect"
 	"url-shortener/internal/http-server/handlers/url/redirect/mocks"
 	"url-shortener/internal/lib/api"
+	"url-shortener/internal/lib/api/response"
 	"url-shortener/internal/lib/logger/handlers/slogdiscard"
+	"url-shortener/internal/lib/shorturl"
+	"url-shortener/internal/storage"
 )
 
 func TestRedirectHandler(t *testing.T) {
 	cases := []struct {
-		name      string
-		alias     string
-		url       string
-		respError string
-		mockError error
+		name       string
+		alias      string
+		url        string
+		respError  string
+		mockError  error
+		clickError error
 	}{
 		{
 			name:  "Success",
 			alias: "test_alias",
 			url:   "http://google.com",
 		},
+		{
+			name:       "SaveClick Error",
+			alias:      "test_alias",
+			url:        "http://google.com",
+			clickError: errors.New("unexpected error"),
+		},
 	}
 
 	for _, tc := range cases {
 		t.Run(tc.name, func(t *testing.T) {
 			urlGetterMock := mocks.NewURLGetter(t)
+			clickSaverMock := mocks.NewClickSaver(t)
 
 			if tc.respError == "" || tc.mockError != nil {
-				urlGetterMock.On("GetURL", tc.alias).
-					Return(tc.url, tc.mockError).Once()
+				urlGetterMock.On("GetURL", mock.Anything, tc.alias).
+					Return(storage.URL{Alias: tc.alias, URL: tc.url}, tc.mockError).Once()
+			}
+
+			if tc.respError == "" {
+				clickSaverMock.On("SaveClick", mock.Anything, mock.MatchedBy(func(c storage.Click) bool {
+					return c.Alias == tc.alias && !c.ClickedAt.IsZero()
+				})).Return(tc.clickError).Once()
 			}
 
 			r := chi.NewRouter()
-			r.Get("/{alias}", redirect.New(slogdiscard.NewDiscardLogger(), urlGetterMock))
+			r.Get("/{alias}", redirect.New(slogdiscard.NewDiscardLogger(), urlGetterMock, clickSaverMock, mocks.NewClickLimiter(t), "", http.StatusFound, 0, nil, nil, redirect.Fallbacks{}, newLinks(t)))
 
 			ts := httptest.NewServer(r)
 			defer ts.Close()
@@ -50,3 +75,819 @@ func TestRedirectHandler(t *testing.T) {
 		})
 	}
 }
+
+func TestRedirectHandler_LegallyBlocked(t *testing.T) {
+	const (
+		alias     = "blocked_alias"
+		noticeURL = "https://example.com/legal/notices"
+	)
+
+	urlGetterMock := mocks.NewURLGetter(t)
+	urlGetterMock.On("GetURL", mock.Anything, alias).
+		Return(storage.URL{}, storage.ErrUrlBlocked).Once()
+	urlGetterMock.On("GetLegalBlock", mock.Anything, alias).
+		Return(storage.LegalBlock{Reason: "DMCA takedown", Reference: "case-42"}, nil).Once()
+
+	r := chi.NewRouter()
+	r.Get("/{alias}", redirect.New(slogdiscard.NewDiscardLogger(), urlGetterMock, mocks.NewClickSaver(t), mocks.NewClickLimiter(t), noticeURL, http.StatusFound, 0, nil, nil, redirect.Fallbacks{}, newLinks(t)))
+
+	req := httptest.NewRequest(http.MethodGet, "/"+alias, nil)
+	rr := httptest.NewRecorder()
+	r.ServeHTTP(rr, req)
+
+	require.Equal(t, http.StatusUnavailableForLegalReasons, rr.Code)
+	require.Equal(t, `<`+noticeURL+`>; rel="blocked-by"`, rr.Header().Get("Link"))
+
+	var resp redirect.LegalBlockResponse
+	require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &resp))
+
+	require.Equal(t, "DMCA takedown", resp.Reason)
+	require.Equal(t, "case-42", resp.Reference)
+	require.Equal(t, noticeURL, resp.NoticeURL)
+}
+
+func TestRedirectHandler_Unsafe(t *testing.T) {
+	const alias = "unsafe_alias"
+
+	urlGetterMock := mocks.NewURLGetter(t)
+	urlGetterMock.On("GetURL", mock.Anything, alias).
+		Return(storage.URL{}, storage.ErrUrlUnsafe).Once()
+
+	r := chi.NewRouter()
+	r.Get("/{alias}", redirect.New(slogdiscard.NewDiscardLogger(), urlGetterMock, mocks.NewClickSaver(t), mocks.NewClickLimiter(t), "", http.StatusFound, 0, nil, nil, redirect.Fallbacks{}, newLinks(t)))
+
+	req := httptest.NewRequest(http.MethodGet, "/"+alias, nil)
+	rr := httptest.NewRecorder()
+	r.ServeHTTP(rr, req)
+
+	require.Equal(t, http.StatusForbidden, rr.Code)
+	require.Empty(t, rr.Header().Get("Location"))
+}
+
+func TestRedirectHandler_Expired(t *testing.T) {
+	const alias = "expired_alias"
+
+	urlGetterMock := mocks.NewURLGetter(t)
+	urlGetterMock.On("GetURL", mock.Anything, alias).
+		Return(storage.URL{}, storage.ErrUrlExpired).Once()
+
+	r := chi.NewRouter()
+	r.Get("/{alias}", redirect.New(slogdiscard.NewDiscardLogger(), urlGetterMock, mocks.NewClickSaver(t), mocks.NewClickLimiter(t), "", http.StatusFound, 0, nil, nil, redirect.Fallbacks{}, newLinks(t)))
+
+	req := httptest.NewRequest(http.MethodGet, "/"+alias, nil)
+	rr := httptest.NewRecorder()
+	r.ServeHTTP(rr, req)
+
+	require.Equal(t, http.StatusGone, rr.Code)
+
+	var resp response.Response
+	require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &resp))
+	require.Equal(t, "url expired", resp.Error)
+}
+
+func TestRedirectHandler_MaxClicks(t *testing.T) {
+	cases := []struct {
+		name         string
+		consumeError error
+		wantCode     int
+	}{
+		{
+			name:     "Click left",
+			wantCode: http.StatusFound,
+		},
+		{
+			name:         "Limit reached concurrently",
+			consumeError: storage.ErrUrlExhausted,
+			wantCode:     http.StatusGone,
+		},
+	}
+
+	const alias = "one_time"
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			urlGetterMock := mocks.NewURLGetter(t)
+			clickSaverMock := mocks.NewClickSaver(t)
+			clickLimiterMock := mocks.NewClickLimiter(t)
+
+			urlGetterMock.On("GetURL", mock.Anything, alias).
+				Return(storage.URL{Alias: alias, URL: "http://google.com", MaxClicks: 1}, nil).Once()
+			clickLimiterMock.On("ConsumeClick", mock.Anything, alias).
+				Return(tc.consumeError).Once()
+
+			if tc.consumeError == nil {
+				clickSaverMock.On("SaveClick", mock.Anything, mock.Anything).Return(nil).Once()
+			}
+
+			r := chi.NewRouter()
+			r.Get("/{alias}", redirect.New(slogdiscard.NewDiscardLogger(), urlGetterMock, clickSaverMock, clickLimiterMock, "", http.StatusFound, 0, nil, nil, redirect.Fallbacks{}, newLinks(t)))
+
+			req := httptest.NewRequest(http.MethodGet, "/"+alias, nil)
+			rr := httptest.NewRecorder()
+			r.ServeHTTP(rr, req)
+
+			require.Equal(t, tc.wantCode, rr.Code)
+		})
+	}
+}
+
+func TestRedirectHandler_Password(t *testing.T) {
+	const alias = "protected"
+
+	hash, err := bcrypt.GenerateFromPassword([]byte("secret"), bcrypt.MinCost)
+	require.NoError(t, err)
+
+	cases := []struct {
+		name      string
+		method    string
+		query     string
+		form      string
+		wantCode  int
+		respError string
+	}{
+		{
+			name:      "No password",
+			method:    http.MethodGet,
+			wantCode:  http.StatusUnauthorized,
+			respError: "password required",
+		},
+		{
+			name:      "Wrong password",
+			method:    http.MethodPost,
+			form:      "password=wrong",
+			wantCode:  http.StatusUnauthorized,
+			respError: "invalid password",
+		},
+		{
+			name:      "Password in query",
+			method:    http.MethodPost,
+			query:     "?password=secret",
+			wantCode:  http.StatusUnauthorized,
+			respError: "password required",
+		},
+		{
+			name:     "Password in form",
+			method:   http.MethodPost,
+			form:     "password=secret",
+			wantCode: http.StatusFound,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			urlGetterMock := mocks.NewURLGetter(t)
+			clickSaverMock := mocks.NewClickSaver(t)
+
+			urlGetterMock.On("GetURL", mock.Anything, alias).
+				Return(storage.URL{Alias: alias, URL: "http://google.com", PasswordHash: string(hash)}, nil).Once()
+
+			if tc.respError == "" {
+				clickSaverMock.On("SaveClick", mock.Anything, mock.Anything).Return(nil).Once()
+			}
+
+			handler := redirect.New(slogdiscard.NewDiscardLogger(), urlGetterMock, clickSaverMock, mocks.NewClickLimiter(t), "", http.StatusFound, 0, nil, nil, redirect.Fallbacks{}, newLinks(t))
+
+			r := chi.NewRouter()
+			r.Get("/{alias}", handler)
+			r.Post("/{alias}", handler)
+
+			req := httptest.NewRequest(tc.method, "/"+alias+tc.query, strings.NewReader(tc.form))
+			if tc.form != "" {
+				req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+			}
+			rr := httptest.NewRecorder()
+			r.ServeHTTP(rr, req)
+
+			require.Equal(t, tc.wantCode, rr.Code)
+
+			if tc.respError != "" {
+				var resp response.Response
+				require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &resp))
+				require.Equal(t, tc.respError, resp.Error)
+			}
+		})
+	}
+}
+
+func TestRedirectHandler_RedirectType(t *testing.T) {
+	const alias = "typed_alias"
+
+	cases := []struct {
+		name        string
+		linkType    int
+		defaultType int
+		wantCode    int
+	}{
+		{
+			name:        "Default",
+			defaultType: http.StatusFound,
+			wantCode:    http.StatusFound,
+		},
+		{
+			name:        "Configured default",
+			defaultType: http.StatusPermanentRedirect,
+			wantCode:    http.StatusPermanentRedirect,
+		},
+		{
+			name:        "Per link",
+			linkType:    http.StatusMovedPermanently,
+			defaultType: http.StatusFound,
+			wantCode:    http.StatusMovedPermanently,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			urlGetterMock := mocks.NewURLGetter(t)
+			clickSaverMock := mocks.NewClickSaver(t)
+
+			urlGetterMock.On("GetURL", mock.Anything, alias).
+				Return(storage.URL{Alias: alias, URL: "http://google.com", RedirectType: tc.linkType}, nil).Once()
+			clickSaverMock.On("SaveClick", mock.Anything, mock.Anything).Return(nil).Once()
+
+			r := chi.NewRouter()
+			r.Get("/{alias}", redirect.New(slogdiscard.NewDiscardLogger(), urlGetterMock, clickSaverMock, mocks.NewClickLimiter(t), "", tc.defaultType, 0, nil, nil, redirect.Fallbacks{}, newLinks(t)))
+
+			rr := httptest.NewRecorder()
+			r.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/"+alias, nil))
+
+			require.Equal(t, tc.wantCode, rr.Code)
+			require.Equal(t, "http://google.com", rr.Header().Get("Location"))
+		})
+	}
+}
+
+func TestRedirectHandler_CacheControl(t *testing.T) {
+	const alias = "cached_alias"
+
+	cases := []struct {
+		name         string
+		url          storage.URL
+		defaultAge   time.Duration
+		cacheControl string
+	}{
+		{
+			name: "Not configured",
+		},
+		{
+			name:         "Default",
+			defaultAge:   time.Hour,
+			cacheControl: "public, max-age=3600",
+		},
+		{
+			name:         "Per link",
+			url:          storage.URL{CacheMaxAge: 86400},
+			defaultAge:   time.Hour,
+			cacheControl: "public, max-age=86400",
+		},
+		{
+			name:         "Per link without default",
+			url:          storage.URL{CacheMaxAge: 60},
+			cacheControl: "public, max-age=60",
+		},
+		{
+			name:         "Forbidden per link",
+			url:          storage.URL{CacheMaxAge: -1},
+			defaultAge:   time.Hour,
+			cacheControl: "no-store",
+		},
+		{
+			name:         "Until expiry",
+			url:          storage.URL{ExpiresAt: time.Now().Add(10*time.Minute + 30*time.Second + 500*time.Millisecond)},
+			defaultAge:   time.Hour,
+			cacheControl: "public, max-age=630",
+		},
+		{
+			name:         "Geo targets",
+			url:          storage.URL{GeoTargets: storage.Targets{"DE": "http://google.de"}},
+			defaultAge:   time.Hour,
+			cacheControl: "no-store",
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			urlGetterMock := mocks.NewURLGetter(t)
+			clickSaverMock := mocks.NewClickSaver(t)
+
+			u := tc.url
+			u.Alias, u.URL = alias, "http://google.com"
+			urlGetterMock.On("GetURL", mock.Anything, alias).Return(u, nil).Once()
+			clickSaverMock.On("SaveClick", mock.Anything, mock.Anything).Return(nil).Once()
+
+			r := chi.NewRouter()
+			r.Get("/{alias}", redirect.New(slogdiscard.NewDiscardLogger(), urlGetterMock, clickSaverMock, mocks.NewClickLimiter(t), "", http.StatusMovedPermanently, tc.defaultAge, nil, nil, redirect.Fallbacks{}, newLinks(t)))
+
+			rr := httptest.NewRecorder()
+			r.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/"+alias, nil))
+
+			require.Equal(t, http.StatusMovedPermanently, rr.Code)
+			require.Equal(t, tc.cacheControl, rr.Header().Get("Cache-Control"))
+		})
+	}
+}
+
+func TestRedirectHandler_ShortDomain(t *testing.T) {
+	cases := []struct {
+		name   string
+		host   string
+		domain string
+		found  bool
+	}{
+		{name: "Default domain", host: "sho.rt", found: true},
+		{name: "Default link on other domain", host: "go.acme.com", found: false},
+		{name: "Other domain", host: "go.acme.com:8082", domain: "go.acme.com", found: true},
+		{name: "Other link on default domain", host: "sho.rt", domain: "go.acme.com", found: false},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			urlGetterMock := mocks.NewURLGetter(t)
+			urlGetterMock.On("GetURL", mock.Anything, "promo").
+				Return(storage.URL{Alias: "promo", URL: "https://google.com", Domain: tc.domain}, nil).Once()
+
+			clickSaverMock := mocks.NewClickSaver(t)
+			if tc.found {
+				clickSaverMock.On("SaveClick", mock.Anything, mock.Anything).Return(nil).Once()
+			}
+
+			links := newLinks(t, "sho.rt", "go.acme.com")
+
+			r := chi.NewRouter()
+			r.Get("/{alias}", redirect.New(slogdiscard.NewDiscardLogger(), urlGetterMock, clickSaverMock, mocks.NewClickLimiter(t), "", http.StatusFound, 0, nil, nil, redirect.Fallbacks{}, links))
+
+			req := httptest.NewRequest(http.MethodGet, "/promo", nil)
+			req.Host = tc.host
+			rr := httptest.NewRecorder()
+			r.ServeHTTP(rr, req)
+
+			if tc.found {
+				require.Equal(t, http.StatusFound, rr.Code)
+				require.Equal(t, "https://google.com", rr.Header().Get("Location"))
+				return
+			}
+
+			require.Empty(t, rr.Header().Get("Location"))
+
+			var resp response.Response
+			require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &resp))
+			require.Equal(t, "Url not found", resp.Error)
+		})
+	}
+}
+
+func TestRedirectHandler_UTM(t *testing.T) {
+	const alias = "utm_alias"
+
+	urlGetterMock := mocks.NewURLGetter(t)
+	clickSaverMock := mocks.NewClickSaver(t)
+
+	urlGetterMock.On("GetURL", mock.Anything, alias).
+		Return(storage.URL{
+			Alias: alias,
+			URL:   "http://google.com/?q=go",
+			UTM:   storage.UTM{Source: "newsletter", Campaign: "{alias}"},
+		}, nil).Once()
+	clickSaverMock.On("SaveClick", mock.Anything, mock.Anything).Return(nil).Once()
+
+	r := chi.NewRouter()
+	r.Get("/{alias}", redirect.New(slogdiscard.NewDiscardLogger(), urlGetterMock, clickSaverMock, mocks.NewClickLimiter(t), "", http.StatusFound, 0, nil, nil, redirect.Fallbacks{}, newLinks(t)))
+
+	rr := httptest.NewRecorder()
+	r.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/"+alias, nil))
+
+	require.Equal(t, http.StatusFound, rr.Code)
+	require.Equal(t, "http://google.com/?q=go&utm_campaign=utm_alias&utm_source=newsletter", rr.Header().Get("Location"))
+}
+
+func TestRedirectHandler_GeoTargets(t *testing.T) {
+	const alias = "geo_alias"
+
+	link := storage.URL{
+		Alias:      alias,
+		URL:        "https://example.com",
+		GeoTargets: storage.Targets{"DE": "https://example.de"},
+	}
+
+	cases := []struct {
+		name      string
+		country   string
+		city      string
+		mockError error
+		want      string
+	}{
+		{
+			name:    "Matching country",
+			country: "DE",
+			city:    "Berlin",
+			want:    "https://example.de",
+		},
+		{
+			name:    "Other country",
+			country: "FR",
+			want:    "https://example.com",
+		},
+		{
+			name: "Unknown country",
+			want: "https://example.com",
+		},
+		{
+			name:      "Lookup error",
+			mockError: errors.New("unexpected error"),
+			want:      "https://example.com",
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			urlGetterMock := mocks.NewURLGetter(t)
+			clickSaverMock := mocks.NewClickSaver(t)
+			locatorMock := mocks.NewLocator(t)
+
+			urlGetterMock.On("GetURL", mock.Anything, alias).Return(link, nil).Once()
+			clickSaverMock.On("SaveClick", mock.Anything, mock.MatchedBy(func(c storage.Click) bool {
+				return c.Country == tc.country && c.City == tc.city
+			})).Return(nil).Once()
+			locatorMock.On("Locate", net.ParseIP("192.0.2.1")).Return(tc.country, tc.city, tc.mockError).Once()
+
+			r := chi.NewRouter()
+			r.Get("/{alias}", redirect.New(slogdiscard.NewDiscardLogger(), urlGetterMock, clickSaverMock, mocks.NewClickLimiter(t), "", http.StatusFound, 0, locatorMock, nil, redirect.Fallbacks{}, newLinks(t)))
+
+			rr := httptest.NewRecorder()
+			r.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/"+alias, nil))
+
+			require.Equal(t, http.StatusFound, rr.Code)
+			require.Equal(t, tc.want, rr.Header().Get("Location"))
+		})
+	}
+}
+
+func TestRedirectHandler_DeviceTargets(t *testing.T) {
+	const alias = "app_alias"
+
+	link := storage.URL{
+		Alias: alias,
+		URL:   "https://example.com/item/1",
+		DeviceTargets: storage.Targets{
+			storage.DeviceIOS:     "myapp://item/1",
+			storage.DeviceAndroid: "intent://item/1#Intent;scheme=myapp;end",
+		},
+		GeoTargets: storage.Targets{"DE": "https://example.de/item/1"},
+	}
+
+	cases := []struct {
+		name      string
+		userAgent string
+		want      string
+	}{
+		{
+			name:      "iOS",
+			userAgent: "Mozilla/5.0 (iPhone; CPU iPhone OS 17_4 like Mac OS X) Mobile/15E148",
+			want:      "myapp://item/1",
+		},
+		{
+			name:      "Android",
+			userAgent: "Mozilla/5.0 (Linux; Android 14; Pixel 8) Mobile Safari/537.36",
+			want:      "intent://item/1#Intent;scheme=myapp;end",
+		},
+		{
+			name:      "Desktop",
+			userAgent: "Mozilla/5.0 (X11; Linux x86_64) Firefox/125.0",
+			want:      "https://example.com/item/1",
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			urlGetterMock := mocks.NewURLGetter(t)
+			clickSaverMock := mocks.NewClickSaver(t)
+
+			urlGetterMock.On("GetURL", mock.Anything, alias).Return(link, nil).Once()
+			clickSaverMock.On("SaveClick", mock.Anything, mock.Anything).Return(nil).Once()
+
+			r := chi.NewRouter()
+			r.Get("/{alias}", redirect.New(slogdiscard.NewDiscardLogger(), urlGetterMock, clickSaverMock, mocks.NewClickLimiter(t), "", http.StatusFound, 0, nil, nil, redirect.Fallbacks{}, newLinks(t)))
+
+			req := httptest.NewRequest(http.MethodGet, "/"+alias, nil)
+			req.Header.Set("User-Agent", tc.userAgent)
+			rr := httptest.NewRecorder()
+			r.ServeHTTP(rr, req)
+
+			require.Equal(t, http.StatusFound, rr.Code)
+			require.Equal(t, tc.want, rr.Header().Get("Location"))
+			require.Equal(t, "User-Agent", rr.Header().Get("Vary"))
+		})
+	}
+}
+
+func TestRedirectHandler_Variants(t *testing.T) {
+	const alias = "ab_alias"
+
+	link := storage.URL{
+		Alias: alias,
+		URL:   "https://example.com",
+		Variants: storage.Variants{
+			{Name: "A", URL: "https://example.com/a", Weight: 1},
+			{Name: "B", URL: "https://example.com/b", Weight: 1},
+		},
+	}
+	want := map[string]string{"https://example.com/a": "A", "https://example.com/b": "B"}
+
+	urlGetterMock := mocks.NewURLGetter(t)
+	clickSaverMock := mocks.NewClickSaver(t)
+
+	var clicked []string
+	urlGetterMock.On("GetURL", mock.Anything, alias).Return(link, nil)
+	clickSaverMock.On("SaveClick", mock.Anything, mock.Anything).Return(nil).Run(func(args mock.Arguments) {
+		clicked = append(clicked, args.Get(1).(storage.Click).Variant)
+	})
+
+	r := chi.NewRouter()
+	r.Get("/{alias}", redirect.New(slogdiscard.NewDiscardLogger(), urlGetterMock, clickSaverMock, mocks.NewClickLimiter(t), "", http.StatusFound, 0, nil, nil, redirect.Fallbacks{}, newLinks(t)))
+
+	served := make(map[string]int)
+	for i := 0; i < 100; i++ {
+		rr := httptest.NewRecorder()
+		r.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/"+alias, nil))
+
+		require.Equal(t, http.StatusFound, rr.Code)
+		location := rr.Header().Get("Location")
+		require.Contains(t, want, location)
+		require.Equal(t, want[location], clicked[i])
+		served[location]++
+	}
+
+	// With equal weights both variants are served.
+	require.Len(t, served, 2)
+}
+
+func TestRedirectHandler_Bots(t *testing.T) {
+	const (
+		alias   = "bot_alias"
+		crawler = "Mozilla/5.0 (compatible; Googlebot/2.1)"
+		browser = "Mozilla/5.0 (X11; Linux x86_64) Firefox/125.0"
+	)
+
+	for _, ua := range []string{crawler, browser} {
+		t.Run(ua, func(t *testing.T) {
+			urlGetterMock := mocks.NewURLGetter(t)
+			clickSaverMock := mocks.NewClickSaver(t)
+			botsMock := mocks.NewBotDetector(t)
+
+			urlGetterMock.On("GetURL", mock.Anything, alias).Return(storage.URL{Alias: alias, URL: "https://example.com"}, nil).Once()
+			botsMock.On("IsBot", ua).Return(ua == crawler).Once()
+			clickSaverMock.On("SaveClick", mock.Anything, mock.MatchedBy(func(c storage.Click) bool {
+				return c.UserAgent == ua && c.Bot == (ua == crawler)
+			})).Return(nil).Once()
+
+			r := chi.NewRouter()
+			r.Get("/{alias}", redirect.New(slogdiscard.NewDiscardLogger(), urlGetterMock, clickSaverMock, mocks.NewClickLimiter(t), "", http.StatusFound, 0, nil, botsMock, redirect.Fallbacks{}, newLinks(t)))
+
+			req := httptest.NewRequest(http.MethodGet, "/"+alias, nil)
+			req.Header.Set("User-Agent", ua)
+			rr := httptest.NewRecorder()
+			r.ServeHTTP(rr, req)
+
+			// Bots are redirected like everyone else.
+			require.Equal(t, http.StatusFound, rr.Code)
+			require.Equal(t, "https://example.com", rr.Header().Get("Location"))
+		})
+	}
+}
+
+func TestRedirectHandler_OpenGraph(t *testing.T) {
+	const (
+		alias   = "sale"
+		crawler = "Twitterbot/1.0"
+		browser = "Mozilla/5.0 (X11; Linux x86_64) Firefox/125.0"
+	)
+
+	link := storage.URL{
+		Alias:     alias,
+		URL:       "https://example.com/shop",
+		MaxClicks: 1,
+		OpenGraph: storage.Metadata{
+			Title:       "Summer <sale>",
+			Description: "Up to 50% off",
+			Image:       "https://cdn.example.com/sale.png",
+		},
+	}
+
+	t.Run("Crawler", func(t *testing.T) {
+		urlGetterMock := mocks.NewURLGetter(t)
+		botsMock := mocks.NewBotDetector(t)
+
+		urlGetterMock.On("GetURL", mock.Anything, alias).Return(link, nil).Once()
+		botsMock.On("IsSocialCrawler", crawler).Return(true).Once()
+
+		r := chi.NewRouter()
+		// Neither a click nor a use of the one-time link is recorded.
+		r.Get("/{alias}", redirect.New(slogdiscard.NewDiscardLogger(), urlGetterMock, mocks.NewClickSaver(t), mocks.NewClickLimiter(t), "", http.StatusFound, 0, nil, botsMock, redirect.Fallbacks{}, newLinks(t)))
+
+		req := httptest.NewRequest(http.MethodGet, "http://sho.rt/"+alias, nil)
+		req.Header.Set("User-Agent", crawler)
+		rr := httptest.NewRecorder()
+		r.ServeHTTP(rr, req)
+
+		require.Equal(t, http.StatusOK, rr.Code)
+		require.Equal(t, "text/html; charset=utf-8", rr.Header().Get("Content-Type"))
+		require.Equal(t, "User-Agent", rr.Header().Get("Vary"))
+
+		body := rr.Body.String()
+		require.Contains(t, body, `<meta property="og:title" content="Summer &lt;sale&gt;">`)
+		require.Contains(t, body, `<meta property="og:description" content="Up to 50% off">`)
+		require.Contains(t, body, `<meta property="og:image" content="https://cdn.example.com/sale.png">`)
+		require.Contains(t, body, `<meta property="og:url" content="http://sho.rt/sale">`)
+		require.Contains(t, body, `<meta name="twitter:card" content="summary_large_image">`)
+		require.NotContains(t, body, link.URL)
+	})
+
+	t.Run("Browser", func(t *testing.T) {
+		urlGetterMock := mocks.NewURLGetter(t)
+		clickSaverMock := mocks.NewClickSaver(t)
+		clickLimiterMock := mocks.NewClickLimiter(t)
+		botsMock := mocks.NewBotDetector(t)
+
+		urlGetterMock.On("GetURL", mock.Anything, alias).Return(link, nil).Once()
+		botsMock.On("IsSocialCrawler", browser).Return(false).Once()
+		botsMock.On("IsBot", browser).Return(false).Once()
+		clickLimiterMock.On("ConsumeClick", mock.Anything, alias).Return(nil).Once()
+		clickSaverMock.On("SaveClick", mock.Anything, mock.Anything).Return(nil).Once()
+
+		r := chi.NewRouter()
+		r.Get("/{alias}", redirect.New(slogdiscard.NewDiscardLogger(), urlGetterMock, clickSaverMock, clickLimiterMock, "", http.StatusFound, 0, nil, botsMock, redirect.Fallbacks{}, newLinks(t)))
+
+		req := httptest.NewRequest(http.MethodGet, "/"+alias, nil)
+		req.Header.Set("User-Agent", browser)
+		rr := httptest.NewRecorder()
+		r.ServeHTTP(rr, req)
+
+		require.Equal(t, http.StatusFound, rr.Code)
+		require.Equal(t, link.URL, rr.Header().Get("Location"))
+		require.Equal(t, "User-Agent", rr.Header().Get("Vary"))
+	})
+}
+
+func TestRedirectHandler_ActiveWindow(t *testing.T) {
+	const alias = "promo"
+
+	now := time.Now()
+	fallbacks := redirect.Fallbacks{
+		NotActive: "https://example.com/soon",
+		Expired:   "https://example.com/over",
+	}
+
+	cases := []struct {
+		name      string
+		link      storage.URL
+		mockError error
+		fallbacks redirect.Fallbacks
+		status    int
+		location  string
+		respError string
+	}{
+		{
+			name:      "Not active yet",
+			link:      storage.URL{URL: "https://example.com", ActiveFrom: now.Add(time.Hour)},
+			status:    http.StatusForbidden,
+			respError: "url is not active yet",
+		},
+		{
+			name:      "Not active yet with fallback",
+			link:      storage.URL{URL: "https://example.com", ActiveFrom: now.Add(time.Hour)},
+			fallbacks: fallbacks,
+			status:    http.StatusFound,
+			location:  "https://example.com/soon",
+		},
+		{
+			name:      "Window over",
+			link:      storage.URL{URL: "https://example.com", ActiveUntil: now.Add(-time.Hour)},
+			status:    http.StatusGone,
+			respError: "url expired",
+		},
+		{
+			name:      "Expired with fallback",
+			mockError: storage.ErrUrlExpired,
+			fallbacks: fallbacks,
+			status:    http.StatusFound,
+			location:  "https://example.com/over",
+		},
+		{
+			name: "Within window",
+			link: storage.URL{
+				URL:         "https://example.com",
+				ActiveFrom:  now.Add(-time.Hour),
+				ActiveUntil: now.Add(time.Hour),
+			},
+			fallbacks: fallbacks,
+			status:    http.StatusFound,
+			location:  "https://example.com",
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			urlGetterMock := mocks.NewURLGetter(t)
+			clickSaverMock := mocks.NewClickSaver(t)
+
+			tc.link.Alias = alias
+			urlGetterMock.On("GetURL", mock.Anything, alias).Return(tc.link, tc.mockError).Once()
+			if tc.location == tc.link.URL {
+				clickSaverMock.On("SaveClick", mock.Anything, mock.Anything).Return(nil).Once()
+			}
+
+			r := chi.NewRouter()
+			r.Get("/{alias}", redirect.New(slogdiscard.NewDiscardLogger(), urlGetterMock, clickSaverMock, mocks.NewClickLimiter(t), "", http.StatusFound, 0, nil, nil, tc.fallbacks, newLinks(t)))
+
+			rr := httptest.NewRecorder()
+			r.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/"+alias, nil))
+
+			require.Equal(t, tc.status, rr.Code)
+			require.Equal(t, tc.location, rr.Header().Get("Location"))
+
+			if tc.respError != "" {
+				var resp response.Response
+				require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &resp))
+				require.Equal(t, tc.respError, resp.Error)
+			}
+		})
+	}
+}
+
+func TestRedirectHandler_ErrorPages(t *testing.T) {
+	cases := []struct {
+		name      string
+		accept    string
+		mockError error
+		status    int
+		message   string
+	}{
+		{
+			name:      "Browser, not found",
+			accept:    "text/html,application/xhtml+xml,*/*;q=0.8",
+			mockError: storage.ErrUrlNotFound,
+			status:    http.StatusNotFound,
+			message:   "url not found",
+		},
+		{
+			name:      "Browser, expired",
+			accept:    "text/html",
+			mockError: storage.ErrUrlExpired,
+			status:    http.StatusGone,
+			message:   "url expired",
+		},
+		{
+			name:      "API client, not found",
+			accept:    "application/json",
+			mockError: storage.ErrUrlNotFound,
+			status:    http.StatusOK,
+		},
+		{
+			name:      "API client, click limit reached",
+			mockError: storage.ErrUrlExhausted,
+			status:    http.StatusGone,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			urlGetterMock := mocks.NewURLGetter(t)
+			pagesMock := mocks.NewErrorPages(t)
+
+			urlGetterMock.On("GetURL", mock.Anything, "missing").Return(storage.URL{}, tc.mockError).Once()
+			if tc.message != "" {
+				pagesMock.On("Render", mock.Anything, tc.status, "missing", tc.message).
+					Run(func(args mock.Arguments) {
+						args.Get(0).(http.ResponseWriter).WriteHeader(tc.status)
+					}).
+					Return(nil).Once()
+			}
+
+			r := chi.NewRouter()
+			r.Get("/{alias}", redirect.New(slogdiscard.NewDiscardLogger(), urlGetterMock, mocks.NewClickSaver(t), mocks.NewClickLimiter(t), "", http.StatusFound, 0, nil, nil, redirect.Fallbacks{Errors: pagesMock}, newLinks(t)))
+
+			req := httptest.NewRequest(http.MethodGet, "/missing", nil)
+			if tc.accept != "" {
+				req.Header.Set("Accept", tc.accept)
+			}
+			rr := httptest.NewRecorder()
+			r.ServeHTTP(rr, req)
+
+			require.Equal(t, tc.status, rr.Code)
+			if tc.message == "" {
+				var resp response.Response
+				require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &resp))
+				require.NotEmpty(t, resp.Error)
+			}
+		})
+	}
+}
+
+func newLinks(t *testing.T, domains ...string) *shorturl.Builder {
+	t.Helper()
+
+	links, err := shorturl.New("", domains)
+	require.NoError(t, err)
+
+	return links
+}
@@ -0,0 +1,127 @@
+package referrers
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"url-shortener/internal/http-server/handlers/url/access"
+	resp "url-shortener/internal/lib/api/response"
+	"url-shortener/internal/lib/logger/sl"
+	"url-shortener/internal/storage"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/go-chi/render"
+)
+
+// ReferrerClicks counts the clicks that came from one referring domain.
+type ReferrerClicks struct {
+	Domain string `json:"domain"`
+	Clicks int64  `json:"clicks"`
+}
+
+type Response struct {
+	resp.Response
+	Alias string `json:"alias"`
+	// Direct counts clicks without a Referer: typed in, bookmarked or
+	// opened from an app.
+	Direct    int64            `json:"direct_clicks"`
+	Referrers []ReferrerClicks `json:"referrers"`
+	// Other counts clicks from the domains cut off by limit.
+	Other int64 `json:"other_clicks"`
+}
+
+const (
+	defaultLimit = 10
+	maxLimit     = 100
+)
+
+//go:generate go run github.com/vektra/mockery/v2@latest --name=ReferrersGetter
+type ReferrersGetter interface {
+	GetReferrers(ctx context.Context, alias string, includeBots bool) ([]storage.ReferrerClicks, error)
+}
+
+// New returns the top `limit` domains (10 by default) clicks on an alias
+// came from, the most clicked first. Clicks of bots are counted only with
+// include_bots=true. Users other than admins and viewers get the referrers
+// of their own links only.
+func New(log *slog.Logger, referrersGetter ReferrersGetter, ownerGetter access.OwnerGetter) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.url.referrers.New"
+
+		log := log.With(
+			slog.String("op", op),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+
+		alias := chi.URLParam(r, "alias")
+		if alias == "" {
+			log.Info("alias is empty")
+			render.Status(r, http.StatusBadRequest)
+			render.JSON(w, r, resp.Error("invalid request"))
+			return
+		}
+
+		limit := defaultLimit
+		if v := r.URL.Query().Get("limit"); v != "" {
+			n, err := strconv.Atoi(v)
+			if err != nil || n <= 0 || n > maxLimit {
+				log.Info("invalid limit", slog.String("limit", v))
+				render.Status(r, http.StatusBadRequest)
+				render.JSON(w, r, resp.Error("invalid limit"))
+				return
+			}
+			limit = n
+		}
+
+		includeBots := false
+		if v := r.URL.Query().Get("include_bots"); v != "" {
+			b, err := strconv.ParseBool(v)
+			if err != nil {
+				log.Info("invalid include_bots", slog.String("include_bots", v))
+				render.Status(r, http.StatusBadRequest)
+				render.JSON(w, r, resp.Error("invalid include_bots"))
+				return
+			}
+			includeBots = b
+		}
+
+		if !access.CanView(w, r, log, ownerGetter, alias) {
+			return
+		}
+
+		referrers, err := referrersGetter.GetReferrers(r.Context(), alias, includeBots)
+		if errors.Is(err, storage.ErrUrlNotFound) {
+			log.Info("url not found", slog.String("alias", alias))
+			render.Status(r, http.StatusNotFound)
+			render.JSON(w, r, resp.Error("url not found"))
+			return
+		}
+		if err != nil {
+			log.Error("failed to get referrers", sl.Err(err))
+			render.Status(r, http.StatusInternalServerError)
+			render.JSON(w, r, resp.Error("failed to get referrers"))
+			return
+		}
+
+		res := Response{
+			Response:  resp.OK(),
+			Alias:     alias,
+			Referrers: make([]ReferrerClicks, 0, min(len(referrers), limit)),
+		}
+		for _, ref := range referrers {
+			switch {
+			case ref.Domain == "":
+				res.Direct += ref.Clicks
+			case len(res.Referrers) < limit:
+				res.Referrers = append(res.Referrers, ReferrerClicks{Domain: ref.Domain, Clicks: ref.Clicks})
+			default:
+				res.Other += ref.Clicks
+			}
+		}
+
+		render.JSON(w, r, res)
+	}
+}
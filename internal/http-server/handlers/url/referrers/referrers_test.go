@@ -0,0 +1,150 @@
+package referrers_test
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	accessMocks "url-shortener/internal/http-server/handlers/url/access/mocks"
+	"url-shortener/internal/http-server/handlers/url/referrers"
+	"url-shortener/internal/http-server/handlers/url/referrers/mocks"
+	"url-shortener/internal/lib/logger/handlers/slogdiscard"
+	"url-shortener/internal/storage"
+)
+
+func TestReferrersHandler(t *testing.T) {
+	stored := []storage.ReferrerClicks{
+		{Domain: "reddit.com", Clicks: 5},
+		{Domain: "", Clicks: 4},
+		{Domain: "t.co", Clicks: 2},
+		{Domain: "news.ycombinator.com", Clicks: 1},
+	}
+
+	cases := []struct {
+		name          string
+		alias         string
+		query         string
+		includeBots   bool
+		status        int
+		respError     string
+		mockReferrers []storage.ReferrerClicks
+		mockError     error
+		callMock      bool
+		want          []referrers.ReferrerClicks
+		direct        int64
+		other         int64
+	}{
+		{
+			name:          "Success",
+			alias:         "test_alias",
+			status:        http.StatusOK,
+			mockReferrers: stored,
+			callMock:      true,
+			want: []referrers.ReferrerClicks{
+				{Domain: "reddit.com", Clicks: 5},
+				{Domain: "t.co", Clicks: 2},
+				{Domain: "news.ycombinator.com", Clicks: 1},
+			},
+			direct: 4,
+		},
+		{
+			name:          "Limit",
+			alias:         "test_alias",
+			query:         "?limit=1",
+			status:        http.StatusOK,
+			mockReferrers: stored,
+			callMock:      true,
+			want:          []referrers.ReferrerClicks{{Domain: "reddit.com", Clicks: 5}},
+			direct:        4,
+			other:         3,
+		},
+		{
+			name:          "Include bots",
+			alias:         "test_alias",
+			query:         "?include_bots=1",
+			includeBots:   true,
+			status:        http.StatusOK,
+			mockReferrers: []storage.ReferrerClicks{{Domain: "t.co", Clicks: 7}},
+			callMock:      true,
+			want:          []referrers.ReferrerClicks{{Domain: "t.co", Clicks: 7}},
+		},
+		{
+			name:      "Invalid include_bots",
+			alias:     "test_alias",
+			query:     "?include_bots=maybe",
+			status:    http.StatusBadRequest,
+			respError: "invalid include_bots",
+		},
+		{
+			name:     "No clicks",
+			alias:    "test_alias",
+			status:   http.StatusOK,
+			callMock: true,
+			want:     []referrers.ReferrerClicks{},
+		},
+		{
+			name:      "Invalid limit",
+			alias:     "test_alias",
+			query:     "?limit=1000",
+			status:    http.StatusBadRequest,
+			respError: "invalid limit",
+		},
+		{
+			name:      "Not found",
+			alias:     "missing",
+			status:    http.StatusNotFound,
+			respError: "url not found",
+			mockError: storage.ErrUrlNotFound,
+			callMock:  true,
+		},
+		{
+			name:      "GetReferrers Error",
+			alias:     "test_alias",
+			status:    http.StatusInternalServerError,
+			respError: "failed to get referrers",
+			mockError: errors.New("unexpected error"),
+			callMock:  true,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			getterMock := mocks.NewReferrersGetter(t)
+			ownerGetterMock := accessMocks.NewOwnerGetter(t)
+			ownerGetterMock.On("GetOwner", mock.Anything, tc.alias).Return("", nil).Maybe()
+
+			if tc.callMock {
+				getterMock.On("GetReferrers", mock.Anything, tc.alias, tc.includeBots).
+					Return(tc.mockReferrers, tc.mockError).
+					Once()
+			}
+
+			r := chi.NewRouter()
+			r.Get("/{alias}/referrers", referrers.New(slogdiscard.NewDiscardLogger(), getterMock, ownerGetterMock))
+
+			req := httptest.NewRequest(http.MethodGet, "/"+tc.alias+"/referrers"+tc.query, nil)
+			rr := httptest.NewRecorder()
+			r.ServeHTTP(rr, req)
+
+			require.Equal(t, tc.status, rr.Code)
+
+			var resp referrers.Response
+			require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &resp))
+
+			require.Equal(t, tc.respError, resp.Error)
+
+			if tc.respError == "" {
+				require.Equal(t, tc.alias, resp.Alias)
+				require.Equal(t, tc.want, resp.Referrers)
+				require.Equal(t, tc.direct, resp.Direct)
+				require.Equal(t, tc.other, resp.Other)
+			}
+		})
+	}
+}
@@ -0,0 +1,134 @@
+package rename
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+	"time"
+	"url-shortener/internal/http-server/middleware/auth"
+	"url-shortener/internal/lib/alias"
+	resp "url-shortener/internal/lib/api/response"
+	"url-shortener/internal/lib/logger/sl"
+	"url-shortener/internal/storage"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/go-chi/render"
+	"github.com/go-playground/validator/v10"
+)
+
+type Request struct {
+	Alias string `json:"alias" validate:"required"`
+	// KeepRedirect leaves the old alias redirecting to the new one for the
+	// configured period.
+	KeepRedirect bool `json:"keep_redirect,omitempty"`
+}
+
+type Response struct {
+	resp.Response
+	Alias string `json:"alias,omitempty"`
+	// RedirectUntil is when the old alias stops redirecting, if it was kept.
+	RedirectUntil *time.Time `json:"redirect_until,omitempty"`
+}
+
+//go:generate go run github.com/vektra/mockery/v2@latest --name=URLRenamer
+type URLRenamer interface {
+	RenameURL(ctx context.Context, alias string, owner string, newAlias string, forwardUntil time.Time) error
+}
+
+// New gives an existing link a new alias, which must follow the same rules
+// as custom aliases of new links. Users can only rename their own links,
+// admins can rename any. With keep_redirect the old alias redirects to the
+// new one for forwardPeriod; 0 disables that option.
+func New(log *slog.Logger, urlRenamer URLRenamer, aliases *alias.Validator, forwardPeriod time.Duration) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.url.rename.New"
+
+		log := log.With(
+			slog.String("op", op),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+
+		oldAlias := chi.URLParam(r, "alias")
+		if oldAlias == "" {
+			log.Info("alias is empty")
+			render.Status(r, http.StatusBadRequest)
+			render.JSON(w, r, resp.Error("invalid request"))
+			return
+		}
+
+		var req Request
+		if err := render.DecodeJSON(r.Body, &req); err != nil {
+			log.Error("failed to decode request body", sl.Err(err))
+			render.JSON(w, r, resp.Error("failed to decode request"))
+			return
+		}
+
+		log.Info("request body decoded", slog.Any("request", req))
+
+		if err := validator.New().Struct(req); err != nil {
+			validateErr := err.(validator.ValidationErrors)
+
+			log.Error("invalide request", sl.Err(err))
+			render.JSON(w, r, resp.ValidationError(validateErr))
+			return
+		}
+
+		if err := aliases.Validate(req.Alias); err != nil {
+			log.Info("invalid alias", slog.String("alias", req.Alias), sl.Err(err))
+
+			var validationErr *alias.ValidationError
+			if errors.As(err, &validationErr) {
+				render.JSON(w, r, resp.InvalidField("alias", validationErr.Rule, validationErr.Message))
+			} else {
+				render.JSON(w, r, resp.Error(err.Error()))
+			}
+			return
+		}
+		req.Alias = aliases.Sign(req.Alias)
+
+		if req.KeepRedirect && forwardPeriod <= 0 {
+			log.Info("invalide request: keep_redirect is disabled")
+			render.JSON(w, r, resp.Error("keep_redirect is disabled on this server"))
+			return
+		}
+
+		var forwardUntil time.Time
+		if req.KeepRedirect {
+			forwardUntil = time.Now().Add(forwardPeriod).UTC()
+		}
+
+		err := urlRenamer.RenameURL(r.Context(), oldAlias, auth.OwnerFromContext(r.Context()), req.Alias, forwardUntil)
+		if errors.Is(err, storage.ErrUrlNotFound) {
+			log.Info("url not found", slog.String("alias", oldAlias))
+			render.Status(r, http.StatusNotFound)
+			render.JSON(w, r, resp.Error("url not found"))
+			return
+		}
+		if errors.Is(err, storage.ErrUrlExists) {
+			log.Info("alias already taken", slog.String("alias", req.Alias))
+			render.Status(r, http.StatusConflict)
+			render.JSON(w, r, resp.Error("alias already exists"))
+			return
+		}
+		if err != nil {
+			log.Error("failed to rename url", sl.Err(err))
+			render.Status(r, http.StatusInternalServerError)
+			render.JSON(w, r, resp.Error("failed to rename url"))
+			return
+		}
+
+		log.Info("url renamed", slog.String("alias", oldAlias), slog.String("new_alias", req.Alias))
+
+		res := Response{
+			Response: resp.OK(),
+			Alias:    req.Alias,
+		}
+		if !forwardUntil.IsZero() {
+			res.RedirectUntil = &forwardUntil
+		}
+
+		render.JSON(w, r, res)
+	}
+}
@@ -0,0 +1,141 @@
+package rename_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"url-shortener/internal/http-server/handlers/url/rename"
+	"url-shortener/internal/http-server/handlers/url/rename/mocks"
+	"url-shortener/internal/http-server/middleware/auth"
+	"url-shortener/internal/lib/alias"
+	"url-shortener/internal/lib/logger/handlers/slogdiscard"
+	"url-shortener/internal/storage"
+)
+
+func TestRenameHandler(t *testing.T) {
+	cases := []struct {
+		name          string
+		alias         string
+		newAlias      string
+		keepRedirect  bool
+		forwardPeriod time.Duration
+		status        int
+		respError     string
+		mockError     error
+		callMock      bool
+	}{
+		{
+			name:     "Success",
+			alias:    "old",
+			newAlias: "new",
+			status:   http.StatusOK,
+			callMock: true,
+		},
+		{
+			name:          "Keep redirect",
+			alias:         "old",
+			newAlias:      "new",
+			keepRedirect:  true,
+			forwardPeriod: time.Hour,
+			status:        http.StatusOK,
+			callMock:      true,
+		},
+		{
+			name:         "Keep redirect disabled",
+			alias:        "old",
+			newAlias:     "new",
+			keepRedirect: true,
+			status:       http.StatusOK,
+			respError:    "keep_redirect is disabled on this server",
+		},
+		{
+			name:      "Empty alias",
+			alias:     "old",
+			status:    http.StatusOK,
+			respError: "field Alias is a required field",
+		},
+		{
+			name:      "Reserved alias",
+			alias:     "old",
+			newAlias:  "admin",
+			status:    http.StatusOK,
+			respError: "field alias is reserved",
+		},
+		{
+			name:      "Not found",
+			alias:     "missing",
+			newAlias:  "new",
+			status:    http.StatusNotFound,
+			respError: "url not found",
+			mockError: storage.ErrUrlNotFound,
+			callMock:  true,
+		},
+		{
+			name:      "Alias taken",
+			alias:     "old",
+			newAlias:  "taken",
+			status:    http.StatusConflict,
+			respError: "alias already exists",
+			mockError: storage.ErrUrlExists,
+			callMock:  true,
+		},
+		{
+			name:      "RenameURL Error",
+			alias:     "old",
+			newAlias:  "new",
+			status:    http.StatusInternalServerError,
+			respError: "failed to rename url",
+			mockError: errors.New("unexpected error"),
+			callMock:  true,
+		},
+	}
+
+	aliases, err := alias.New(alias.Rules{Reserved: []string{"admin"}})
+	require.NoError(t, err)
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			urlRenamerMock := mocks.NewURLRenamer(t)
+
+			if tc.callMock {
+				urlRenamerMock.On("RenameURL", mock.Anything, tc.alias, "alice", tc.newAlias,
+					mock.MatchedBy(func(until time.Time) bool { return until.IsZero() != tc.keepRedirect }),
+				).
+					Return(tc.mockError).
+					Once()
+			}
+
+			r := chi.NewRouter()
+			r.Post("/{alias}/rename", rename.New(slogdiscard.NewDiscardLogger(), urlRenamerMock, aliases, tc.forwardPeriod))
+
+			input := fmt.Sprintf(`{"alias": "%s", "keep_redirect": %t}`, tc.newAlias, tc.keepRedirect)
+
+			req := httptest.NewRequest(http.MethodPost, "/"+tc.alias+"/rename", bytes.NewReader([]byte(input)))
+			req = req.WithContext(auth.WithUser(req.Context(), storage.User{Username: "alice", Role: storage.RoleUser}))
+			rr := httptest.NewRecorder()
+			r.ServeHTTP(rr, req)
+
+			require.Equal(t, tc.status, rr.Code)
+
+			var resp rename.Response
+			require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &resp))
+
+			require.Equal(t, tc.respError, resp.Error)
+
+			if tc.respError == "" {
+				require.Equal(t, tc.newAlias, resp.Alias)
+				require.Equal(t, tc.keepRedirect, resp.RedirectUntil != nil)
+			}
+		})
+	}
+}
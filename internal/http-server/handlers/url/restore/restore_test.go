@@ -0,0 +1,75 @@
+package restore_test
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"url-shortener/internal/http-server/handlers/url/restore"
+	"url-shortener/internal/http-server/handlers/url/restore/mocks"
+	"url-shortener/internal/http-server/middleware/auth"
+	resp "url-shortener/internal/lib/api/response"
+	"url-shortener/internal/lib/logger/handlers/slogdiscard"
+	"url-shortener/internal/storage"
+)
+
+func TestRestoreHandler(t *testing.T) {
+	cases := []struct {
+		name      string
+		alias     string
+		status    int
+		respError string
+		mockError error
+	}{
+		{
+			name:   "Success",
+			alias:  "test_alias",
+			status: http.StatusOK,
+		},
+		{
+			name:      "Not found",
+			alias:     "missing",
+			status:    http.StatusNotFound,
+			respError: "deleted url not found",
+			mockError: storage.ErrUrlNotFound,
+		},
+		{
+			name:      "RestoreURL Error",
+			alias:     "test_alias",
+			status:    http.StatusInternalServerError,
+			respError: "failed to restore url",
+			mockError: errors.New("unexpected error"),
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			urlRestorerMock := mocks.NewURLRestorer(t)
+
+			urlRestorerMock.On("RestoreURL", mock.Anything, tc.alias, "alice").
+				Return(tc.mockError).
+				Once()
+
+			r := chi.NewRouter()
+			r.Post("/{alias}/restore", restore.New(slogdiscard.NewDiscardLogger(), urlRestorerMock))
+
+			req := httptest.NewRequest(http.MethodPost, "/"+tc.alias+"/restore", nil)
+			req = req.WithContext(auth.WithUser(req.Context(), storage.User{Username: "alice", Role: storage.RoleUser}))
+			rr := httptest.NewRecorder()
+			r.ServeHTTP(rr, req)
+
+			require.Equal(t, tc.status, rr.Code)
+
+			var body resp.Response
+			require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &body))
+
+			require.Equal(t, tc.respError, body.Error)
+		})
+	}
+}
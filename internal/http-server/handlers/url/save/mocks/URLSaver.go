@@ -2,7 +2,13 @@
 
 package mocks
 
-import mock "github.com/stretchr/testify/mock"
+import (
+	context "context"
+
+	mock "github.com/stretchr/testify/mock"
+
+	storage "url-shortener/internal/storage"
+)
 
 // URLSaver is an autogenerated mock type for the URLSaver type
 type URLSaver struct {
@@ -17,9 +23,125 @@ func (_m *URLSaver) EXPECT() *URLSaver_Expecter {
 	return &URLSaver_Expecter{mock: &_m.Mock}
 }
 
-// SaveURL provides a mock function with given fields: urlToSave, alias
-func (_m *URLSaver) SaveURL(urlToSave string, alias string) (int64, error) {
-	ret := _m.Called(urlToSave, alias)
+// GetAliasByURL provides a mock function with given fields: ctx, url, owner
+func (_m *URLSaver) GetAliasByURL(ctx context.Context, url string, owner string) (string, error) {
+	ret := _m.Called(ctx, url, owner)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetAliasByURL")
+	}
+
+	var r0 string
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) (string, error)); ok {
+		return rf(ctx, url, owner)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) string); ok {
+		r0 = rf(ctx, url, owner)
+	} else {
+		r0 = ret.Get(0).(string)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, string) error); ok {
+		r1 = rf(ctx, url, owner)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// URLSaver_GetAliasByURL_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GetAliasByURL'
+type URLSaver_GetAliasByURL_Call struct {
+	*mock.Call
+}
+
+// GetAliasByURL is a helper method to define mock.On call
+//   - ctx context.Context
+//   - url string
+//   - owner string
+func (_e *URLSaver_Expecter) GetAliasByURL(ctx interface{}, url interface{}, owner interface{}) *URLSaver_GetAliasByURL_Call {
+	return &URLSaver_GetAliasByURL_Call{Call: _e.mock.On("GetAliasByURL", ctx, url, owner)}
+}
+
+func (_c *URLSaver_GetAliasByURL_Call) Run(run func(ctx context.Context, url string, owner string)) *URLSaver_GetAliasByURL_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(string), args[2].(string))
+	})
+	return _c
+}
+
+func (_c *URLSaver_GetAliasByURL_Call) Return(_a0 string, _a1 error) *URLSaver_GetAliasByURL_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *URLSaver_GetAliasByURL_Call) RunAndReturn(run func(context.Context, string, string) (string, error)) *URLSaver_GetAliasByURL_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// GetCampaign provides a mock function with given fields: ctx, id, owner
+func (_m *URLSaver) GetCampaign(ctx context.Context, id int64, owner string) (storage.Campaign, error) {
+	ret := _m.Called(ctx, id, owner)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetCampaign")
+	}
+
+	var r0 storage.Campaign
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, int64, string) (storage.Campaign, error)); ok {
+		return rf(ctx, id, owner)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, int64, string) storage.Campaign); ok {
+		r0 = rf(ctx, id, owner)
+	} else {
+		r0 = ret.Get(0).(storage.Campaign)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, int64, string) error); ok {
+		r1 = rf(ctx, id, owner)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// URLSaver_GetCampaign_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GetCampaign'
+type URLSaver_GetCampaign_Call struct {
+	*mock.Call
+}
+
+// GetCampaign is a helper method to define mock.On call
+//   - ctx context.Context
+//   - id int64
+//   - owner string
+func (_e *URLSaver_Expecter) GetCampaign(ctx interface{}, id interface{}, owner interface{}) *URLSaver_GetCampaign_Call {
+	return &URLSaver_GetCampaign_Call{Call: _e.mock.On("GetCampaign", ctx, id, owner)}
+}
+
+func (_c *URLSaver_GetCampaign_Call) Run(run func(ctx context.Context, id int64, owner string)) *URLSaver_GetCampaign_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(int64), args[2].(string))
+	})
+	return _c
+}
+
+func (_c *URLSaver_GetCampaign_Call) Return(_a0 storage.Campaign, _a1 error) *URLSaver_GetCampaign_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *URLSaver_GetCampaign_Call) RunAndReturn(run func(context.Context, int64, string) (storage.Campaign, error)) *URLSaver_GetCampaign_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// SaveURL provides a mock function with given fields: ctx, u
+func (_m *URLSaver) SaveURL(ctx context.Context, u storage.URL) (int64, error) {
+	ret := _m.Called(ctx, u)
 
 	if len(ret) == 0 {
 		panic("no return value specified for SaveURL")
@@ -27,17 +149,17 @@ func (_m *URLSaver) SaveURL(urlToSave string, alias string) (int64, error) {
 
 	var r0 int64
 	var r1 error
-	if rf, ok := ret.Get(0).(func(string, string) (int64, error)); ok {
-		return rf(urlToSave, alias)
+	if rf, ok := ret.Get(0).(func(context.Context, storage.URL) (int64, error)); ok {
+		return rf(ctx, u)
 	}
-	if rf, ok := ret.Get(0).(func(string, string) int64); ok {
-		r0 = rf(urlToSave, alias)
+	if rf, ok := ret.Get(0).(func(context.Context, storage.URL) int64); ok {
+		r0 = rf(ctx, u)
 	} else {
 		r0 = ret.Get(0).(int64)
 	}
 
-	if rf, ok := ret.Get(1).(func(string, string) error); ok {
-		r1 = rf(urlToSave, alias)
+	if rf, ok := ret.Get(1).(func(context.Context, storage.URL) error); ok {
+		r1 = rf(ctx, u)
 	} else {
 		r1 = ret.Error(1)
 	}
@@ -51,15 +173,15 @@ type URLSaver_SaveURL_Call struct {
 }
 
 // SaveURL is a helper method to define mock.On call
-//   - urlToSave string
-//   - alias string
-func (_e *URLSaver_Expecter) SaveURL(urlToSave interface{}, alias interface{}) *URLSaver_SaveURL_Call {
-	return &URLSaver_SaveURL_Call{Call: _e.mock.On("SaveURL", urlToSave, alias)}
+//   - ctx context.Context
+//   - u storage.URL
+func (_e *URLSaver_Expecter) SaveURL(ctx interface{}, u interface{}) *URLSaver_SaveURL_Call {
+	return &URLSaver_SaveURL_Call{Call: _e.mock.On("SaveURL", ctx, u)}
 }
 
-func (_c *URLSaver_SaveURL_Call) Run(run func(urlToSave string, alias string)) *URLSaver_SaveURL_Call {
+func (_c *URLSaver_SaveURL_Call) Run(run func(ctx context.Context, u storage.URL)) *URLSaver_SaveURL_Call {
 	_c.Call.Run(func(args mock.Arguments) {
-		run(args[0].(string), args[1].(string))
+		run(args[0].(context.Context), args[1].(storage.URL))
 	})
 	return _c
 }
@@ -69,7 +191,7 @@ func (_c *URLSaver_SaveURL_Call) Return(_a0 int64, _a1 error) *URLSaver_SaveURL_
 	return _c
 }
 
-func (_c *URLSaver_SaveURL_Call) RunAndReturn(run func(string, string) (int64, error)) *URLSaver_SaveURL_Call {
+func (_c *URLSaver_SaveURL_Call) RunAndReturn(run func(context.Context, storage.URL) (int64, error)) *URLSaver_SaveURL_Call {
 	_c.Call.Return(run)
 	return _c
 }
@@ -1,125 +1,783 @@
 package save
 
 import (
+	"bytes"
+	"context"
+	"encoding/json"
 	"errors"
+	"fmt"
+	"io"
 	"log/slog"
+	"maps"
 	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+	"time"
+	mwAnonymous "url-shortener/internal/http-server/middleware/anonymous"
+	"url-shortener/internal/http-server/middleware/auth"
+	"url-shortener/internal/lib/alias"
 	resp "url-shortener/internal/lib/api/response"
+	"url-shortener/internal/lib/domain"
 	"url-shortener/internal/lib/logger/sl"
-	"url-shortener/internal/lib/random"
+	"url-shortener/internal/lib/quota"
+	"url-shortener/internal/lib/shorturl"
+	"url-shortener/internal/lib/tag"
 	"url-shortener/internal/storage"
 
 	"github.com/go-chi/chi/v5/middleware"
 	"github.com/go-chi/render"
 	"github.com/go-playground/validator/v10"
+	"golang.org/x/crypto/bcrypt"
 )
 
 type Request struct {
 	URL   string `json:"url" validate:"required,url"`
 	Alias string `json:"alias,omitempty"`
+	// ExpiresAt и TTL задают срок жизни ссылки; указать можно только одно из них.
+	ExpiresAt *time.Time `json:"expires_at,omitempty"`
+	TTL       string     `json:"ttl,omitempty"`
+	// ActiveFrom и ActiveUntil задают окно, в котором ссылка работает; вне
+	// его ссылка не удаляется, а просто не ведёт на URL.
+	ActiveFrom  *time.Time `json:"active_from,omitempty"`
+	ActiveUntil *time.Time `json:"active_until,omitempty"`
+	// MaxClicks превращает ссылку в одноразовую: после стольких переходов она перестаёт работать.
+	MaxClicks int64 `json:"max_clicks,omitempty" validate:"gte=0"`
+	// Password защищает переход по ссылке; хранится только bcrypt-хеш.
+	Password string `json:"password,omitempty" validate:"max=72"`
+	// ReuseExisting возвращает алиас уже сохранённой ссылки на тот же URL
+	// вместо создания новой, если алиас не указан.
+	ReuseExisting bool `json:"reuse_existing,omitempty"`
+	// RedirectType задаёт код ответа при переходе: 301 и 308 для постоянных
+	// ссылок, 302 и 307 для временных; по умолчанию берётся из конфига.
+	RedirectType int `json:"redirect_type,omitempty" validate:"omitempty,oneof=301 302 307 308"`
+	// CacheMaxAge — сколько секунд браузеры и CDN могут кэшировать
+	// редирект; -1 запрещает кэширование, по умолчанию берётся из конфига.
+	CacheMaxAge int `json:"cache_max_age,omitempty" validate:"min=-1,max=31536000"`
+	// UTM дописывается к целевому URL при переходе.
+	UTM *UTM `json:"utm,omitempty"`
+	// Geo сопоставляет код страны ISO 3166-1 (например, "DE") с URL, на
+	// который ведёт ссылка посетителей из этой страны; остальные попадают на URL.
+	Geo map[string]string `json:"geo,omitempty" validate:"omitempty,max=50,dive,keys,len=2,alpha,endkeys,required,url"`
+	// Devices задаёт отдельные адреса для разных устройств и важнее Geo.
+	Devices *Devices `json:"devices,omitempty"`
+	// Variants делит переходы между несколькими адресами для A/B-тестов.
+	Variants []Variant `json:"variants,omitempty" validate:"omitempty,min=2,max=10,dive"`
+	// Tags группируют ссылки, например по кампании или команде; по тегу
+	// можно отфильтровать список ссылок.
+	Tags []string `json:"tags,omitempty"`
+	// Domain — короткий домен, на котором будет работать ссылка, один из
+	// short_domains конфига; по умолчанию первый из них.
+	Domain string `json:"domain,omitempty"`
+	// OG задаёт превью ссылки в соцсетях и мессенджерах: их краулеры
+	// получают страницу с этими Open Graph тегами вместо редиректа.
+	OG *OpenGraph `json:"og,omitempty"`
+	// Campaign добавляет ссылку в кампанию пользователя. UTM-метки, срок
+	// жизни и домен кампании действуют, если в запросе не указаны свои.
+	Campaign int64 `json:"campaign,omitempty" validate:"gte=0"`
+}
+
+// OpenGraph — заголовок, описание и картинка превью ссылки. Image должен
+// быть абсолютным URL.
+type OpenGraph struct {
+	Title       string `json:"title,omitempty" validate:"max=200"`
+	Description string `json:"description,omitempty" validate:"max=500"`
+	Image       string `json:"image,omitempty" validate:"omitempty,url"`
+}
+
+// Variant — один из адресов A/B-теста. Переходы распределяются
+// пропорционально Weight; Name по умолчанию A, B, C и так далее и
+// записывается в статистику переходов.
+type Variant struct {
+	Name   string `json:"name,omitempty" validate:"max=50"`
+	URL    string `json:"url" validate:"required,url"`
+	Weight int    `json:"weight" validate:"min=1,max=1000"`
+}
+
+// Devices задаёт адреса для iOS, Android, прочих мобильных устройств и
+// компьютеров; это могут быть и deep link'и приложений вроде myapp://item/1.
+// Без адреса для iOS или Android используется Mobile, а без подходящего
+// адреса — URL.
+type Devices struct {
+	IOS     string `json:"ios,omitempty" validate:"omitempty,url"`
+	Android string `json:"android,omitempty" validate:"omitempty,url"`
+	Mobile  string `json:"mobile,omitempty" validate:"omitempty,url"`
+	Desktop string `json:"desktop,omitempty" validate:"omitempty,url"`
+}
+
+// UTM задаёт метки utm_source, utm_medium и utm_campaign. Пустые метки и
+// метки, которые уже есть в URL, не добавляются; {alias} в значении
+// заменяется на алиас ссылки.
+type UTM struct {
+	Source   string `json:"source,omitempty" validate:"max=100"`
+	Medium   string `json:"medium,omitempty" validate:"max=100"`
+	Campaign string `json:"campaign,omitempty" validate:"max=100"`
 }
 
 type Response struct {
 	resp.Response
 	Alias string `json:"alias,omitempty"`
+	// ShortURL — готовая короткая ссылка, например https://sho.rt/abc123.
+	ShortURL string `json:"short_url,omitempty"`
+	// Domain не указывается для ссылок на домене по умолчанию.
+	Domain      string     `json:"domain,omitempty"`
+	ExpiresAt   *time.Time `json:"expires_at,omitempty"`
+	ActiveFrom  *time.Time `json:"active_from,omitempty"`
+	ActiveUntil *time.Time `json:"active_until,omitempty"`
+	MaxClicks   int64      `json:"max_clicks,omitempty"`
+	Protected   bool       `json:"protected,omitempty"`
+	// RedirectType не указывается для ссылок с кодом по умолчанию.
+	RedirectType int `json:"redirect_type,omitempty"`
+	CacheMaxAge  int `json:"cache_max_age,omitempty"`
+	// Title, Description и Image описывают целевую страницу, если
+	// загрузка метаданных включена и удалась.
+	Title       string            `json:"title,omitempty"`
+	Description string            `json:"description,omitempty"`
+	Image       string            `json:"image,omitempty"`
+	UTM         *UTM              `json:"utm,omitempty"`
+	Geo         map[string]string `json:"geo,omitempty"`
+	Devices     *Devices          `json:"devices,omitempty"`
+	Variants    []Variant         `json:"variants,omitempty"`
+	Tags        []string          `json:"tags,omitempty"`
+	OG          *OpenGraph        `json:"og,omitempty"`
+	Campaign    int64             `json:"campaign,omitempty"`
+	// Reused сообщает, что вернули алиас существующей ссылки.
+	Reused bool `json:"reused,omitempty"`
+	// Quota показывает израсходованную квоту, когда она исчерпана.
+	Quota *quota.Usage `json:"quota,omitempty"`
 }
 
+const maxRetries = 5
+
+// Rules reported in details for URLs that passed validation but were
+// refused: ruleDomain for hosts outside the domain policy, ruleSelfReference
+// for links to the service itself, ruleUnsafe for URLs flagged by the
+// checker. ruleShortDomain rejects short domains the service does not
+// answer on. ruleCampaign rejects campaigns the user does not have or
+// that have ended.
 const (
-	aliasLength = 6
-	maxRetries  = 5
+	ruleDomain        = "domain"
+	ruleSelfReference = "self_reference"
+	ruleUnsafe        = "unsafe"
+	ruleTag           = "tag"
+	ruleShortDomain   = "short_domain"
+	ruleCampaign      = "campaign"
 )
 
-
 type URLSaver interface {
-	SaveURL(urlToSave string, alias string) (int64, error)
+	SaveURL(ctx context.Context, u storage.URL) (int64, error)
+	GetAliasByURL(ctx context.Context, url string, owner string) (string, error)
+	GetCampaign(ctx context.Context, id int64, owner string) (storage.Campaign, error)
+}
+
+// AliasGenerator makes aliases for links saved without one.
+type AliasGenerator interface {
+	Generate(ctx context.Context) (string, error)
+}
+
+// MetadataFetcher reads the title and Open Graph tags of the target page.
+type MetadataFetcher interface {
+	Fetch(ctx context.Context, url string) (storage.Metadata, error)
+}
+
+// URLChecker reports a threat type for each unsafe URL, empty for the rest.
+type URLChecker interface {
+	Check(ctx context.Context, urls []string) ([]string, error)
 }
 
-func New(log *slog.Logger, urlSaver URLSaver) http.HandlerFunc {
+// QuotaChecker tells whether a user or an API key may create n more links.
+type QuotaChecker interface {
+	Check(ctx context.Context, owner string, apiKeyID int64, n int) (quota.Usage, error)
+}
+
+// New returns the handler that saves a link. fetcher and checker may be
+// nil to skip fetching metadata and screening the URL; if either of them
+// fails, the link is saved anyway. quotas may be nil to let everyone
+// create any number of links. links builds the short_url of the response
+// and checks the short domain the link is saved on. The handler also
+// serves GET requests, taking the link from the query string.
+func New(
+	log *slog.Logger,
+	urlSaver URLSaver,
+	aliases *alias.Validator,
+	domains *domain.Policy,
+	generator AliasGenerator,
+	fetcher MetadataFetcher,
+	checker URLChecker,
+	quotas QuotaChecker,
+	links *shorturl.Builder,
+) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		const op = "handlers.url.save.New"
 
-		log = log.With(
+		log := log.With(
 			slog.String("op", op),
 			slog.String("request_id", middleware.GetReqID(r.Context())),
 		)
 
-		var req Request
-		err := render.DecodeJSON(r.Body, &req)
+		req, err := decode(r)
 		if err != nil {
 			log.Error("failed to decode request body", sl.Err(err))
 
-			render.JSON(w, r, resp.Error("failed to decode request"))
+			reply(w, r, resp.Error("failed to decode request"))
 
 			return
 		}
 
-		log.Info("request body decoded", slog.Any("request", req))
+		log.Info("request body decoded", slog.String("url", req.URL), slog.String("alias", req.Alias))
 
 		if err := validator.New().Struct(req); err != nil {
 			validateErr := err.(validator.ValidationErrors)
 
 			log.Error("invalide request", sl.Err(err))
 
-			render.JSON(w, r, resp.ValidationError(validateErr))
+			reply(w, r, resp.ValidationError(validateErr))
 
 			return
 		}
 
-		alias := req.Alias
-		if alias == "" {
+		if req.Alias != "" {
+			if err := aliases.Validate(req.Alias); err != nil {
+				log.Info("invalid alias", slog.String("alias", req.Alias), sl.Err(err))
+
+				var validationErr *alias.ValidationError
+				if errors.As(err, &validationErr) {
+					reply(w, r, resp.InvalidField("alias", validationErr.Rule, validationErr.Message))
+				} else {
+					reply(w, r, resp.Error(err.Error()))
+				}
+
+				return
+			}
+		}
+
+		// Every destination of the link, the default URL first, is screened
+		// the same way; fields name them in errors.
+		geo := geoTargets(req.Geo)
+		devices := deviceTargets(req.Devices)
+		fields, targets := []string{"url"}, []string{req.URL}
+		for _, country := range slices.Sorted(maps.Keys(geo)) {
+			fields = append(fields, "geo."+country)
+			targets = append(targets, geo[country])
+		}
+		for _, class := range slices.Sorted(maps.Keys(devices)) {
+			fields = append(fields, "devices."+class)
+			targets = append(targets, devices[class])
+		}
+
+		variants, err := splitVariants(req.Variants)
+		if err != nil {
+			log.Info("invalide request", sl.Err(err))
+
+			reply(w, r, resp.InvalidField("variants", "unique", err.Error()))
+
+			return
+		}
+		for _, v := range variants {
+			fields = append(fields, "variants."+v.Name)
+			targets = append(targets, v.URL)
+		}
+
+		tags, err := tag.NormalizeAll(req.Tags)
+		if err != nil {
+			log.Info("invalide request", sl.Err(err))
+
+			reply(w, r, resp.InvalidField("tags", ruleTag, err.Error()))
+
+			return
+		}
+
+		shortDomain, err := links.Domain(req.Domain)
+		if err != nil {
+			log.Info("unknown short domain", slog.String("domain", req.Domain))
+
+			reply(w, r, resp.InvalidField("domain", ruleShortDomain, "field domain must be one of the short domains of the service"))
+
+			return
+		}
+
+		for i, target := range targets {
+			if err := domains.Check(target, r.Host); err != nil {
+				log.Info("domain not allowed", slog.String("url", target), sl.Err(err))
+
+				rule := ruleDomain
+				if errors.Is(err, domain.ErrSelfReference) {
+					rule = ruleSelfReference
+				}
+				reply(w, r, resp.InvalidField(fields[i], rule, err.Error()))
+
+				return
+			}
+		}
+
+		if checker != nil {
+			threats, err := checker.Check(r.Context(), targets)
+			if err != nil {
+				log.Error("failed to check url reputation", sl.Err(err))
+			} else {
+				for i, threat := range threats {
+					if threat == "" {
+						continue
+					}
+
+					log.Warn("unsafe url rejected", slog.String("url", targets[i]), slog.String("threat", threat))
+
+					reply(w, r, resp.InvalidField(fields[i], ruleUnsafe, "url is flagged as unsafe: "+threat))
+
+					return
+				}
+			}
+		}
+
+		if req.ReuseExisting && (req.ExpiresAt != nil || req.TTL != "" || req.ActiveFrom != nil || req.ActiveUntil != nil || req.MaxClicks > 0 || req.Password != "" || req.RedirectType != 0 || req.CacheMaxAge != 0 || req.UTM != nil || len(geo) > 0 || len(devices) > 0 || len(variants) > 0 || len(tags) > 0 || shortDomain != "" || req.OG != nil || req.Campaign != 0) {
+			log.Info("invalide request: reuse_existing with link limits")
+
+			reply(w, r, resp.Error("reuse_existing cannot be combined with expires_at, ttl, active_from, active_until, max_clicks, password, redirect_type, cache_max_age, utm, geo, devices, variants, tags, domain, og or campaign"))
+
+			return
+		}
+
+		visitor, anonymous := mwAnonymous.FromContext(r.Context())
+		if anonymous && req.ReuseExisting {
+			log.Info("invalide request: reuse_existing without an account")
+
+			reply(w, r, resp.Error("reuse_existing needs an account"))
+
+			return
+		}
+
+		var campaign storage.Campaign
+		if req.Campaign != 0 {
+			if anonymous {
+				log.Info("invalide request: campaign without an account")
+
+				reply(w, r, resp.Error("campaign needs an account"))
+
+				return
+			}
+
+			campaign, err = urlSaver.GetCampaign(r.Context(), req.Campaign, auth.OwnerFromContext(r.Context()))
+			if errors.Is(err, storage.ErrCampaignNotFound) {
+				log.Info("campaign not found", slog.Int64("campaign", req.Campaign))
+
+				reply(w, r, resp.InvalidField("campaign", ruleCampaign, "campaign not found"))
+
+				return
+			}
+			if err != nil {
+				log.Error("failed to get campaign", sl.Err(err))
+				reply(w, r, resp.Error("failed to save url"))
+				return
+			}
+			if campaign.Ended(time.Now()) {
+				log.Info("campaign has ended", slog.Int64("campaign", req.Campaign))
+
+				reply(w, r, resp.InvalidField("campaign", ruleCampaign, "campaign has ended"))
+
+				return
+			}
+		}
+
+		expiresAt, err := expiration(req, time.Now())
+		if err != nil {
+			log.Error("invalide request", sl.Err(err))
+
+			reply(w, r, resp.Error(err.Error()))
+
+			return
+		}
+		if expiresAt.IsZero() {
+			expiresAt = campaign.ExpiresAt
+		}
+		// Links created without an account always expire.
+		if anonymous {
+			limit := time.Now().Add(visitor.LinkTTL).UTC()
+			if expiresAt.IsZero() || expiresAt.After(limit) {
+				expiresAt = limit
+			}
+		}
+
+		activeFrom, activeUntil, err := activeWindow(req, time.Now())
+		if err != nil {
+			log.Error("invalide request", sl.Err(err))
+
+			reply(w, r, resp.Error(err.Error()))
+
+			return
+		}
+
+		u := storage.URL{
+			URL:           req.URL,
+			Alias:         aliases.Sign(req.Alias),
+			ExpiresAt:     expiresAt,
+			ActiveFrom:    activeFrom,
+			ActiveUntil:   activeUntil,
+			MaxClicks:     req.MaxClicks,
+			RedirectType:  req.RedirectType,
+			CacheMaxAge:   req.CacheMaxAge,
+			GeoTargets:    geo,
+			DeviceTargets: devices,
+			Variants:      variants,
+			Tags:          tags,
+			Domain:        shortDomain,
+		}
+		if req.Campaign != 0 {
+			u.CampaignID = campaign.ID
+			u.UTM = campaign.UTM
+			if req.Domain == "" {
+				u.Domain = campaign.Domain
+			}
+		}
+		if req.UTM != nil {
+			u.UTM = storage.UTM(*req.UTM)
+		}
+		if req.OG != nil {
+			u.OpenGraph = storage.Metadata(*req.OG)
+		}
+		if user, ok := auth.UserFromContext(r.Context()); ok {
+			u.Owner = user.Username
+		}
+		if keyID, ok := auth.APIKeyIDFromContext(r.Context()); ok {
+			u.APIKeyID = keyID
+		}
+
+		if fetcher != nil {
+			u.Metadata, err = fetcher.Fetch(r.Context(), u.URL)
+			if err != nil {
+				log.Info("failed to fetch metadata", slog.String("url", u.URL), sl.Err(err))
+			}
+		}
+
+		if req.Password != "" {
+			hash, err := bcrypt.GenerateFromPassword([]byte(req.Password), bcrypt.DefaultCost)
+			if err != nil {
+				log.Error("failed to hash password", sl.Err(err))
+				reply(w, r, resp.Error("failed to save url"))
+				return
+			}
+			u.PasswordHash = string(hash)
+		}
+
+		if u.Alias == "" && req.ReuseExisting {
+			existing, err := urlSaver.GetAliasByURL(r.Context(), u.URL, u.Owner)
+			if err == nil {
+				log.Info("existing url reused", slog.String("alias", existing))
+
+				u.Alias = existing
+				res := responseOK(u, links.URL(r, u.Domain, u.Alias))
+				res.Reused = true
+				reply(w, r, res)
+				return
+			}
+
+			if !errors.Is(err, storage.ErrUrlNotFound) {
+				log.Error("failed to get alias by url", sl.Err(err))
+				reply(w, r, resp.Error("failed to save url"))
+				return
+			}
+		}
+
+		if quotas != nil {
+			usage, err := quotas.Check(r.Context(), auth.OwnerFromContext(r.Context()), u.APIKeyID, 1)
+			if errors.Is(err, quota.ErrExceeded) {
+				log.Info("quota exceeded", slog.Int64("links", usage.Links), slog.Int64("today", usage.Today))
+				render.Status(r, http.StatusForbidden)
+				reply(w, r, Response{Response: resp.Error("quota exceeded"), Quota: &usage})
+				return
+			}
+			if err != nil {
+				log.Error("failed to check quota", sl.Err(err))
+				reply(w, r, resp.Error("failed to save url"))
+				return
+			}
+		}
+
+		if u.Alias == "" {
 			// Генерируем уникальный алиас с повторными попытками
 			for i := 0; i < maxRetries; i++ {
-				alias = random.NewRandomString(aliasLength)
+				u.Alias, err = generator.Generate(r.Context())
+				if err != nil {
+					log.Error("failed to generate alias", sl.Err(err))
+					reply(w, r, resp.Error("failed to save url"))
+					return
+				}
 
-				id, err := urlSaver.SaveURL(req.URL, alias)
+				id, err := urlSaver.SaveURL(r.Context(), u)
 				if err == nil {
 					// Успешно сохранили
-					log.Info("url added", slog.String("alias", alias), slog.Int64("id", id))
+					log.Info("url added", slog.String("alias", u.Alias), slog.Int64("id", id))
 
-					render.JSON(w, r, Response{
-						Response: resp.OK(),
-						Alias:    alias,
-					})
+					reply(w, r, responseOK(u, links.URL(r, u.Domain, u.Alias)))
 					return
 				}
 
 				if !errors.Is(err, storage.ErrUrlExists) {
 					// Другая ошибка, не коллизия
 					log.Error("failed to save url", sl.Err(err))
-					render.JSON(w, r, resp.Error("failed to save url"))
+					reply(w, r, resp.Error("failed to save url"))
 					return
 				}
 
 				// Коллизия алиаса, пробуем снова
-				log.Info("alias collision, retrying", slog.String("alias", alias), slog.Int("attempt", i+1))
+				log.Info("alias collision, retrying", slog.String("alias", u.Alias), slog.Int("attempt", i+1))
 			}
 
 			// Не удалось сгенерировать уникальный алиас за maxRetries попыток
 			log.Error("failed to generate unique alias after retries", slog.Int("max_retries", maxRetries))
-			render.JSON(w, r, resp.Error("failed to generate unique alias"))
+			reply(w, r, resp.Error("failed to generate unique alias"))
 			return
 		}
 
 		// Пользователь предоставил свой алиас
-		id, err := urlSaver.SaveURL(req.URL, alias)
+		id, err := urlSaver.SaveURL(r.Context(), u)
 		if errors.Is(err, storage.ErrUrlExists) {
 			log.Info("url already exists", slog.String("url", req.URL))
-			render.JSON(w, r, resp.Error("url already exists"))
+			reply(w, r, resp.Error("url already exists"))
 			return
 		}
 
 		if err != nil {
 			log.Error("failed to save url", sl.Err(err))
-			render.JSON(w, r, resp.Error("failed to save url"))
+			reply(w, r, resp.Error("failed to save url"))
 
 			return
 		}
 
-		log.Info("url added", slog.String("alias", alias), slog.Int64("id", id))
+		log.Info("url added", slog.String("alias", u.Alias), slog.Int64("id", id))
+
+		reply(w, r, responseOK(u, links.URL(r, u.Domain, u.Alias)))
+	}
+}
+
+// geoTargets приводит коды стран к верхнему регистру, в котором их
+// возвращает база GeoIP.
+func geoTargets(geo map[string]string) storage.Targets {
+	if len(geo) == 0 {
+		return nil
+	}
+
+	targets := make(storage.Targets, len(geo))
+	for country, target := range geo {
+		targets[strings.ToUpper(country)] = target
+	}
+
+	return targets
+}
+
+// deviceTargets собирает непустые адреса Devices по классам устройств.
+func deviceTargets(d *Devices) storage.Targets {
+	if d == nil {
+		return nil
+	}
+
+	targets := make(storage.Targets)
+	for class, target := range map[string]string{
+		storage.DeviceIOS:     d.IOS,
+		storage.DeviceAndroid: d.Android,
+		storage.DeviceMobile:  d.Mobile,
+		storage.DeviceDesktop: d.Desktop,
+	} {
+		if target != "" {
+			targets[class] = target
+		}
+	}
+	if len(targets) == 0 {
+		return nil
+	}
+
+	return targets
+}
+
+// splitVariants даёт безымянным вариантам имена по их номеру (A, B, ...) и
+// проверяет, что имена не повторяются.
+func splitVariants(req []Variant) (storage.Variants, error) {
+	if len(req) == 0 {
+		return nil, nil
+	}
+
+	variants := make(storage.Variants, len(req))
+	seen := make(map[string]bool, len(req))
+	for i, v := range req {
+		if v.Name == "" {
+			v.Name = string(rune('A' + i))
+		}
+		if seen[v.Name] {
+			return nil, fmt.Errorf("variant name %q is used more than once", v.Name)
+		}
+		seen[v.Name] = true
+
+		variants[i] = storage.Variant(v)
+	}
+
+	return variants, nil
+}
+
+// expiration возвращает момент истечения ссылки по expires_at или ttl из
+// запроса; нулевое время означает бессрочную ссылку.
+func expiration(req Request, now time.Time) (time.Time, error) {
+	switch {
+	case req.ExpiresAt != nil && req.TTL != "":
+		return time.Time{}, errors.New("only one of expires_at and ttl may be set")
+	case req.ExpiresAt != nil:
+		if !req.ExpiresAt.After(now) {
+			return time.Time{}, errors.New("field expires_at must be in the future")
+		}
+		return req.ExpiresAt.UTC(), nil
+	case req.TTL != "":
+		ttl, err := time.ParseDuration(req.TTL)
+		if err != nil || ttl <= 0 {
+			return time.Time{}, errors.New("field ttl must be a positive duration")
+		}
+		return now.Add(ttl).UTC(), nil
+	}
+
+	return time.Time{}, nil
+}
+
+// activeWindow проверяет active_from и active_until из запроса: окно не
+// может закончиться раньше, чем начнётся, или уже в прошлом.
+func activeWindow(req Request, now time.Time) (time.Time, time.Time, error) {
+	var from, until time.Time
+	if req.ActiveFrom != nil {
+		from = req.ActiveFrom.UTC()
+	}
+	if req.ActiveUntil != nil {
+		until = req.ActiveUntil.UTC()
+		if !until.After(now) {
+			return time.Time{}, time.Time{}, errors.New("field active_until must be in the future")
+		}
+		if !from.IsZero() && !until.After(from) {
+			return time.Time{}, time.Time{}, errors.New("field active_until must be after active_from")
+		}
+	}
+
+	return from, until, nil
+}
+
+// decode reads the request as JSON, or as a form for shell users sending
+// `curl -d url=...`, or from the query string of a GET for clients that
+// cannot send a body. Forms and queries carry only url, alias, ttl and
+// domain. curl labels JSON sent with -d as a form too, so a body starting
+// with { is always JSON.
+func decode(r *http.Request) (Request, error) {
+	if r.Method == http.MethodGet {
+		return fromValues(r.URL.Query()), nil
+	}
+
+	var req Request
+
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		return req, err
+	}
+
+	if render.GetRequestContentType(r) != render.ContentTypeForm || bytes.HasPrefix(bytes.TrimSpace(body), []byte("{")) {
+		err := json.Unmarshal(body, &req)
+		return req, err
+	}
+
+	form, err := url.ParseQuery(string(body))
+	if err != nil {
+		return req, err
+	}
+
+	return fromValues(form), nil
+}
+
+func fromValues(v url.Values) Request {
+	return Request{
+		URL:    v.Get("url"),
+		Alias:  v.Get("alias"),
+		TTL:    v.Get("ttl"),
+		Domain: v.Get("domain"),
+	}
+}
+
+// plainText reports whether the client asked for the bare short URL with
+// Accept: text/plain or ?format=txt. Only the first media type in Accept
+// counts; a missing or */* one keeps JSON.
+func plainText(r *http.Request) bool {
+	accept, _, _ := strings.Cut(r.Header.Get("Accept"), ",")
+
+	return r.URL.Query().Get("format") == "txt" ||
+		render.GetContentType(accept) == render.ContentTypePlainText
+}
+
+// reply writes res, a Response or a resp.Response, as JSON. Clients asking
+// for plain text get a single line instead: the short URL, or the error
+// with 400 unless another status was set, so that `curl -f` fails.
+func reply(w http.ResponseWriter, r *http.Request, res any) {
+	if !plainText(r) {
+		render.JSON(w, r, res)
+		return
+	}
+
+	var out Response
+	switch res := res.(type) {
+	case Response:
+		out = res
+	case resp.Response:
+		out.Response = res
+	}
+
+	if out.Status != resp.StatusOk {
+		if _, ok := r.Context().Value(render.StatusCtxKey).(int); !ok {
+			render.Status(r, http.StatusBadRequest)
+		}
+		render.PlainText(w, r, out.Error+"\n")
+		return
+	}
+
+	render.PlainText(w, r, out.ShortURL+"\n")
+}
 
-		render.JSON(w, r, Response{
-			Response: resp.OK(),
-			Alias:    alias,
-		})
+func responseOK(u storage.URL, shortURL string) Response {
+	res := Response{
+		Response:     resp.OK(),
+		Alias:        u.Alias,
+		ShortURL:     shortURL,
+		Domain:       u.Domain,
+		MaxClicks:    u.MaxClicks,
+		Protected:    u.PasswordHash != "",
+		RedirectType: u.RedirectType,
+		CacheMaxAge:  u.CacheMaxAge,
+		Title:        u.Metadata.Title,
+		Description:  u.Metadata.Description,
+		Image:        u.Metadata.Image,
+		Tags:         u.Tags,
+		Campaign:     u.CampaignID,
+	}
+	if !u.ExpiresAt.IsZero() {
+		res.ExpiresAt = &u.ExpiresAt
+	}
+	if !u.ActiveFrom.IsZero() {
+		res.ActiveFrom = &u.ActiveFrom
+	}
+	if !u.ActiveUntil.IsZero() {
+		res.ActiveUntil = &u.ActiveUntil
+	}
+	if u.UTM != (storage.UTM{}) {
+		utm := UTM(u.UTM)
+		res.UTM = &utm
 	}
+	if len(u.GeoTargets) > 0 {
+		res.Geo = u.GeoTargets
+	}
+	if u.OpenGraph != (storage.Metadata{}) {
+		og := OpenGraph(u.OpenGraph)
+		res.OG = &og
+	}
+	for _, v := range u.Variants {
+		res.Variants = append(res.Variants, Variant(v))
+	}
+	if len(u.DeviceTargets) > 0 {
+		res.Devices = &Devices{
+			IOS:     u.DeviceTargets[storage.DeviceIOS],
+			Android: u.DeviceTargets[storage.DeviceAndroid],
+			Mobile:  u.DeviceTargets[storage.DeviceMobile],
+			Desktop: u.DeviceTargets[storage.DeviceDesktop],
+		}
+	}
+
+	return res
 }
@@ -2,19 +2,31 @@ package save_test
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
 	"net/http"
 	"net/http/httptest"
+	"slices"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/bcrypt"
 
 	"url-shortener/internal/http-server/handlers/url/save"
 	"url-shortener/internal/http-server/handlers/url/save/mocks"
+	mwAnonymous "url-shortener/internal/http-server/middleware/anonymous"
+	"url-shortener/internal/http-server/middleware/auth"
+	"url-shortener/internal/lib/alias"
+	"url-shortener/internal/lib/domain"
 	"url-shortener/internal/lib/logger/handlers/slogdiscard"
+	"url-shortener/internal/lib/quota"
+	"url-shortener/internal/lib/shorturl"
+	"url-shortener/internal/storage"
+	"url-shortener/internal/storage/memory"
 )
 
 func TestSaveHandler(t *testing.T) {
@@ -22,6 +34,8 @@ func TestSaveHandler(t *testing.T) {
 		name      string
 		alias     string
 		url       string
+		ttl       string
+		password  string
 		respError string
 		mockError error
 	}{
@@ -39,23 +53,56 @@ func TestSaveHandler(t *testing.T) {
 			name:      "Empty url",
 			alias:     "some_alias",
 			url:       "",
-			respError: "field URL is a reqired field",
+			respError: "field URL is a required field",
 		},
 		{
 			name:      "Invalid URL",
 			alias:     "test_alias",
 			url:       "Some invalid URL",
-			respError: "fiald URL is not a valid URL",
+			respError: "field URL is not valid",
 		},
 		{
 			name:      "SaveURL Error",
 			alias:     "test_alias",
 			url:       "https://google.com",
-			respError: "failed to add url",
+			respError: "failed to save url",
 			mockError: errors.New("unexpected error"),
 		},
+		{
+			name:  "With TTL",
+			alias: "test_alias",
+			url:   "https://google.com",
+			ttl:   "1h",
+		},
+		{
+			name:      "Invalid TTL",
+			alias:     "test_alias",
+			url:       "https://google.com",
+			ttl:       "-1h",
+			respError: "field ttl must be a positive duration",
+		},
+		{
+			name:     "With password",
+			alias:    "test_alias",
+			url:      "https://google.com",
+			password: "secret",
+		},
+		{
+			name:      "Reserved alias",
+			alias:     "Admin",
+			url:       "https://google.com",
+			respError: "field alias is reserved",
+		},
+		{
+			name:      "Invalid alias",
+			alias:     "../etc",
+			url:       "https://google.com",
+			respError: "field alias contains invalid characters",
+		},
 	}
 
+	aliases := newAliases(t)
+
 	for _, tc := range cases {
 		tc := tc
 
@@ -65,14 +112,21 @@ func TestSaveHandler(t *testing.T) {
 			urlSaverMock := mocks.NewURLSaver(t)
 
 			if tc.respError == "" || tc.mockError != nil {
-				urlSaverMock.On("SaveURL", tc.url, mock.AnythingOfType("string")).
+				urlSaverMock.On("SaveURL", mock.Anything, mock.MatchedBy(func(u storage.URL) bool {
+					if tc.password != "" &&
+						bcrypt.CompareHashAndPassword([]byte(u.PasswordHash), []byte(tc.password)) != nil {
+						return false
+					}
+					return u.URL == tc.url && u.Alias != "" && u.ExpiresAt.IsZero() == (tc.ttl == "")
+				})).
 					Return(int64(1), tc.mockError).
 					Once()
 			}
 
-			handler := save.New(slogdiscard.NewDiscardLogger(), urlSaverMock)
+			handler := save.New(slogdiscard.NewDiscardLogger(), urlSaverMock, aliases, newDomains(t, domain.Rules{}), alias.DefaultRandom(), nil, nil, nil, newLinks(t))
 
-			input := fmt.Sprintf(`{"url": "%s", "alias": "%s"}`, tc.url, tc.alias)
+			input := fmt.Sprintf(`{"url": "%s", "alias": "%s", "ttl": "%s", "password": "%s"}`,
+				tc.url, tc.alias, tc.ttl, tc.password)
 
 			req, err := http.NewRequest(http.MethodPost, "/save", bytes.NewReader([]byte(input)))
 			require.NoError(t, err)
@@ -90,8 +144,846 @@ func TestSaveHandler(t *testing.T) {
 
 			require.Equal(t, tc.respError, resp.Error)
 
+			if tc.respError == "" {
+				require.Equal(t, tc.ttl != "", resp.ExpiresAt != nil)
+				require.Equal(t, tc.password != "", resp.Protected)
+				require.Equal(t, "https://sho.rt/"+resp.Alias, resp.ShortURL)
+			}
+
 			// TODO: add more checks
 
 		})
 	}
 }
+
+func TestSaveHandler_AliasDetails(t *testing.T) {
+	handler := save.New(slogdiscard.NewDiscardLogger(), mocks.NewURLSaver(t), newAliases(t), newDomains(t, domain.Rules{}), alias.DefaultRandom(), nil, nil, nil, newLinks(t))
+
+	req := httptest.NewRequest(http.MethodPost, "/save", bytes.NewReader([]byte(`{"url": "https://google.com", "alias": "ab"}`)))
+	rr := httptest.NewRecorder()
+	handler.ServeHTTP(rr, req)
+
+	var resp save.Response
+	require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &resp))
+
+	require.Len(t, resp.Details, 1)
+	require.Equal(t, "alias", resp.Details[0].Field)
+	require.Equal(t, alias.RuleMinLength, resp.Details[0].Rule)
+	require.Equal(t, resp.Error, resp.Details[0].Message)
+}
+
+func TestSaveHandler_ReuseExisting(t *testing.T) {
+	const url = "https://google.com"
+
+	cases := []struct {
+		name      string
+		input     string
+		existing  string
+		respError string
+		reused    bool
+	}{
+		{
+			name:     "Existing alias",
+			input:    `{"url": "https://google.com", "reuse_existing": true}`,
+			existing: "google",
+			reused:   true,
+		},
+		{
+			name:  "Not found",
+			input: `{"url": "https://google.com", "reuse_existing": true}`,
+		},
+		{
+			name:      "With TTL",
+			input:     `{"url": "https://google.com", "ttl": "1h", "reuse_existing": true}`,
+			respError: "reuse_existing cannot be combined with expires_at, ttl, active_from, active_until, max_clicks, password, redirect_type, cache_max_age, utm, geo, devices, variants, tags, domain, og or campaign",
+		},
+	}
+
+	for _, tc := range cases {
+		tc := tc
+
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			urlSaverMock := mocks.NewURLSaver(t)
+
+			if tc.respError == "" {
+				lookupErr := error(nil)
+				if tc.existing == "" {
+					lookupErr = storage.ErrUrlNotFound
+				}
+				urlSaverMock.On("GetAliasByURL", mock.Anything, url, "").
+					Return(tc.existing, lookupErr).
+					Once()
+			}
+			if tc.respError == "" && !tc.reused {
+				urlSaverMock.On("SaveURL", mock.Anything, mock.Anything).
+					Return(int64(1), nil).
+					Once()
+			}
+
+			handler := save.New(slogdiscard.NewDiscardLogger(), urlSaverMock, newAliases(t), newDomains(t, domain.Rules{}), alias.DefaultRandom(), nil, nil, nil, newLinks(t))
+
+			req := httptest.NewRequest(http.MethodPost, "/save", bytes.NewReader([]byte(tc.input)))
+			rr := httptest.NewRecorder()
+			handler.ServeHTTP(rr, req)
+
+			var resp save.Response
+			require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &resp))
+
+			require.Equal(t, tc.respError, resp.Error)
+			require.Equal(t, tc.reused, resp.Reused)
+			if tc.reused {
+				require.Equal(t, tc.existing, resp.Alias)
+			}
+		})
+	}
+}
+
+type fetcherFunc func(ctx context.Context, url string) (storage.Metadata, error)
+
+func (f fetcherFunc) Fetch(ctx context.Context, url string) (storage.Metadata, error) {
+	return f(ctx, url)
+}
+
+func TestSaveHandler_Metadata(t *testing.T) {
+	meta := storage.Metadata{Title: "Google", Description: "Search", Image: "https://google.com/logo.png"}
+
+	cases := []struct {
+		name     string
+		fetchErr error
+		wantMeta storage.Metadata
+	}{
+		{
+			name:     "Fetched",
+			wantMeta: meta,
+		},
+		{
+			name:     "Fetch Error",
+			fetchErr: errors.New("timeout"),
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			fetcher := fetcherFunc(func(_ context.Context, url string) (storage.Metadata, error) {
+				require.Equal(t, "https://google.com", url)
+				if tc.fetchErr != nil {
+					return storage.Metadata{}, tc.fetchErr
+				}
+				return meta, nil
+			})
+
+			urlSaverMock := mocks.NewURLSaver(t)
+			urlSaverMock.On("SaveURL", mock.Anything, mock.MatchedBy(func(u storage.URL) bool {
+				return u.Metadata == tc.wantMeta
+			})).Return(int64(1), nil).Once()
+
+			handler := save.New(slogdiscard.NewDiscardLogger(), urlSaverMock, newAliases(t), newDomains(t, domain.Rules{}), alias.DefaultRandom(), fetcher, nil, nil, newLinks(t))
+
+			req := httptest.NewRequest(http.MethodPost, "/save", bytes.NewReader([]byte(`{"url": "https://google.com"}`)))
+			rr := httptest.NewRecorder()
+			handler.ServeHTTP(rr, req)
+
+			var resp save.Response
+			require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &resp))
+
+			require.Empty(t, resp.Error)
+			require.Equal(t, tc.wantMeta.Title, resp.Title)
+			require.Equal(t, tc.wantMeta.Description, resp.Description)
+			require.Equal(t, tc.wantMeta.Image, resp.Image)
+		})
+	}
+}
+
+type checkerFunc func(ctx context.Context, urls []string) ([]string, error)
+
+func (f checkerFunc) Check(ctx context.Context, urls []string) ([]string, error) {
+	return f(ctx, urls)
+}
+
+func TestSaveHandler_Unsafe(t *testing.T) {
+	cases := []struct {
+		name      string
+		threat    string
+		checkErr  error
+		wantSaved bool
+	}{
+		{
+			name:   "Flagged",
+			threat: "MALWARE",
+		},
+		{
+			name:      "Clean",
+			wantSaved: true,
+		},
+		{
+			name:      "Checker Error",
+			checkErr:  errors.New("unavailable"),
+			wantSaved: true,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			checker := checkerFunc(func(_ context.Context, urls []string) ([]string, error) {
+				require.Equal(t, []string{"https://google.com"}, urls)
+				if tc.checkErr != nil {
+					return nil, tc.checkErr
+				}
+				return []string{tc.threat}, nil
+			})
+
+			urlSaverMock := mocks.NewURLSaver(t)
+			if tc.wantSaved {
+				urlSaverMock.On("SaveURL", mock.Anything, mock.AnythingOfType("storage.URL")).
+					Return(int64(1), nil).Once()
+			}
+
+			handler := save.New(slogdiscard.NewDiscardLogger(), urlSaverMock, newAliases(t), newDomains(t, domain.Rules{}), alias.DefaultRandom(), nil, checker, nil, newLinks(t))
+
+			req := httptest.NewRequest(http.MethodPost, "/save", bytes.NewReader([]byte(`{"url": "https://google.com"}`)))
+			rr := httptest.NewRecorder()
+			handler.ServeHTTP(rr, req)
+
+			var resp save.Response
+			require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &resp))
+
+			if tc.wantSaved {
+				require.Empty(t, resp.Error)
+				return
+			}
+
+			require.Equal(t, "url is flagged as unsafe: MALWARE", resp.Error)
+			require.Len(t, resp.Details, 1)
+			require.Equal(t, "url", resp.Details[0].Field)
+			require.Equal(t, "unsafe", resp.Details[0].Rule)
+		})
+	}
+}
+
+func TestSaveHandler_DomainNotAllowed(t *testing.T) {
+	domains := newDomains(t, domain.Rules{Block: []string{"*.evil.com"}})
+	handler := save.New(slogdiscard.NewDiscardLogger(), mocks.NewURLSaver(t), newAliases(t), domains, alias.DefaultRandom(), nil, nil, nil, newLinks(t))
+
+	req := httptest.NewRequest(http.MethodPost, "/save", bytes.NewReader([]byte(`{"url": "https://www.evil.com/login"}`)))
+	rr := httptest.NewRecorder()
+	handler.ServeHTTP(rr, req)
+
+	var resp save.Response
+	require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &resp))
+
+	require.Equal(t, "domain not allowed: www.evil.com", resp.Error)
+	require.Len(t, resp.Details, 1)
+	require.Equal(t, "url", resp.Details[0].Field)
+	require.Equal(t, "domain", resp.Details[0].Rule)
+}
+
+func TestSaveHandler_SelfReference(t *testing.T) {
+	domains := newDomains(t, domain.Rules{Self: []string{"sho.rt"}, SelfRequestHost: true})
+	handler := save.New(slogdiscard.NewDiscardLogger(), mocks.NewURLSaver(t), newAliases(t), domains, alias.DefaultRandom(), nil, nil, nil, newLinks(t))
+
+	for _, body := range []string{
+		`{"url": "https://sho.rt/abc123"}`,
+		// httptest requests are sent to example.com.
+		`{"url": "https://google.com", "geo": {"DE": "http://example.com/abc123"}}`,
+	} {
+		req := httptest.NewRequest(http.MethodPost, "/save", bytes.NewReader([]byte(body)))
+		rr := httptest.NewRecorder()
+		handler.ServeHTTP(rr, req)
+
+		var resp save.Response
+		require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &resp))
+
+		require.Len(t, resp.Details, 1, body)
+		require.Equal(t, "self_reference", resp.Details[0].Rule)
+	}
+}
+
+func TestSaveHandler_Geo(t *testing.T) {
+	urlSaverMock := mocks.NewURLSaver(t)
+	urlSaverMock.On("SaveURL", mock.Anything, mock.MatchedBy(func(u storage.URL) bool {
+		return u.URL == "https://google.com" &&
+			len(u.GeoTargets) == 1 && u.GeoTargets["DE"] == "https://google.de"
+	})).Return(int64(1), nil).Once()
+
+	handler := save.New(slogdiscard.NewDiscardLogger(), urlSaverMock, newAliases(t), newDomains(t, domain.Rules{}), alias.DefaultRandom(), nil, nil, nil, newLinks(t))
+
+	body := `{"url": "https://google.com", "alias": "geo", "geo": {"de": "https://google.de"}}`
+	req := httptest.NewRequest(http.MethodPost, "/save", bytes.NewReader([]byte(body)))
+	rr := httptest.NewRecorder()
+	handler.ServeHTTP(rr, req)
+
+	var resp save.Response
+	require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &resp))
+
+	require.Empty(t, resp.Error)
+	require.Equal(t, map[string]string{"DE": "https://google.de"}, resp.Geo)
+}
+
+func TestSaveHandler_Devices(t *testing.T) {
+	urlSaverMock := mocks.NewURLSaver(t)
+	urlSaverMock.On("SaveURL", mock.Anything, mock.MatchedBy(func(u storage.URL) bool {
+		return len(u.DeviceTargets) == 2 &&
+			u.DeviceTargets[storage.DeviceIOS] == "myapp://item/1" &&
+			u.DeviceTargets[storage.DeviceAndroid] == "https://play.google.com/store/apps/details?id=com.example"
+	})).Return(int64(1), nil).Once()
+
+	handler := save.New(slogdiscard.NewDiscardLogger(), urlSaverMock, newAliases(t), newDomains(t, domain.Rules{}), alias.DefaultRandom(), nil, nil, nil, newLinks(t))
+
+	body := `{"url": "https://example.com/item/1", "alias": "app", "devices": {
+		"ios": "myapp://item/1",
+		"android": "https://play.google.com/store/apps/details?id=com.example"
+	}}`
+	req := httptest.NewRequest(http.MethodPost, "/save", bytes.NewReader([]byte(body)))
+	rr := httptest.NewRecorder()
+	handler.ServeHTTP(rr, req)
+
+	var resp save.Response
+	require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &resp))
+
+	require.Empty(t, resp.Error)
+	require.Equal(t, &save.Devices{
+		IOS:     "myapp://item/1",
+		Android: "https://play.google.com/store/apps/details?id=com.example",
+	}, resp.Devices)
+}
+
+func TestSaveHandler_OpenGraph(t *testing.T) {
+	og := storage.Metadata{
+		Title:       "Summer sale",
+		Description: "Up to 50% off",
+		Image:       "https://cdn.example.com/sale.png",
+	}
+
+	urlSaverMock := mocks.NewURLSaver(t)
+	urlSaverMock.On("SaveURL", mock.Anything, mock.MatchedBy(func(u storage.URL) bool {
+		return u.OpenGraph == og && u.Metadata == (storage.Metadata{})
+	})).Return(int64(1), nil).Once()
+
+	handler := save.New(slogdiscard.NewDiscardLogger(), urlSaverMock, newAliases(t), newDomains(t, domain.Rules{}), alias.DefaultRandom(), nil, nil, nil, newLinks(t))
+
+	body := `{"url": "https://example.com/shop", "alias": "sale", "og": {
+		"title": "Summer sale",
+		"description": "Up to 50% off",
+		"image": "https://cdn.example.com/sale.png"
+	}}`
+	req := httptest.NewRequest(http.MethodPost, "/save", bytes.NewReader([]byte(body)))
+	rr := httptest.NewRecorder()
+	handler.ServeHTTP(rr, req)
+
+	var resp save.Response
+	require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &resp))
+
+	require.Empty(t, resp.Error)
+	require.Equal(t, &save.OpenGraph{
+		Title:       "Summer sale",
+		Description: "Up to 50% off",
+		Image:       "https://cdn.example.com/sale.png",
+	}, resp.OG)
+
+	body = `{"url": "https://example.com/shop", "alias": "sale", "og": {"image": "sale.png"}}`
+	req = httptest.NewRequest(http.MethodPost, "/save", bytes.NewReader([]byte(body)))
+	rr = httptest.NewRecorder()
+	handler.ServeHTTP(rr, req)
+
+	resp = save.Response{}
+	require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &resp))
+
+	require.Equal(t, "field Image is not valid", resp.Error)
+}
+
+func TestSaveHandler_Campaign(t *testing.T) {
+	expiresAt := time.Now().Add(time.Hour).UTC().Truncate(time.Second)
+	campaign := storage.Campaign{
+		ID:        3,
+		Name:      "spring",
+		Owner:     "alice",
+		UTM:       storage.UTM{Source: "newsletter", Campaign: "spring"},
+		ExpiresAt: expiresAt,
+		Domain:    "go.acme.com",
+	}
+
+	cases := []struct {
+		name      string
+		body      string
+		campaign  storage.Campaign
+		mockError error
+		respError string
+		want      storage.URL
+	}{
+		{
+			name:     "Campaign settings",
+			body:     `{"url": "https://example.com", "alias": "spring1", "campaign": 3}`,
+			campaign: campaign,
+			want: storage.URL{
+				CampaignID: 3,
+				UTM:        campaign.UTM,
+				ExpiresAt:  expiresAt,
+				Domain:     "go.acme.com",
+			},
+		},
+		{
+			name:     "Own settings win",
+			body:     `{"url": "https://example.com", "alias": "spring1", "campaign": 3, "utm": {"source": "ads"}, "domain": "sho.rt", "ttl": "10m"}`,
+			campaign: campaign,
+			want: storage.URL{
+				CampaignID: 3,
+				UTM:        storage.UTM{Source: "ads"},
+				Domain:     "sho.rt",
+			},
+		},
+		{
+			name:      "Unknown campaign",
+			body:      `{"url": "https://example.com", "campaign": 3}`,
+			mockError: storage.ErrCampaignNotFound,
+			respError: "campaign not found",
+		},
+		{
+			name:      "Ended campaign",
+			body:      `{"url": "https://example.com", "campaign": 3}`,
+			campaign:  storage.Campaign{ID: 3, ExpiresAt: time.Now().Add(-time.Hour)},
+			respError: "campaign has ended",
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			urlSaverMock := mocks.NewURLSaver(t)
+			urlSaverMock.On("GetCampaign", mock.Anything, int64(3), "alice").Return(tc.campaign, tc.mockError).Once()
+			if tc.respError == "" {
+				urlSaverMock.On("SaveURL", mock.Anything, mock.MatchedBy(func(u storage.URL) bool {
+					// A ttl of the link itself ends before the campaign.
+					expiry := u.ExpiresAt.Equal(tc.want.ExpiresAt) ||
+						tc.want.ExpiresAt.IsZero() && u.ExpiresAt.Before(expiresAt)
+					return expiry && u.CampaignID == tc.want.CampaignID && u.UTM == tc.want.UTM && u.Domain == tc.want.Domain
+				})).Return(int64(1), nil).Once()
+			}
+
+			handler := save.New(slogdiscard.NewDiscardLogger(), urlSaverMock, newAliases(t), newDomains(t, domain.Rules{}), alias.DefaultRandom(), nil, nil, nil, newLinks(t))
+
+			req := httptest.NewRequest(http.MethodPost, "/save", bytes.NewReader([]byte(tc.body)))
+			req = req.WithContext(auth.WithUser(req.Context(), storage.User{Username: "alice", Role: storage.RoleUser}))
+			rr := httptest.NewRecorder()
+			handler.ServeHTTP(rr, req)
+
+			var resp save.Response
+			require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &resp))
+
+			require.Equal(t, tc.respError, resp.Error)
+			if tc.respError == "" {
+				require.Equal(t, int64(3), resp.Campaign)
+				require.Equal(t, tc.want.Domain, resp.Domain)
+			}
+		})
+	}
+}
+
+func TestSaveHandler_Variants(t *testing.T) {
+	cases := []struct {
+		name      string
+		variants  string
+		respError string
+		want      []save.Variant
+	}{
+		{
+			name:     "Default names",
+			variants: `[{"url": "https://google.com/a", "weight": 3}, {"name": "new", "url": "https://google.com/b", "weight": 1}]`,
+			want: []save.Variant{
+				{Name: "A", URL: "https://google.com/a", Weight: 3},
+				{Name: "new", URL: "https://google.com/b", Weight: 1},
+			},
+		},
+		{
+			name:      "Duplicate names",
+			variants:  `[{"url": "https://google.com/a", "weight": 1}, {"name": "A", "url": "https://google.com/b", "weight": 1}]`,
+			respError: `variant name "A" is used more than once`,
+		},
+		{
+			name:      "Single variant",
+			variants:  `[{"url": "https://google.com/a", "weight": 1}]`,
+			respError: "field Variants is not valid",
+		},
+		{
+			name:      "Zero weight",
+			variants:  `[{"url": "https://google.com/a", "weight": 1}, {"url": "https://google.com/b", "weight": 0}]`,
+			respError: "field Weight is not valid",
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			urlSaverMock := mocks.NewURLSaver(t)
+			if tc.respError == "" {
+				urlSaverMock.On("SaveURL", mock.Anything, mock.MatchedBy(func(u storage.URL) bool {
+					return len(u.Variants) == len(tc.want)
+				})).Return(int64(1), nil).Once()
+			}
+
+			handler := save.New(slogdiscard.NewDiscardLogger(), urlSaverMock, newAliases(t), newDomains(t, domain.Rules{}), alias.DefaultRandom(), nil, nil, nil, newLinks(t))
+
+			body := fmt.Sprintf(`{"url": "https://google.com", "alias": "split", "variants": %s}`, tc.variants)
+			req := httptest.NewRequest(http.MethodPost, "/save", bytes.NewReader([]byte(body)))
+			rr := httptest.NewRecorder()
+			handler.ServeHTTP(rr, req)
+
+			var resp save.Response
+			require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &resp))
+
+			require.Equal(t, tc.respError, resp.Error)
+			require.Equal(t, tc.want, resp.Variants)
+		})
+	}
+}
+
+func TestSaveHandler_ActiveWindow(t *testing.T) {
+	from := time.Now().Add(time.Hour).UTC().Truncate(time.Second)
+	until := from.Add(24 * time.Hour)
+
+	cases := []struct {
+		name      string
+		window    string
+		respError string
+	}{
+		{
+			name:   "Window",
+			window: fmt.Sprintf(`"active_from": %q, "active_until": %q`, from.Format(time.RFC3339), until.Format(time.RFC3339)),
+		},
+		{
+			name:      "Until before from",
+			window:    fmt.Sprintf(`"active_from": %q, "active_until": %q`, until.Format(time.RFC3339), from.Format(time.RFC3339)),
+			respError: "field active_until must be after active_from",
+		},
+		{
+			name:      "Until in the past",
+			window:    fmt.Sprintf(`"active_until": %q`, time.Now().Add(-time.Hour).Format(time.RFC3339)),
+			respError: "field active_until must be in the future",
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			urlSaverMock := mocks.NewURLSaver(t)
+			if tc.respError == "" {
+				urlSaverMock.On("SaveURL", mock.Anything, mock.MatchedBy(func(u storage.URL) bool {
+					return u.ActiveFrom.Equal(from) && u.ActiveUntil.Equal(until)
+				})).Return(int64(1), nil).Once()
+			}
+
+			handler := save.New(slogdiscard.NewDiscardLogger(), urlSaverMock, newAliases(t), newDomains(t, domain.Rules{}), alias.DefaultRandom(), nil, nil, nil, newLinks(t))
+
+			body := fmt.Sprintf(`{"url": "https://google.com", "alias": "promo", %s}`, tc.window)
+			req := httptest.NewRequest(http.MethodPost, "/save", bytes.NewReader([]byte(body)))
+			rr := httptest.NewRecorder()
+			handler.ServeHTTP(rr, req)
+
+			var resp save.Response
+			require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &resp))
+
+			require.Equal(t, tc.respError, resp.Error)
+			if tc.respError == "" {
+				require.True(t, from.Equal(*resp.ActiveFrom))
+				require.True(t, until.Equal(*resp.ActiveUntil))
+			}
+		})
+	}
+}
+
+func TestSaveHandler_Tags(t *testing.T) {
+	urlSaverMock := mocks.NewURLSaver(t)
+	urlSaverMock.On("SaveURL", mock.Anything, mock.MatchedBy(func(u storage.URL) bool {
+		return slices.Equal(u.Tags, []string{"spring-sale", "team:growth"})
+	})).Return(int64(1), nil).Once()
+
+	handler := save.New(slogdiscard.NewDiscardLogger(), urlSaverMock, newAliases(t), newDomains(t, domain.Rules{}), alias.DefaultRandom(), nil, nil, nil, newLinks(t))
+
+	body := `{"url": "https://google.com", "alias": "tagged", "tags": ["Team:Growth", "spring-sale", "SPRING-SALE"]}`
+	req := httptest.NewRequest(http.MethodPost, "/save", bytes.NewReader([]byte(body)))
+	rr := httptest.NewRecorder()
+	handler.ServeHTTP(rr, req)
+
+	var resp save.Response
+	require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &resp))
+
+	require.Empty(t, resp.Error)
+	require.Equal(t, []string{"spring-sale", "team:growth"}, resp.Tags)
+
+	body = `{"url": "https://google.com", "tags": ["spring sale"]}`
+	req = httptest.NewRequest(http.MethodPost, "/save", bytes.NewReader([]byte(body)))
+	rr = httptest.NewRecorder()
+	handler.ServeHTTP(rr, req)
+
+	require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &resp))
+	require.Len(t, resp.Details, 1)
+	require.Equal(t, "tags", resp.Details[0].Field)
+	require.Equal(t, "tag", resp.Details[0].Rule)
+}
+
+func TestSaveHandler_ShortDomain(t *testing.T) {
+	urlSaverMock := mocks.NewURLSaver(t)
+	urlSaverMock.On("SaveURL", mock.Anything, mock.MatchedBy(func(u storage.URL) bool {
+		return u.Domain == "go.acme.com"
+	})).Return(int64(1), nil).Once()
+
+	handler := save.New(slogdiscard.NewDiscardLogger(), urlSaverMock, newAliases(t), newDomains(t, domain.Rules{}), alias.DefaultRandom(), nil, nil, nil, newLinks(t))
+
+	body := `{"url": "https://google.com", "alias": "promo", "domain": "Go.Acme.com"}`
+	req := httptest.NewRequest(http.MethodPost, "/save", bytes.NewReader([]byte(body)))
+	rr := httptest.NewRecorder()
+	handler.ServeHTTP(rr, req)
+
+	var resp save.Response
+	require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &resp))
+
+	require.Empty(t, resp.Error)
+	require.Equal(t, "go.acme.com", resp.Domain)
+	require.Equal(t, "https://go.acme.com/promo", resp.ShortURL)
+
+	body = `{"url": "https://google.com", "domain": "evil.com"}`
+	req = httptest.NewRequest(http.MethodPost, "/save", bytes.NewReader([]byte(body)))
+	rr = httptest.NewRecorder()
+	handler.ServeHTTP(rr, req)
+
+	resp = save.Response{}
+	require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &resp))
+	require.Len(t, resp.Details, 1)
+	require.Equal(t, "domain", resp.Details[0].Field)
+	require.Equal(t, "short_domain", resp.Details[0].Rule)
+}
+
+func TestSaveHandler_PlainText(t *testing.T) {
+	handler := save.New(slogdiscard.NewDiscardLogger(), memory.New(), newAliases(t), newDomains(t, domain.Rules{}), alias.DefaultRandom(), nil, nil, nil, newLinks(t))
+
+	cases := []struct {
+		name        string
+		target      string
+		accept      string
+		contentType string
+		body        string
+		code        int
+		respBody    string
+	}{
+		{
+			name:        "Accept header with form",
+			target:      "/save",
+			accept:      "text/plain",
+			contentType: "application/x-www-form-urlencoded",
+			body:        "url=https%3A%2F%2Fgoogle.com&alias=txt1",
+			code:        http.StatusOK,
+			respBody:    "https://sho.rt/txt1\n",
+		},
+		{
+			name:     "Format query with JSON",
+			target:   "/save?format=txt",
+			body:     `{"url": "https://google.com", "alias": "txt2"}`,
+			code:     http.StatusOK,
+			respBody: "https://sho.rt/txt2\n",
+		},
+		{
+			name:        "JSON sent as form by curl",
+			target:      "/save?format=txt",
+			contentType: "application/x-www-form-urlencoded",
+			body:        `{"url": "https://google.com", "alias": "txt3"}`,
+			code:        http.StatusOK,
+			respBody:    "https://sho.rt/txt3\n",
+		},
+		{
+			name:     "Error",
+			target:   "/save?format=txt",
+			body:     `{"url": "https://google.com", "alias": "txt2"}`,
+			code:     http.StatusBadRequest,
+			respBody: "url already exists\n",
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, tc.target, bytes.NewReader([]byte(tc.body)))
+			req.Header.Set("Accept", tc.accept)
+			req.Header.Set("Content-Type", tc.contentType)
+			rr := httptest.NewRecorder()
+			handler.ServeHTTP(rr, req)
+
+			require.Equal(t, tc.code, rr.Code)
+			require.Equal(t, "text/plain; charset=utf-8", rr.Header().Get("Content-Type"))
+			require.Equal(t, tc.respBody, rr.Body.String())
+		})
+	}
+}
+
+func TestSaveHandler_Get(t *testing.T) {
+	urls := memory.New()
+	handler := save.New(slogdiscard.NewDiscardLogger(), urls, newAliases(t), newDomains(t, domain.Rules{}), alias.DefaultRandom(), nil, nil, nil, newLinks(t))
+
+	req := httptest.NewRequest(http.MethodGet, "/shorten?url=https%3A%2F%2Fgoogle.com%2F%3Fq%3D1&alias=bookmark&ttl=1h", nil)
+	rr := httptest.NewRecorder()
+	handler.ServeHTTP(rr, req)
+
+	var resp save.Response
+	require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &resp))
+
+	require.Empty(t, resp.Error)
+	require.Equal(t, "https://sho.rt/bookmark", resp.ShortURL)
+	require.NotNil(t, resp.ExpiresAt)
+
+	u, err := urls.GetURL(context.Background(), "bookmark")
+	require.NoError(t, err)
+	require.Equal(t, "https://google.com/?q=1", u.URL)
+}
+
+func TestSaveHandler_Quota(t *testing.T) {
+	urls := memory.New()
+	quotas := quota.New(urls, quota.Limits{MaxLinks: 2})
+	handler := save.New(slogdiscard.NewDiscardLogger(), urls, newAliases(t), newDomains(t, domain.Rules{}), alias.DefaultRandom(), nil, nil, quotas, newLinks(t))
+
+	post := func() (int, save.Response) {
+		req := httptest.NewRequest(http.MethodPost, "/save", bytes.NewReader([]byte(`{"url": "https://google.com"}`)))
+		req = req.WithContext(auth.WithUser(req.Context(), storage.User{Username: "alice", Role: storage.RoleUser}))
+		rr := httptest.NewRecorder()
+		handler.ServeHTTP(rr, req)
+
+		var resp save.Response
+		require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &resp))
+		return rr.Code, resp
+	}
+
+	for range 2 {
+		code, resp := post()
+		require.Equal(t, http.StatusOK, code)
+		require.Nil(t, resp.Quota)
+	}
+
+	code, resp := post()
+	require.Equal(t, http.StatusForbidden, code)
+	require.Equal(t, "quota exceeded", resp.Error)
+	require.Equal(t, &quota.Usage{Links: 2, MaxLinks: 2}, resp.Quota)
+}
+
+func TestSaveHandler_Anonymous(t *testing.T) {
+	cases := []struct {
+		name      string
+		body      string
+		wantTTL   time.Duration
+		respError string
+	}{
+		{
+			name:    "Expires after the link ttl",
+			body:    `{"url": "https://google.com"}`,
+			wantTTL: 24 * time.Hour,
+		},
+		{
+			name:    "Shorter ttl kept",
+			body:    `{"url": "https://google.com", "ttl": "1h"}`,
+			wantTTL: time.Hour,
+		},
+		{
+			name:    "Longer ttl capped",
+			body:    `{"url": "https://google.com", "ttl": "720h"}`,
+			wantTTL: 24 * time.Hour,
+		},
+		{
+			name:      "Reuse existing",
+			body:      `{"url": "https://google.com", "reuse_existing": true}`,
+			respError: "reuse_existing needs an account",
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			urlSaverMock := mocks.NewURLSaver(t)
+			if tc.respError == "" {
+				urlSaverMock.On("SaveURL", mock.Anything, mock.MatchedBy(func(u storage.URL) bool {
+					ttl := time.Until(u.ExpiresAt)
+					return u.Owner == "" && ttl > tc.wantTTL-time.Minute && ttl <= tc.wantTTL
+				})).Return(int64(1), nil).Once()
+			}
+
+			handler := save.New(slogdiscard.NewDiscardLogger(), urlSaverMock, newAliases(t), newDomains(t, domain.Rules{}), alias.DefaultRandom(), nil, nil, nil, newLinks(t))
+
+			req := httptest.NewRequest(http.MethodPost, "/save", bytes.NewReader([]byte(tc.body)))
+			req = req.WithContext(mwAnonymous.WithVisitor(req.Context(), mwAnonymous.Visitor{LinkTTL: 24 * time.Hour}))
+			rr := httptest.NewRecorder()
+			handler.ServeHTTP(rr, req)
+
+			var resp save.Response
+			require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &resp))
+			require.Equal(t, tc.respError, resp.Error)
+			if tc.respError == "" {
+				require.NotNil(t, resp.ExpiresAt)
+			}
+		})
+	}
+}
+
+func TestSaveHandler_GeoRejected(t *testing.T) {
+	cases := []struct {
+		name      string
+		geo       string
+		respError string
+	}{
+		{
+			name:      "Invalid country",
+			geo:       `{"deu": "https://google.de"}`,
+			respError: "field Geo[deu] is not valid",
+		},
+		{
+			name:      "Invalid URL",
+			geo:       `{"de": "not a url"}`,
+			respError: "field Geo[de] is not valid",
+		},
+		{
+			name:      "Domain not allowed",
+			geo:       `{"de": "https://www.evil.com"}`,
+			respError: "domain not allowed: www.evil.com",
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			domains := newDomains(t, domain.Rules{Block: []string{"*.evil.com"}})
+			handler := save.New(slogdiscard.NewDiscardLogger(), mocks.NewURLSaver(t), newAliases(t), domains, alias.DefaultRandom(), nil, nil, nil, newLinks(t))
+
+			body := fmt.Sprintf(`{"url": "https://google.com", "geo": %s}`, tc.geo)
+			req := httptest.NewRequest(http.MethodPost, "/save", bytes.NewReader([]byte(body)))
+			rr := httptest.NewRecorder()
+			handler.ServeHTTP(rr, req)
+
+			var resp save.Response
+			require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &resp))
+
+			require.Equal(t, tc.respError, resp.Error)
+		})
+	}
+}
+
+func newDomains(t *testing.T, rules domain.Rules) *domain.Policy {
+	t.Helper()
+
+	domains, err := domain.New(rules)
+	require.NoError(t, err)
+
+	return domains
+}
+
+func newLinks(t *testing.T) *shorturl.Builder {
+	t.Helper()
+
+	links, err := shorturl.New("https://sho.rt", []string{"sho.rt", "go.acme.com"})
+	require.NoError(t, err)
+
+	return links
+}
+
+func newAliases(t *testing.T) *alias.Validator {
+	t.Helper()
+
+	aliases, err := alias.New(alias.Rules{
+		MinLength: 3,
+		MaxLength: 64,
+		Pattern:   "[A-Za-z0-9_-]+",
+		Reserved:  []string{"admin", "url"},
+	})
+	require.NoError(t, err)
+
+	return aliases
+}
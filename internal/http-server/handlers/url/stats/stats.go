@@ -0,0 +1,137 @@
+package stats
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"time"
+	"url-shortener/internal/http-server/handlers/url/access"
+	resp "url-shortener/internal/lib/api/response"
+	"url-shortener/internal/lib/logger/sl"
+	"url-shortener/internal/lib/shorturl"
+	"url-shortener/internal/storage"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/go-chi/render"
+)
+
+type DailyClicks struct {
+	Date   string `json:"date"`
+	Clicks int64  `json:"clicks"`
+}
+
+// VariantClicks counts all clicks on a split-test variant.
+type VariantClicks struct {
+	Variant string `json:"variant"`
+	Clicks  int64  `json:"clicks"`
+}
+
+type Response struct {
+	resp.Response
+	Alias       string          `json:"alias"`
+	ShortURL    string          `json:"short_url"`
+	TotalClicks int64           `json:"total_clicks"`
+	LastAccess  *time.Time      `json:"last_access,omitempty"`
+	Daily       []DailyClicks   `json:"daily"`
+	Variants    []VariantClicks `json:"variants,omitempty"`
+}
+
+const (
+	defaultDays = 30
+	maxDays     = 366
+)
+
+//go:generate go run github.com/vektra/mockery/v2@latest --name=StatsGetter
+type StatsGetter interface {
+	GetStats(ctx context.Context, alias string, since time.Time, includeBots bool) (storage.Stats, error)
+}
+
+// New returns click statistics for an alias: total clicks, last access and
+// a daily breakdown for the last `days` days (30 by default, today included).
+// Clicks of bots are counted only with include_bots=true. Users other than
+// admins and viewers get the stats of their own links only.
+func New(log *slog.Logger, statsGetter StatsGetter, ownerGetter access.OwnerGetter, links *shorturl.Builder) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.url.stats.New"
+
+		log := log.With(
+			slog.String("op", op),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+
+		alias := chi.URLParam(r, "alias")
+		if alias == "" {
+			log.Info("alias is empty")
+			render.Status(r, http.StatusBadRequest)
+			render.JSON(w, r, resp.Error("invalid request"))
+			return
+		}
+
+		days := defaultDays
+		if v := r.URL.Query().Get("days"); v != "" {
+			n, err := strconv.Atoi(v)
+			if err != nil || n <= 0 || n > maxDays {
+				log.Info("invalid days", slog.String("days", v))
+				render.Status(r, http.StatusBadRequest)
+				render.JSON(w, r, resp.Error("invalid days"))
+				return
+			}
+			days = n
+		}
+
+		includeBots := false
+		if v := r.URL.Query().Get("include_bots"); v != "" {
+			b, err := strconv.ParseBool(v)
+			if err != nil {
+				log.Info("invalid include_bots", slog.String("include_bots", v))
+				render.Status(r, http.StatusBadRequest)
+				render.JSON(w, r, resp.Error("invalid include_bots"))
+				return
+			}
+			includeBots = b
+		}
+
+		today := time.Now().UTC().Truncate(24 * time.Hour)
+		since := today.AddDate(0, 0, -(days - 1))
+
+		if !access.CanView(w, r, log, ownerGetter, alias) {
+			return
+		}
+
+		stats, err := statsGetter.GetStats(r.Context(), alias, since, includeBots)
+		if errors.Is(err, storage.ErrUrlNotFound) {
+			log.Info("url not found", slog.String("alias", alias))
+			render.Status(r, http.StatusNotFound)
+			render.JSON(w, r, resp.Error("url not found"))
+			return
+		}
+		if err != nil {
+			log.Error("failed to get stats", sl.Err(err))
+			render.Status(r, http.StatusInternalServerError)
+			render.JSON(w, r, resp.Error("failed to get stats"))
+			return
+		}
+
+		res := Response{
+			Response:    resp.OK(),
+			Alias:       alias,
+			ShortURL:    links.URL(r, stats.Domain, alias),
+			TotalClicks: stats.Total,
+			Daily:       make([]DailyClicks, 0, len(stats.Daily)),
+		}
+		if !stats.LastAccess.IsZero() {
+			res.LastAccess = &stats.LastAccess
+		}
+		for _, d := range stats.Daily {
+			res.Daily = append(res.Daily, DailyClicks{Date: d.Date, Clicks: d.Clicks})
+		}
+		for _, v := range stats.Variants {
+			res.Variants = append(res.Variants, VariantClicks{Variant: v.Variant, Clicks: v.Clicks})
+		}
+
+		render.JSON(w, r, res)
+	}
+}
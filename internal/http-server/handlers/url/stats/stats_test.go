@@ -0,0 +1,164 @@
+package stats_test
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	accessMocks "url-shortener/internal/http-server/handlers/url/access/mocks"
+	"url-shortener/internal/http-server/handlers/url/stats"
+	"url-shortener/internal/http-server/handlers/url/stats/mocks"
+	"url-shortener/internal/lib/logger/handlers/slogdiscard"
+	"url-shortener/internal/lib/shorturl"
+	"url-shortener/internal/storage"
+)
+
+func TestStatsHandler(t *testing.T) {
+	lastAccess := time.Date(2025, 3, 2, 10, 0, 0, 0, time.UTC)
+
+	cases := []struct {
+		name        string
+		alias       string
+		query       string
+		days        int
+		includeBots bool
+		status      int
+		respError   string
+		mockStats   storage.Stats
+		mockError   error
+		callMock    bool
+	}{
+		{
+			name:   "Success",
+			alias:  "test_alias",
+			days:   30,
+			status: http.StatusOK,
+			mockStats: storage.Stats{
+				Total:      3,
+				LastAccess: lastAccess,
+				Daily: []storage.DailyClicks{
+					{Date: "2025-03-01", Clicks: 1},
+					{Date: "2025-03-02", Clicks: 2},
+				},
+				Variants: []storage.VariantClicks{
+					{Variant: "A", Clicks: 2},
+					{Variant: "B", Clicks: 1},
+				},
+			},
+			callMock: true,
+		},
+		{
+			name:      "No clicks",
+			alias:     "test_alias",
+			query:     "?days=7",
+			days:      7,
+			status:    http.StatusOK,
+			mockStats: storage.Stats{},
+			callMock:  true,
+		},
+		{
+			name:        "Include bots",
+			alias:       "test_alias",
+			query:       "?include_bots=true",
+			days:        30,
+			includeBots: true,
+			status:      http.StatusOK,
+			mockStats:   storage.Stats{Total: 5},
+			callMock:    true,
+		},
+		{
+			name:      "Invalid include_bots",
+			alias:     "test_alias",
+			query:     "?include_bots=maybe",
+			status:    http.StatusBadRequest,
+			respError: "invalid include_bots",
+		},
+		{
+			name:      "Invalid days",
+			alias:     "test_alias",
+			query:     "?days=0",
+			status:    http.StatusBadRequest,
+			respError: "invalid days",
+		},
+		{
+			name:      "Not found",
+			alias:     "missing",
+			days:      30,
+			status:    http.StatusNotFound,
+			respError: "url not found",
+			mockError: storage.ErrUrlNotFound,
+			callMock:  true,
+		},
+		{
+			name:      "GetStats Error",
+			alias:     "test_alias",
+			days:      30,
+			status:    http.StatusInternalServerError,
+			respError: "failed to get stats",
+			mockError: errors.New("unexpected error"),
+			callMock:  true,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			statsGetterMock := mocks.NewStatsGetter(t)
+			ownerGetterMock := accessMocks.NewOwnerGetter(t)
+			ownerGetterMock.On("GetOwner", mock.Anything, tc.alias).Return("", nil).Maybe()
+
+			if tc.callMock {
+				statsGetterMock.On("GetStats", mock.Anything, tc.alias, mock.MatchedBy(func(since time.Time) bool {
+					today := time.Now().UTC().Truncate(24 * time.Hour)
+					return since.Equal(today.AddDate(0, 0, -(tc.days - 1)))
+				}), tc.includeBots).
+					Return(tc.mockStats, tc.mockError).
+					Once()
+			}
+
+			links, err := shorturl.New("https://sho.rt", nil)
+			require.NoError(t, err)
+
+			r := chi.NewRouter()
+			r.Get("/{alias}/stats", stats.New(slogdiscard.NewDiscardLogger(), statsGetterMock, ownerGetterMock, links))
+
+			req := httptest.NewRequest(http.MethodGet, "/"+tc.alias+"/stats"+tc.query, nil)
+			rr := httptest.NewRecorder()
+			r.ServeHTTP(rr, req)
+
+			require.Equal(t, tc.status, rr.Code)
+
+			var resp stats.Response
+			require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &resp))
+
+			require.Equal(t, tc.respError, resp.Error)
+
+			if tc.respError == "" {
+				require.Equal(t, tc.mockStats.Total, resp.TotalClicks)
+				require.Equal(t, "https://sho.rt/"+tc.alias, resp.ShortURL)
+				require.Len(t, resp.Daily, len(tc.mockStats.Daily))
+				for i, d := range tc.mockStats.Daily {
+					require.Equal(t, d.Date, resp.Daily[i].Date)
+					require.Equal(t, d.Clicks, resp.Daily[i].Clicks)
+				}
+				require.Len(t, resp.Variants, len(tc.mockStats.Variants))
+				for i, v := range tc.mockStats.Variants {
+					require.Equal(t, v.Variant, resp.Variants[i].Variant)
+					require.Equal(t, v.Clicks, resp.Variants[i].Clicks)
+				}
+
+				if tc.mockStats.LastAccess.IsZero() {
+					require.Nil(t, resp.LastAccess)
+				} else {
+					require.True(t, tc.mockStats.LastAccess.Equal(*resp.LastAccess))
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,240 @@
+package statsexport
+
+import (
+	"context"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"time"
+	"url-shortener/internal/http-server/handlers/url/access"
+	resp "url-shortener/internal/lib/api/response"
+	"url-shortener/internal/lib/logger/sl"
+	"url-shortener/internal/storage"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/go-chi/render"
+)
+
+// ClicksHeader is the first row of the raw click log export.
+var ClicksHeader = []string{"clicked_at", "referrer", "user_agent", "variant", "country", "city", "bot"}
+
+// DailyHeader is the first row of the export with group=day.
+var DailyHeader = []string{"date", "clicks"}
+
+// pageSize is how many clicks are read from the storage at a time.
+const pageSize = 1000
+
+//go:generate go run github.com/vektra/mockery/v2@latest --name=ClickLister
+type ClickLister interface {
+	ListClicks(ctx context.Context, alias string, filter storage.ClickFilter, afterID int64, limit int) ([]storage.Click, error)
+}
+
+//go:generate go run github.com/vektra/mockery/v2@latest --name=StatsGetter
+type StatsGetter interface {
+	GetStats(ctx context.Context, alias string, since time.Time, includeBots bool) (storage.Stats, error)
+}
+
+// Storage is what the export reads from.
+type Storage interface {
+	ClickLister
+	StatsGetter
+	access.OwnerGetter
+}
+
+// New streams the clicks on an alias as CSV, oldest first, reading the
+// storage page by page. from and to (RFC 3339 or YYYY-MM-DD, to exclusive)
+// bound the export; with group=day it holds clicks per whole day instead
+// of single clicks. Clicks of bots are exported only with include_bots=true.
+// Users other than admins and viewers can export their own links only.
+func New(log *slog.Logger, store Storage) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.url.statsexport.New"
+
+		log := log.With(
+			slog.String("op", op),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+
+		alias := chi.URLParam(r, "alias")
+		if alias == "" {
+			log.Info("alias is empty")
+			render.Status(r, http.StatusBadRequest)
+			render.JSON(w, r, resp.Error("invalid request"))
+			return
+		}
+
+		var filter storage.ClickFilter
+		for _, p := range []struct {
+			name string
+			dst  *time.Time
+		}{{"from", &filter.From}, {"to", &filter.To}} {
+			v := r.URL.Query().Get(p.name)
+			if v == "" {
+				continue
+			}
+			t, err := parseTime(v)
+			if err != nil {
+				log.Info("invalid "+p.name, slog.String(p.name, v))
+				render.Status(r, http.StatusBadRequest)
+				render.JSON(w, r, resp.Error("invalid "+p.name))
+				return
+			}
+			*p.dst = t
+		}
+		if !filter.From.IsZero() && !filter.To.IsZero() && !filter.From.Before(filter.To) {
+			log.Info("empty range", slog.Time("from", filter.From), slog.Time("to", filter.To))
+			render.Status(r, http.StatusBadRequest)
+			render.JSON(w, r, resp.Error("from must be before to"))
+			return
+		}
+
+		if v := r.URL.Query().Get("include_bots"); v != "" {
+			b, err := strconv.ParseBool(v)
+			if err != nil {
+				log.Info("invalid include_bots", slog.String("include_bots", v))
+				render.Status(r, http.StatusBadRequest)
+				render.JSON(w, r, resp.Error("invalid include_bots"))
+				return
+			}
+			filter.IncludeBots = b
+		}
+
+		group := r.URL.Query().Get("group")
+		if group != "" && group != "day" {
+			log.Info("invalid group", slog.String("group", group))
+			render.Status(r, http.StatusBadRequest)
+			render.JSON(w, r, resp.Error("invalid group"))
+			return
+		}
+
+		if !access.CanView(w, r, log, store, alias) {
+			return
+		}
+
+		if group == "day" {
+			exportDaily(w, r, log, store, alias, filter)
+		} else {
+			exportClicks(w, r, log, store, alias, filter)
+		}
+	}
+}
+
+func exportClicks(w http.ResponseWriter, r *http.Request, log *slog.Logger, clickLister ClickLister, alias string, filter storage.ClickFilter) {
+	// The first page is read before anything is written, so an unknown
+	// alias or a broken storage still gets a proper error response.
+	clicks, err := clickLister.ListClicks(r.Context(), alias, filter, 0, pageSize)
+	if !writeError(w, r, log, alias, err) {
+		return
+	}
+
+	cw := startCSV(w, alias, ClicksHeader)
+
+	count := 0
+	for {
+		for _, c := range clicks {
+			_ = cw.Write([]string{
+				c.ClickedAt.UTC().Format(time.RFC3339Nano),
+				c.Referrer,
+				c.UserAgent,
+				c.Variant,
+				c.Country,
+				c.City,
+				strconv.FormatBool(c.Bot),
+			})
+		}
+		count += len(clicks)
+
+		if !flush(w, cw, log) {
+			return
+		}
+
+		if len(clicks) < pageSize {
+			break
+		}
+
+		clicks, err = clickLister.ListClicks(r.Context(), alias, filter, clicks[len(clicks)-1].ID, pageSize)
+		if err != nil {
+			// The status is already sent; the client gets a truncated file.
+			log.Error("failed to list clicks", sl.Err(err), slog.Int("exported", count))
+			return
+		}
+	}
+
+	log.Info("clicks exported", slog.String("alias", alias), slog.Int("count", count))
+}
+
+func exportDaily(w http.ResponseWriter, r *http.Request, log *slog.Logger, statsGetter StatsGetter, alias string, filter storage.ClickFilter) {
+	stats, err := statsGetter.GetStats(r.Context(), alias, filter.From.UTC().Truncate(24*time.Hour), filter.IncludeBots)
+	if !writeError(w, r, log, alias, err) {
+		return
+	}
+
+	cw := startCSV(w, alias, DailyHeader)
+
+	to := ""
+	if !filter.To.IsZero() {
+		to = filter.To.UTC().Format(time.DateOnly)
+	}
+	for _, d := range stats.Daily {
+		if to != "" && d.Date >= to {
+			break
+		}
+		_ = cw.Write([]string{d.Date, strconv.FormatInt(d.Clicks, 10)})
+	}
+
+	if flush(w, cw, log) {
+		log.Info("daily clicks exported", slog.String("alias", alias))
+	}
+}
+
+// writeError answers a failed first read and reports whether the export
+// can go on.
+func writeError(w http.ResponseWriter, r *http.Request, log *slog.Logger, alias string, err error) bool {
+	if errors.Is(err, storage.ErrUrlNotFound) {
+		log.Info("url not found", slog.String("alias", alias))
+		render.Status(r, http.StatusNotFound)
+		render.JSON(w, r, resp.Error("url not found"))
+		return false
+	}
+	if err != nil {
+		log.Error("failed to read clicks", sl.Err(err))
+		render.Status(r, http.StatusInternalServerError)
+		render.JSON(w, r, resp.Error("failed to export stats"))
+		return false
+	}
+	return true
+}
+
+func startCSV(w http.ResponseWriter, alias string, header []string) *csv.Writer {
+	w.Header().Set("Content-Type", "text/csv; charset=utf-8")
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", alias+"-clicks.csv"))
+
+	cw := csv.NewWriter(w)
+	_ = cw.Write(header)
+	return cw
+}
+
+// flush sends the rows written so far to the client and reports whether
+// it is still there.
+func flush(w http.ResponseWriter, cw *csv.Writer, log *slog.Logger) bool {
+	cw.Flush()
+	if err := cw.Error(); err != nil {
+		log.Info("export aborted", sl.Err(err))
+		return false
+	}
+	if f, ok := w.(http.Flusher); ok {
+		f.Flush()
+	}
+	return true
+}
+
+func parseTime(v string) (time.Time, error) {
+	if t, err := time.Parse(time.DateOnly, v); err == nil {
+		return t, nil
+	}
+	return time.Parse(time.RFC3339, v)
+}
@@ -0,0 +1,207 @@
+package statsexport_test
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	accessMocks "url-shortener/internal/http-server/handlers/url/access/mocks"
+	"url-shortener/internal/http-server/handlers/url/statsexport"
+	"url-shortener/internal/http-server/handlers/url/statsexport/mocks"
+	"url-shortener/internal/http-server/middleware/auth"
+	resp "url-shortener/internal/lib/api/response"
+	"url-shortener/internal/lib/logger/handlers/slogdiscard"
+	"url-shortener/internal/storage"
+)
+
+type storageMock struct {
+	*mocks.ClickLister
+	*mocks.StatsGetter
+	*accessMocks.OwnerGetter
+}
+
+// owned returns an OwnerGetter that reports alice as the owner of
+// test_alias.
+func owned(t *testing.T) *accessMocks.OwnerGetter {
+	ownerGetterMock := accessMocks.NewOwnerGetter(t)
+	ownerGetterMock.On("GetOwner", mock.Anything, "test_alias").Return("alice", nil).Maybe()
+	return ownerGetterMock
+}
+
+func serve(t *testing.T, store statsexport.Storage, target string) *httptest.ResponseRecorder {
+	t.Helper()
+
+	return serveAs(t, store, storage.User{Username: "alice", Role: storage.RoleEditor}, target)
+}
+
+func serveAs(t *testing.T, store statsexport.Storage, user storage.User, target string) *httptest.ResponseRecorder {
+	t.Helper()
+
+	r := chi.NewRouter()
+	r.Get("/{alias}/stats/export", statsexport.New(slogdiscard.NewDiscardLogger(), store))
+
+	req := httptest.NewRequest(http.MethodGet, target, nil)
+	req = req.WithContext(auth.WithUser(req.Context(), user))
+
+	rr := httptest.NewRecorder()
+	r.ServeHTTP(rr, req)
+	return rr
+}
+
+func TestExportHandler_Clicks(t *testing.T) {
+	clickedAt := time.Date(2025, 3, 1, 10, 0, 0, 0, time.UTC)
+
+	// Two pages: a full one and the rest.
+	firstPage := make([]storage.Click, 1000)
+	for i := range firstPage {
+		firstPage[i] = storage.Click{ID: int64(i + 1), ClickedAt: clickedAt, Referrer: "https://example.com/"}
+	}
+	secondPage := []storage.Click{
+		{ID: 1001, ClickedAt: clickedAt.Add(time.Hour), UserAgent: "curl/8.0", Variant: "b", Country: "DE", City: "Berlin", Bot: true},
+	}
+
+	filter := storage.ClickFilter{
+		From:        time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC),
+		To:          time.Date(2025, 3, 2, 0, 0, 0, 0, time.UTC),
+		IncludeBots: true,
+	}
+	listerMock := mocks.NewClickLister(t)
+	listerMock.On("ListClicks", mock.Anything, "test_alias", filter, int64(0), 1000).Return(firstPage, nil).Once()
+	listerMock.On("ListClicks", mock.Anything, "test_alias", filter, int64(1000), 1000).Return(secondPage, nil).Once()
+
+	rr := serve(t, storageMock{ClickLister: listerMock, OwnerGetter: owned(t)}, "/test_alias/stats/export?from=2025-03-01&to=2025-03-02&include_bots=true")
+
+	require.Equal(t, http.StatusOK, rr.Code)
+	require.Equal(t, "text/csv; charset=utf-8", rr.Header().Get("Content-Type"))
+	require.Equal(t, `attachment; filename="test_alias-clicks.csv"`, rr.Header().Get("Content-Disposition"))
+
+	records, err := csv.NewReader(rr.Body).ReadAll()
+	require.NoError(t, err)
+	require.Len(t, records, 1002)
+	require.Equal(t, statsexport.ClicksHeader, records[0])
+	require.Equal(t, []string{"2025-03-01T10:00:00Z", "https://example.com/", "", "", "", "", "false"}, records[1])
+	require.Equal(t, []string{"2025-03-01T11:00:00Z", "", "curl/8.0", "b", "DE", "Berlin", "true"}, records[1001])
+}
+
+func TestExportHandler_Daily(t *testing.T) {
+	getterMock := mocks.NewStatsGetter(t)
+	getterMock.On("GetStats", mock.Anything, "test_alias", time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC), false).
+		Return(storage.Stats{Daily: []storage.DailyClicks{
+			{Date: "2025-03-01", Clicks: 4},
+			{Date: "2025-03-03", Clicks: 1},
+			{Date: "2025-03-05", Clicks: 7},
+		}}, nil).
+		Once()
+
+	rr := serve(t, storageMock{StatsGetter: getterMock, OwnerGetter: owned(t)}, "/test_alias/stats/export?group=day&from=2025-03-01T12:00:00Z&to=2025-03-05")
+
+	require.Equal(t, http.StatusOK, rr.Code)
+
+	records, err := csv.NewReader(rr.Body).ReadAll()
+	require.NoError(t, err)
+	require.Equal(t, [][]string{statsexport.DailyHeader, {"2025-03-01", "4"}, {"2025-03-03", "1"}}, records)
+}
+
+func TestExportHandler_Errors(t *testing.T) {
+	cases := []struct {
+		name      string
+		query     string
+		status    int
+		respError string
+		mockError error
+		callMock  bool
+	}{
+		{
+			name:      "Invalid from",
+			query:     "?from=yesterday",
+			status:    http.StatusBadRequest,
+			respError: "invalid from",
+		},
+		{
+			name:      "Invalid to",
+			query:     "?to=2025-13-01",
+			status:    http.StatusBadRequest,
+			respError: "invalid to",
+		},
+		{
+			name:      "Empty range",
+			query:     "?from=2025-03-02&to=2025-03-01",
+			status:    http.StatusBadRequest,
+			respError: "from must be before to",
+		},
+		{
+			name:      "Invalid include_bots",
+			query:     "?include_bots=maybe",
+			status:    http.StatusBadRequest,
+			respError: "invalid include_bots",
+		},
+		{
+			name:      "Invalid group",
+			query:     "?group=week",
+			status:    http.StatusBadRequest,
+			respError: "invalid group",
+		},
+		{
+			name:      "Not found",
+			status:    http.StatusNotFound,
+			respError: "url not found",
+			mockError: storage.ErrUrlNotFound,
+			callMock:  true,
+		},
+		{
+			name:      "ListClicks Error",
+			status:    http.StatusInternalServerError,
+			respError: "failed to export stats",
+			mockError: errors.New("unexpected error"),
+			callMock:  true,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			listerMock := mocks.NewClickLister(t)
+
+			if tc.callMock {
+				listerMock.On("ListClicks", mock.Anything, "test_alias", storage.ClickFilter{}, int64(0), 1000).
+					Return(nil, tc.mockError).
+					Once()
+			}
+
+			rr := serve(t, storageMock{ClickLister: listerMock, OwnerGetter: owned(t)}, "/test_alias/stats/export"+tc.query)
+
+			require.Equal(t, tc.status, rr.Code)
+
+			var res resp.Response
+			require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &res))
+			require.Equal(t, tc.respError, res.Error)
+		})
+	}
+}
+
+func TestExportHandler_NotOwner(t *testing.T) {
+	// Neither the clicks nor the daily stats of another user's link are
+	// read.
+	store := storageMock{
+		ClickLister: mocks.NewClickLister(t),
+		StatsGetter: mocks.NewStatsGetter(t),
+		OwnerGetter: owned(t),
+	}
+
+	for _, query := range []string{"", "?group=day"} {
+		rr := serveAs(t, store, storage.User{Username: "bob", Role: storage.RoleEditor}, "/test_alias/stats/export"+query)
+
+		require.Equal(t, http.StatusNotFound, rr.Code)
+
+		var res resp.Response
+		require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &res))
+		require.Equal(t, "url not found", res.Error)
+	}
+}
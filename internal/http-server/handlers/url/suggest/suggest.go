@@ -0,0 +1,116 @@
+package suggest
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"url-shortener/internal/lib/alias"
+	resp "url-shortener/internal/lib/api/response"
+	"url-shortener/internal/lib/logger/sl"
+	"url-shortener/internal/storage"
+
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/go-chi/render"
+	"github.com/go-playground/validator/v10"
+)
+
+const (
+	defaultCount = 5
+	maxCount     = 20
+)
+
+type Response struct {
+	resp.Response
+	// Suggestions are free aliases, the best first, to send as the alias
+	// of POST /url.
+	Suggestions []string `json:"suggestions"`
+}
+
+//go:generate go run github.com/vektra/mockery/v2@latest --name=AliasChecker
+type AliasChecker interface {
+	AliasExists(ctx context.Context, alias string) (bool, error)
+}
+
+// MetadataFetcher reads the title of the page the link leads to.
+type MetadataFetcher interface {
+	Fetch(ctx context.Context, url string) (storage.Metadata, error)
+}
+
+// New proposes aliases that are free right now for a link to the url
+// query parameter, so UIs can offer a choice of readable names. count
+// sets how many, 5 by default. fetcher may be nil to make suggestions
+// from the domain and random words only; if it fails, so they are made
+// too.
+func New(
+	log *slog.Logger,
+	suggester *alias.Suggester,
+	aliases *alias.Validator,
+	aliasChecker AliasChecker,
+	fetcher MetadataFetcher,
+) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.url.suggest.New"
+
+		log := log.With(
+			slog.String("op", op),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+
+		query := r.URL.Query()
+
+		target := query.Get("url")
+		if err := validator.New().Var(target, "required,url"); err != nil {
+			log.Info("invalid url", slog.String("url", target))
+			render.Status(r, http.StatusBadRequest)
+			render.JSON(w, r, resp.Error("invalid url"))
+			return
+		}
+
+		count := defaultCount
+		if s := query.Get("count"); s != "" {
+			n, err := strconv.Atoi(s)
+			if err != nil || n <= 0 || n > maxCount {
+				log.Info("invalid count", slog.String("count", s))
+				render.Status(r, http.StatusBadRequest)
+				render.JSON(w, r, resp.Error("invalid count"))
+				return
+			}
+			count = n
+		}
+
+		var title string
+		if fetcher != nil {
+			meta, err := fetcher.Fetch(r.Context(), target)
+			if err != nil {
+				log.Info("failed to fetch metadata", slog.String("url", target), sl.Err(err))
+			}
+			title = meta.Title
+		}
+
+		// Twice as many candidates, as some of them may be taken.
+		suggestions := make([]string, 0, count)
+		for _, a := range suggester.Suggest(target, title, 2*count) {
+			// With signed aliases the link would be saved under the signed one.
+			exists, err := aliasChecker.AliasExists(r.Context(), aliases.Sign(a))
+			if err != nil {
+				log.Error("failed to check alias", sl.Err(err))
+				render.Status(r, http.StatusInternalServerError)
+				render.JSON(w, r, resp.Error("failed to suggest aliases"))
+				return
+			}
+			if exists {
+				continue
+			}
+
+			suggestions = append(suggestions, a)
+			if len(suggestions) == count {
+				break
+			}
+		}
+
+		log.Info("aliases suggested", slog.Int("count", len(suggestions)))
+
+		render.JSON(w, r, Response{Response: resp.OK(), Suggestions: suggestions})
+	}
+}
@@ -0,0 +1,122 @@
+package suggest_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"url-shortener/internal/http-server/handlers/url/suggest"
+	"url-shortener/internal/http-server/handlers/url/suggest/mocks"
+	"url-shortener/internal/lib/alias"
+	"url-shortener/internal/lib/logger/handlers/slogdiscard"
+	"url-shortener/internal/storage"
+)
+
+type fetcher struct {
+	title string
+	err   error
+}
+
+func (f fetcher) Fetch(context.Context, string) (storage.Metadata, error) {
+	return storage.Metadata{Title: f.title}, f.err
+}
+
+func TestSuggestHandler(t *testing.T) {
+	cases := []struct {
+		name      string
+		query     string
+		fetcher   suggest.MetadataFetcher
+		taken     []string
+		mockError error
+		status    int
+		want      []string
+		respError string
+	}{
+		{
+			name:    "From title",
+			query:   "?url=https://go.dev/doc&count=3",
+			fetcher: fetcher{title: "Documentation - The Go Programming Language"},
+			status:  http.StatusOK,
+			want:    []string{"documentation-programming-language", "documentation-programming", "go"},
+		},
+		{
+			name:    "Taken skipped",
+			query:   "?url=https://go.dev/doc&count=2",
+			fetcher: fetcher{title: "Documentation - The Go Programming Language"},
+			taken:   []string{"documentation-programming"},
+			status:  http.StatusOK,
+			want:    []string{"documentation-programming-language", "go"},
+		},
+		{
+			name:    "Fetch failed",
+			query:   "?url=https://github.com/x&count=1",
+			fetcher: fetcher{err: errors.New("timeout")},
+			status:  http.StatusOK,
+			want:    []string{"github"},
+		},
+		{
+			name:   "Without fetcher",
+			query:  "?url=https://github.com/x&count=1",
+			status: http.StatusOK,
+			want:   []string{"github"},
+		},
+		{
+			name:      "Invalid url",
+			query:     "?url=github",
+			status:    http.StatusBadRequest,
+			respError: "invalid url",
+		},
+		{
+			name:      "Invalid count",
+			query:     "?url=https://github.com&count=100",
+			status:    http.StatusBadRequest,
+			respError: "invalid count",
+		},
+		{
+			name:      "AliasExists Error",
+			query:     "?url=https://github.com",
+			mockError: errors.New("unexpected error"),
+			status:    http.StatusInternalServerError,
+			respError: "failed to suggest aliases",
+		},
+	}
+
+	aliases, err := alias.New(alias.Rules{MinLength: 2, Pattern: "[a-z-]+"})
+	require.NoError(t, err)
+	suggester := alias.NewSuggester(aliases, nil)
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			aliasCheckerMock := mocks.NewAliasChecker(t)
+			if tc.mockError != nil {
+				aliasCheckerMock.On("AliasExists", mock.Anything, mock.Anything).
+					Return(false, tc.mockError).Once()
+			}
+			for _, a := range tc.taken {
+				aliasCheckerMock.On("AliasExists", mock.Anything, a).Return(true, nil).Once()
+			}
+			aliasCheckerMock.On("AliasExists", mock.Anything, mock.Anything).Return(false, nil).Maybe()
+
+			handler := suggest.New(slogdiscard.NewDiscardLogger(), suggester, aliases, aliasCheckerMock, tc.fetcher)
+
+			req := httptest.NewRequest(http.MethodGet, "/suggest"+tc.query, nil)
+			rr := httptest.NewRecorder()
+			handler.ServeHTTP(rr, req)
+
+			require.Equal(t, tc.status, rr.Code)
+
+			var resp suggest.Response
+			require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &resp))
+			require.Equal(t, tc.respError, resp.Error)
+			if tc.respError == "" {
+				require.Equal(t, tc.want, resp.Suggestions)
+			}
+		})
+	}
+}
@@ -0,0 +1,104 @@
+package update
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+	"url-shortener/internal/http-server/middleware/auth"
+	resp "url-shortener/internal/lib/api/response"
+	"url-shortener/internal/lib/domain"
+	"url-shortener/internal/lib/logger/sl"
+	"url-shortener/internal/storage"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/go-chi/render"
+	"github.com/go-playground/validator/v10"
+)
+
+type Request struct {
+	URL string `json:"url" validate:"required,url"`
+}
+
+type Response struct {
+	resp.Response
+	Alias string `json:"alias,omitempty"`
+	URL   string `json:"url,omitempty"`
+}
+
+//go:generate go run github.com/vektra/mockery/v2@latest --name=URLUpdater
+type URLUpdater interface {
+	UpdateURL(ctx context.Context, alias string, owner string, newURL string) error
+}
+
+// New repoints an existing alias to a new destination URL. Users can only
+// update their own links, admins can update any. The new URL is subject to
+// the same domain policy as new links.
+func New(log *slog.Logger, urlUpdater URLUpdater, domains *domain.Policy) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.url.update.New"
+
+		log := log.With(
+			slog.String("op", op),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+
+		alias := chi.URLParam(r, "alias")
+		if alias == "" {
+			log.Info("alias is empty")
+			render.Status(r, http.StatusBadRequest)
+			render.JSON(w, r, resp.Error("invalid request"))
+			return
+		}
+
+		var req Request
+		if err := render.DecodeJSON(r.Body, &req); err != nil {
+			log.Error("failed to decode request body", sl.Err(err))
+			render.JSON(w, r, resp.Error("failed to decode request"))
+			return
+		}
+
+		log.Info("request body decoded", slog.Any("request", req))
+
+		if err := validator.New().Struct(req); err != nil {
+			validateErr := err.(validator.ValidationErrors)
+
+			log.Error("invalide request", sl.Err(err))
+			render.JSON(w, r, resp.ValidationError(validateErr))
+			return
+		}
+
+		if err := domains.Check(req.URL, r.Host); err != nil {
+			log.Info("domain not allowed", slog.String("url", req.URL), sl.Err(err))
+			rule := "domain"
+			if errors.Is(err, domain.ErrSelfReference) {
+				rule = "self_reference"
+			}
+			render.JSON(w, r, resp.InvalidField("url", rule, err.Error()))
+			return
+		}
+
+		err := urlUpdater.UpdateURL(r.Context(), alias, auth.OwnerFromContext(r.Context()), req.URL)
+		if errors.Is(err, storage.ErrUrlNotFound) {
+			log.Info("url not found", slog.String("alias", alias))
+			render.Status(r, http.StatusNotFound)
+			render.JSON(w, r, resp.Error("url not found"))
+			return
+		}
+		if err != nil {
+			log.Error("failed to update url", sl.Err(err))
+			render.Status(r, http.StatusInternalServerError)
+			render.JSON(w, r, resp.Error("failed to update url"))
+			return
+		}
+
+		log.Info("url updated", slog.String("alias", alias), slog.String("url", req.URL))
+
+		render.JSON(w, r, Response{
+			Response: resp.OK(),
+			Alias:    alias,
+			URL:      req.URL,
+		})
+	}
+}
@@ -0,0 +1,118 @@
+package update_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"url-shortener/internal/http-server/handlers/url/update"
+	"url-shortener/internal/http-server/handlers/url/update/mocks"
+	"url-shortener/internal/http-server/middleware/auth"
+	"url-shortener/internal/lib/domain"
+	"url-shortener/internal/lib/logger/handlers/slogdiscard"
+	"url-shortener/internal/storage"
+)
+
+func TestUpdateHandler(t *testing.T) {
+	cases := []struct {
+		name      string
+		alias     string
+		url       string
+		status    int
+		respError string
+		mockError error
+		callMock  bool
+	}{
+		{
+			name:     "Success",
+			alias:    "test_alias",
+			url:      "https://google.com",
+			status:   http.StatusOK,
+			callMock: true,
+		},
+		{
+			name:      "Empty url",
+			alias:     "test_alias",
+			url:       "",
+			status:    http.StatusOK,
+			respError: "field URL is a required field",
+		},
+		{
+			name:      "Invalid URL",
+			alias:     "test_alias",
+			url:       "Some invalid URL",
+			status:    http.StatusOK,
+			respError: "field URL is not valid",
+		},
+		{
+			name:      "Domain not allowed",
+			alias:     "test_alias",
+			url:       "https://www.evil.com",
+			status:    http.StatusOK,
+			respError: "domain not allowed: www.evil.com",
+		},
+		{
+			name:      "Not found",
+			alias:     "missing",
+			url:       "https://google.com",
+			status:    http.StatusNotFound,
+			respError: "url not found",
+			mockError: storage.ErrUrlNotFound,
+			callMock:  true,
+		},
+		{
+			name:      "UpdateURL Error",
+			alias:     "test_alias",
+			url:       "https://google.com",
+			status:    http.StatusInternalServerError,
+			respError: "failed to update url",
+			mockError: errors.New("unexpected error"),
+			callMock:  true,
+		},
+	}
+
+	domains, err := domain.New(domain.Rules{Block: []string{"*.evil.com"}})
+	require.NoError(t, err)
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			urlUpdaterMock := mocks.NewURLUpdater(t)
+
+			if tc.callMock {
+				urlUpdaterMock.On("UpdateURL", mock.Anything, tc.alias, "alice", tc.url).
+					Return(tc.mockError).
+					Once()
+			}
+
+			r := chi.NewRouter()
+			r.Patch("/{alias}", update.New(slogdiscard.NewDiscardLogger(), urlUpdaterMock, domains))
+
+			input := fmt.Sprintf(`{"url": "%s"}`, tc.url)
+
+			req := httptest.NewRequest(http.MethodPatch, "/"+tc.alias, bytes.NewReader([]byte(input)))
+			req = req.WithContext(auth.WithUser(req.Context(), storage.User{Username: "alice", Role: storage.RoleUser}))
+			rr := httptest.NewRecorder()
+			r.ServeHTTP(rr, req)
+
+			require.Equal(t, tc.status, rr.Code)
+
+			var resp update.Response
+			require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &resp))
+
+			require.Equal(t, tc.respError, resp.Error)
+
+			if tc.respError == "" {
+				require.Equal(t, tc.alias, resp.Alias)
+				require.Equal(t, tc.url, resp.URL)
+			}
+		})
+	}
+}
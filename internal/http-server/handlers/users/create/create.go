@@ -0,0 +1,105 @@
+package create
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+	resp "url-shortener/internal/lib/api/response"
+	"url-shortener/internal/lib/logger/sl"
+	"url-shortener/internal/storage"
+
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/go-chi/render"
+	"github.com/go-playground/validator/v10"
+	"golang.org/x/crypto/bcrypt"
+)
+
+type Request struct {
+	Username string `json:"username" validate:"required,max=64"`
+	Password string `json:"password" validate:"required,min=8,max=72"`
+	Role     string `json:"role,omitempty" validate:"omitempty,oneof=viewer editor admin user"`
+}
+
+type Response struct {
+	resp.Response
+	ID       int64  `json:"id,omitempty"`
+	Username string `json:"username,omitempty"`
+	Role     string `json:"role,omitempty"`
+}
+
+//go:generate go run github.com/vektra/mockery/v2@latest --name=UserSaver
+type UserSaver interface {
+	SaveUser(ctx context.Context, user storage.User) (int64, error)
+}
+
+// New creates a user. The role defaults to "editor"; only a bcrypt hash
+// of the password is stored.
+func New(log *slog.Logger, userSaver UserSaver) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.users.create.New"
+
+		log := log.With(
+			slog.String("op", op),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+
+		var req Request
+		if err := render.DecodeJSON(r.Body, &req); err != nil {
+			log.Error("failed to decode request body", sl.Err(err))
+			render.Status(r, http.StatusBadRequest)
+			render.JSON(w, r, resp.Error("failed to decode request"))
+			return
+		}
+
+		if err := validator.New().Struct(req); err != nil {
+			validateErr := err.(validator.ValidationErrors)
+
+			log.Error("invalid request", sl.Err(err))
+			render.Status(r, http.StatusBadRequest)
+			render.JSON(w, r, resp.ValidationError(validateErr))
+			return
+		}
+
+		role := req.Role
+		if role == "" {
+			role = storage.RoleEditor
+		}
+
+		hash, err := bcrypt.GenerateFromPassword([]byte(req.Password), bcrypt.DefaultCost)
+		if err != nil {
+			log.Error("failed to hash password", sl.Err(err))
+			render.Status(r, http.StatusInternalServerError)
+			render.JSON(w, r, resp.Error("failed to create user"))
+			return
+		}
+
+		id, err := userSaver.SaveUser(r.Context(), storage.User{
+			Username:     req.Username,
+			PasswordHash: string(hash),
+			Role:         role,
+		})
+		if errors.Is(err, storage.ErrUserExists) {
+			log.Info("user already exists", slog.String("username", req.Username))
+			render.Status(r, http.StatusConflict)
+			render.JSON(w, r, resp.Error("user already exists"))
+			return
+		}
+		if err != nil {
+			log.Error("failed to save user", sl.Err(err))
+			render.Status(r, http.StatusInternalServerError)
+			render.JSON(w, r, resp.Error("failed to create user"))
+			return
+		}
+
+		log.Info("user created", slog.Int64("id", id), slog.String("username", req.Username), slog.String("role", role))
+
+		render.Status(r, http.StatusCreated)
+		render.JSON(w, r, Response{
+			Response: resp.OK(),
+			ID:       id,
+			Username: req.Username,
+			Role:     role,
+		})
+	}
+}
@@ -0,0 +1,121 @@
+package create_test
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/bcrypt"
+
+	"url-shortener/internal/http-server/handlers/users/create"
+	"url-shortener/internal/http-server/handlers/users/create/mocks"
+	"url-shortener/internal/lib/logger/handlers/slogdiscard"
+	"url-shortener/internal/storage"
+)
+
+func TestCreateHandler(t *testing.T) {
+	cases := []struct {
+		name      string
+		input     string
+		status    int
+		respError string
+		role      string
+		save      bool
+		mockError error
+	}{
+		{
+			name:   "Success",
+			input:  `{"username": "alice", "password": "secret123"}`,
+			status: http.StatusCreated,
+			role:   storage.RoleEditor,
+			save:   true,
+		},
+		{
+			name:   "Viewer",
+			input:  `{"username": "alice", "password": "secret123", "role": "viewer"}`,
+			status: http.StatusCreated,
+			role:   storage.RoleViewer,
+			save:   true,
+		},
+		{
+			name:   "Legacy user role",
+			input:  `{"username": "alice", "password": "secret123", "role": "user"}`,
+			status: http.StatusCreated,
+			role:   storage.RoleUser,
+			save:   true,
+		},
+		{
+			name:   "Admin",
+			input:  `{"username": "alice", "password": "secret123", "role": "admin"}`,
+			status: http.StatusCreated,
+			role:   storage.RoleAdmin,
+			save:   true,
+		},
+		{
+			name:      "Unknown role",
+			input:     `{"username": "alice", "password": "secret123", "role": "root"}`,
+			status:    http.StatusBadRequest,
+			respError: "field Role is not valid",
+		},
+		{
+			name:      "Short password",
+			input:     `{"username": "alice", "password": "short"}`,
+			status:    http.StatusBadRequest,
+			respError: "field Password is not valid",
+		},
+		{
+			name:      "User exists",
+			input:     `{"username": "alice", "password": "secret123"}`,
+			status:    http.StatusConflict,
+			respError: "user already exists",
+			role:      storage.RoleEditor,
+			save:      true,
+			mockError: storage.ErrUserExists,
+		},
+		{
+			name:      "SaveUser Error",
+			input:     `{"username": "alice", "password": "secret123"}`,
+			status:    http.StatusInternalServerError,
+			respError: "failed to create user",
+			role:      storage.RoleEditor,
+			save:      true,
+			mockError: errors.New("unexpected error"),
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			userSaverMock := mocks.NewUserSaver(t)
+
+			if tc.save {
+				userSaverMock.On("SaveUser", mock.Anything, mock.MatchedBy(func(u storage.User) bool {
+					return u.Username == "alice" && u.Role == tc.role &&
+						bcrypt.CompareHashAndPassword([]byte(u.PasswordHash), []byte("secret123")) == nil
+				})).Return(int64(1), tc.mockError).Once()
+			}
+
+			handler := create.New(slogdiscard.NewDiscardLogger(), userSaverMock)
+
+			req := httptest.NewRequest(http.MethodPost, "/users", strings.NewReader(tc.input))
+			rr := httptest.NewRecorder()
+			handler.ServeHTTP(rr, req)
+
+			require.Equal(t, tc.status, rr.Code)
+
+			var resp create.Response
+			require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &resp))
+
+			require.Equal(t, tc.respError, resp.Error)
+
+			if tc.respError == "" {
+				require.Equal(t, int64(1), resp.ID)
+				require.Equal(t, tc.role, resp.Role)
+			}
+		})
+	}
+}
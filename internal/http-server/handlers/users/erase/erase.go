@@ -0,0 +1,100 @@
+package erase
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	resp "url-shortener/internal/lib/api/response"
+	"url-shortener/internal/lib/logger/sl"
+	"url-shortener/internal/storage"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/go-chi/render"
+)
+
+type Response struct {
+	resp.Response
+	Username string  `json:"username,omitempty"`
+	Report   *Report `json:"report,omitempty"`
+}
+
+// Report tells what was done with the data of the user.
+type Report struct {
+	DeletedLinks           []string `json:"deleted_links"`
+	DeletedClicks          int64    `json:"deleted_clicks"`
+	DeletedAPIKeys         int64    `json:"deleted_api_keys"`
+	AnonymizedAuditEntries int64    `json:"anonymized_audit_entries"`
+	DeletedCampaigns       int64    `json:"deleted_campaigns"`
+	DeletedAccount         bool     `json:"deleted_account"`
+}
+
+//go:generate go run github.com/vektra/mockery/v2@latest --name=UserDataDeleter
+type UserDataDeleter interface {
+	DeleteUserData(ctx context.Context, username string) (storage.UserDataReport, error)
+}
+
+// New deletes the data of the user {id}, a username, for a GDPR erasure
+// request: their links with the clicks, their API keys, their campaigns and
+// their account; the audit log keeps the entries but loses who made them
+// and what they showed. Users from the config file have no account to
+// delete.
+func New(log *slog.Logger, deleter UserDataDeleter) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.users.erase.New"
+
+		log := log.With(
+			slog.String("op", op),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+
+		username := chi.URLParam(r, "id")
+		if username == "" {
+			log.Info("username is empty")
+			render.Status(r, http.StatusBadRequest)
+			render.JSON(w, r, resp.Error("invalid request"))
+			return
+		}
+
+		report, err := deleter.DeleteUserData(r.Context(), username)
+		if err != nil {
+			log.Error("failed to delete user data", sl.Err(err))
+			render.Status(r, http.StatusInternalServerError)
+			render.JSON(w, r, resp.Error("failed to delete user data"))
+			return
+		}
+		if report.Empty() {
+			log.Info("no data of user", slog.String("username", username))
+			render.Status(r, http.StatusNotFound)
+			render.JSON(w, r, resp.Error("user not found"))
+			return
+		}
+
+		log.Info("user data deleted",
+			slog.String("username", username),
+			slog.Int("links", len(report.Aliases)),
+			slog.Int64("clicks", report.Clicks),
+			slog.Int64("api_keys", report.APIKeys),
+			slog.Int64("audit_entries", report.AuditEntries),
+			slog.Int64("campaigns", report.Campaigns),
+			slog.Bool("account", report.User),
+		)
+
+		deleted := report.Aliases
+		if deleted == nil {
+			deleted = []string{}
+		}
+		render.JSON(w, r, Response{
+			Response: resp.OK(),
+			Username: username,
+			Report: &Report{
+				DeletedLinks:           deleted,
+				DeletedClicks:          report.Clicks,
+				DeletedAPIKeys:         report.APIKeys,
+				AnonymizedAuditEntries: report.AuditEntries,
+				DeletedCampaigns:       report.Campaigns,
+				DeletedAccount:         report.User,
+			},
+		})
+	}
+}
@@ -0,0 +1,95 @@
+package erase_test
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"url-shortener/internal/http-server/handlers/users/erase"
+	"url-shortener/internal/http-server/handlers/users/erase/mocks"
+	"url-shortener/internal/lib/logger/handlers/slogdiscard"
+	"url-shortener/internal/storage"
+)
+
+func TestEraseHandler(t *testing.T) {
+	cases := []struct {
+		name       string
+		report     storage.UserDataReport
+		mockError  error
+		status     int
+		respError  string
+		wantReport *erase.Report
+	}{
+		{
+			name: "Success",
+			report: storage.UserDataReport{
+				Aliases:      []string{"abc", "promo"},
+				Clicks:       12,
+				APIKeys:      1,
+				AuditEntries: 4,
+				User:         true,
+			},
+			status: http.StatusOK,
+			wantReport: &erase.Report{
+				DeletedLinks:           []string{"abc", "promo"},
+				DeletedClicks:          12,
+				DeletedAPIKeys:         1,
+				AnonymizedAuditEntries: 4,
+				DeletedAccount:         true,
+			},
+		},
+		{
+			name:   "Config user",
+			report: storage.UserDataReport{AuditEntries: 2},
+			status: http.StatusOK,
+			wantReport: &erase.Report{
+				DeletedLinks:           []string{},
+				AnonymizedAuditEntries: 2,
+			},
+		},
+		{
+			name:      "No data",
+			status:    http.StatusNotFound,
+			respError: "user not found",
+		},
+		{
+			name:      "DeleteUserData Error",
+			mockError: errors.New("unexpected error"),
+			status:    http.StatusInternalServerError,
+			respError: "failed to delete user data",
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			deleterMock := mocks.NewUserDataDeleter(t)
+			deleterMock.On("DeleteUserData", mock.Anything, "bob").
+				Return(tc.report, tc.mockError).
+				Once()
+
+			r := chi.NewRouter()
+			r.Delete("/users/{id}/data", erase.New(slogdiscard.NewDiscardLogger(), deleterMock))
+
+			req := httptest.NewRequest(http.MethodDelete, "/users/bob/data", nil)
+			rr := httptest.NewRecorder()
+			r.ServeHTTP(rr, req)
+
+			require.Equal(t, tc.status, rr.Code)
+
+			var body erase.Response
+			require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &body))
+
+			require.Equal(t, tc.respError, body.Error)
+			require.Equal(t, tc.wantReport, body.Report)
+			if tc.wantReport != nil {
+				require.Equal(t, "bob", body.Username)
+			}
+		})
+	}
+}
@@ -0,0 +1,24 @@
+package favicon
+
+import (
+	"bytes"
+	_ "embed"
+	"net/http"
+	"time"
+)
+
+//go:embed favicon.ico
+var icon []byte
+
+// modTime lets browsers revalidate the icon with If-Modified-Since.
+var modTime = time.Date(2026, time.October, 16, 0, 0, 0, 0, time.UTC)
+
+// New serves the built-in icon for /favicon.ico, which browsers ask for on
+// every page they open, so the request is not looked up as an alias.
+func New() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "image/x-icon")
+		w.Header().Set("Cache-Control", "public, max-age=604800")
+		http.ServeContent(w, r, "favicon.ico", modTime, bytes.NewReader(icon))
+	}
+}
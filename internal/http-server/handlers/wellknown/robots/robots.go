@@ -0,0 +1,26 @@
+package robots
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/go-chi/render"
+)
+
+// New serves /robots.txt, so crawlers asking for it get an answer instead
+// of a "url not found" for the alias "robots.txt". Short links stay open to
+// crawlers, which follow them to the pages they point to; the API and the
+// admin UI are not for indexing.
+func New(apiPrefix string) http.HandlerFunc {
+	body := strings.Join([]string{
+		"User-agent: *",
+		"Disallow: " + apiPrefix + "/",
+		"Disallow: /admin/",
+		"",
+	}, "\n")
+
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Cache-Control", "public, max-age=86400")
+		render.PlainText(w, r, body)
+	}
+}
@@ -0,0 +1,54 @@
+package accesslog
+
+import (
+	"log/slog"
+	"net"
+	"net/http"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/chi/v5/middleware"
+)
+
+// New returns a middleware that writes one record per redirect to log: the
+// alias, where the visitor was sent, the status, the latency, the client IP
+// and the referrer. log is meant to be a dedicated access logger, kept apart
+// from the application logs.
+func New(log *slog.Logger) func(next http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		fn := func(w http.ResponseWriter, r *http.Request) {
+			ww := middleware.NewWrapResponseWriter(w, r.ProtoMajor)
+
+			t1 := time.Now()
+			defer func() {
+				status := ww.Status()
+				if status == 0 {
+					status = http.StatusOK
+				}
+
+				log.Info("redirect",
+					slog.String("alias", chi.URLParam(r, "alias")),
+					slog.String("destination", ww.Header().Get("Location")),
+					slog.Int("status", status),
+					slog.Duration("latency", time.Since(t1)),
+					slog.String("client_ip", clientIP(r)),
+					slog.String("referrer", r.Referer()),
+					slog.String("user_agent", r.UserAgent()),
+					slog.String("request_id", middleware.GetReqID(r.Context())),
+				)
+			}()
+
+			next.ServeHTTP(ww, r)
+		}
+
+		return http.HandlerFunc(fn)
+	}
+}
+
+func clientIP(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
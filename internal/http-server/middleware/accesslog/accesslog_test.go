@@ -0,0 +1,41 @@
+package accesslog_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/require"
+
+	"url-shortener/internal/http-server/middleware/accesslog"
+)
+
+func TestAccessLogMiddleware(t *testing.T) {
+	var buf bytes.Buffer
+	log := slog.New(slog.NewJSONHandler(&buf, nil))
+
+	r := chi.NewRouter()
+	r.With(accesslog.New(log)).Get("/{alias}", func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, "https://example.com", http.StatusFound)
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/abc", nil)
+	req.RemoteAddr = "203.0.113.7:52000"
+	req.Header.Set("Referer", "https://news.example.org/")
+	r.ServeHTTP(httptest.NewRecorder(), req)
+
+	var record map[string]any
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &record))
+
+	require.Equal(t, "redirect", record["msg"])
+	require.Equal(t, "abc", record["alias"])
+	require.Equal(t, "https://example.com", record["destination"])
+	require.Equal(t, float64(http.StatusFound), record["status"])
+	require.Equal(t, "203.0.113.7", record["client_ip"])
+	require.Equal(t, "https://news.example.org/", record["referrer"])
+	require.Contains(t, record, "latency")
+}
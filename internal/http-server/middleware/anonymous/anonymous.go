@@ -0,0 +1,117 @@
+package anonymous
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net"
+	"net/http"
+	"time"
+	resp "url-shortener/internal/lib/api/response"
+	"url-shortener/internal/lib/captcha"
+	"url-shortener/internal/lib/logger/sl"
+
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/go-chi/render"
+)
+
+// TokenHeader carries the captcha token a visitor without an account
+// solved, e.g. the h-captcha-response or cf-turnstile-response of the
+// widget.
+const TokenHeader = "X-Captcha-Token"
+
+//go:generate go run github.com/vektra/mockery/v2@latest --name=CaptchaVerifier
+type CaptchaVerifier interface {
+	Verify(ctx context.Context, token string, remoteIP string) error
+}
+
+// Visitor is a client that made a request without an account.
+type Visitor struct {
+	// LinkTTL is how long the links it creates live at most.
+	LinkTTL time.Duration
+}
+
+type ctxKey struct{}
+
+// FromContext returns the visitor if the request was let in without an
+// account.
+func FromContext(ctx context.Context) (Visitor, bool) {
+	v, ok := ctx.Value(ctxKey{}).(Visitor)
+	return v, ok
+}
+
+// WithVisitor returns a copy of ctx carrying v, as the middleware does.
+func WithVisitor(ctx context.Context, v Visitor) context.Context {
+	return context.WithValue(ctx, ctxKey{}, v)
+}
+
+// New returns a middleware that opens a route to visitors without an
+// account. Requests with credentials, an Authorization or X-Api-Key
+// header, go through authenticate, normally the auth middleware, as
+// before. The rest pass limits, e.g. a per-IP rate limit, then must send
+// a captcha token verifier accepts in TokenHeader, and reach next with
+// a Visitor whose links expire after linkTTL.
+func New(
+	log *slog.Logger,
+	verifier CaptchaVerifier,
+	linkTTL time.Duration,
+	authenticate func(http.Handler) http.Handler,
+	limits ...func(http.Handler) http.Handler,
+) func(next http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		log := log.With(
+			slog.String("component", "middleware/anonymous"),
+		)
+
+		log.Info("anonymous access enabled", slog.Duration("link_ttl", linkTTL))
+
+		authenticated := authenticate(next)
+
+		var anonymous http.Handler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			err := verifier.Verify(r.Context(), r.Header.Get(TokenHeader), clientIP(r))
+			if errors.Is(err, captcha.ErrInvalidToken) {
+				log.Info("captcha rejected",
+					sl.Err(err),
+					slog.String("request_id", middleware.GetReqID(r.Context())),
+				)
+				render.Status(r, http.StatusForbidden)
+				render.JSON(w, r, resp.Error("captcha verification failed"))
+				return
+			}
+			if err != nil {
+				log.Error("failed to verify captcha",
+					sl.Err(err),
+					slog.String("request_id", middleware.GetReqID(r.Context())),
+				)
+				render.Status(r, http.StatusServiceUnavailable)
+				render.JSON(w, r, resp.Error("captcha verification unavailable"))
+				return
+			}
+
+			ctx := WithVisitor(r.Context(), Visitor{LinkTTL: linkTTL})
+			next.ServeHTTP(w, r.WithContext(ctx))
+		})
+		for i := len(limits) - 1; i >= 0; i-- {
+			anonymous = limits[i](anonymous)
+		}
+
+		fn := func(w http.ResponseWriter, r *http.Request) {
+			if r.Header.Get("Authorization") != "" || r.Header.Get("X-Api-Key") != "" {
+				authenticated.ServeHTTP(w, r)
+				return
+			}
+
+			anonymous.ServeHTTP(w, r)
+		}
+
+		return http.HandlerFunc(fn)
+	}
+}
+
+func clientIP(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
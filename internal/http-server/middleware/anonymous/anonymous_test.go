@@ -0,0 +1,109 @@
+package anonymous_test
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"url-shortener/internal/http-server/middleware/anonymous"
+	"url-shortener/internal/http-server/middleware/anonymous/mocks"
+	"url-shortener/internal/lib/captcha"
+	"url-shortener/internal/lib/logger/handlers/slogdiscard"
+)
+
+func TestAnonymousMiddleware(t *testing.T) {
+	cases := []struct {
+		name      string
+		header    string
+		value     string
+		token     string
+		verifyErr error
+		wantCode  int
+		wantVia   string
+	}{
+		{
+			name:     "Bearer token",
+			header:   "Authorization",
+			value:    "Bearer abc",
+			wantCode: http.StatusOK,
+			wantVia:  "auth",
+		},
+		{
+			name:     "API key",
+			header:   "X-Api-Key",
+			value:    "key",
+			wantCode: http.StatusOK,
+			wantVia:  "auth",
+		},
+		{
+			name:     "Solved captcha",
+			token:    "solved",
+			wantCode: http.StatusOK,
+			wantVia:  "limit,visitor",
+		},
+		{
+			name:      "Invalid captcha",
+			token:     "forged",
+			verifyErr: fmt.Errorf("verify: %w", captcha.ErrInvalidToken),
+			wantCode:  http.StatusForbidden,
+			wantVia:   "limit",
+		},
+		{
+			name:      "Provider down",
+			token:     "solved",
+			verifyErr: errors.New("connection refused"),
+			wantCode:  http.StatusServiceUnavailable,
+			wantVia:   "limit",
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			verifierMock := mocks.NewCaptchaVerifier(t)
+			if tc.header == "" {
+				verifierMock.On("Verify", mock.Anything, tc.token, "192.0.2.1").
+					Return(tc.verifyErr).Once()
+			}
+
+			var via []string
+			mark := func(name string) func(http.Handler) http.Handler {
+				return func(next http.Handler) http.Handler {
+					return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+						via = append(via, name)
+						next.ServeHTTP(w, r)
+					})
+				}
+			}
+			next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if v, ok := anonymous.FromContext(r.Context()); ok {
+					require.Equal(t, time.Hour, v.LinkTTL)
+					via = append(via, "visitor")
+				}
+			})
+
+			handler := anonymous.New(slogdiscard.NewDiscardLogger(), verifierMock, time.Hour, mark("auth"), mark("limit"))(next)
+
+			req := httptest.NewRequest(http.MethodPost, "/url", nil)
+			req.RemoteAddr = "192.0.2.1:1234"
+			if tc.header != "" {
+				req.Header.Set(tc.header, tc.value)
+			}
+			if tc.token != "" {
+				req.Header.Set(anonymous.TokenHeader, tc.token)
+			}
+			rr := httptest.NewRecorder()
+
+			handler.ServeHTTP(rr, req)
+
+			require.Equal(t, tc.wantCode, rr.Code)
+			require.Equal(t, tc.wantVia, strings.Join(via, ","))
+		})
+	}
+}
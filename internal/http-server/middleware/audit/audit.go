@@ -0,0 +1,35 @@
+package audit
+
+import (
+	"net"
+	"net/http"
+
+	"url-shortener/internal/http-server/middleware/auth"
+	"url-shortener/internal/storage/audited"
+)
+
+// Actor attributes the changes a request makes to the user and API key it
+// was authenticated with and to its client IP. It goes after the auth
+// middleware.
+func Actor(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		user, _ := auth.UserFromContext(r.Context())
+		keyID, _ := auth.APIKeyIDFromContext(r.Context())
+
+		ctx := audited.WithActor(r.Context(), audited.Actor{
+			User:     user.Username,
+			APIKeyID: keyID,
+			IP:       clientIP(r),
+		})
+
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
+func clientIP(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
@@ -0,0 +1,28 @@
+package audit_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"url-shortener/internal/http-server/middleware/audit"
+	"url-shortener/internal/http-server/middleware/auth"
+	"url-shortener/internal/storage"
+	"url-shortener/internal/storage/audited"
+)
+
+func TestActorMiddleware(t *testing.T) {
+	var actor audited.Actor
+	handler := audit.Actor(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		actor = audited.ActorFromContext(r.Context())
+	}))
+
+	req := httptest.NewRequest(http.MethodPost, "/url", nil)
+	req.RemoteAddr = "203.0.113.7:52000"
+	req = req.WithContext(auth.WithUser(req.Context(), storage.User{Username: "alice", Role: storage.RoleUser}))
+	handler.ServeHTTP(httptest.NewRecorder(), req)
+
+	require.Equal(t, audited.Actor{User: "alice", IP: "203.0.113.7"}, actor)
+}
@@ -0,0 +1,239 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+	"strings"
+	resp "url-shortener/internal/lib/api/response"
+	"url-shortener/internal/lib/apikey"
+	"url-shortener/internal/lib/logger/sl"
+	"url-shortener/internal/storage"
+	"url-shortener/internal/users"
+
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/go-chi/render"
+)
+
+//go:generate go run github.com/vektra/mockery/v2@latest --name=TokenParser
+type TokenParser interface {
+	Parse(token string) (string, string, error)
+}
+
+// TokenParsers accepts a token any of its parsers accepts, trying them in
+// order. The error is that of the first parser.
+type TokenParsers []TokenParser
+
+func (p TokenParsers) Parse(token string) (string, string, error) {
+	firstErr := errors.New("no token parsers")
+	for i, parser := range p {
+		username, role, err := parser.Parse(token)
+		if err == nil {
+			return username, role, nil
+		}
+		if i == 0 {
+			firstErr = err
+		}
+	}
+
+	return "", "", firstErr
+}
+
+//go:generate go run github.com/vektra/mockery/v2@latest --name=KeyGetter
+type KeyGetter interface {
+	GetAPIKeyByHash(ctx context.Context, hash string) (storage.APIKey, error)
+}
+
+//go:generate go run github.com/vektra/mockery/v2@latest --name=UserAuthenticator
+type UserAuthenticator interface {
+	Authenticate(ctx context.Context, username string, password string) (storage.User, error)
+	GetUser(ctx context.Context, username string) (storage.User, error)
+}
+
+type (
+	ctxKey       struct{}
+	apiKeyCtxKey struct{}
+)
+
+// UserFromContext returns the user authenticated by the middleware.
+func UserFromContext(ctx context.Context) (storage.User, bool) {
+	user, ok := ctx.Value(ctxKey{}).(storage.User)
+	return user, ok
+}
+
+// WithUser returns a copy of ctx carrying user, as the middleware does
+// after a successful authentication.
+func WithUser(ctx context.Context, user storage.User) context.Context {
+	return context.WithValue(ctx, ctxKey{}, user)
+}
+
+// OwnerFromContext returns the owner storage queries should be restricted
+// to: the authenticated user's name, or "" (everyone's links) for admins.
+func OwnerFromContext(ctx context.Context) string {
+	user, _ := UserFromContext(ctx)
+	if user.IsAdmin() {
+		return ""
+	}
+
+	return user.Username
+}
+
+// ViewerFromContext returns the owner read-only queries should be
+// restricted to: like OwnerFromContext, but viewers see everyone's links.
+// Queries that change links must use OwnerFromContext.
+func ViewerFromContext(ctx context.Context) string {
+	user, _ := UserFromContext(ctx)
+	if user.CanViewAll() {
+		return ""
+	}
+
+	return user.Username
+}
+
+// APIKeyIDFromContext returns the id of the API key the request was
+// authenticated with.
+func APIKeyIDFromContext(ctx context.Context) (int64, bool) {
+	id, ok := ctx.Value(apiKeyCtxKey{}).(int64)
+	return id, ok
+}
+
+// New returns a middleware that requires a valid "Authorization: Bearer"
+// token. If keys is not nil, an active key in the X-Api-Key header is
+// accepted too, and if basicFallback is set, so are user credentials sent
+// via Basic Auth.
+func New(
+	log *slog.Logger,
+	tokens TokenParser,
+	keys KeyGetter,
+	userAuth UserAuthenticator,
+	basicFallback bool,
+) func(next http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		log := log.With(
+			slog.String("component", "middleware/auth"),
+		)
+
+		log.Info("auth middleware enabled",
+			slog.Bool("api_keys", keys != nil),
+			slog.Bool("basic_fallback", basicFallback),
+		)
+
+		fn := func(w http.ResponseWriter, r *http.Request) {
+			if key := r.Header.Get("X-Api-Key"); key != "" && keys != nil {
+				k, err := keys.GetAPIKeyByHash(r.Context(), apikey.Hash(key))
+				if err != nil && !errors.Is(err, storage.ErrKeyNotFound) {
+					log.Error("failed to get api key",
+						sl.Err(err),
+						slog.String("request_id", middleware.GetReqID(r.Context())),
+					)
+					render.Status(r, http.StatusInternalServerError)
+					render.JSON(w, r, resp.Error("internal error"))
+					return
+				}
+				if err != nil || k.Revoked() {
+					log.Info("invalid api key", slog.String("request_id", middleware.GetReqID(r.Context())))
+					unauthorized(w, r, basicFallback)
+					return
+				}
+
+				// The key acts with the current role of its owner.
+				user, err := userAuth.GetUser(r.Context(), k.Owner)
+				if errors.Is(err, storage.ErrUserNotFound) {
+					log.Info("api key owner not found", slog.String("request_id", middleware.GetReqID(r.Context())))
+					unauthorized(w, r, basicFallback)
+					return
+				}
+				if err != nil {
+					log.Error("failed to get api key owner",
+						sl.Err(err),
+						slog.String("request_id", middleware.GetReqID(r.Context())),
+					)
+					render.Status(r, http.StatusInternalServerError)
+					render.JSON(w, r, resp.Error("internal error"))
+					return
+				}
+
+				ctx := WithUser(r.Context(), user)
+				ctx = context.WithValue(ctx, apiKeyCtxKey{}, k.ID)
+				next.ServeHTTP(w, r.WithContext(ctx))
+				return
+			}
+
+			if token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer "); ok {
+				username, role, err := tokens.Parse(token)
+				if err != nil {
+					log.Info("invalid token",
+						sl.Err(err),
+						slog.String("request_id", middleware.GetReqID(r.Context())),
+					)
+					unauthorized(w, r, basicFallback)
+					return
+				}
+
+				user := storage.User{Username: username, Role: role}
+				next.ServeHTTP(w, r.WithContext(WithUser(r.Context(), user)))
+				return
+			}
+
+			if username, password, ok := r.BasicAuth(); ok && basicFallback {
+				user, err := userAuth.Authenticate(r.Context(), username, password)
+				if err == nil {
+					next.ServeHTTP(w, r.WithContext(WithUser(r.Context(), user)))
+					return
+				}
+				if !errors.Is(err, users.ErrInvalidCredentials) {
+					log.Error("failed to authenticate user",
+						sl.Err(err),
+						slog.String("request_id", middleware.GetReqID(r.Context())),
+					)
+					render.Status(r, http.StatusInternalServerError)
+					render.JSON(w, r, resp.Error("internal error"))
+					return
+				}
+			}
+
+			unauthorized(w, r, basicFallback)
+		}
+
+		return http.HandlerFunc(fn)
+	}
+}
+
+// AdminOnly rejects requests from users without the admin role. It must be
+// mounted after the middleware returned by New.
+func AdminOnly(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if user, _ := UserFromContext(r.Context()); !user.IsAdmin() {
+			render.Status(r, http.StatusForbidden)
+			render.JSON(w, r, resp.Error("forbidden"))
+			return
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+// EditorOnly rejects requests from viewers, who may only read links. It
+// must be mounted after the middleware returned by New.
+func EditorOnly(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if user, _ := UserFromContext(r.Context()); !user.CanEdit() {
+			render.Status(r, http.StatusForbidden)
+			render.JSON(w, r, resp.Error("forbidden"))
+			return
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+func unauthorized(w http.ResponseWriter, r *http.Request, basic bool) {
+	w.Header().Add("WWW-Authenticate", `Bearer realm="url-shortener"`)
+	if basic {
+		w.Header().Add("WWW-Authenticate", `Basic realm="url-shortener"`)
+	}
+
+	render.Status(r, http.StatusUnauthorized)
+	render.JSON(w, r, resp.Error("unauthorized"))
+}
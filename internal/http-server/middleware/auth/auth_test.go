@@ -0,0 +1,289 @@
+package auth_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"url-shortener/internal/http-server/middleware/auth"
+	"url-shortener/internal/http-server/middleware/auth/mocks"
+	"url-shortener/internal/lib/apikey"
+	"url-shortener/internal/lib/logger/handlers/slogdiscard"
+	"url-shortener/internal/storage"
+	"url-shortener/internal/users"
+)
+
+func TestAuthMiddleware(t *testing.T) {
+	cases := []struct {
+		name       string
+		bearer     string
+		basicUser  string
+		basicPass  string
+		fallback   bool
+		parseError error
+		apiKey     string
+		key        storage.APIKey
+		keyError   error
+		ownerError error
+		authError  error
+		wantCode   int
+		wantUser   string
+		wantRole   string
+		wantKeyID  int64
+	}{
+		{
+			name:     "Valid token",
+			bearer:   "good",
+			wantCode: http.StatusOK,
+			wantUser: "alice",
+			wantRole: storage.RoleUser,
+		},
+		{
+			name:       "Invalid token",
+			bearer:     "bad",
+			parseError: errors.New("token is expired"),
+			wantCode:   http.StatusUnauthorized,
+		},
+		{
+			name:     "No credentials",
+			wantCode: http.StatusUnauthorized,
+		},
+		{
+			name:      "Basic without fallback",
+			basicUser: "myuser",
+			basicPass: "mypass",
+			wantCode:  http.StatusUnauthorized,
+		},
+		{
+			name:      "Basic with fallback",
+			basicUser: "myuser",
+			basicPass: "mypass",
+			fallback:  true,
+			wantCode:  http.StatusOK,
+			wantUser:  "myuser",
+			wantRole:  storage.RoleAdmin,
+		},
+		{
+			name:      "Valid api key",
+			apiKey:    "us_good",
+			key:       storage.APIKey{ID: 7, Owner: "bob"},
+			wantCode:  http.StatusOK,
+			wantUser:  "bob",
+			wantRole:  storage.RoleUser,
+			wantKeyID: 7,
+		},
+		{
+			name:       "Api key of deleted user",
+			apiKey:     "us_good",
+			key:        storage.APIKey{ID: 7, Owner: "bob"},
+			ownerError: storage.ErrUserNotFound,
+			wantCode:   http.StatusUnauthorized,
+		},
+		{
+			name:     "Revoked api key",
+			apiKey:   "us_revoked",
+			key:      storage.APIKey{ID: 7, Owner: "bob", RevokedAt: time.Now()},
+			wantCode: http.StatusUnauthorized,
+		},
+		{
+			name:     "Unknown api key",
+			apiKey:   "us_unknown",
+			keyError: storage.ErrKeyNotFound,
+			wantCode: http.StatusUnauthorized,
+		},
+		{
+			name:     "Api key lookup error",
+			apiKey:   "us_good",
+			keyError: errors.New("unexpected error"),
+			wantCode: http.StatusInternalServerError,
+		},
+		{
+			name:      "Wrong basic password",
+			basicUser: "myuser",
+			basicPass: "wrong",
+			fallback:  true,
+			authError: users.ErrInvalidCredentials,
+			wantCode:  http.StatusUnauthorized,
+		},
+		{
+			name:      "Basic authenticate error",
+			basicUser: "myuser",
+			basicPass: "mypass",
+			fallback:  true,
+			authError: errors.New("unexpected error"),
+			wantCode:  http.StatusInternalServerError,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			parserMock := mocks.NewTokenParser(t)
+			keyGetterMock := mocks.NewKeyGetter(t)
+			userAuthMock := mocks.NewUserAuthenticator(t)
+
+			if tc.bearer != "" {
+				parserMock.On("Parse", tc.bearer).Return(tc.wantUser, tc.wantRole, tc.parseError).Once()
+			}
+			if tc.apiKey != "" {
+				keyGetterMock.On("GetAPIKeyByHash", mock.Anything, apikey.Hash(tc.apiKey)).
+					Return(tc.key, tc.keyError).Once()
+			}
+			if tc.apiKey != "" && tc.keyError == nil && !tc.key.Revoked() {
+				userAuthMock.On("GetUser", mock.Anything, tc.key.Owner).
+					Return(storage.User{Username: tc.key.Owner, Role: storage.RoleUser}, tc.ownerError).Once()
+			}
+			if tc.basicUser != "" && tc.fallback {
+				var user storage.User
+				if tc.authError == nil {
+					user = storage.User{Username: tc.basicUser, Role: storage.RoleAdmin}
+				}
+				userAuthMock.On("Authenticate", mock.Anything, tc.basicUser, tc.basicPass).
+					Return(user, tc.authError).Once()
+			}
+
+			var (
+				gotUser  storage.User
+				gotKeyID int64
+			)
+			next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				gotUser, _ = auth.UserFromContext(r.Context())
+				gotKeyID, _ = auth.APIKeyIDFromContext(r.Context())
+			})
+
+			handler := auth.New(slogdiscard.NewDiscardLogger(), parserMock, keyGetterMock, userAuthMock, tc.fallback)(next)
+
+			req := httptest.NewRequest(http.MethodPost, "/url", nil)
+			if tc.bearer != "" {
+				req.Header.Set("Authorization", "Bearer "+tc.bearer)
+			}
+			if tc.basicUser != "" {
+				req.SetBasicAuth(tc.basicUser, tc.basicPass)
+			}
+			if tc.apiKey != "" {
+				req.Header.Set("X-Api-Key", tc.apiKey)
+			}
+
+			rr := httptest.NewRecorder()
+			handler.ServeHTTP(rr, req)
+
+			require.Equal(t, tc.wantCode, rr.Code)
+			require.Equal(t, tc.wantUser, gotUser.Username)
+			require.Equal(t, tc.wantRole, gotUser.Role)
+			require.Equal(t, tc.wantKeyID, gotKeyID)
+
+			if tc.wantCode == http.StatusUnauthorized {
+				require.NotEmpty(t, rr.Header().Values("WWW-Authenticate"))
+			}
+		})
+	}
+}
+
+func TestAdminOnly(t *testing.T) {
+	cases := []struct {
+		name     string
+		role     string
+		wantCode int
+	}{
+		{name: "Admin", role: storage.RoleAdmin, wantCode: http.StatusOK},
+		{name: "User", role: storage.RoleUser, wantCode: http.StatusForbidden},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			parserMock := mocks.NewTokenParser(t)
+			parserMock.On("Parse", "good").Return("alice", tc.role, nil).Once()
+
+			next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {})
+			handler := auth.New(slogdiscard.NewDiscardLogger(), parserMock, nil, nil, false)(auth.AdminOnly(next))
+
+			req := httptest.NewRequest(http.MethodPost, "/users", nil)
+			req.Header.Set("Authorization", "Bearer good")
+
+			rr := httptest.NewRecorder()
+			handler.ServeHTTP(rr, req)
+
+			require.Equal(t, tc.wantCode, rr.Code)
+		})
+	}
+}
+
+func TestEditorOnly(t *testing.T) {
+	cases := []struct {
+		name     string
+		role     string
+		wantCode int
+	}{
+		{name: "Admin", role: storage.RoleAdmin, wantCode: http.StatusOK},
+		{name: "Editor", role: storage.RoleEditor, wantCode: http.StatusOK},
+		{name: "Legacy user", role: storage.RoleUser, wantCode: http.StatusOK},
+		{name: "Viewer", role: storage.RoleViewer, wantCode: http.StatusForbidden},
+		{name: "Unknown role", role: "guest", wantCode: http.StatusForbidden},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			parserMock := mocks.NewTokenParser(t)
+			parserMock.On("Parse", "good").Return("alice", tc.role, nil).Once()
+
+			next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {})
+			handler := auth.New(slogdiscard.NewDiscardLogger(), parserMock, nil, nil, false)(auth.EditorOnly(next))
+
+			req := httptest.NewRequest(http.MethodPost, "/url", nil)
+			req.Header.Set("Authorization", "Bearer good")
+
+			rr := httptest.NewRecorder()
+			handler.ServeHTTP(rr, req)
+
+			require.Equal(t, tc.wantCode, rr.Code)
+		})
+	}
+}
+
+func TestViewerFromContext(t *testing.T) {
+	for role, want := range map[string]string{
+		storage.RoleAdmin:  "",
+		storage.RoleViewer: "",
+		storage.RoleEditor: "alice",
+		storage.RoleUser:   "alice",
+	} {
+		ctx := auth.WithUser(context.Background(), storage.User{Username: "alice", Role: role})
+		require.Equal(t, want, auth.ViewerFromContext(ctx), role)
+	}
+
+	ctx := auth.WithUser(context.Background(), storage.User{Username: "alice", Role: storage.RoleViewer})
+	require.Equal(t, "alice", auth.OwnerFromContext(ctx))
+}
+
+func TestTokenParsers(t *testing.T) {
+	local := mocks.NewTokenParser(t)
+	local.On("Parse", "local").Return("alice", storage.RoleUser, nil)
+	local.On("Parse", mock.Anything).Return("", "", errors.New("bad signature"))
+
+	external := mocks.NewTokenParser(t)
+	external.On("Parse", "external").Return("bob@example.com", storage.RoleAdmin, nil)
+	external.On("Parse", mock.Anything).Return("", "", errors.New("unknown key"))
+
+	parsers := auth.TokenParsers{local, external}
+
+	username, role, err := parsers.Parse("local")
+	require.NoError(t, err)
+	require.Equal(t, "alice", username)
+	require.Equal(t, storage.RoleUser, role)
+
+	username, role, err = parsers.Parse("external")
+	require.NoError(t, err)
+	require.Equal(t, "bob@example.com", username)
+	require.Equal(t, storage.RoleAdmin, role)
+
+	_, _, err = parsers.Parse("forged")
+	require.EqualError(t, err, "bad signature")
+
+	_, _, err = auth.TokenParsers{}.Parse("local")
+	require.Error(t, err)
+}
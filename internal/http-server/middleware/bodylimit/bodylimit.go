@@ -0,0 +1,39 @@
+package bodylimit
+
+import (
+	"net/http"
+	"slices"
+	resp "url-shortener/internal/lib/api/response"
+
+	"github.com/go-chi/render"
+)
+
+// New returns a middleware that answers 413 to requests whose
+// Content-Length is over limit bytes. Bodies sent without one are cut off
+// at limit, so handlers fail to read them. Requests for the paths in
+// exempt, such as uploads that enforce limits of their own, are let
+// through.
+func New(limit int64, exempt ...string) func(next http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		fn := func(w http.ResponseWriter, r *http.Request) {
+			if r.Body == nil || r.Body == http.NoBody || slices.Contains(exempt, r.URL.Path) {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			if r.ContentLength > limit {
+				// The body is not read, so the connection cannot be reused.
+				w.Header().Set("Connection", "close")
+				render.Status(r, http.StatusRequestEntityTooLarge)
+				render.JSON(w, r, resp.Error("request body too large"))
+				return
+			}
+
+			r.Body = http.MaxBytesReader(w, r.Body, limit)
+
+			next.ServeHTTP(w, r)
+		}
+
+		return http.HandlerFunc(fn)
+	}
+}
@@ -0,0 +1,54 @@
+package bodylimit_test
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"url-shortener/internal/http-server/middleware/bodylimit"
+)
+
+func TestNew(t *testing.T) {
+	cases := []struct {
+		name     string
+		path     string
+		body     string
+		chunked  bool
+		wantCode int
+		wantBody string
+	}{
+		{name: "Within limit", path: "/url", body: "0123456789", wantCode: http.StatusOK, wantBody: "0123456789"},
+		{name: "Too large", path: "/url", body: "0123456789a", wantCode: http.StatusRequestEntityTooLarge},
+		{name: "Too large without length", path: "/url", body: "0123456789a", chunked: true, wantCode: http.StatusBadRequest},
+		{name: "Exempt", path: "/urls/import", body: "0123456789a", wantCode: http.StatusOK, wantBody: "0123456789a"},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			handler := bodylimit.New(10, "/urls/import")(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				body, err := io.ReadAll(r.Body)
+				if err != nil {
+					w.WriteHeader(http.StatusBadRequest)
+					return
+				}
+				_, _ = w.Write(body)
+			}))
+
+			req := httptest.NewRequest(http.MethodPost, tc.path, strings.NewReader(tc.body))
+			if tc.chunked {
+				req.ContentLength = -1
+			}
+			rr := httptest.NewRecorder()
+			handler.ServeHTTP(rr, req)
+
+			require.Equal(t, tc.wantCode, rr.Code)
+			if tc.wantBody != "" {
+				require.Equal(t, tc.wantBody, rr.Body.String())
+			}
+		})
+	}
+}
@@ -0,0 +1,267 @@
+// Package compress compresses responses with gzip or deflate for clients
+// that accept either.
+package compress
+
+import (
+	"compress/gzip"
+	"compress/zlib"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+const (
+	encodingGzip    = "gzip"
+	encodingDeflate = "deflate"
+)
+
+type Options struct {
+	// MinSize is the smallest body, in bytes, worth compressing.
+	MinSize int
+	// GzipLevel and DeflateLevel go from 1, the fastest, to 9, the
+	// smallest output.
+	GzipLevel    int
+	DeflateLevel int
+	// Types are the media types to compress, e.g. application/json.
+	Types []string
+}
+
+// encoder is what gzip.Writer and zlib.Writer have in common.
+type encoder interface {
+	io.WriteCloser
+	Flush() error
+	Reset(w io.Writer)
+}
+
+type compressor struct {
+	opts  Options
+	pools map[string]*sync.Pool
+}
+
+// New returns a middleware that compresses responses of opts.Types once
+// their body reaches opts.MinSize. Responses that already have a
+// Content-Encoding are left alone.
+func New(opts Options) (func(next http.Handler) http.Handler, error) {
+	const op = "middleware.compress.New"
+
+	if _, err := gzip.NewWriterLevel(io.Discard, opts.GzipLevel); err != nil {
+		return nil, fmt.Errorf("%s: gzip: %w", op, err)
+	}
+	if _, err := zlib.NewWriterLevel(io.Discard, opts.DeflateLevel); err != nil {
+		return nil, fmt.Errorf("%s: deflate: %w", op, err)
+	}
+
+	c := &compressor{
+		opts: opts,
+		pools: map[string]*sync.Pool{
+			encodingGzip: {New: func() any {
+				w, _ := gzip.NewWriterLevel(io.Discard, opts.GzipLevel)
+				return w
+			}},
+			encodingDeflate: {New: func() any {
+				w, _ := zlib.NewWriterLevel(io.Discard, opts.DeflateLevel)
+				return w
+			}},
+		},
+	}
+	c.opts.Types = make([]string, len(opts.Types))
+	for i, t := range opts.Types {
+		c.opts.Types[i] = strings.ToLower(strings.TrimSpace(t))
+	}
+
+	return func(next http.Handler) http.Handler {
+		fn := func(w http.ResponseWriter, r *http.Request) {
+			if r.Method == http.MethodHead {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			cw := &writer{
+				ResponseWriter: w,
+				c:              c,
+				encoding:       negotiate(r.Header.Get("Accept-Encoding")),
+			}
+			next.ServeHTTP(cw, r)
+			_ = cw.close()
+		}
+
+		return http.HandlerFunc(fn)
+	}, nil
+}
+
+func (c *compressor) compressible(contentType string) bool {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return false
+	}
+	return slices.Contains(c.opts.Types, mediaType)
+}
+
+// negotiate picks the encoding from an Accept-Encoding header, preferring
+// gzip when both are equally welcome. It returns "" for none.
+func negotiate(header string) string {
+	var (
+		best  string
+		bestQ float64
+	)
+	for _, part := range strings.Split(header, ",") {
+		name, params, _ := strings.Cut(part, ";")
+		name = strings.ToLower(strings.TrimSpace(name))
+
+		q := 1.0
+		if v, ok := strings.CutPrefix(strings.TrimSpace(params), "q="); ok {
+			parsed, err := strconv.ParseFloat(v, 64)
+			if err != nil || parsed <= 0 {
+				continue
+			}
+			q = parsed
+		}
+
+		var encoding string
+		switch name {
+		case encodingGzip, "x-gzip", "*":
+			encoding = encodingGzip
+		case encodingDeflate:
+			encoding = encodingDeflate
+		default:
+			continue
+		}
+		if q > bestQ || (q == bestQ && encoding == encodingGzip) {
+			best, bestQ = encoding, q
+		}
+	}
+
+	return best
+}
+
+// writer holds the body back until it reaches MinSize, then decides
+// whether to compress it.
+type writer struct {
+	http.ResponseWriter
+	c        *compressor
+	encoding string
+
+	status  int
+	buf     []byte
+	decided bool
+	enc     encoder
+}
+
+func (w *writer) WriteHeader(code int) {
+	switch {
+	case w.decided:
+		w.ResponseWriter.WriteHeader(code)
+	case code < http.StatusOK:
+		// Informational responses go out at once.
+		w.ResponseWriter.WriteHeader(code)
+	case w.status == 0:
+		w.status = code
+	}
+}
+
+func (w *writer) Write(p []byte) (int, error) {
+	if w.decided {
+		if w.enc != nil {
+			return w.enc.Write(p)
+		}
+		return w.ResponseWriter.Write(p)
+	}
+
+	if w.status == 0 {
+		w.status = http.StatusOK
+	}
+	w.buf = append(w.buf, p...)
+	if len(w.buf) >= w.c.opts.MinSize {
+		if err := w.decide(true); err != nil {
+			return 0, err
+		}
+	}
+
+	return len(p), nil
+}
+
+// decide sends the headers and the held back body, compressed if the
+// response qualifies. large reports whether the body is big enough.
+func (w *writer) decide(large bool) error {
+	w.decided = true
+
+	h := w.Header()
+	if h.Get("Content-Type") == "" && len(w.buf) > 0 {
+		// net/http would sniff the compressed bytes instead.
+		h.Set("Content-Type", http.DetectContentType(w.buf))
+	}
+
+	compressible := h.Get("Content-Encoding") == "" &&
+		w.status != http.StatusNoContent && w.status != http.StatusNotModified &&
+		w.c.compressible(h.Get("Content-Type"))
+	if compressible {
+		h.Add("Vary", "Accept-Encoding")
+	}
+
+	if compressible && large && w.encoding != "" {
+		h.Set("Content-Encoding", w.encoding)
+		h.Del("Content-Length")
+		w.enc = w.c.pools[w.encoding].Get().(encoder)
+		w.enc.Reset(w.ResponseWriter)
+	}
+
+	w.ResponseWriter.WriteHeader(w.status)
+
+	buf := w.buf
+	w.buf = nil
+	if len(buf) == 0 {
+		return nil
+	}
+	if w.enc != nil {
+		_, err := w.enc.Write(buf)
+		return err
+	}
+	_, err := w.ResponseWriter.Write(buf)
+	return err
+}
+
+// Flush sends what has been written so far. A stream flushed before it
+// reaches MinSize is compressed from the start if its type qualifies, as
+// it is likely to grow.
+func (w *writer) Flush() {
+	if !w.decided {
+		if w.status == 0 {
+			w.status = http.StatusOK
+		}
+		if err := w.decide(true); err != nil {
+			return
+		}
+	}
+	if w.enc != nil {
+		if err := w.enc.Flush(); err != nil {
+			return
+		}
+	}
+	_ = http.NewResponseController(w.ResponseWriter).Flush()
+}
+
+// Unwrap lets http.ResponseController reach the underlying writer.
+func (w *writer) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
+func (w *writer) close() error {
+	if !w.decided && w.status != 0 {
+		if err := w.decide(false); err != nil {
+			return err
+		}
+	}
+	if w.enc == nil {
+		return nil
+	}
+
+	err := w.enc.Close()
+	w.c.pools[w.encoding].Put(w.enc)
+	w.enc = nil
+	return err
+}
@@ -0,0 +1,124 @@
+package compress_test
+
+import (
+	"compress/gzip"
+	"compress/zlib"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"url-shortener/internal/http-server/middleware/compress"
+)
+
+var opts = compress.Options{
+	MinSize:      100,
+	GzipLevel:    5,
+	DeflateLevel: 5,
+	Types:        []string{"application/json", "text/csv"},
+}
+
+func serve(t *testing.T, acceptEncoding string, handler http.HandlerFunc) *httptest.ResponseRecorder {
+	t.Helper()
+
+	mw, err := compress.New(opts)
+	require.NoError(t, err)
+
+	req := httptest.NewRequest(http.MethodGet, "/url", nil)
+	if acceptEncoding != "" {
+		req.Header.Set("Accept-Encoding", acceptEncoding)
+	}
+	rr := httptest.NewRecorder()
+	mw(handler).ServeHTTP(rr, req)
+
+	return rr
+}
+
+func jsonBody(body string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json; charset=utf-8")
+		_, _ = io.WriteString(w, body)
+	}
+}
+
+func TestNew(t *testing.T) {
+	large := `{"urls":[` + strings.Repeat(`{"alias":"abc"},`, 20) + `{}]}`
+
+	cases := []struct {
+		name           string
+		acceptEncoding string
+		body           string
+		encoding       string
+	}{
+		{name: "Gzip", acceptEncoding: "gzip, deflate, br", body: large, encoding: "gzip"},
+		{name: "Deflate", acceptEncoding: "deflate", body: large, encoding: "deflate"},
+		{name: "Preferred", acceptEncoding: "gzip;q=0.5, deflate", body: large, encoding: "deflate"},
+		{name: "Refused", acceptEncoding: "gzip;q=0", body: large},
+		{name: "Not accepted", body: large},
+		{name: "Small", acceptEncoding: "gzip", body: `{"status":"OK"}`},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			rr := serve(t, tc.acceptEncoding, jsonBody(tc.body))
+
+			require.Equal(t, http.StatusOK, rr.Code)
+			require.Equal(t, "Accept-Encoding", rr.Header().Get("Vary"))
+			require.Equal(t, tc.encoding, rr.Header().Get("Content-Encoding"))
+
+			var body io.Reader = rr.Body
+			switch tc.encoding {
+			case "gzip":
+				zr, err := gzip.NewReader(rr.Body)
+				require.NoError(t, err)
+				body = zr
+			case "deflate":
+				zr, err := zlib.NewReader(rr.Body)
+				require.NoError(t, err)
+				body = zr
+			}
+			got, err := io.ReadAll(body)
+			require.NoError(t, err)
+			require.Equal(t, tc.body, string(got))
+		})
+	}
+}
+
+func TestNew_OtherType(t *testing.T) {
+	rr := serve(t, "gzip", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "image/png")
+		w.WriteHeader(http.StatusCreated)
+		_, _ = w.Write(make([]byte, 500))
+	})
+
+	require.Equal(t, http.StatusCreated, rr.Code)
+	require.Empty(t, rr.Header().Get("Content-Encoding"))
+	require.Empty(t, rr.Header().Get("Vary"))
+	require.Len(t, rr.Body.Bytes(), 500)
+}
+
+func TestNew_Stream(t *testing.T) {
+	rr := serve(t, "gzip", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/csv; charset=utf-8")
+		_, _ = io.WriteString(w, "alias,url\n")
+		w.(http.Flusher).Flush()
+		_, _ = io.WriteString(w, "abc,https://example.com\n")
+	})
+
+	require.True(t, rr.Flushed)
+	require.Equal(t, "gzip", rr.Header().Get("Content-Encoding"))
+
+	zr, err := gzip.NewReader(rr.Body)
+	require.NoError(t, err)
+	got, err := io.ReadAll(zr)
+	require.NoError(t, err)
+	require.Equal(t, "alias,url\nabc,https://example.com\n", string(got))
+}
+
+func TestNew_InvalidLevel(t *testing.T) {
+	_, err := compress.New(compress.Options{GzipLevel: 10, DeflateLevel: 5})
+	require.Error(t, err)
+}
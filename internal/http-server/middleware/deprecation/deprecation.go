@@ -0,0 +1,26 @@
+package deprecation
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// New returns a middleware for routes that moved under prefix. Responses
+// keep coming from the old route but carry the Deprecation header
+// (RFC 9745) with since, the moment the route was deprecated, and a Link to
+// the same path under prefix as its successor version.
+func New(prefix string, since time.Time) func(next http.Handler) http.Handler {
+	deprecation := fmt.Sprintf("@%d", since.Unix())
+
+	return func(next http.Handler) http.Handler {
+		fn := func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Deprecation", deprecation)
+			w.Header().Add("Link", fmt.Sprintf("<%s%s>; rel=\"successor-version\"", prefix, r.URL.EscapedPath()))
+
+			next.ServeHTTP(w, r)
+		}
+
+		return http.HandlerFunc(fn)
+	}
+}
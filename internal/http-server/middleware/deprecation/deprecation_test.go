@@ -0,0 +1,27 @@
+package deprecation_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"url-shortener/internal/http-server/middleware/deprecation"
+)
+
+func TestNew(t *testing.T) {
+	since := time.Date(2026, time.October, 16, 0, 0, 0, 0, time.UTC)
+	handler := deprecation.New("/api/v1", since)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusCreated)
+	}))
+
+	req := httptest.NewRequest(http.MethodPost, "/url/a%20b/stats?days=7", nil)
+	rr := httptest.NewRecorder()
+	handler.ServeHTTP(rr, req)
+
+	require.Equal(t, http.StatusCreated, rr.Code)
+	require.Equal(t, "@1792108800", rr.Header().Get("Deprecation"))
+	require.Equal(t, `</api/v1/url/a%20b/stats>; rel="successor-version"`, rr.Header().Get("Link"))
+}
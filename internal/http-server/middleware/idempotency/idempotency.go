@@ -0,0 +1,174 @@
+package idempotency
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io"
+	"log/slog"
+	"net"
+	"net/http"
+	"strconv"
+	"time"
+	"url-shortener/internal/http-server/middleware/anonymous"
+	"url-shortener/internal/http-server/middleware/auth"
+	resp "url-shortener/internal/lib/api/response"
+	"url-shortener/internal/lib/logger/sl"
+	"url-shortener/internal/storage"
+
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/go-chi/render"
+)
+
+const (
+	// Header is the request header with the client's key.
+	Header = "Idempotency-Key"
+	// ReplayedHeader is set on responses repeated from an earlier request.
+	ReplayedHeader = "Idempotent-Replayed"
+
+	maxKeyLength = 255
+)
+
+//go:generate go run github.com/vektra/mockery/v2@latest --name=Store
+type Store interface {
+	ReserveIdempotencyKey(ctx context.Context, rec storage.IdempotencyRecord) (storage.IdempotencyRecord, error)
+	CompleteIdempotencyKey(ctx context.Context, key string, status int, contentType string, body string) error
+	ReleaseIdempotencyKey(ctx context.Context, key string) error
+}
+
+// New returns a middleware that handles a request with an Idempotency-Key
+// header once: retries with the same key get the first response, for ttl.
+// Keys are scoped to the user and API key, so it goes after the auth
+// middleware. Requests without the header pass through, and so do retries
+// of requests that failed with a 5xx status.
+func New(log *slog.Logger, store Store, ttl time.Duration) func(next http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		log := log.With(
+			slog.String("component", "middleware/idempotency"),
+		)
+
+		log.Info("idempotency middleware enabled", slog.Duration("ttl", ttl))
+
+		fn := func(w http.ResponseWriter, r *http.Request) {
+			clientKey := r.Header.Get(Header)
+			if clientKey == "" {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			log := log.With(slog.String("request_id", middleware.GetReqID(r.Context())))
+
+			if len(clientKey) > maxKeyLength {
+				render.Status(r, http.StatusBadRequest)
+				render.JSON(w, r, resp.Error("idempotency key is longer than "+strconv.Itoa(maxKeyLength)+" characters"))
+				return
+			}
+
+			body, err := io.ReadAll(r.Body)
+			if err != nil {
+				render.Status(r, http.StatusBadRequest)
+				render.JSON(w, r, resp.Error("failed to read request"))
+				return
+			}
+			r.Body = io.NopCloser(bytes.NewReader(body))
+
+			hash := requestHash(r, body)
+			now := time.Now()
+			rec, err := store.ReserveIdempotencyKey(r.Context(), storage.IdempotencyRecord{
+				Key:         scopedKey(r, clientKey),
+				RequestHash: hash,
+				CreatedAt:   now,
+				ExpiresAt:   now.Add(ttl),
+			})
+			if errors.Is(err, storage.ErrIdempotencyKeyExists) {
+				replay(w, r, rec, hash)
+				return
+			}
+			if err != nil {
+				log.Error("failed to reserve idempotency key", sl.Err(err))
+				render.Status(r, http.StatusInternalServerError)
+				render.JSON(w, r, resp.Error("internal error"))
+				return
+			}
+
+			var out bytes.Buffer
+			ww := middleware.NewWrapResponseWriter(w, r.ProtoMajor)
+			ww.Tee(&out)
+
+			next.ServeHTTP(ww, r)
+
+			// The response has been sent; the request may have been
+			// cancelled by now, but its outcome still has to be kept.
+			ctx := context.WithoutCancel(r.Context())
+			status := ww.Status()
+			if status == 0 {
+				// Nothing was written, which net/http sends as 200.
+				status = http.StatusOK
+			}
+			if status >= http.StatusInternalServerError {
+				err = store.ReleaseIdempotencyKey(ctx, rec.Key)
+			} else {
+				err = store.CompleteIdempotencyKey(ctx, rec.Key, status, ww.Header().Get("Content-Type"), out.String())
+			}
+			if err != nil {
+				log.Error("failed to store idempotency key", sl.Err(err))
+			}
+		}
+
+		return http.HandlerFunc(fn)
+	}
+}
+
+func replay(w http.ResponseWriter, r *http.Request, rec storage.IdempotencyRecord, hash string) {
+	switch {
+	case rec.Pending():
+		render.Status(r, http.StatusConflict)
+		render.JSON(w, r, resp.Error("request with this idempotency key is in progress"))
+	case rec.RequestHash != hash:
+		render.Status(r, http.StatusUnprocessableEntity)
+		render.JSON(w, r, resp.Error("idempotency key was used with a different request"))
+	default:
+		if rec.ContentType != "" {
+			w.Header().Set("Content-Type", rec.ContentType)
+		}
+		w.Header().Set(ReplayedHeader, "true")
+		w.WriteHeader(rec.Status)
+		_, _ = io.WriteString(w, rec.Body)
+	}
+}
+
+// scopedKey keeps the keys of different users and API keys apart, and
+// those of visitors without an account by client IP.
+func scopedKey(r *http.Request, clientKey string) string {
+	user, _ := auth.UserFromContext(r.Context())
+	keyID, _ := auth.APIKeyIDFromContext(r.Context())
+	if _, ok := anonymous.FromContext(r.Context()); ok {
+		host, _, err := net.SplitHostPort(r.RemoteAddr)
+		if err != nil {
+			host = r.RemoteAddr
+		}
+		user.Username = "ip:" + host
+	}
+
+	h := sha256.New()
+	h.Write([]byte(user.Username))
+	h.Write([]byte{0})
+	h.Write([]byte(strconv.FormatInt(keyID, 10)))
+	h.Write([]byte{0})
+	h.Write([]byte(clientKey))
+
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+func requestHash(r *http.Request, body []byte) string {
+	h := sha256.New()
+	h.Write([]byte(r.Method))
+	h.Write([]byte{0})
+	h.Write([]byte(r.URL.Path))
+	h.Write([]byte{0})
+	h.Write(body)
+
+	return hex.EncodeToString(h.Sum(nil))
+}
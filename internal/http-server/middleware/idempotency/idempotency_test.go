@@ -0,0 +1,137 @@
+package idempotency_test
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"url-shortener/internal/http-server/middleware/anonymous"
+	"url-shortener/internal/http-server/middleware/auth"
+	"url-shortener/internal/http-server/middleware/idempotency"
+	"url-shortener/internal/http-server/middleware/idempotency/mocks"
+	"url-shortener/internal/lib/logger/handlers/slogdiscard"
+	"url-shortener/internal/storage"
+	"url-shortener/internal/storage/memory"
+)
+
+func TestIdempotencyMiddleware(t *testing.T) {
+	calls := 0
+	status := http.StatusCreated
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		body, _ := io.ReadAll(r.Body)
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(status)
+		fmt.Fprintf(w, `{"call":%d,"body":%q}`, calls, body)
+	})
+	handler := idempotency.New(slogdiscard.NewDiscardLogger(), memory.New(), time.Hour)(next)
+
+	post := func(user, key, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/url", strings.NewReader(body))
+		if key != "" {
+			req.Header.Set(idempotency.Header, key)
+		}
+		req = req.WithContext(auth.WithUser(req.Context(), storage.User{Username: user}))
+		rr := httptest.NewRecorder()
+		handler.ServeHTTP(rr, req)
+		return rr
+	}
+
+	// Without a key every request is handled.
+	post("alice", "", "a")
+	post("alice", "", "a")
+	require.Equal(t, 2, calls)
+
+	first := post("alice", "k1", "a")
+	require.Equal(t, http.StatusCreated, first.Code)
+	require.Empty(t, first.Header().Get(idempotency.ReplayedHeader))
+
+	retry := post("alice", "k1", "a")
+	require.Equal(t, 3, calls)
+	require.Equal(t, http.StatusCreated, retry.Code)
+	require.Equal(t, "true", retry.Header().Get(idempotency.ReplayedHeader))
+	require.Equal(t, "application/json", retry.Header().Get("Content-Type"))
+	require.Equal(t, first.Body.String(), retry.Body.String())
+
+	mismatch := post("alice", "k1", "b")
+	require.Equal(t, http.StatusUnprocessableEntity, mismatch.Code)
+	require.Equal(t, 3, calls)
+
+	// Keys of different users do not collide.
+	require.Equal(t, http.StatusCreated, post("bob", "k1", "a").Code)
+	require.Equal(t, 4, calls)
+
+	// Server errors are not kept, so the request can be retried.
+	status = http.StatusInternalServerError
+	require.Equal(t, http.StatusInternalServerError, post("alice", "k2", "a").Code)
+	status = http.StatusCreated
+	require.Equal(t, http.StatusCreated, post("alice", "k2", "a").Code)
+	require.Equal(t, 6, calls)
+
+	require.Equal(t, http.StatusBadRequest, post("alice", strings.Repeat("k", 256), "a").Code)
+	require.Equal(t, 6, calls)
+
+	// Nor do those of visitors without an account from different IPs.
+	visit := func(ip string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/url", strings.NewReader("a"))
+		req.RemoteAddr = ip + ":1234"
+		req.Header.Set(idempotency.Header, "k3")
+		req = req.WithContext(anonymous.WithVisitor(req.Context(), anonymous.Visitor{LinkTTL: time.Hour}))
+		rr := httptest.NewRecorder()
+		handler.ServeHTTP(rr, req)
+		return rr
+	}
+	require.Empty(t, visit("192.0.2.1").Header().Get(idempotency.ReplayedHeader))
+	require.Empty(t, visit("192.0.2.2").Header().Get(idempotency.ReplayedHeader))
+	require.Equal(t, "true", visit("192.0.2.1").Header().Get(idempotency.ReplayedHeader))
+	require.Equal(t, 8, calls)
+}
+
+func TestIdempotencyMiddleware_StoreResults(t *testing.T) {
+	cases := []struct {
+		name     string
+		rec      storage.IdempotencyRecord
+		err      error
+		wantCode int
+	}{
+		{
+			name:     "In progress",
+			rec:      storage.IdempotencyRecord{Key: "k"},
+			err:      storage.ErrIdempotencyKeyExists,
+			wantCode: http.StatusConflict,
+		},
+		{
+			name:     "Reserve Error",
+			err:      errors.New("unexpected error"),
+			wantCode: http.StatusInternalServerError,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			storeMock := mocks.NewStore(t)
+			storeMock.On("ReserveIdempotencyKey", mock.Anything, mock.AnythingOfType("storage.IdempotencyRecord")).
+				Return(tc.rec, tc.err).Once()
+
+			next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				t.Fatal("request handled")
+			})
+			handler := idempotency.New(slogdiscard.NewDiscardLogger(), storeMock, time.Hour)(next)
+
+			req := httptest.NewRequest(http.MethodPost, "/url", strings.NewReader("{}"))
+			req.Header.Set(idempotency.Header, "k")
+			rr := httptest.NewRecorder()
+			handler.ServeHTTP(rr, req)
+
+			require.Equal(t, tc.wantCode, rr.Code)
+		})
+	}
+}
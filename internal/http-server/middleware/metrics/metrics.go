@@ -0,0 +1,53 @@
+package metrics
+
+import (
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/chi/v5/middleware"
+)
+
+// unmatchedRoute labels requests that did not match any route.
+const unmatchedRoute = "unmatched"
+
+//go:generate go run github.com/vektra/mockery/v2@latest --name=Recorder
+type Recorder interface {
+	ObserveRequest(method string, route string, status int, duration time.Duration)
+}
+
+// New returns a middleware that reports the latency and status of every
+// request, labelled with the chi route pattern.
+func New(log *slog.Logger, recorder Recorder) func(next http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		log := log.With(
+			slog.String("component", "middleware/metrics"),
+		)
+
+		log.Info("metrics middleware enabled")
+
+		fn := func(w http.ResponseWriter, r *http.Request) {
+			ww := middleware.NewWrapResponseWriter(w, r.ProtoMajor)
+
+			t1 := time.Now()
+			defer func() {
+				route := unmatchedRoute
+				if rctx := chi.RouteContext(r.Context()); rctx != nil && rctx.RoutePattern() != "" {
+					route = rctx.RoutePattern()
+				}
+
+				status := ww.Status()
+				if status == 0 {
+					status = http.StatusOK
+				}
+
+				recorder.ObserveRequest(r.Method, route, status, time.Since(t1))
+			}()
+
+			next.ServeHTTP(ww, r)
+		}
+
+		return http.HandlerFunc(fn)
+	}
+}
@@ -0,0 +1,68 @@
+package metrics_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"url-shortener/internal/http-server/middleware/metrics"
+	"url-shortener/internal/http-server/middleware/metrics/mocks"
+	"url-shortener/internal/lib/logger/handlers/slogdiscard"
+)
+
+func TestMetricsMiddleware(t *testing.T) {
+	cases := []struct {
+		name      string
+		path      string
+		wantRoute string
+		wantCode  int
+	}{
+		{
+			name:      "Redirect",
+			path:      "/abc",
+			wantRoute: "/{alias}",
+			wantCode:  http.StatusFound,
+		},
+		{
+			name:      "Nested route",
+			path:      "/url/abc/stats",
+			wantRoute: "/url/{alias}/stats",
+			wantCode:  http.StatusOK,
+		},
+		{
+			name:      "Unmatched",
+			path:      "/a/b/c/d",
+			wantRoute: "unmatched",
+			wantCode:  http.StatusNotFound,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			recorderMock := mocks.NewRecorder(t)
+			recorderMock.On("ObserveRequest", http.MethodGet, tc.wantRoute, tc.wantCode, mock.AnythingOfType("time.Duration")).
+				Return().Once()
+
+			r := chi.NewRouter()
+			r.Use(metrics.New(slogdiscard.NewDiscardLogger(), recorderMock))
+			r.Get("/{alias}", func(w http.ResponseWriter, r *http.Request) {
+				http.Redirect(w, r, "https://example.com", http.StatusFound)
+			})
+			r.Route("/url", func(r chi.Router) {
+				r.Get("/{alias}/stats", func(w http.ResponseWriter, r *http.Request) {
+					_, _ = w.Write([]byte("{}"))
+				})
+			})
+
+			req := httptest.NewRequest(http.MethodGet, tc.path, nil)
+			rr := httptest.NewRecorder()
+			r.ServeHTTP(rr, req)
+
+			require.Equal(t, tc.wantCode, rr.Code)
+		})
+	}
+}
@@ -0,0 +1,78 @@
+package ratelimit
+
+import (
+	"log/slog"
+	"math"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+	"url-shortener/internal/http-server/middleware/auth"
+	resp "url-shortener/internal/lib/api/response"
+
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/go-chi/render"
+)
+
+//go:generate go run github.com/vektra/mockery/v2@latest --name=Limiter
+type Limiter interface {
+	Allow(key string, now time.Time) (bool, time.Duration)
+}
+
+// New returns a middleware that answers 429 with Retry-After once a client
+// runs out of requests. Authenticated requests are limited per user, the
+// rest per client IP; mount it after the auth middleware to get per-user
+// limits.
+func New(log *slog.Logger, limiter Limiter) func(next http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		log := log.With(
+			slog.String("component", "middleware/ratelimit"),
+		)
+
+		log.Info("rate limit middleware enabled")
+
+		fn := func(w http.ResponseWriter, r *http.Request) {
+			key := clientKey(r)
+
+			ok, wait := limiter.Allow(key, time.Now())
+			if !ok {
+				log.Info("rate limit exceeded",
+					keyAttr(key),
+					slog.String("request_id", middleware.GetReqID(r.Context())),
+				)
+
+				w.Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(wait.Seconds()))))
+				render.Status(r, http.StatusTooManyRequests)
+				render.JSON(w, r, resp.Error("too many requests"))
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		}
+
+		return http.HandlerFunc(fn)
+	}
+}
+
+// keyAttr logs an address under client_ip, which the privacy mode
+// anonymizes.
+func keyAttr(key string) slog.Attr {
+	if ip, ok := strings.CutPrefix(key, "ip:"); ok {
+		return slog.String("client_ip", ip)
+	}
+	return slog.String("key", key)
+}
+
+func clientKey(r *http.Request) string {
+	if user, ok := auth.UserFromContext(r.Context()); ok {
+		return "user:" + user.Username
+	}
+
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		host = r.RemoteAddr
+	}
+
+	return "ip:" + host
+}
@@ -0,0 +1,74 @@
+package ratelimit_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"url-shortener/internal/http-server/middleware/auth"
+	"url-shortener/internal/http-server/middleware/ratelimit"
+	"url-shortener/internal/http-server/middleware/ratelimit/mocks"
+	"url-shortener/internal/lib/logger/handlers/slogdiscard"
+	"url-shortener/internal/storage"
+)
+
+func TestRateLimitMiddleware(t *testing.T) {
+	cases := []struct {
+		name           string
+		user           string
+		wantKey        string
+		allow          bool
+		wait           time.Duration
+		wantCode       int
+		wantRetryAfter string
+	}{
+		{
+			name:     "Allowed by ip",
+			wantKey:  "ip:192.0.2.1",
+			allow:    true,
+			wantCode: http.StatusOK,
+		},
+		{
+			name:     "Allowed by user",
+			user:     "alice",
+			wantKey:  "user:alice",
+			allow:    true,
+			wantCode: http.StatusOK,
+		},
+		{
+			name:           "Limited",
+			user:           "alice",
+			wantKey:        "user:alice",
+			wait:           1500 * time.Millisecond,
+			wantCode:       http.StatusTooManyRequests,
+			wantRetryAfter: "2",
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			limiterMock := mocks.NewLimiter(t)
+			limiterMock.On("Allow", tc.wantKey, mock.AnythingOfType("time.Time")).
+				Return(tc.allow, tc.wait).Once()
+
+			next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {})
+			handler := ratelimit.New(slogdiscard.NewDiscardLogger(), limiterMock)(next)
+
+			req := httptest.NewRequest(http.MethodPost, "/url", nil)
+			req.RemoteAddr = "192.0.2.1:1234"
+			if tc.user != "" {
+				req = req.WithContext(auth.WithUser(req.Context(), storage.User{Username: tc.user}))
+			}
+
+			rr := httptest.NewRecorder()
+			handler.ServeHTTP(rr, req)
+
+			require.Equal(t, tc.wantCode, rr.Code)
+			require.Equal(t, tc.wantRetryAfter, rr.Header().Get("Retry-After"))
+		})
+	}
+}
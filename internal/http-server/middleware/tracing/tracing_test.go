@@ -0,0 +1,56 @@
+package tracing_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+
+	"url-shortener/internal/http-server/middleware/tracing"
+	"url-shortener/internal/lib/logger/handlers/slogdiscard"
+	tracingSetup "url-shortener/internal/tracing"
+)
+
+func TestTracingMiddleware(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+
+	var handlerSpan trace.SpanContext
+
+	r := chi.NewRouter()
+	r.Use(tracing.New(slogdiscard.NewDiscardLogger(), provider, tracingSetup.Propagator()))
+	r.Get("/{alias}", func(w http.ResponseWriter, r *http.Request) {
+		handlerSpan = trace.SpanContextFromContext(r.Context())
+		http.Redirect(w, r, "https://example.com", http.StatusFound)
+	})
+	r.Get("/broken", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/abc", nil)
+	req.Header.Set("traceparent", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+	r.ServeHTTP(httptest.NewRecorder(), req)
+	r.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/broken", nil))
+
+	spans := recorder.Ended()
+	require.Len(t, spans, 2)
+
+	span := spans[0]
+	require.Equal(t, "GET /{alias}", span.Name())
+	require.Equal(t, trace.SpanKindServer, span.SpanKind())
+	require.Equal(t, "4bf92f3577b34da6a3ce929d0e0e4736", span.SpanContext().TraceID().String())
+	require.Equal(t, span.SpanContext().SpanID(), handlerSpan.SpanID())
+	require.Contains(t, span.Attributes(), attribute.String("http.route", "/{alias}"))
+	require.Contains(t, span.Attributes(), attribute.Int("http.response.status_code", http.StatusFound))
+	require.Equal(t, codes.Unset, span.Status().Code)
+
+	require.Equal(t, "GET /broken", spans[1].Name())
+	require.Equal(t, codes.Error, spans[1].Status().Code)
+}
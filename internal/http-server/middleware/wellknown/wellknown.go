@@ -0,0 +1,32 @@
+package wellknown
+
+import (
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// New returns a middleware that serves GET and HEAD requests for the
+// paths in handlers, such as /robots.txt, with their handler. It must come
+// before middleware.URLFormat, which would route /robots.txt as /robots,
+// that is as the alias "robots".
+func New(handlers map[string]http.Handler) func(next http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		fn := func(w http.ResponseWriter, r *http.Request) {
+			h, ok := handlers[r.URL.Path]
+			if !ok || (r.Method != http.MethodGet && r.Method != http.MethodHead) {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			// Metrics are labelled with the route pattern.
+			if rctx := chi.RouteContext(r.Context()); rctx != nil {
+				rctx.RoutePatterns = append(rctx.RoutePatterns, r.URL.Path)
+			}
+
+			h.ServeHTTP(w, r)
+		}
+
+		return http.HandlerFunc(fn)
+	}
+}
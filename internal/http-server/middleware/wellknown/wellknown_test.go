@@ -0,0 +1,56 @@
+package wellknown_test
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/stretchr/testify/require"
+
+	"url-shortener/internal/http-server/handlers/wellknown/favicon"
+	"url-shortener/internal/http-server/handlers/wellknown/robots"
+	"url-shortener/internal/http-server/middleware/wellknown"
+)
+
+func TestWellKnown(t *testing.T) {
+	r := chi.NewRouter()
+	r.Use(wellknown.New(map[string]http.Handler{
+		"/robots.txt":  robots.New("/api/v1"),
+		"/favicon.ico": favicon.New(),
+	}))
+	r.Use(middleware.URLFormat)
+	r.Get("/{alias}", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = io.WriteString(w, "alias "+chi.URLParam(r, "alias"))
+	})
+
+	cases := []struct {
+		name        string
+		path        string
+		contentType string
+		body        string
+	}{
+		{name: "robots.txt", path: "/robots.txt", contentType: "text/plain; charset=utf-8", body: "User-agent: *\nDisallow: /api/v1/\nDisallow: /admin/\n"},
+		{name: "favicon.ico", path: "/favicon.ico", contentType: "image/x-icon"},
+		// Aliases that only look like the well-known paths still redirect.
+		{name: "alias robots", path: "/robots", body: "alias robots"},
+		{name: "alias favicon", path: "/favicon.png", body: "alias favicon"},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			rr := httptest.NewRecorder()
+			r.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, tc.path, nil))
+
+			require.Equal(t, http.StatusOK, rr.Code)
+			if tc.contentType != "" {
+				require.Equal(t, tc.contentType, rr.Header().Get("Content-Type"))
+			}
+			if tc.body != "" {
+				require.Equal(t, tc.body, rr.Body.String())
+			}
+		})
+	}
+}
@@ -0,0 +1,610 @@
+// Package openapi describes the HTTP API as an OpenAPI 3 document. Request
+// and response schemas are generated from the handler types, so they follow
+// the handlers as they change.
+package openapi
+
+import (
+	"fmt"
+	"net/http"
+	"reflect"
+	"slices"
+	"strings"
+
+	auditList "url-shortener/internal/http-server/handlers/audit/list"
+	"url-shortener/internal/http-server/handlers/auth/login"
+	backupCreate "url-shortener/internal/http-server/handlers/backup/create"
+	backupList "url-shortener/internal/http-server/handlers/backup/list"
+	campaignsCreate "url-shortener/internal/http-server/handlers/campaigns/create"
+	campaignsGet "url-shortener/internal/http-server/handlers/campaigns/get"
+	campaignsList "url-shortener/internal/http-server/handlers/campaigns/list"
+	campaignsStats "url-shortener/internal/http-server/handlers/campaigns/stats"
+	keysCreate "url-shortener/internal/http-server/handlers/keys/create"
+	keysList "url-shortener/internal/http-server/handlers/keys/list"
+	"url-shortener/internal/http-server/handlers/quota/usage"
+	"url-shortener/internal/http-server/handlers/url/available"
+	"url-shortener/internal/http-server/handlers/url/batch"
+	"url-shortener/internal/http-server/handlers/url/block"
+	"url-shortener/internal/http-server/handlers/url/csvimport"
+	"url-shortener/internal/http-server/handlers/url/expand"
+	"url-shortener/internal/http-server/handlers/url/geo"
+	"url-shortener/internal/http-server/handlers/url/list"
+	"url-shortener/internal/http-server/handlers/url/redirect"
+	"url-shortener/internal/http-server/handlers/url/referrers"
+	"url-shortener/internal/http-server/handlers/url/rename"
+	"url-shortener/internal/http-server/handlers/url/save"
+	"url-shortener/internal/http-server/handlers/url/stats"
+	"url-shortener/internal/http-server/handlers/url/suggest"
+	"url-shortener/internal/http-server/handlers/url/update"
+	usersCreate "url-shortener/internal/http-server/handlers/users/create"
+	usersErase "url-shortener/internal/http-server/handlers/users/erase"
+	"url-shortener/internal/http-server/middleware/anonymous"
+	"url-shortener/internal/http-server/middleware/idempotency"
+	resp "url-shortener/internal/lib/api/response"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/getkin/kin-openapi/openapi3gen"
+)
+
+// Version of the API reported in the document.
+const Version = "1.0.0"
+
+// Prefix is where the JSON API is served. Redirects, probes and the
+// documentation stay at the root; a breaking change to the API gets a new
+// prefix.
+const Prefix = "/api/v1"
+
+// New returns the OpenAPI document of the HTTP API.
+func New() (*openapi3.T, error) {
+	const op = "http-server.openapi.New"
+
+	b := builder{
+		doc: &openapi3.T{
+			OpenAPI: "3.0.3",
+			Info: &openapi3.Info{
+				Title:   "url-shortener",
+				Version: Version,
+			},
+			Paths: openapi3.Paths{},
+			Components: openapi3.Components{
+				SecuritySchemes: openapi3.SecuritySchemes{
+					"bearerAuth": {Value: openapi3.NewJWTSecurityScheme()},
+					"basicAuth": {Value: openapi3.NewSecurityScheme().
+						WithType("http").WithScheme("basic")},
+					"apiKey": {Value: openapi3.NewSecurityScheme().
+						WithType("apiKey").WithIn("header").WithName("X-Api-Key")},
+				},
+			},
+			// Every endpoint needs authentication unless it says otherwise.
+			Security: openapi3.SecurityRequirements{
+				{"bearerAuth": {}},
+				{"apiKey": {}},
+				{"basicAuth": {}},
+			},
+		},
+	}
+
+	b.public(http.MethodPost, Prefix+"/auth/login", "Get a JWT for user credentials",
+		b.body(login.Request{}),
+		b.json(http.StatusOK, "Token", login.Response{}),
+		b.json(http.StatusBadRequest, "Invalid request", resp.Response{}),
+		b.json(http.StatusUnauthorized, "Invalid credentials", resp.Response{}),
+	)
+	b.public(http.MethodGet, Prefix+"/auth/oidc/login", "Sign in with the OpenID Connect provider, if one is configured",
+		b.query("redirect", "Path to return to after signing in, /admin/ by default", openapi3.NewStringSchema()),
+		b.found("Redirect to the provider's sign-in page"),
+		b.json(http.StatusBadRequest, "Redirect is not a local path", resp.Response{}),
+		b.json(http.StatusBadGateway, "Provider unavailable", resp.Response{}),
+	)
+	b.public(http.MethodGet, Prefix+"/auth/oidc/callback", "Finish signing in with the OpenID Connect provider",
+		b.query("code", "Authorization code from the provider", openapi3.NewStringSchema()),
+		b.query("state", "State of the login", openapi3.NewStringSchema()),
+		b.found("Redirect to the page the login was started for, with token, user, role and expires_at in the fragment"),
+		b.json(http.StatusBadRequest, "Login expired or state mismatch", resp.Response{}),
+		b.json(http.StatusUnauthorized, "Sign-in refused by the provider", resp.Response{}),
+		b.json(http.StatusForbidden, "User may not sign in", resp.Response{}),
+		b.json(http.StatusBadGateway, "Provider unavailable", resp.Response{}),
+	)
+
+	b.add(http.MethodPost, Prefix+"/url", "Create a short link",
+		b.body(save.Request{}),
+		b.form("url", "alias", "ttl", "domain"),
+		b.query("format", "txt answers with the bare short URL, same as Accept: text/plain", openapi3.NewStringSchema().WithEnum("txt")),
+		b.header(idempotency.Header, "Retries with the same key within idempotency.ttl get the first response, marked with Idempotent-Replayed: true",
+			openapi3.NewStringSchema().WithMaxLength(255)),
+		b.header(anonymous.TokenHeader, "Captcha token; required without credentials when anonymous.enabled is set, and such links expire after anonymous.link_ttl",
+			openapi3.NewStringSchema()),
+		b.json(http.StatusOK, "Created link, or an error in the error field", save.Response{}),
+		b.text(http.StatusOK),
+		b.content(http.StatusBadRequest, "Error message, for plain text requests", "text/plain"),
+		b.json(http.StatusForbidden, "Quota exceeded, quota holds the usage; or the user is a viewer; or the captcha was rejected", save.Response{}),
+		b.json(http.StatusConflict, "Request with this Idempotency-Key is in progress", resp.Response{}),
+		b.json(http.StatusUnprocessableEntity, "Idempotency-Key was used with a different request", resp.Response{}),
+		b.json(http.StatusTooManyRequests, "Rate limit exceeded", resp.Response{}),
+		b.json(http.StatusServiceUnavailable, "Captcha provider unavailable", resp.Response{}),
+	)
+	b.add(http.MethodGet, Prefix+"/shorten", "Create a short link from the query string, for clients that cannot send a body",
+		b.query("url", "URL to shorten", openapi3.NewStringSchema().WithFormat("uri")),
+		b.query("alias", "Alias of the link; generated if empty", openapi3.NewStringSchema()),
+		b.query("ttl", "Lifetime of the link, e.g. 24h", openapi3.NewStringSchema()),
+		b.query("domain", "Short domain of the link", openapi3.NewStringSchema()),
+		b.query("format", "txt answers with the bare short URL, same as Accept: text/plain", openapi3.NewStringSchema().WithEnum("txt")),
+		b.json(http.StatusOK, "Created link, or an error in the error field", save.Response{}),
+		b.text(http.StatusOK),
+		b.content(http.StatusBadRequest, "Error message, for plain text requests", "text/plain"),
+		b.json(http.StatusForbidden, "Quota exceeded, quota holds the usage; or the user is a viewer", save.Response{}),
+		b.json(http.StatusTooManyRequests, "Rate limit exceeded", resp.Response{}),
+	)
+	b.add(http.MethodPost, Prefix+"/url/batch", "Create up to 1000 short links at once",
+		b.body([]batch.Item{}),
+		b.json(http.StatusOK, "Result for every item in request order", batch.Response{}),
+		b.json(http.StatusBadRequest, "Invalid request", resp.Response{}),
+		b.json(http.StatusForbidden, "Quota exceeded, quota holds the usage; or the user is a viewer", batch.Response{}),
+		b.json(http.StatusTooManyRequests, "Rate limit exceeded", resp.Response{}),
+	)
+	b.add(http.MethodPatch, Prefix+"/url/{alias}", "Change the target of a link",
+		b.alias(),
+		b.body(update.Request{}),
+		b.json(http.StatusOK, "Updated link", update.Response{}),
+		b.json(http.StatusBadRequest, "Invalid request", resp.Response{}),
+		b.json(http.StatusForbidden, "Viewers cannot change links", resp.Response{}),
+		b.json(http.StatusNotFound, "Link not found", resp.Response{}),
+	)
+	b.add(http.MethodDelete, Prefix+"/url/{alias}", "Delete a link; it can be restored during the grace period",
+		b.alias(),
+		b.json(http.StatusOK, "Link deleted", resp.Response{}),
+		b.json(http.StatusForbidden, "Viewers cannot change links", resp.Response{}),
+		b.json(http.StatusNotFound, "Link not found", resp.Response{}),
+	)
+	b.add(http.MethodPost, Prefix+"/url/{alias}/rename", "Give a link a new alias",
+		b.alias(),
+		b.body(rename.Request{}),
+		b.json(http.StatusOK, "Renamed link", rename.Response{}),
+		b.json(http.StatusForbidden, "Viewers cannot change links", resp.Response{}),
+		b.json(http.StatusNotFound, "Link not found", resp.Response{}),
+		b.json(http.StatusConflict, "Alias already taken", resp.Response{}),
+	)
+	b.add(http.MethodPost, Prefix+"/url/{alias}/restore", "Restore a deleted link",
+		b.alias(),
+		b.json(http.StatusOK, "Link restored", resp.Response{}),
+		b.json(http.StatusForbidden, "Viewers cannot change links", resp.Response{}),
+		b.json(http.StatusNotFound, "Deleted link not found", resp.Response{}),
+	)
+	b.add(http.MethodPost, Prefix+"/url/{alias}/block", "Block a link after a legal takedown (admin only)",
+		b.alias(),
+		b.body(block.Request{}),
+		b.json(http.StatusOK, "Link blocked", resp.Response{}),
+		b.json(http.StatusForbidden, "Not an admin", resp.Response{}),
+		b.json(http.StatusNotFound, "Link not found", resp.Response{}),
+	)
+	b.add(http.MethodGet, Prefix+"/url/{alias}/stats", "Get click statistics of a link",
+		b.alias(),
+		b.query("days", "Number of days in the daily breakdown", openapi3.NewIntegerSchema()),
+		b.query("include_bots", "Count clicks of crawlers and scripts too", openapi3.NewBoolSchema()),
+		b.json(http.StatusOK, "Statistics", stats.Response{}),
+		b.json(http.StatusNotFound, "Link not found", resp.Response{}),
+	)
+	b.add(http.MethodGet, Prefix+"/url/{alias}/stats/export", "Download the clicks on a link as CSV",
+		b.alias(),
+		b.query("from", "Clicked at or after, RFC 3339 or YYYY-MM-DD", openapi3.NewStringSchema()),
+		b.query("to", "Clicked before, RFC 3339 or YYYY-MM-DD", openapi3.NewStringSchema()),
+		b.query("group", "Export clicks per day instead of single clicks", openapi3.NewStringSchema().WithEnum("day")),
+		b.query("include_bots", "Export clicks of crawlers and scripts too", openapi3.NewBoolSchema()),
+		b.content(http.StatusOK, "CSV with the columns clicked_at,referrer,user_agent,variant,country,city,bot, or date,clicks with group=day", "text/csv"),
+		b.json(http.StatusBadRequest, "Invalid request", resp.Response{}),
+		b.json(http.StatusNotFound, "Link not found", resp.Response{}),
+	)
+	b.add(http.MethodGet, Prefix+"/url/{alias}/referrers", "Get the top referring domains of a link",
+		b.alias(),
+		b.query("limit", "Number of domains, 1 to 100", openapi3.NewIntegerSchema().WithMin(1).WithMax(100)),
+		b.query("include_bots", "Count clicks of crawlers and scripts too", openapi3.NewBoolSchema()),
+		b.json(http.StatusOK, "Referring domains", referrers.Response{}),
+		b.json(http.StatusNotFound, "Link not found", resp.Response{}),
+	)
+	b.add(http.MethodGet, Prefix+"/url/{alias}/geo", "Get clicks on a link per country",
+		b.alias(),
+		b.query("include_bots", "Count clicks of crawlers and scripts too", openapi3.NewBoolSchema()),
+		b.json(http.StatusOK, "Clicks per country", geo.Response{}),
+		b.json(http.StatusNotFound, "Link not found", resp.Response{}),
+	)
+	b.add(http.MethodGet, Prefix+"/url/{alias}/events", "Stream clicks on a link live",
+		b.alias(),
+		b.query("include_bots", "Stream clicks of crawlers and scripts too", openapi3.NewBoolSchema()),
+		b.content(http.StatusOK, "Server-Sent Events named click, with the click as JSON data", "text/event-stream"),
+		b.json(http.StatusNotFound, "Link not found", resp.Response{}),
+	)
+	b.add(http.MethodGet, Prefix+"/url/{alias}/qr", "Get a QR code of a short link",
+		b.alias(),
+		b.query("size", "Size in pixels, 64 to 1024", openapi3.NewIntegerSchema().WithMin(64).WithMax(1024)),
+		b.query("format", "Image format", openapi3.NewStringSchema().WithEnum("png", "svg")),
+		b.content(http.StatusOK, "QR code", "image/png", "image/svg+xml"),
+		b.json(http.StatusNotFound, "Link not found", resp.Response{}),
+	)
+
+	b.add(http.MethodGet, Prefix+"/urls", "List links",
+		b.query("limit", "Page size, 1 to 100", openapi3.NewIntegerSchema().WithMin(1).WithMax(100)),
+		b.query("offset", "Number of links to skip", openapi3.NewIntegerSchema().WithMin(0)),
+		b.query("order", "Sort by creation time", openapi3.NewStringSchema().WithEnum("desc", "asc")),
+		b.query("url", "Part of the original URL, case-insensitive", openapi3.NewStringSchema()),
+		b.query("tag", "Tag of the links", openapi3.NewStringSchema()),
+		b.query("owner", "Owner of the links, admins only", openapi3.NewStringSchema()),
+		b.query("created_from", "Created at or after, RFC 3339 or YYYY-MM-DD", openapi3.NewStringSchema()),
+		b.query("created_to", "Created before, RFC 3339 or YYYY-MM-DD; a date includes the whole day", openapi3.NewStringSchema()),
+		b.query("health", "Outcome of the last dead-link check", openapi3.NewStringSchema().WithEnum("ok", "broken", "redirect_loop")),
+		b.query("campaign", "Id of the campaign of the links", openapi3.NewInt64Schema()),
+		b.json(http.StatusOK, "Page of links", list.Response{}),
+		b.json(http.StatusBadRequest, "Invalid request", resp.Response{}),
+		b.json(http.StatusForbidden, "Owner filter used by an editor", resp.Response{}),
+	)
+	b.add(http.MethodGet, Prefix+"/urls/export", "Download links as CSV",
+		b.content(http.StatusOK, "CSV with the columns alias,url,owner,created_at,expires_at", "text/csv"),
+	)
+	b.add(http.MethodGet, Prefix+"/urls/feed", "Get the newest public links as an RSS or Atom feed, if the feed is enabled; a public feed needs no credentials",
+		b.query("format", "Feed format", openapi3.NewStringSchema().WithEnum("rss", "atom")),
+		b.content(http.StatusOK, "RSS 2.0 or Atom feed of the newest public links", "application/rss+xml", "application/atom+xml"),
+		b.json(http.StatusBadRequest, "Invalid request", resp.Response{}),
+	)
+	b.add(http.MethodPost, Prefix+"/urls/import", "Upload links from a CSV file",
+		b.upload(),
+		b.json(http.StatusOK, "Result for every row", csvimport.Response{}),
+		b.json(http.StatusBadRequest, "Invalid file", resp.Response{}),
+		b.json(http.StatusForbidden, "Quota exceeded, quota holds the usage; or the user is a viewer", csvimport.Response{}),
+	)
+
+	b.add(http.MethodGet, Prefix+"/alias/{alias}/available", "Check whether an alias is free for a new link",
+		b.alias(),
+		b.json(http.StatusOK, "Availability; reason is taken or the broken alias rule", available.Response{}),
+	)
+	b.add(http.MethodGet, Prefix+"/suggest", "Suggest readable free aliases for a link",
+		b.query("url", "Destination of the link", openapi3.NewStringSchema().WithFormat("uri")),
+		b.query("count", "How many aliases to suggest, 5 by default", openapi3.NewIntegerSchema().WithMin(1).WithMax(20)),
+		b.json(http.StatusOK, "Free aliases made of the domain and title of the page and random words, the best first", suggest.Response{}),
+		b.json(http.StatusBadRequest, "Invalid url or count", resp.Response{}),
+	)
+
+	b.public(http.MethodGet, Prefix+"/expand/{alias}", "Resolve a short link without redirecting",
+		b.alias(),
+		b.json(http.StatusOK, "Destination and status of the link; url is omitted for protected links and links that no longer work", expand.Response{}),
+		b.json(http.StatusNotFound, "Link not found", resp.Response{}),
+	)
+
+	b.add(http.MethodGet, Prefix+"/quota", "Get the link quota of the user or API key",
+		b.json(http.StatusOK, "Usage and what remains; limits of 0 are unlimited", usage.Response{}),
+	)
+
+	b.add(http.MethodPost, Prefix+"/campaigns", "Create a campaign",
+		b.body(campaignsCreate.Request{}),
+		b.json(http.StatusCreated, "Created campaign", campaignsCreate.Response{}),
+		b.json(http.StatusBadRequest, "Invalid request", resp.Response{}),
+	)
+	b.add(http.MethodGet, Prefix+"/campaigns", "List campaigns",
+		b.json(http.StatusOK, "Campaigns", campaignsList.Response{}),
+	)
+	b.add(http.MethodGet, Prefix+"/campaigns/{id}", "Get a campaign",
+		b.path("id", openapi3.NewInt64Schema()),
+		b.json(http.StatusOK, "Campaign", campaignsGet.Response{}),
+		b.json(http.StatusNotFound, "Campaign not found", resp.Response{}),
+	)
+	b.add(http.MethodPut, Prefix+"/campaigns/{id}", "Replace the name and settings of a campaign; its links keep theirs",
+		b.path("id", openapi3.NewInt64Schema()),
+		b.body(campaignsCreate.Request{}),
+		b.json(http.StatusOK, "Campaign updated", resp.Response{}),
+		b.json(http.StatusBadRequest, "Invalid request", resp.Response{}),
+		b.json(http.StatusNotFound, "Campaign not found", resp.Response{}),
+	)
+	b.add(http.MethodDelete, Prefix+"/campaigns/{id}", "Delete a campaign; its links are kept",
+		b.path("id", openapi3.NewInt64Schema()),
+		b.json(http.StatusOK, "Campaign deleted", resp.Response{}),
+		b.json(http.StatusNotFound, "Campaign not found", resp.Response{}),
+	)
+	b.add(http.MethodGet, Prefix+"/campaigns/{id}/stats", "Get click statistics of all links of a campaign",
+		b.path("id", openapi3.NewInt64Schema()),
+		b.query("days", "Number of days in the daily breakdown", openapi3.NewIntegerSchema()),
+		b.query("include_bots", "Count clicks of crawlers and scripts too", openapi3.NewBoolSchema()),
+		b.json(http.StatusOK, "Statistics summed over the links, and the clicks of each link", campaignsStats.Response{}),
+		b.json(http.StatusNotFound, "Campaign not found", resp.Response{}),
+	)
+
+	b.add(http.MethodPost, Prefix+"/keys", "Create an API key",
+		b.body(keysCreate.Request{}),
+		b.json(http.StatusCreated, "Created key; the key itself is shown only once", keysCreate.Response{}),
+		b.json(http.StatusBadRequest, "Invalid request", resp.Response{}),
+	)
+	b.add(http.MethodGet, Prefix+"/keys", "List API keys",
+		b.json(http.StatusOK, "API keys", keysList.Response{}),
+	)
+	b.add(http.MethodDelete, Prefix+"/keys/{id}", "Revoke an API key",
+		b.path("id", openapi3.NewInt64Schema()),
+		b.json(http.StatusOK, "Key revoked", resp.Response{}),
+		b.json(http.StatusNotFound, "Key not found", resp.Response{}),
+	)
+
+	b.add(http.MethodGet, Prefix+"/events", "Stream clicks on all links live (admin only)",
+		b.query("include_bots", "Stream clicks of crawlers and scripts too", openapi3.NewBoolSchema()),
+		b.content(http.StatusOK, "Server-Sent Events named click, with the click as JSON data", "text/event-stream"),
+		b.json(http.StatusForbidden, "Not an admin", resp.Response{}),
+	)
+	b.add(http.MethodGet, Prefix+"/audit", "List the audit log, newest first (admin only)",
+		b.query("limit", "Page size, 1 to 500", openapi3.NewIntegerSchema().WithMin(1).WithMax(500)),
+		b.query("offset", "Number of entries to skip", openapi3.NewIntegerSchema().WithMin(0)),
+		b.query("action", "Action, e.g. link.update", openapi3.NewStringSchema()),
+		b.query("target", "Alias, API key id or username", openapi3.NewStringSchema()),
+		b.query("actor", "User who made the change", openapi3.NewStringSchema()),
+		b.json(http.StatusOK, "Page of entries", auditList.Response{}),
+		b.json(http.StatusBadRequest, "Invalid request", resp.Response{}),
+		b.json(http.StatusForbidden, "Not an admin", resp.Response{}),
+	)
+
+	b.add(http.MethodPost, Prefix+"/backups", "Take a snapshot of the SQLite database (admin only)",
+		b.json(http.StatusCreated, "Created snapshot", backupCreate.Response{}),
+		b.json(http.StatusForbidden, "Not an admin", resp.Response{}),
+	)
+	b.add(http.MethodGet, Prefix+"/backups", "List database snapshots, newest first (admin only)",
+		b.json(http.StatusOK, "Snapshots", backupList.Response{}),
+		b.json(http.StatusForbidden, "Not an admin", resp.Response{}),
+	)
+	b.add(http.MethodGet, Prefix+"/backups/{name}", "Download a database snapshot (admin only)",
+		b.path("name", openapi3.NewStringSchema()),
+		b.content(http.StatusOK, "SQLite database file", "application/vnd.sqlite3"),
+		b.json(http.StatusForbidden, "Not an admin", resp.Response{}),
+		b.json(http.StatusNotFound, "Snapshot not found", resp.Response{}),
+	)
+	b.add(http.MethodPost, Prefix+"/backups/{name}/restore", "Replace the database with a snapshot (admin only)",
+		b.path("name", openapi3.NewStringSchema()),
+		b.json(http.StatusOK, "Database restored; changes made after the snapshot are lost", resp.Response{}),
+		b.json(http.StatusForbidden, "Not an admin", resp.Response{}),
+		b.json(http.StatusNotFound, "Snapshot not found", resp.Response{}),
+	)
+
+	b.add(http.MethodPost, Prefix+"/users", "Create a user (admin only)",
+		b.body(usersCreate.Request{}),
+		b.json(http.StatusCreated, "Created user", usersCreate.Response{}),
+		b.json(http.StatusBadRequest, "Invalid request", resp.Response{}),
+		b.json(http.StatusForbidden, "Not an admin", resp.Response{}),
+		b.json(http.StatusConflict, "User already exists", resp.Response{}),
+	)
+	b.add(http.MethodDelete, Prefix+"/users/{id}/data", "Delete the links, clicks, API keys and campaigns of a user and anonymize their audit entries (admin only)",
+		b.path("id", openapi3.NewStringSchema()),
+		b.json(http.StatusOK, "Deletion report", usersErase.Response{}),
+		b.json(http.StatusForbidden, "Not an admin", resp.Response{}),
+		b.json(http.StatusNotFound, "No data for the user", resp.Response{}),
+	)
+
+	for _, method := range []string{http.MethodGet, http.MethodPost} {
+		b.public(method, "/{alias}", "Follow a short link",
+			b.alias(),
+			b.redirect(),
+			b.content(http.StatusOK, "Page with the Open Graph tags of a link with og, served to social network crawlers instead of the redirect", "text/html"),
+			b.json(http.StatusUnauthorized, "Password required or wrong", resp.Response{}),
+			b.json(http.StatusForbidden, "Link flagged as unsafe", resp.Response{}),
+			b.json(http.StatusNotFound, "Link not found, or saved on another short domain", resp.Response{}),
+			b.json(http.StatusGone, "Link expired or used up", resp.Response{}),
+			b.json(http.StatusUnavailableForLegalReasons, "Link blocked", redirect.LegalBlockResponse{}),
+		)
+	}
+
+	b.public(http.MethodGet, "/{alias}+", "Preview a short link, same as GET /{alias}?preview=1",
+		b.alias(),
+		b.content(http.StatusOK, "Page with the destination and a continue button", "text/html"),
+		b.json(http.StatusForbidden, "Link flagged as unsafe", resp.Response{}),
+		b.json(http.StatusNotFound, "Link not found", resp.Response{}),
+		b.json(http.StatusGone, "Link expired or used up", resp.Response{}),
+		b.json(http.StatusUnavailableForLegalReasons, "Link blocked", resp.Response{}),
+	)
+
+	b.public(http.MethodGet, "/healthz", "Liveness probe",
+		b.json(http.StatusOK, "Server is running", resp.Response{}),
+	)
+	b.public(http.MethodGet, "/readyz", "Readiness probe",
+		b.json(http.StatusOK, "Server is ready", resp.Response{}),
+		b.json(http.StatusServiceUnavailable, "Storage is unavailable or server is stopping", resp.Response{}),
+	)
+
+	if b.err != nil {
+		return nil, fmt.Errorf("%s: %w", op, b.err)
+	}
+
+	return b.doc, nil
+}
+
+// builder collects operations and keeps the first schema generation error
+// so New does not have to check every call.
+type builder struct {
+	doc *openapi3.T
+	err error
+}
+
+type option func(*openapi3.Operation)
+
+func (b *builder) add(method, path, summary string, opts ...option) {
+	operation := openapi3.NewOperation()
+	operation.Summary = summary
+	operation.Responses = openapi3.NewResponses()
+	// NewResponses adds a bare default response; ours are listed explicitly.
+	delete(operation.Responses, "default")
+
+	for _, opt := range opts {
+		opt(operation)
+	}
+
+	// Authenticated endpoints may also answer 401.
+	if operation.Security == nil {
+		operation.AddResponse(http.StatusUnauthorized, b.response("Not authenticated", resp.Response{}))
+	}
+
+	b.doc.AddOperation(path, method, operation)
+}
+
+func (b *builder) public(method, path, summary string, opts ...option) {
+	opts = append([]option{func(o *openapi3.Operation) {
+		o.Security = &openapi3.SecurityRequirements{}
+	}}, opts...)
+
+	b.add(method, path, summary, opts...)
+}
+
+func (b *builder) body(v any) option {
+	schema := b.schema(v)
+
+	return func(o *openapi3.Operation) {
+		o.RequestBody = &openapi3.RequestBodyRef{
+			Value: openapi3.NewRequestBody().WithRequired(true).WithJSONSchemaRef(schema),
+		}
+	}
+}
+
+// form lets an operation with a JSON body also take a form of string
+// fields, the first of them required.
+func (b *builder) form(fields ...string) option {
+	schema := openapi3.NewObjectSchema()
+	for _, field := range fields {
+		schema.WithProperty(field, openapi3.NewStringSchema())
+	}
+	schema.Required = fields[:1]
+
+	return func(o *openapi3.Operation) {
+		o.RequestBody.Value.Content["application/x-www-form-urlencoded"] = openapi3.NewMediaType().WithSchema(schema)
+	}
+}
+
+func (b *builder) upload() option {
+	file := openapi3.NewObjectSchema().
+		WithProperty("file", openapi3.NewStringSchema().WithFormat("binary"))
+	file.Required = []string{"file"}
+
+	return func(o *openapi3.Operation) {
+		o.RequestBody = &openapi3.RequestBodyRef{
+			Value: openapi3.NewRequestBody().WithRequired(true).
+				WithContent(openapi3.NewContentWithSchema(file, []string{"multipart/form-data"})),
+		}
+	}
+}
+
+func (b *builder) json(status int, description string, v any) option {
+	r := b.response(description, v)
+
+	return func(o *openapi3.Operation) {
+		o.AddResponse(status, r)
+	}
+}
+
+func (b *builder) content(status int, description string, contentTypes ...string) option {
+	schema := openapi3.NewStringSchema().WithFormat("binary")
+
+	return func(o *openapi3.Operation) {
+		o.AddResponse(status, openapi3.NewResponse().
+			WithDescription(description).
+			WithContent(openapi3.NewContentWithSchema(schema, contentTypes)))
+	}
+}
+
+// text lets a JSON response also come as a line of plain text.
+func (b *builder) text(status int) option {
+	return func(o *openapi3.Operation) {
+		o.Responses.Get(status).Value.Content["text/plain"] = openapi3.NewMediaType().WithSchema(openapi3.NewStringSchema())
+	}
+}
+
+// redirect documents every status a link can redirect with; which one is
+// used depends on its redirect_type and the server default.
+func (b *builder) redirect() option {
+	return func(o *openapi3.Operation) {
+		for _, status := range []int{
+			http.StatusMovedPermanently,
+			http.StatusFound,
+			http.StatusTemporaryRedirect,
+			http.StatusPermanentRedirect,
+		} {
+			r := openapi3.NewResponse().WithDescription("Redirect to the target URL")
+			r.Headers = openapi3.Headers{
+				"Location": {Value: &openapi3.Header{Parameter: openapi3.Parameter{
+					Schema: openapi3.NewStringSchema().WithFormat("uri").NewRef(),
+				}}},
+				"Cache-Control": {Value: &openapi3.Header{Parameter: openapi3.Parameter{
+					Description: "Sent for links with a cache lifetime of their own or by default",
+					Schema:      openapi3.NewStringSchema().NewRef(),
+				}}},
+			}
+			o.AddResponse(status, r)
+		}
+	}
+}
+
+// found documents a plain redirect to the Location header.
+func (b *builder) found(description string) option {
+	return func(o *openapi3.Operation) {
+		r := openapi3.NewResponse().WithDescription(description)
+		r.Headers = openapi3.Headers{
+			"Location": {Value: &openapi3.Header{Parameter: openapi3.Parameter{
+				Schema: openapi3.NewStringSchema().NewRef(),
+			}}},
+		}
+		o.AddResponse(http.StatusFound, r)
+	}
+}
+
+func (b *builder) alias() option {
+	return b.path("alias", openapi3.NewStringSchema())
+}
+
+func (b *builder) path(name string, schema *openapi3.Schema) option {
+	return func(o *openapi3.Operation) {
+		o.AddParameter(openapi3.NewPathParameter(name).WithSchema(schema))
+	}
+}
+
+func (b *builder) header(name, description string, schema *openapi3.Schema) option {
+	return func(o *openapi3.Operation) {
+		o.AddParameter(openapi3.NewHeaderParameter(name).WithDescription(description).WithSchema(schema))
+	}
+}
+
+func (b *builder) query(name, description string, schema *openapi3.Schema) option {
+	return func(o *openapi3.Operation) {
+		o.AddParameter(openapi3.NewQueryParameter(name).WithDescription(description).WithSchema(schema))
+	}
+}
+
+func (b *builder) response(description string, v any) *openapi3.Response {
+	return openapi3.NewResponse().WithDescription(description).WithJSONSchemaRef(b.schema(v))
+}
+
+// schema generates the JSON schema of v from its type. Validation tags are
+// carried over where OpenAPI has a counterpart.
+func (b *builder) schema(v any) *openapi3.SchemaRef {
+	ref, err := openapi3gen.NewSchemaRefForValue(v, nil, openapi3gen.SchemaCustomizer(customize))
+	if err != nil && b.err == nil {
+		b.err = fmt.Errorf("schema of %T: %w", v, err)
+	}
+
+	return ref
+}
+
+func customize(_ string, t reflect.Type, tag reflect.StructTag, schema *openapi3.Schema) error {
+	rules := fieldRules(tag)
+	if slices.Contains(rules, "url") {
+		schema.Format = "uri"
+	}
+
+	if t.Kind() != reflect.Struct {
+		return nil
+	}
+
+	for _, field := range reflect.VisibleFields(t) {
+		name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+		if name == "" || name == "-" {
+			continue
+		}
+		if slices.Contains(fieldRules(field.Tag), "required") {
+			schema.Required = append(schema.Required, name)
+		}
+	}
+
+	return nil
+}
+
+// fieldRules returns the validation rules of the field itself; rules after
+// "dive" apply to the elements of a slice or map.
+func fieldRules(tag reflect.StructTag) []string {
+	rules, _, _ := strings.Cut(tag.Get("validate"), ",dive")
+	return strings.Split(rules, ",")
+}
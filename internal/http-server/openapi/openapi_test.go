@@ -0,0 +1,62 @@
+package openapi_test
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"url-shortener/internal/http-server/openapi"
+)
+
+func TestNew(t *testing.T) {
+	doc, err := openapi.New()
+	require.NoError(t, err)
+	require.NoError(t, doc.Validate(context.Background()))
+
+	for path, methods := range map[string][]string{
+		"/api/v1/url":                      {http.MethodPost},
+		"/api/v1/auth/oidc/login":          {http.MethodGet},
+		"/api/v1/auth/oidc/callback":       {http.MethodGet},
+		"/api/v1/shorten":                  {http.MethodGet},
+		"/api/v1/url/{alias}":              {http.MethodPatch, http.MethodDelete},
+		"/api/v1/url/{alias}/stats":        {http.MethodGet},
+		"/api/v1/url/{alias}/referrers":    {http.MethodGet},
+		"/api/v1/url/{alias}/stats/export": {http.MethodGet},
+		"/api/v1/url/{alias}/events":       {http.MethodGet},
+		"/api/v1/events":                   {http.MethodGet},
+		"/api/v1/url/{alias}/geo":          {http.MethodGet},
+		"/api/v1/alias/{alias}/available":  {http.MethodGet},
+		"/api/v1/suggest":                  {http.MethodGet},
+		"/api/v1/urls/feed":                {http.MethodGet},
+		"/api/v1/expand/{alias}":           {http.MethodGet},
+		"/api/v1/campaigns":                {http.MethodPost, http.MethodGet},
+		"/api/v1/campaigns/{id}":           {http.MethodGet, http.MethodPut, http.MethodDelete},
+		"/api/v1/campaigns/{id}/stats":     {http.MethodGet},
+		"/{alias}":                         {http.MethodGet, http.MethodPost},
+	} {
+		for _, method := range methods {
+			require.NotNil(t, doc.Paths.Find(path).GetOperation(method), "%s %s", method, path)
+		}
+	}
+
+	save := doc.Paths.Find("/api/v1/url").Post
+	request := save.RequestBody.Value.Content.Get("application/json").Schema.Value
+	require.Equal(t, []string{"url"}, request.Required)
+	require.Equal(t, "uri", request.Properties["url"].Value.Format)
+	require.Equal(t, "date-time", request.Properties["expires_at"].Value.Format)
+
+	// resp.Response is embedded, so status sits next to the handler fields.
+	response := save.Responses.Get(http.StatusOK).Value.Content.Get("application/json").Schema.Value
+	require.Contains(t, response.Properties, "status")
+	require.Contains(t, response.Properties, "alias")
+	require.NotNil(t, save.Responses.Get(http.StatusUnauthorized))
+	require.NotNil(t, save.Responses.Get(http.StatusOK).Value.Content.Get("text/plain"))
+	require.NotNil(t, save.RequestBody.Value.Content.Get("application/x-www-form-urlencoded"))
+
+	redirect := doc.Paths.Find("/{alias}").Get
+	require.NotNil(t, redirect.Security)
+	require.Empty(t, *redirect.Security)
+	require.Nil(t, doc.Paths.Find("/healthz").Get.Responses.Get(http.StatusUnauthorized))
+}
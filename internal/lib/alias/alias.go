@@ -0,0 +1,131 @@
+// Package alias validates aliases chosen by users.
+package alias
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+)
+
+// Rules reported in ValidationError.
+const (
+	RuleReserved  = "reserved"
+	RuleMinLength = "min_length"
+	RuleMaxLength = "max_length"
+	RulePattern   = "pattern"
+)
+
+// Routes are the first path segments of the HTTP API. An alias equal to one
+// of them could never be followed, so they belong to every reserved list.
+var Routes = []string{
+	"api", "url", "urls", "auth", "keys", "users", "quota", "audit", "backups",
+	"metrics", "healthz", "readyz", "openapi", "docs", "admin", "alias", "events", "expand",
+	"suggest", "campaigns",
+}
+
+type Rules struct {
+	// MinLength and MaxLength count characters; 0 means no limit.
+	MinLength int
+	MaxLength int
+	// Pattern every alias must match in full; empty allows any characters.
+	Pattern string
+	// Reserved words are compared case-insensitively.
+	Reserved []string
+	// Signer, if set, signs the aliases users choose; see Sign.
+	Signer *Signer
+}
+
+// ValidationError tells which rule an alias broke.
+type ValidationError struct {
+	Rule    string
+	Message string
+}
+
+func (e *ValidationError) Error() string {
+	return e.Message
+}
+
+type Validator struct {
+	rules    Rules
+	pattern  *regexp.Regexp
+	reserved map[string]struct{}
+}
+
+// New returns a validator enforcing rules.
+func New(rules Rules) (*Validator, error) {
+	const op = "lib.alias.New"
+
+	if rules.MaxLength > 0 && rules.MinLength > rules.MaxLength {
+		return nil, fmt.Errorf("%s: min length %d is greater than max length %d", op, rules.MinLength, rules.MaxLength)
+	}
+
+	v := &Validator{
+		rules:    rules,
+		reserved: make(map[string]struct{}, len(rules.Reserved)),
+	}
+
+	if rules.Pattern != "" {
+		// Anchor the pattern so it has to match the whole alias.
+		pattern, err := regexp.Compile(`^(?:` + rules.Pattern + `)$`)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", op, err)
+		}
+		v.pattern = pattern
+	}
+
+	for _, word := range rules.Reserved {
+		v.reserved[strings.ToLower(word)] = struct{}{}
+	}
+
+	return v, nil
+}
+
+// Validate returns a *ValidationError if alias breaks any of the rules.
+func (v *Validator) Validate(alias string) error {
+	length := utf8.RuneCountInString(alias)
+
+	switch {
+	case v.rules.MinLength > 0 && length < v.rules.MinLength:
+		return &ValidationError{
+			Rule:    RuleMinLength,
+			Message: fmt.Sprintf("field alias must be at least %d characters long", v.rules.MinLength),
+		}
+	case v.rules.MaxLength > 0 && length > v.rules.MaxLength:
+		return &ValidationError{
+			Rule:    RuleMaxLength,
+			Message: fmt.Sprintf("field alias must be at most %d characters long", v.rules.MaxLength),
+		}
+	case v.pattern != nil && !v.pattern.MatchString(alias):
+		return &ValidationError{
+			Rule:    RulePattern,
+			Message: "field alias contains invalid characters",
+		}
+	}
+
+	if v.Reserved(alias) {
+		return &ValidationError{
+			Rule:    RuleReserved,
+			Message: "field alias is reserved",
+		}
+	}
+
+	return nil
+}
+
+// Sign returns the alias a link chosen as alias is saved under: alias with
+// a signature if aliases are signed, else alias itself. Validate checks
+// alias before it is signed.
+func (v *Validator) Sign(alias string) string {
+	if v.rules.Signer == nil || alias == "" {
+		return alias
+	}
+
+	return v.rules.Signer.Sign(alias)
+}
+
+// Reserved reports whether alias is one of the reserved words.
+func (v *Validator) Reserved(alias string) bool {
+	_, ok := v.reserved[strings.ToLower(alias)]
+	return ok
+}
@@ -0,0 +1,86 @@
+package alias_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"url-shortener/internal/lib/alias"
+)
+
+func TestValidator(t *testing.T) {
+	v, err := alias.New(alias.Rules{
+		MinLength: 3,
+		MaxLength: 8,
+		Pattern:   `[a-z0-9-]+`,
+		Reserved:  []string{"admin", "metrics"},
+	})
+	require.NoError(t, err)
+
+	cases := []struct {
+		alias string
+		rule  string
+	}{
+		{alias: "my-link"},
+		{alias: "ab", rule: alias.RuleMinLength},
+		{alias: "much-too-long", rule: alias.RuleMaxLength},
+		{alias: "ok/../x", rule: alias.RulePattern},
+		{alias: "MyLink", rule: alias.RulePattern},
+		{alias: "admin", rule: alias.RuleReserved},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.alias, func(t *testing.T) {
+			err := v.Validate(tc.alias)
+			if tc.rule == "" {
+				require.NoError(t, err)
+				return
+			}
+
+			var validationErr *alias.ValidationError
+			require.True(t, errors.As(err, &validationErr))
+			require.Equal(t, tc.rule, validationErr.Rule)
+		})
+	}
+}
+
+func TestValidator_ReservedIgnoresCase(t *testing.T) {
+	v, err := alias.New(alias.Rules{Reserved: []string{"Metrics"}})
+	require.NoError(t, err)
+
+	require.Error(t, v.Validate("METRICS"))
+	require.NoError(t, v.Validate("x"))
+}
+
+func TestValidator_ReservedRoutes(t *testing.T) {
+	v, err := alias.New(alias.Rules{Reserved: alias.Routes})
+	require.NoError(t, err)
+
+	for _, route := range []string{"url", "urls", "events", "expand", "suggest", "campaigns"} {
+		var validationErr *alias.ValidationError
+		require.True(t, errors.As(v.Validate(route), &validationErr), route)
+		require.Equal(t, alias.RuleReserved, validationErr.Rule)
+	}
+}
+
+func TestValidator_Sign(t *testing.T) {
+	v, err := alias.New(alias.Rules{})
+	require.NoError(t, err)
+	require.Equal(t, "promo", v.Sign("promo"))
+
+	signer, err := alias.NewSigner([]byte("0123456789abcdef"), 6, false)
+	require.NoError(t, err)
+	v, err = alias.New(alias.Rules{Signer: signer})
+	require.NoError(t, err)
+	require.Equal(t, signer.Sign("promo"), v.Sign("promo"))
+	require.Empty(t, v.Sign(""))
+}
+
+func TestNew_InvalidRules(t *testing.T) {
+	_, err := alias.New(alias.Rules{Pattern: "["})
+	require.Error(t, err)
+
+	_, err = alias.New(alias.Rules{MinLength: 10, MaxLength: 5})
+	require.Error(t, err)
+}
@@ -0,0 +1,79 @@
+package alias
+
+import (
+	_ "embed"
+	"strings"
+)
+
+//go:embed badwords.txt
+var badWords string
+
+// confusables are characters mistaken for each other (0, O and o; 1, l
+// and I) and pairs that read as one letter (rn as m, vv as w, cl as d).
+var confusables = []string{"0", "O", "o", "1", "l", "I", "rn", "vv", "cl"}
+
+// leet reads digits as the letters they stand for in words like f4ck.
+var leet = strings.NewReplacer("0", "o", "1", "i", "3", "e", "4", "a", "5", "s", "7", "t", "8", "b", "9", "g")
+
+// FilterRules set which generated aliases are thrown away and made anew.
+type FilterRules struct {
+	// BadWords turns on the built-in list of bad words; ExtraWords are
+	// rejected as well.
+	BadWords   bool
+	ExtraWords []string
+	// Confusable rejects aliases that are easy to misread or mishear, such
+	// as those with O and 0 or rn, which looks like m.
+	Confusable bool
+}
+
+// Filter tells generated aliases that are unfit to print or read aloud.
+type Filter struct {
+	words      []string
+	confusable bool
+}
+
+// NewFilter returns a filter following rules.
+func NewFilter(rules FilterRules) *Filter {
+	f := &Filter{confusable: rules.Confusable}
+
+	if rules.BadWords {
+		for _, line := range strings.Split(badWords, "\n") {
+			if line = strings.TrimSpace(line); line != "" && !strings.HasPrefix(line, "#") {
+				f.words = append(f.words, line)
+			}
+		}
+	}
+	for _, w := range rules.ExtraWords {
+		if w = strings.ToLower(strings.TrimSpace(w)); w != "" {
+			f.words = append(f.words, w)
+		}
+	}
+
+	return f
+}
+
+// Reject reports whether alias contains a bad word, ignoring case and
+// digits standing for letters, or, with Confusable, a confusable
+// character or pair.
+func (f *Filter) Reject(alias string) bool {
+	if f.confusable {
+		for _, c := range confusables {
+			if strings.Contains(alias, c) {
+				return true
+			}
+		}
+	}
+
+	// 1 stands for l as often as for i.
+	lower := strings.ToLower(alias)
+	spellings := []string{lower, leet.Replace(lower), leet.Replace(strings.ReplaceAll(lower, "1", "l"))}
+	for _, w := range f.words {
+		for _, s := range spellings {
+			if strings.Contains(s, w) {
+				return true
+			}
+		}
+	}
+
+	return false
+}
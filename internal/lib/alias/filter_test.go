@@ -0,0 +1,42 @@
+package alias_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"url-shortener/internal/lib/alias"
+)
+
+func TestFilter(t *testing.T) {
+	f := alias.NewFilter(alias.FilterRules{BadWords: true, ExtraWords: []string{" Acme "}, Confusable: true})
+
+	cases := []struct {
+		alias  string
+		reject bool
+	}{
+		{alias: "Xk7bQz", reject: false},
+		{alias: "aShitq", reject: true},
+		{alias: "Sh1tXY", reject: true},
+		{alias: "pr1ckZ", reject: true},
+		{alias: "fvckAC", reject: false},
+		{alias: "zACMEz", reject: true},
+		{alias: "ab0cde", reject: true},
+		{alias: "abOcde", reject: true},
+		{alias: "abIcde", reject: true},
+		{alias: "turnXY", reject: true},
+		{alias: "LpTXYZ", reject: false},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.alias, func(t *testing.T) {
+			require.Equal(t, tc.reject, f.Reject(tc.alias))
+		})
+	}
+}
+
+func TestFilter_Off(t *testing.T) {
+	f := alias.NewFilter(alias.FilterRules{})
+
+	require.False(t, f.Reject("shit0O"))
+}
@@ -0,0 +1,182 @@
+package alias
+
+import (
+	"context"
+	"fmt"
+	"unicode/utf8"
+	"url-shortener/internal/lib/random"
+)
+
+// Strategies of generating aliases.
+const (
+	StrategyRandom     = "random"
+	StrategySequential = "sequential"
+)
+
+type Generator interface {
+	Generate(ctx context.Context) (string, error)
+}
+
+// NewGenerator returns the generator for strategy. Random aliases are
+// length characters long; sequential ones grow with the number of links.
+// Aliases rejected by filter are skipped; filter may be nil.
+func NewGenerator(strategy string, length int, alphabet string, ids IDSource, validator *Validator, filter *Filter) (Generator, error) {
+	var (
+		g   Generator
+		err error
+	)
+	switch strategy {
+	case StrategyRandom:
+		g, err = NewRandom(length, alphabet)
+	case StrategySequential:
+		g, err = NewSequential(ids, alphabet, validator)
+	default:
+		return nil, fmt.Errorf("lib.alias.NewGenerator: unknown strategy %q", strategy)
+	}
+	if err != nil || filter == nil {
+		return g, err
+	}
+
+	return NewFiltered(g, filter), nil
+}
+
+// maxFiltered bounds the aliases Filtered throws away in a row, for
+// alphabets the filter rejects almost entirely.
+const maxFiltered = 100
+
+// Filtered makes aliases with another generator and skips those its filter
+// rejects.
+type Filtered struct {
+	next   Generator
+	filter *Filter
+}
+
+// NewFiltered returns a generator of the aliases of next that filter lets
+// through.
+func NewFiltered(next Generator, filter *Filter) *Filtered {
+	return &Filtered{next: next, filter: filter}
+}
+
+func (g *Filtered) Generate(ctx context.Context) (string, error) {
+	const op = "lib.alias.Filtered.Generate"
+
+	for range maxFiltered {
+		alias, err := g.next.Generate(ctx)
+		if err != nil {
+			return "", fmt.Errorf("%s: %w", op, err)
+		}
+		if !g.filter.Reject(alias) {
+			return alias, nil
+		}
+	}
+
+	return "", fmt.Errorf("%s: every one of %d aliases was filtered out", op, maxFiltered)
+}
+
+// Random makes random aliases for links saved without one. They may
+// collide with existing aliases, so callers retry on storage.ErrUrlExists.
+type Random struct {
+	length   int
+	alphabet string
+}
+
+// NewRandom returns a generator of aliases of length characters picked
+// from alphabet.
+func NewRandom(length int, alphabet string) (*Random, error) {
+	const op = "lib.alias.NewRandom"
+
+	if length <= 0 {
+		return nil, fmt.Errorf("%s: length must be positive", op)
+	}
+	if err := checkAlphabet(alphabet); err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return &Random{length: length, alphabet: alphabet}, nil
+}
+
+// DefaultRandom returns 6-character aliases of Latin letters and digits.
+func DefaultRandom() *Random {
+	return &Random{length: 6, alphabet: random.DefaultAlphabet}
+}
+
+func (g *Random) Generate(context.Context) (string, error) {
+	return random.NewString(g.length, g.alphabet), nil
+}
+
+type IDSource interface {
+	NextURLID(ctx context.Context) (int64, error)
+}
+
+// Sequential encodes numbers drawn from a storage sequence in the base of
+// its alphabet, which is Base62 for the default one. Every number is used
+// once, so aliases only collide with custom aliases that happen to look the
+// same. The aliases are short but reveal how many links were created.
+type Sequential struct {
+	ids      IDSource
+	alphabet []rune
+	reserved func(alias string) bool
+}
+
+// NewSequential returns a generator of sequential aliases. Numbers whose
+// encoding is reserved according to validator are skipped.
+func NewSequential(ids IDSource, alphabet string, validator *Validator) (*Sequential, error) {
+	const op = "lib.alias.NewSequential"
+
+	if err := checkAlphabet(alphabet); err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return &Sequential{ids: ids, alphabet: []rune(alphabet), reserved: validator.Reserved}, nil
+}
+
+func (g *Sequential) Generate(ctx context.Context) (string, error) {
+	const op = "lib.alias.Sequential.Generate"
+
+	for {
+		id, err := g.ids.NextURLID(ctx)
+		if err != nil {
+			return "", fmt.Errorf("%s: %w", op, err)
+		}
+
+		alias := Encode(id, g.alphabet)
+		if !g.reserved(alias) {
+			return alias, nil
+		}
+	}
+}
+
+// Encode writes n in the positional system whose digits are alphabet.
+func Encode(n int64, alphabet []rune) string {
+	base := int64(len(alphabet))
+	if n == 0 {
+		return string(alphabet[0])
+	}
+
+	var digits []rune
+	for ; n > 0; n /= base {
+		digits = append(digits, alphabet[n%base])
+	}
+	for i, j := 0, len(digits)-1; i < j; i, j = i+1, j-1 {
+		digits[i], digits[j] = digits[j], digits[i]
+	}
+
+	return string(digits)
+}
+
+// checkAlphabet requires unique characters so none of them is more likely
+// than the others and every number has a single encoding.
+func checkAlphabet(alphabet string) error {
+	seen := make(map[rune]struct{}, len(alphabet))
+	for _, c := range alphabet {
+		if _, ok := seen[c]; ok {
+			return fmt.Errorf("duplicate character %q in alphabet", c)
+		}
+		seen[c] = struct{}{}
+	}
+	if utf8.RuneCountInString(alphabet) < 2 {
+		return fmt.Errorf("alphabet must have at least 2 characters")
+	}
+
+	return nil
+}
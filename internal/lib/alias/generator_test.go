@@ -0,0 +1,96 @@
+package alias_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"unicode/utf8"
+
+	"github.com/stretchr/testify/require"
+
+	"url-shortener/internal/lib/alias"
+	"url-shortener/internal/lib/random"
+)
+
+func TestRandom(t *testing.T) {
+	const alphabet = "abcdefghjkmnpqrstuvwxyz23456789"
+
+	g, err := alias.NewRandom(8, alphabet)
+	require.NoError(t, err)
+
+	for range 100 {
+		a, err := g.Generate(context.Background())
+		require.NoError(t, err)
+		require.Equal(t, 8, utf8.RuneCountInString(a))
+		for _, c := range a {
+			require.True(t, strings.ContainsRune(alphabet, c), "unexpected %q in %q", c, a)
+		}
+	}
+}
+
+func TestNewRandom_InvalidOptions(t *testing.T) {
+	_, err := alias.NewRandom(0, "abc")
+	require.Error(t, err)
+
+	_, err = alias.NewRandom(6, "a")
+	require.Error(t, err)
+
+	_, err = alias.NewRandom(6, "abca")
+	require.Error(t, err)
+}
+
+func TestEncode(t *testing.T) {
+	base62 := []rune("0123456789abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ")
+
+	require.Equal(t, "0", alias.Encode(0, base62))
+	require.Equal(t, "Z", alias.Encode(61, base62))
+	require.Equal(t, "10", alias.Encode(62, base62))
+	require.Equal(t, "g8", alias.Encode(1000, base62))
+	require.Equal(t, "101", alias.Encode(5, []rune("01")))
+}
+
+// counter hands out 1, 2, 3...
+type counter struct{ n int64 }
+
+func (c *counter) NextURLID(context.Context) (int64, error) {
+	c.n++
+	return c.n, nil
+}
+
+func TestSequential(t *testing.T) {
+	validator, err := alias.New(alias.Rules{Reserved: []string{"C"}})
+	require.NoError(t, err)
+
+	g, err := alias.NewSequential(&counter{}, random.DefaultAlphabet, validator)
+	require.NoError(t, err)
+
+	var got []string
+	for range 3 {
+		a, err := g.Generate(context.Background())
+		require.NoError(t, err)
+		got = append(got, a)
+	}
+
+	// 2 encodes to the reserved "C" (case-insensitively) and is skipped.
+	require.Equal(t, []string{"B", "D", "E"}, got)
+}
+
+func TestFiltered(t *testing.T) {
+	validator, err := alias.New(alias.Rules{})
+	require.NoError(t, err)
+
+	g, err := alias.NewGenerator(alias.StrategySequential, 0, "0123456789", &counter{}, validator, alias.NewFilter(alias.FilterRules{Confusable: true}))
+	require.NoError(t, err)
+
+	// 1 looks like l and is skipped.
+	a, err := g.Generate(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, "2", a)
+
+	// Every alias of an alphabet of confusable characters is rejected.
+	g, err = alias.NewGenerator(alias.StrategyRandom, 6, "0O1l", nil, nil, alias.NewFilter(alias.FilterRules{Confusable: true}))
+	require.NoError(t, err)
+
+	_, err = g.Generate(context.Background())
+	require.Error(t, err)
+}
@@ -0,0 +1,113 @@
+package alias
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"fmt"
+	"strings"
+)
+
+// SignatureAlphabet is what signatures are written in: lower-case letters
+// and digits, so they survive case-insensitive aliases.
+const SignatureAlphabet = "0123456789abcdefghijklmnopqrstuvwxyz"
+
+// SignatureSeparator joins an alias and its signature.
+const SignatureSeparator = "-"
+
+// Minimum and maximum signature lengths; every character adds a little
+// over five bits.
+const (
+	MinSignatureLength = 4
+	MaxSignatureLength = 16
+)
+
+// Signer appends a short HMAC signature to aliases, e.g. promo becomes
+// promo-k3x9q, so that aliases nobody issued can be told apart without a
+// lookup. Character i of the signature of alias a is
+// SignatureAlphabet[HMAC-SHA256(key, a)[i] % 36], which an edge worker can
+// recompute with nothing but the key.
+type Signer struct {
+	key      []byte
+	length   int
+	foldCase bool
+}
+
+// NewSigner returns a Signer with signatures of length characters. With
+// foldCase, aliases are signed in lower case, for services whose aliases
+// are case-insensitive.
+func NewSigner(key []byte, length int, foldCase bool) (*Signer, error) {
+	const op = "lib.alias.NewSigner"
+
+	if len(key) == 0 {
+		return nil, fmt.Errorf("%s: empty key", op)
+	}
+	if length < MinSignatureLength || length > MaxSignatureLength {
+		return nil, fmt.Errorf("%s: length must be %d to %d, got %d", op, MinSignatureLength, MaxSignatureLength, length)
+	}
+
+	return &Signer{key: key, length: length, foldCase: foldCase}, nil
+}
+
+// Sign returns alias with its signature. Aliases that are already signed
+// are returned as they are, so links exported from this service can be
+// imported again.
+func (s *Signer) Sign(alias string) string {
+	if s.Verify(alias) {
+		return alias
+	}
+
+	return alias + SignatureSeparator + s.signature(alias)
+}
+
+// Verify reports whether alias carries a valid signature.
+func (s *Signer) Verify(alias string) bool {
+	cut := len(alias) - s.length - len(SignatureSeparator)
+	if cut < 1 || alias[cut:cut+len(SignatureSeparator)] != SignatureSeparator {
+		return false
+	}
+
+	sig := alias[cut+len(SignatureSeparator):]
+	if s.foldCase {
+		sig = strings.ToLower(sig)
+	}
+
+	return hmac.Equal([]byte(sig), []byte(s.signature(alias[:cut])))
+}
+
+func (s *Signer) signature(alias string) string {
+	if s.foldCase {
+		alias = strings.ToLower(alias)
+	}
+
+	mac := hmac.New(sha256.New, s.key)
+	mac.Write([]byte(alias))
+	sum := mac.Sum(nil)
+
+	sig := make([]byte, s.length)
+	for i := range sig {
+		sig[i] = SignatureAlphabet[int(sum[i])%len(SignatureAlphabet)]
+	}
+
+	return string(sig)
+}
+
+// Signed makes aliases with another generator and signs them.
+type Signed struct {
+	next   Generator
+	signer *Signer
+}
+
+// NewSigned returns a generator of the aliases of next signed by signer.
+func NewSigned(next Generator, signer *Signer) *Signed {
+	return &Signed{next: next, signer: signer}
+}
+
+func (g *Signed) Generate(ctx context.Context) (string, error) {
+	alias, err := g.next.Generate(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	return g.signer.Sign(alias), nil
+}
@@ -0,0 +1,55 @@
+package alias_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"url-shortener/internal/lib/alias"
+)
+
+func TestSigner(t *testing.T) {
+	key := []byte("0123456789abcdef")
+
+	s, err := alias.NewSigner(key, 6, false)
+	require.NoError(t, err)
+
+	signed := s.Sign("promo")
+	require.Len(t, signed, len("promo-")+6)
+	require.True(t, strings.HasPrefix(signed, "promo-"))
+	require.True(t, s.Verify(signed))
+	require.Equal(t, signed, s.Sign(signed), "signing twice")
+
+	require.False(t, s.Verify("promo"))
+	require.False(t, s.Verify("-"+signed[len("promo-"):]))
+	require.False(t, s.Verify(strings.ToUpper(signed)))
+
+	other, err := alias.NewSigner([]byte("fedcba9876543210"), 6, false)
+	require.NoError(t, err)
+	require.False(t, other.Verify(signed))
+
+	folded, err := alias.NewSigner(key, 6, true)
+	require.NoError(t, err)
+	require.Equal(t, signed, folded.Sign("promo"))
+	require.True(t, folded.Verify(strings.ToUpper(signed)))
+
+	_, err = alias.NewSigner(key, 3, false)
+	require.Error(t, err)
+	_, err = alias.NewSigner(nil, 6, false)
+	require.Error(t, err)
+}
+
+func TestSigned(t *testing.T) {
+	s, err := alias.NewSigner([]byte("0123456789abcdef"), 4, false)
+	require.NoError(t, err)
+
+	next, err := alias.NewRandom(6, "abc")
+	require.NoError(t, err)
+
+	a, err := alias.NewSigned(next, s).Generate(context.Background())
+	require.NoError(t, err)
+	require.Len(t, a, 11)
+	require.True(t, s.Verify(a))
+}
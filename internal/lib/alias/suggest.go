@@ -0,0 +1,183 @@
+package alias
+
+import (
+	_ "embed"
+	"math/rand/v2"
+	"net/url"
+	"strings"
+	"unicode"
+
+	"golang.org/x/net/publicsuffix"
+)
+
+var (
+	//go:embed adjectives.txt
+	adjectivesList string
+	//go:embed nouns.txt
+	nounsList string
+)
+
+// stopWords are title words too common to name a page by.
+var stopWords = map[string]struct{}{
+	"the": {}, "and": {}, "for": {}, "with": {}, "from": {}, "your": {}, "you": {},
+	"our": {}, "are": {}, "how": {}, "what": {}, "why": {}, "this": {}, "that": {},
+	"home": {}, "page": {}, "welcome": {}, "official": {}, "site": {}, "website": {},
+}
+
+// maxTitleWords is how many words of a title suggestions are made of.
+const maxTitleWords = 3
+
+// Suggester proposes aliases people can read and remember, made of the
+// domain of a link, the title of its page and random words.
+type Suggester struct {
+	validator  *Validator
+	filter     *Filter
+	adjectives []string
+	nouns      []string
+}
+
+// NewSuggester returns a Suggester whose aliases pass validator and, if
+// it is not nil, filter.
+func NewSuggester(validator *Validator, filter *Filter) *Suggester {
+	return &Suggester{
+		validator:  validator,
+		filter:     filter,
+		adjectives: wordList(adjectivesList),
+		nouns:      wordList(nounsList),
+	}
+}
+
+func wordList(list string) []string {
+	var words []string
+	for _, line := range strings.Split(list, "\n") {
+		if line = strings.TrimSpace(line); line != "" && !strings.HasPrefix(line, "#") {
+			words = append(words, line)
+		}
+	}
+
+	return words
+}
+
+// Suggest returns up to n different aliases for a link to rawURL whose
+// page is titled title, which may be empty. Aliases made of the domain
+// and the title come first, then ones with random words. Whether an alias
+// is free is up to the caller.
+func (s *Suggester) Suggest(rawURL string, title string, n int) []string {
+	site := siteName(rawURL)
+	words := titleWords(title)
+
+	var candidates [][]string
+	if len(words) > 1 {
+		candidates = append(candidates, words)
+		candidates = append(candidates, words[:2])
+	}
+	if site != "" {
+		candidates = append(candidates, []string{site})
+		for _, w := range words {
+			if w != site {
+				candidates = append(candidates, []string{site, w})
+			}
+		}
+	}
+	for _, w := range words {
+		candidates = append(candidates, []string{w})
+	}
+	// Random words take every remaining place; a few rounds more than n
+	// make up for the ones that are rejected or already taken.
+	for range 3 * n {
+		noun := s.nouns[rand.IntN(len(s.nouns))]
+		switch {
+		case site != "":
+			candidates = append(candidates, []string{site, noun})
+		case len(words) > 0:
+			candidates = append(candidates, []string{words[0], noun})
+		}
+		candidates = append(candidates, []string{s.adjectives[rand.IntN(len(s.adjectives))], noun})
+	}
+
+	seen := make(map[string]struct{})
+	suggestions := make([]string, 0, n)
+	for _, parts := range candidates {
+		a, ok := s.join(parts)
+		if !ok {
+			continue
+		}
+		if _, dup := seen[a]; dup {
+			continue
+		}
+		seen[a] = struct{}{}
+
+		suggestions = append(suggestions, a)
+		if len(suggestions) == n {
+			break
+		}
+	}
+
+	return suggestions
+}
+
+// join makes an alias of parts, separated by dashes or, if the rules do
+// not allow them, run together.
+func (s *Suggester) join(parts []string) (string, bool) {
+	for _, sep := range []string{"-", ""} {
+		a := strings.Join(parts, sep)
+		if s.filter != nil && s.filter.Reject(a) {
+			return "", false
+		}
+		if s.validator.Validate(a) == nil {
+			return a, true
+		}
+	}
+
+	return "", false
+}
+
+// siteName returns the name a site is known by, e.g. github for
+// https://docs.github.com or bbc for https://www.bbc.co.uk.
+func siteName(rawURL string) string {
+	u, err := url.Parse(rawURL)
+	if err != nil || u.Hostname() == "" {
+		return ""
+	}
+
+	domain, err := publicsuffix.EffectiveTLDPlusOne(strings.ToLower(u.Hostname()))
+	if err != nil {
+		return ""
+	}
+	name, _, _ := strings.Cut(domain, ".")
+
+	return slug(name)
+}
+
+// titleWords returns the first words of title worth naming a page by, in
+// lower case and without characters other than Latin letters and digits.
+func titleWords(title string) []string {
+	var words []string
+	for _, field := range strings.FieldsFunc(strings.ToLower(title), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	}) {
+		w := slug(field)
+		if len(w) < 3 {
+			continue
+		}
+		if _, stop := stopWords[w]; stop {
+			continue
+		}
+		words = append(words, w)
+		if len(words) == maxTitleWords {
+			break
+		}
+	}
+
+	return words
+}
+
+// slug keeps the ASCII letters and digits of s.
+func slug(s string) string {
+	return strings.Map(func(r rune) rune {
+		if r >= 'a' && r <= 'z' || r >= '0' && r <= '9' {
+			return r
+		}
+		return -1
+	}, strings.ToLower(s))
+}
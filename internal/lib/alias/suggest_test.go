@@ -0,0 +1,50 @@
+package alias_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"url-shortener/internal/lib/alias"
+)
+
+func TestSuggester_Suggest(t *testing.T) {
+	v, err := alias.New(alias.Rules{MinLength: 3, MaxLength: 64, Pattern: "[A-Za-z0-9_-]+", Reserved: alias.Routes})
+	require.NoError(t, err)
+	s := alias.NewSuggester(v, alias.NewFilter(alias.FilterRules{BadWords: true}))
+
+	got := s.Suggest("https://docs.github.com/en/actions", "Understanding GitHub Actions - GitHub Docs", 5)
+	require.Equal(t, []string{"understanding-github-actions", "understanding-github", "github", "github-understanding", "github-actions"}, got)
+
+	got = s.Suggest("https://www.bbc.co.uk/news", "", 6)
+	require.Len(t, got, 6)
+	require.Equal(t, "bbc", got[0])
+	for _, a := range got {
+		require.NoError(t, v.Validate(a))
+	}
+
+	// Without a usable domain or title the words are random.
+	got = s.Suggest("not a url", "Привет, мир", 4)
+	require.Len(t, got, 4)
+	for _, a := range got {
+		require.Len(t, strings.Split(a, "-"), 2, a)
+	}
+}
+
+func TestSuggester_NoDashes(t *testing.T) {
+	v, err := alias.New(alias.Rules{Pattern: "[a-z]+"})
+	require.NoError(t, err)
+
+	got := alias.NewSuggester(v, nil).Suggest("https://example.com", "Example Domain", 2)
+	require.Equal(t, []string{"exampledomain", "example"}, got)
+}
+
+func TestSuggester_WordsPassFilter(t *testing.T) {
+	v, err := alias.New(alias.Rules{})
+	require.NoError(t, err)
+	s := alias.NewSuggester(v, alias.NewFilter(alias.FilterRules{BadWords: true}))
+
+	// A filtered word would leave fewer suggestions than asked for.
+	require.Len(t, s.Suggest("", "", 50), 50)
+}
@@ -8,8 +8,17 @@ import (
 )
 
 type Response struct {
-	Status string `json:"status"` //error, ok
-	Error  string `json:"error,omitempty"`
+	Status  string       `json:"status"` //error, ok
+	Error   string       `json:"error,omitempty"`
+	Details []FieldError `json:"details,omitempty"`
+}
+
+// FieldError tells which validation rule a request field broke, so clients
+// can react without parsing the error message.
+type FieldError struct {
+	Field   string `json:"field"`
+	Rule    string `json:"rule"`
+	Message string `json:"message"`
 }
 
 const (
@@ -30,6 +39,15 @@ func Error(msg string) Response {
 	}
 }
 
+// InvalidField reports a single broken validation rule.
+func InvalidField(field, rule, msg string) Response {
+	return Response{
+		Status:  StatusError,
+		Error:   msg,
+		Details: []FieldError{{Field: field, Rule: rule, Message: msg}},
+	}
+}
+
 func ValidationError(errs validator.ValidationErrors) Response {
 	var errMsgs []string
 	for _, err := range errs {
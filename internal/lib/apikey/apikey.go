@@ -0,0 +1,29 @@
+package apikey
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+)
+
+// prefix makes keys easy to recognise in configs and secret scanners.
+const prefix = "us_"
+
+// Generate returns a new random API key.
+func Generate() (string, error) {
+	const op = "lib.apikey.Generate"
+
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("%s: %w", op, err)
+	}
+
+	return prefix + hex.EncodeToString(b), nil
+}
+
+// Hash returns the value stored in place of the key itself.
+func Hash(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:])
+}
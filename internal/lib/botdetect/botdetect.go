@@ -0,0 +1,83 @@
+// Package botdetect tells crawlers, link unfurlers and scripts from people
+// by the User-Agent of their requests, to keep them out of link stats, and
+// tells the crawlers of social networks from other bots, to show them link
+// previews.
+package botdetect
+
+import (
+	_ "embed"
+	"fmt"
+	"os"
+	"strings"
+)
+
+var (
+	//go:embed bots.txt
+	knownBots string
+	//go:embed social.txt
+	socialBots string
+)
+
+// Detector classifies User-Agents with the built-in list of bot markers
+// and, optionally, a list of its own.
+type Detector struct {
+	markers []string
+	social  []string
+}
+
+// New returns a detector with the built-in markers and those in listFile,
+// if set: User-Agent substrings one per line, # starting a comment.
+func New(listFile string) (*Detector, error) {
+	const op = "botdetect.New"
+
+	d := &Detector{markers: parse(knownBots), social: parse(socialBots)}
+
+	if listFile != "" {
+		data, err := os.ReadFile(listFile)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", op, err)
+		}
+		d.markers = append(d.markers, parse(string(data))...)
+	}
+
+	return d, nil
+}
+
+func parse(list string) []string {
+	var markers []string
+	for _, line := range strings.Split(list, "\n") {
+		if line = strings.ToLower(strings.TrimSpace(line)); line != "" && !strings.HasPrefix(line, "#") {
+			markers = append(markers, line)
+		}
+	}
+
+	return markers
+}
+
+// IsBot reports whether userAgent is empty, which browsers never send, or
+// contains one of the markers, ignoring case.
+func (d *Detector) IsBot(userAgent string) bool {
+	if strings.TrimSpace(userAgent) == "" {
+		return true
+	}
+
+	return matches(d.markers, userAgent)
+}
+
+// IsSocialCrawler reports whether userAgent is that of a crawler social
+// networks and messengers unfurl shared links with, e.g. Twitterbot or
+// Slackbot. Only the built-in markers are checked.
+func (d *Detector) IsSocialCrawler(userAgent string) bool {
+	return matches(d.social, userAgent)
+}
+
+func matches(markers []string, userAgent string) bool {
+	ua := strings.ToLower(userAgent)
+	for _, marker := range markers {
+		if strings.Contains(ua, marker) {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,82 @@
+package botdetect_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"url-shortener/internal/lib/botdetect"
+)
+
+func TestIsBot(t *testing.T) {
+	d, err := botdetect.New("")
+	require.NoError(t, err)
+
+	cases := []struct {
+		name      string
+		userAgent string
+		want      bool
+	}{
+		{name: "Chrome", userAgent: "Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/124.0.0.0 Safari/537.36"},
+		{name: "iPhone", userAgent: "Mozilla/5.0 (iPhone; CPU iPhone OS 17_4 like Mac OS X) AppleWebKit/605.1.15 (KHTML, like Gecko) Version/17.4 Mobile/15E148 Safari/604.1"},
+		{name: "Googlebot", userAgent: "Mozilla/5.0 (compatible; Googlebot/2.1; +http://www.google.com/bot.html)", want: true},
+		{name: "Unfurler", userAgent: "facebookexternalhit/1.1 (+http://www.facebook.com/externalhit_uatext.php)", want: true},
+		{name: "Headless", userAgent: "Mozilla/5.0 (X11; Linux x86_64) AppleWebKit/537.36 (KHTML, like Gecko) HeadlessChrome/124.0.0.0 Safari/537.36", want: true},
+		{name: "curl", userAgent: "curl/8.5.0", want: true},
+		{name: "Go", userAgent: "Go-http-client/1.1", want: true},
+		{name: "Empty", userAgent: " ", want: true},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			require.Equal(t, tc.want, d.IsBot(tc.userAgent))
+		})
+	}
+}
+
+func TestNew_ListFile(t *testing.T) {
+	const ua = "Mozilla/5.0 (compatible; AcmeFetcher/1.0)"
+
+	path := filepath.Join(t.TempDir(), "bots.txt")
+	require.NoError(t, os.WriteFile(path, []byte("# ours\nAcmeFetcher\n\n"), 0o600))
+
+	d, err := botdetect.New("")
+	require.NoError(t, err)
+	require.False(t, d.IsBot(ua))
+
+	d, err = botdetect.New(path)
+	require.NoError(t, err)
+	require.True(t, d.IsBot(ua))
+
+	_, err = botdetect.New(filepath.Join(t.TempDir(), "missing.txt"))
+	require.Error(t, err)
+}
+
+func TestIsSocialCrawler(t *testing.T) {
+	d, err := botdetect.New("")
+	require.NoError(t, err)
+
+	cases := []struct {
+		name      string
+		userAgent string
+		want      bool
+	}{
+		{name: "Chrome", userAgent: "Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/124.0.0.0 Safari/537.36"},
+		{name: "Googlebot", userAgent: "Mozilla/5.0 (compatible; Googlebot/2.1; +http://www.google.com/bot.html)"},
+		{name: "curl", userAgent: "curl/8.5.0"},
+		{name: "Empty", userAgent: ""},
+		{name: "Facebook", userAgent: "facebookexternalhit/1.1 (+http://www.facebook.com/externalhit_uatext.php)", want: true},
+		{name: "Twitter", userAgent: "Twitterbot/1.0", want: true},
+		{name: "Slack", userAgent: "Slackbot-LinkExpanding 1.0 (+https://api.slack.com/robots)", want: true},
+		{name: "Telegram", userAgent: "TelegramBot (like TwitterBot)", want: true},
+		{name: "Discord", userAgent: "Mozilla/5.0 (compatible; Discordbot/2.0; +https://discordapp.com)", want: true},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			require.Equal(t, tc.want, d.IsSocialCrawler(tc.userAgent))
+		})
+	}
+}
@@ -0,0 +1,78 @@
+package bulk
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"url-shortener/internal/storage"
+)
+
+const maxRetries = 5
+
+// ErrAliasGeneration is reported for a link that still collided after
+// maxRetries random aliases.
+var ErrAliasGeneration = errors.New("failed to generate unique alias")
+
+type Saver interface {
+	SaveURLs(ctx context.Context, urls []storage.URL) ([]error, error)
+}
+
+// AliasGenerator makes aliases for links saved without one.
+type AliasGenerator interface {
+	Generate(ctx context.Context) (string, error)
+}
+
+// Save saves urls with as few storage calls as possible. Links without an
+// alias get one from generator, which is regenerated on collision; aliases chosen
+// by the caller fail with storage.ErrUrlExists at once. Generated aliases
+// are written back into urls. The returned slice holds the outcome of
+// every link; the error means the storage failed and the rest of the links
+// were not saved.
+func Save(ctx context.Context, saver Saver, generator AliasGenerator, urls []storage.URL) ([]error, error) {
+	const op = "lib.bulk.Save"
+
+	errs := make([]error, len(urls))
+
+	pending := make([]int, len(urls))
+	generated := make([]bool, len(urls))
+	for i, u := range urls {
+		pending[i] = i
+		generated[i] = u.Alias == ""
+	}
+
+	for attempt := 0; attempt < maxRetries && len(pending) > 0; attempt++ {
+		batch := make([]storage.URL, len(pending))
+		for j, i := range pending {
+			if generated[i] {
+				alias, err := generator.Generate(ctx)
+				if err != nil {
+					return nil, fmt.Errorf("%s: %w", op, err)
+				}
+				urls[i].Alias = alias
+			}
+			batch[j] = urls[i]
+		}
+
+		batchErrs, err := saver.SaveURLs(ctx, batch)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", op, err)
+		}
+
+		retry := pending[:0]
+		for j, i := range pending {
+			if generated[i] && errors.Is(batchErrs[j], storage.ErrUrlExists) {
+				retry = append(retry, i)
+				continue
+			}
+			errs[i] = batchErrs[j]
+		}
+		pending = retry
+	}
+
+	for _, i := range pending {
+		urls[i].Alias = ""
+		errs[i] = ErrAliasGeneration
+	}
+
+	return errs, nil
+}
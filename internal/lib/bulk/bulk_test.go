@@ -0,0 +1,75 @@
+package bulk_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"url-shortener/internal/lib/alias"
+	"url-shortener/internal/lib/bulk"
+	"url-shortener/internal/storage"
+	"url-shortener/internal/storage/memory"
+)
+
+// collidingSaver reports every generated alias as taken.
+type collidingSaver struct {
+	calls int
+}
+
+func (s *collidingSaver) SaveURLs(_ context.Context, urls []storage.URL) ([]error, error) {
+	s.calls++
+	errs := make([]error, len(urls))
+	for i := range urls {
+		errs[i] = storage.ErrUrlExists
+	}
+	return errs, nil
+}
+
+func TestSave(t *testing.T) {
+	s := memory.New()
+	ctx := context.Background()
+
+	_, err := s.SaveURL(ctx, storage.URL{URL: "https://google.com", Alias: "google"})
+	require.NoError(t, err)
+
+	urls := []storage.URL{
+		{URL: "https://github.com", Alias: "github"},
+		{URL: "https://github.com", Alias: "google"},
+		{URL: "https://go.dev"},
+	}
+
+	errs, err := bulk.Save(ctx, s, alias.DefaultRandom(), urls)
+	require.NoError(t, err)
+	require.NoError(t, errs[0])
+	require.ErrorIs(t, errs[1], storage.ErrUrlExists)
+	require.NoError(t, errs[2])
+	require.NotEmpty(t, urls[2].Alias)
+
+	got, err := s.GetURL(ctx, urls[2].Alias)
+	require.NoError(t, err)
+	require.Equal(t, "https://go.dev", got.URL)
+}
+
+func TestSave_GivesUpOnCollisions(t *testing.T) {
+	saver := &collidingSaver{}
+	urls := []storage.URL{{URL: "https://go.dev"}}
+
+	errs, err := bulk.Save(context.Background(), saver, alias.DefaultRandom(), urls)
+	require.NoError(t, err)
+	require.ErrorIs(t, errs[0], bulk.ErrAliasGeneration)
+	require.Empty(t, urls[0].Alias)
+	require.Equal(t, 5, saver.calls)
+}
+
+func TestSave_StorageError(t *testing.T) {
+	_, err := bulk.Save(context.Background(), failingSaver{}, alias.DefaultRandom(), []storage.URL{{URL: "https://go.dev"}})
+	require.Error(t, err)
+}
+
+type failingSaver struct{}
+
+func (failingSaver) SaveURLs(context.Context, []storage.URL) ([]error, error) {
+	return nil, errors.New("unexpected error")
+}
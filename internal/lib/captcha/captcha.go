@@ -0,0 +1,95 @@
+// Package captcha checks captcha tokens with the siteverify API shared by
+// hCaptcha, reCAPTCHA and Cloudflare Turnstile.
+package captcha
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// Providers and their siteverify endpoints.
+const (
+	HCaptcha  = "hcaptcha"
+	ReCaptcha = "recaptcha"
+	Turnstile = "turnstile"
+)
+
+var endpoints = map[string]string{
+	HCaptcha:  "https://api.hcaptcha.com/siteverify",
+	ReCaptcha: "https://www.google.com/recaptcha/api/siteverify",
+	Turnstile: "https://challenges.cloudflare.com/turnstile/v0/siteverify",
+}
+
+// ErrInvalidToken means the provider did not accept the token: it is
+// missing, expired, already used or solved for another site.
+var ErrInvalidToken = errors.New("invalid captcha token")
+
+// Verifier checks tokens with one provider.
+type Verifier struct {
+	client   *http.Client
+	endpoint string
+	secret   string
+}
+
+// Endpoint returns the siteverify URL of provider, or "" for an unknown
+// provider.
+func Endpoint(provider string) string {
+	return endpoints[provider]
+}
+
+// New returns a Verifier that calls endpoint, normally Endpoint of the
+// provider, with the secret key of the site.
+func New(client *http.Client, endpoint string, secret string) *Verifier {
+	return &Verifier{client: client, endpoint: endpoint, secret: secret}
+}
+
+type verifyResponse struct {
+	Success    bool     `json:"success"`
+	ErrorCodes []string `json:"error-codes"`
+}
+
+// Verify returns ErrInvalidToken if the provider rejects token, which the
+// visitor at remoteIP solved; remoteIP may be empty.
+func (v *Verifier) Verify(ctx context.Context, token string, remoteIP string) error {
+	const op = "lib.captcha.Verify"
+
+	if token == "" {
+		return fmt.Errorf("%s: %w", op, ErrInvalidToken)
+	}
+
+	form := url.Values{"secret": {v.secret}, "response": {token}}
+	if remoteIP != "" {
+		form.Set("remoteip", remoteIP)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, v.endpoint, strings.NewReader(form.Encode()))
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	res, err := v.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		return fmt.Errorf("%s: unexpected status %d", op, res.StatusCode)
+	}
+
+	var verified verifyResponse
+	if err := json.NewDecoder(res.Body).Decode(&verified); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	if !verified.Success {
+		return fmt.Errorf("%s: %w: %s", op, ErrInvalidToken, strings.Join(verified.ErrorCodes, ", "))
+	}
+
+	return nil
+}
@@ -0,0 +1,39 @@
+package captcha_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"url-shortener/internal/lib/captcha"
+)
+
+func TestVerifier_Verify(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.NoError(t, r.ParseForm())
+		require.Equal(t, "secret", r.PostForm.Get("secret"))
+		require.Equal(t, "203.0.113.7", r.PostForm.Get("remoteip"))
+
+		if r.PostForm.Get("response") == "solved" {
+			_, _ = w.Write([]byte(`{"success": true}`))
+			return
+		}
+		_, _ = w.Write([]byte(`{"success": false, "error-codes": ["invalid-input-response"]}`))
+	}))
+	defer ts.Close()
+
+	v := captcha.New(ts.Client(), ts.URL, "secret")
+	ctx := context.Background()
+
+	require.NoError(t, v.Verify(ctx, "solved", "203.0.113.7"))
+	require.ErrorIs(t, v.Verify(ctx, "forged", "203.0.113.7"), captcha.ErrInvalidToken)
+	require.ErrorIs(t, v.Verify(ctx, "", "203.0.113.7"), captcha.ErrInvalidToken)
+}
+
+func TestEndpoint(t *testing.T) {
+	require.Equal(t, "https://api.hcaptcha.com/siteverify", captcha.Endpoint(captcha.HCaptcha))
+	require.Empty(t, captcha.Endpoint("unknown"))
+}
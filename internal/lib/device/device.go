@@ -0,0 +1,55 @@
+// Package device tells which kind of device a request comes from by its
+// User-Agent, to pick a link's device target.
+package device
+
+import (
+	"strings"
+
+	"url-shortener/internal/storage"
+)
+
+// mobileMarkers are User-Agent substrings of phones and tablets other than
+// iOS and Android ones.
+var mobileMarkers = []string{
+	"mobile", "windows phone", "blackberry", "bb10", "opera mini", "kaios", "tablet",
+}
+
+// Classify returns storage.DeviceIOS, storage.DeviceAndroid,
+// storage.DeviceMobile or storage.DeviceDesktop for userAgent. Anything not
+// recognised as a mobile device, including an empty User-Agent, is a
+// desktop.
+func Classify(userAgent string) string {
+	ua := strings.ToLower(userAgent)
+
+	switch {
+	case strings.Contains(ua, "iphone") || strings.Contains(ua, "ipad") || strings.Contains(ua, "ipod"):
+		return storage.DeviceIOS
+	case strings.Contains(ua, "android"):
+		return storage.DeviceAndroid
+	}
+
+	for _, marker := range mobileMarkers {
+		if strings.Contains(ua, marker) {
+			return storage.DeviceMobile
+		}
+	}
+
+	return storage.DeviceDesktop
+}
+
+// Target returns the URL of targets for a visitor with userAgent. iOS and
+// Android devices without a target of their own get the mobile one; false
+// means the link's default URL applies.
+func Target(targets storage.Targets, userAgent string) (string, bool) {
+	class := Classify(userAgent)
+	if target, ok := targets[class]; ok {
+		return target, true
+	}
+
+	if class == storage.DeviceIOS || class == storage.DeviceAndroid {
+		target, ok := targets[storage.DeviceMobile]
+		return target, ok
+	}
+
+	return "", false
+}
@@ -0,0 +1,63 @@
+package device_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"url-shortener/internal/lib/device"
+	"url-shortener/internal/storage"
+)
+
+const (
+	iPhone  = "Mozilla/5.0 (iPhone; CPU iPhone OS 17_4 like Mac OS X) AppleWebKit/605.1.15 (KHTML, like Gecko) Version/17.4 Mobile/15E148 Safari/604.1"
+	android = "Mozilla/5.0 (Linux; Android 14; Pixel 8) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/124.0.0.0 Mobile Safari/537.36"
+	desktop = "Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/124.0.0.0 Safari/537.36"
+	phone   = "Mozilla/5.0 (Mobile; rv:48.0) Gecko/48.0 Firefox/48.0 KAIOS/2.5"
+)
+
+func TestClassify(t *testing.T) {
+	cases := []struct {
+		name      string
+		userAgent string
+		want      string
+	}{
+		{name: "iPhone", userAgent: iPhone, want: storage.DeviceIOS},
+		{name: "Android", userAgent: android, want: storage.DeviceAndroid},
+		{name: "Other mobile", userAgent: phone, want: storage.DeviceMobile},
+		{name: "Desktop", userAgent: desktop, want: storage.DeviceDesktop},
+		{name: "Empty", userAgent: "", want: storage.DeviceDesktop},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			require.Equal(t, tc.want, device.Classify(tc.userAgent))
+		})
+	}
+}
+
+func TestTarget(t *testing.T) {
+	targets := storage.Targets{
+		storage.DeviceIOS:    "myapp://item/1",
+		storage.DeviceMobile: "https://m.example.com/item/1",
+	}
+
+	cases := []struct {
+		name      string
+		userAgent string
+		want      string
+		wantOK    bool
+	}{
+		{name: "Own target", userAgent: iPhone, want: "myapp://item/1", wantOK: true},
+		{name: "Mobile fallback", userAgent: android, want: "https://m.example.com/item/1", wantOK: true},
+		{name: "No target", userAgent: desktop},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			target, ok := device.Target(targets, tc.userAgent)
+			require.Equal(t, tc.wantOK, ok)
+			require.Equal(t, tc.want, target)
+		})
+	}
+}
@@ -0,0 +1,122 @@
+// Package domain restricts the hosts links may point to.
+package domain
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"path"
+	"strings"
+)
+
+var ErrNotAllowed = errors.New("domain not allowed")
+
+// ErrSelfReference is returned for links to the shortener itself. Such a
+// link would only lead to another short link, or back to itself in a loop.
+var ErrSelfReference = errors.New("url points to the url shortener itself")
+
+type Rules struct {
+	// Allow, if not empty, lists the only hosts links may point to.
+	Allow []string
+	// Block lists hosts links may not point to, even if allowed.
+	Block []string
+	// Self lists the hosts the shortener is served on; links may never
+	// point to them.
+	Self []string
+	// SelfRequestHost adds the host a request came in on to Self, for
+	// services that make short links from it.
+	SelfRequestHost bool
+}
+
+// Policy checks hosts against host patterns. A pattern is a host name in
+// which * stands for any run of characters, so *.example.com covers every
+// subdomain of example.com but not example.com itself. Hosts are compared
+// case-insensitively.
+type Policy struct {
+	allow       []string
+	block       []string
+	self        []string
+	requestHost bool
+}
+
+// New returns a policy enforcing rules; empty rules allow every host.
+func New(rules Rules) (*Policy, error) {
+	const op = "lib.domain.New"
+
+	allow, err := patterns(rules.Allow)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	block, err := patterns(rules.Block)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	self, err := patterns(rules.Self)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return &Policy{allow: allow, block: block, self: self, requestHost: rules.SelfRequestHost}, nil
+}
+
+func patterns(entries []string) ([]string, error) {
+	var res []string
+	for _, entry := range entries {
+		entry = normalize(entry)
+		if entry == "" {
+			continue
+		}
+		if _, err := path.Match(entry, ""); err != nil {
+			return nil, fmt.Errorf("invalid host pattern %q: %w", entry, err)
+		}
+		res = append(res, entry)
+	}
+
+	return res, nil
+}
+
+// Check returns an error wrapping ErrSelfReference if rawURL points to the
+// shortener, and one wrapping ErrNotAllowed if its host is blocked or
+// missing from a non-empty allowlist. requestHost is the Host the request
+// came in on, if any; its port is ignored.
+func (p *Policy) Check(rawURL string, requestHost string) error {
+	if len(p.allow) == 0 && len(p.block) == 0 && len(p.self) == 0 && !p.requestHost {
+		return nil
+	}
+
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return fmt.Errorf("%w: %s", ErrNotAllowed, rawURL)
+	}
+
+	host := normalize(u.Hostname())
+	if matchAny(p.self, host) || (p.requestHost && requestHost != "" && hostname(requestHost) == host) {
+		return fmt.Errorf("%w: %s", ErrSelfReference, host)
+	}
+	if matchAny(p.block, host) || (len(p.allow) > 0 && !matchAny(p.allow, host)) {
+		return fmt.Errorf("%w: %s", ErrNotAllowed, host)
+	}
+
+	return nil
+}
+
+func matchAny(patterns []string, host string) bool {
+	for _, pattern := range patterns {
+		// Patterns are validated in New, so Match cannot fail here.
+		if ok, _ := path.Match(pattern, host); ok {
+			return true
+		}
+	}
+
+	return false
+}
+
+// hostname strips the port from host, as in the Host header.
+func hostname(host string) string {
+	return normalize((&url.URL{Host: host}).Hostname())
+}
+
+func normalize(host string) string {
+	return strings.TrimSuffix(strings.ToLower(strings.TrimSpace(host)), ".")
+}
@@ -0,0 +1,117 @@
+package domain_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"url-shortener/internal/lib/domain"
+)
+
+func TestPolicy_Check(t *testing.T) {
+	cases := []struct {
+		name    string
+		rules   domain.Rules
+		url     string
+		allowed bool
+	}{
+		{
+			name:    "No Rules",
+			url:     "https://example.com",
+			allowed: true,
+		},
+		{
+			name:  "Blocked",
+			rules: domain.Rules{Block: []string{"evil.com"}},
+			url:   "https://EVIL.com./path",
+		},
+		{
+			name:    "Block Is Exact",
+			rules:   domain.Rules{Block: []string{"evil.com"}},
+			url:     "https://notevil.com",
+			allowed: true,
+		},
+		{
+			name:  "Blocked Wildcard",
+			rules: domain.Rules{Block: []string{"*.evil.com"}},
+			url:   "https://cdn.eu.evil.com",
+		},
+		{
+			name:    "Wildcard Skips Parent",
+			rules:   domain.Rules{Block: []string{"*.evil.com"}},
+			url:     "https://evil.com",
+			allowed: true,
+		},
+		{
+			name:    "Allowed",
+			rules:   domain.Rules{Allow: []string{"example.com", "*.example.com"}},
+			url:     "https://docs.example.com:8443/a",
+			allowed: true,
+		},
+		{
+			name:  "Not Allowed",
+			rules: domain.Rules{Allow: []string{"example.com", "*.example.com"}},
+			url:   "https://example.org",
+		},
+		{
+			name:  "Block Wins",
+			rules: domain.Rules{Allow: []string{"*.example.com"}, Block: []string{"ads.example.com"}},
+			url:   "https://ads.example.com",
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			p, err := domain.New(tc.rules)
+			require.NoError(t, err)
+
+			err = p.Check(tc.url, "")
+			if tc.allowed {
+				require.NoError(t, err)
+			} else {
+				require.ErrorIs(t, err, domain.ErrNotAllowed)
+			}
+		})
+	}
+}
+
+func TestPolicy_CheckSelf(t *testing.T) {
+	p, err := domain.New(domain.Rules{Allow: []string{"*"}, Self: []string{"sho.rt", "go.example.com"}, SelfRequestHost: true})
+	require.NoError(t, err)
+
+	cases := []struct {
+		name        string
+		url         string
+		requestHost string
+		ok          bool
+	}{
+		{name: "Short link", url: "https://sho.rt/abc123"},
+		{name: "Case and port", url: "http://GO.example.com:8080/abc"},
+		{name: "Request host", url: "http://localhost/abc", requestHost: "localhost:8082"},
+		{name: "Other host", url: "https://example.com/sho.rt", requestHost: "localhost:8082", ok: true},
+		{name: "Subdomain", url: "https://www.sho.rt", ok: true},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := p.Check(tc.url, tc.requestHost)
+			if tc.ok {
+				require.NoError(t, err)
+			} else {
+				require.ErrorIs(t, err, domain.ErrSelfReference)
+			}
+		})
+	}
+}
+
+func TestPolicy_CheckRequestHostOff(t *testing.T) {
+	p, err := domain.New(domain.Rules{Self: []string{"sho.rt"}})
+	require.NoError(t, err)
+
+	require.NoError(t, p.Check("http://localhost/abc", "localhost:8082"))
+}
+
+func TestNew_InvalidPattern(t *testing.T) {
+	_, err := domain.New(domain.Rules{Block: []string{"[evil.com"}})
+	require.Error(t, err)
+}
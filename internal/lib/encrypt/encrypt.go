@@ -0,0 +1,113 @@
+// Package encrypt seals strings with AES-GCM so they can be stored as text.
+package encrypt
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hkdf"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// prefix marks sealed values; values without it were stored before
+// encryption was turned on and are read as they are.
+const prefix = "enc1:"
+
+var ErrDecrypt = errors.New("cannot decrypt value")
+
+// Cipher encrypts deterministically: the nonce is a keyed hash of the
+// plaintext, so equal values give equal ciphertexts and can still be
+// looked up by equality. That tells an attacker which values are equal
+// but nothing else about them.
+type Cipher struct {
+	aead     cipher.AEAD
+	nonceKey []byte
+}
+
+// New returns a Cipher for a 16, 24 or 32 byte key, which picks AES-128,
+// AES-192 or AES-256.
+func New(key []byte) (*Cipher, error) {
+	const op = "encrypt.New"
+
+	switch len(key) {
+	case 16, 24, 32:
+	default:
+		return nil, fmt.Errorf("%s: key must be 16, 24 or 32 bytes, got %d", op, len(key))
+	}
+
+	encKey, err := hkdf.Key(sha256.New, key, nil, "url-shortener encryption", len(key))
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	nonceKey, err := hkdf.Key(sha256.New, key, nil, "url-shortener nonce", sha256.Size)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	block, err := aes.NewCipher(encKey)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return &Cipher{aead: aead, nonceKey: nonceKey}, nil
+}
+
+// ParseKey decodes a base64 key, standard or URL-safe, padded or not.
+func ParseKey(s string) ([]byte, error) {
+	s = strings.TrimRight(strings.TrimSpace(s), "=")
+	if key, err := base64.RawStdEncoding.DecodeString(s); err == nil {
+		return key, nil
+	}
+	return base64.RawURLEncoding.DecodeString(s)
+}
+
+// Encrypt seals s. The empty string stays empty.
+func (c *Cipher) Encrypt(s string) string {
+	if s == "" {
+		return ""
+	}
+
+	mac := hmac.New(sha256.New, c.nonceKey)
+	mac.Write([]byte(s))
+	nonce := mac.Sum(nil)[:c.aead.NonceSize()]
+
+	sealed := c.aead.Seal(nonce, nonce, []byte(s), nil)
+
+	return prefix + base64.RawURLEncoding.EncodeToString(sealed)
+}
+
+// Decrypt opens a value sealed by Encrypt. Values Encrypt did not produce
+// are returned unchanged.
+func (c *Cipher) Decrypt(s string) (string, error) {
+	data, ok := strings.CutPrefix(s, prefix)
+	if !ok {
+		return s, nil
+	}
+
+	sealed, err := base64.RawURLEncoding.DecodeString(data)
+	if err != nil || len(sealed) < c.aead.NonceSize() {
+		return "", ErrDecrypt
+	}
+	nonce, ciphertext := sealed[:c.aead.NonceSize()], sealed[c.aead.NonceSize():]
+
+	plaintext, err := c.aead.Open(nil, nonce, ciphertext, nil)
+	if err != nil {
+		// A different key, or the value was tampered with.
+		return "", ErrDecrypt
+	}
+
+	return string(plaintext), nil
+}
+
+// Encrypted reports whether s was produced by Encrypt.
+func Encrypted(s string) bool {
+	return strings.HasPrefix(s, prefix)
+}
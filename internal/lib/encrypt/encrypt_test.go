@@ -0,0 +1,76 @@
+package encrypt_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"url-shortener/internal/lib/encrypt"
+)
+
+var key = []byte("0123456789abcdef0123456789abcdef")
+
+func TestCipher(t *testing.T) {
+	c, err := encrypt.New(key)
+	require.NoError(t, err)
+
+	const plain = "https://example.com/private?id=42"
+	sealed := c.Encrypt(plain)
+	require.True(t, encrypt.Encrypted(sealed))
+	require.NotContains(t, sealed, "example.com")
+	require.Equal(t, sealed, c.Encrypt(plain), "equal values give equal ciphertexts")
+	require.NotEqual(t, sealed, c.Encrypt(plain+"3"))
+
+	got, err := c.Decrypt(sealed)
+	require.NoError(t, err)
+	require.Equal(t, plain, got)
+
+	require.Empty(t, c.Encrypt(""))
+
+	// Stored before encryption was turned on.
+	got, err = c.Decrypt("https://example.com")
+	require.NoError(t, err)
+	require.Equal(t, "https://example.com", got)
+}
+
+func TestCipher_WrongKey(t *testing.T) {
+	c, err := encrypt.New(key)
+	require.NoError(t, err)
+	other, err := encrypt.New([]byte(strings.Repeat("k", 32)))
+	require.NoError(t, err)
+
+	_, err = other.Decrypt(c.Encrypt("https://example.com"))
+	require.ErrorIs(t, err, encrypt.ErrDecrypt)
+
+	_, err = c.Decrypt("enc1:!!!")
+	require.ErrorIs(t, err, encrypt.ErrDecrypt)
+}
+
+func TestNew_KeySize(t *testing.T) {
+	for _, size := range []int{16, 24, 32} {
+		_, err := encrypt.New(make([]byte, size))
+		require.NoError(t, err)
+	}
+
+	_, err := encrypt.New(make([]byte, 20))
+	require.Error(t, err)
+}
+
+func TestParseKey(t *testing.T) {
+	for _, s := range []string{
+		"MDEyMzQ1Njc4OWFiY2RlZjAxMjM0NTY3ODlhYmNkZWY=",
+		"MDEyMzQ1Njc4OWFiY2RlZjAxMjM0NTY3ODlhYmNkZWY",
+	} {
+		got, err := encrypt.ParseKey(s)
+		require.NoError(t, err)
+		require.Equal(t, key, got)
+	}
+
+	got, err := encrypt.ParseKey("-_-_")
+	require.NoError(t, err)
+	require.Equal(t, []byte{0xfb, 0xff, 0xbf}, got)
+
+	_, err = encrypt.ParseKey("not base64!")
+	require.Error(t, err)
+}
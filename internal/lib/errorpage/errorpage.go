@@ -0,0 +1,112 @@
+// Package errorpage renders HTML error pages for browsers that follow a
+// short link which cannot be served. API clients keep getting JSON.
+package errorpage
+
+import (
+	"fmt"
+	"html/template"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+var defaultPage = `<!DOCTYPE html>
+<html lang="en">
+<head>
+  <meta charset="utf-8">
+  <meta name="viewport" content="width=device-width, initial-scale=1">
+  <meta name="robots" content="noindex">
+  <title>{{.Status}} {{.StatusText}}</title>
+  <style>
+    body { font-family: sans-serif; max-width: 40em; margin: 4em auto; padding: 0 1em; }
+    .status { font-size: 3em; font-weight: bold; color: #555; }
+  </style>
+</head>
+<body>
+  <p class="status">{{.Status}}</p>
+  <h1>{{.StatusText}}</h1>
+  <p>The short link {{if .Alias}}<b>/{{.Alias}}</b> {{end}}cannot be opened: {{.Message}}.</p>
+</body>
+</html>
+`
+
+// Data is what the template of an error page is executed with.
+type Data struct {
+	Status     int
+	StatusText string
+	// Message is the error the JSON response would carry, e.g.
+	// "url not found".
+	Message string
+	Alias   string
+}
+
+// Pages renders error pages from an html/template.
+type Pages struct {
+	tmpl *template.Template
+}
+
+// New parses the template file at path; an empty path uses the built-in
+// page.
+func New(path string) (*Pages, error) {
+	const op = "lib.errorpage.New"
+
+	var (
+		tmpl *template.Template
+		err  error
+	)
+	if path == "" {
+		tmpl, err = template.New("error").Parse(defaultPage)
+	} else {
+		tmpl, err = template.ParseFiles(path)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return &Pages{tmpl: tmpl}, nil
+}
+
+// Render writes the error page with status.
+func (p *Pages) Render(w http.ResponseWriter, status int, alias string, message string) error {
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.Header().Set("Cache-Control", "no-store")
+	w.WriteHeader(status)
+
+	return p.tmpl.Execute(w, Data{
+		Status:     status,
+		StatusText: http.StatusText(status),
+		Message:    message,
+		Alias:      alias,
+	})
+}
+
+// Accepts reports whether r asks for HTML rather than JSON: text/html or
+// application/xhtml+xml is listed in Accept with a quality at least that
+// of JSON. Wildcards count for JSON only, so curl and API clients, which
+// send */* or nothing, keep getting JSON.
+func Accepts(r *http.Request) bool {
+	var html, json float64
+	for _, part := range strings.Split(r.Header.Get("Accept"), ",") {
+		mediaType, params, err := mime.ParseMediaType(strings.TrimSpace(part))
+		if err != nil {
+			continue
+		}
+
+		q := 1.0
+		if v, ok := params["q"]; ok {
+			if q, err = strconv.ParseFloat(v, 64); err != nil {
+				continue
+			}
+		}
+
+		switch mediaType {
+		case "text/html", "application/xhtml+xml":
+			html = max(html, q)
+		case "application/json", "application/*", "*/*":
+			json = max(json, q)
+		}
+	}
+
+	return html > 0 && html >= json
+}
@@ -0,0 +1,68 @@
+package errorpage_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"url-shortener/internal/lib/errorpage"
+)
+
+func TestAccepts(t *testing.T) {
+	cases := []struct {
+		name   string
+		accept string
+		want   bool
+	}{
+		{name: "Browser", accept: "text/html,application/xhtml+xml,application/xml;q=0.9,*/*;q=0.8", want: true},
+		{name: "No header"},
+		{name: "Any", accept: "*/*"},
+		{name: "JSON", accept: "application/json"},
+		{name: "JSON preferred", accept: "text/html;q=0.5, application/json"},
+		{name: "HTML refused", accept: "text/html;q=0, */*"},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodGet, "/", nil)
+			if tc.accept != "" {
+				r.Header.Set("Accept", tc.accept)
+			}
+
+			require.Equal(t, tc.want, errorpage.Accepts(r))
+		})
+	}
+}
+
+func TestPages_Render(t *testing.T) {
+	pages, err := errorpage.New("")
+	require.NoError(t, err)
+
+	rr := httptest.NewRecorder()
+	require.NoError(t, pages.Render(rr, http.StatusNotFound, "<x>", "url not found"))
+
+	require.Equal(t, http.StatusNotFound, rr.Code)
+	require.Equal(t, "text/html; charset=utf-8", rr.Header().Get("Content-Type"))
+	require.Contains(t, rr.Body.String(), "Not Found")
+	require.Contains(t, rr.Body.String(), "url not found")
+	require.Contains(t, rr.Body.String(), "/&lt;x&gt;")
+}
+
+func TestNew_Template(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "error.html")
+	require.NoError(t, os.WriteFile(path, []byte(`<h1>{{.Status}}: {{.Message}}</h1>`), 0o600))
+
+	pages, err := errorpage.New(path)
+	require.NoError(t, err)
+
+	rr := httptest.NewRecorder()
+	require.NoError(t, pages.Render(rr, http.StatusGone, "promo", "url expired"))
+	require.Equal(t, "<h1>410: url expired</h1>", rr.Body.String())
+
+	_, err = errorpage.New(filepath.Join(t.TempDir(), "missing.html"))
+	require.Error(t, err)
+}
@@ -0,0 +1,45 @@
+// Package geoip resolves the country and city of an IP address with a
+// MaxMind database (GeoLite2 or GeoIP2, Country or City edition).
+package geoip
+
+import (
+	"fmt"
+	"net"
+
+	"github.com/oschwald/geoip2-golang"
+)
+
+type DB struct {
+	reader *geoip2.Reader
+}
+
+// Open opens the .mmdb database at path. The file is memory-mapped and
+// must not be replaced in place while it is open.
+func Open(path string) (*DB, error) {
+	const op = "lib.geoip.Open"
+
+	reader, err := geoip2.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return &DB{reader: reader}, nil
+}
+
+// Locate returns the ISO 3166-1 alpha-2 code of the country ip belongs
+// to and the English name of its city; either is "" if the database does
+// not know it. Country editions know no cities.
+func (db *DB) Locate(ip net.IP) (country string, city string, err error) {
+	const op = "lib.geoip.Locate"
+
+	record, err := db.reader.City(ip)
+	if err != nil {
+		return "", "", fmt.Errorf("%s: %w", op, err)
+	}
+
+	return record.Country.IsoCode, record.City.Names["en"], nil
+}
+
+func (db *DB) Close() error {
+	return db.reader.Close()
+}
@@ -0,0 +1,66 @@
+package jwt
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+var ErrInvalidToken = errors.New("invalid token")
+
+// Manager issues and verifies HS256-signed access tokens.
+type Manager struct {
+	secret []byte
+	ttl    time.Duration
+}
+
+// claims are the registered claims plus the role of the subject.
+type claims struct {
+	Role string `json:"role,omitempty"`
+	jwt.RegisteredClaims
+}
+
+func New(secret string, ttl time.Duration) *Manager {
+	return &Manager{secret: []byte(secret), ttl: ttl}
+}
+
+// Issue returns a token for subject with the given role that expires ttl
+// after now.
+func (m *Manager) Issue(subject string, role string, now time.Time) (string, time.Time, error) {
+	const op = "lib.jwt.Issue"
+
+	expiresAt := now.Add(m.ttl)
+
+	token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, claims{
+		Role: role,
+		RegisteredClaims: jwt.RegisteredClaims{
+			Subject:   subject,
+			IssuedAt:  jwt.NewNumericDate(now),
+			ExpiresAt: jwt.NewNumericDate(expiresAt),
+		},
+	}).SignedString(m.secret)
+	if err != nil {
+		return "", time.Time{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return token, expiresAt, nil
+}
+
+// Parse verifies the token signature and expiry and returns its subject and
+// role.
+func (m *Manager) Parse(token string) (string, string, error) {
+	const op = "lib.jwt.Parse"
+
+	var c claims
+
+	_, err := jwt.ParseWithClaims(token, &c, func(*jwt.Token) (any, error) {
+		return m.secret, nil
+	}, jwt.WithValidMethods([]string{jwt.SigningMethodHS256.Alg()}), jwt.WithExpirationRequired())
+	if err != nil {
+		return "", "", fmt.Errorf("%s: %w: %w", op, ErrInvalidToken, err)
+	}
+
+	return c.Subject, c.Role, nil
+}
@@ -0,0 +1,59 @@
+// Package listener opens the network listeners the servers accept
+// connections on.
+package listener
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"net"
+	"os"
+	"strings"
+)
+
+// unixPrefix marks addresses of Unix domain sockets, e.g.
+// unix:/run/url-shortener.sock.
+const unixPrefix = "unix:"
+
+// Listen listens on addr: a TCP host:port or the path of a Unix socket
+// after "unix:". A socket file left behind by a previous run is replaced,
+// and the new one gets mode so that a reverse proxy running as another
+// user can connect to it.
+func Listen(addr string, mode fs.FileMode) (net.Listener, error) {
+	const op = "lib.listener.Listen"
+
+	path, ok := strings.CutPrefix(addr, unixPrefix)
+	if !ok {
+		l, err := net.Listen("tcp", addr)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", op, err)
+		}
+		return l, nil
+	}
+
+	if path == "" {
+		return nil, fmt.Errorf("%s: empty unix socket path", op)
+	}
+
+	if info, err := os.Lstat(path); err == nil {
+		if info.Mode().Type() != fs.ModeSocket {
+			return nil, fmt.Errorf("%s: %s exists and is not a socket", op, path)
+		}
+		if err := os.Remove(path); err != nil {
+			return nil, fmt.Errorf("%s: %w", op, err)
+		}
+	} else if !errors.Is(err, fs.ErrNotExist) {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	l, err := net.Listen("unix", path)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	if err := os.Chmod(path, mode); err != nil {
+		l.Close()
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return l, nil
+}
@@ -0,0 +1,54 @@
+package listener_test
+
+import (
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"url-shortener/internal/lib/listener"
+)
+
+func TestListen_TCP(t *testing.T) {
+	l, err := listener.Listen("127.0.0.1:0", 0o660)
+	require.NoError(t, err)
+	defer l.Close()
+
+	require.Equal(t, "tcp", l.Addr().Network())
+}
+
+func TestListen_Unix(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "url-shortener.sock")
+
+	// A socket left by a crashed run is replaced.
+	stale, err := net.Listen("unix", path)
+	require.NoError(t, err)
+	stale.(*net.UnixListener).SetUnlinkOnClose(false)
+	require.NoError(t, stale.Close())
+
+	l, err := listener.Listen("unix:"+path, 0o660)
+	require.NoError(t, err)
+	defer l.Close()
+
+	info, err := os.Stat(path)
+	require.NoError(t, err)
+	require.Equal(t, os.FileMode(0o660), info.Mode().Perm())
+
+	conn, err := net.Dial("unix", path)
+	require.NoError(t, err)
+	require.NoError(t, conn.Close())
+}
+
+func TestListen_NotASocket(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "storage.db")
+	require.NoError(t, os.WriteFile(path, []byte("data"), 0o600))
+
+	_, err := listener.Listen("unix:"+path, 0o660)
+	require.Error(t, err)
+
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	require.Equal(t, "data", string(data))
+}
@@ -0,0 +1,195 @@
+// Package rotate provides an io.Writer that appends to a file and moves it
+// aside once it grows too big or a new period starts, keeping a limited
+// number of old files.
+package rotate
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+// backupLayout timestamps old files, which sort by name in the order they
+// were rotated: app.log becomes app-2024-05-01T12-00-00.000.log.
+const backupLayout = "2006-01-02T15-04-05.000"
+
+type Options struct {
+	// MaxSize is the size, in bytes, a file may reach; 0 means no limit.
+	MaxSize int64
+	// Interval starts a new file every period of that length, counted from
+	// the Unix epoch, so 24h rotates at midnight UTC; 0 disables it.
+	Interval time.Duration
+	// MaxBackups is how many old files are kept and MaxAge for how long;
+	// 0 keeps them all.
+	MaxBackups int
+	MaxAge     time.Duration
+}
+
+// Writer writes to a file, rotating it as Options say. It is safe for
+// concurrent use.
+type Writer struct {
+	path string
+	opts Options
+
+	mu     sync.Mutex
+	f      *os.File
+	size   int64
+	period time.Time
+}
+
+// Open opens path for appending, creating it and its directory if needed.
+func Open(path string, opts Options) (*Writer, error) {
+	const op = "rotate.Open"
+
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	w := &Writer{path: path, opts: opts}
+	if err := w.open(); err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return w, nil
+}
+
+func (w *Writer) open() error {
+	f, err := os.OpenFile(w.path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o644)
+	if err != nil {
+		return err
+	}
+	info, err := f.Stat()
+	if err != nil {
+		_ = f.Close()
+		return err
+	}
+
+	w.f = f
+	w.size = info.Size()
+	// A file last written in an earlier period is rotated on the first
+	// write.
+	w.period = w.periodOf(info.ModTime())
+	if info.Size() == 0 {
+		w.period = w.periodOf(time.Now())
+	}
+
+	return nil
+}
+
+func (w *Writer) periodOf(t time.Time) time.Time {
+	if w.opts.Interval <= 0 {
+		return time.Time{}
+	}
+	return t.Truncate(w.opts.Interval)
+}
+
+// Write writes p to the file, rotating it first if p would take it over
+// MaxSize or a new period has started. A single write is never split, so
+// one bigger than MaxSize gets a file of its own.
+func (w *Writer) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.f == nil {
+		return 0, fs.ErrClosed
+	}
+
+	tooBig := w.opts.MaxSize > 0 && w.size > 0 && w.size+int64(len(p)) > w.opts.MaxSize
+	if tooBig || w.periodOf(time.Now()) != w.period {
+		if err := w.rotate(); err != nil {
+			return 0, err
+		}
+	}
+
+	n, err := w.f.Write(p)
+	w.size += int64(n)
+	return n, err
+}
+
+// Close closes the file. Writes after it fail.
+func (w *Writer) Close() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.f == nil {
+		return nil
+	}
+	err := w.f.Close()
+	w.f = nil
+	return err
+}
+
+func (w *Writer) rotate() error {
+	if err := w.f.Close(); err != nil {
+		return err
+	}
+	w.f = nil
+
+	prefix, ext := w.parts()
+	stamp := time.Now().UTC()
+	backup := prefix + stamp.Format(backupLayout) + ext
+	// Rotations within the same millisecond must not overwrite each other.
+	for exists(backup) {
+		stamp = stamp.Add(time.Millisecond)
+		backup = prefix + stamp.Format(backupLayout) + ext
+	}
+	if err := os.Rename(w.path, backup); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return err
+	}
+	if err := w.open(); err != nil {
+		return err
+	}
+
+	w.prune()
+	return nil
+}
+
+func exists(path string) bool {
+	_, err := os.Lstat(path)
+	return err == nil
+}
+
+// parts splits the path into what goes before and after the timestamp of
+// an old file.
+func (w *Writer) parts() (prefix, ext string) {
+	ext = filepath.Ext(w.path)
+	return strings.TrimSuffix(w.path, ext) + "-", ext
+}
+
+// prune removes old files beyond MaxBackups or older than MaxAge. Failures
+// are ignored: the log still works, the disk just fills up sooner.
+func (w *Writer) prune() {
+	if w.opts.MaxBackups <= 0 && w.opts.MaxAge <= 0 {
+		return
+	}
+
+	prefix, ext := w.parts()
+	backups, err := filepath.Glob(prefix + "*" + ext)
+	if err != nil {
+		return
+	}
+	backups = slices.DeleteFunc(backups, func(name string) bool {
+		stamp := strings.TrimSuffix(strings.TrimPrefix(name, prefix), ext)
+		_, err := time.Parse(backupLayout, stamp)
+		return err != nil
+	})
+	slices.Sort(backups)
+	slices.Reverse(backups)
+
+	for i, name := range backups {
+		expired := false
+		if w.opts.MaxAge > 0 {
+			info, err := os.Stat(name)
+			expired = err == nil && time.Since(info.ModTime()) > w.opts.MaxAge
+		}
+		if (w.opts.MaxBackups > 0 && i >= w.opts.MaxBackups) || expired {
+			_ = os.Remove(name)
+		}
+	}
+}
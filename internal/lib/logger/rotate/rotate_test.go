@@ -0,0 +1,87 @@
+package rotate_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"url-shortener/internal/lib/logger/rotate"
+)
+
+func TestWriter_MaxSize(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "logs", "app.log")
+
+	w, err := rotate.Open(path, rotate.Options{MaxSize: 10, MaxBackups: 2})
+	require.NoError(t, err)
+	defer w.Close()
+
+	for _, line := range []string{"first\n", "second\n", "third\n", "fourth\n"} {
+		_, err := w.Write([]byte(line))
+		require.NoError(t, err)
+	}
+
+	current, err := os.ReadFile(path)
+	require.NoError(t, err)
+	require.Equal(t, "fourth\n", string(current))
+
+	backups, err := filepath.Glob(filepath.Join(dir, "logs", "app-*.log"))
+	require.NoError(t, err)
+	require.Len(t, backups, 2)
+
+	// The oldest file, with "first", is pruned.
+	var kept []string
+	for _, b := range backups {
+		data, err := os.ReadFile(b)
+		require.NoError(t, err)
+		kept = append(kept, string(data))
+	}
+	require.Equal(t, []string{"second\n", "third\n"}, kept)
+}
+
+func TestWriter_Interval(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "app.log")
+
+	w, err := rotate.Open(path, rotate.Options{Interval: 50 * time.Millisecond})
+	require.NoError(t, err)
+	defer w.Close()
+
+	_, err = w.Write([]byte("before\n"))
+	require.NoError(t, err)
+	time.Sleep(60 * time.Millisecond)
+	_, err = w.Write([]byte("after\n"))
+	require.NoError(t, err)
+
+	current, err := os.ReadFile(path)
+	require.NoError(t, err)
+	require.Equal(t, "after\n", string(current))
+
+	backups, err := filepath.Glob(filepath.Join(dir, "app-*.log"))
+	require.NoError(t, err)
+	require.Len(t, backups, 1)
+	data, err := os.ReadFile(backups[0])
+	require.NoError(t, err)
+	require.Equal(t, "before\n", string(data))
+}
+
+func TestWriter_Append(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "app.log")
+	require.NoError(t, os.WriteFile(path, []byte("old\n"), 0o644))
+
+	w, err := rotate.Open(path, rotate.Options{MaxSize: 100})
+	require.NoError(t, err)
+	_, err = w.Write([]byte("new\n"))
+	require.NoError(t, err)
+	require.NoError(t, w.Close())
+
+	_, err = w.Write([]byte("closed\n"))
+	require.Error(t, err)
+
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	require.Equal(t, "old\nnew\n", string(data))
+}
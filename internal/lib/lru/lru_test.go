@@ -0,0 +1,68 @@
+package lru_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"url-shortener/internal/lib/lru"
+)
+
+func TestCache_Evicts(t *testing.T) {
+	c := lru.New[string, int](2, 0)
+
+	c.Add("a", 1)
+	c.Add("b", 2)
+
+	// Reading a makes b the least recently used.
+	v, ok := c.Get("a")
+	require.True(t, ok)
+	require.Equal(t, 1, v)
+
+	c.Add("c", 3)
+
+	_, ok = c.Get("b")
+	require.False(t, ok)
+	_, ok = c.Get("a")
+	require.True(t, ok)
+	_, ok = c.Get("c")
+	require.True(t, ok)
+	require.Equal(t, 2, c.Len())
+
+	c.Remove("a")
+	_, ok = c.Get("a")
+	require.False(t, ok)
+}
+
+func TestCache_TTL(t *testing.T) {
+	c := lru.New[string, int](10, 50*time.Millisecond)
+
+	c.Add("a", 1)
+	_, ok := c.Get("a")
+	require.True(t, ok)
+
+	time.Sleep(60 * time.Millisecond)
+
+	_, ok = c.Get("a")
+	require.False(t, ok)
+	require.Zero(t, c.Len())
+}
+
+func TestCache_Purge(t *testing.T) {
+	c := lru.New[string, int](10, 0)
+
+	c.Add("a", 1)
+	c.Add("b", 2)
+	c.Purge()
+
+	require.Zero(t, c.Len())
+	_, ok := c.Get("a")
+	require.False(t, ok)
+
+	// The cache stays usable.
+	c.Add("c", 3)
+	v, ok := c.Get("c")
+	require.True(t, ok)
+	require.Equal(t, 3, v)
+}
@@ -0,0 +1,230 @@
+package metadata
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"syscall"
+	"time"
+	"unicode/utf8"
+	"url-shortener/internal/storage"
+
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/charset"
+)
+
+const (
+	// maxBodySize limits how much of the page is read; the head of any
+	// sane page fits well within it.
+	maxBodySize = 1 << 20
+	// maxFieldLength limits stored values, in characters.
+	maxFieldLength = 512
+
+	userAgent = "url-shortener-metadata/1.0"
+)
+
+var ErrForbiddenAddress = errors.New("address is not public")
+
+// Fetcher reads the title and Open Graph tags of web pages.
+type Fetcher struct {
+	client *http.Client
+}
+
+// New returns a Fetcher that makes requests with client, usually one from
+// NewClient.
+func New(client *http.Client) *Fetcher {
+	return &Fetcher{client: client}
+}
+
+// NewClient returns an HTTP client for fetching user-supplied URLs: it
+// gives up after timeout and refuses to connect to loopback, private and
+// other non-public addresses, so links cannot be used to probe the
+// server's own network.
+func NewClient(timeout time.Duration) *http.Client {
+	dialer := &net.Dialer{
+		Timeout: timeout,
+		Control: func(_, address string, _ syscall.RawConn) error {
+			host, _, err := net.SplitHostPort(address)
+			if err != nil {
+				return err
+			}
+			ip := net.ParseIP(host)
+			if ip == nil || !public(ip) {
+				return fmt.Errorf("%w: %s", ErrForbiddenAddress, host)
+			}
+			return nil
+		},
+	}
+
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.Proxy = nil
+	transport.DialContext = dialer.DialContext
+
+	return &http.Client{Timeout: timeout, Transport: transport}
+}
+
+func public(ip net.IP) bool {
+	return !ip.IsLoopback() && !ip.IsPrivate() && !ip.IsUnspecified() &&
+		!ip.IsLinkLocalUnicast() && !ip.IsLinkLocalMulticast() &&
+		!ip.IsInterfaceLocalMulticast() && !ip.IsMulticast()
+}
+
+// Fetch downloads the page at rawURL and extracts its metadata. Pages that
+// are not HTML yield empty metadata and no error.
+func (f *Fetcher) Fetch(ctx context.Context, rawURL string) (storage.Metadata, error) {
+	const op = "lib.metadata.Fetch"
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, rawURL, nil)
+	if err != nil {
+		return storage.Metadata{}, fmt.Errorf("%s: %w", op, err)
+	}
+	req.Header.Set("User-Agent", userAgent)
+	req.Header.Set("Accept", "text/html,application/xhtml+xml")
+
+	res, err := f.client.Do(req)
+	if err != nil {
+		return storage.Metadata{}, fmt.Errorf("%s: %w", op, err)
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode < 200 || res.StatusCode > 299 {
+		return storage.Metadata{}, fmt.Errorf("%s: unexpected status %d", op, res.StatusCode)
+	}
+
+	contentType := res.Header.Get("Content-Type")
+	if mediaType, _, _ := mime.ParseMediaType(contentType); contentType != "" &&
+		mediaType != "text/html" && mediaType != "application/xhtml+xml" {
+		return storage.Metadata{}, nil
+	}
+
+	body, err := charset.NewReader(io.LimitReader(res.Body, maxBodySize), contentType)
+	if err != nil {
+		return storage.Metadata{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	// Relative og:image URLs are resolved against the page we ended up on
+	// after redirects.
+	return Parse(body, res.Request.URL), nil
+}
+
+// Parse extracts metadata from the head of an HTML document. Open Graph
+// tags take precedence over <title> and <meta name="description">.
+func Parse(r io.Reader, base *url.URL) storage.Metadata {
+	var (
+		m               storage.Metadata
+		title, desc     string
+		inTitle         bool
+		ogTitle, ogDesc string
+		ogImage         string
+	)
+
+	z := html.NewTokenizer(r)
+loop:
+	for {
+		tt := z.Next()
+		switch tt {
+		case html.ErrorToken:
+			break loop
+		case html.TextToken:
+			if inTitle {
+				title += string(z.Text())
+			}
+		case html.EndTagToken:
+			name, _ := z.TagName()
+			switch string(name) {
+			case "title":
+				inTitle = false
+			case "head":
+				break loop
+			}
+		case html.StartTagToken, html.SelfClosingTagToken:
+			name, hasAttr := z.TagName()
+			switch string(name) {
+			case "title":
+				inTitle = tt == html.StartTagToken && title == ""
+			case "body":
+				break loop
+			case "meta":
+				if !hasAttr {
+					continue
+				}
+				attrs := attributes(z)
+				content := attrs["content"]
+				switch {
+				case attrs["property"] == "og:title":
+					ogTitle = content
+				case attrs["property"] == "og:description":
+					ogDesc = content
+				case attrs["property"] == "og:image":
+					ogImage = content
+				case strings.EqualFold(attrs["name"], "description"):
+					desc = content
+				}
+			}
+		}
+	}
+
+	m.Title = clean(first(ogTitle, title))
+	m.Description = clean(first(ogDesc, desc))
+	if ogImage != "" {
+		m.Image = absolute(base, strings.TrimSpace(ogImage))
+	}
+
+	return m
+}
+
+func attributes(z *html.Tokenizer) map[string]string {
+	attrs := make(map[string]string)
+	for {
+		key, val, more := z.TagAttr()
+		attrs[strings.ToLower(string(key))] = string(val)
+		if !more {
+			return attrs
+		}
+	}
+}
+
+func first(values ...string) string {
+	for _, v := range values {
+		if strings.TrimSpace(v) != "" {
+			return v
+		}
+	}
+	return ""
+}
+
+// clean collapses whitespace and truncates s to maxFieldLength characters.
+func clean(s string) string {
+	s = strings.Join(strings.Fields(s), " ")
+	if !utf8.ValidString(s) {
+		s = strings.ToValidUTF8(s, "")
+	}
+	if utf8.RuneCountInString(s) > maxFieldLength {
+		s = string([]rune(s)[:maxFieldLength])
+	}
+	return s
+}
+
+// absolute resolves ref against base and keeps only http(s) URLs.
+func absolute(base *url.URL, ref string) string {
+	u, err := url.Parse(ref)
+	if err != nil {
+		return ""
+	}
+	if base != nil {
+		u = base.ResolveReference(u)
+	}
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return ""
+	}
+	if s := u.String(); len(s) <= 2048 {
+		return s
+	}
+	return ""
+}
@@ -0,0 +1,108 @@
+package metadata_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"url-shortener/internal/lib/metadata"
+	"url-shortener/internal/storage"
+)
+
+func TestParse(t *testing.T) {
+	base, err := url.Parse("https://example.com/articles/1")
+	require.NoError(t, err)
+
+	cases := []struct {
+		name string
+		html string
+		want storage.Metadata
+	}{
+		{
+			name: "Open Graph",
+			html: `<html><head>
+				<title>Page title</title>
+				<meta name="description" content="Plain description">
+				<meta property="og:title" content="OG title">
+				<meta property="og:description" content="OG description">
+				<meta property="og:image" content="/img/cover.png">
+			</head><body></body></html>`,
+			want: storage.Metadata{
+				Title:       "OG title",
+				Description: "OG description",
+				Image:       "https://example.com/img/cover.png",
+			},
+		},
+		{
+			name: "Title and description",
+			html: `<head><title>
+				Tom &amp; Jerry
+			</title><meta name="Description" content="Cartoon"></head>`,
+			want: storage.Metadata{Title: "Tom & Jerry", Description: "Cartoon"},
+		},
+		{
+			name: "Body is ignored",
+			html: `<head></head><body><title>Not a title</title></body>`,
+			want: storage.Metadata{},
+		},
+		{
+			name: "Unsafe image",
+			html: `<meta property="og:image" content="javascript:alert(1)">`,
+			want: storage.Metadata{},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			require.Equal(t, tc.want, metadata.Parse(strings.NewReader(tc.html), base))
+		})
+	}
+}
+
+func TestFetcher_Fetch(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/page", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html; charset=windows-1251")
+		// "Привет" in windows-1251.
+		_, _ = w.Write([]byte("<title>\xcf\xf0\xe8\xe2\xe5\xf2</title>"))
+	})
+	mux.HandleFunc("/file", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/pdf")
+		_, _ = w.Write([]byte("<title>PDF</title>"))
+	})
+	mux.HandleFunc("/missing", http.NotFound)
+
+	ts := httptest.NewServer(mux)
+	defer ts.Close()
+
+	f := metadata.New(ts.Client())
+
+	m, err := f.Fetch(context.Background(), ts.URL+"/page")
+	require.NoError(t, err)
+	require.Equal(t, "Привет", m.Title)
+
+	m, err = f.Fetch(context.Background(), ts.URL+"/file")
+	require.NoError(t, err)
+	require.Equal(t, storage.Metadata{}, m)
+
+	_, err = f.Fetch(context.Background(), ts.URL+"/missing")
+	require.Error(t, err)
+}
+
+func TestNewClient_RefusesPrivateAddresses(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		t.Error("request reached a loopback server")
+	}))
+	defer ts.Close()
+
+	f := metadata.New(metadata.NewClient(time.Second))
+
+	_, err := f.Fetch(context.Background(), ts.URL)
+	require.ErrorIs(t, err, metadata.ErrForbiddenAddress)
+}
@@ -0,0 +1,219 @@
+// Package privacy strips personal data before it is logged or stored:
+// client IPs are truncated or hashed and secrets in URLs redacted.
+package privacy
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"log/slog"
+	"net"
+	"net/url"
+	"slices"
+	"strings"
+)
+
+// IP modes.
+const (
+	// IPTruncate zeroes the last octet of IPv4 addresses and all but the
+	// first 48 bits of IPv6 ones, which still tells the network apart.
+	IPTruncate = "truncate"
+	// IPHash replaces addresses with a keyed hash, which still tells
+	// visitors apart.
+	IPHash = "hash"
+)
+
+// Redacted replaces the values of secret query parameters.
+const Redacted = "REDACTED"
+
+// secretParams are query parameters that usually carry credentials or
+// personal data.
+var secretParams = []string{
+	"token", "access_token", "id_token", "refresh_token", "auth", "code",
+	"key", "api_key", "apikey", "secret", "client_secret",
+	"password", "passwd", "pwd", "pass",
+	"signature", "sig", "session", "sessionid", "sid", "email",
+}
+
+// Keys of log attributes holding client IPs and URLs.
+var (
+	ipKeys  = []string{"remote_addr", "client_ip", "ip"}
+	urlKeys = []string{"url", "destination", "referrer"}
+)
+
+type Options struct {
+	// IPMode is IPTruncate or IPHash.
+	IPMode string
+	// IPSalt keys the hash; empty picks a random one, so hashes differ
+	// between runs.
+	IPSalt string
+	// SecretParams are redacted on top of the built-in token, key,
+	// password and the like.
+	SecretParams []string
+}
+
+type Anonymizer struct {
+	mode   string
+	salt   []byte
+	params []string
+}
+
+func New(opts Options) (*Anonymizer, error) {
+	const op = "privacy.New"
+
+	a := &Anonymizer{mode: opts.IPMode, salt: []byte(opts.IPSalt)}
+	switch opts.IPMode {
+	case IPTruncate:
+	case IPHash:
+		if len(a.salt) == 0 {
+			a.salt = make([]byte, 32)
+			_, _ = rand.Read(a.salt)
+		}
+	default:
+		return nil, fmt.Errorf("%s: unknown ip mode %q", op, opts.IPMode)
+	}
+
+	a.params = slices.Clone(secretParams)
+	for _, p := range opts.SecretParams {
+		a.params = append(a.params, strings.ToLower(strings.TrimSpace(p)))
+	}
+
+	return a, nil
+}
+
+// IP anonymizes addr, an IP with or without a port; the port is dropped.
+// Anything else, such as a Unix socket path, is returned as is.
+func (a *Anonymizer) IP(addr string) string {
+	host := addr
+	if h, _, err := net.SplitHostPort(addr); err == nil {
+		host = h
+	}
+	ip := net.ParseIP(host)
+	if ip == nil {
+		return addr
+	}
+
+	if a.mode == IPHash {
+		mac := hmac.New(sha256.New, a.salt)
+		mac.Write(ip.To16())
+		return hex.EncodeToString(mac.Sum(nil)[:16])
+	}
+
+	if v4 := ip.To4(); v4 != nil {
+		return v4.Mask(net.CIDRMask(24, 32)).String()
+	}
+	return ip.Mask(net.CIDRMask(48, 128)).String()
+}
+
+// URL redacts the password of rawURL and the values of secret parameters
+// in its query and fragment. A URL that does not parse is returned as is
+// if it has no query, and cut at "?" otherwise.
+func (a *Anonymizer) URL(rawURL string) string {
+	if !strings.ContainsAny(rawURL, "?#@") {
+		return rawURL
+	}
+
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		before, _, _ := strings.Cut(rawURL, "?")
+		return before
+	}
+
+	if _, ok := u.User.Password(); ok {
+		u.User = url.UserPassword(u.User.Username(), Redacted)
+	}
+	u.RawQuery = a.query(u.RawQuery)
+	// OAuth implicit flows put tokens in the fragment.
+	if strings.Contains(u.Fragment, "=") {
+		u.RawFragment = ""
+		u.Fragment = a.query(u.Fragment)
+	}
+
+	return u.String()
+}
+
+func (a *Anonymizer) query(raw string) string {
+	if raw == "" {
+		return raw
+	}
+
+	parts := strings.Split(raw, "&")
+	for i, part := range parts {
+		name, _, ok := strings.Cut(part, "=")
+		if !ok {
+			continue
+		}
+		if decoded, err := url.QueryUnescape(name); err == nil {
+			name = decoded
+		}
+		if slices.Contains(a.params, strings.ToLower(name)) {
+			parts[i] = part[:strings.Index(part, "=")+1] + Redacted
+		}
+	}
+
+	return strings.Join(parts, "&")
+}
+
+// Handler returns a slog.Handler that anonymizes client IPs and URLs in
+// the attributes of records before passing them to next. Attributes are
+// recognised by key: remote_addr, client_ip and ip hold IPs, url,
+// destination and referrer URLs.
+func (a *Anonymizer) Handler(next slog.Handler) slog.Handler {
+	return &handler{next: next, a: a}
+}
+
+type handler struct {
+	next slog.Handler
+	a    *Anonymizer
+}
+
+func (h *handler) Enabled(ctx context.Context, level slog.Level) bool {
+	return h.next.Enabled(ctx, level)
+}
+
+func (h *handler) Handle(ctx context.Context, r slog.Record) error {
+	scrubbed := slog.NewRecord(r.Time, r.Level, r.Message, r.PC)
+	r.Attrs(func(attr slog.Attr) bool {
+		scrubbed.AddAttrs(h.a.attr(attr))
+		return true
+	})
+
+	return h.next.Handle(ctx, scrubbed)
+}
+
+func (h *handler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	scrubbed := make([]slog.Attr, len(attrs))
+	for i, attr := range attrs {
+		scrubbed[i] = h.a.attr(attr)
+	}
+
+	return &handler{next: h.next.WithAttrs(scrubbed), a: h.a}
+}
+
+func (h *handler) WithGroup(name string) slog.Handler {
+	return &handler{next: h.next.WithGroup(name), a: h.a}
+}
+
+func (a *Anonymizer) attr(attr slog.Attr) slog.Attr {
+	attr.Value = attr.Value.Resolve()
+
+	switch {
+	case attr.Value.Kind() == slog.KindGroup:
+		group := attr.Value.Group()
+		scrubbed := make([]slog.Attr, len(group))
+		for i, ga := range group {
+			scrubbed[i] = a.attr(ga)
+		}
+		attr.Value = slog.GroupValue(scrubbed...)
+	case attr.Value.Kind() != slog.KindString:
+	case slices.Contains(ipKeys, attr.Key):
+		attr.Value = slog.StringValue(a.IP(attr.Value.String()))
+	case slices.Contains(urlKeys, attr.Key):
+		attr.Value = slog.StringValue(a.URL(attr.Value.String()))
+	}
+
+	return attr
+}
@@ -0,0 +1,102 @@
+// Package quota limits how many links a user or an API key may have and
+// create per day.
+package quota
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"url-shortener/internal/storage"
+)
+
+var ErrExceeded = errors.New("quota exceeded")
+
+// Counter counts saved links.
+type Counter interface {
+	CountURLs(ctx context.Context, filter storage.ListFilter) (int64, error)
+}
+
+// Limits are the quotas of every user and API key; zero means unlimited.
+type Limits struct {
+	// MaxLinks caps the links one owns at a time; deleted links are not
+	// counted.
+	MaxLinks int64
+	// MaxPerDay caps the links created per UTC day.
+	MaxPerDay int64
+}
+
+// Usage is what a user or an API key has used of its quota. Limits of 0
+// are unlimited.
+type Usage struct {
+	Links     int64 `json:"links"`
+	MaxLinks  int64 `json:"max_links,omitempty"`
+	Today     int64 `json:"today"`
+	MaxPerDay int64 `json:"max_per_day,omitempty"`
+}
+
+// Quota checks Limits against the links in storage.
+type Quota struct {
+	counter Counter
+	limits  Limits
+	now     func() time.Time
+}
+
+func New(counter Counter, limits Limits) *Quota {
+	return &Quota{counter: counter, limits: limits, now: time.Now}
+}
+
+// Enabled reports whether any limit is set.
+func (q *Quota) Enabled() bool {
+	return q.limits != Limits{}
+}
+
+// Usage counts the links of the API key apiKeyID, or of owner if apiKeyID
+// is 0. With neither, e.g. for admins, nothing is counted and the usage
+// is unlimited.
+func (q *Quota) Usage(ctx context.Context, owner string, apiKeyID int64) (Usage, error) {
+	const op = "lib.quota.Usage"
+
+	if !q.Enabled() || (owner == "" && apiKeyID == 0) {
+		return Usage{}, nil
+	}
+
+	filter := storage.ListFilter{Owner: owner}
+	if apiKeyID != 0 {
+		filter = storage.ListFilter{APIKeyID: apiKeyID}
+	}
+
+	usage := Usage{MaxLinks: q.limits.MaxLinks, MaxPerDay: q.limits.MaxPerDay}
+
+	var err error
+	if q.limits.MaxLinks > 0 {
+		if usage.Links, err = q.counter.CountURLs(ctx, filter); err != nil {
+			return Usage{}, fmt.Errorf("%s: %w", op, err)
+		}
+	}
+	if q.limits.MaxPerDay > 0 {
+		filter.CreatedFrom = q.now().UTC().Truncate(24 * time.Hour)
+		if usage.Today, err = q.counter.CountURLs(ctx, filter); err != nil {
+			return Usage{}, fmt.Errorf("%s: %w", op, err)
+		}
+	}
+
+	return usage, nil
+}
+
+// Check returns ErrExceeded, along with the usage, if n more links would
+// go over a limit.
+func (q *Quota) Check(ctx context.Context, owner string, apiKeyID int64, n int) (Usage, error) {
+	usage, err := q.Usage(ctx, owner, apiKeyID)
+	if err != nil {
+		return Usage{}, err
+	}
+
+	if usage.MaxLinks > 0 && usage.Links+int64(n) > usage.MaxLinks ||
+		usage.MaxPerDay > 0 && usage.Today+int64(n) > usage.MaxPerDay {
+		return usage, ErrExceeded
+	}
+
+	return usage, nil
+}
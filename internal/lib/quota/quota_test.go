@@ -0,0 +1,83 @@
+package quota_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"url-shortener/internal/lib/quota"
+	"url-shortener/internal/storage"
+)
+
+// counter counts daily links (CreatedFrom set) and all links separately.
+type counter struct {
+	links, today int64
+	err          error
+	filters      []storage.ListFilter
+}
+
+func (c *counter) CountURLs(_ context.Context, filter storage.ListFilter) (int64, error) {
+	c.filters = append(c.filters, filter)
+	if !filter.CreatedFrom.IsZero() {
+		return c.today, c.err
+	}
+	return c.links, c.err
+}
+
+func TestQuota_Check(t *testing.T) {
+	cases := []struct {
+		name    string
+		limits  quota.Limits
+		links   int64
+		today   int64
+		n       int
+		wantErr error
+	}{
+		{name: "Unlimited", links: 1000, today: 1000, n: 1},
+		{name: "Under limits", limits: quota.Limits{MaxLinks: 10, MaxPerDay: 5}, links: 9, today: 4, n: 1},
+		{name: "Links exceeded", limits: quota.Limits{MaxLinks: 10}, links: 10, n: 1, wantErr: quota.ErrExceeded},
+		{name: "Day exceeded", limits: quota.Limits{MaxPerDay: 5}, today: 3, n: 3, wantErr: quota.ErrExceeded},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			c := &counter{links: tc.links, today: tc.today}
+			q := quota.New(c, tc.limits)
+
+			usage, err := q.Check(context.Background(), "alice", 0, tc.n)
+			require.ErrorIs(t, err, tc.wantErr)
+			require.Equal(t, tc.limits.MaxLinks, usage.MaxLinks)
+			require.Equal(t, tc.limits.MaxPerDay, usage.MaxPerDay)
+		})
+	}
+}
+
+func TestQuota_Usage(t *testing.T) {
+	ctx := context.Background()
+
+	c := &counter{links: 7, today: 2}
+	q := quota.New(c, quota.Limits{MaxLinks: 10, MaxPerDay: 5})
+
+	usage, err := q.Usage(ctx, "alice", 3)
+	require.NoError(t, err)
+	require.Equal(t, quota.Usage{Links: 7, MaxLinks: 10, Today: 2, MaxPerDay: 5}, usage)
+
+	// The API key is counted on its own, not with the rest of the user's links.
+	require.Len(t, c.filters, 2)
+	require.Equal(t, storage.ListFilter{APIKeyID: 3}, c.filters[0])
+	require.Equal(t, int64(3), c.filters[1].APIKeyID)
+	require.Empty(t, c.filters[1].Owner)
+
+	// Admins authenticated without a key own every link and are not limited.
+	c.filters = nil
+	usage, err = q.Usage(ctx, "", 0)
+	require.NoError(t, err)
+	require.Equal(t, quota.Usage{}, usage)
+	require.Empty(t, c.filters)
+
+	c.err = errors.New("unexpected error")
+	_, err = q.Usage(ctx, "alice", 0)
+	require.ErrorIs(t, err, c.err)
+}
@@ -5,16 +5,24 @@ import (
 	"time"
 )
 
+// DefaultAlphabet is used by NewRandomString.
+const DefaultAlphabet = "ABCDEFGHIJKLMNOPQRSTUVWXYZ" +
+	"abcdefghijklmnopqrstuvwxyz" +
+	"0123456789"
+
 func NewRandomString(size int) string {
+	return NewString(size, DefaultAlphabet)
+}
+
+// NewString returns size characters picked from alphabet.
+func NewString(size int, alphabet string) string {
 	rnd := rand.New(rand.NewSource(time.Now().UnixNano()))
 
-	chars := []rune("ABCDEFGHIJKLMNOPQRSTUVWXYZ" +
-		"abcdefghijklmnopqrstuvwxyz" +
-		"0123456789")
+	chars := []rune(alphabet)
 
 	b := make([]rune, size)
 	for i := range b {
 		b[i] = chars[rnd.Intn(len(chars))]
 	}
 	return string(b)
-}
\ No newline at end of file
+}
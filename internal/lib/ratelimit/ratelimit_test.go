@@ -0,0 +1,43 @@
+package ratelimit_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"url-shortener/internal/lib/ratelimit"
+)
+
+func TestLimiter_Allow(t *testing.T) {
+	l := ratelimit.New(2, 3)
+	now := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	for i := 0; i < 3; i++ {
+		ok, _ := l.Allow("alice", now)
+		require.True(t, ok, "request %d within burst", i)
+	}
+
+	ok, wait := l.Allow("alice", now)
+	require.False(t, ok)
+	require.Equal(t, 500*time.Millisecond, wait)
+
+	// Other keys have their own bucket.
+	ok, _ = l.Allow("bob", now)
+	require.True(t, ok)
+
+	ok, _ = l.Allow("alice", now.Add(500*time.Millisecond))
+	require.True(t, ok)
+
+	ok, _ = l.Allow("alice", now.Add(500*time.Millisecond))
+	require.False(t, ok)
+
+	// The bucket never holds more than burst tokens.
+	later := now.Add(time.Hour)
+	for i := 0; i < 3; i++ {
+		ok, _ := l.Allow("alice", later)
+		require.True(t, ok)
+	}
+	ok, _ = l.Allow("alice", later)
+	require.False(t, ok)
+}
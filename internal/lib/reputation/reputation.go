@@ -0,0 +1,219 @@
+// Package reputation checks URLs against lists of known malware and
+// phishing sites. Checkers take a batch of URLs and return a threat type
+// for each of them, empty for URLs that are not flagged.
+package reputation
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+)
+
+// ThreatBlocklisted is reported for URLs found in a local blocklist.
+const ThreatBlocklisted = "BLOCKLISTED"
+
+// Blocklist flags URLs by a local list. An entry is either a domain, which
+// also covers its subdomains, or a URL with a scheme, which covers every URL
+// starting with it.
+type Blocklist struct {
+	domains  map[string]struct{}
+	prefixes []string
+}
+
+// NewBlocklist builds a blocklist from entries; blank entries and entries
+// starting with # are skipped.
+func NewBlocklist(entries []string) *Blocklist {
+	b := &Blocklist{domains: make(map[string]struct{})}
+	for _, entry := range entries {
+		entry = strings.TrimSpace(entry)
+		switch {
+		case entry == "" || strings.HasPrefix(entry, "#"):
+		case strings.Contains(entry, "://"):
+			b.prefixes = append(b.prefixes, entry)
+		default:
+			b.domains[strings.TrimSuffix(strings.ToLower(entry), ".")] = struct{}{}
+		}
+	}
+
+	return b
+}
+
+// LoadBlocklist reads a blocklist with one entry per line.
+func LoadBlocklist(path string) (*Blocklist, error) {
+	const op = "lib.reputation.LoadBlocklist"
+
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	defer f.Close()
+
+	var entries []string
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		entries = append(entries, scanner.Text())
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return NewBlocklist(entries), nil
+}
+
+func (b *Blocklist) Check(_ context.Context, urls []string) ([]string, error) {
+	threats := make([]string, len(urls))
+	for i, rawURL := range urls {
+		if b.blocked(rawURL) {
+			threats[i] = ThreatBlocklisted
+		}
+	}
+
+	return threats, nil
+}
+
+func (b *Blocklist) blocked(rawURL string) bool {
+	for _, prefix := range b.prefixes {
+		if strings.HasPrefix(rawURL, prefix) {
+			return true
+		}
+	}
+
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return false
+	}
+
+	// evil.example.com is covered by both itself and example.com.
+	host := strings.TrimSuffix(strings.ToLower(u.Hostname()), ".")
+	for host != "" {
+		if _, ok := b.domains[host]; ok {
+			return true
+		}
+		_, host, _ = strings.Cut(host, ".")
+	}
+
+	return false
+}
+
+// SafeBrowsingURL is the Lookup API endpoint of Google Safe Browsing v4.
+const SafeBrowsingURL = "https://safebrowsing.googleapis.com/v4/threatMatches:find"
+
+// safeBrowsingBatch is the most URLs the API accepts in one request.
+const safeBrowsingBatch = 500
+
+// SafeBrowsing flags URLs with the Google Safe Browsing Lookup API. Threat
+// types are those of the API, e.g. MALWARE or SOCIAL_ENGINEERING.
+type SafeBrowsing struct {
+	client   *http.Client
+	endpoint string
+	key      string
+}
+
+// NewSafeBrowsing returns a checker that calls endpoint, normally
+// SafeBrowsingURL, with the API key.
+func NewSafeBrowsing(client *http.Client, endpoint string, key string) *SafeBrowsing {
+	return &SafeBrowsing{client: client, endpoint: endpoint, key: key}
+}
+
+type threatEntry struct {
+	URL string `json:"url"`
+}
+
+type findRequest struct {
+	Client struct {
+		ClientID      string `json:"clientId"`
+		ClientVersion string `json:"clientVersion"`
+	} `json:"client"`
+	ThreatInfo struct {
+		ThreatTypes      []string      `json:"threatTypes"`
+		PlatformTypes    []string      `json:"platformTypes"`
+		ThreatEntryTypes []string      `json:"threatEntryTypes"`
+		ThreatEntries    []threatEntry `json:"threatEntries"`
+	} `json:"threatInfo"`
+}
+
+type findResponse struct {
+	Matches []struct {
+		ThreatType string      `json:"threatType"`
+		Threat     threatEntry `json:"threat"`
+	} `json:"matches"`
+}
+
+func (s *SafeBrowsing) Check(ctx context.Context, urls []string) ([]string, error) {
+	threats := make([]string, len(urls))
+	for start := 0; start < len(urls); start += safeBrowsingBatch {
+		end := min(start+safeBrowsingBatch, len(urls))
+		if err := s.check(ctx, urls[start:end], threats[start:end]); err != nil {
+			return nil, err
+		}
+	}
+
+	return threats, nil
+}
+
+func (s *SafeBrowsing) check(ctx context.Context, urls []string, threats []string) error {
+	const op = "lib.reputation.SafeBrowsing.Check"
+
+	var body findRequest
+	body.Client.ClientID = "url-shortener"
+	body.Client.ClientVersion = "1.0"
+	body.ThreatInfo.ThreatTypes = []string{
+		"MALWARE", "SOCIAL_ENGINEERING", "UNWANTED_SOFTWARE", "POTENTIALLY_HARMFUL_APPLICATION",
+	}
+	body.ThreatInfo.PlatformTypes = []string{"ANY_PLATFORM"}
+	body.ThreatInfo.ThreatEntryTypes = []string{"URL"}
+	for _, u := range urls {
+		body.ThreatInfo.ThreatEntries = append(body.ThreatInfo.ThreatEntries, threatEntry{URL: u})
+	}
+
+	payload, err := json.Marshal(body)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost,
+		s.endpoint+"?key="+url.QueryEscape(s.key), bytes.NewReader(payload))
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	res, err := s.client.Do(req)
+	if err != nil {
+		// url.Error quotes the request URL and with it the key.
+		var urlErr *url.Error
+		if errors.As(err, &urlErr) {
+			err = urlErr.Err
+		}
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		return fmt.Errorf("%s: unexpected status %d", op, res.StatusCode)
+	}
+
+	var found findResponse
+	if err := json.NewDecoder(res.Body).Decode(&found); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	index := make(map[string][]int, len(urls))
+	for i, u := range urls {
+		index[u] = append(index[u], i)
+	}
+	for _, match := range found.Matches {
+		for _, i := range index[match.Threat.URL] {
+			threats[i] = match.ThreatType
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,85 @@
+package reputation_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"url-shortener/internal/lib/reputation"
+)
+
+func TestBlocklist_Check(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "blocklist.txt")
+	require.NoError(t, os.WriteFile(path, []byte(`
+# known bad sites
+evil.example.com
+Phishing.test
+https://files.example.org/malware/
+`), 0o600))
+
+	b, err := reputation.LoadBlocklist(path)
+	require.NoError(t, err)
+
+	threats, err := b.Check(context.Background(), []string{
+		"https://evil.example.com/login",
+		"https://cdn.evil.example.com/x.js",
+		"https://example.com",
+		"http://www.phishing.test",
+		"https://files.example.org/malware/setup.exe",
+		"https://files.example.org/docs/readme.txt",
+		"https://notphishing.test",
+	})
+	require.NoError(t, err)
+	require.Equal(t, []string{
+		reputation.ThreatBlocklisted,
+		reputation.ThreatBlocklisted,
+		"",
+		reputation.ThreatBlocklisted,
+		reputation.ThreatBlocklisted,
+		"",
+		"",
+	}, threats)
+}
+
+func TestSafeBrowsing_Check(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, "secret", r.URL.Query().Get("key"))
+
+		var req struct {
+			ThreatInfo struct {
+				ThreatEntries []struct {
+					URL string `json:"url"`
+				} `json:"threatEntries"`
+			} `json:"threatInfo"`
+		}
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+		require.Len(t, req.ThreatInfo.ThreatEntries, 2)
+
+		_, _ = w.Write([]byte(`{"matches": [{"threatType": "MALWARE", "threat": {"url": "https://malware.test"}}]}`))
+	}))
+	defer ts.Close()
+
+	sb := reputation.NewSafeBrowsing(ts.Client(), ts.URL, "secret")
+
+	threats, err := sb.Check(context.Background(), []string{"https://example.com", "https://malware.test"})
+	require.NoError(t, err)
+	require.Equal(t, []string{"", "MALWARE"}, threats)
+}
+
+func TestSafeBrowsing_Error(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusForbidden)
+	}))
+	defer ts.Close()
+
+	sb := reputation.NewSafeBrowsing(ts.Client(), ts.URL, "secret")
+
+	_, err := sb.Check(context.Background(), []string{"https://example.com"})
+	require.Error(t, err)
+}
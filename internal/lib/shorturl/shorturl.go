@@ -0,0 +1,136 @@
+// Package shorturl builds the addresses clients follow to short links.
+package shorturl
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+)
+
+// ErrUnknownDomain is returned by Domain for hosts that are not among the
+// configured short domains.
+var ErrUnknownDomain = errors.New("unknown short domain")
+
+// Builder joins aliases to the base URL of the service.
+type Builder struct {
+	// base has no trailing slash; empty takes the scheme and host from
+	// the request.
+	base string
+	// domains are lower-case host names, the first being the default;
+	// empty serves every link on any host.
+	domains []string
+}
+
+// New returns a builder for baseURL, e.g. https://sho.rt. baseURL may have
+// a path for services behind a proxy that strips it. With an empty baseURL
+// short URLs are made from the Host the request was sent to, which is
+// wrong behind proxies that rewrite it.
+//
+// domains are the host names links can be created on, the first being the
+// default for links saved without one. baseURL, if set, is the address of
+// the default domain; links on the others get its scheme.
+func New(baseURL string, domains []string) (*Builder, error) {
+	b := &Builder{}
+
+	for _, d := range domains {
+		d = strings.ToLower(strings.TrimSpace(d))
+		u, err := url.Parse("//" + d)
+		if d == "" || err != nil || u.Host != d || u.Port() != "" || u.User != nil {
+			return nil, fmt.Errorf("short domain must be a host name without port: %q", d)
+		}
+		if !slices.Contains(b.domains, d) {
+			b.domains = append(b.domains, d)
+		}
+	}
+
+	if baseURL == "" {
+		return b, nil
+	}
+
+	u, err := url.Parse(baseURL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" || u.RawQuery != "" || u.Fragment != "" {
+		return nil, fmt.Errorf("base url must be an absolute http or https URL without query: %q", baseURL)
+	}
+	b.base = strings.TrimSuffix(baseURL, "/")
+
+	return b, nil
+}
+
+// Domain returns the short domain to save a link on for the host a client
+// asked for: "" (the default one) if it asked for none, or the host itself
+// if it is configured.
+func (b *Builder) Domain(host string) (string, error) {
+	if host == "" {
+		return "", nil
+	}
+
+	host = strings.ToLower(host)
+	if !slices.Contains(b.domains, host) {
+		return "", ErrUnknownDomain
+	}
+
+	return host, nil
+}
+
+// Hosts returns the host names short links are served on, known from the
+// configuration. A base URL with a path is left out: its host serves more
+// than short links.
+func (b *Builder) Hosts() []string {
+	hosts := slices.Clone(b.domains)
+	if u, err := url.Parse(b.base); err == nil && u.Host != "" && u.Path == "" {
+		hosts = append(hosts, u.Hostname())
+	}
+
+	return hosts
+}
+
+// Serves reports whether a link saved on domain answers requests sent to
+// the Host of r. Without configured domains every link answers on every
+// host.
+func (b *Builder) Serves(r *http.Request, domain string) bool {
+	if len(b.domains) == 0 {
+		return true
+	}
+
+	host := r.Host
+	if h, _, err := net.SplitHostPort(host); err == nil {
+		host = h
+	}
+
+	if domain == "" {
+		domain = b.domains[0]
+	}
+
+	return strings.EqualFold(host, domain)
+}
+
+// URL returns the short URL of alias saved on domain.
+func (b *Builder) URL(r *http.Request, domain string, alias string) string {
+	base := b.base
+	switch {
+	case len(b.domains) > 0 && domain != "" && domain != b.domains[0]:
+		base = b.scheme(r) + "://" + domain
+	case base == "" && len(b.domains) > 0:
+		base = b.scheme(r) + "://" + b.domains[0]
+	case base == "":
+		base = b.scheme(r) + "://" + r.Host
+	}
+
+	return base + "/" + url.PathEscape(alias)
+}
+
+// scheme returns the scheme of the base URL, or that of r without one.
+func (b *Builder) scheme(r *http.Request) string {
+	if scheme, _, ok := strings.Cut(b.base, "://"); ok {
+		return scheme
+	}
+	if r.TLS != nil {
+		return "https"
+	}
+
+	return "http"
+}
@@ -0,0 +1,117 @@
+package shorturl_test
+
+import (
+	"crypto/tls"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"url-shortener/internal/lib/shorturl"
+)
+
+func TestBuilder(t *testing.T) {
+	cases := []struct {
+		name    string
+		baseURL string
+		domains []string
+		tls     bool
+		domain  string
+		alias   string
+		want    string
+	}{
+		{name: "Base URL", baseURL: "https://sho.rt", alias: "abc123", want: "https://sho.rt/abc123"},
+		{name: "Trailing slash", baseURL: "https://sho.rt/", alias: "abc123", want: "https://sho.rt/abc123"},
+		{name: "Path", baseURL: "https://example.com/s", alias: "abc123", want: "https://example.com/s/abc123"},
+		{name: "Escaped alias", baseURL: "https://sho.rt", alias: "a b", want: "https://sho.rt/a%20b"},
+		{name: "From request", alias: "abc123", want: "http://example.com/abc123"},
+		{name: "From TLS request", tls: true, alias: "abc123", want: "https://example.com/abc123"},
+		{name: "Default domain", domains: []string{"sho.rt", "go.acme.com"}, alias: "abc123", want: "http://sho.rt/abc123"},
+		{name: "Other domain", domains: []string{"sho.rt", "go.acme.com"}, domain: "go.acme.com", alias: "abc123", want: "http://go.acme.com/abc123"},
+		{name: "Other domain with base URL", baseURL: "https://sho.rt/s", domains: []string{"sho.rt", "go.acme.com"}, domain: "go.acme.com", alias: "abc123", want: "https://go.acme.com/abc123"},
+		{name: "Default domain with base URL", baseURL: "https://sho.rt/s", domains: []string{"sho.rt", "go.acme.com"}, domain: "sho.rt", alias: "abc123", want: "https://sho.rt/s/abc123"},
+		{name: "Domain no longer configured", domain: "go.acme.com", alias: "abc123", want: "http://example.com/abc123"},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			b, err := shorturl.New(tc.baseURL, tc.domains)
+			require.NoError(t, err)
+
+			r := httptest.NewRequest("POST", "/url", nil)
+			if tc.tls {
+				r.TLS = &tls.ConnectionState{}
+			}
+
+			require.Equal(t, tc.want, b.URL(r, tc.domain, tc.alias))
+		})
+	}
+}
+
+func TestNew_Invalid(t *testing.T) {
+	for _, baseURL := range []string{"sho.rt", "ftp://sho.rt", "https://", "https://sho.rt/?a=1", "://"} {
+		_, err := shorturl.New(baseURL, nil)
+		require.Error(t, err, baseURL)
+	}
+
+	for _, domain := range []string{"", "sho.rt:8080", "https://sho.rt", "sho.rt/s", "user@sho.rt"} {
+		_, err := shorturl.New("", []string{domain})
+		require.Error(t, err, domain)
+	}
+}
+
+func TestBuilder_Domain(t *testing.T) {
+	b, err := shorturl.New("", []string{"Sho.rt", "go.acme.com"})
+	require.NoError(t, err)
+
+	domain, err := b.Domain("")
+	require.NoError(t, err)
+	require.Equal(t, "", domain)
+
+	domain, err = b.Domain("GO.acme.com")
+	require.NoError(t, err)
+	require.Equal(t, "go.acme.com", domain)
+
+	_, err = b.Domain("evil.com")
+	require.ErrorIs(t, err, shorturl.ErrUnknownDomain)
+}
+
+func TestBuilder_Hosts(t *testing.T) {
+	b, err := shorturl.New("https://sho.rt:8443", []string{"go.acme.com"})
+	require.NoError(t, err)
+	require.Equal(t, []string{"go.acme.com", "sho.rt"}, b.Hosts())
+
+	b, err = shorturl.New("https://example.com/s", nil)
+	require.NoError(t, err)
+	require.Empty(t, b.Hosts())
+}
+
+func TestBuilder_Serves(t *testing.T) {
+	cases := []struct {
+		name    string
+		domains []string
+		host    string
+		domain  string
+		want    bool
+	}{
+		{name: "No domains", host: "anything.com", domain: "go.acme.com", want: true},
+		{name: "Default domain", domains: []string{"sho.rt", "go.acme.com"}, host: "sho.rt", want: true},
+		{name: "Default domain with port", domains: []string{"sho.rt", "go.acme.com"}, host: "SHO.RT:8082", want: true},
+		{name: "Default link on other domain", domains: []string{"sho.rt", "go.acme.com"}, host: "go.acme.com", want: false},
+		{name: "Other domain", domains: []string{"sho.rt", "go.acme.com"}, host: "go.acme.com", domain: "go.acme.com", want: true},
+		{name: "Other link on default domain", domains: []string{"sho.rt", "go.acme.com"}, host: "sho.rt", domain: "go.acme.com", want: false},
+		{name: "Unknown host", domains: []string{"sho.rt"}, host: "localhost", want: false},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			b, err := shorturl.New("", tc.domains)
+			require.NoError(t, err)
+
+			r := httptest.NewRequest("GET", "/abc123", nil)
+			r.Host = tc.host
+
+			require.Equal(t, tc.want, b.Serves(r, tc.domain))
+		})
+	}
+}
@@ -0,0 +1,65 @@
+// Package tag normalizes the tags links are grouped by.
+package tag
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+const (
+	// MaxLength is the longest tag, in characters.
+	MaxLength = 50
+	// MaxPerLink is the most tags a link can have.
+	MaxPerLink = 20
+)
+
+var ErrTooMany = fmt.Errorf("a link can have at most %d tags", MaxPerLink)
+
+// Normalize trims and lower-cases tag so that "Promo" and "promo " group
+// the same links. Tags are made of letters, digits and the characters
+// "-", "_", "." and ":"; anything else is an error.
+func Normalize(tag string) (string, error) {
+	tag = strings.ToLower(strings.TrimSpace(tag))
+	if tag == "" {
+		return "", errors.New("tag is empty")
+	}
+	if utf8.RuneCountInString(tag) > MaxLength {
+		return "", fmt.Errorf("tag %q is longer than %d characters", tag, MaxLength)
+	}
+	for _, r := range tag {
+		if !unicode.IsLetter(r) && !unicode.IsDigit(r) && !strings.ContainsRune("-_.:", r) {
+			return "", fmt.Errorf("tag %q contains %q; use letters, digits, '-', '_', '.' or ':'", tag, r)
+		}
+	}
+
+	return tag, nil
+}
+
+// NormalizeAll normalizes every tag of a link and drops repeats; the result
+// is sorted.
+func NormalizeAll(tags []string) ([]string, error) {
+	if len(tags) == 0 {
+		return nil, nil
+	}
+
+	normalized := make([]string, 0, len(tags))
+	for _, t := range tags {
+		t, err := Normalize(t)
+		if err != nil {
+			return nil, err
+		}
+		normalized = append(normalized, t)
+	}
+
+	slices.Sort(normalized)
+	normalized = slices.Compact(normalized)
+	if len(normalized) > MaxPerLink {
+		return nil, ErrTooMany
+	}
+
+	return normalized, nil
+}
@@ -0,0 +1,56 @@
+package tag_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"url-shortener/internal/lib/tag"
+)
+
+func TestNormalize(t *testing.T) {
+	cases := []struct {
+		name    string
+		tag     string
+		want    string
+		wantErr bool
+	}{
+		{name: "Lower-cased and trimmed", tag: "  Spring-Sale ", want: "spring-sale"},
+		{name: "Punctuation", tag: "team:growth_2025.q1", want: "team:growth_2025.q1"},
+		{name: "Non-Latin letters", tag: "Акция", want: "акция"},
+		{name: "Empty", tag: "  ", wantErr: true},
+		{name: "Comma", tag: "a,b", wantErr: true},
+		{name: "Space", tag: "spring sale", wantErr: true},
+		{name: "Too long", tag: strings.Repeat("a", tag.MaxLength+1), wantErr: true},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := tag.Normalize(tc.tag)
+			if tc.wantErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tc.want, got)
+		})
+	}
+}
+
+func TestNormalizeAll(t *testing.T) {
+	got, err := tag.NormalizeAll([]string{"promo", "Team:Growth", "PROMO "})
+	require.NoError(t, err)
+	require.Equal(t, []string{"promo", "team:growth"}, got)
+
+	got, err = tag.NormalizeAll(nil)
+	require.NoError(t, err)
+	require.Nil(t, got)
+
+	tags := make([]string, tag.MaxPerLink+1)
+	for i := range tags {
+		tags[i] = strings.Repeat("a", i+1)
+	}
+	_, err = tag.NormalizeAll(tags)
+	require.ErrorIs(t, err, tag.ErrTooMany)
+}
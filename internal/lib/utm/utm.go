@@ -0,0 +1,43 @@
+// Package utm adds a link's UTM parameters to its target URL.
+package utm
+
+import (
+	"net/url"
+	"strings"
+
+	"url-shortener/internal/storage"
+)
+
+// Placeholder in a UTM value is replaced with the alias of the link, so a
+// single template can tell links of one campaign apart.
+const Placeholder = "{alias}"
+
+// Append returns rawURL with the utm_source, utm_medium and utm_campaign
+// parameters of t. Empty values are skipped, and parameters the URL
+// already has are left as they are. URLs that do not parse are returned
+// unchanged.
+func Append(rawURL string, alias string, t storage.UTM) string {
+	if t == (storage.UTM{}) {
+		return rawURL
+	}
+
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return rawURL
+	}
+
+	query := u.Query()
+	for _, p := range []struct{ name, value string }{
+		{"utm_source", t.Source},
+		{"utm_medium", t.Medium},
+		{"utm_campaign", t.Campaign},
+	} {
+		if p.value == "" || query.Has(p.name) {
+			continue
+		}
+		query.Set(p.name, strings.ReplaceAll(p.value, Placeholder, alias))
+	}
+	u.RawQuery = query.Encode()
+
+	return u.String()
+}
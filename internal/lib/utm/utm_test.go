@@ -0,0 +1,49 @@
+package utm_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"url-shortener/internal/lib/utm"
+	"url-shortener/internal/storage"
+)
+
+func TestAppend(t *testing.T) {
+	cases := []struct {
+		name string
+		url  string
+		utm  storage.UTM
+		want string
+	}{
+		{
+			name: "No parameters",
+			url:  "https://example.com/page?b=2&a=1",
+			want: "https://example.com/page?b=2&a=1",
+		},
+		{
+			name: "All parameters",
+			url:  "https://example.com/page",
+			utm:  storage.UTM{Source: "newsletter", Medium: "email", Campaign: "spring sale"},
+			want: "https://example.com/page?utm_campaign=spring+sale&utm_medium=email&utm_source=newsletter",
+		},
+		{
+			name: "Existing parameters win",
+			url:  "https://example.com/?utm_source=twitter&q=go",
+			utm:  storage.UTM{Source: "newsletter", Medium: "email"},
+			want: "https://example.com/?q=go&utm_medium=email&utm_source=twitter",
+		},
+		{
+			name: "Alias placeholder",
+			url:  "https://example.com/#top",
+			utm:  storage.UTM{Campaign: "promo-{alias}"},
+			want: "https://example.com/?utm_campaign=promo-abc#top",
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			require.Equal(t, tc.want, utm.Append(tc.url, "abc", tc.utm))
+		})
+	}
+}
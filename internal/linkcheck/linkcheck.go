@@ -0,0 +1,235 @@
+// Package linkcheck finds links whose destinations stopped working. Every
+// destination is requested with HEAD and the outcome is stored as the
+// link's health, so broken links can be listed and fixed.
+package linkcheck
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+	"sync"
+	"time"
+
+	"url-shortener/internal/lib/logger/sl"
+	"url-shortener/internal/lib/metadata"
+	"url-shortener/internal/storage"
+	"url-shortener/internal/webhook"
+)
+
+const (
+	// pageSize is how many links are read and checked at once.
+	pageSize = 500
+
+	userAgent = "url-shortener-linkcheck/1.0"
+)
+
+var errRedirectLoop = errors.New("redirect loop")
+
+//go:generate go run github.com/vektra/mockery/v2@latest --name=URLStore
+type URLStore interface {
+	ListURLs(ctx context.Context, filter storage.ListFilter, limit int, offset int, desc bool) ([]storage.URL, error)
+	SetURLHealth(ctx context.Context, alias string, health storage.Health) error
+}
+
+type Notifier interface {
+	Notify(event webhook.Event)
+}
+
+type Options struct {
+	// Concurrency is how many destinations are requested at once.
+	Concurrency int
+	// MaxRedirects is how many redirects a destination may take before it
+	// counts as a redirect loop.
+	MaxRedirects int
+}
+
+// Checker checks the destinations of all links and tells the notifiers
+// about every link that breaks.
+type Checker struct {
+	log       *slog.Logger
+	store     URLStore
+	client    *http.Client
+	opts      Options
+	notifiers []Notifier
+}
+
+// New returns a Checker that makes requests with a copy of client, usually
+// one from metadata.NewClient, so links cannot point it at the server's own
+// network.
+func New(log *slog.Logger, store URLStore, client *http.Client, opts Options, notifiers ...Notifier) *Checker {
+	c := &Checker{
+		log:       log.With(slog.String("component", "linkcheck")),
+		store:     store,
+		opts:      opts,
+		notifiers: notifiers,
+	}
+
+	withLoops := *client
+	withLoops.CheckRedirect = c.checkRedirect
+	c.client = &withLoops
+
+	return c
+}
+
+func (c *Checker) checkRedirect(req *http.Request, via []*http.Request) error {
+	if len(via) >= c.opts.MaxRedirects {
+		return errRedirectLoop
+	}
+	for _, prev := range via {
+		if prev.URL.String() == req.URL.String() {
+			return errRedirectLoop
+		}
+	}
+	return nil
+}
+
+// Check checks the destination of every link once. It is meant to be run
+// as a scheduler job and fails only if the links cannot be listed.
+func (c *Checker) Check(ctx context.Context) error {
+	var (
+		mu      sync.Mutex
+		checked int
+		broken  int
+	)
+	for offset := 0; ; offset += pageSize {
+		urls, err := c.store.ListURLs(ctx, storage.ListFilter{}, pageSize, offset, false)
+		if err != nil {
+			return err
+		}
+
+		jobs := make(chan storage.URL)
+		var wg sync.WaitGroup
+		for range max(c.opts.Concurrency, 1) {
+			wg.Go(func() {
+				for u := range jobs {
+					health, ok := c.checkURL(ctx, u)
+					if !ok {
+						continue
+					}
+
+					mu.Lock()
+					checked++
+					if health.Status != storage.HealthOK {
+						broken++
+					}
+					mu.Unlock()
+				}
+			})
+		}
+		for _, u := range urls {
+			jobs <- u
+		}
+		close(jobs)
+		wg.Wait()
+
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if len(urls) < pageSize {
+			break
+		}
+	}
+
+	c.log.Info("links checked", slog.Int("checked", checked), slog.Int("broken", broken))
+
+	return nil
+}
+
+// checkURL checks the destination of u and stores the outcome. It reports
+// false if the destination could not be checked.
+func (c *Checker) checkURL(ctx context.Context, u storage.URL) (storage.Health, bool) {
+	log := c.log.With(slog.String("alias", u.Alias))
+
+	health, err := c.check(ctx, log, u.URL)
+	if err != nil {
+		if ctx.Err() == nil {
+			log.Info("destination not checked", sl.Err(err))
+		}
+		return storage.Health{}, false
+	}
+
+	err = c.store.SetURLHealth(ctx, u.Alias, health)
+	if errors.Is(err, storage.ErrUrlNotFound) {
+		// Deleted since it was listed.
+		return storage.Health{}, false
+	}
+	if err != nil {
+		log.Error("failed to save link health", sl.Err(err))
+		return storage.Health{}, false
+	}
+
+	// Only the first check that finds a destination broken is reported,
+	// not every one after it.
+	if health.Status != storage.HealthOK && (u.Health.Status == "" || u.Health.Status == storage.HealthOK) {
+		log.Warn("destination broken",
+			slog.String("health", health.Status),
+			slog.Int("status_code", health.StatusCode),
+		)
+
+		event := webhook.Event{
+			Type:       webhook.EventLinkBroken,
+			Alias:      u.Alias,
+			URL:        u.URL,
+			Owner:      u.Owner,
+			Health:     health.Status,
+			StatusCode: health.StatusCode,
+		}
+		event.Stamp()
+		for _, n := range c.notifiers {
+			n.Notify(event)
+		}
+	}
+
+	return health, true
+}
+
+// check requests rawURL. Destinations that answer 404, 410 or 5xx or cannot
+// be reached at all are broken; anything else, such as 401 or 403, means
+// the site is up. Servers that do not support HEAD are asked with GET.
+func (c *Checker) check(ctx context.Context, log *slog.Logger, rawURL string) (storage.Health, error) {
+	health := storage.Health{CheckedAt: time.Now()}
+
+	code, err := c.request(ctx, http.MethodHead, rawURL)
+	if err == nil && (code == http.StatusMethodNotAllowed || code == http.StatusNotImplemented) {
+		code, err = c.request(ctx, http.MethodGet, rawURL)
+	}
+	if ctx.Err() != nil {
+		return storage.Health{}, ctx.Err()
+	}
+	switch {
+	case errors.Is(err, errRedirectLoop):
+		health.Status = storage.HealthRedirectLoop
+	case errors.Is(err, metadata.ErrForbiddenAddress):
+		return storage.Health{}, err
+	case err != nil:
+		log.Info("destination unreachable", sl.Err(err))
+		health.Status = storage.HealthBroken
+	case code == http.StatusNotFound || code == http.StatusGone || code >= http.StatusInternalServerError:
+		health.Status = storage.HealthBroken
+		health.StatusCode = code
+	default:
+		health.Status = storage.HealthOK
+		health.StatusCode = code
+	}
+
+	return health, nil
+}
+
+func (c *Checker) request(ctx context.Context, method, rawURL string) (int, error) {
+	req, err := http.NewRequestWithContext(ctx, method, rawURL, nil)
+	if err != nil {
+		return 0, err
+	}
+	req.Header.Set("User-Agent", userAgent)
+
+	res, err := c.client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer res.Body.Close()
+	_, _ = io.Copy(io.Discard, io.LimitReader(res.Body, 64<<10))
+
+	return res.StatusCode, nil
+}
@@ -0,0 +1,108 @@
+package linkcheck_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"url-shortener/internal/lib/logger/handlers/slogdiscard"
+	"url-shortener/internal/linkcheck"
+	"url-shortener/internal/linkcheck/mocks"
+	"url-shortener/internal/storage"
+	"url-shortener/internal/webhook"
+)
+
+type notifier struct {
+	mu     sync.Mutex
+	events []webhook.Event
+}
+
+func (n *notifier) Notify(event webhook.Event) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	n.events = append(n.events, event)
+}
+
+func TestChecker_Check(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/ok", func(w http.ResponseWriter, r *http.Request) {})
+	mux.HandleFunc("/missing", http.NotFound)
+	mux.HandleFunc("/error", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadGateway)
+	})
+	mux.HandleFunc("/loop", func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, "/loop", http.StatusFound)
+	})
+	mux.HandleFunc("/forbidden", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusForbidden)
+	})
+	mux.HandleFunc("/get-only", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			w.WriteHeader(http.StatusMethodNotAllowed)
+		}
+	})
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+
+	urls := []storage.URL{
+		{Alias: "ok", URL: srv.URL + "/ok"},
+		{Alias: "get-only", URL: srv.URL + "/get-only"},
+		{Alias: "forbidden", URL: srv.URL + "/forbidden"},
+		{Alias: "missing", URL: srv.URL + "/missing", Owner: "alice"},
+		{Alias: "error", URL: srv.URL + "/error", Health: storage.Health{Status: storage.HealthOK}},
+		{Alias: "loop", URL: srv.URL + "/loop"},
+		// Already broken at the last check, so it is not reported again.
+		{Alias: "still-missing", URL: srv.URL + "/missing", Health: storage.Health{Status: storage.HealthBroken}},
+		{Alias: "deleted", URL: srv.URL + "/ok"},
+	}
+	want := map[string]storage.Health{
+		"ok":            {Status: storage.HealthOK, StatusCode: http.StatusOK},
+		"get-only":      {Status: storage.HealthOK, StatusCode: http.StatusOK},
+		"forbidden":     {Status: storage.HealthOK, StatusCode: http.StatusForbidden},
+		"missing":       {Status: storage.HealthBroken, StatusCode: http.StatusNotFound},
+		"error":         {Status: storage.HealthBroken, StatusCode: http.StatusBadGateway},
+		"loop":          {Status: storage.HealthRedirectLoop},
+		"still-missing": {Status: storage.HealthBroken, StatusCode: http.StatusNotFound},
+	}
+
+	storeMock := mocks.NewURLStore(t)
+	storeMock.On("ListURLs", mock.Anything, storage.ListFilter{}, mock.AnythingOfType("int"), 0, false).
+		Return(urls, nil).
+		Once()
+	for alias, health := range want {
+		storeMock.On("SetURLHealth", mock.Anything, alias, mock.MatchedBy(func(h storage.Health) bool {
+			return h.Status == health.Status && h.StatusCode == health.StatusCode && !h.CheckedAt.IsZero()
+		})).
+			Return(nil).
+			Once()
+	}
+	storeMock.On("SetURLHealth", mock.Anything, "deleted", mock.Anything).
+		Return(storage.ErrUrlNotFound).
+		Once()
+
+	n := &notifier{}
+	checker := linkcheck.New(slogdiscard.NewDiscardLogger(), storeMock, http.DefaultClient, linkcheck.Options{
+		Concurrency:  3,
+		MaxRedirects: 5,
+	}, n)
+
+	require.NoError(t, checker.Check(context.Background()))
+
+	reported := map[string]webhook.Event{}
+	for _, e := range n.events {
+		require.Equal(t, webhook.EventLinkBroken, e.Type)
+		require.NotEmpty(t, e.ID)
+		reported[e.Alias] = e
+	}
+	require.Len(t, reported, 3)
+	require.Contains(t, reported, "error")
+	require.Contains(t, reported, "loop")
+	require.Equal(t, "alice", reported["missing"].Owner)
+	require.Equal(t, http.StatusNotFound, reported["missing"].StatusCode)
+	require.Equal(t, storage.HealthBroken, reported["missing"].Health)
+}
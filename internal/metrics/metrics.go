@@ -0,0 +1,156 @@
+package metrics
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+
+	"url-shortener/internal/storage"
+)
+
+const namespace = "url_shortener"
+
+// redirectRoute is the route pattern of the redirect handler.
+const redirectRoute = "/{alias}"
+
+// Metrics holds the Prometheus collectors of the service in a registry of
+// its own, served by Handler.
+type Metrics struct {
+	registry *prometheus.Registry
+
+	requestDuration *prometheus.HistogramVec
+	notFound        prometheus.Counter
+	redirects       prometheus.Counter
+	saves           prometheus.Counter
+	queryDuration   *prometheus.HistogramVec
+	storageErrors   *prometheus.CounterVec
+	jobDuration     *prometheus.HistogramVec
+	jobRuns         *prometheus.CounterVec
+	jobLastSuccess  *prometheus.GaugeVec
+}
+
+func New() *Metrics {
+	m := &Metrics{
+		registry: prometheus.NewRegistry(),
+		requestDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Subsystem: "http",
+			Name:      "request_duration_seconds",
+			Help:      "HTTP request latency by route.",
+			Buckets:   prometheus.DefBuckets,
+		}, []string{"method", "route", "status"}),
+		notFound: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: "http",
+			Name:      "not_found_total",
+			Help:      "Responses with status 404.",
+		}),
+		redirects: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "redirects_total",
+			Help:      "Successful redirects to the original URL.",
+		}),
+		saves: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "saves_total",
+			Help:      "Saved short links.",
+		}),
+		queryDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Subsystem: "storage",
+			Name:      "query_duration_seconds",
+			Help:      "Storage call latency by method.",
+			Buckets:   []float64{.0005, .001, .0025, .005, .01, .025, .05, .1, .25, .5, 1},
+		}, []string{"query"}),
+		storageErrors: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: "storage",
+			Name:      "errors_total",
+			Help:      "Unexpected storage errors by method.",
+		}, []string{"query"}),
+		jobDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Subsystem: "scheduler",
+			Name:      "job_duration_seconds",
+			Help:      "Maintenance job run time by job.",
+			Buckets:   []float64{.01, .05, .1, .5, 1, 5, 10, 30, 60, 300},
+		}, []string{"job"}),
+		jobRuns: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: "scheduler",
+			Name:      "job_runs_total",
+			Help:      "Maintenance job runs by job and result.",
+		}, []string{"job", "result"}),
+		jobLastSuccess: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Namespace: namespace,
+			Subsystem: "scheduler",
+			Name:      "job_last_success_timestamp_seconds",
+			Help:      "Unix time a job last finished without error.",
+		}, []string{"job"}),
+	}
+
+	m.registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		m.requestDuration,
+		m.notFound,
+		m.redirects,
+		m.saves,
+		m.queryDuration,
+		m.storageErrors,
+		m.jobDuration,
+		m.jobRuns,
+		m.jobLastSuccess,
+	)
+
+	return m
+}
+
+// Handler serves the metrics in the Prometheus text format.
+func (m *Metrics) Handler() http.Handler {
+	return promhttp.HandlerFor(m.registry, promhttp.HandlerOpts{})
+}
+
+// ObserveRequest records a served HTTP request. route is the chi route
+// pattern, so that aliases do not end up in label values.
+func (m *Metrics) ObserveRequest(method string, route string, status int, duration time.Duration) {
+	m.requestDuration.WithLabelValues(method, route, strconv.Itoa(status)).Observe(duration.Seconds())
+
+	if status == http.StatusNotFound {
+		m.notFound.Inc()
+	}
+	if route == redirectRoute && status >= 300 && status < 400 {
+		m.redirects.Inc()
+	}
+}
+
+// ObserveQuery records a storage call. Expected outcomes such as a missing
+// alias are not counted as errors.
+func (m *Metrics) ObserveQuery(query string, duration time.Duration, err error) {
+	m.queryDuration.WithLabelValues(query).Observe(duration.Seconds())
+
+	if err != nil && !storage.Expected(err) {
+		m.storageErrors.WithLabelValues(query).Inc()
+	}
+}
+
+// ObserveSaved counts n created links.
+func (m *Metrics) ObserveSaved(n int) {
+	m.saves.Add(float64(n))
+}
+
+// ObserveJob records a run of a maintenance job.
+func (m *Metrics) ObserveJob(job string, duration time.Duration, err error) {
+	m.jobDuration.WithLabelValues(job).Observe(duration.Seconds())
+
+	if err != nil {
+		m.jobRuns.WithLabelValues(job, "error").Inc()
+		return
+	}
+	m.jobRuns.WithLabelValues(job, "ok").Inc()
+	m.jobLastSuccess.WithLabelValues(job).SetToCurrentTime()
+}
@@ -0,0 +1,390 @@
+// Package oidc signs users in with an OpenID Connect provider such as
+// Google or Keycloak: it runs the authorization code flow with PKCE,
+// verifies the ID token and maps its claims to a local user and role.
+package oidc
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+
+	"url-shortener/internal/storage"
+)
+
+var (
+	ErrInvalidToken = errors.New("invalid id token")
+	// ErrNotAllowed is returned for valid tokens of users who may not sign
+	// in, such as those with an unverified email or from another domain.
+	ErrNotAllowed = errors.New("user not allowed")
+)
+
+// signingMethods are the algorithms ID tokens may be signed with; HS256 is
+// left out as it would make the client secret a signing key.
+var signingMethods = []string{"RS256", "RS384", "RS512", "PS256", "PS384", "PS512", "ES256", "ES384", "ES512", "EdDSA"}
+
+// keysRefresh is how often unknown key ids may trigger a refetch of the
+// provider's keys.
+const keysRefresh = time.Minute
+
+type Options struct {
+	// Issuer is the provider URL, e.g. https://accounts.google.com; its
+	// /.well-known/openid-configuration is read on first use.
+	Issuer       string
+	ClientID     string
+	ClientSecret string
+	// RedirectURL is the callback registered with the provider.
+	RedirectURL string
+	// Scopes are requested on top of openid.
+	Scopes []string
+	// UsernameClaim names the local user, e.g. email or
+	// preferred_username.
+	UsernameClaim string
+	// RoleClaim is a claim holding a string or a list of strings, e.g.
+	// groups or, in Keycloak, realm_access.roles; dots walk into objects.
+	// Users with one of AdminValues in it are admins. If EditorValues are
+	// set, those with one of them are editors and everyone else a viewer;
+	// otherwise everyone else is an editor.
+	RoleClaim    string
+	AdminValues  []string
+	EditorValues []string
+	// AllowedDomains limits sign-in to emails of these domains; empty
+	// allows any.
+	AllowedDomains []string
+}
+
+// Identity is the local user an ID token maps to.
+type Identity struct {
+	Username string
+	Role     string
+}
+
+type metadata struct {
+	Issuer                string `json:"issuer"`
+	AuthorizationEndpoint string `json:"authorization_endpoint"`
+	TokenEndpoint         string `json:"token_endpoint"`
+	JWKSURI               string `json:"jwks_uri"`
+}
+
+type Provider struct {
+	client *http.Client
+	opts   Options
+
+	mu          sync.Mutex
+	meta        *metadata
+	keys        map[string]any
+	keysFetched time.Time
+}
+
+// New returns a Provider that talks to the provider with client. Nothing
+// is requested until the first sign-in, so the server starts while the
+// provider is down.
+func New(client *http.Client, opts Options) *Provider {
+	opts.Issuer = strings.TrimSuffix(opts.Issuer, "/")
+	domains := make([]string, len(opts.AllowedDomains))
+	for i, d := range opts.AllowedDomains {
+		domains[i] = strings.ToLower(d)
+	}
+	opts.AllowedDomains = domains
+
+	return &Provider{client: client, opts: opts}
+}
+
+// AuthURL returns the address of the provider's sign-in page for a login
+// with state s.
+func (p *Provider) AuthURL(ctx context.Context, s State) (string, error) {
+	const op = "oidc.AuthURL"
+
+	meta, err := p.discover(ctx)
+	if err != nil {
+		return "", fmt.Errorf("%s: %w", op, err)
+	}
+
+	challenge := sha256.Sum256([]byte(s.Verifier))
+	q := url.Values{
+		"response_type":         {"code"},
+		"client_id":             {p.opts.ClientID},
+		"redirect_uri":          {p.opts.RedirectURL},
+		"scope":                 {strings.Join(slices.Concat([]string{"openid"}, p.opts.Scopes), " ")},
+		"state":                 {s.State},
+		"nonce":                 {s.Nonce},
+		"code_challenge":        {base64.RawURLEncoding.EncodeToString(challenge[:])},
+		"code_challenge_method": {"S256"},
+	}
+
+	sep := "?"
+	if strings.Contains(meta.AuthorizationEndpoint, "?") {
+		sep = "&"
+	}
+
+	return meta.AuthorizationEndpoint + sep + q.Encode(), nil
+}
+
+// Exchange redeems the code the provider sent back to the callback of
+// login s and returns the identity in its ID token.
+func (p *Provider) Exchange(ctx context.Context, code string, s State) (Identity, error) {
+	const op = "oidc.Exchange"
+
+	meta, err := p.discover(ctx)
+	if err != nil {
+		return Identity{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	form := url.Values{
+		"grant_type":    {"authorization_code"},
+		"code":          {code},
+		"redirect_uri":  {p.opts.RedirectURL},
+		"code_verifier": {s.Verifier},
+	}
+	if p.opts.ClientSecret == "" {
+		form.Set("client_id", p.opts.ClientID)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, meta.TokenEndpoint, strings.NewReader(form.Encode()))
+	if err != nil {
+		return Identity{}, fmt.Errorf("%s: %w", op, err)
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+	if p.opts.ClientSecret != "" {
+		req.SetBasicAuth(url.QueryEscape(p.opts.ClientID), url.QueryEscape(p.opts.ClientSecret))
+	}
+
+	var res struct {
+		IDToken          string `json:"id_token"`
+		Error            string `json:"error"`
+		ErrorDescription string `json:"error_description"`
+	}
+	if err := p.getJSON(req, &res); err != nil && res.Error == "" {
+		return Identity{}, fmt.Errorf("%s: %w", op, err)
+	}
+	if res.Error != "" {
+		return Identity{}, fmt.Errorf("%s: %s: %s", op, res.Error, res.ErrorDescription)
+	}
+	if res.IDToken == "" {
+		return Identity{}, fmt.Errorf("%s: no id_token in the response", op)
+	}
+
+	return p.verify(ctx, res.IDToken, s.Nonce)
+}
+
+// Parse verifies an ID token issued to this client, sent as a bearer
+// token, and returns the username and role it maps to. It lets API
+// clients sign in with the provider themselves.
+func (p *Provider) Parse(token string) (string, string, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), p.client.Timeout+time.Second)
+	defer cancel()
+
+	id, err := p.verify(ctx, token, "")
+	if err != nil {
+		return "", "", err
+	}
+
+	return id.Username, id.Role, nil
+}
+
+// verify checks the signature, issuer, audience, expiry and, if nonce is
+// not empty, the nonce of an ID token and maps its claims.
+func (p *Provider) verify(ctx context.Context, raw string, nonce string) (Identity, error) {
+	const op = "oidc.verify"
+
+	meta, err := p.discover(ctx)
+	if err != nil {
+		return Identity{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	claims := jwt.MapClaims{}
+	_, err = jwt.ParseWithClaims(raw, claims, func(t *jwt.Token) (any, error) {
+		kid, _ := t.Header["kid"].(string)
+		return p.key(ctx, meta, kid)
+	},
+		jwt.WithValidMethods(signingMethods),
+		jwt.WithIssuer(meta.Issuer),
+		jwt.WithAudience(p.opts.ClientID),
+		jwt.WithExpirationRequired(),
+		jwt.WithLeeway(time.Minute),
+	)
+	if err != nil {
+		return Identity{}, fmt.Errorf("%s: %w: %w", op, ErrInvalidToken, err)
+	}
+	if nonce != "" && claims["nonce"] != nonce {
+		return Identity{}, fmt.Errorf("%s: %w: nonce mismatch", op, ErrInvalidToken)
+	}
+
+	id, err := p.identity(claims)
+	if err != nil {
+		return Identity{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return id, nil
+}
+
+func (p *Provider) identity(claims jwt.MapClaims) (Identity, error) {
+	if verified, ok := claims["email_verified"].(bool); ok && !verified {
+		return Identity{}, fmt.Errorf("%w: email not verified", ErrNotAllowed)
+	}
+	if len(p.opts.AllowedDomains) > 0 {
+		email, _ := claims["email"].(string)
+		_, domain, _ := strings.Cut(email, "@")
+		if !slices.Contains(p.opts.AllowedDomains, strings.ToLower(domain)) {
+			return Identity{}, fmt.Errorf("%w: email domain %q", ErrNotAllowed, domain)
+		}
+	}
+
+	username, _ := lookup(claims, p.opts.UsernameClaim).(string)
+	if username == "" {
+		return Identity{}, fmt.Errorf("%w: no %s claim", ErrNotAllowed, p.opts.UsernameClaim)
+	}
+	if p.opts.UsernameClaim == "email" {
+		username = strings.ToLower(username)
+	}
+
+	id := Identity{Username: username, Role: storage.RoleEditor}
+	if p.opts.RoleClaim != "" {
+		var values []string
+		switch v := lookup(claims, p.opts.RoleClaim).(type) {
+		case string:
+			values = []string{v}
+		case []any:
+			for _, item := range v {
+				if s, ok := item.(string); ok {
+					values = append(values, s)
+				}
+			}
+		}
+		hasAny := func(want []string) bool {
+			return slices.ContainsFunc(values, func(v string) bool { return slices.Contains(want, v) })
+		}
+		switch {
+		case hasAny(p.opts.AdminValues):
+			id.Role = storage.RoleAdmin
+		case len(p.opts.EditorValues) > 0 && !hasAny(p.opts.EditorValues):
+			id.Role = storage.RoleViewer
+		}
+	}
+
+	return id, nil
+}
+
+// lookup returns the claim at a dotted path.
+func lookup(claims map[string]any, path string) any {
+	var v any = claims
+	for _, name := range strings.Split(path, ".") {
+		obj, ok := v.(map[string]any)
+		if !ok {
+			return nil
+		}
+		v = obj[name]
+	}
+
+	return v
+}
+
+// discover reads the provider metadata once; failures are retried on the
+// next call.
+func (p *Provider) discover(ctx context.Context) (*metadata, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.meta != nil {
+		return p.meta, nil
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, p.opts.Issuer+"/.well-known/openid-configuration", nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var meta metadata
+	if err := p.getJSON(req, &meta); err != nil {
+		return nil, fmt.Errorf("discovery: %w", err)
+	}
+	if strings.TrimSuffix(meta.Issuer, "/") != p.opts.Issuer {
+		return nil, fmt.Errorf("discovery: issuer %q does not match %q", meta.Issuer, p.opts.Issuer)
+	}
+	if meta.AuthorizationEndpoint == "" || meta.TokenEndpoint == "" || meta.JWKSURI == "" {
+		return nil, errors.New("discovery: endpoints missing")
+	}
+
+	p.meta = &meta
+
+	return p.meta, nil
+}
+
+// key returns the verification key with id kid, refetching the keys when
+// the provider may have rotated them.
+func (p *Provider) key(ctx context.Context, meta *metadata, kid string) (any, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if k, ok := p.pick(kid); ok {
+		return k, nil
+	}
+	if time.Since(p.keysFetched) < keysRefresh {
+		return nil, fmt.Errorf("unknown key %q", kid)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, meta.JWKSURI, nil)
+	if err != nil {
+		return nil, err
+	}
+	var set struct {
+		Keys []jwk `json:"keys"`
+	}
+	if err := p.getJSON(req, &set); err != nil {
+		return nil, fmt.Errorf("keys: %w", err)
+	}
+
+	p.keys = parseKeys(set.Keys)
+	p.keysFetched = time.Now()
+
+	if k, ok := p.pick(kid); ok {
+		return k, nil
+	}
+
+	return nil, fmt.Errorf("unknown key %q", kid)
+}
+
+// pick returns the key with id kid, or the only key if the token names
+// none.
+func (p *Provider) pick(kid string) (any, bool) {
+	if kid == "" && len(p.keys) == 1 {
+		for _, k := range p.keys {
+			return k, true
+		}
+	}
+	k, ok := p.keys[kid]
+
+	return k, ok
+}
+
+func (p *Provider) getJSON(req *http.Request, v any) error {
+	res, err := p.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+
+	body, err := io.ReadAll(io.LimitReader(res.Body, 1<<20))
+	if err != nil {
+		return err
+	}
+	// Error responses of the token endpoint are JSON too.
+	jsonErr := json.Unmarshal(body, v)
+	if res.StatusCode != http.StatusOK {
+		return fmt.Errorf("unexpected status %d", res.StatusCode)
+	}
+
+	return jsonErr
+}
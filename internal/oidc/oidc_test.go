@@ -0,0 +1,273 @@
+package oidc_test
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/require"
+
+	"url-shortener/internal/oidc"
+	"url-shortener/internal/storage"
+)
+
+// idp is a minimal OpenID provider: it serves discovery and keys and
+// answers every code with the ID token in claims.
+type idp struct {
+	*httptest.Server
+	key    *rsa.PrivateKey
+	claims jwt.MapClaims
+	// form is the last token request.
+	form url.Values
+}
+
+func newIDP(t *testing.T) *idp {
+	t.Helper()
+
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+
+	p := &idp{key: key}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/.well-known/openid-configuration", func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewEncoder(w).Encode(map[string]string{
+			"issuer":                 p.URL,
+			"authorization_endpoint": p.URL + "/authorize",
+			"token_endpoint":         p.URL + "/token",
+			"jwks_uri":               p.URL + "/keys",
+		})
+	})
+	mux.HandleFunc("/keys", func(w http.ResponseWriter, r *http.Request) {
+		b64 := base64.RawURLEncoding
+		_ = json.NewEncoder(w).Encode(map[string]any{"keys": []map[string]string{{
+			"kty": "RSA",
+			"kid": "k1",
+			"use": "sig",
+			"n":   b64.EncodeToString(key.N.Bytes()),
+			"e":   b64.EncodeToString(big.NewInt(int64(key.E)).Bytes()),
+		}}})
+	})
+	mux.HandleFunc("/token", func(w http.ResponseWriter, r *http.Request) {
+		_ = r.ParseForm()
+		p.form = r.PostForm
+		if r.PostForm.Get("code") != "good" {
+			w.WriteHeader(http.StatusBadRequest)
+			_ = json.NewEncoder(w).Encode(map[string]string{"error": "invalid_grant"})
+			return
+		}
+		_ = json.NewEncoder(w).Encode(map[string]string{"id_token": p.sign(t, p.claims)})
+	})
+	p.Server = httptest.NewServer(mux)
+	t.Cleanup(p.Close)
+
+	return p
+}
+
+func (p *idp) sign(t *testing.T, claims jwt.MapClaims) string {
+	t.Helper()
+
+	token := jwt.NewWithClaims(jwt.SigningMethodRS256, claims)
+	token.Header["kid"] = "k1"
+	signed, err := token.SignedString(p.key)
+	require.NoError(t, err)
+
+	return signed
+}
+
+func (p *idp) provider(opts oidc.Options) *oidc.Provider {
+	opts.Issuer = p.URL
+	opts.ClientID = "shortener"
+	opts.RedirectURL = "https://sho.rt/api/v1/auth/oidc/callback"
+	if opts.UsernameClaim == "" {
+		opts.UsernameClaim = "email"
+	}
+
+	return oidc.New(p.Client(), opts)
+}
+
+func (p *idp) claimsFor(s oidc.State) jwt.MapClaims {
+	return jwt.MapClaims{
+		"iss":            p.URL,
+		"aud":            "shortener",
+		"sub":            "123",
+		"exp":            time.Now().Add(time.Hour).Unix(),
+		"nonce":          s.Nonce,
+		"email":          "Alice@Example.com",
+		"email_verified": true,
+		"groups":         []string{"staff", "shortener-admins"},
+	}
+}
+
+func TestProvider_AuthURL(t *testing.T) {
+	p := newIDP(t)
+	provider := p.provider(oidc.Options{Scopes: []string{"email"}})
+	s := oidc.NewState("/admin/", time.Now())
+
+	raw, err := provider.AuthURL(context.Background(), s)
+	require.NoError(t, err)
+
+	u, err := url.Parse(raw)
+	require.NoError(t, err)
+	require.Equal(t, p.URL+"/authorize", u.Scheme+"://"+u.Host+u.Path)
+
+	challenge := sha256.Sum256([]byte(s.Verifier))
+	q := u.Query()
+	require.Equal(t, "code", q.Get("response_type"))
+	require.Equal(t, "shortener", q.Get("client_id"))
+	require.Equal(t, "openid email", q.Get("scope"))
+	require.Equal(t, s.State, q.Get("state"))
+	require.Equal(t, s.Nonce, q.Get("nonce"))
+	require.Equal(t, base64.RawURLEncoding.EncodeToString(challenge[:]), q.Get("code_challenge"))
+	require.Equal(t, "S256", q.Get("code_challenge_method"))
+}
+
+func TestProvider_Exchange(t *testing.T) {
+	cases := []struct {
+		name    string
+		opts    oidc.Options
+		code    string
+		claims  func(c jwt.MapClaims)
+		want    oidc.Identity
+		wantErr error
+	}{
+		{
+			name: "User",
+			want: oidc.Identity{Username: "alice@example.com", Role: storage.RoleEditor},
+		},
+		{
+			name: "Admin by group",
+			opts: oidc.Options{RoleClaim: "groups", AdminValues: []string{"shortener-admins"}},
+			want: oidc.Identity{Username: "alice@example.com", Role: storage.RoleAdmin},
+		},
+		{
+			name: "Editor by group",
+			opts: oidc.Options{RoleClaim: "groups", AdminValues: []string{"ops"}, EditorValues: []string{"staff"}},
+			want: oidc.Identity{Username: "alice@example.com", Role: storage.RoleEditor},
+		},
+		{
+			name: "Viewer without editor group",
+			opts: oidc.Options{RoleClaim: "groups", AdminValues: []string{"ops"}, EditorValues: []string{"marketing"}},
+			want: oidc.Identity{Username: "alice@example.com", Role: storage.RoleViewer},
+		},
+		{
+			name: "Nested role claim",
+			opts: oidc.Options{RoleClaim: "realm_access.roles", AdminValues: []string{"admin"}},
+			claims: func(c jwt.MapClaims) {
+				c["realm_access"] = map[string]any{"roles": []string{"admin"}}
+			},
+			want: oidc.Identity{Username: "alice@example.com", Role: storage.RoleAdmin},
+		},
+		{
+			name: "Username claim",
+			opts: oidc.Options{UsernameClaim: "preferred_username"},
+			claims: func(c jwt.MapClaims) {
+				c["preferred_username"] = "Alice"
+			},
+			want: oidc.Identity{Username: "Alice", Role: storage.RoleEditor},
+		},
+		{
+			name: "Allowed domain",
+			opts: oidc.Options{AllowedDomains: []string{"EXAMPLE.com"}},
+			want: oidc.Identity{Username: "alice@example.com", Role: storage.RoleEditor},
+		},
+		{
+			name:    "Other domain",
+			opts:    oidc.Options{AllowedDomains: []string{"corp.example"}},
+			wantErr: oidc.ErrNotAllowed,
+		},
+		{
+			name: "Unverified email",
+			claims: func(c jwt.MapClaims) {
+				c["email_verified"] = false
+			},
+			wantErr: oidc.ErrNotAllowed,
+		},
+		{
+			name: "Nonce mismatch",
+			claims: func(c jwt.MapClaims) {
+				c["nonce"] = "replayed"
+			},
+			wantErr: oidc.ErrInvalidToken,
+		},
+		{
+			name: "Other audience",
+			claims: func(c jwt.MapClaims) {
+				c["aud"] = "another-app"
+			},
+			wantErr: oidc.ErrInvalidToken,
+		},
+		{
+			name: "Expired",
+			claims: func(c jwt.MapClaims) {
+				c["exp"] = time.Now().Add(-time.Hour).Unix()
+			},
+			wantErr: oidc.ErrInvalidToken,
+		},
+		{
+			name: "Bad code",
+			code: "bad",
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			p := newIDP(t)
+			provider := p.provider(tc.opts)
+			s := oidc.NewState("/admin/", time.Now())
+
+			p.claims = p.claimsFor(s)
+			if tc.claims != nil {
+				tc.claims(p.claims)
+			}
+			code := tc.code
+			if code == "" {
+				code = "good"
+			}
+
+			id, err := provider.Exchange(context.Background(), code, s)
+			require.Equal(t, s.Verifier, p.form.Get("code_verifier"))
+
+			if tc.code == "bad" {
+				require.ErrorContains(t, err, "invalid_grant")
+				return
+			}
+			if tc.wantErr != nil {
+				require.ErrorIs(t, err, tc.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tc.want, id)
+		})
+	}
+}
+
+func TestProvider_Parse(t *testing.T) {
+	p := newIDP(t)
+	provider := p.provider(oidc.Options{RoleClaim: "groups", AdminValues: []string{"shortener-admins"}})
+
+	claims := p.claimsFor(oidc.State{})
+	delete(claims, "nonce")
+
+	username, role, err := provider.Parse(p.sign(t, claims))
+	require.NoError(t, err)
+	require.Equal(t, "alice@example.com", username)
+	require.Equal(t, storage.RoleAdmin, role)
+
+	// Tokens of our own JWT manager are not signed by the provider.
+	own := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{"sub": "alice", "iss": p.URL, "aud": "shortener"})
+	signed, err := own.SignedString([]byte("secret"))
+	require.NoError(t, err)
+
+	_, _, err = provider.Parse(signed)
+	require.ErrorIs(t, err, oidc.ErrInvalidToken)
+}
@@ -0,0 +1,86 @@
+package oidc
+
+import (
+	"crypto/hkdf"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"strings"
+	"time"
+)
+
+// StateTTL is how long a user has to sign in with the provider.
+const StateTTL = 10 * time.Minute
+
+var ErrInvalidState = errors.New("invalid login state")
+
+// State is what a login needs to remember between sending the user to the
+// provider and the callback. It travels in a signed cookie.
+type State struct {
+	State    string    `json:"s"`
+	Nonce    string    `json:"n"`
+	Verifier string    `json:"v"`
+	Redirect string    `json:"r"`
+	Expires  time.Time `json:"e"`
+}
+
+// NewState starts a login that returns the user to redirect.
+func NewState(redirect string, now time.Time) State {
+	return State{
+		State:    random(),
+		Nonce:    random(),
+		Verifier: random() + random(),
+		Redirect: redirect,
+		Expires:  now.Add(StateTTL),
+	}
+}
+
+// Seal encodes s and signs it with key.
+func (s State) Seal(key []byte) string {
+	data, _ := json.Marshal(s)
+	payload := base64.RawURLEncoding.EncodeToString(data)
+
+	return payload + "." + sign(key, payload)
+}
+
+// OpenState decodes a state sealed with key and checks that it has not
+// expired.
+func OpenState(value string, key []byte, now time.Time) (State, error) {
+	payload, sig, ok := strings.Cut(value, ".")
+	if !ok || !hmac.Equal([]byte(sig), []byte(sign(key, payload))) {
+		return State{}, ErrInvalidState
+	}
+
+	data, err := base64.RawURLEncoding.DecodeString(payload)
+	if err != nil {
+		return State{}, ErrInvalidState
+	}
+	var s State
+	if err := json.Unmarshal(data, &s); err != nil || now.After(s.Expires) {
+		return State{}, ErrInvalidState
+	}
+
+	return s, nil
+}
+
+// StateKey derives the key states are signed with from secret, the JWT
+// secret, so that no other setting is needed.
+func StateKey(secret string) []byte {
+	key, _ := hkdf.Key(sha256.New, []byte(secret), nil, "url-shortener oidc state", sha256.Size)
+	return key
+}
+
+func sign(key []byte, payload string) string {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(payload))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+func random() string {
+	b := make([]byte, 24)
+	_, _ = rand.Read(b)
+	return base64.RawURLEncoding.EncodeToString(b)
+}
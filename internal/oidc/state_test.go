@@ -0,0 +1,36 @@
+package oidc_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"url-shortener/internal/oidc"
+)
+
+func TestState(t *testing.T) {
+	now := time.Now()
+	key := oidc.StateKey("jwt-secret")
+
+	s := oidc.NewState("/admin/", now)
+	sealed := s.Seal(key)
+
+	opened, err := oidc.OpenState(sealed, key, now.Add(time.Minute))
+	require.NoError(t, err)
+	require.Equal(t, s.State, opened.State)
+	require.Equal(t, s.Nonce, opened.Nonce)
+	require.Equal(t, s.Verifier, opened.Verifier)
+	require.Equal(t, "/admin/", opened.Redirect)
+
+	_, err = oidc.OpenState(sealed, oidc.StateKey("other-secret"), now)
+	require.ErrorIs(t, err, oidc.ErrInvalidState)
+
+	_, err = oidc.OpenState(sealed, key, now.Add(oidc.StateTTL+time.Second))
+	require.ErrorIs(t, err, oidc.ErrInvalidState)
+
+	_, err = oidc.OpenState("garbage", key, now)
+	require.ErrorIs(t, err, oidc.ErrInvalidState)
+
+	require.NotEqual(t, s.State, oidc.NewState("/admin/", now).State)
+}
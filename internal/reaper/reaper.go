@@ -0,0 +1,61 @@
+package reaper
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+)
+
+//go:generate go run github.com/vektra/mockery/v2@latest --name=ExpiredDeleter
+type ExpiredDeleter interface {
+	DeleteExpired(ctx context.Context, now time.Time) ([]string, error)
+	PurgeDeleted(ctx context.Context, before time.Time) ([]string, error)
+}
+
+// Reaper purges expired links from storage, as well as deleted links once
+// their grace period is over. The scheduler runs it periodically.
+type Reaper struct {
+	log     *slog.Logger
+	deleter ExpiredDeleter
+	grace   time.Duration
+}
+
+// New returns a reaper. Deleted links can be restored, and their aliases
+// stay taken, for grace; 0 keeps them forever.
+func New(log *slog.Logger, deleter ExpiredDeleter, grace time.Duration) *Reaper {
+	return &Reaper{
+		log:     log.With(slog.String("component", "reaper")),
+		deleter: deleter,
+		grace:   grace,
+	}
+}
+
+// Reap purges expired links and deleted ones past their grace period.
+func (r *Reaper) Reap(ctx context.Context) error {
+	const op = "reaper.Reap"
+
+	now := time.Now()
+
+	deleted, err := r.deleter.DeleteExpired(ctx, now)
+	if len(deleted) > 0 {
+		r.log.Info("expired urls deleted", slog.Int("count", len(deleted)))
+	}
+	if err != nil {
+		return fmt.Errorf("%s: delete expired: %w", op, err)
+	}
+
+	if r.grace <= 0 {
+		return nil
+	}
+
+	purged, err := r.deleter.PurgeDeleted(ctx, now.Add(-r.grace))
+	if len(purged) > 0 {
+		r.log.Info("deleted urls purged", slog.Int("count", len(purged)))
+	}
+	if err != nil {
+		return fmt.Errorf("%s: purge deleted: %w", op, err)
+	}
+
+	return nil
+}
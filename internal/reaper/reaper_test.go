@@ -0,0 +1,67 @@
+package reaper_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"url-shortener/internal/lib/logger/handlers/slogdiscard"
+	"url-shortener/internal/reaper"
+	"url-shortener/internal/reaper/mocks"
+)
+
+func TestReaper_Reap(t *testing.T) {
+	cases := []struct {
+		name       string
+		grace      time.Duration
+		mockError  error
+		purgeError error
+	}{
+		{
+			name: "Success",
+		},
+		{
+			name:  "Purge Deleted",
+			grace: time.Hour,
+		},
+		{
+			name:      "DeleteExpired Error",
+			grace:     time.Hour,
+			mockError: errors.New("unexpected error"),
+		},
+		{
+			name:       "PurgeDeleted Error",
+			grace:      time.Hour,
+			purgeError: errors.New("unexpected error"),
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			deleterMock := mocks.NewExpiredDeleter(t)
+
+			deleterMock.On("DeleteExpired", mock.Anything, mock.AnythingOfType("time.Time")).
+				Return([]string{"expired"}, tc.mockError).
+				Once()
+			if tc.grace > 0 && tc.mockError == nil {
+				deleterMock.On("PurgeDeleted", mock.Anything, mock.MatchedBy(func(before time.Time) bool {
+					return time.Until(before) < -tc.grace+time.Minute
+				})).
+					Return([]string{"deleted"}, tc.purgeError).
+					Once()
+			}
+
+			err := reaper.New(slogdiscard.NewDiscardLogger(), deleterMock, tc.grace).Reap(context.Background())
+
+			if tc.mockError != nil || tc.purgeError != nil {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+		})
+	}
+}
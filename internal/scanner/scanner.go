@@ -0,0 +1,110 @@
+package scanner
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"time"
+
+	"url-shortener/internal/lib/logger/sl"
+	"url-shortener/internal/storage"
+)
+
+// pageSize is how many links are read and checked at once.
+const pageSize = 500
+
+//go:generate go run github.com/vektra/mockery/v2@latest --name=URLStore
+type URLStore interface {
+	ListURLs(ctx context.Context, filter storage.ListFilter, limit int, offset int, desc bool) ([]storage.URL, error)
+	FlagURL(ctx context.Context, alias string, threat string) error
+}
+
+//go:generate go run github.com/vektra/mockery/v2@latest --name=URLChecker
+type URLChecker interface {
+	Check(ctx context.Context, urls []string) ([]string, error)
+}
+
+// Scanner periodically re-checks the targets of all links, since a site
+// can turn malicious after its link was created, and flags those reported
+// as unsafe.
+type Scanner struct {
+	log      *slog.Logger
+	store    URLStore
+	checker  URLChecker
+	interval time.Duration
+}
+
+func New(log *slog.Logger, store URLStore, checker URLChecker, interval time.Duration) *Scanner {
+	return &Scanner{
+		log:      log.With(slog.String("component", "scanner")),
+		store:    store,
+		checker:  checker,
+		interval: interval,
+	}
+}
+
+// Run scans all links every interval until ctx is cancelled.
+func (s *Scanner) Run(ctx context.Context) {
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			s.scan(ctx)
+		}
+	}
+}
+
+func (s *Scanner) scan(ctx context.Context) {
+	var checked, flagged int
+	for offset := 0; ; offset += pageSize {
+		urls, err := s.store.ListURLs(ctx, storage.ListFilter{}, pageSize, offset, false)
+		if err != nil {
+			s.log.Error("failed to list urls", sl.Err(err))
+			return
+		}
+
+		targets := make([]string, len(urls))
+		for i, u := range urls {
+			targets[i] = u.URL
+		}
+
+		threats, err := s.checker.Check(ctx, targets)
+		if err != nil {
+			s.log.Error("failed to check urls", sl.Err(err))
+			return
+		}
+		checked += len(urls)
+
+		for i, threat := range threats {
+			if threat == "" {
+				continue
+			}
+
+			err := s.store.FlagURL(ctx, urls[i].Alias, threat)
+			if errors.Is(err, storage.ErrUrlNotFound) {
+				// Already blocked or deleted since it was listed.
+				continue
+			}
+			if err != nil {
+				s.log.Error("failed to flag url", slog.String("alias", urls[i].Alias), sl.Err(err))
+				continue
+			}
+
+			flagged++
+			s.log.Warn("url flagged as unsafe",
+				slog.String("alias", urls[i].Alias),
+				slog.String("threat", threat),
+			)
+		}
+
+		if len(urls) < pageSize {
+			break
+		}
+	}
+
+	s.log.Info("urls scanned", slog.Int("checked", checked), slog.Int("flagged", flagged))
+}
@@ -0,0 +1,52 @@
+package scanner_test
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/mock"
+
+	"url-shortener/internal/lib/logger/handlers/slogdiscard"
+	"url-shortener/internal/scanner"
+	"url-shortener/internal/scanner/mocks"
+	"url-shortener/internal/storage"
+)
+
+func TestScanner_Run(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	urls := []storage.URL{
+		{Alias: "good", URL: "https://example.com"},
+		{Alias: "bad", URL: "https://malware.test"},
+		{Alias: "gone", URL: "https://phishing.test"},
+	}
+
+	storeMock := mocks.NewURLStore(t)
+	checkerMock := mocks.NewURLChecker(t)
+
+	storeMock.On("ListURLs", mock.Anything, storage.ListFilter{}, mock.AnythingOfType("int"), 0, false).Return(urls, nil)
+	checkerMock.On("Check", mock.Anything, []string{"https://example.com", "https://malware.test", "https://phishing.test"}).
+		Return([]string{"", "MALWARE", "SOCIAL_ENGINEERING"}, nil)
+	storeMock.On("FlagURL", mock.Anything, "gone", "SOCIAL_ENGINEERING").Return(storage.ErrUrlNotFound)
+
+	// A tick may race with cancellation, so more than one scan is fine.
+	var once sync.Once
+	storeMock.On("FlagURL", mock.Anything, "bad", "MALWARE").
+		Return(nil).
+		Run(func(mock.Arguments) { once.Do(cancel) })
+
+	done := make(chan struct{})
+	go func() {
+		scanner.New(slogdiscard.NewDiscardLogger(), storeMock, checkerMock, time.Millisecond).Run(ctx)
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("scanner did not stop after context cancellation")
+	}
+}
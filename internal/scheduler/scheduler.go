@@ -0,0 +1,88 @@
+package scheduler
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+	"time"
+
+	"url-shortener/internal/lib/logger/sl"
+)
+
+//go:generate go run github.com/vektra/mockery/v2@latest --name=Recorder
+type Recorder interface {
+	ObserveJob(job string, duration time.Duration, err error)
+}
+
+// Job is a maintenance task run every Interval.
+type Job struct {
+	// Name identifies the job in logs and metrics, e.g. "vacuum".
+	Name     string
+	Interval time.Duration
+	Run      func(ctx context.Context) error
+}
+
+// Scheduler runs maintenance jobs in the background, logging and
+// recording the duration and outcome of every run.
+type Scheduler struct {
+	log      *slog.Logger
+	recorder Recorder
+	jobs     []Job
+}
+
+func New(log *slog.Logger, recorder Recorder) *Scheduler {
+	return &Scheduler{
+		log:      log.With(slog.String("component", "scheduler")),
+		recorder: recorder,
+	}
+}
+
+// Add registers job. Jobs with an interval of 0 are disabled and never run.
+func (s *Scheduler) Add(job Job) {
+	if job.Interval <= 0 {
+		s.log.Info("job disabled", slog.String("job", job.Name))
+		return
+	}
+
+	s.jobs = append(s.jobs, job)
+}
+
+// Run runs every job on its own interval until ctx is cancelled and waits
+// for the runs in progress. A job that overruns its interval skips the
+// ticks it missed rather than running twice at once.
+func (s *Scheduler) Run(ctx context.Context) {
+	var wg sync.WaitGroup
+	for _, job := range s.jobs {
+		wg.Go(func() {
+			ticker := time.NewTicker(job.Interval)
+			defer ticker.Stop()
+
+			for {
+				select {
+				case <-ctx.Done():
+					return
+				case <-ticker.C:
+					s.run(ctx, job)
+				}
+			}
+		})
+	}
+	wg.Wait()
+}
+
+func (s *Scheduler) run(ctx context.Context, job Job) {
+	log := s.log.With(slog.String("job", job.Name))
+
+	start := time.Now()
+	err := job.Run(ctx)
+	duration := time.Since(start)
+
+	s.recorder.ObserveJob(job.Name, duration, err)
+
+	if err != nil {
+		log.Error("job failed", slog.Duration("duration", duration), sl.Err(err))
+		return
+	}
+
+	log.Debug("job finished", slog.Duration("duration", duration))
+}
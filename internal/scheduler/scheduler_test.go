@@ -0,0 +1,62 @@
+package scheduler_test
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"url-shortener/internal/lib/logger/handlers/slogdiscard"
+	"url-shortener/internal/scheduler"
+	"url-shortener/internal/scheduler/mocks"
+)
+
+func TestScheduler_Run(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	errFailed := errors.New("failed")
+
+	recorderMock := mocks.NewRecorder(t)
+	recorderMock.On("ObserveJob", "ok", mock.AnythingOfType("time.Duration"), nil).Maybe()
+	recorderMock.On("ObserveJob", "failing", mock.AnythingOfType("time.Duration"), errFailed).Maybe()
+
+	var ok, failing atomic.Int32
+	s := scheduler.New(slogdiscard.NewDiscardLogger(), recorderMock)
+	s.Add(scheduler.Job{Name: "ok", Interval: time.Millisecond, Run: func(context.Context) error {
+		ok.Add(1)
+		return nil
+	}})
+	s.Add(scheduler.Job{Name: "failing", Interval: time.Millisecond, Run: func(context.Context) error {
+		failing.Add(1)
+		return errFailed
+	}})
+	s.Add(scheduler.Job{Name: "disabled", Run: func(context.Context) error {
+		t.Error("disabled job ran")
+		return nil
+	}})
+
+	done := make(chan struct{})
+	go func() {
+		s.Run(ctx)
+		close(done)
+	}()
+
+	require.Eventually(t, func() bool {
+		return ok.Load() >= 2 && failing.Load() >= 2
+	}, time.Second, time.Millisecond)
+
+	cancel()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("scheduler did not stop after context cancellation")
+	}
+
+	recorderMock.AssertCalled(t, "ObserveJob", "ok", mock.AnythingOfType("time.Duration"), nil)
+	recorderMock.AssertCalled(t, "ObserveJob", "failing", mock.AnythingOfType("time.Duration"), errFailed)
+}
@@ -0,0 +1,31 @@
+package anonymized
+
+import (
+	"context"
+
+	"url-shortener/internal/lib/privacy"
+	"url-shortener/internal/storage"
+	"url-shortener/internal/storage/instrumented"
+)
+
+// Storage strips personal data from what it passes to the backend: the
+// referrers of clicks lose their secrets and audit entries their full
+// client IP.
+type Storage struct {
+	instrumented.Backend
+	anonymizer *privacy.Anonymizer
+}
+
+func New(backend instrumented.Backend, anonymizer *privacy.Anonymizer) *Storage {
+	return &Storage{Backend: backend, anonymizer: anonymizer}
+}
+
+func (s *Storage) SaveClick(ctx context.Context, click storage.Click) error {
+	click.Referrer = s.anonymizer.URL(click.Referrer)
+	return s.Backend.SaveClick(ctx, click)
+}
+
+func (s *Storage) SaveAuditEntry(ctx context.Context, e storage.AuditEntry) error {
+	e.IP = s.anonymizer.IP(e.IP)
+	return s.Backend.SaveAuditEntry(ctx, e)
+}
@@ -0,0 +1,40 @@
+package anonymized_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"url-shortener/internal/lib/privacy"
+	"url-shortener/internal/storage"
+	"url-shortener/internal/storage/anonymized"
+	"url-shortener/internal/storage/memory"
+)
+
+func TestStorage(t *testing.T) {
+	ctx := context.Background()
+	anonymizer, err := privacy.New(privacy.Options{IPMode: privacy.IPTruncate})
+	require.NoError(t, err)
+	s := anonymized.New(memory.New(), anonymizer)
+
+	_, err = s.SaveURL(ctx, storage.URL{URL: "https://example.com", Alias: "promo"})
+	require.NoError(t, err)
+
+	require.NoError(t, s.SaveClick(ctx, storage.Click{
+		Alias:     "promo",
+		ClickedAt: time.Now(),
+		Referrer:  "https://mail.example.org/inbox?token=abc&page=2",
+	}))
+	clicks, err := s.ListClicks(ctx, "promo", storage.ClickFilter{}, 0, 10)
+	require.NoError(t, err)
+	require.Len(t, clicks, 1)
+	require.Equal(t, "https://mail.example.org/inbox?token=REDACTED&page=2", clicks[0].Referrer)
+
+	require.NoError(t, s.SaveAuditEntry(ctx, storage.AuditEntry{Action: "create", Target: "promo", IP: "203.0.113.57"}))
+	entries, err := s.ListAuditLog(ctx, storage.AuditFilter{}, 10, 0)
+	require.NoError(t, err)
+	require.Len(t, entries, 1)
+	require.Equal(t, "203.0.113.0", entries[0].IP)
+}
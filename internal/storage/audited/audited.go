@@ -0,0 +1,368 @@
+// Package audited records every change made through the API in the audit
+// log of the backend.
+package audited
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"strconv"
+	"time"
+
+	"url-shortener/internal/lib/logger/sl"
+	"url-shortener/internal/storage"
+	"url-shortener/internal/storage/instrumented"
+)
+
+// Actor is who makes a request.
+type Actor struct {
+	User     string
+	APIKeyID int64
+	IP       string
+}
+
+type actorCtxKey struct{}
+
+// WithActor returns a copy of ctx carrying the actor the changes made with
+// it are attributed to.
+func WithActor(ctx context.Context, actor Actor) context.Context {
+	return context.WithValue(ctx, actorCtxKey{}, actor)
+}
+
+// ActorFromContext returns the actor set by WithActor; changes without one,
+// e.g. by background jobs, have no actor.
+func ActorFromContext(ctx context.Context) Actor {
+	actor, _ := ctx.Value(actorCtxKey{}).(Actor)
+	return actor
+}
+
+// EntrySaver writes the audit log.
+type EntrySaver interface {
+	SaveAuditEntry(ctx context.Context, e storage.AuditEntry) error
+}
+
+// Storage passes every call to the backend and records links, API keys,
+// campaigns and users that were created or changed. The changes are not undone if the
+// entry cannot be saved; the failure is logged instead.
+type Storage struct {
+	instrumented.Backend
+	log *slog.Logger
+}
+
+func New(log *slog.Logger, backend instrumented.Backend) *Storage {
+	return &Storage{Backend: backend, log: log}
+}
+
+// link is what the log shows of a link before and after a change.
+type link struct {
+	URL          string     `json:"url"`
+	Owner        string     `json:"owner,omitempty"`
+	ExpiresAt    *time.Time `json:"expires_at,omitempty"`
+	ActiveFrom   *time.Time `json:"active_from,omitempty"`
+	ActiveUntil  *time.Time `json:"active_until,omitempty"`
+	MaxClicks    int64      `json:"max_clicks,omitempty"`
+	RedirectType int        `json:"redirect_type,omitempty"`
+	CacheMaxAge  int        `json:"cache_max_age,omitempty"`
+	Protected    bool       `json:"protected,omitempty"`
+	Tags         []string   `json:"tags,omitempty"`
+	OpenGraph    *openGraph `json:"og,omitempty"`
+	Campaign     int64      `json:"campaign,omitempty"`
+}
+
+type openGraph struct {
+	Title       string `json:"title,omitempty"`
+	Description string `json:"description,omitempty"`
+	Image       string `json:"image,omitempty"`
+}
+
+func snapshot(u storage.URL) link {
+	l := link{
+		URL:          u.URL,
+		Owner:        u.Owner,
+		ExpiresAt:    timePtr(u.ExpiresAt),
+		ActiveFrom:   timePtr(u.ActiveFrom),
+		ActiveUntil:  timePtr(u.ActiveUntil),
+		MaxClicks:    u.MaxClicks,
+		RedirectType: u.RedirectType,
+		CacheMaxAge:  u.CacheMaxAge,
+		Protected:    u.PasswordHash != "",
+		Tags:         u.Tags,
+		Campaign:     u.CampaignID,
+	}
+	if u.OpenGraph != (storage.Metadata{}) {
+		og := openGraph(u.OpenGraph)
+		l.OpenGraph = &og
+	}
+
+	return l
+}
+
+func timePtr(t time.Time) *time.Time {
+	if t.IsZero() {
+		return nil
+	}
+	t = t.UTC()
+	return &t
+}
+
+// current returns the link as it is now, or nil if it cannot be read,
+// e.g. because it has expired.
+func (s *Storage) current(ctx context.Context, alias string) any {
+	u, err := s.Backend.GetURL(ctx, alias)
+	if err != nil {
+		return nil
+	}
+
+	return snapshot(u)
+}
+
+// record saves an entry; before and after are marshalled to JSON unless
+// nil.
+func (s *Storage) record(ctx context.Context, action string, target string, before any, after any) {
+	actor := ActorFromContext(ctx)
+	e := storage.AuditEntry{
+		Time:     time.Now().UTC(),
+		Action:   action,
+		Target:   target,
+		Actor:    actor.User,
+		APIKeyID: actor.APIKeyID,
+		IP:       actor.IP,
+		Before:   marshal(before),
+		After:    marshal(after),
+	}
+
+	// The change is done; the entry is saved even if the request is gone.
+	if err := s.Backend.SaveAuditEntry(context.WithoutCancel(ctx), e); err != nil {
+		s.log.Error("failed to save audit entry",
+			slog.String("action", action),
+			slog.String("target", target),
+			sl.Err(err),
+		)
+	}
+}
+
+func marshal(v any) string {
+	if v == nil {
+		return ""
+	}
+
+	data, err := json.Marshal(v)
+	if err != nil {
+		return ""
+	}
+
+	return string(data)
+}
+
+func (s *Storage) SaveURL(ctx context.Context, u storage.URL) (int64, error) {
+	id, err := s.Backend.SaveURL(ctx, u)
+	if err == nil {
+		s.record(ctx, storage.AuditLinkCreate, u.Alias, nil, snapshot(u))
+	}
+
+	return id, err
+}
+
+func (s *Storage) SaveURLs(ctx context.Context, urls []storage.URL) ([]error, error) {
+	errs, err := s.Backend.SaveURLs(ctx, urls)
+	if err == nil {
+		for i, u := range urls {
+			if errs[i] == nil {
+				s.record(ctx, storage.AuditLinkCreate, u.Alias, nil, snapshot(u))
+			}
+		}
+	}
+
+	return errs, err
+}
+
+func (s *Storage) UpdateURL(ctx context.Context, alias string, owner string, newURL string) error {
+	before := s.current(ctx, alias)
+
+	err := s.Backend.UpdateURL(ctx, alias, owner, newURL)
+	if err == nil {
+		s.record(ctx, storage.AuditLinkUpdate, alias, before, s.current(ctx, alias))
+	}
+
+	return err
+}
+
+func (s *Storage) DeleteURL(ctx context.Context, alias string, owner string) error {
+	before := s.current(ctx, alias)
+
+	err := s.Backend.DeleteURL(ctx, alias, owner)
+	if err == nil {
+		s.record(ctx, storage.AuditLinkDelete, alias, before, nil)
+	}
+
+	return err
+}
+
+func (s *Storage) RestoreURL(ctx context.Context, alias string, owner string) error {
+	err := s.Backend.RestoreURL(ctx, alias, owner)
+	if err == nil {
+		s.record(ctx, storage.AuditLinkRestore, alias, nil, s.current(ctx, alias))
+	}
+
+	return err
+}
+
+type renamed struct {
+	Alias string `json:"alias"`
+}
+
+func (s *Storage) RenameURL(ctx context.Context, alias string, owner string, newAlias string, forwardUntil time.Time) error {
+	err := s.Backend.RenameURL(ctx, alias, owner, newAlias, forwardUntil)
+	if err == nil {
+		s.record(ctx, storage.AuditLinkRename, alias, renamed{Alias: alias}, renamed{Alias: newAlias})
+	}
+
+	return err
+}
+
+type blocked struct {
+	Reason    string `json:"reason"`
+	Reference string `json:"reference,omitempty"`
+}
+
+func (s *Storage) BlockURL(ctx context.Context, alias string, reason string, reference string) error {
+	before := s.current(ctx, alias)
+
+	err := s.Backend.BlockURL(ctx, alias, reason, reference)
+	if err == nil {
+		s.record(ctx, storage.AuditLinkBlock, alias, before, blocked{Reason: reason, Reference: reference})
+	}
+
+	return err
+}
+
+type apiKey struct {
+	Name  string `json:"name"`
+	Owner string `json:"owner,omitempty"`
+}
+
+func (s *Storage) SaveAPIKey(ctx context.Context, key storage.APIKey) (int64, error) {
+	id, err := s.Backend.SaveAPIKey(ctx, key)
+	if err == nil {
+		// Only the name and owner: not even the hash of the key is logged.
+		s.record(ctx, storage.AuditKeyCreate, strconv.FormatInt(id, 10), nil, apiKey{Name: key.Name, Owner: key.Owner})
+	}
+
+	return id, err
+}
+
+func (s *Storage) RevokeAPIKey(ctx context.Context, id int64, owner string) error {
+	err := s.Backend.RevokeAPIKey(ctx, id, owner)
+	if err == nil {
+		s.record(ctx, storage.AuditKeyRevoke, strconv.FormatInt(id, 10), nil, nil)
+	}
+
+	return err
+}
+
+// campaign is what the log shows of a campaign before and after a change.
+// Unlike links, it leaves out the owner: erasing a user does not touch the
+// entries of their campaigns.
+type campaign struct {
+	Name      string     `json:"name"`
+	UTM       *utm       `json:"utm,omitempty"`
+	ExpiresAt *time.Time `json:"expires_at,omitempty"`
+	Domain    string     `json:"domain,omitempty"`
+}
+
+type utm struct {
+	Source   string `json:"source,omitempty"`
+	Medium   string `json:"medium,omitempty"`
+	Campaign string `json:"campaign,omitempty"`
+}
+
+func campaignSnapshot(c storage.Campaign) campaign {
+	snap := campaign{
+		Name:      c.Name,
+		ExpiresAt: timePtr(c.ExpiresAt),
+		Domain:    c.Domain,
+	}
+	if c.UTM != (storage.UTM{}) {
+		u := utm(c.UTM)
+		snap.UTM = &u
+	}
+
+	return snap
+}
+
+func (s *Storage) SaveCampaign(ctx context.Context, c storage.Campaign) (int64, error) {
+	id, err := s.Backend.SaveCampaign(ctx, c)
+	if err == nil {
+		s.record(ctx, storage.AuditCampaignCreate, strconv.FormatInt(id, 10), nil, campaignSnapshot(c))
+	}
+
+	return id, err
+}
+
+func (s *Storage) UpdateCampaign(ctx context.Context, c storage.Campaign, owner string) error {
+	var before any
+	if old, err := s.Backend.GetCampaign(ctx, c.ID, owner); err == nil {
+		before = campaignSnapshot(old)
+	}
+
+	err := s.Backend.UpdateCampaign(ctx, c, owner)
+	if err == nil {
+		s.record(ctx, storage.AuditCampaignUpdate, strconv.FormatInt(c.ID, 10), before, campaignSnapshot(c))
+	}
+
+	return err
+}
+
+func (s *Storage) DeleteCampaign(ctx context.Context, id int64, owner string) error {
+	var before any
+	if old, err := s.Backend.GetCampaign(ctx, id, owner); err == nil {
+		before = campaignSnapshot(old)
+	}
+
+	err := s.Backend.DeleteCampaign(ctx, id, owner)
+	if err == nil {
+		s.record(ctx, storage.AuditCampaignDelete, strconv.FormatInt(id, 10), before, nil)
+	}
+
+	return err
+}
+
+type user struct {
+	Role string `json:"role"`
+}
+
+// erased is what the log keeps of a user whose data was deleted: how much
+// there was, not what it was.
+type erased struct {
+	Links        int   `json:"links"`
+	Clicks       int64 `json:"clicks"`
+	APIKeys      int64 `json:"api_keys"`
+	AuditEntries int64 `json:"audit_entries"`
+	Campaigns    int64 `json:"campaigns"`
+	User         bool  `json:"user"`
+}
+
+func (s *Storage) DeleteUserData(ctx context.Context, username string) (storage.UserDataReport, error) {
+	report, err := s.Backend.DeleteUserData(ctx, username)
+	if err == nil && !report.Empty() {
+		s.record(ctx, storage.AuditUserErase, username, nil, erased{
+			Links:        len(report.Aliases),
+			Clicks:       report.Clicks,
+			APIKeys:      report.APIKeys,
+			AuditEntries: report.AuditEntries,
+			Campaigns:    report.Campaigns,
+			User:         report.User,
+		})
+	}
+
+	return report, err
+}
+
+func (s *Storage) SaveUser(ctx context.Context, u storage.User) (int64, error) {
+	id, err := s.Backend.SaveUser(ctx, u)
+	if err == nil {
+		s.record(ctx, storage.AuditUserCreate, u.Username, nil, user{Role: u.Role})
+	}
+
+	return id, err
+}
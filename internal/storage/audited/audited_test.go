@@ -0,0 +1,62 @@
+package audited_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"url-shortener/internal/lib/logger/handlers/slogdiscard"
+	"url-shortener/internal/storage"
+	"url-shortener/internal/storage/audited"
+	"url-shortener/internal/storage/memory"
+)
+
+func TestStorage_Records(t *testing.T) {
+	backend := memory.New()
+	s := audited.New(slogdiscard.NewDiscardLogger(), backend)
+	ctx := audited.WithActor(context.Background(), audited.Actor{User: "alice", APIKeyID: 7, IP: "203.0.113.9"})
+
+	_, err := s.SaveURL(ctx, storage.URL{URL: "https://example.com", Alias: "example", Owner: "alice"})
+	require.NoError(t, err)
+	require.NoError(t, s.UpdateURL(ctx, "example", "alice", "https://example.org"))
+
+	// Failed changes are not recorded.
+	require.ErrorIs(t, s.DeleteURL(ctx, "example", "bob"), storage.ErrUrlNotFound)
+	require.NoError(t, s.DeleteURL(ctx, "example", "alice"))
+
+	_, err = s.SaveAPIKey(context.Background(), storage.APIKey{Name: "ci", Owner: "alice", Hash: "secret-hash"})
+	require.NoError(t, err)
+
+	entries, err := s.ListAuditLog(ctx, storage.AuditFilter{}, 10, 0)
+	require.NoError(t, err)
+	require.Len(t, entries, 4)
+
+	key := entries[0]
+	require.Equal(t, storage.AuditKeyCreate, key.Action)
+	require.Equal(t, "1", key.Target)
+	require.Empty(t, key.Actor)
+	require.NotContains(t, key.After, "secret-hash")
+
+	deleted := entries[1]
+	require.Equal(t, storage.AuditLinkDelete, deleted.Action)
+	require.JSONEq(t, `{"url": "https://example.org", "owner": "alice"}`, deleted.Before)
+	require.Empty(t, deleted.After)
+
+	updated := entries[2]
+	require.Equal(t, storage.AuditLinkUpdate, updated.Action)
+	require.Equal(t, "example", updated.Target)
+	require.Equal(t, "alice", updated.Actor)
+	require.Equal(t, int64(7), updated.APIKeyID)
+	require.Equal(t, "203.0.113.9", updated.IP)
+	require.JSONEq(t, `{"url": "https://example.com", "owner": "alice"}`, updated.Before)
+	require.JSONEq(t, `{"url": "https://example.org", "owner": "alice"}`, updated.After)
+
+	require.Equal(t, storage.AuditLinkCreate, entries[3].Action)
+	require.Empty(t, entries[3].Before)
+
+	entries, err = s.ListAuditLog(ctx, storage.AuditFilter{Target: "example"}, 1, 1)
+	require.NoError(t, err)
+	require.Len(t, entries, 1)
+	require.Equal(t, storage.AuditLinkUpdate, entries[0].Action)
+}
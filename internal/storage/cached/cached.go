@@ -0,0 +1,108 @@
+package cached
+
+import (
+	"context"
+	"time"
+
+	"url-shortener/internal/lib/lru"
+	"url-shortener/internal/storage"
+	"url-shortener/internal/storage/instrumented"
+)
+
+// Storage serves GetURL for hot aliases from an in-process LRU cache and
+// passes everything else to the backend. Links are dropped from the cache
+// whenever they are changed through it; changes made by other instances
+// sharing the backend show up once the TTL runs out.
+type Storage struct {
+	instrumented.Backend
+	links *lru.Cache[string, storage.URL]
+}
+
+// New caches up to size links for at most ttl each.
+func New(backend instrumented.Backend, size int, ttl time.Duration) *Storage {
+	return &Storage{
+		Backend: backend,
+		links:   lru.New[string, storage.URL](size, ttl),
+	}
+}
+
+// Flush drops every cached link, e.g. after the database was restored from
+// a backup.
+func (s *Storage) Flush(_ context.Context) error {
+	s.links.Purge()
+
+	return nil
+}
+
+// GetURL caches only links that resolve; errors always go to the backend.
+// Links with a click limit are not cached, as every click changes whether
+// they still resolve.
+func (s *Storage) GetURL(ctx context.Context, alias string) (storage.URL, error) {
+	if u, ok := s.links.Get(alias); ok {
+		if u.Expired(time.Now()) {
+			s.links.Remove(alias)
+			return storage.URL{}, storage.ErrUrlExpired
+		}
+		return u, nil
+	}
+
+	u, err := s.Backend.GetURL(ctx, alias)
+	if err == nil && u.MaxClicks == 0 {
+		s.links.Add(alias, u)
+	}
+
+	return u, err
+}
+
+func (s *Storage) DeleteURL(ctx context.Context, alias string, owner string) error {
+	err := s.Backend.DeleteURL(ctx, alias, owner)
+	s.links.Remove(alias)
+
+	return err
+}
+
+func (s *Storage) RenameURL(ctx context.Context, alias string, owner string, newAlias string, forwardUntil time.Time) error {
+	err := s.Backend.RenameURL(ctx, alias, owner, newAlias, forwardUntil)
+	s.links.Remove(alias)
+
+	return err
+}
+
+func (s *Storage) UpdateURL(ctx context.Context, alias string, owner string, newURL string) error {
+	err := s.Backend.UpdateURL(ctx, alias, owner, newURL)
+	s.links.Remove(alias)
+
+	return err
+}
+
+func (s *Storage) BlockURL(ctx context.Context, alias string, reason string, reference string) error {
+	err := s.Backend.BlockURL(ctx, alias, reason, reference)
+	s.links.Remove(alias)
+
+	return err
+}
+
+func (s *Storage) FlagURL(ctx context.Context, alias string, threat string) error {
+	err := s.Backend.FlagURL(ctx, alias, threat)
+	s.links.Remove(alias)
+
+	return err
+}
+
+func (s *Storage) DeleteExpired(ctx context.Context, now time.Time) ([]string, error) {
+	deleted, err := s.Backend.DeleteExpired(ctx, now)
+	for _, alias := range deleted {
+		s.links.Remove(alias)
+	}
+
+	return deleted, err
+}
+
+func (s *Storage) DeleteUserData(ctx context.Context, username string) (storage.UserDataReport, error) {
+	report, err := s.Backend.DeleteUserData(ctx, username)
+	for _, alias := range report.Aliases {
+		s.links.Remove(alias)
+	}
+
+	return report, err
+}
@@ -0,0 +1,87 @@
+package cached_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"url-shortener/internal/storage"
+	"url-shortener/internal/storage/cached"
+	"url-shortener/internal/storage/memory"
+)
+
+func TestStorage_GetURL(t *testing.T) {
+	backend := memory.New()
+	s := cached.New(backend, 10, time.Minute)
+	ctx := context.Background()
+
+	_, err := s.SaveURL(ctx, storage.URL{URL: "https://example.com", Alias: "example"})
+	require.NoError(t, err)
+
+	u, err := s.GetURL(ctx, "example")
+	require.NoError(t, err)
+	require.Equal(t, "https://example.com", u.URL)
+
+	// Changes made behind the cache's back are not seen...
+	require.NoError(t, backend.UpdateURL(ctx, "example", "", "https://example.org"))
+	u, err = s.GetURL(ctx, "example")
+	require.NoError(t, err)
+	require.Equal(t, "https://example.com", u.URL)
+
+	// ...while changes made through it are.
+	require.NoError(t, s.UpdateURL(ctx, "example", "", "https://example.net"))
+	u, err = s.GetURL(ctx, "example")
+	require.NoError(t, err)
+	require.Equal(t, "https://example.net", u.URL)
+
+	// Flushing lets changes made behind its back through, as after a
+	// restore from a backup.
+	require.NoError(t, backend.UpdateURL(ctx, "example", "", "https://example.org"))
+	require.NoError(t, s.Flush(ctx))
+	u, err = s.GetURL(ctx, "example")
+	require.NoError(t, err)
+	require.Equal(t, "https://example.org", u.URL)
+
+	require.NoError(t, s.BlockURL(ctx, "example", "DMCA takedown", ""))
+	_, err = s.GetURL(ctx, "example")
+	require.ErrorIs(t, err, storage.ErrUrlBlocked)
+}
+
+func TestStorage_Invalidation(t *testing.T) {
+	s := cached.New(memory.New(), 10, time.Minute)
+	ctx := context.Background()
+	now := time.Now()
+
+	_, err := s.SaveURL(ctx, storage.URL{URL: "https://example.com", Alias: "deleted"})
+	require.NoError(t, err)
+	_, err = s.SaveURL(ctx, storage.URL{URL: "https://example.com", Alias: "flagged"})
+	require.NoError(t, err)
+	_, err = s.SaveURL(ctx, storage.URL{URL: "https://example.com", Alias: "renamed"})
+	require.NoError(t, err)
+	_, err = s.SaveURL(ctx, storage.URL{URL: "https://example.com", Alias: "expiring", ExpiresAt: now.Add(time.Hour)})
+	require.NoError(t, err)
+
+	for _, alias := range []string{"deleted", "flagged", "renamed", "expiring"} {
+		_, err := s.GetURL(ctx, alias)
+		require.NoError(t, err)
+	}
+
+	require.NoError(t, s.DeleteURL(ctx, "deleted", ""))
+	_, err = s.GetURL(ctx, "deleted")
+	require.ErrorIs(t, err, storage.ErrUrlNotFound)
+
+	require.NoError(t, s.FlagURL(ctx, "flagged", "MALWARE"))
+	_, err = s.GetURL(ctx, "flagged")
+	require.ErrorIs(t, err, storage.ErrUrlUnsafe)
+
+	require.NoError(t, s.RenameURL(ctx, "renamed", "", "new-name", time.Time{}))
+	_, err = s.GetURL(ctx, "renamed")
+	require.ErrorIs(t, err, storage.ErrUrlNotFound)
+
+	_, err = s.DeleteExpired(ctx, now.Add(2*time.Hour))
+	require.NoError(t, err)
+	_, err = s.GetURL(ctx, "expiring")
+	require.ErrorIs(t, err, storage.ErrUrlNotFound)
+}
@@ -0,0 +1,105 @@
+package casefolded
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"url-shortener/internal/storage"
+	"url-shortener/internal/storage/instrumented"
+)
+
+// Storage makes aliases case-insensitive: it lower-cases them before
+// passing calls to the backend, so links are saved and looked up in lower
+// case and /Abc leads where /abc does.
+type Storage struct {
+	instrumented.Backend
+}
+
+func New(backend instrumented.Backend) *Storage {
+	return &Storage{Backend: backend}
+}
+
+func (s *Storage) SaveURL(ctx context.Context, u storage.URL) (int64, error) {
+	u.Alias = strings.ToLower(u.Alias)
+	return s.Backend.SaveURL(ctx, u)
+}
+
+func (s *Storage) SaveURLs(ctx context.Context, urls []storage.URL) ([]error, error) {
+	folded := make([]storage.URL, len(urls))
+	for i, u := range urls {
+		u.Alias = strings.ToLower(u.Alias)
+		folded[i] = u
+	}
+
+	return s.Backend.SaveURLs(ctx, folded)
+}
+
+func (s *Storage) GetURL(ctx context.Context, alias string) (storage.URL, error) {
+	return s.Backend.GetURL(ctx, strings.ToLower(alias))
+}
+
+func (s *Storage) FlagURL(ctx context.Context, alias string, threat string) error {
+	return s.Backend.FlagURL(ctx, strings.ToLower(alias), threat)
+}
+
+func (s *Storage) ConsumeClick(ctx context.Context, alias string) error {
+	return s.Backend.ConsumeClick(ctx, strings.ToLower(alias))
+}
+
+func (s *Storage) DeleteURL(ctx context.Context, alias string, owner string) error {
+	return s.Backend.DeleteURL(ctx, strings.ToLower(alias), owner)
+}
+
+func (s *Storage) RestoreURL(ctx context.Context, alias string, owner string) error {
+	return s.Backend.RestoreURL(ctx, strings.ToLower(alias), owner)
+}
+
+func (s *Storage) RenameURL(ctx context.Context, alias string, owner string, newAlias string, forwardUntil time.Time) error {
+	return s.Backend.RenameURL(ctx, strings.ToLower(alias), owner, strings.ToLower(newAlias), forwardUntil)
+}
+
+func (s *Storage) UpdateURL(ctx context.Context, alias string, owner string, newURL string) error {
+	return s.Backend.UpdateURL(ctx, strings.ToLower(alias), owner, newURL)
+}
+
+func (s *Storage) BlockURL(ctx context.Context, alias string, reason string, reference string) error {
+	return s.Backend.BlockURL(ctx, strings.ToLower(alias), reason, reference)
+}
+
+func (s *Storage) GetLegalBlock(ctx context.Context, alias string) (storage.LegalBlock, error) {
+	return s.Backend.GetLegalBlock(ctx, strings.ToLower(alias))
+}
+
+func (s *Storage) GetOwner(ctx context.Context, alias string) (string, error) {
+	return s.Backend.GetOwner(ctx, strings.ToLower(alias))
+}
+
+func (s *Storage) AliasExists(ctx context.Context, alias string) (bool, error) {
+	return s.Backend.AliasExists(ctx, strings.ToLower(alias))
+}
+
+func (s *Storage) SetURLHealth(ctx context.Context, alias string, health storage.Health) error {
+	return s.Backend.SetURLHealth(ctx, strings.ToLower(alias), health)
+}
+
+func (s *Storage) SaveClick(ctx context.Context, click storage.Click) error {
+	click.Alias = strings.ToLower(click.Alias)
+	return s.Backend.SaveClick(ctx, click)
+}
+
+func (s *Storage) GetStats(ctx context.Context, alias string, since time.Time, includeBots bool) (storage.Stats, error) {
+	return s.Backend.GetStats(ctx, strings.ToLower(alias), since, includeBots)
+}
+
+func (s *Storage) GetReferrers(ctx context.Context, alias string, includeBots bool) ([]storage.ReferrerClicks, error) {
+	return s.Backend.GetReferrers(ctx, strings.ToLower(alias), includeBots)
+}
+
+func (s *Storage) GetCountries(ctx context.Context, alias string, includeBots bool) ([]storage.CountryClicks, error) {
+	return s.Backend.GetCountries(ctx, strings.ToLower(alias), includeBots)
+}
+
+func (s *Storage) ListClicks(ctx context.Context, alias string, filter storage.ClickFilter, afterID int64, limit int) ([]storage.Click, error) {
+	return s.Backend.ListClicks(ctx, strings.ToLower(alias), filter, afterID, limit)
+}
@@ -0,0 +1,44 @@
+package casefolded_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"url-shortener/internal/storage"
+	"url-shortener/internal/storage/casefolded"
+	"url-shortener/internal/storage/memory"
+)
+
+func TestStorage(t *testing.T) {
+	ctx := context.Background()
+	backend := memory.New()
+	s := casefolded.New(backend)
+
+	_, err := s.SaveURL(ctx, storage.URL{URL: "https://example.com", Alias: "Promo"})
+	require.NoError(t, err)
+
+	// Saved in lower case, so the backend alone finds only that spelling.
+	_, err = backend.GetURL(ctx, "Promo")
+	require.ErrorIs(t, err, storage.ErrUrlNotFound)
+
+	for _, alias := range []string{"promo", "PROMO", "pRoMo"} {
+		u, err := s.GetURL(ctx, alias)
+		require.NoError(t, err)
+		require.Equal(t, "promo", u.Alias)
+	}
+
+	_, err = s.SaveURL(ctx, storage.URL{URL: "https://example.org", Alias: "PROMO"})
+	require.ErrorIs(t, err, storage.ErrUrlExists)
+
+	require.NoError(t, s.SaveClick(ctx, storage.Click{Alias: "Promo", ClickedAt: time.Now()}))
+	stats, err := s.GetStats(ctx, "PROMO", time.Time{}, false)
+	require.NoError(t, err)
+	require.Equal(t, int64(1), stats.Total)
+
+	require.NoError(t, s.RenameURL(ctx, "Promo", "", "Sale", time.Time{}))
+	_, err = s.GetURL(ctx, "SALE")
+	require.NoError(t, err)
+}
@@ -0,0 +1,250 @@
+package encrypted
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+
+	"url-shortener/internal/lib/encrypt"
+	"url-shortener/internal/storage"
+	"url-shortener/internal/storage/instrumented"
+)
+
+// pageSize is how many links are decrypted at once when links are
+// searched by URL.
+const pageSize = 500
+
+// Storage encrypts destination URLs before they reach the backend and
+// decrypts them on the way back, so the database and the caches below it
+// hold only ciphertext. Audit snapshots, which contain the URLs too, are
+// encrypted as well. Links saved before encryption was turned on are read
+// as they are.
+type Storage struct {
+	instrumented.Backend
+	cipher *encrypt.Cipher
+}
+
+func New(backend instrumented.Backend, cipher *encrypt.Cipher) *Storage {
+	return &Storage{Backend: backend, cipher: cipher}
+}
+
+func (s *Storage) seal(u storage.URL) storage.URL {
+	u.URL = s.cipher.Encrypt(u.URL)
+	u.GeoTargets = s.sealTargets(u.GeoTargets)
+	u.DeviceTargets = s.sealTargets(u.DeviceTargets)
+	if len(u.Variants) > 0 {
+		u.Variants = slices.Clone(u.Variants)
+		for i := range u.Variants {
+			u.Variants[i].URL = s.cipher.Encrypt(u.Variants[i].URL)
+		}
+	}
+
+	return u
+}
+
+func (s *Storage) sealTargets(t storage.Targets) storage.Targets {
+	if len(t) == 0 {
+		return t
+	}
+
+	sealed := maps.Clone(t)
+	for k, v := range sealed {
+		sealed[k] = s.cipher.Encrypt(v)
+	}
+
+	return sealed
+}
+
+func (s *Storage) open(u storage.URL) (storage.URL, error) {
+	const op = "storage.encrypted.open"
+
+	var err error
+	if u.URL, err = s.cipher.Decrypt(u.URL); err != nil {
+		return storage.URL{}, fmt.Errorf("%s: %s: %w", op, u.Alias, err)
+	}
+	if u.GeoTargets, err = s.openTargets(u.GeoTargets); err != nil {
+		return storage.URL{}, fmt.Errorf("%s: %s: %w", op, u.Alias, err)
+	}
+	if u.DeviceTargets, err = s.openTargets(u.DeviceTargets); err != nil {
+		return storage.URL{}, fmt.Errorf("%s: %s: %w", op, u.Alias, err)
+	}
+	if len(u.Variants) > 0 {
+		u.Variants = slices.Clone(u.Variants)
+		for i := range u.Variants {
+			if u.Variants[i].URL, err = s.cipher.Decrypt(u.Variants[i].URL); err != nil {
+				return storage.URL{}, fmt.Errorf("%s: %s: %w", op, u.Alias, err)
+			}
+		}
+	}
+
+	return u, nil
+}
+
+func (s *Storage) openTargets(t storage.Targets) (storage.Targets, error) {
+	if len(t) == 0 {
+		return t, nil
+	}
+
+	opened := maps.Clone(t)
+	for k, v := range opened {
+		plain, err := s.cipher.Decrypt(v)
+		if err != nil {
+			return nil, err
+		}
+		opened[k] = plain
+	}
+
+	return opened, nil
+}
+
+func (s *Storage) SaveURL(ctx context.Context, u storage.URL) (int64, error) {
+	return s.Backend.SaveURL(ctx, s.seal(u))
+}
+
+func (s *Storage) SaveURLs(ctx context.Context, urls []storage.URL) ([]error, error) {
+	sealed := make([]storage.URL, len(urls))
+	for i, u := range urls {
+		sealed[i] = s.seal(u)
+	}
+
+	return s.Backend.SaveURLs(ctx, sealed)
+}
+
+func (s *Storage) GetURL(ctx context.Context, alias string) (storage.URL, error) {
+	u, err := s.Backend.GetURL(ctx, alias)
+	if err != nil {
+		return storage.URL{}, err
+	}
+
+	return s.open(u)
+}
+
+// GetAliasByURL falls back to the plain URL for links saved before
+// encryption was turned on.
+func (s *Storage) GetAliasByURL(ctx context.Context, url string, owner string) (string, error) {
+	alias, err := s.Backend.GetAliasByURL(ctx, s.cipher.Encrypt(url), owner)
+	if errors.Is(err, storage.ErrUrlNotFound) {
+		return s.Backend.GetAliasByURL(ctx, url, owner)
+	}
+
+	return alias, err
+}
+
+func (s *Storage) UpdateURL(ctx context.Context, alias string, owner string, newURL string) error {
+	return s.Backend.UpdateURL(ctx, alias, owner, s.cipher.Encrypt(newURL))
+}
+
+// ListURLs searches by URL itself, as the backend only sees ciphertext:
+// it decrypts every link that passes the rest of the filter.
+func (s *Storage) ListURLs(ctx context.Context, filter storage.ListFilter, limit int, offset int, desc bool) ([]storage.URL, error) {
+	if filter.URLContains == "" {
+		urls, err := s.Backend.ListURLs(ctx, filter, limit, offset, desc)
+		if err != nil {
+			return nil, err
+		}
+		return s.openAll(urls)
+	}
+
+	urls := []storage.URL{}
+	if limit <= 0 {
+		return urls, nil
+	}
+	err := s.search(ctx, filter, desc, func(u storage.URL) bool {
+		if offset > 0 {
+			offset--
+			return true
+		}
+		urls = append(urls, u)
+		return len(urls) < limit
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return urls, nil
+}
+
+func (s *Storage) CountURLs(ctx context.Context, filter storage.ListFilter) (int64, error) {
+	if filter.URLContains == "" {
+		return s.Backend.CountURLs(ctx, filter)
+	}
+
+	var n int64
+	err := s.search(ctx, filter, false, func(storage.URL) bool {
+		n++
+		return true
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	return n, nil
+}
+
+// search calls yield with every link matching filter, decrypted, until
+// yield returns false.
+func (s *Storage) search(ctx context.Context, filter storage.ListFilter, desc bool, yield func(storage.URL) bool) error {
+	needle := strings.ToLower(filter.URLContains)
+	filter.URLContains = ""
+
+	for offset := 0; ; offset += pageSize {
+		page, err := s.Backend.ListURLs(ctx, filter, pageSize, offset, desc)
+		if err != nil {
+			return err
+		}
+		page, err = s.openAll(page)
+		if err != nil {
+			return err
+		}
+
+		for _, u := range page {
+			if strings.Contains(strings.ToLower(u.URL), needle) && !yield(u) {
+				return nil
+			}
+		}
+		if len(page) < pageSize {
+			return nil
+		}
+	}
+}
+
+func (s *Storage) openAll(urls []storage.URL) ([]storage.URL, error) {
+	for i, u := range urls {
+		opened, err := s.open(u)
+		if err != nil {
+			return nil, err
+		}
+		urls[i] = opened
+	}
+
+	return urls, nil
+}
+
+func (s *Storage) SaveAuditEntry(ctx context.Context, e storage.AuditEntry) error {
+	e.Before = s.cipher.Encrypt(e.Before)
+	e.After = s.cipher.Encrypt(e.After)
+	return s.Backend.SaveAuditEntry(ctx, e)
+}
+
+func (s *Storage) ListAuditLog(ctx context.Context, filter storage.AuditFilter, limit int, offset int) ([]storage.AuditEntry, error) {
+	const op = "storage.encrypted.ListAuditLog"
+
+	entries, err := s.Backend.ListAuditLog(ctx, filter, limit, offset)
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range entries {
+		if entries[i].Before, err = s.cipher.Decrypt(entries[i].Before); err != nil {
+			return nil, fmt.Errorf("%s: entry %d: %w", op, entries[i].ID, err)
+		}
+		if entries[i].After, err = s.cipher.Decrypt(entries[i].After); err != nil {
+			return nil, fmt.Errorf("%s: entry %d: %w", op, entries[i].ID, err)
+		}
+	}
+
+	return entries, nil
+}
@@ -0,0 +1,151 @@
+package encrypted_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"url-shortener/internal/lib/encrypt"
+	"url-shortener/internal/storage"
+	"url-shortener/internal/storage/encrypted"
+	"url-shortener/internal/storage/memory"
+)
+
+func newStorage(t *testing.T) (*encrypted.Storage, *memory.Storage) {
+	t.Helper()
+
+	cipher, err := encrypt.New([]byte("0123456789abcdef"))
+	require.NoError(t, err)
+	backend := memory.New()
+
+	return encrypted.New(backend, cipher), backend
+}
+
+func TestStorage_URL(t *testing.T) {
+	ctx := context.Background()
+	s, backend := newStorage(t)
+
+	_, err := s.SaveURL(ctx, storage.URL{
+		URL:           "https://example.com/secret",
+		Alias:         "promo",
+		GeoTargets:    storage.Targets{"DE": "https://example.de"},
+		DeviceTargets: storage.Targets{storage.DeviceIOS: "https://apps.apple.com/app"},
+		Variants: storage.Variants{
+			{Name: "a", URL: "https://example.com/a", Weight: 1},
+			{Name: "b", URL: "https://example.com/b", Weight: 1},
+		},
+	})
+	require.NoError(t, err)
+
+	raw, err := backend.GetURL(ctx, "promo")
+	require.NoError(t, err)
+	require.True(t, encrypt.Encrypted(raw.URL))
+	require.True(t, encrypt.Encrypted(raw.GeoTargets["DE"]))
+	require.True(t, encrypt.Encrypted(raw.DeviceTargets[storage.DeviceIOS]))
+	require.True(t, encrypt.Encrypted(raw.Variants[1].URL))
+
+	u, err := s.GetURL(ctx, "promo")
+	require.NoError(t, err)
+	require.Equal(t, "https://example.com/secret", u.URL)
+	require.Equal(t, storage.Targets{"DE": "https://example.de"}, u.GeoTargets)
+	require.Equal(t, storage.Targets{storage.DeviceIOS: "https://apps.apple.com/app"}, u.DeviceTargets)
+	require.Equal(t, "https://example.com/b", u.Variants[1].URL)
+
+	require.NoError(t, s.UpdateURL(ctx, "promo", "", "https://example.org"))
+	raw, err = backend.GetURL(ctx, "promo")
+	require.NoError(t, err)
+	require.True(t, encrypt.Encrypted(raw.URL))
+	u, err = s.GetURL(ctx, "promo")
+	require.NoError(t, err)
+	require.Equal(t, "https://example.org", u.URL)
+}
+
+func TestStorage_GetAliasByURL(t *testing.T) {
+	ctx := context.Background()
+	s, backend := newStorage(t)
+
+	_, err := s.SaveURL(ctx, storage.URL{URL: "https://example.com", Alias: "new"})
+	require.NoError(t, err)
+	_, err = backend.SaveURL(ctx, storage.URL{URL: "https://example.org", Alias: "old"})
+	require.NoError(t, err)
+
+	alias, err := s.GetAliasByURL(ctx, "https://example.com", "")
+	require.NoError(t, err)
+	require.Equal(t, "new", alias)
+
+	alias, err = s.GetAliasByURL(ctx, "https://example.org", "")
+	require.NoError(t, err)
+	require.Equal(t, "old", alias)
+
+	_, err = s.GetAliasByURL(ctx, "https://example.net", "")
+	require.ErrorIs(t, err, storage.ErrUrlNotFound)
+}
+
+func TestStorage_ListURLs(t *testing.T) {
+	ctx := context.Background()
+	s, backend := newStorage(t)
+
+	for _, u := range []storage.URL{
+		{URL: "https://GitHub.com/a", Alias: "a"},
+		{URL: "https://example.com", Alias: "b"},
+		{URL: "https://github.com/c", Alias: "c"},
+		{URL: "https://github.com/d", Alias: "d"},
+	} {
+		_, err := s.SaveURL(ctx, u)
+		require.NoError(t, err)
+	}
+	_, err := backend.SaveURL(ctx, storage.URL{URL: "https://github.com/legacy", Alias: "e"})
+	require.NoError(t, err)
+
+	urls, err := s.ListURLs(ctx, storage.ListFilter{}, 10, 0, false)
+	require.NoError(t, err)
+	require.Len(t, urls, 5)
+	require.Equal(t, "https://GitHub.com/a", urls[0].URL)
+
+	filter := storage.ListFilter{URLContains: "github"}
+	urls, err = s.ListURLs(ctx, filter, 2, 1, false)
+	require.NoError(t, err)
+	require.Equal(t, []string{"c", "d"}, aliases(urls))
+
+	urls, err = s.ListURLs(ctx, filter, 10, 0, true)
+	require.NoError(t, err)
+	require.Equal(t, []string{"e", "d", "c", "a"}, aliases(urls))
+
+	n, err := s.CountURLs(ctx, filter)
+	require.NoError(t, err)
+	require.EqualValues(t, 4, n)
+
+	n, err = s.CountURLs(ctx, storage.ListFilter{})
+	require.NoError(t, err)
+	require.EqualValues(t, 5, n)
+}
+
+func TestStorage_AuditLog(t *testing.T) {
+	ctx := context.Background()
+	s, backend := newStorage(t)
+
+	require.NoError(t, s.SaveAuditEntry(ctx, storage.AuditEntry{
+		Action: storage.AuditLinkCreate,
+		Target: "promo",
+		After:  `{"url":"https://example.com/secret"}`,
+	}))
+
+	raw, err := backend.ListAuditLog(ctx, storage.AuditFilter{}, 10, 0)
+	require.NoError(t, err)
+	require.True(t, encrypt.Encrypted(raw[0].After))
+	require.Empty(t, raw[0].Before)
+
+	entries, err := s.ListAuditLog(ctx, storage.AuditFilter{Target: "promo"}, 10, 0)
+	require.NoError(t, err)
+	require.Len(t, entries, 1)
+	require.Equal(t, `{"url":"https://example.com/secret"}`, entries[0].After)
+}
+
+func aliases(urls []storage.URL) []string {
+	res := make([]string, len(urls))
+	for i, u := range urls {
+		res[i] = u.Alias
+	}
+	return res
+}
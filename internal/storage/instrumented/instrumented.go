@@ -0,0 +1,316 @@
+package instrumented
+
+import (
+	"context"
+	"time"
+
+	"url-shortener/internal/storage"
+)
+
+// Backend is implemented by every storage backend.
+type Backend interface {
+	SaveURL(ctx context.Context, u storage.URL) (int64, error)
+	SaveURLs(ctx context.Context, urls []storage.URL) ([]error, error)
+	NextURLID(ctx context.Context) (int64, error)
+	GetURL(ctx context.Context, alias string) (storage.URL, error)
+	GetAliasByURL(ctx context.Context, url string, owner string) (string, error)
+	FlagURL(ctx context.Context, alias string, threat string) error
+	ConsumeClick(ctx context.Context, alias string) error
+	DeleteURL(ctx context.Context, alias string, owner string) error
+	RestoreURL(ctx context.Context, alias string, owner string) error
+	RenameURL(ctx context.Context, alias string, owner string, newAlias string, forwardUntil time.Time) error
+	UpdateURL(ctx context.Context, alias string, owner string, newURL string) error
+	BlockURL(ctx context.Context, alias string, reason string, reference string) error
+	GetLegalBlock(ctx context.Context, alias string) (storage.LegalBlock, error)
+	GetOwner(ctx context.Context, alias string) (string, error)
+	AliasExists(ctx context.Context, alias string) (bool, error)
+	SetURLHealth(ctx context.Context, alias string, health storage.Health) error
+	ListURLs(ctx context.Context, filter storage.ListFilter, limit int, offset int, desc bool) ([]storage.URL, error)
+	CountURLs(ctx context.Context, filter storage.ListFilter) (int64, error)
+	SaveClick(ctx context.Context, click storage.Click) error
+	GetStats(ctx context.Context, alias string, since time.Time, includeBots bool) (storage.Stats, error)
+	GetReferrers(ctx context.Context, alias string, includeBots bool) ([]storage.ReferrerClicks, error)
+	GetCountries(ctx context.Context, alias string, includeBots bool) ([]storage.CountryClicks, error)
+	ListClicks(ctx context.Context, alias string, filter storage.ClickFilter, afterID int64, limit int) ([]storage.Click, error)
+	DeleteExpired(ctx context.Context, now time.Time) ([]string, error)
+	PurgeDeleted(ctx context.Context, before time.Time) ([]string, error)
+	SaveAPIKey(ctx context.Context, key storage.APIKey) (int64, error)
+	GetAPIKeyByHash(ctx context.Context, hash string) (storage.APIKey, error)
+	ListAPIKeys(ctx context.Context, owner string) ([]storage.APIKey, error)
+	RevokeAPIKey(ctx context.Context, id int64, owner string) error
+	SaveCampaign(ctx context.Context, c storage.Campaign) (int64, error)
+	GetCampaign(ctx context.Context, id int64, owner string) (storage.Campaign, error)
+	ListCampaigns(ctx context.Context, owner string) ([]storage.Campaign, error)
+	UpdateCampaign(ctx context.Context, c storage.Campaign, owner string) error
+	DeleteCampaign(ctx context.Context, id int64, owner string) error
+	SaveUser(ctx context.Context, user storage.User) (int64, error)
+	GetUser(ctx context.Context, username string) (storage.User, error)
+	DeleteUserData(ctx context.Context, username string) (storage.UserDataReport, error)
+	SaveAuditEntry(ctx context.Context, e storage.AuditEntry) error
+	ListAuditLog(ctx context.Context, filter storage.AuditFilter, limit int, offset int) ([]storage.AuditEntry, error)
+	ReserveIdempotencyKey(ctx context.Context, rec storage.IdempotencyRecord) (storage.IdempotencyRecord, error)
+	CompleteIdempotencyKey(ctx context.Context, key string, status int, contentType string, body string) error
+	ReleaseIdempotencyKey(ctx context.Context, key string) error
+	Ping(ctx context.Context) error
+	Close() error
+}
+
+//go:generate go run github.com/vektra/mockery/v2@latest --name=Recorder
+type Recorder interface {
+	ObserveQuery(query string, duration time.Duration, err error)
+	// ObserveSaved counts links that were created.
+	ObserveSaved(n int)
+}
+
+// Storage reports the duration and outcome of every call to a backend.
+type Storage struct {
+	backend  Backend
+	recorder Recorder
+}
+
+func New(backend Backend, recorder Recorder) *Storage {
+	return &Storage{backend: backend, recorder: recorder}
+}
+
+func (s *Storage) observe(query string, start time.Time, err *error) {
+	s.recorder.ObserveQuery(query, time.Since(start), *err)
+}
+
+func (s *Storage) SaveURL(ctx context.Context, u storage.URL) (_ int64, err error) {
+	defer s.observe("SaveURL", time.Now(), &err)
+
+	id, err := s.backend.SaveURL(ctx, u)
+	if err == nil {
+		s.recorder.ObserveSaved(1)
+	}
+
+	return id, err
+}
+
+func (s *Storage) NextURLID(ctx context.Context) (_ int64, err error) {
+	defer s.observe("NextURLID", time.Now(), &err)
+
+	return s.backend.NextURLID(ctx)
+}
+
+func (s *Storage) SaveURLs(ctx context.Context, urls []storage.URL) (_ []error, err error) {
+	defer s.observe("SaveURLs", time.Now(), &err)
+
+	errs, err := s.backend.SaveURLs(ctx, urls)
+	if err == nil {
+		saved := 0
+		for _, e := range errs {
+			if e == nil {
+				saved++
+			}
+		}
+		s.recorder.ObserveSaved(saved)
+	}
+
+	return errs, err
+}
+
+func (s *Storage) GetURL(ctx context.Context, alias string) (_ storage.URL, err error) {
+	defer s.observe("GetURL", time.Now(), &err)
+	return s.backend.GetURL(ctx, alias)
+}
+
+func (s *Storage) GetAliasByURL(ctx context.Context, url string, owner string) (_ string, err error) {
+	defer s.observe("GetAliasByURL", time.Now(), &err)
+	return s.backend.GetAliasByURL(ctx, url, owner)
+}
+
+func (s *Storage) FlagURL(ctx context.Context, alias string, threat string) (err error) {
+	defer s.observe("FlagURL", time.Now(), &err)
+	return s.backend.FlagURL(ctx, alias, threat)
+}
+
+func (s *Storage) ConsumeClick(ctx context.Context, alias string) (err error) {
+	defer s.observe("ConsumeClick", time.Now(), &err)
+	return s.backend.ConsumeClick(ctx, alias)
+}
+
+func (s *Storage) DeleteURL(ctx context.Context, alias string, owner string) (err error) {
+	defer s.observe("DeleteURL", time.Now(), &err)
+	return s.backend.DeleteURL(ctx, alias, owner)
+}
+
+func (s *Storage) RestoreURL(ctx context.Context, alias string, owner string) (err error) {
+	defer s.observe("RestoreURL", time.Now(), &err)
+	return s.backend.RestoreURL(ctx, alias, owner)
+}
+
+func (s *Storage) RenameURL(ctx context.Context, alias string, owner string, newAlias string, forwardUntil time.Time) (err error) {
+	defer s.observe("RenameURL", time.Now(), &err)
+	return s.backend.RenameURL(ctx, alias, owner, newAlias, forwardUntil)
+}
+
+func (s *Storage) UpdateURL(ctx context.Context, alias string, owner string, newURL string) (err error) {
+	defer s.observe("UpdateURL", time.Now(), &err)
+	return s.backend.UpdateURL(ctx, alias, owner, newURL)
+}
+
+func (s *Storage) BlockURL(ctx context.Context, alias string, reason string, reference string) (err error) {
+	defer s.observe("BlockURL", time.Now(), &err)
+	return s.backend.BlockURL(ctx, alias, reason, reference)
+}
+
+func (s *Storage) GetLegalBlock(ctx context.Context, alias string) (_ storage.LegalBlock, err error) {
+	defer s.observe("GetLegalBlock", time.Now(), &err)
+	return s.backend.GetLegalBlock(ctx, alias)
+}
+
+func (s *Storage) GetOwner(ctx context.Context, alias string) (_ string, err error) {
+	defer s.observe("GetOwner", time.Now(), &err)
+	return s.backend.GetOwner(ctx, alias)
+}
+
+func (s *Storage) AliasExists(ctx context.Context, alias string) (_ bool, err error) {
+	defer s.observe("AliasExists", time.Now(), &err)
+	return s.backend.AliasExists(ctx, alias)
+}
+
+func (s *Storage) SetURLHealth(ctx context.Context, alias string, health storage.Health) (err error) {
+	defer s.observe("SetURLHealth", time.Now(), &err)
+	return s.backend.SetURLHealth(ctx, alias, health)
+}
+
+func (s *Storage) ListURLs(ctx context.Context, filter storage.ListFilter, limit int, offset int, desc bool) (_ []storage.URL, err error) {
+	defer s.observe("ListURLs", time.Now(), &err)
+	return s.backend.ListURLs(ctx, filter, limit, offset, desc)
+}
+
+func (s *Storage) CountURLs(ctx context.Context, filter storage.ListFilter) (_ int64, err error) {
+	defer s.observe("CountURLs", time.Now(), &err)
+	return s.backend.CountURLs(ctx, filter)
+}
+
+func (s *Storage) SaveClick(ctx context.Context, click storage.Click) (err error) {
+	defer s.observe("SaveClick", time.Now(), &err)
+	return s.backend.SaveClick(ctx, click)
+}
+
+func (s *Storage) GetStats(ctx context.Context, alias string, since time.Time, includeBots bool) (_ storage.Stats, err error) {
+	defer s.observe("GetStats", time.Now(), &err)
+	return s.backend.GetStats(ctx, alias, since, includeBots)
+}
+
+func (s *Storage) GetReferrers(ctx context.Context, alias string, includeBots bool) (_ []storage.ReferrerClicks, err error) {
+	defer s.observe("GetReferrers", time.Now(), &err)
+	return s.backend.GetReferrers(ctx, alias, includeBots)
+}
+
+func (s *Storage) GetCountries(ctx context.Context, alias string, includeBots bool) (_ []storage.CountryClicks, err error) {
+	defer s.observe("GetCountries", time.Now(), &err)
+	return s.backend.GetCountries(ctx, alias, includeBots)
+}
+
+func (s *Storage) ListClicks(ctx context.Context, alias string, filter storage.ClickFilter, afterID int64, limit int) (_ []storage.Click, err error) {
+	defer s.observe("ListClicks", time.Now(), &err)
+	return s.backend.ListClicks(ctx, alias, filter, afterID, limit)
+}
+
+func (s *Storage) DeleteExpired(ctx context.Context, now time.Time) (_ []string, err error) {
+	defer s.observe("DeleteExpired", time.Now(), &err)
+	return s.backend.DeleteExpired(ctx, now)
+}
+
+func (s *Storage) PurgeDeleted(ctx context.Context, before time.Time) (_ []string, err error) {
+	defer s.observe("PurgeDeleted", time.Now(), &err)
+	return s.backend.PurgeDeleted(ctx, before)
+}
+
+func (s *Storage) SaveAPIKey(ctx context.Context, key storage.APIKey) (_ int64, err error) {
+	defer s.observe("SaveAPIKey", time.Now(), &err)
+	return s.backend.SaveAPIKey(ctx, key)
+}
+
+func (s *Storage) GetAPIKeyByHash(ctx context.Context, hash string) (_ storage.APIKey, err error) {
+	defer s.observe("GetAPIKeyByHash", time.Now(), &err)
+	return s.backend.GetAPIKeyByHash(ctx, hash)
+}
+
+func (s *Storage) ListAPIKeys(ctx context.Context, owner string) (_ []storage.APIKey, err error) {
+	defer s.observe("ListAPIKeys", time.Now(), &err)
+	return s.backend.ListAPIKeys(ctx, owner)
+}
+
+func (s *Storage) RevokeAPIKey(ctx context.Context, id int64, owner string) (err error) {
+	defer s.observe("RevokeAPIKey", time.Now(), &err)
+	return s.backend.RevokeAPIKey(ctx, id, owner)
+}
+
+func (s *Storage) SaveCampaign(ctx context.Context, c storage.Campaign) (_ int64, err error) {
+	defer s.observe("SaveCampaign", time.Now(), &err)
+	return s.backend.SaveCampaign(ctx, c)
+}
+
+func (s *Storage) GetCampaign(ctx context.Context, id int64, owner string) (_ storage.Campaign, err error) {
+	defer s.observe("GetCampaign", time.Now(), &err)
+	return s.backend.GetCampaign(ctx, id, owner)
+}
+
+func (s *Storage) ListCampaigns(ctx context.Context, owner string) (_ []storage.Campaign, err error) {
+	defer s.observe("ListCampaigns", time.Now(), &err)
+	return s.backend.ListCampaigns(ctx, owner)
+}
+
+func (s *Storage) UpdateCampaign(ctx context.Context, c storage.Campaign, owner string) (err error) {
+	defer s.observe("UpdateCampaign", time.Now(), &err)
+	return s.backend.UpdateCampaign(ctx, c, owner)
+}
+
+func (s *Storage) DeleteCampaign(ctx context.Context, id int64, owner string) (err error) {
+	defer s.observe("DeleteCampaign", time.Now(), &err)
+	return s.backend.DeleteCampaign(ctx, id, owner)
+}
+
+func (s *Storage) SaveUser(ctx context.Context, user storage.User) (_ int64, err error) {
+	defer s.observe("SaveUser", time.Now(), &err)
+	return s.backend.SaveUser(ctx, user)
+}
+
+func (s *Storage) GetUser(ctx context.Context, username string) (_ storage.User, err error) {
+	defer s.observe("GetUser", time.Now(), &err)
+	return s.backend.GetUser(ctx, username)
+}
+
+func (s *Storage) DeleteUserData(ctx context.Context, username string) (_ storage.UserDataReport, err error) {
+	defer s.observe("DeleteUserData", time.Now(), &err)
+	return s.backend.DeleteUserData(ctx, username)
+}
+
+func (s *Storage) SaveAuditEntry(ctx context.Context, e storage.AuditEntry) (err error) {
+	defer s.observe("SaveAuditEntry", time.Now(), &err)
+	return s.backend.SaveAuditEntry(ctx, e)
+}
+
+func (s *Storage) ListAuditLog(ctx context.Context, filter storage.AuditFilter, limit int, offset int) (_ []storage.AuditEntry, err error) {
+	defer s.observe("ListAuditLog", time.Now(), &err)
+	return s.backend.ListAuditLog(ctx, filter, limit, offset)
+}
+
+func (s *Storage) ReserveIdempotencyKey(ctx context.Context, rec storage.IdempotencyRecord) (_ storage.IdempotencyRecord, err error) {
+	defer s.observe("ReserveIdempotencyKey", time.Now(), &err)
+	return s.backend.ReserveIdempotencyKey(ctx, rec)
+}
+
+func (s *Storage) CompleteIdempotencyKey(ctx context.Context, key string, status int, contentType string, body string) (err error) {
+	defer s.observe("CompleteIdempotencyKey", time.Now(), &err)
+	return s.backend.CompleteIdempotencyKey(ctx, key, status, contentType, body)
+}
+
+func (s *Storage) ReleaseIdempotencyKey(ctx context.Context, key string) (err error) {
+	defer s.observe("ReleaseIdempotencyKey", time.Now(), &err)
+	return s.backend.ReleaseIdempotencyKey(ctx, key)
+}
+
+func (s *Storage) Ping(ctx context.Context) (err error) {
+	defer s.observe("Ping", time.Now(), &err)
+	return s.backend.Ping(ctx)
+}
+
+// Close is not a query, so it is passed through without being observed.
+func (s *Storage) Close() error {
+	return s.backend.Close()
+}
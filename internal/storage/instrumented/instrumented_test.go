@@ -0,0 +1,39 @@
+package instrumented_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"url-shortener/internal/storage"
+	"url-shortener/internal/storage/instrumented"
+	"url-shortener/internal/storage/instrumented/mocks"
+	"url-shortener/internal/storage/memory"
+)
+
+func TestStorage_ObservesCalls(t *testing.T) {
+	recorderMock := mocks.NewRecorder(t)
+	s := instrumented.New(memory.New(), recorderMock)
+	ctx := context.Background()
+
+	recorderMock.On("ObserveQuery", "SaveURL", mock.AnythingOfType("time.Duration"), nil).Return().Once()
+	recorderMock.On("ObserveSaved", 1).Return().Once()
+	_, err := s.SaveURL(ctx, storage.URL{URL: "https://example.com", Alias: "example"})
+	require.NoError(t, err)
+
+	recorderMock.On("ObserveQuery", "SaveURLs", mock.AnythingOfType("time.Duration"), nil).Return().Once()
+	recorderMock.On("ObserveSaved", 1).Return().Once()
+	errs, err := s.SaveURLs(ctx, []storage.URL{
+		{URL: "https://example.com", Alias: "example"},
+		{URL: "https://example.org", Alias: "other"},
+	})
+	require.NoError(t, err)
+	require.ErrorIs(t, errs[0], storage.ErrUrlExists)
+	require.NoError(t, errs[1])
+
+	recorderMock.On("ObserveQuery", "GetURL", mock.AnythingOfType("time.Duration"), storage.ErrUrlNotFound).Return().Once()
+	_, err = s.GetURL(ctx, "missing")
+	require.ErrorIs(t, err, storage.ErrUrlNotFound)
+}
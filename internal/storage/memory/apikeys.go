@@ -0,0 +1,83 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"url-shortener/internal/storage"
+)
+
+func (s *Storage) SaveAPIKey(_ context.Context, key storage.APIKey) (int64, error) {
+	const op = "storage.memory.SaveAPIKey"
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, k := range s.keys {
+		if k.Hash == key.Hash {
+			return 0, fmt.Errorf("%s: duplicate key hash", op)
+		}
+	}
+
+	s.lastKeyID++
+	key.ID = s.lastKeyID
+	key.CreatedAt = time.Now().UTC()
+	key.RevokedAt = time.Time{}
+	s.keys = append(s.keys, key)
+
+	return key.ID, nil
+}
+
+// GetAPIKeyByHash looks a key up by the hash of its value. Revoked keys are
+// returned as well; callers check APIKey.Revoked.
+func (s *Storage) GetAPIKeyByHash(_ context.Context, hash string) (storage.APIKey, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	for _, k := range s.keys {
+		if k.Hash == hash {
+			return k, nil
+		}
+	}
+
+	return storage.APIKey{}, storage.ErrKeyNotFound
+}
+
+// ListAPIKeys returns keys ordered by id. A non-empty owner limits the
+// result to that user's keys.
+func (s *Storage) ListAPIKeys(_ context.Context, owner string) ([]storage.APIKey, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	keys := []storage.APIKey{}
+	for _, k := range s.keys {
+		if owner == "" || k.Owner == owner {
+			keys = append(keys, k)
+		}
+	}
+
+	return keys, nil
+}
+
+// RevokeAPIKey marks a key as revoked. Revoking a revoked key keeps its
+// original revocation time. A non-empty owner restricts revocation to that
+// user's keys.
+func (s *Storage) RevokeAPIKey(_ context.Context, id int64, owner string) error {
+	const op = "storage.memory.RevokeAPIKey"
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for i := range s.keys {
+		if s.keys[i].ID != id || (owner != "" && s.keys[i].Owner != owner) {
+			continue
+		}
+		if !s.keys[i].Revoked() {
+			s.keys[i].RevokedAt = time.Now().UTC()
+		}
+		return nil
+	}
+
+	return fmt.Errorf("%s: %w", op, storage.ErrKeyNotFound)
+}
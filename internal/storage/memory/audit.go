@@ -0,0 +1,41 @@
+package memory
+
+import (
+	"context"
+	"slices"
+
+	"url-shortener/internal/storage"
+)
+
+func (s *Storage) SaveAuditEntry(_ context.Context, e storage.AuditEntry) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	e.ID = int64(len(s.audit)) + 1
+	s.audit = append(s.audit, e)
+
+	return nil
+}
+
+// ListAuditLog returns entries newest first.
+func (s *Storage) ListAuditLog(_ context.Context, filter storage.AuditFilter, limit int, offset int) ([]storage.AuditEntry, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	entries := []storage.AuditEntry{}
+	for _, e := range slices.Backward(s.audit) {
+		if !filter.Match(e) {
+			continue
+		}
+		if offset > 0 {
+			offset--
+			continue
+		}
+		if len(entries) == limit {
+			break
+		}
+		entries = append(entries, e)
+	}
+
+	return entries, nil
+}
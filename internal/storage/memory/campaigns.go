@@ -0,0 +1,110 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"url-shortener/internal/storage"
+)
+
+func (s *Storage) SaveCampaign(_ context.Context, c storage.Campaign) (int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.lastCampaignID++
+	c.ID = s.lastCampaignID
+	c.CreatedAt = time.Now().UTC()
+	s.campaigns = append(s.campaigns, c)
+
+	return c.ID, nil
+}
+
+// GetCampaign returns storage.ErrCampaignNotFound for unknown campaigns and,
+// with a non-empty owner, for those of other users.
+func (s *Storage) GetCampaign(_ context.Context, id int64, owner string) (storage.Campaign, error) {
+	const op = "storage.memory.GetCampaign"
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	i := s.campaign(id, owner)
+	if i < 0 {
+		return storage.Campaign{}, fmt.Errorf("%s: %w", op, storage.ErrCampaignNotFound)
+	}
+
+	return s.campaigns[i], nil
+}
+
+// ListCampaigns returns campaigns ordered by id. A non-empty owner limits
+// the result to that user's campaigns.
+func (s *Storage) ListCampaigns(_ context.Context, owner string) ([]storage.Campaign, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	campaigns := []storage.Campaign{}
+	for _, c := range s.campaigns {
+		if owner == "" || c.Owner == owner {
+			campaigns = append(campaigns, c)
+		}
+	}
+
+	return campaigns, nil
+}
+
+// UpdateCampaign replaces the name and the settings of campaign c.ID; its
+// owner and creation time are kept. A non-empty owner restricts the update
+// to that user's campaigns.
+func (s *Storage) UpdateCampaign(_ context.Context, c storage.Campaign, owner string) error {
+	const op = "storage.memory.UpdateCampaign"
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	i := s.campaign(c.ID, owner)
+	if i < 0 {
+		return fmt.Errorf("%s: %w", op, storage.ErrCampaignNotFound)
+	}
+
+	c.Owner = s.campaigns[i].Owner
+	c.CreatedAt = s.campaigns[i].CreatedAt
+	s.campaigns[i] = c
+
+	return nil
+}
+
+// DeleteCampaign removes a campaign. Its links are kept and no longer
+// belong to any campaign. A non-empty owner restricts deletion to that
+// user's campaigns.
+func (s *Storage) DeleteCampaign(_ context.Context, id int64, owner string) error {
+	const op = "storage.memory.DeleteCampaign"
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	i := s.campaign(id, owner)
+	if i < 0 {
+		return fmt.Errorf("%s: %w", op, storage.ErrCampaignNotFound)
+	}
+	s.campaigns = append(s.campaigns[:i], s.campaigns[i+1:]...)
+
+	for _, l := range s.links {
+		if l.campaignID == id {
+			l.campaignID = 0
+		}
+	}
+
+	return nil
+}
+
+// campaign returns the index of campaign id, -1 if there is no such
+// campaign of owner.
+func (s *Storage) campaign(id int64, owner string) int {
+	for i, c := range s.campaigns {
+		if c.ID == id && (owner == "" || c.Owner == owner) {
+			return i
+		}
+	}
+
+	return -1
+}
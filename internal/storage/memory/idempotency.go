@@ -0,0 +1,71 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"time"
+
+	"url-shortener/internal/storage"
+)
+
+// ReserveIdempotencyKey saves rec unless a record with its key exists, in
+// which case it returns that record and storage.ErrIdempotencyKeyExists.
+// Records that expired by rec.CreatedAt are removed first.
+func (s *Storage) ReserveIdempotencyKey(_ context.Context, rec storage.IdempotencyRecord) (storage.IdempotencyRecord, error) {
+	const op = "storage.memory.ReserveIdempotencyKey"
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	maps.DeleteFunc(s.idempotency, func(_ string, r storage.IdempotencyRecord) bool {
+		return !r.ExpiresAt.After(rec.CreatedAt)
+	})
+
+	if existing, ok := s.idempotency[rec.Key]; ok {
+		return existing, fmt.Errorf("%s: %w", op, storage.ErrIdempotencyKeyExists)
+	}
+	s.idempotency[rec.Key] = rec
+
+	return rec, nil
+}
+
+// CompleteIdempotencyKey stores the response to the request that reserved
+// key.
+func (s *Storage) CompleteIdempotencyKey(_ context.Context, key string, status int, contentType string, body string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	rec, ok := s.idempotency[key]
+	if !ok {
+		return nil
+	}
+	rec.Status, rec.ContentType, rec.Body = status, contentType, body
+	s.idempotency[key] = rec
+
+	return nil
+}
+
+// ReleaseIdempotencyKey removes key, so that the request can be retried.
+func (s *Storage) ReleaseIdempotencyKey(_ context.Context, key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.idempotency, key)
+
+	return nil
+}
+
+// PruneIdempotencyKeys removes records that expired by now and returns how
+// many there were.
+func (s *Storage) PruneIdempotencyKeys(_ context.Context, now time.Time) (int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	before := len(s.idempotency)
+	maps.DeleteFunc(s.idempotency, func(_ string, r storage.IdempotencyRecord) bool {
+		return !r.ExpiresAt.After(now)
+	})
+
+	return int64(before - len(s.idempotency)), nil
+}
@@ -0,0 +1,717 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"slices"
+	"sort"
+	"sync"
+	"time"
+
+	"url-shortener/internal/storage"
+)
+
+type link struct {
+	id             int64
+	url            string
+	domain         string
+	state          string
+	blockReason    string
+	blockReference string
+	createdAt      time.Time
+	expiresAt      time.Time
+	activeFrom     time.Time
+	activeUntil    time.Time
+	maxClicks      int64
+	clickCount     int64
+	importedClicks int64
+	passwordHash   string
+	apiKeyID       int64
+	owner          string
+	redirectType   int
+	cacheMaxAge    int
+	metadata       storage.Metadata
+	openGraph      storage.Metadata
+	utm            storage.UTM
+	geoTargets     storage.Targets
+	deviceTargets  storage.Targets
+	variants       storage.Variants
+	campaignID     int64
+	tags           []string
+	health         storage.Health
+	clicks         []storage.Click
+	deletedAt      time.Time
+}
+
+// Storage keeps links in a map guarded by a mutex. Nothing survives a
+// restart, so it is meant for local development and tests.
+type Storage struct {
+	mu         sync.RWMutex
+	lastID     int64
+	links      map[string]*link
+	lastKeyID  int64
+	keys       []storage.APIKey
+	lastUserID int64
+	users      map[string]storage.User
+	audit      []storage.AuditEntry
+	// idempotency is keyed by IdempotencyRecord.Key.
+	idempotency map[string]storage.IdempotencyRecord
+	// campaigns are ordered by id.
+	lastCampaignID int64
+	campaigns      []storage.Campaign
+}
+
+func New() *Storage {
+	return &Storage{
+		links:       make(map[string]*link),
+		users:       make(map[string]storage.User),
+		idempotency: make(map[string]storage.IdempotencyRecord),
+	}
+}
+
+// Ping always succeeds: there is nothing to connect to.
+func (s *Storage) Ping(_ context.Context) error {
+	return nil
+}
+
+// Close is a no-op; the links are simply dropped with the process.
+func (s *Storage) Close() error {
+	return nil
+}
+
+func (s *Storage) SaveURL(_ context.Context, u storage.URL) (int64, error) {
+	const op = "storage.memory.SaveURL"
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	id, err := s.save(u)
+	if err != nil {
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return id, nil
+}
+
+// NextURLID takes the next value of the counter link ids come from.
+func (s *Storage) NextURLID(_ context.Context) (int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.lastID++
+
+	return s.lastID, nil
+}
+
+// SaveURLs saves urls atomically with respect to other calls. A taken alias
+// does not abort the batch: storage.ErrUrlExists is reported at its index in
+// the returned slice.
+func (s *Storage) SaveURLs(_ context.Context, urls []storage.URL) ([]error, error) {
+	const op = "storage.memory.SaveURLs"
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	errs := make([]error, len(urls))
+	for i, u := range urls {
+		if _, err := s.save(u); err != nil {
+			errs[i] = fmt.Errorf("%s: %w", op, err)
+		}
+	}
+
+	return errs, nil
+}
+
+// save must be called with s.mu held.
+func (s *Storage) save(u storage.URL) (int64, error) {
+	if _, ok := s.links[u.Alias]; ok {
+		return 0, storage.ErrUrlExists
+	}
+
+	s.lastID++
+	s.links[u.Alias] = &link{
+		id:             s.lastID,
+		url:            u.URL,
+		domain:         u.Domain,
+		state:          storage.StateActive,
+		createdAt:      time.Now().UTC(),
+		expiresAt:      u.ExpiresAt,
+		activeFrom:     u.ActiveFrom,
+		activeUntil:    u.ActiveUntil,
+		maxClicks:      u.MaxClicks,
+		importedClicks: u.ImportedClicks,
+		passwordHash:   u.PasswordHash,
+		apiKeyID:       u.APIKeyID,
+		owner:          u.Owner,
+		redirectType:   u.RedirectType,
+		cacheMaxAge:    u.CacheMaxAge,
+		metadata:       u.Metadata,
+		openGraph:      u.OpenGraph,
+		utm:            u.UTM,
+		geoTargets:     maps.Clone(u.GeoTargets),
+		deviceTargets:  maps.Clone(u.DeviceTargets),
+		tags:           sortedTags(u.Tags),
+		variants:       slices.Clone(u.Variants),
+		campaignID:     u.CampaignID,
+	}
+
+	return s.lastID, nil
+}
+
+func (s *Storage) GetURL(_ context.Context, alias string) (storage.URL, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	l, ok := s.links[alias]
+	if !ok || l.deleted() {
+		return storage.URL{}, storage.ErrUrlNotFound
+	}
+
+	if l.state == storage.StateBlockedLegal {
+		return storage.URL{}, storage.ErrUrlBlocked
+	}
+
+	if l.state == storage.StateBlockedUnsafe {
+		return storage.URL{}, storage.ErrUrlUnsafe
+	}
+
+	u := l.toURL(alias)
+	if u.Expired(time.Now()) {
+		return storage.URL{}, storage.ErrUrlExpired
+	}
+
+	if l.exhausted() {
+		return storage.URL{}, storage.ErrUrlExhausted
+	}
+
+	return u, nil
+}
+
+// GetAliasByURL returns the alias of the oldest plain link of owner
+// pointing to url: active, unexpired, without a password, a click limit,
+// a custom redirect type or cache lifetime, UTM parameters, geo or device
+// targets, A/B variants, an activation window, a short domain or an Open
+// Graph preview of its own, and not in a campaign.
+// Unlike elsewhere, owner "" matches only links without an owner. It
+// returns storage.ErrUrlNotFound if there is no such link.
+func (s *Storage) GetAliasByURL(_ context.Context, url string, owner string) (string, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	now := time.Now()
+	var (
+		alias string
+		id    int64
+	)
+	for a, l := range s.links {
+		if l.owner != owner || l.url != url || l.state != storage.StateActive || l.deleted() ||
+			l.maxClicks > 0 || l.passwordHash != "" || l.redirectType != 0 || l.cacheMaxAge != 0 || l.utm != (storage.UTM{}) ||
+			len(l.geoTargets) > 0 || len(l.deviceTargets) > 0 || len(l.variants) > 0 ||
+			!l.activeFrom.IsZero() || !l.activeUntil.IsZero() || l.domain != "" ||
+			l.openGraph != (storage.Metadata{}) || l.campaignID != 0 || l.toURL(a).Expired(now) {
+			continue
+		}
+		if alias == "" || l.id < id {
+			alias, id = a, l.id
+		}
+	}
+
+	if alias == "" {
+		return "", storage.ErrUrlNotFound
+	}
+
+	return alias, nil
+}
+
+// ConsumeClick atomically counts a redirect against the link's max_clicks
+// and returns storage.ErrUrlExhausted once the limit has been reached.
+func (s *Storage) ConsumeClick(_ context.Context, alias string) error {
+	const op = "storage.memory.ConsumeClick"
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	l, ok := s.links[alias]
+	if !ok || l.deleted() {
+		return fmt.Errorf("%s: %w", op, storage.ErrUrlNotFound)
+	}
+
+	if l.exhausted() {
+		return fmt.Errorf("%s: %w", op, storage.ErrUrlExhausted)
+	}
+
+	l.clickCount++
+
+	return nil
+}
+
+// DeleteURL marks alias as deleted: it stops resolving but keeps the alias
+// taken until PurgeDeleted removes it, and can be brought back with
+// RestoreURL. A non-empty owner restricts the deletion to that user's
+// links; other links are reported as not found.
+func (s *Storage) DeleteURL(_ context.Context, alias string, owner string) error {
+	const op = "storage.memory.DeleteURL"
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	l, ok := s.links[alias]
+	if !ok || l.deleted() || !l.ownedBy(owner) {
+		return fmt.Errorf("%s: %w", op, storage.ErrUrlNotFound)
+	}
+
+	l.deletedAt = time.Now().UTC()
+
+	return nil
+}
+
+// RestoreURL brings back a deleted link. A non-empty owner restricts the
+// restore to that user's links; other links, and links that are not
+// deleted, are reported as not found.
+func (s *Storage) RestoreURL(_ context.Context, alias string, owner string) error {
+	const op = "storage.memory.RestoreURL"
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	l, ok := s.links[alias]
+	if !ok || !l.deleted() || !l.ownedBy(owner) {
+		return fmt.Errorf("%s: %w", op, storage.ErrUrlNotFound)
+	}
+
+	l.deletedAt = time.Time{}
+
+	return nil
+}
+
+// UpdateURL points alias at newURL. A non-empty owner restricts the update
+// to that user's links; other links are reported as not found.
+func (s *Storage) UpdateURL(_ context.Context, alias string, owner string, newURL string) error {
+	const op = "storage.memory.UpdateURL"
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	l, ok := s.links[alias]
+	if !ok || l.deleted() || !l.ownedBy(owner) {
+		return fmt.Errorf("%s: %w", op, storage.ErrUrlNotFound)
+	}
+
+	l.url = newURL
+	l.metadata = storage.Metadata{}
+
+	return nil
+}
+
+// RenameURL moves a live link and its clicks from alias to newAlias and
+// returns storage.ErrUrlExists if newAlias is taken. A non-zero
+// forwardUntil keeps alias until then as a link to storage.ForwardURL of
+// newAlias. A non-empty owner restricts the rename to that user's links;
+// other links are reported as not found.
+func (s *Storage) RenameURL(_ context.Context, alias string, owner string, newAlias string, forwardUntil time.Time) error {
+	const op = "storage.memory.RenameURL"
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	l, ok := s.links[alias]
+	if !ok || l.deleted() || !l.ownedBy(owner) {
+		return fmt.Errorf("%s: %w", op, storage.ErrUrlNotFound)
+	}
+
+	if _, ok := s.links[newAlias]; ok {
+		return fmt.Errorf("%s: %w", op, storage.ErrUrlExists)
+	}
+
+	delete(s.links, alias)
+	s.links[newAlias] = l
+	for i := range l.clicks {
+		l.clicks[i].Alias = newAlias
+	}
+
+	if !forwardUntil.IsZero() {
+		_, _ = s.save(storage.URL{
+			Alias:     alias,
+			URL:       storage.ForwardURL(newAlias),
+			ExpiresAt: forwardUntil,
+			Owner:     l.owner,
+			Domain:    l.domain,
+		})
+	}
+
+	return nil
+}
+
+func (s *Storage) BlockURL(_ context.Context, alias string, reason string, reference string) error {
+	const op = "storage.memory.BlockURL"
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	l, ok := s.links[alias]
+	if !ok {
+		return fmt.Errorf("%s: %w", op, storage.ErrUrlNotFound)
+	}
+
+	l.state = storage.StateBlockedLegal
+	l.blockReason = reason
+	l.blockReference = reference
+
+	return nil
+}
+
+// FlagURL marks an active link as unsafe after a reputation check flagged
+// its target; threat is kept as the block reason. Links that are already
+// blocked are left as they are and reported as storage.ErrUrlNotFound.
+func (s *Storage) FlagURL(_ context.Context, alias string, threat string) error {
+	const op = "storage.memory.FlagURL"
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	l, ok := s.links[alias]
+	if !ok || l.state != storage.StateActive || l.deleted() {
+		return fmt.Errorf("%s: %w", op, storage.ErrUrlNotFound)
+	}
+
+	l.state = storage.StateBlockedUnsafe
+	l.blockReason = threat
+
+	return nil
+}
+
+// SetURLHealth records the outcome of checking the destination of alias.
+func (s *Storage) SetURLHealth(_ context.Context, alias string, health storage.Health) error {
+	const op = "storage.memory.SetURLHealth"
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	l, ok := s.links[alias]
+	if !ok || l.deleted() {
+		return fmt.Errorf("%s: %w", op, storage.ErrUrlNotFound)
+	}
+
+	l.health = health
+
+	return nil
+}
+
+func (s *Storage) GetLegalBlock(_ context.Context, alias string) (storage.LegalBlock, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	l, ok := s.links[alias]
+	if !ok || l.state != storage.StateBlockedLegal {
+		return storage.LegalBlock{}, storage.ErrUrlNotFound
+	}
+
+	return storage.LegalBlock{Reason: l.blockReason, Reference: l.blockReference}, nil
+}
+
+// GetOwner returns the owner of alias whatever its state: "" for links
+// created without an owner, storage.ErrUrlNotFound if there is no such
+// link or it is deleted.
+func (s *Storage) GetOwner(_ context.Context, alias string) (string, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	l, ok := s.links[alias]
+	if !ok || l.deleted() {
+		return "", storage.ErrUrlNotFound
+	}
+
+	return l.owner, nil
+}
+
+// AliasExists reports whether a link holds alias, deleted links that were
+// not purged yet included.
+func (s *Storage) AliasExists(_ context.Context, alias string) (bool, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	_, ok := s.links[alias]
+	return ok, nil
+}
+
+// CountURLs counts the saved links that pass filter.
+func (s *Storage) CountURLs(_ context.Context, filter storage.ListFilter) (int64, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	var n int64
+	for _, l := range s.links {
+		if l.matches(filter) {
+			n++
+		}
+	}
+
+	return n, nil
+}
+
+// ListURLs returns a page of the saved links that pass filter, ordered by
+// creation date.
+func (s *Storage) ListURLs(_ context.Context, filter storage.ListFilter, limit int, offset int, desc bool) ([]storage.URL, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	type entry struct {
+		alias string
+		*link
+	}
+
+	entries := make([]entry, 0, len(s.links))
+	for alias, l := range s.links {
+		if l.matches(filter) {
+			entries = append(entries, entry{alias: alias, link: l})
+		}
+	}
+
+	// ids grow with creation time, so they break ties between links
+	// created within the same clock tick.
+	sort.Slice(entries, func(i, j int) bool {
+		if desc {
+			return entries[i].id > entries[j].id
+		}
+		return entries[i].id < entries[j].id
+	})
+
+	urls := []storage.URL{}
+	for i := offset; i < len(entries) && len(urls) < limit; i++ {
+		urls = append(urls, entries[i].toURL(entries[i].alias))
+	}
+
+	return urls, nil
+}
+
+func (s *Storage) SaveClick(_ context.Context, click storage.Click) error {
+	const op = "storage.memory.SaveClick"
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	l, ok := s.links[click.Alias]
+	if !ok {
+		return fmt.Errorf("%s: %w", op, storage.ErrUrlNotFound)
+	}
+
+	l.clicks = append(l.clicks, click)
+
+	return nil
+}
+
+// GetStats returns click totals for alias and a per-day breakdown of the
+// clicks made since the given time. Clicks of bots are left out unless
+// includeBots is set.
+func (s *Storage) GetStats(_ context.Context, alias string, since time.Time, includeBots bool) (storage.Stats, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	l, ok := s.links[alias]
+	if !ok || l.deleted() {
+		return storage.Stats{}, storage.ErrUrlNotFound
+	}
+
+	stats := storage.Stats{
+		Domain: l.domain,
+		Total:  l.importedClicks,
+		Daily:  []storage.DailyClicks{},
+	}
+
+	daily := make(map[string]int64)
+	variants := make(map[string]int64)
+	for _, c := range l.clicks {
+		if c.Bot && !includeBots {
+			continue
+		}
+		stats.Total++
+		if c.ClickedAt.After(stats.LastAccess) {
+			stats.LastAccess = c.ClickedAt
+		}
+		if !c.ClickedAt.Before(since) {
+			daily[c.ClickedAt.UTC().Format(time.DateOnly)]++
+		}
+		if c.Variant != "" {
+			variants[c.Variant]++
+		}
+	}
+
+	for day, clicks := range daily {
+		stats.Daily = append(stats.Daily, storage.DailyClicks{Date: day, Clicks: clicks})
+	}
+	sort.Slice(stats.Daily, func(i, j int) bool { return stats.Daily[i].Date < stats.Daily[j].Date })
+
+	for _, variant := range slices.Sorted(maps.Keys(variants)) {
+		stats.Variants = append(stats.Variants, storage.VariantClicks{Variant: variant, Clicks: variants[variant]})
+	}
+
+	return stats, nil
+}
+
+// GetReferrers counts the clicks on alias per referring domain, the most
+// clicked first. Clicks of bots are left out unless includeBots is set.
+func (s *Storage) GetReferrers(_ context.Context, alias string, includeBots bool) ([]storage.ReferrerClicks, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	l, ok := s.links[alias]
+	if !ok || l.deleted() {
+		return nil, storage.ErrUrlNotFound
+	}
+
+	byDomain := make(map[string]int64)
+	for _, c := range l.clicks {
+		if !c.Bot || includeBots {
+			byDomain[storage.ReferrerDomain(c.Referrer)]++
+		}
+	}
+
+	return storage.SortReferrers(byDomain), nil
+}
+
+// GetCountries counts the clicks on alias per country, the most clicked
+// first. Clicks of bots are left out unless includeBots is set.
+func (s *Storage) GetCountries(_ context.Context, alias string, includeBots bool) ([]storage.CountryClicks, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	l, ok := s.links[alias]
+	if !ok || l.deleted() {
+		return nil, storage.ErrUrlNotFound
+	}
+
+	byCountry := make(map[string]int64)
+	for _, c := range l.clicks {
+		if !c.Bot || includeBots {
+			byCountry[c.Country]++
+		}
+	}
+
+	return storage.SortCountries(byCountry), nil
+}
+
+// ListClicks returns up to limit clicks on alias with IDs above afterID
+// that pass filter, oldest first. A click's ID is its position among the
+// link's clicks, counting from 1.
+func (s *Storage) ListClicks(_ context.Context, alias string, filter storage.ClickFilter, afterID int64, limit int) ([]storage.Click, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	l, ok := s.links[alias]
+	if !ok || l.deleted() {
+		return nil, storage.ErrUrlNotFound
+	}
+
+	clicks := []storage.Click{}
+	for i := max(afterID, 0); i < int64(len(l.clicks)) && len(clicks) < limit; i++ {
+		c := l.clicks[i]
+		if filter.Match(c) {
+			c.ID = i + 1
+			clicks = append(clicks, c)
+		}
+	}
+
+	return clicks, nil
+}
+
+// DeleteExpired removes links that expired before now and returns their
+// aliases. Deleted links are left to PurgeDeleted.
+func (s *Storage) DeleteExpired(_ context.Context, now time.Time) ([]string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var deleted []string
+	for alias, l := range s.links {
+		if !l.deleted() && !l.expiresAt.IsZero() && !l.expiresAt.After(now) {
+			delete(s.links, alias)
+			deleted = append(deleted, alias)
+		}
+	}
+
+	return deleted, nil
+}
+
+// PurgeDeleted removes links deleted before the given time, freeing their
+// aliases, and returns the aliases.
+func (s *Storage) PurgeDeleted(_ context.Context, before time.Time) ([]string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var purged []string
+	for alias, l := range s.links {
+		if l.deleted() && !l.deletedAt.After(before) {
+			delete(s.links, alias)
+			purged = append(purged, alias)
+		}
+	}
+
+	return purged, nil
+}
+
+func (l *link) toURL(alias string) storage.URL {
+	return storage.URL{
+		Alias:         alias,
+		URL:           l.url,
+		Domain:        l.domain,
+		CreatedAt:     l.createdAt,
+		ExpiresAt:     l.expiresAt,
+		ActiveFrom:    l.activeFrom,
+		ActiveUntil:   l.activeUntil,
+		MaxClicks:     l.maxClicks,
+		PasswordHash:  l.passwordHash,
+		APIKeyID:      l.apiKeyID,
+		Owner:         l.owner,
+		RedirectType:  l.redirectType,
+		CacheMaxAge:   l.cacheMaxAge,
+		Metadata:      l.metadata,
+		OpenGraph:     l.openGraph,
+		UTM:           l.utm,
+		GeoTargets:    maps.Clone(l.geoTargets),
+		DeviceTargets: maps.Clone(l.deviceTargets),
+		Variants:      slices.Clone(l.variants),
+		CampaignID:    l.campaignID,
+		Tags:          slices.Clone(l.tags),
+		Health:        l.health,
+		State:         l.state,
+	}
+}
+
+// matches reports whether the link is live and passes filter.
+func (l *link) matches(filter storage.ListFilter) bool {
+	return !l.deleted() && filter.Match(storage.URL{
+		URL:          l.url,
+		CreatedAt:    l.createdAt,
+		ExpiresAt:    l.expiresAt,
+		ActiveFrom:   l.activeFrom,
+		ActiveUntil:  l.activeUntil,
+		PasswordHash: l.passwordHash,
+		APIKeyID:     l.apiKeyID,
+		Owner:        l.owner,
+		Tags:         l.tags,
+		Health:       l.health,
+		CampaignID:   l.campaignID,
+		State:        l.state,
+	})
+}
+
+func sortedTags(tags []string) []string {
+	if len(tags) == 0 {
+		return nil
+	}
+	return slices.Sorted(slices.Values(tags))
+}
+
+// ownedBy reports whether the link belongs to owner; an empty owner matches
+// every link.
+func (l *link) ownedBy(owner string) bool {
+	return owner == "" || l.owner == owner
+}
+
+func (l *link) deleted() bool {
+	return !l.deletedAt.IsZero()
+}
+
+func (l *link) exhausted() bool {
+	return l.maxClicks > 0 && l.clickCount >= l.maxClicks
+}
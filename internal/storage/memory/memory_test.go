@@ -0,0 +1,1049 @@
+package memory_test
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"url-shortener/internal/storage"
+	"url-shortener/internal/storage/memory"
+)
+
+func TestStorage_SaveGetDelete(t *testing.T) {
+	s := memory.New()
+	ctx := context.Background()
+
+	id, err := s.SaveURL(ctx, storage.URL{URL: "https://google.com", Alias: "google"})
+	require.NoError(t, err)
+	require.Equal(t, int64(1), id)
+
+	_, err = s.SaveURL(ctx, storage.URL{URL: "https://github.com", Alias: "google"})
+	require.ErrorIs(t, err, storage.ErrUrlExists)
+
+	resURL, err := s.GetURL(ctx, "google")
+	require.NoError(t, err)
+	require.Equal(t, "https://google.com", resURL.URL)
+
+	require.NoError(t, s.UpdateURL(ctx, "google", "", "https://google.com/new"))
+	resURL, err = s.GetURL(ctx, "google")
+	require.NoError(t, err)
+	require.Equal(t, "https://google.com/new", resURL.URL)
+
+	require.NoError(t, s.DeleteURL(ctx, "google", ""))
+	require.ErrorIs(t, s.DeleteURL(ctx, "google", ""), storage.ErrUrlNotFound)
+
+	_, err = s.GetURL(ctx, "google")
+	require.ErrorIs(t, err, storage.ErrUrlNotFound)
+	require.ErrorIs(t, s.UpdateURL(ctx, "google", "", "https://google.com"), storage.ErrUrlNotFound)
+}
+
+func TestStorage_SoftDelete(t *testing.T) {
+	s := memory.New()
+	ctx := context.Background()
+
+	_, err := s.SaveURL(ctx, storage.URL{URL: "https://google.com", Alias: "google", Owner: "alice"})
+	require.NoError(t, err)
+
+	require.ErrorIs(t, s.RestoreURL(ctx, "google", ""), storage.ErrUrlNotFound)
+	require.NoError(t, s.DeleteURL(ctx, "google", "alice"))
+
+	// The alias stays taken while the link can still be restored.
+	_, err = s.SaveURL(ctx, storage.URL{URL: "https://github.com", Alias: "google"})
+	require.ErrorIs(t, err, storage.ErrUrlExists)
+
+	urls, err := s.ListURLs(ctx, storage.ListFilter{}, 10, 0, false)
+	require.NoError(t, err)
+	require.Empty(t, urls)
+
+	_, err = s.GetStats(ctx, "google", time.Time{}, false)
+	require.ErrorIs(t, err, storage.ErrUrlNotFound)
+
+	require.ErrorIs(t, s.RestoreURL(ctx, "google", "bob"), storage.ErrUrlNotFound)
+	require.NoError(t, s.RestoreURL(ctx, "google", "alice"))
+
+	resURL, err := s.GetURL(ctx, "google")
+	require.NoError(t, err)
+	require.Equal(t, "https://google.com", resURL.URL)
+
+	require.NoError(t, s.DeleteURL(ctx, "google", ""))
+
+	purged, err := s.PurgeDeleted(ctx, time.Now().Add(-time.Hour))
+	require.NoError(t, err)
+	require.Empty(t, purged)
+
+	purged, err = s.PurgeDeleted(ctx, time.Now())
+	require.NoError(t, err)
+	require.Equal(t, []string{"google"}, purged)
+
+	require.ErrorIs(t, s.RestoreURL(ctx, "google", ""), storage.ErrUrlNotFound)
+	_, err = s.SaveURL(ctx, storage.URL{URL: "https://github.com", Alias: "google"})
+	require.NoError(t, err)
+}
+
+func TestStorage_RenameURL(t *testing.T) {
+	s := memory.New()
+	ctx := context.Background()
+
+	_, err := s.SaveURL(ctx, storage.URL{URL: "https://google.com", Alias: "google", Owner: "alice"})
+	require.NoError(t, err)
+	_, err = s.SaveURL(ctx, storage.URL{URL: "https://github.com", Alias: "github", Owner: "alice"})
+	require.NoError(t, err)
+	require.NoError(t, s.SaveClick(ctx, storage.Click{Alias: "google", ClickedAt: time.Now()}))
+
+	require.ErrorIs(t, s.RenameURL(ctx, "google", "", "github", time.Time{}), storage.ErrUrlExists)
+	require.ErrorIs(t, s.RenameURL(ctx, "google", "bob", "search", time.Time{}), storage.ErrUrlNotFound)
+	require.ErrorIs(t, s.RenameURL(ctx, "missing", "", "search", time.Time{}), storage.ErrUrlNotFound)
+
+	require.NoError(t, s.RenameURL(ctx, "google", "alice", "search", time.Time{}))
+
+	_, err = s.GetURL(ctx, "google")
+	require.ErrorIs(t, err, storage.ErrUrlNotFound)
+
+	resURL, err := s.GetURL(ctx, "search")
+	require.NoError(t, err)
+	require.Equal(t, "https://google.com", resURL.URL)
+
+	stats, err := s.GetStats(ctx, "search", time.Time{}, false)
+	require.NoError(t, err)
+	require.Equal(t, int64(1), stats.Total)
+
+	forwardUntil := time.Now().Add(time.Hour)
+	require.NoError(t, s.RenameURL(ctx, "search", "", "find", forwardUntil))
+
+	forward, err := s.GetURL(ctx, "search")
+	require.NoError(t, err)
+	require.Equal(t, storage.ForwardURL("find"), forward.URL)
+	require.Equal(t, "alice", forward.Owner)
+	require.True(t, forward.ExpiresAt.Equal(forwardUntil))
+}
+
+func TestStorage_SaveURLs(t *testing.T) {
+	s := memory.New()
+	ctx := context.Background()
+
+	_, err := s.SaveURL(ctx, storage.URL{URL: "https://google.com", Alias: "google"})
+	require.NoError(t, err)
+
+	errs, err := s.SaveURLs(ctx, []storage.URL{
+		{URL: "https://github.com", Alias: "github"},
+		{URL: "https://github.com", Alias: "google"},
+		{URL: "https://gitlab.com", Alias: "github"},
+		{URL: "https://go.dev", Alias: "go"},
+	})
+	require.NoError(t, err)
+	require.Len(t, errs, 4)
+	require.NoError(t, errs[0])
+	require.ErrorIs(t, errs[1], storage.ErrUrlExists)
+	require.ErrorIs(t, errs[2], storage.ErrUrlExists)
+	require.NoError(t, errs[3])
+
+	resURL, err := s.GetURL(ctx, "github")
+	require.NoError(t, err)
+	require.Equal(t, "https://github.com", resURL.URL)
+}
+
+func TestStorage_LegalBlock(t *testing.T) {
+	s := memory.New()
+	ctx := context.Background()
+
+	_, err := s.SaveURL(ctx, storage.URL{URL: "https://google.com", Alias: "google"})
+	require.NoError(t, err)
+
+	_, err = s.GetLegalBlock(ctx, "google")
+	require.ErrorIs(t, err, storage.ErrUrlNotFound)
+
+	require.NoError(t, s.BlockURL(ctx, "google", "DMCA takedown", "case-42"))
+	require.ErrorIs(t, s.BlockURL(ctx, "missing", "DMCA takedown", ""), storage.ErrUrlNotFound)
+
+	_, err = s.GetURL(ctx, "google")
+	require.ErrorIs(t, err, storage.ErrUrlBlocked)
+
+	block, err := s.GetLegalBlock(ctx, "google")
+	require.NoError(t, err)
+	require.Equal(t, storage.LegalBlock{Reason: "DMCA takedown", Reference: "case-42"}, block)
+}
+
+func TestStorage_FlagURL(t *testing.T) {
+	s := memory.New()
+	ctx := context.Background()
+
+	_, err := s.SaveURL(ctx, storage.URL{URL: "https://example.com", Alias: "example"})
+	require.NoError(t, err)
+	_, err = s.SaveURL(ctx, storage.URL{URL: "https://google.com", Alias: "google"})
+	require.NoError(t, err)
+	require.NoError(t, s.BlockURL(ctx, "google", "DMCA takedown", ""))
+
+	require.NoError(t, s.FlagURL(ctx, "example", "MALWARE"))
+	require.ErrorIs(t, s.FlagURL(ctx, "example", "MALWARE"), storage.ErrUrlNotFound)
+	require.ErrorIs(t, s.FlagURL(ctx, "missing", "MALWARE"), storage.ErrUrlNotFound)
+
+	// A legal block takes precedence and keeps its reason.
+	require.ErrorIs(t, s.FlagURL(ctx, "google", "MALWARE"), storage.ErrUrlNotFound)
+	_, err = s.GetURL(ctx, "google")
+	require.ErrorIs(t, err, storage.ErrUrlBlocked)
+
+	_, err = s.GetURL(ctx, "example")
+	require.ErrorIs(t, err, storage.ErrUrlUnsafe)
+
+	_, err = s.GetAliasByURL(ctx, "https://example.com", "")
+	require.ErrorIs(t, err, storage.ErrUrlNotFound)
+}
+
+func TestStorage_ListURLs(t *testing.T) {
+	s := memory.New()
+	ctx := context.Background()
+
+	for i := 0; i < 5; i++ {
+		_, err := s.SaveURL(ctx, storage.URL{URL: fmt.Sprintf("https://example.com/%d", i), Alias: fmt.Sprintf("alias%d", i)})
+		require.NoError(t, err)
+	}
+
+	urls, err := s.ListURLs(ctx, storage.ListFilter{}, 2, 0, true)
+	require.NoError(t, err)
+	require.Len(t, urls, 2)
+	require.Equal(t, "alias4", urls[0].Alias)
+	require.Equal(t, "alias3", urls[1].Alias)
+
+	urls, err = s.ListURLs(ctx, storage.ListFilter{}, 10, 3, false)
+	require.NoError(t, err)
+	require.Len(t, urls, 2)
+	require.Equal(t, "alias3", urls[0].Alias)
+	require.Equal(t, "alias4", urls[1].Alias)
+	require.False(t, urls[0].CreatedAt.IsZero())
+
+	urls, err = s.ListURLs(ctx, storage.ListFilter{}, 10, 10, false)
+	require.NoError(t, err)
+	require.Empty(t, urls)
+}
+
+func TestStorage_ListURLs_Filter(t *testing.T) {
+	s := memory.New()
+	ctx := context.Background()
+
+	before := time.Now().Add(-time.Second)
+	for _, u := range []storage.URL{
+		{URL: "https://Example.com/docs", Alias: "docs", Owner: "alice", Tags: []string{"promo", "docs"}},
+		{URL: "https://example.org/blog", Alias: "blog", Owner: "alice", Tags: []string{"promo"}},
+		{URL: "https://example.com/shop", Alias: "shop", Owner: "bob"},
+	} {
+		_, err := s.SaveURL(ctx, u)
+		require.NoError(t, err)
+	}
+
+	urls, err := s.ListURLs(ctx, storage.ListFilter{URLContains: "EXAMPLE.com"}, 10, 0, false)
+	require.NoError(t, err)
+	require.Len(t, urls, 2)
+	require.Equal(t, "docs", urls[0].Alias)
+	require.Equal(t, "shop", urls[1].Alias)
+
+	urls, err = s.ListURLs(ctx, storage.ListFilter{Owner: "alice", URLContains: ".com"}, 10, 0, false)
+	require.NoError(t, err)
+	require.Len(t, urls, 1)
+	require.Equal(t, "docs", urls[0].Alias)
+
+	urls, err = s.ListURLs(ctx, storage.ListFilter{Tag: "promo"}, 10, 0, false)
+	require.NoError(t, err)
+	require.Len(t, urls, 2)
+	require.Equal(t, []string{"docs", "promo"}, urls[0].Tags)
+	require.Equal(t, []string{"promo"}, urls[1].Tags)
+
+	urls, err = s.ListURLs(ctx, storage.ListFilter{Tag: "promo", URLContains: ".org"}, 10, 0, false)
+	require.NoError(t, err)
+	require.Len(t, urls, 1)
+	require.Equal(t, "blog", urls[0].Alias)
+
+	urls, err = s.ListURLs(ctx, storage.ListFilter{CreatedFrom: before, CreatedTo: time.Now().Add(time.Second)}, 10, 0, false)
+	require.NoError(t, err)
+	require.Len(t, urls, 3)
+
+	urls, err = s.ListURLs(ctx, storage.ListFilter{CreatedTo: before}, 10, 0, false)
+	require.NoError(t, err)
+	require.Empty(t, urls)
+}
+
+func TestStorage_ListURLs_Public(t *testing.T) {
+	s := memory.New()
+	ctx := context.Background()
+
+	now := time.Now()
+	for _, u := range []storage.URL{
+		{URL: "https://example.com/a", Alias: "plain"},
+		{URL: "https://example.com/b", Alias: "protected", PasswordHash: "hash"},
+		{URL: "https://example.com/c", Alias: "legal"},
+		{URL: "https://example.com/d", Alias: "unsafe"},
+		{URL: "https://example.com/e", Alias: "expired", ExpiresAt: now.Add(-time.Minute)},
+		{URL: "https://example.com/f", Alias: "later", ActiveFrom: now.Add(time.Hour)},
+		{URL: "https://example.com/g", Alias: "ended", ActiveUntil: now.Add(-time.Minute)},
+		{URL: "https://example.com/h", Alias: "window", ActiveFrom: now.Add(-time.Hour), ActiveUntil: now.Add(time.Hour)},
+	} {
+		_, err := s.SaveURL(ctx, u)
+		require.NoError(t, err)
+	}
+	require.NoError(t, s.BlockURL(ctx, "legal", "DMCA", "case-1"))
+	require.NoError(t, s.FlagURL(ctx, "unsafe", "MALWARE"))
+
+	urls, err := s.ListURLs(ctx, storage.ListFilter{Public: true}, 10, 0, false)
+	require.NoError(t, err)
+	require.Len(t, urls, 2)
+	require.Equal(t, "plain", urls[0].Alias)
+	require.Equal(t, "window", urls[1].Alias)
+
+	n, err := s.CountURLs(ctx, storage.ListFilter{Public: true})
+	require.NoError(t, err)
+	require.Equal(t, int64(2), n)
+}
+
+func TestStorage_CountURLs(t *testing.T) {
+	s := memory.New()
+	ctx := context.Background()
+
+	for _, u := range []storage.URL{
+		{URL: "https://example.com/a", Alias: "a", Owner: "alice", APIKeyID: 1},
+		{URL: "https://example.com/b", Alias: "b", Owner: "alice"},
+		{URL: "https://example.com/c", Alias: "c", Owner: "bob"},
+	} {
+		_, err := s.SaveURL(ctx, u)
+		require.NoError(t, err)
+	}
+	require.NoError(t, s.DeleteURL(ctx, "b", "alice"))
+
+	n, err := s.CountURLs(ctx, storage.ListFilter{})
+	require.NoError(t, err)
+	require.Equal(t, int64(2), n)
+
+	n, err = s.CountURLs(ctx, storage.ListFilter{Owner: "alice"})
+	require.NoError(t, err)
+	require.Equal(t, int64(1), n)
+
+	n, err = s.CountURLs(ctx, storage.ListFilter{APIKeyID: 1})
+	require.NoError(t, err)
+	require.Equal(t, int64(1), n)
+
+	n, err = s.CountURLs(ctx, storage.ListFilter{Owner: "bob", CreatedFrom: time.Now().Add(time.Minute)})
+	require.NoError(t, err)
+	require.Zero(t, n)
+}
+
+func TestStorage_ConcurrentSave(t *testing.T) {
+	s := memory.New()
+	ctx := context.Background()
+
+	var wg sync.WaitGroup
+	for i := 0; i < 50; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			_, err := s.SaveURL(ctx, storage.URL{URL: "https://example.com", Alias: fmt.Sprintf("alias%d", i)})
+			assert.NoError(t, err)
+		}(i)
+	}
+	wg.Wait()
+
+	urls, err := s.ListURLs(ctx, storage.ListFilter{}, 100, 0, false)
+	require.NoError(t, err)
+	require.Len(t, urls, 50)
+}
+
+func TestStorage_NextURLID(t *testing.T) {
+	s := memory.New()
+	ctx := context.Background()
+
+	first, err := s.NextURLID(ctx)
+	require.NoError(t, err)
+
+	id, err := s.SaveURL(ctx, storage.URL{URL: "https://google.com", Alias: "google"})
+	require.NoError(t, err)
+	require.Greater(t, id, first)
+
+	next, err := s.NextURLID(ctx)
+	require.NoError(t, err)
+	require.Greater(t, next, id)
+}
+
+func TestStorage_GetAliasByURL(t *testing.T) {
+	s := memory.New()
+	ctx := context.Background()
+
+	const url = "https://google.com"
+	for _, u := range []storage.URL{
+		{URL: url, Alias: "limited", Owner: "alice", MaxClicks: 1},
+		{URL: url, Alias: "protected", Owner: "alice", PasswordHash: "hash"},
+		{URL: url, Alias: "permanent", Owner: "alice", RedirectType: 301},
+		{URL: url, Alias: "cached", Owner: "alice", CacheMaxAge: 3600},
+		{URL: url, Alias: "branded", Owner: "alice", OpenGraph: storage.Metadata{Title: "Sale"}},
+		{URL: url, Alias: "expired", Owner: "alice", ExpiresAt: time.Now().Add(-time.Minute)},
+		{URL: url, Alias: "first", Owner: "alice"},
+		{URL: url, Alias: "second", Owner: "alice"},
+		{URL: url, Alias: "bobs", Owner: "bob"},
+	} {
+		_, err := s.SaveURL(ctx, u)
+		require.NoError(t, err)
+	}
+
+	alias, err := s.GetAliasByURL(ctx, url, "alice")
+	require.NoError(t, err)
+	require.Equal(t, "first", alias)
+
+	alias, err = s.GetAliasByURL(ctx, url, "bob")
+	require.NoError(t, err)
+	require.Equal(t, "bobs", alias)
+
+	_, err = s.GetAliasByURL(ctx, url, "")
+	require.ErrorIs(t, err, storage.ErrUrlNotFound)
+
+	_, err = s.GetAliasByURL(ctx, "https://example.com", "alice")
+	require.ErrorIs(t, err, storage.ErrUrlNotFound)
+}
+
+func TestStorage_Metadata(t *testing.T) {
+	s := memory.New()
+	ctx := context.Background()
+
+	meta := storage.Metadata{Title: "Google", Description: "Search", Image: "https://google.com/logo.png"}
+	_, err := s.SaveURL(ctx, storage.URL{URL: "https://google.com", Alias: "google", Metadata: meta})
+	require.NoError(t, err)
+
+	urls, err := s.ListURLs(ctx, storage.ListFilter{}, 10, 0, true)
+	require.NoError(t, err)
+	require.Len(t, urls, 1)
+	require.Equal(t, meta, urls[0].Metadata)
+
+	// The metadata describes the old target, so changing it drops them.
+	require.NoError(t, s.UpdateURL(ctx, "google", "", "https://example.com"))
+
+	urls, err = s.ListURLs(ctx, storage.ListFilter{}, 10, 0, true)
+	require.NoError(t, err)
+	require.Empty(t, urls[0].Metadata)
+}
+
+func TestStorage_Targets(t *testing.T) {
+	s := memory.New()
+	ctx := context.Background()
+
+	geo := storage.Targets{"DE": "https://google.de"}
+	devices := storage.Targets{storage.DeviceIOS: "googleapp://search"}
+	_, err := s.SaveURL(ctx, storage.URL{URL: "https://google.com", Alias: "google", GeoTargets: geo})
+	require.NoError(t, err)
+	_, err = s.SaveURL(ctx, storage.URL{URL: "https://google.com", Alias: "app", DeviceTargets: devices})
+	require.NoError(t, err)
+
+	u, err := s.GetURL(ctx, "google")
+	require.NoError(t, err)
+	require.Equal(t, geo, u.GeoTargets)
+
+	u, err = s.GetURL(ctx, "app")
+	require.NoError(t, err)
+	require.Equal(t, devices, u.DeviceTargets)
+
+	// Links with targets do not always lead to their URL.
+	_, err = s.GetAliasByURL(ctx, "https://google.com", "")
+	require.ErrorIs(t, err, storage.ErrUrlNotFound)
+}
+
+func TestStorage_Domain(t *testing.T) {
+	s := memory.New()
+	ctx := context.Background()
+
+	_, err := s.SaveURL(ctx, storage.URL{URL: "https://google.com", Alias: "google", Domain: "go.acme.com"})
+	require.NoError(t, err)
+
+	u, err := s.GetURL(ctx, "google")
+	require.NoError(t, err)
+	require.Equal(t, "go.acme.com", u.Domain)
+
+	stats, err := s.GetStats(ctx, "google", time.Time{}, false)
+	require.NoError(t, err)
+	require.Equal(t, "go.acme.com", stats.Domain)
+
+	// The forward left behind answers on the same domain.
+	require.NoError(t, s.RenameURL(ctx, "google", "", "search", time.Now().Add(time.Hour)))
+	u, err = s.GetURL(ctx, "google")
+	require.NoError(t, err)
+	require.Equal(t, "go.acme.com", u.Domain)
+
+	// Links on other domains are not reused for links on the default one.
+	_, err = s.GetAliasByURL(ctx, "https://google.com", "")
+	require.ErrorIs(t, err, storage.ErrUrlNotFound)
+}
+
+func TestStorage_Variants(t *testing.T) {
+	s := memory.New()
+	ctx := context.Background()
+
+	variants := storage.Variants{
+		{Name: "A", URL: "https://google.com/a", Weight: 3},
+		{Name: "B", URL: "https://google.com/b", Weight: 1},
+	}
+	_, err := s.SaveURL(ctx, storage.URL{URL: "https://google.com", Alias: "google", Variants: variants})
+	require.NoError(t, err)
+
+	u, err := s.GetURL(ctx, "google")
+	require.NoError(t, err)
+	require.Equal(t, variants, u.Variants)
+
+	now := time.Now()
+	for _, v := range []string{"B", "A", "", "A"} {
+		require.NoError(t, s.SaveClick(ctx, storage.Click{Alias: "google", ClickedAt: now, Variant: v}))
+	}
+
+	stats, err := s.GetStats(ctx, "google", now.Add(-time.Hour), false)
+	require.NoError(t, err)
+	require.Equal(t, int64(4), stats.Total)
+	require.Equal(t, []storage.VariantClicks{{Variant: "A", Clicks: 2}, {Variant: "B", Clicks: 1}}, stats.Variants)
+}
+
+func TestStorage_Stats(t *testing.T) {
+	s := memory.New()
+	ctx := context.Background()
+
+	_, err := s.SaveURL(ctx, storage.URL{URL: "https://google.com", Alias: "google"})
+	require.NoError(t, err)
+
+	day1 := time.Date(2025, 3, 1, 10, 0, 0, 0, time.UTC)
+	day2 := time.Date(2025, 3, 2, 10, 0, 0, 0, time.UTC)
+	for _, clickedAt := range []time.Time{day1.AddDate(0, 0, -10), day1, day2, day2.Add(time.Hour)} {
+		require.NoError(t, s.SaveClick(ctx, storage.Click{Alias: "google", ClickedAt: clickedAt}))
+	}
+
+	stats, err := s.GetStats(ctx, "google", day1.Truncate(24*time.Hour), false)
+	require.NoError(t, err)
+	require.Equal(t, int64(4), stats.Total)
+	require.True(t, day2.Add(time.Hour).Equal(stats.LastAccess))
+	require.Equal(t, []storage.DailyClicks{
+		{Date: "2025-03-01", Clicks: 1},
+		{Date: "2025-03-02", Clicks: 2},
+	}, stats.Daily)
+
+	_, err = s.GetStats(ctx, "missing", day1, false)
+	require.ErrorIs(t, err, storage.ErrUrlNotFound)
+
+	// Imported clicks add to the total only.
+	errs, err := s.SaveURLs(ctx, []storage.URL{{URL: "https://example.com", Alias: "imported", ImportedClicks: 100}})
+	require.NoError(t, err)
+	require.NoError(t, errs[0])
+	require.NoError(t, s.SaveClick(ctx, storage.Click{Alias: "imported", ClickedAt: day2}))
+
+	stats, err = s.GetStats(ctx, "imported", day1.Truncate(24*time.Hour), false)
+	require.NoError(t, err)
+	require.Equal(t, int64(101), stats.Total)
+	require.Equal(t, []storage.DailyClicks{{Date: "2025-03-02", Clicks: 1}}, stats.Daily)
+}
+
+func TestStorage_Referrers(t *testing.T) {
+	s := memory.New()
+	ctx := context.Background()
+
+	_, err := s.SaveURL(ctx, storage.URL{URL: "https://google.com", Alias: "google"})
+	require.NoError(t, err)
+
+	for _, ref := range []string{"https://www.reddit.com/r/golang", "https://reddit.com/", "https://t.co/abc", "", "https://t.co/xyz", "https://Reddit.com"} {
+		require.NoError(t, s.SaveClick(ctx, storage.Click{Alias: "google", ClickedAt: time.Now(), Referrer: ref}))
+	}
+
+	referrers, err := s.GetReferrers(ctx, "google", false)
+	require.NoError(t, err)
+	require.Equal(t, []storage.ReferrerClicks{
+		{Domain: "reddit.com", Clicks: 3},
+		{Domain: "t.co", Clicks: 2},
+		{Domain: "", Clicks: 1},
+	}, referrers)
+
+	_, err = s.GetReferrers(ctx, "missing", false)
+	require.ErrorIs(t, err, storage.ErrUrlNotFound)
+}
+
+func TestStorage_Bots(t *testing.T) {
+	s := memory.New()
+	ctx := context.Background()
+
+	_, err := s.SaveURL(ctx, storage.URL{URL: "https://google.com", Alias: "google"})
+	require.NoError(t, err)
+
+	human := time.Date(2025, 3, 1, 10, 0, 0, 0, time.UTC)
+	bot := human.Add(time.Hour)
+	require.NoError(t, s.SaveClick(ctx, storage.Click{Alias: "google", ClickedAt: human, Referrer: "https://t.co/x"}))
+	require.NoError(t, s.SaveClick(ctx, storage.Click{Alias: "google", ClickedAt: bot, Referrer: "https://bing.com/", Bot: true}))
+
+	stats, err := s.GetStats(ctx, "google", time.Time{}, false)
+	require.NoError(t, err)
+	require.Equal(t, int64(1), stats.Total)
+	require.True(t, human.Equal(stats.LastAccess))
+
+	stats, err = s.GetStats(ctx, "google", time.Time{}, true)
+	require.NoError(t, err)
+	require.Equal(t, int64(2), stats.Total)
+	require.True(t, bot.Equal(stats.LastAccess))
+
+	referrers, err := s.GetReferrers(ctx, "google", false)
+	require.NoError(t, err)
+	require.Equal(t, []storage.ReferrerClicks{{Domain: "t.co", Clicks: 1}}, referrers)
+
+	referrers, err = s.GetReferrers(ctx, "google", true)
+	require.NoError(t, err)
+	require.Len(t, referrers, 2)
+}
+
+func TestStorage_Countries(t *testing.T) {
+	s := memory.New()
+	ctx := context.Background()
+
+	_, err := s.SaveURL(ctx, storage.URL{URL: "https://google.com", Alias: "google"})
+	require.NoError(t, err)
+
+	for _, c := range []storage.Click{
+		{Country: "DE", City: "Berlin"},
+		{Country: "FR"},
+		{Country: "DE", City: "Hamburg"},
+		{},
+		{Country: "US", Bot: true},
+	} {
+		c.Alias, c.ClickedAt = "google", time.Now()
+		require.NoError(t, s.SaveClick(ctx, c))
+	}
+
+	countries, err := s.GetCountries(ctx, "google", false)
+	require.NoError(t, err)
+	require.Equal(t, []storage.CountryClicks{
+		{Country: "DE", Clicks: 2},
+		{Country: "", Clicks: 1},
+		{Country: "FR", Clicks: 1},
+	}, countries)
+
+	countries, err = s.GetCountries(ctx, "google", true)
+	require.NoError(t, err)
+	require.Len(t, countries, 4)
+
+	_, err = s.GetCountries(ctx, "missing", false)
+	require.ErrorIs(t, err, storage.ErrUrlNotFound)
+}
+
+func TestStorage_ListClicks(t *testing.T) {
+	s := memory.New()
+	ctx := context.Background()
+	day := time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC)
+
+	_, err := s.SaveURL(ctx, storage.URL{URL: "https://google.com", Alias: "google"})
+	require.NoError(t, err)
+
+	for i, c := range []storage.Click{
+		{Referrer: "https://a.example/"},
+		{Bot: true},
+		{Country: "DE"},
+		{Variant: "b"},
+		{City: "Paris"},
+	} {
+		c.Alias, c.ClickedAt = "google", day.Add(time.Duration(i)*time.Hour)
+		require.NoError(t, s.SaveClick(ctx, c))
+	}
+
+	clicks, err := s.ListClicks(ctx, "google", storage.ClickFilter{}, 0, 2)
+	require.NoError(t, err)
+	require.Len(t, clicks, 2)
+	require.Equal(t, "https://a.example/", clicks[0].Referrer)
+	require.Equal(t, "DE", clicks[1].Country)
+
+	// The next page starts after the last ID of the previous one.
+	clicks, err = s.ListClicks(ctx, "google", storage.ClickFilter{}, clicks[1].ID, 2)
+	require.NoError(t, err)
+	require.Len(t, clicks, 2)
+	require.Equal(t, "b", clicks[0].Variant)
+	require.Equal(t, "Paris", clicks[1].City)
+
+	clicks, err = s.ListClicks(ctx, "google", storage.ClickFilter{
+		From:        day.Add(time.Hour),
+		To:          day.Add(3 * time.Hour),
+		IncludeBots: true,
+	}, 0, 10)
+	require.NoError(t, err)
+	require.Len(t, clicks, 2)
+	require.True(t, clicks[0].Bot)
+	require.Equal(t, "DE", clicks[1].Country)
+
+	_, err = s.ListClicks(ctx, "missing", storage.ClickFilter{}, 0, 10)
+	require.ErrorIs(t, err, storage.ErrUrlNotFound)
+}
+
+func TestStorage_AliasExists(t *testing.T) {
+	s := memory.New()
+	ctx := context.Background()
+
+	_, err := s.SaveURL(ctx, storage.URL{URL: "https://google.com", Alias: "google"})
+	require.NoError(t, err)
+
+	exists, err := s.AliasExists(ctx, "google")
+	require.NoError(t, err)
+	require.True(t, exists)
+
+	// A deleted link keeps its alias until it is purged.
+	require.NoError(t, s.DeleteURL(ctx, "google", ""))
+	exists, err = s.AliasExists(ctx, "google")
+	require.NoError(t, err)
+	require.True(t, exists)
+
+	exists, err = s.AliasExists(ctx, "missing")
+	require.NoError(t, err)
+	require.False(t, exists)
+}
+
+func TestStorage_GetOwner(t *testing.T) {
+	s := memory.New()
+	ctx := context.Background()
+
+	_, err := s.SaveURL(ctx, storage.URL{URL: "https://google.com", Alias: "google", Owner: "alice"})
+	require.NoError(t, err)
+	// Links that cannot be followed still have an owner.
+	_, err = s.SaveURL(ctx, storage.URL{URL: "https://example.com", Alias: "expired", ExpiresAt: time.Now().Add(-time.Hour)})
+	require.NoError(t, err)
+
+	owner, err := s.GetOwner(ctx, "google")
+	require.NoError(t, err)
+	require.Equal(t, "alice", owner)
+
+	owner, err = s.GetOwner(ctx, "expired")
+	require.NoError(t, err)
+	require.Empty(t, owner)
+
+	require.NoError(t, s.DeleteURL(ctx, "google", ""))
+	_, err = s.GetOwner(ctx, "google")
+	require.ErrorIs(t, err, storage.ErrUrlNotFound)
+
+	_, err = s.GetOwner(ctx, "missing")
+	require.ErrorIs(t, err, storage.ErrUrlNotFound)
+}
+
+func TestStorage_SetURLHealth(t *testing.T) {
+	s := memory.New()
+	ctx := context.Background()
+
+	for _, alias := range []string{"ok", "broken"} {
+		_, err := s.SaveURL(ctx, storage.URL{URL: "https://google.com", Alias: alias})
+		require.NoError(t, err)
+	}
+
+	checkedAt := time.Now()
+	require.NoError(t, s.SetURLHealth(ctx, "ok", storage.Health{Status: storage.HealthOK, StatusCode: 200, CheckedAt: checkedAt}))
+	require.NoError(t, s.SetURLHealth(ctx, "broken", storage.Health{Status: storage.HealthBroken, StatusCode: 404, CheckedAt: checkedAt}))
+
+	urls, err := s.ListURLs(ctx, storage.ListFilter{Health: storage.HealthBroken}, 10, 0, false)
+	require.NoError(t, err)
+	require.Len(t, urls, 1)
+	require.Equal(t, "broken", urls[0].Alias)
+	require.Equal(t, 404, urls[0].Health.StatusCode)
+	require.True(t, checkedAt.Equal(urls[0].Health.CheckedAt))
+
+	err = s.SetURLHealth(ctx, "missing", storage.Health{Status: storage.HealthOK})
+	require.ErrorIs(t, err, storage.ErrUrlNotFound)
+}
+
+func TestStorage_Expiration(t *testing.T) {
+	s := memory.New()
+	ctx := context.Background()
+	now := time.Now()
+
+	_, err := s.SaveURL(ctx, storage.URL{URL: "https://google.com", Alias: "expired", ExpiresAt: now.Add(-time.Minute)})
+	require.NoError(t, err)
+	_, err = s.SaveURL(ctx, storage.URL{URL: "https://google.com", Alias: "fresh", ExpiresAt: now.Add(time.Hour)})
+	require.NoError(t, err)
+	_, err = s.SaveURL(ctx, storage.URL{URL: "https://google.com", Alias: "forever"})
+	require.NoError(t, err)
+
+	_, err = s.GetURL(ctx, "expired")
+	require.ErrorIs(t, err, storage.ErrUrlExpired)
+
+	resURL, err := s.GetURL(ctx, "fresh")
+	require.NoError(t, err)
+	require.WithinDuration(t, now.Add(time.Hour), resURL.ExpiresAt, 0)
+
+	deleted, err := s.DeleteExpired(ctx, now)
+	require.NoError(t, err)
+	require.Equal(t, []string{"expired"}, deleted)
+
+	_, err = s.GetURL(ctx, "expired")
+	require.ErrorIs(t, err, storage.ErrUrlNotFound)
+
+	_, err = s.GetURL(ctx, "forever")
+	require.NoError(t, err)
+}
+
+func TestStorage_ActiveWindow(t *testing.T) {
+	s := memory.New()
+	ctx := context.Background()
+
+	from := time.Now().Add(time.Hour).UTC()
+	until := from.Add(time.Hour)
+	_, err := s.SaveURL(ctx, storage.URL{URL: "https://google.com", Alias: "promo", ActiveFrom: from, ActiveUntil: until})
+	require.NoError(t, err)
+
+	// Links outside their window are returned; the caller decides what to
+	// do with them.
+	u, err := s.GetURL(ctx, "promo")
+	require.NoError(t, err)
+	require.True(t, u.NotActiveYet(time.Now()))
+	require.False(t, u.Ended(time.Now()))
+	require.True(t, u.Ended(until))
+
+	_, err = s.GetAliasByURL(ctx, "https://google.com", "")
+	require.ErrorIs(t, err, storage.ErrUrlNotFound)
+
+	deleted, err := s.DeleteExpired(ctx, until.Add(time.Hour))
+	require.NoError(t, err)
+	require.Empty(t, deleted)
+}
+
+func TestStorage_MaxClicks(t *testing.T) {
+	s := memory.New()
+	ctx := context.Background()
+
+	_, err := s.SaveURL(ctx, storage.URL{URL: "https://google.com", Alias: "once", MaxClicks: 3})
+	require.NoError(t, err)
+
+	var (
+		wg       sync.WaitGroup
+		mu       sync.Mutex
+		consumed int
+	)
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			err := s.ConsumeClick(ctx, "once")
+			if err == nil {
+				mu.Lock()
+				consumed++
+				mu.Unlock()
+				return
+			}
+			assert.ErrorIs(t, err, storage.ErrUrlExhausted)
+		}()
+	}
+	wg.Wait()
+
+	require.Equal(t, 3, consumed)
+
+	_, err = s.GetURL(ctx, "once")
+	require.ErrorIs(t, err, storage.ErrUrlExhausted)
+
+	require.ErrorIs(t, s.ConsumeClick(ctx, "missing"), storage.ErrUrlNotFound)
+
+	require.NoError(t, s.DeleteURL(ctx, "once", ""))
+	require.ErrorIs(t, s.ConsumeClick(ctx, "once"), storage.ErrUrlNotFound)
+}
+
+func TestStorage_APIKeys(t *testing.T) {
+	s := memory.New()
+	ctx := context.Background()
+
+	id, err := s.SaveAPIKey(ctx, storage.APIKey{Name: "ci", Owner: "myuser", Hash: "hash1"})
+	require.NoError(t, err)
+
+	_, err = s.SaveAPIKey(ctx, storage.APIKey{Name: "dup", Hash: "hash1"})
+	require.Error(t, err)
+
+	key, err := s.GetAPIKeyByHash(ctx, "hash1")
+	require.NoError(t, err)
+	require.Equal(t, id, key.ID)
+	require.Equal(t, "myuser", key.Owner)
+	require.False(t, key.Revoked())
+
+	require.ErrorIs(t, s.RevokeAPIKey(ctx, id, "someone"), storage.ErrKeyNotFound)
+	require.NoError(t, s.RevokeAPIKey(ctx, id, "myuser"))
+	require.ErrorIs(t, s.RevokeAPIKey(ctx, id+1, ""), storage.ErrKeyNotFound)
+
+	key, err = s.GetAPIKeyByHash(ctx, "hash1")
+	require.NoError(t, err)
+	require.True(t, key.Revoked())
+
+	keys, err := s.ListAPIKeys(ctx, "")
+	require.NoError(t, err)
+	require.Len(t, keys, 1)
+
+	keys, err = s.ListAPIKeys(ctx, "someone")
+	require.NoError(t, err)
+	require.Empty(t, keys)
+
+	_, err = s.GetAPIKeyByHash(ctx, "missing")
+	require.ErrorIs(t, err, storage.ErrKeyNotFound)
+}
+
+func TestStorage_Ownership(t *testing.T) {
+	s := memory.New()
+	ctx := context.Background()
+
+	_, err := s.SaveURL(ctx, storage.URL{URL: "https://example.com/a", Alias: "alice1", Owner: "alice"})
+	require.NoError(t, err)
+	_, err = s.SaveURL(ctx, storage.URL{URL: "https://example.com/b", Alias: "bob1", Owner: "bob"})
+	require.NoError(t, err)
+
+	urls, err := s.ListURLs(ctx, storage.ListFilter{Owner: "alice"}, 10, 0, false)
+	require.NoError(t, err)
+	require.Len(t, urls, 1)
+	require.Equal(t, "alice1", urls[0].Alias)
+	require.Equal(t, "alice", urls[0].Owner)
+
+	urls, err = s.ListURLs(ctx, storage.ListFilter{}, 10, 0, false)
+	require.NoError(t, err)
+	require.Len(t, urls, 2)
+
+	require.ErrorIs(t, s.UpdateURL(ctx, "bob1", "alice", "https://evil.com"), storage.ErrUrlNotFound)
+	require.ErrorIs(t, s.DeleteURL(ctx, "bob1", "alice"), storage.ErrUrlNotFound)
+	require.NoError(t, s.UpdateURL(ctx, "bob1", "bob", "https://example.com/b2"))
+	require.NoError(t, s.DeleteURL(ctx, "bob1", ""))
+}
+
+func TestStorage_Campaigns(t *testing.T) {
+	s := memory.New()
+	ctx := context.Background()
+
+	id, err := s.SaveCampaign(ctx, storage.Campaign{Name: "spring", Owner: "alice", UTM: storage.UTM{Source: "newsletter"}})
+	require.NoError(t, err)
+	_, err = s.SaveCampaign(ctx, storage.Campaign{Name: "other", Owner: "bob"})
+	require.NoError(t, err)
+
+	_, err = s.GetCampaign(ctx, id, "bob")
+	require.ErrorIs(t, err, storage.ErrCampaignNotFound)
+	campaigns, err := s.ListCampaigns(ctx, "alice")
+	require.NoError(t, err)
+	require.Len(t, campaigns, 1)
+
+	require.ErrorIs(t, s.UpdateCampaign(ctx, storage.Campaign{ID: id, Name: "taken"}, "bob"), storage.ErrCampaignNotFound)
+	require.NoError(t, s.UpdateCampaign(ctx, storage.Campaign{ID: id, Name: "summer"}, "alice"))
+	c, err := s.GetCampaign(ctx, id, "")
+	require.NoError(t, err)
+	require.Equal(t, "summer", c.Name)
+	require.Equal(t, "alice", c.Owner)
+	require.Zero(t, c.UTM)
+
+	_, err = s.SaveURL(ctx, storage.URL{URL: "https://example.com", Alias: "in", Owner: "alice", CampaignID: id})
+	require.NoError(t, err)
+	_, err = s.SaveURL(ctx, storage.URL{URL: "https://example.org", Alias: "out", Owner: "alice"})
+	require.NoError(t, err)
+
+	// Links of a campaign are not plain links to reuse.
+	_, err = s.GetAliasByURL(ctx, "https://example.com", "alice")
+	require.ErrorIs(t, err, storage.ErrUrlNotFound)
+
+	urls, err := s.ListURLs(ctx, storage.ListFilter{CampaignID: id}, 10, 0, false)
+	require.NoError(t, err)
+	require.Len(t, urls, 1)
+	require.Equal(t, "in", urls[0].Alias)
+
+	require.ErrorIs(t, s.DeleteCampaign(ctx, id, "bob"), storage.ErrCampaignNotFound)
+	require.NoError(t, s.DeleteCampaign(ctx, id, "alice"))
+	_, err = s.GetCampaign(ctx, id, "")
+	require.ErrorIs(t, err, storage.ErrCampaignNotFound)
+
+	// The links stay, outside any campaign.
+	u, err := s.GetURL(ctx, "in")
+	require.NoError(t, err)
+	require.Zero(t, u.CampaignID)
+}
+
+func TestStorage_Users(t *testing.T) {
+	s := memory.New()
+	ctx := context.Background()
+
+	id, err := s.SaveUser(ctx, storage.User{Username: "alice", PasswordHash: "hash", Role: storage.RoleUser})
+	require.NoError(t, err)
+
+	_, err = s.SaveUser(ctx, storage.User{Username: "alice", PasswordHash: "other"})
+	require.ErrorIs(t, err, storage.ErrUserExists)
+
+	user, err := s.GetUser(ctx, "alice")
+	require.NoError(t, err)
+	require.Equal(t, id, user.ID)
+	require.Equal(t, "hash", user.PasswordHash)
+	require.False(t, user.IsAdmin())
+
+	_, err = s.GetUser(ctx, "bob")
+	require.ErrorIs(t, err, storage.ErrUserNotFound)
+}
+
+func TestStorage_DeleteUserData(t *testing.T) {
+	s := memory.New()
+	ctx := context.Background()
+
+	for _, u := range []storage.URL{
+		{URL: "https://example.com", Alias: "bob1", Owner: "bob"},
+		{URL: "https://example.org", Alias: "bob2", Owner: "bob"},
+		{URL: "https://example.net", Alias: "alice1", Owner: "alice"},
+	} {
+		_, err := s.SaveURL(ctx, u)
+		require.NoError(t, err)
+	}
+	require.NoError(t, s.DeleteURL(ctx, "bob2", "bob"))
+	require.NoError(t, s.SaveClick(ctx, storage.Click{Alias: "bob1", ClickedAt: time.Now()}))
+	_, err := s.SaveAPIKey(ctx, storage.APIKey{Name: "ci", Owner: "bob", Hash: "h1"})
+	require.NoError(t, err)
+	_, err = s.SaveUser(ctx, storage.User{Username: "bob", PasswordHash: "hash", Role: storage.RoleUser})
+	require.NoError(t, err)
+
+	for _, e := range []storage.AuditEntry{
+		{Action: storage.AuditUserCreate, Target: "bob", Actor: "admin", IP: "10.0.0.1", After: `{"role":"user"}`},
+		{Action: storage.AuditLinkCreate, Target: "bob1", Actor: "bob", IP: "10.0.0.2", After: `{"owner":"bob"}`},
+		{Action: storage.AuditLinkCreate, Target: "alice1", Actor: "alice", IP: "10.0.0.3", After: `{"owner":"alice"}`},
+	} {
+		require.NoError(t, s.SaveAuditEntry(ctx, e))
+	}
+
+	report, err := s.DeleteUserData(ctx, "bob")
+	require.NoError(t, err)
+	require.Equal(t, storage.UserDataReport{
+		Aliases:      []string{"bob1", "bob2"},
+		Clicks:       1,
+		APIKeys:      1,
+		AuditEntries: 2,
+		User:         true,
+	}, report)
+
+	_, err = s.GetURL(ctx, "bob1")
+	require.ErrorIs(t, err, storage.ErrUrlNotFound)
+	_, err = s.GetURL(ctx, "alice1")
+	require.NoError(t, err)
+	_, err = s.GetUser(ctx, "bob")
+	require.ErrorIs(t, err, storage.ErrUserNotFound)
+	keys, err := s.ListAPIKeys(ctx, "")
+	require.NoError(t, err)
+	require.Empty(t, keys)
+
+	entries, err := s.ListAuditLog(ctx, storage.AuditFilter{}, 10, 0)
+	require.NoError(t, err)
+	require.Equal(t, []storage.AuditEntry{
+		{ID: 3, Action: storage.AuditLinkCreate, Target: "alice1", Actor: "alice", IP: "10.0.0.3", After: `{"owner":"alice"}`},
+		{ID: 2, Action: storage.AuditLinkCreate, Target: "bob1"},
+		{ID: 1, Action: storage.AuditUserCreate, Actor: "admin", IP: "10.0.0.1"},
+	}, entries)
+
+	report, err = s.DeleteUserData(ctx, "bob")
+	require.NoError(t, err)
+	require.True(t, report.Empty())
+
+	_, err = s.DeleteUserData(ctx, "")
+	require.Error(t, err)
+}
+
+func TestStorage_PruneIdempotencyKeys(t *testing.T) {
+	s := memory.New()
+	ctx := context.Background()
+	now := time.Now()
+
+	for i, ttl := range []time.Duration{-time.Minute, time.Hour} {
+		_, err := s.ReserveIdempotencyKey(ctx, storage.IdempotencyRecord{
+			Key:       fmt.Sprintf("key-%d", i),
+			CreatedAt: now.Add(-2 * time.Minute),
+			ExpiresAt: now.Add(ttl),
+		})
+		require.NoError(t, err)
+	}
+
+	n, err := s.PruneIdempotencyKeys(ctx, now)
+	require.NoError(t, err)
+	require.Equal(t, int64(1), n)
+
+	_, err = s.ReserveIdempotencyKey(ctx, storage.IdempotencyRecord{Key: "key-1", CreatedAt: now, ExpiresAt: now.Add(time.Hour)})
+	require.ErrorIs(t, err, storage.ErrIdempotencyKeyExists)
+}
@@ -0,0 +1,101 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"time"
+
+	"url-shortener/internal/storage"
+)
+
+func (s *Storage) SaveUser(_ context.Context, user storage.User) (int64, error) {
+	const op = "storage.memory.SaveUser"
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.users[user.Username]; ok {
+		return 0, fmt.Errorf("%s: %w", op, storage.ErrUserExists)
+	}
+
+	s.lastUserID++
+	user.ID = s.lastUserID
+	user.CreatedAt = time.Now().UTC()
+	s.users[user.Username] = user
+
+	return user.ID, nil
+}
+
+func (s *Storage) GetUser(_ context.Context, username string) (storage.User, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	user, ok := s.users[username]
+	if !ok {
+		return storage.User{}, storage.ErrUserNotFound
+	}
+
+	return user, nil
+}
+
+// DeleteUserData removes the links of username with their clicks, their API
+// keys, their campaigns and their account, and erases them from the audit
+// log.
+func (s *Storage) DeleteUserData(_ context.Context, username string) (storage.UserDataReport, error) {
+	const op = "storage.memory.DeleteUserData"
+
+	// Links without an owner would all match.
+	if username == "" {
+		return storage.UserDataReport{}, fmt.Errorf("%s: empty username", op)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var report storage.UserDataReport
+	for alias, l := range s.links {
+		if l.owner != username {
+			continue
+		}
+		report.Aliases = append(report.Aliases, alias)
+		report.Clicks += int64(len(l.clicks))
+		delete(s.links, alias)
+	}
+	slices.Sort(report.Aliases)
+
+	s.keys = slices.DeleteFunc(s.keys, func(k storage.APIKey) bool {
+		if k.Owner != username {
+			return false
+		}
+		report.APIKeys++
+		return true
+	})
+
+	s.campaigns = slices.DeleteFunc(s.campaigns, func(c storage.Campaign) bool {
+		if c.Owner != username {
+			return false
+		}
+		report.Campaigns++
+		// Links of other users stay but leave the campaign.
+		for _, l := range s.links {
+			if l.campaignID == c.ID {
+				l.campaignID = 0
+			}
+		}
+		return true
+	})
+
+	for i := range s.audit {
+		if s.audit[i].Erase(username, report.Aliases) {
+			report.AuditEntries++
+		}
+	}
+
+	if _, ok := s.users[username]; ok {
+		delete(s.users, username)
+		report.User = true
+	}
+
+	return report, nil
+}
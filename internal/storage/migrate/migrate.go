@@ -0,0 +1,275 @@
+// Package migrate applies versioned SQL migrations to a database. A
+// migration is a pair of files, NNNN_name.up.sql and NNNN_name.down.sql;
+// the applied versions are kept in the schema_migrations table.
+package migrate
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"io/fs"
+	"path"
+	"regexp"
+	"slices"
+	"strconv"
+	"time"
+)
+
+var ErrNoDown = errors.New("migration cannot be rolled back")
+
+// Migration is one step of the schema.
+type Migration struct {
+	Version int
+	Name    string
+	Up      string
+	// Down is empty for migrations that cannot be rolled back.
+	Down string
+}
+
+// Status is a migration and whether it has been applied.
+type Status struct {
+	Version int
+	// Name is empty for versions applied by a newer build that this one
+	// does not know.
+	Name string
+	// AppliedAt is zero for pending migrations.
+	AppliedAt time.Time
+}
+
+// Options tune the migrator to the database.
+type Options struct {
+	// Lock is run first in every migration transaction to keep
+	// instances that start together from applying the same migration,
+	// e.g. "SELECT pg_advisory_xact_lock(1)".
+	Lock string
+}
+
+type Migrator struct {
+	db         *sql.DB
+	migrations []Migration
+	opts       Options
+}
+
+var fileName = regexp.MustCompile(`^(\d+)_(\w+)\.(up|down)\.sql$`)
+
+// New reads the migrations in the root of fsys.
+func New(db *sql.DB, fsys fs.FS, opts Options) (*Migrator, error) {
+	const op = "storage.migrate.New"
+
+	files, err := fs.Glob(fsys, "*.sql")
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	byVersion := map[int]*Migration{}
+	for _, file := range files {
+		m := fileName.FindStringSubmatch(path.Base(file))
+		if m == nil {
+			return nil, fmt.Errorf("%s: invalid migration file name %q", op, file)
+		}
+		version, err := strconv.Atoi(m[1])
+		if err != nil || version <= 0 {
+			return nil, fmt.Errorf("%s: invalid migration version in %q", op, file)
+		}
+
+		data, err := fs.ReadFile(fsys, file)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", op, err)
+		}
+
+		mig, ok := byVersion[version]
+		if !ok {
+			mig = &Migration{Version: version, Name: m[2]}
+			byVersion[version] = mig
+		}
+		if mig.Name != m[2] {
+			return nil, fmt.Errorf("%s: migration %d has two names: %s and %s", op, version, mig.Name, m[2])
+		}
+		if m[3] == "up" {
+			mig.Up = string(data)
+		} else {
+			mig.Down = string(data)
+		}
+	}
+
+	migrations := make([]Migration, 0, len(byVersion))
+	for _, mig := range byVersion {
+		if mig.Up == "" {
+			return nil, fmt.Errorf("%s: migration %d has no up file", op, mig.Version)
+		}
+		migrations = append(migrations, *mig)
+	}
+	slices.SortFunc(migrations, func(a, b Migration) int { return a.Version - b.Version })
+
+	return &Migrator{db: db, migrations: migrations, opts: opts}, nil
+}
+
+func (m *Migrator) init(ctx context.Context) error {
+	_, err := m.db.ExecContext(ctx, `
+	CREATE TABLE IF NOT EXISTS schema_migrations(
+		version INTEGER PRIMARY KEY,
+		name TEXT NOT NULL,
+		applied_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP)`)
+	return err
+}
+
+// Version returns the newest applied version, 0 if none.
+func (m *Migrator) Version(ctx context.Context) (int, error) {
+	const op = "storage.migrate.Version"
+
+	if err := m.init(ctx); err != nil {
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+
+	var version int
+	if err := m.db.QueryRowContext(ctx, "SELECT COALESCE(MAX(version), 0) FROM schema_migrations").Scan(&version); err != nil {
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return version, nil
+}
+
+// Up applies every pending migration in order, each in its own
+// transaction, and returns how many were applied.
+func (m *Migrator) Up(ctx context.Context) (int, error) {
+	const op = "storage.migrate.Up"
+
+	if err := m.init(ctx); err != nil {
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+
+	applied := 0
+	for _, mig := range m.migrations {
+		done, err := m.inTx(ctx, func(tx *sql.Tx) (bool, error) {
+			if ok, err := isApplied(ctx, tx, mig.Version); err != nil || ok {
+				return false, err
+			}
+			if _, err := tx.ExecContext(ctx, mig.Up); err != nil {
+				return false, err
+			}
+			_, err := tx.ExecContext(ctx,
+				fmt.Sprintf("INSERT INTO schema_migrations(version, name) VALUES (%d, '%s')", mig.Version, mig.Name),
+			)
+			return err == nil, err
+		})
+		if err != nil {
+			return applied, fmt.Errorf("%s: migration %d_%s: %w", op, mig.Version, mig.Name, err)
+		}
+		if done {
+			applied++
+		}
+	}
+
+	return applied, nil
+}
+
+// Down rolls back the last steps applied migrations, newest first, and
+// returns how many were rolled back.
+func (m *Migrator) Down(ctx context.Context, steps int) (int, error) {
+	const op = "storage.migrate.Down"
+
+	if err := m.init(ctx); err != nil {
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+
+	rolledBack := 0
+	for _, mig := range slices.Backward(m.migrations) {
+		if rolledBack == steps {
+			break
+		}
+
+		done, err := m.inTx(ctx, func(tx *sql.Tx) (bool, error) {
+			if ok, err := isApplied(ctx, tx, mig.Version); err != nil || !ok {
+				return false, err
+			}
+			if mig.Down == "" {
+				return false, ErrNoDown
+			}
+			if _, err := tx.ExecContext(ctx, mig.Down); err != nil {
+				return false, err
+			}
+			_, err := tx.ExecContext(ctx, fmt.Sprintf("DELETE FROM schema_migrations WHERE version = %d", mig.Version))
+			return err == nil, err
+		})
+		if err != nil {
+			return rolledBack, fmt.Errorf("%s: migration %d_%s: %w", op, mig.Version, mig.Name, err)
+		}
+		if done {
+			rolledBack++
+		}
+	}
+
+	return rolledBack, nil
+}
+
+// Status lists the known migrations and the applied versions this build
+// does not know, by version.
+func (m *Migrator) Status(ctx context.Context) ([]Status, error) {
+	const op = "storage.migrate.Status"
+
+	if err := m.init(ctx); err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	rows, err := m.db.QueryContext(ctx, "SELECT version, applied_at FROM schema_migrations")
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	defer rows.Close()
+
+	applied := map[int]time.Time{}
+	for rows.Next() {
+		var (
+			version   int
+			appliedAt time.Time
+		)
+		if err := rows.Scan(&version, &appliedAt); err != nil {
+			return nil, fmt.Errorf("%s: scan row %w", op, err)
+		}
+		applied[version] = appliedAt
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	statuses := make([]Status, 0, len(m.migrations))
+	for _, mig := range m.migrations {
+		statuses = append(statuses, Status{Version: mig.Version, Name: mig.Name, AppliedAt: applied[mig.Version]})
+		delete(applied, mig.Version)
+	}
+	for version, appliedAt := range applied {
+		statuses = append(statuses, Status{Version: version, AppliedAt: appliedAt})
+	}
+	slices.SortFunc(statuses, func(a, b Status) int { return a.Version - b.Version })
+
+	return statuses, nil
+}
+
+// inTx runs fn in a transaction after taking the lock.
+func (m *Migrator) inTx(ctx context.Context, fn func(tx *sql.Tx) (bool, error)) (bool, error) {
+	tx, err := m.db.BeginTx(ctx, nil)
+	if err != nil {
+		return false, err
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	if m.opts.Lock != "" {
+		if _, err := tx.ExecContext(ctx, m.opts.Lock); err != nil {
+			return false, err
+		}
+	}
+
+	done, err := fn(tx)
+	if err != nil || !done {
+		return false, err
+	}
+
+	return true, tx.Commit()
+}
+
+func isApplied(ctx context.Context, tx *sql.Tx, version int) (bool, error) {
+	var n int
+	err := tx.QueryRowContext(ctx, fmt.Sprintf("SELECT COUNT(*) FROM schema_migrations WHERE version = %d", version)).Scan(&n)
+	return n > 0, err
+}
@@ -0,0 +1,121 @@
+package migrate_test
+
+import (
+	"context"
+	"database/sql"
+	"path/filepath"
+	"testing"
+	"testing/fstest"
+
+	_ "github.com/mattn/go-sqlite3"
+	"github.com/stretchr/testify/require"
+
+	"url-shortener/internal/storage/migrate"
+)
+
+func openDB(t *testing.T) *sql.DB {
+	db, err := sql.Open("sqlite3", filepath.Join(t.TempDir(), "test.db"))
+	require.NoError(t, err)
+	t.Cleanup(func() { db.Close() })
+
+	return db
+}
+
+var migrations = fstest.MapFS{
+	"0001_init.up.sql":    {Data: []byte("CREATE TABLE url(id INTEGER PRIMARY KEY, alias TEXT NOT NULL);")},
+	"0001_init.down.sql":  {Data: []byte("DROP TABLE url;")},
+	"0002_owner.up.sql":   {Data: []byte("ALTER TABLE url ADD COLUMN owner TEXT NOT NULL DEFAULT '';\nCREATE INDEX idx_url_owner ON url(owner);")},
+	"0002_owner.down.sql": {Data: []byte("DROP INDEX idx_url_owner;\nALTER TABLE url DROP COLUMN owner;")},
+	"0003_clicks.up.sql":  {Data: []byte("CREATE TABLE clicks(id INTEGER PRIMARY KEY);")},
+}
+
+func TestMigrator(t *testing.T) {
+	db := openDB(t)
+	ctx := context.Background()
+
+	m, err := migrate.New(db, migrations, migrate.Options{})
+	require.NoError(t, err)
+
+	n, err := m.Up(ctx)
+	require.NoError(t, err)
+	require.Equal(t, 3, n)
+
+	n, err = m.Up(ctx)
+	require.NoError(t, err)
+	require.Zero(t, n)
+
+	version, err := m.Version(ctx)
+	require.NoError(t, err)
+	require.Equal(t, 3, version)
+
+	_, err = db.Exec("INSERT INTO url(alias, owner) VALUES ('a', 'alice')")
+	require.NoError(t, err)
+
+	// 0003 has no down file.
+	n, err = m.Down(ctx, 1)
+	require.ErrorIs(t, err, migrate.ErrNoDown)
+	require.Zero(t, n)
+
+	_, err = db.Exec("DROP TABLE clicks")
+	require.NoError(t, err)
+	_, err = db.Exec("DELETE FROM schema_migrations WHERE version = 3")
+	require.NoError(t, err)
+
+	n, err = m.Down(ctx, 1)
+	require.NoError(t, err)
+	require.Equal(t, 1, n)
+
+	_, err = db.Exec("INSERT INTO url(alias, owner) VALUES ('b', 'bob')")
+	require.Error(t, err)
+
+	statuses, err := m.Status(ctx)
+	require.NoError(t, err)
+	require.Len(t, statuses, 3)
+	require.Equal(t, "init", statuses[0].Name)
+	require.False(t, statuses[0].AppliedAt.IsZero())
+	require.Equal(t, "owner", statuses[1].Name)
+	require.True(t, statuses[1].AppliedAt.IsZero())
+	require.True(t, statuses[2].AppliedAt.IsZero())
+}
+
+func TestMigrator_FailedMigration(t *testing.T) {
+	db := openDB(t)
+	ctx := context.Background()
+
+	m, err := migrate.New(db, fstest.MapFS{
+		"0001_init.up.sql":   {Data: []byte("CREATE TABLE url(id INTEGER PRIMARY KEY);")},
+		"0002_broken.up.sql": {Data: []byte("CREATE TABLE clicks(id INTEGER PRIMARY KEY);\nALTER TABLE missing ADD COLUMN x TEXT;")},
+	}, migrate.Options{})
+	require.NoError(t, err)
+
+	n, err := m.Up(ctx)
+	require.Error(t, err)
+	require.Equal(t, 1, n)
+
+	// The failed migration is rolled back as a whole.
+	version, err := m.Version(ctx)
+	require.NoError(t, err)
+	require.Equal(t, 1, version)
+
+	var tables int
+	require.NoError(t, db.QueryRow("SELECT COUNT(*) FROM sqlite_master WHERE name = 'clicks'").Scan(&tables))
+	require.Zero(t, tables)
+}
+
+func TestNew_InvalidFiles(t *testing.T) {
+	db := openDB(t)
+
+	for name, fsys := range map[string]fstest.MapFS{
+		"Bad name":   {"init.up.sql": {Data: []byte("SELECT 1;")}},
+		"No up file": {"0001_init.down.sql": {Data: []byte("SELECT 1;")}},
+		"Two names": {
+			"0001_init.up.sql":  {Data: []byte("SELECT 1;")},
+			"0001_other.up.sql": {Data: []byte("SELECT 1;")},
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			_, err := migrate.New(db, fsys, migrate.Options{})
+			require.Error(t, err)
+		})
+	}
+}
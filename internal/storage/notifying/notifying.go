@@ -0,0 +1,97 @@
+package notifying
+
+import (
+	"context"
+	"time"
+
+	"url-shortener/internal/storage"
+	"url-shortener/internal/storage/instrumented"
+	"url-shortener/internal/webhook"
+)
+
+//go:generate go run github.com/vektra/mockery/v2@latest --name=Notifier
+type Notifier interface {
+	Notify(event webhook.Event)
+}
+
+// Storage passes every call to the backend and reports links that were
+// created, deleted, clicked or purged after expiring to the notifiers.
+type Storage struct {
+	instrumented.Backend
+	notifiers []Notifier
+}
+
+func New(backend instrumented.Backend, notifiers ...Notifier) *Storage {
+	return &Storage{Backend: backend, notifiers: notifiers}
+}
+
+func (s *Storage) notify(event webhook.Event) {
+	// Every notifier gets the same ID, so a consumer of several of them can
+	// drop the duplicates.
+	event.Stamp()
+	for _, n := range s.notifiers {
+		n.Notify(event)
+	}
+}
+
+func (s *Storage) SaveURL(ctx context.Context, u storage.URL) (int64, error) {
+	id, err := s.Backend.SaveURL(ctx, u)
+	if err == nil {
+		s.notify(created(u))
+	}
+
+	return id, err
+}
+
+func (s *Storage) SaveURLs(ctx context.Context, urls []storage.URL) ([]error, error) {
+	errs, err := s.Backend.SaveURLs(ctx, urls)
+	if err == nil {
+		for i, u := range urls {
+			if errs[i] == nil {
+				s.notify(created(u))
+			}
+		}
+	}
+
+	return errs, err
+}
+
+func (s *Storage) DeleteURL(ctx context.Context, alias string, owner string) error {
+	err := s.Backend.DeleteURL(ctx, alias, owner)
+	if err == nil {
+		s.notify(webhook.Event{Type: webhook.EventLinkDeleted, Alias: alias, Owner: owner})
+	}
+
+	return err
+}
+
+func (s *Storage) SaveClick(ctx context.Context, click storage.Click) error {
+	err := s.Backend.SaveClick(ctx, click)
+	if err == nil {
+		s.notify(webhook.Event{
+			Type:      webhook.EventLinkClicked,
+			Time:      click.ClickedAt.UTC(),
+			Alias:     click.Alias,
+			Referrer:  click.Referrer,
+			UserAgent: click.UserAgent,
+			Country:   click.Country,
+			Bot:       click.Bot,
+		})
+	}
+
+	return err
+}
+
+func (s *Storage) DeleteExpired(ctx context.Context, now time.Time) ([]string, error) {
+	deleted, err := s.Backend.DeleteExpired(ctx, now)
+	// Links deleted before a failure are gone all the same.
+	for _, alias := range deleted {
+		s.notify(webhook.Event{Type: webhook.EventLinkExpired, Alias: alias})
+	}
+
+	return deleted, err
+}
+
+func created(u storage.URL) webhook.Event {
+	return webhook.Event{Type: webhook.EventLinkCreated, Alias: u.Alias, URL: u.URL, Owner: u.Owner}
+}
@@ -0,0 +1,53 @@
+package notifying_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"url-shortener/internal/storage"
+	"url-shortener/internal/storage/memory"
+	"url-shortener/internal/storage/notifying"
+	"url-shortener/internal/storage/notifying/mocks"
+	"url-shortener/internal/webhook"
+)
+
+func event(typ, alias string) any {
+	return mock.MatchedBy(func(e webhook.Event) bool {
+		return e.Type == typ && e.Alias == alias
+	})
+}
+
+func TestStorage_Notifies(t *testing.T) {
+	notifierMock := mocks.NewNotifier(t)
+	s := notifying.New(memory.New(), notifierMock)
+	ctx := context.Background()
+	now := time.Now()
+
+	notifierMock.On("Notify", event(webhook.EventLinkCreated, "example")).Return().Once()
+	_, err := s.SaveURL(ctx, storage.URL{URL: "https://example.com", Alias: "example", Owner: "alice"})
+	require.NoError(t, err)
+
+	// Only links that were actually saved are reported.
+	notifierMock.On("Notify", event(webhook.EventLinkCreated, "old")).Return().Once()
+	errs, err := s.SaveURLs(ctx, []storage.URL{
+		{URL: "https://example.com", Alias: "example"},
+		{URL: "https://example.org", Alias: "old", ExpiresAt: now.Add(-time.Minute)},
+	})
+	require.NoError(t, err)
+	require.ErrorIs(t, errs[0], storage.ErrUrlExists)
+
+	notifierMock.On("Notify", event(webhook.EventLinkClicked, "example")).Return().Once()
+	require.NoError(t, s.SaveClick(ctx, storage.Click{Alias: "example", ClickedAt: now}))
+
+	notifierMock.On("Notify", event(webhook.EventLinkExpired, "old")).Return().Once()
+	_, err = s.DeleteExpired(ctx, now)
+	require.NoError(t, err)
+
+	require.ErrorIs(t, s.DeleteURL(ctx, "example", "bob"), storage.ErrUrlNotFound)
+	notifierMock.On("Notify", event(webhook.EventLinkDeleted, "example")).Return().Once()
+	require.NoError(t, s.DeleteURL(ctx, "example", "alice"))
+}
@@ -0,0 +1,153 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"url-shortener/internal/storage"
+)
+
+const campaignColumns = "id, name, owner, utm_source, utm_medium, utm_campaign, expires_at, domain, created_at"
+
+func (s *Storage) SaveCampaign(ctx context.Context, c storage.Campaign) (int64, error) {
+	const op = "storage.postgres.SaveCampaign"
+
+	var id int64
+	err := s.db.QueryRowContext(ctx, `
+	INSERT INTO campaign(name, owner, utm_source, utm_medium, utm_campaign, expires_at, domain)
+	VALUES($1, $2, $3, $4, $5, $6, $7) RETURNING id`,
+		c.Name, c.Owner, c.UTM.Source, c.UTM.Medium, c.UTM.Campaign, nullTime(c.ExpiresAt), c.Domain,
+	).Scan(&id)
+	if err != nil {
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return id, nil
+}
+
+// GetCampaign returns storage.ErrCampaignNotFound for unknown campaigns and,
+// with a non-empty owner, for those of other users.
+func (s *Storage) GetCampaign(ctx context.Context, id int64, owner string) (storage.Campaign, error) {
+	const op = "storage.postgres.GetCampaign"
+
+	c, err := scanCampaign(s.db.QueryRowContext(ctx,
+		"SELECT "+campaignColumns+" FROM campaign WHERE id = $1 AND ($2 = '' OR owner = $2)", id, owner,
+	))
+	if errors.Is(err, sql.ErrNoRows) {
+		return storage.Campaign{}, fmt.Errorf("%s: %w", op, storage.ErrCampaignNotFound)
+	}
+	if err != nil {
+		return storage.Campaign{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return c, nil
+}
+
+// ListCampaigns returns campaigns ordered by id. A non-empty owner limits
+// the result to that user's campaigns.
+func (s *Storage) ListCampaigns(ctx context.Context, owner string) ([]storage.Campaign, error) {
+	const op = "storage.postgres.ListCampaigns"
+
+	rows, err := s.db.QueryContext(ctx,
+		"SELECT "+campaignColumns+" FROM campaign WHERE $1 = '' OR owner = $1 ORDER BY id", owner,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	defer rows.Close()
+
+	campaigns := []storage.Campaign{}
+	for rows.Next() {
+		c, err := scanCampaign(rows)
+		if err != nil {
+			return nil, fmt.Errorf("%s: scan row %w", op, err)
+		}
+		campaigns = append(campaigns, c)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return campaigns, nil
+}
+
+// UpdateCampaign replaces the name and the settings of campaign c.ID; its
+// owner and creation time are kept. A non-empty owner restricts the update
+// to that user's campaigns.
+func (s *Storage) UpdateCampaign(ctx context.Context, c storage.Campaign, owner string) error {
+	const op = "storage.postgres.UpdateCampaign"
+
+	res, err := s.db.ExecContext(ctx, `
+	UPDATE campaign SET name = $1, utm_source = $2, utm_medium = $3, utm_campaign = $4, expires_at = $5, domain = $6
+	WHERE id = $7 AND ($8 = '' OR owner = $8)`,
+		c.Name, c.UTM.Source, c.UTM.Medium, c.UTM.Campaign, nullTime(c.ExpiresAt), c.Domain,
+		c.ID, owner,
+	)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	rowsAffected, err := res.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("%s: failed to get rows affected: %w", op, err)
+	}
+
+	if rowsAffected == 0 {
+		return fmt.Errorf("%s: %w", op, storage.ErrCampaignNotFound)
+	}
+
+	return nil
+}
+
+// DeleteCampaign removes a campaign. Its links are kept and no longer
+// belong to any campaign. A non-empty owner restricts deletion to that
+// user's campaigns.
+func (s *Storage) DeleteCampaign(ctx context.Context, id int64, owner string) error {
+	const op = "storage.postgres.DeleteCampaign"
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	defer tx.Rollback()
+
+	res, err := tx.ExecContext(ctx, "DELETE FROM campaign WHERE id = $1 AND ($2 = '' OR owner = $2)", id, owner)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	rowsAffected, err := res.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("%s: failed to get rows affected: %w", op, err)
+	}
+
+	if rowsAffected == 0 {
+		return fmt.Errorf("%s: %w", op, storage.ErrCampaignNotFound)
+	}
+
+	if _, err := tx.ExecContext(ctx, "UPDATE url SET campaign_id = 0 WHERE campaign_id = $1", id); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	return nil
+}
+
+func scanCampaign(row interface{ Scan(dest ...any) error }) (storage.Campaign, error) {
+	var (
+		c         storage.Campaign
+		expiresAt sql.NullTime
+	)
+	err := row.Scan(&c.ID, &c.Name, &c.Owner, &c.UTM.Source, &c.UTM.Medium, &c.UTM.Campaign, &expiresAt, &c.Domain, &c.CreatedAt)
+	if err != nil {
+		return storage.Campaign{}, err
+	}
+	c.ExpiresAt = expiresAt.Time
+
+	return c, nil
+}
@@ -0,0 +1,73 @@
+-- The schema as it was when migrations were introduced. Every statement is
+-- idempotent so that databases created before then are adopted as they are.
+CREATE TABLE IF NOT EXISTS url(
+	id BIGSERIAL PRIMARY KEY,
+	alias TEXT NOT NULL UNIQUE,
+	url TEXT NOT NULL,
+	state TEXT NOT NULL DEFAULT 'active',
+	block_reason TEXT NOT NULL DEFAULT '',
+	block_reference TEXT NOT NULL DEFAULT '',
+	created_at TIMESTAMPTZ NOT NULL DEFAULT now());
+CREATE INDEX IF NOT EXISTS idx_url_created_at ON url(created_at);
+CREATE TABLE IF NOT EXISTS clicks(
+	id BIGSERIAL PRIMARY KEY,
+	alias TEXT NOT NULL REFERENCES url(alias) ON DELETE CASCADE ON UPDATE CASCADE,
+	clicked_at TIMESTAMPTZ NOT NULL,
+	referrer TEXT NOT NULL DEFAULT '',
+	user_agent TEXT NOT NULL DEFAULT '');
+CREATE INDEX IF NOT EXISTS idx_clicks_alias_clicked_at ON clicks(alias, clicked_at);
+ALTER TABLE url ADD COLUMN IF NOT EXISTS expires_at TIMESTAMPTZ;
+CREATE INDEX IF NOT EXISTS idx_url_expires_at ON url(expires_at) WHERE expires_at IS NOT NULL;
+ALTER TABLE url ADD COLUMN IF NOT EXISTS max_clicks BIGINT NOT NULL DEFAULT 0;
+ALTER TABLE url ADD COLUMN IF NOT EXISTS click_count BIGINT NOT NULL DEFAULT 0;
+ALTER TABLE url ADD COLUMN IF NOT EXISTS password_hash TEXT NOT NULL DEFAULT '';
+ALTER TABLE url ADD COLUMN IF NOT EXISTS api_key_id BIGINT NOT NULL DEFAULT 0;
+ALTER TABLE url ADD COLUMN IF NOT EXISTS owner TEXT NOT NULL DEFAULT '';
+ALTER TABLE url ADD COLUMN IF NOT EXISTS redirect_type INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE url ADD COLUMN IF NOT EXISTS title TEXT NOT NULL DEFAULT '';
+ALTER TABLE url ADD COLUMN IF NOT EXISTS description TEXT NOT NULL DEFAULT '';
+ALTER TABLE url ADD COLUMN IF NOT EXISTS image_url TEXT NOT NULL DEFAULT '';
+CREATE INDEX IF NOT EXISTS idx_url_owner ON url(owner);
+CREATE INDEX IF NOT EXISTS idx_url_owner_url ON url(owner, url);
+CREATE INDEX IF NOT EXISTS idx_url_owner_created_at ON url(owner, created_at);
+ALTER TABLE url ADD COLUMN IF NOT EXISTS deleted_at TIMESTAMPTZ;
+ALTER TABLE url ADD COLUMN IF NOT EXISTS utm_source TEXT NOT NULL DEFAULT '';
+ALTER TABLE url ADD COLUMN IF NOT EXISTS utm_medium TEXT NOT NULL DEFAULT '';
+ALTER TABLE url ADD COLUMN IF NOT EXISTS utm_campaign TEXT NOT NULL DEFAULT '';
+ALTER TABLE url ADD COLUMN IF NOT EXISTS geo_targets TEXT NOT NULL DEFAULT '';
+ALTER TABLE url ADD COLUMN IF NOT EXISTS device_targets TEXT NOT NULL DEFAULT '';
+ALTER TABLE url ADD COLUMN IF NOT EXISTS variants TEXT NOT NULL DEFAULT '';
+ALTER TABLE url ADD COLUMN IF NOT EXISTS active_from TIMESTAMPTZ;
+ALTER TABLE url ADD COLUMN IF NOT EXISTS active_until TIMESTAMPTZ;
+ALTER TABLE clicks ADD COLUMN IF NOT EXISTS variant TEXT NOT NULL DEFAULT '';
+CREATE INDEX IF NOT EXISTS idx_url_deleted_at ON url(deleted_at) WHERE deleted_at IS NOT NULL;
+CREATE TABLE IF NOT EXISTS api_keys(
+	id BIGSERIAL PRIMARY KEY,
+	name TEXT NOT NULL,
+	owner TEXT NOT NULL DEFAULT '',
+	key_hash TEXT NOT NULL UNIQUE,
+	created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+	revoked_at TIMESTAMPTZ);
+CREATE TABLE IF NOT EXISTS users(
+	id BIGSERIAL PRIMARY KEY,
+	username TEXT NOT NULL UNIQUE,
+	password_hash TEXT NOT NULL,
+	role TEXT NOT NULL DEFAULT 'user',
+	created_at TIMESTAMPTZ NOT NULL DEFAULT now());
+CREATE TABLE IF NOT EXISTS url_tag(
+	url_id BIGINT NOT NULL REFERENCES url(id) ON DELETE CASCADE,
+	tag TEXT NOT NULL,
+	PRIMARY KEY (tag, url_id));
+CREATE INDEX IF NOT EXISTS idx_url_tag_url_id ON url_tag(url_id);
+CREATE TABLE IF NOT EXISTS audit_log(
+	id BIGSERIAL PRIMARY KEY,
+	created_at TIMESTAMPTZ NOT NULL,
+	action TEXT NOT NULL,
+	target TEXT NOT NULL,
+	actor TEXT NOT NULL DEFAULT '',
+	api_key_id BIGINT NOT NULL DEFAULT 0,
+	ip TEXT NOT NULL DEFAULT '',
+	before_value TEXT NOT NULL DEFAULT '',
+	after_value TEXT NOT NULL DEFAULT '');
+CREATE INDEX IF NOT EXISTS idx_audit_log_target ON audit_log(target);
+CREATE INDEX IF NOT EXISTS idx_audit_log_actor ON audit_log(actor);
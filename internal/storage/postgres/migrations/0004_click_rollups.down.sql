@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS click_rollup_state;
+DROP TABLE IF EXISTS click_rollups;
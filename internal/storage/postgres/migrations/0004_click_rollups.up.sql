@@ -0,0 +1,12 @@
+-- Clicks of the UTC days before rolled_until are summed up in
+-- click_rollups; GetStats counts only later ones from clicks.
+CREATE TABLE click_rollups(
+	alias TEXT NOT NULL REFERENCES url(alias) ON DELETE CASCADE ON UPDATE CASCADE,
+	day DATE NOT NULL,
+	variant TEXT NOT NULL DEFAULT '',
+	clicks BIGINT NOT NULL,
+	PRIMARY KEY (alias, day, variant));
+CREATE TABLE click_rollup_state(
+	id INTEGER PRIMARY KEY CHECK (id = 1),
+	rolled_until TIMESTAMPTZ NOT NULL);
+INSERT INTO click_rollup_state(id, rolled_until) VALUES (1, 'epoch');
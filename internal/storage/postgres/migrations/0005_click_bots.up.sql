@@ -0,0 +1,3 @@
+-- Clicks saved before bots were told apart count as human ones.
+ALTER TABLE clicks ADD COLUMN bot BOOLEAN NOT NULL DEFAULT FALSE;
+ALTER TABLE click_rollups ADD COLUMN bot_clicks BIGINT NOT NULL DEFAULT 0;
@@ -0,0 +1,3 @@
+ALTER TABLE url ADD COLUMN health TEXT NOT NULL DEFAULT '';
+ALTER TABLE url ADD COLUMN health_status_code INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE url ADD COLUMN health_checked_at TIMESTAMPTZ;
@@ -0,0 +1,3 @@
+ALTER TABLE url DROP COLUMN og_image;
+ALTER TABLE url DROP COLUMN og_description;
+ALTER TABLE url DROP COLUMN og_title;
@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS idx_url_campaign_id;
+ALTER TABLE url DROP COLUMN campaign_id;
+DROP TABLE IF EXISTS campaign;
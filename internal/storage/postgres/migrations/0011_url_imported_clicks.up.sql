@@ -0,0 +1 @@
+ALTER TABLE url ADD COLUMN imported_clicks BIGINT NOT NULL DEFAULT 0;
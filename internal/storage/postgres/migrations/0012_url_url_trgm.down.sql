@@ -0,0 +1,2 @@
+-- The extension is left in place: other database objects may use it.
+DROP INDEX IF EXISTS idx_url_url_trgm;
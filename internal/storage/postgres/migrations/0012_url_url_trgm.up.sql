@@ -0,0 +1,11 @@
+-- Searching by a part of the URL can use a trigram index, but only where
+-- the pg_trgm extension is available to this user; elsewhere the search
+-- still works by scanning.
+DO $$
+BEGIN
+	CREATE EXTENSION IF NOT EXISTS pg_trgm;
+	CREATE INDEX IF NOT EXISTS idx_url_url_trgm ON url USING gin (url gin_trgm_ops);
+EXCEPTION WHEN insufficient_privilege OR undefined_file THEN
+	RAISE NOTICE 'pg_trgm is not available, searching by url scans the table';
+END
+$$;
@@ -0,0 +1,1063 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"embed"
+	"errors"
+	"fmt"
+	"io/fs"
+	"strings"
+	"time"
+
+	"github.com/lib/pq"
+
+	"url-shortener/internal/storage"
+	"url-shortener/internal/storage/migrate"
+)
+
+const uniqueViolation = "23505"
+
+type Storage struct {
+	db *sql.DB
+}
+
+type Options struct {
+	MaxOpenConns    int
+	MaxIdleConns    int
+	ConnMaxLifetime time.Duration
+}
+
+//go:embed migrations/*.sql
+var migrations embed.FS
+
+// migrationLock serializes migrations of instances that start together.
+const migrationLock = "SELECT pg_advisory_xact_lock(7283015)"
+
+// New connects to the database and applies pending migrations.
+func New(dsn string, opts Options) (*Storage, error) {
+	const op = "storage.postgres.New"
+
+	s, err := Open(dsn, opts)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	ctx := context.Background()
+
+	m, err := s.Migrator(ctx)
+	if err == nil {
+		_, err = m.Up(ctx)
+	}
+	if err != nil {
+		s.db.Close()
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return s, nil
+}
+
+// Open connects to the database as it is, without migrating it.
+func Open(dsn string, opts Options) (*Storage, error) {
+	const op = "storage.postgres.Open"
+
+	db, err := sql.Open("postgres", dsn)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	db.SetMaxOpenConns(opts.MaxOpenConns)
+	db.SetMaxIdleConns(opts.MaxIdleConns)
+	db.SetConnMaxLifetime(opts.ConnMaxLifetime)
+
+	if err := db.Ping(); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return &Storage{db: db}, nil
+}
+
+// Migrator returns the migrations of the schema.
+func (s *Storage) Migrator(_ context.Context) (*migrate.Migrator, error) {
+	const op = "storage.postgres.Migrator"
+
+	fsys, err := fs.Sub(migrations, "migrations")
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	m, err := migrate.New(s.db, fsys, migrate.Options{Lock: migrationLock})
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return m, nil
+}
+
+// Ping checks that the database is reachable.
+func (s *Storage) Ping(ctx context.Context) error {
+	const op = "storage.postgres.Ping"
+
+	if err := s.db.PingContext(ctx); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	return nil
+}
+
+// Vacuum reclaims the space of deleted rows and refreshes the statistics
+// of the query planner. It does not lock tables against reads or writes.
+func (s *Storage) Vacuum(ctx context.Context) error {
+	const op = "storage.postgres.Vacuum"
+
+	if _, err := s.db.ExecContext(ctx, `VACUUM ANALYZE`); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	return nil
+}
+
+// Close closes the database connections.
+func (s *Storage) Close() error {
+	const op = "storage.postgres.Close"
+
+	if err := s.db.Close(); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	return nil
+}
+
+func (s *Storage) SaveURL(ctx context.Context, u storage.URL) (int64, error) {
+	const op = "storage.postgres.SaveURL"
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+	defer tx.Rollback()
+
+	stmt, err := tx.PrepareContext(ctx, `
+	INSERT INTO url(url, alias, expires_at, max_clicks, password_hash, api_key_id, owner, redirect_type, title, description, image_url,
+		utm_source, utm_medium, utm_campaign, geo_targets, device_targets, variants, active_from, active_until, domain, cache_max_age,
+		og_title, og_description, og_image, campaign_id)
+	VALUES($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18, $19, $20, $21, $22, $23, $24, $25) RETURNING id`)
+	if err != nil {
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+	defer stmt.Close()
+
+	var id int64
+	err = stmt.QueryRowContext(ctx,
+		u.URL, u.Alias, nullTime(u.ExpiresAt), u.MaxClicks, u.PasswordHash, u.APIKeyID, u.Owner,
+		u.RedirectType, u.Metadata.Title, u.Metadata.Description, u.Metadata.Image,
+		u.UTM.Source, u.UTM.Medium, u.UTM.Campaign, u.GeoTargets, u.DeviceTargets, u.Variants,
+		nullTime(u.ActiveFrom), nullTime(u.ActiveUntil), u.Domain, u.CacheMaxAge,
+		u.OpenGraph.Title, u.OpenGraph.Description, u.OpenGraph.Image, u.CampaignID,
+	).Scan(&id)
+	if err != nil {
+		var pqErr *pq.Error
+		if errors.As(err, &pqErr) && pqErr.Code == uniqueViolation {
+			return 0, fmt.Errorf("%s: %w", op, storage.ErrUrlExists)
+		}
+
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+
+	if err := saveTags(ctx, tx, id, u.Tags); err != nil {
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return id, nil
+}
+
+// saveTags links tags to the link saved as id; repeated tags are stored
+// once.
+func saveTags(ctx context.Context, tx *sql.Tx, id int64, tags []string) error {
+	if len(tags) == 0 {
+		return nil
+	}
+
+	_, err := tx.ExecContext(ctx, `
+	INSERT INTO url_tag(url_id, tag) SELECT $1, unnest($2::text[])
+	ON CONFLICT DO NOTHING`, id, pq.Array(tags))
+
+	return err
+}
+
+// NextURLID takes the next value of the sequence behind url.id. Links saved
+// afterwards skip it, so every value is handed out once.
+func (s *Storage) NextURLID(ctx context.Context) (int64, error) {
+	const op = "storage.postgres.NextURLID"
+
+	var id int64
+	err := s.db.QueryRowContext(ctx, `SELECT nextval(pg_get_serial_sequence('url', 'id'))`).Scan(&id)
+	if err != nil {
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return id, nil
+}
+
+// SaveURLs saves urls in a single transaction. An alias that is already
+// taken (or repeated in the batch) does not abort the batch: the link is
+// skipped and storage.ErrUrlExists is reported at its index in the returned
+// slice. The second error means nothing was saved.
+func (s *Storage) SaveURLs(ctx context.Context, urls []storage.URL) ([]error, error) {
+	const op = "storage.postgres.SaveURLs"
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	defer tx.Rollback()
+
+	stmt, err := tx.PrepareContext(ctx, `
+	INSERT INTO url(url, alias, expires_at, max_clicks, password_hash, api_key_id, owner, redirect_type, title, description, image_url,
+		utm_source, utm_medium, utm_campaign, geo_targets, device_targets, variants, active_from, active_until, domain, cache_max_age,
+		og_title, og_description, og_image, campaign_id, imported_clicks)
+	VALUES($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18, $19, $20, $21, $22, $23, $24, $25, $26)
+	ON CONFLICT (alias) DO NOTHING RETURNING id`)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	defer stmt.Close()
+
+	errs := make([]error, len(urls))
+	for i, u := range urls {
+		var id int64
+		err := stmt.QueryRowContext(ctx,
+			u.URL, u.Alias, nullTime(u.ExpiresAt), u.MaxClicks, u.PasswordHash, u.APIKeyID, u.Owner,
+			u.RedirectType, u.Metadata.Title, u.Metadata.Description, u.Metadata.Image,
+			u.UTM.Source, u.UTM.Medium, u.UTM.Campaign, u.GeoTargets, u.DeviceTargets, u.Variants,
+			nullTime(u.ActiveFrom), nullTime(u.ActiveUntil), u.Domain, u.CacheMaxAge,
+			u.OpenGraph.Title, u.OpenGraph.Description, u.OpenGraph.Image, u.CampaignID, u.ImportedClicks,
+		).Scan(&id)
+		if errors.Is(err, sql.ErrNoRows) {
+			errs[i] = fmt.Errorf("%s: %w", op, storage.ErrUrlExists)
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", op, err)
+		}
+
+		if err := saveTags(ctx, tx, id, u.Tags); err != nil {
+			return nil, fmt.Errorf("%s: %w", op, err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return errs, nil
+}
+
+func (s *Storage) GetURL(ctx context.Context, alias string) (storage.URL, error) {
+	const op = "storage.postgres.GetURL"
+
+	stmt, err := s.db.PrepareContext(ctx, `
+	SELECT url, state, created_at, expires_at, max_clicks, click_count, password_hash, redirect_type,
+		utm_source, utm_medium, utm_campaign, geo_targets, device_targets, variants, active_from, active_until, domain, cache_max_age,
+		og_title, og_description, og_image, campaign_id
+	FROM url WHERE alias = $1 AND deleted_at IS NULL`)
+	if err != nil {
+		return storage.URL{}, fmt.Errorf("%s: %w", op, err)
+	}
+	defer stmt.Close()
+
+	var (
+		res                     = storage.URL{Alias: alias}
+		state                   string
+		expiresAt               sql.NullTime
+		activeFrom, activeUntil sql.NullTime
+		clickCount              int64
+	)
+	err = stmt.QueryRowContext(ctx, alias).Scan(
+		&res.URL, &state, &res.CreatedAt, &expiresAt, &res.MaxClicks, &clickCount, &res.PasswordHash, &res.RedirectType,
+		&res.UTM.Source, &res.UTM.Medium, &res.UTM.Campaign, &res.GeoTargets, &res.DeviceTargets, &res.Variants,
+		&activeFrom, &activeUntil, &res.Domain, &res.CacheMaxAge,
+		&res.OpenGraph.Title, &res.OpenGraph.Description, &res.OpenGraph.Image, &res.CampaignID,
+	)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return storage.URL{}, storage.ErrUrlNotFound
+		}
+		return storage.URL{}, fmt.Errorf("%s: execute statement %w", op, err)
+	}
+	res.ExpiresAt = expiresAt.Time
+	res.ActiveFrom = activeFrom.Time
+	res.ActiveUntil = activeUntil.Time
+
+	if state == storage.StateBlockedLegal {
+		return storage.URL{}, storage.ErrUrlBlocked
+	}
+
+	if state == storage.StateBlockedUnsafe {
+		return storage.URL{}, storage.ErrUrlUnsafe
+	}
+
+	if res.Expired(time.Now()) {
+		return storage.URL{}, storage.ErrUrlExpired
+	}
+
+	if res.MaxClicks > 0 && clickCount >= res.MaxClicks {
+		return storage.URL{}, storage.ErrUrlExhausted
+	}
+
+	return res, nil
+}
+
+// GetAliasByURL returns the alias of the oldest plain link of owner
+// pointing to url: active, unexpired, without a password, a click limit,
+// a custom redirect type or cache lifetime, UTM parameters, geo or device
+// targets, A/B variants, an activation window, a short domain or an Open
+// Graph preview of its own, and not in a campaign.
+// Unlike elsewhere, owner "" matches only links without an owner. It
+// returns storage.ErrUrlNotFound if there is no such link.
+func (s *Storage) GetAliasByURL(ctx context.Context, url string, owner string) (string, error) {
+	const op = "storage.postgres.GetAliasByURL"
+
+	var alias string
+	err := s.db.QueryRowContext(ctx, `
+	SELECT alias FROM url
+	WHERE owner = $1 AND url = $2 AND state = $3 AND deleted_at IS NULL
+		AND (expires_at IS NULL OR expires_at > now())
+		AND max_clicks = 0 AND password_hash = '' AND redirect_type = 0 AND cache_max_age = 0
+		AND utm_source = '' AND utm_medium = '' AND utm_campaign = '' AND geo_targets = '' AND device_targets = '' AND variants = ''
+		AND active_from IS NULL AND active_until IS NULL AND domain = ''
+		AND og_title = '' AND og_description = '' AND og_image = '' AND campaign_id = 0
+	ORDER BY id LIMIT 1`, owner, url, storage.StateActive).Scan(&alias)
+	if errors.Is(err, sql.ErrNoRows) {
+		return "", storage.ErrUrlNotFound
+	}
+	if err != nil {
+		return "", fmt.Errorf("%s: %w", op, err)
+	}
+
+	return alias, nil
+}
+
+// ConsumeClick atomically counts a redirect against the link's max_clicks
+// and returns storage.ErrUrlExhausted once the limit has been reached.
+func (s *Storage) ConsumeClick(ctx context.Context, alias string) error {
+	const op = "storage.postgres.ConsumeClick"
+
+	var consumed, exists bool
+	err := s.db.QueryRowContext(ctx, `
+	WITH upd AS (
+		UPDATE url SET click_count = click_count + 1
+		WHERE alias = $1 AND deleted_at IS NULL AND (max_clicks = 0 OR click_count < max_clicks)
+		RETURNING 1
+	)
+	SELECT EXISTS(SELECT 1 FROM upd), EXISTS(SELECT 1 FROM url WHERE alias = $1 AND deleted_at IS NULL)`, alias,
+	).Scan(&consumed, &exists)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	if !exists {
+		return fmt.Errorf("%s: %w", op, storage.ErrUrlNotFound)
+	}
+	if !consumed {
+		return fmt.Errorf("%s: %w", op, storage.ErrUrlExhausted)
+	}
+
+	return nil
+}
+
+// DeleteURL marks alias as deleted: it stops resolving but keeps the alias
+// taken until PurgeDeleted removes it, and can be brought back with
+// RestoreURL. A non-empty owner restricts the deletion to that user's
+// links; other links are reported as not found.
+func (s *Storage) DeleteURL(ctx context.Context, alias string, owner string) error {
+	const op = "storage.postgres.DeleteURL"
+
+	return s.execAffectingAlias(ctx, op,
+		"UPDATE url SET deleted_at = now() WHERE alias = $1 AND deleted_at IS NULL AND ($2 = '' OR owner = $2)",
+		alias, owner,
+	)
+}
+
+// RestoreURL brings back a deleted link. A non-empty owner restricts the
+// restore to that user's links; other links, and links that are not
+// deleted, are reported as not found.
+func (s *Storage) RestoreURL(ctx context.Context, alias string, owner string) error {
+	const op = "storage.postgres.RestoreURL"
+
+	return s.execAffectingAlias(ctx, op,
+		"UPDATE url SET deleted_at = NULL WHERE alias = $1 AND deleted_at IS NOT NULL AND ($2 = '' OR owner = $2)",
+		alias, owner,
+	)
+}
+
+// UpdateURL points alias at newURL. A non-empty owner restricts the update
+// to that user's links; other links are reported as not found.
+func (s *Storage) UpdateURL(ctx context.Context, alias string, owner string, newURL string) error {
+	const op = "storage.postgres.UpdateURL"
+
+	return s.execAffectingAlias(ctx, op,
+		`
+		UPDATE url SET url = $3, title = '', description = '', image_url = ''
+		WHERE alias = $1 AND deleted_at IS NULL AND ($2 = '' OR owner = $2)`, alias, owner, newURL,
+	)
+}
+
+// RenameURL moves a live link and its clicks from alias to newAlias and
+// returns storage.ErrUrlExists if newAlias is taken. A non-zero
+// forwardUntil keeps alias until then as a link to storage.ForwardURL of
+// newAlias. A non-empty owner restricts the rename to that user's links;
+// other links are reported as not found.
+func (s *Storage) RenameURL(ctx context.Context, alias string, owner string, newAlias string, forwardUntil time.Time) error {
+	const op = "storage.postgres.RenameURL"
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	defer tx.Rollback()
+
+	// clicks follow through ON UPDATE CASCADE.
+	res, err := tx.ExecContext(ctx,
+		"UPDATE url SET alias = $3 WHERE alias = $1 AND deleted_at IS NULL AND ($2 = '' OR owner = $2)",
+		alias, owner, newAlias,
+	)
+	if err != nil {
+		var pqErr *pq.Error
+		if errors.As(err, &pqErr) && pqErr.Code == uniqueViolation {
+			return fmt.Errorf("%s: %w", op, storage.ErrUrlExists)
+		}
+
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	rowsAffected, err := res.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("%s: failed to get rows affected: %w", op, err)
+	}
+
+	if rowsAffected == 0 {
+		return fmt.Errorf("%s: %w", op, storage.ErrUrlNotFound)
+	}
+
+	if !forwardUntil.IsZero() {
+		_, err = tx.ExecContext(ctx, `
+		INSERT INTO url(url, alias, expires_at, owner, domain)
+		SELECT $1, $2, $3, owner, domain FROM url WHERE alias = $4`,
+			storage.ForwardURL(newAlias), alias, forwardUntil, newAlias,
+		)
+		if err != nil {
+			return fmt.Errorf("%s: %w", op, err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	return nil
+}
+
+func (s *Storage) BlockURL(ctx context.Context, alias string, reason string, reference string) error {
+	const op = "storage.postgres.BlockURL"
+
+	return s.execAffectingAlias(ctx, op,
+		"UPDATE url SET state = $2, block_reason = $3, block_reference = $4 WHERE alias = $1",
+		alias, storage.StateBlockedLegal, reason, reference,
+	)
+}
+
+// FlagURL marks an active link as unsafe after a reputation check flagged
+// its target; threat is kept as the block reason. Links that are already
+// blocked are left as they are and reported as storage.ErrUrlNotFound.
+func (s *Storage) FlagURL(ctx context.Context, alias string, threat string) error {
+	const op = "storage.postgres.FlagURL"
+
+	return s.execAffectingAlias(ctx, op,
+		"UPDATE url SET state = $2, block_reason = $3 WHERE alias = $1 AND state = $4 AND deleted_at IS NULL",
+		alias, storage.StateBlockedUnsafe, threat, storage.StateActive,
+	)
+}
+
+// SetURLHealth records the outcome of checking the destination of alias.
+func (s *Storage) SetURLHealth(ctx context.Context, alias string, health storage.Health) error {
+	const op = "storage.postgres.SetURLHealth"
+
+	return s.execAffectingAlias(ctx, op,
+		"UPDATE url SET health = $2, health_status_code = $3, health_checked_at = $4 WHERE alias = $1 AND deleted_at IS NULL",
+		alias, health.Status, health.StatusCode, nullTime(health.CheckedAt),
+	)
+}
+
+func (s *Storage) GetLegalBlock(ctx context.Context, alias string) (storage.LegalBlock, error) {
+	const op = "storage.postgres.GetLegalBlock"
+
+	stmt, err := s.db.PrepareContext(ctx, "SELECT block_reason, block_reference FROM url WHERE alias = $1 AND state = $2")
+	if err != nil {
+		return storage.LegalBlock{}, fmt.Errorf("%s: %w", op, err)
+	}
+	defer stmt.Close()
+
+	var block storage.LegalBlock
+	err = stmt.QueryRowContext(ctx, alias, storage.StateBlockedLegal).Scan(&block.Reason, &block.Reference)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return storage.LegalBlock{}, storage.ErrUrlNotFound
+		}
+		return storage.LegalBlock{}, fmt.Errorf("%s: execute statement %w", op, err)
+	}
+
+	return block, nil
+}
+
+// GetOwner returns the owner of alias whatever its state: "" for links
+// created without an owner, storage.ErrUrlNotFound if there is no such
+// link or it is deleted.
+func (s *Storage) GetOwner(ctx context.Context, alias string) (string, error) {
+	const op = "storage.postgres.GetOwner"
+
+	var owner string
+	err := s.db.QueryRowContext(ctx, "SELECT owner FROM url WHERE alias = $1 AND deleted_at IS NULL", alias).Scan(&owner)
+	if errors.Is(err, sql.ErrNoRows) {
+		return "", storage.ErrUrlNotFound
+	}
+	if err != nil {
+		return "", fmt.Errorf("%s: %w", op, err)
+	}
+
+	return owner, nil
+}
+
+// AliasExists reports whether a link holds alias, deleted links that were
+// not purged yet included.
+func (s *Storage) AliasExists(ctx context.Context, alias string) (bool, error) {
+	const op = "storage.postgres.AliasExists"
+
+	var exists bool
+	err := s.db.QueryRowContext(ctx, "SELECT EXISTS (SELECT 1 FROM url WHERE alias = $1)", alias).Scan(&exists)
+	if err != nil {
+		return false, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return exists, nil
+}
+
+// ListURLs returns a page of the saved links that pass filter, ordered by
+// creation date. Only the conditions filter sets end up in the query, so
+// the planner can use the owner and created_at indexes.
+func (s *Storage) ListURLs(ctx context.Context, filter storage.ListFilter, limit int, offset int, desc bool) ([]storage.URL, error) {
+	const op = "storage.postgres.ListURLs"
+
+	order := "ASC"
+	if desc {
+		order = "DESC"
+	}
+
+	where, args := listWhere(filter)
+	args = append(args, limit, offset)
+
+	stmt, err := s.db.PrepareContext(ctx, fmt.Sprintf(`
+	SELECT alias, url, domain, created_at, expires_at, api_key_id, owner, title, description, image_url,
+		ARRAY(SELECT tag FROM url_tag WHERE url_id = url.id ORDER BY tag),
+		health, health_status_code, health_checked_at, campaign_id, state
+	FROM url
+	WHERE %[1]s
+	ORDER BY created_at %[2]s, id %[2]s LIMIT $%[3]d OFFSET $%[4]d`,
+		where, order, len(args)-1, len(args),
+	))
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	defer stmt.Close()
+
+	rows, err := stmt.QueryContext(ctx, args...)
+	if err != nil {
+		return nil, fmt.Errorf("%s: execute statement %w", op, err)
+	}
+	defer rows.Close()
+
+	urls := []storage.URL{}
+	for rows.Next() {
+		var (
+			u                    storage.URL
+			expiresAt, checkedAt sql.NullTime
+		)
+		if err := rows.Scan(
+			&u.Alias, &u.URL, &u.Domain, &u.CreatedAt, &expiresAt, &u.APIKeyID, &u.Owner,
+			&u.Metadata.Title, &u.Metadata.Description, &u.Metadata.Image, pq.Array(&u.Tags),
+			&u.Health.Status, &u.Health.StatusCode, &checkedAt, &u.CampaignID, &u.State,
+		); err != nil {
+			return nil, fmt.Errorf("%s: scan row %w", op, err)
+		}
+		u.ExpiresAt = expiresAt.Time
+		u.Health.CheckedAt = checkedAt.Time
+		if len(u.Tags) == 0 {
+			u.Tags = nil
+		}
+		urls = append(urls, u)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return urls, nil
+}
+
+// CountURLs counts the saved links that pass filter.
+func (s *Storage) CountURLs(ctx context.Context, filter storage.ListFilter) (int64, error) {
+	const op = "storage.postgres.CountURLs"
+
+	where, args := listWhere(filter)
+
+	var n int64
+	err := s.db.QueryRowContext(ctx, "SELECT COUNT(*) FROM url WHERE "+where, args...).Scan(&n)
+	if err != nil {
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return n, nil
+}
+
+// listWhere turns filter into the conditions of a query on url, joined
+// with AND, and their arguments.
+func listWhere(filter storage.ListFilter) (string, []any) {
+	where := []string{"deleted_at IS NULL"}
+	var args []any
+	arg := func(cond string, value any) {
+		args = append(args, value)
+		where = append(where, fmt.Sprintf(cond, len(args)))
+	}
+	if filter.Owner != "" {
+		arg("owner = $%d", filter.Owner)
+	}
+	if filter.APIKeyID != 0 {
+		arg("api_key_id = $%d", filter.APIKeyID)
+	}
+	if filter.Tag != "" {
+		arg("id IN (SELECT url_id FROM url_tag WHERE tag = $%d)", filter.Tag)
+	}
+	if filter.URLContains != "" {
+		arg(`url ILIKE $%d ESCAPE '\'`, likePattern(filter.URLContains))
+	}
+	if !filter.CreatedFrom.IsZero() {
+		arg("created_at >= $%d", filter.CreatedFrom.UTC())
+	}
+	if !filter.CreatedTo.IsZero() {
+		arg("created_at < $%d", filter.CreatedTo.UTC())
+	}
+	if filter.Health != "" {
+		arg("health = $%d", filter.Health)
+	}
+	if filter.CampaignID != 0 {
+		arg("campaign_id = $%d", filter.CampaignID)
+	}
+	if filter.Public {
+		now := time.Now().UTC()
+		where = append(where, "password_hash = ''")
+		arg("state = $%d", storage.StateActive)
+		arg("(expires_at IS NULL OR expires_at > $%d)", now)
+		arg("(active_from IS NULL OR active_from <= $%d)", now)
+		arg("(active_until IS NULL OR active_until > $%d)", now)
+	}
+
+	return strings.Join(where, " AND "), args
+}
+
+// likePattern matches s anywhere in a value; the wildcards % and _ in s
+// are taken literally.
+func likePattern(s string) string {
+	return "%" + strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`).Replace(s) + "%"
+}
+
+func (s *Storage) SaveClick(ctx context.Context, click storage.Click) error {
+	const op = "storage.postgres.SaveClick"
+
+	stmt, err := s.db.PrepareContext(ctx, "INSERT INTO clicks(alias, clicked_at, referrer, user_agent, variant, bot, country, city) VALUES($1, $2, $3, $4, $5, $6, $7, $8)")
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	defer stmt.Close()
+
+	_, err = stmt.ExecContext(ctx, click.Alias, click.ClickedAt, click.Referrer, click.UserAgent, click.Variant, click.Bot, click.Country, click.City)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	return nil
+}
+
+// GetStats returns click totals for alias and a per-day breakdown of the
+// clicks made since the given time. Clicks of bots are left out unless
+// includeBots is set.
+func (s *Storage) GetStats(ctx context.Context, alias string, since time.Time, includeBots bool) (storage.Stats, error) {
+	const op = "storage.postgres.GetStats"
+
+	var (
+		domain     sql.NullString
+		imported   sql.NullInt64
+		stats      storage.Stats
+		lastAccess sql.NullTime
+	)
+
+	rolled, raw := clickColumns(includeBots)
+
+	// Days before rolled_until are counted in click_rollups, later clicks
+	// one by one.
+	err := s.db.QueryRowContext(ctx, `
+	SELECT (SELECT domain FROM url WHERE alias = $1 AND deleted_at IS NULL),
+		(SELECT imported_clicks FROM url WHERE alias = $1 AND deleted_at IS NULL),
+		(SELECT COALESCE(SUM(`+rolled+`), 0) FROM click_rollups WHERE alias = $1)
+			+ (SELECT COUNT(*) FROM clicks WHERE alias = $1`+raw+` AND clicked_at >= (SELECT rolled_until FROM click_rollup_state)),
+		(SELECT MAX(clicked_at) FROM clicks WHERE alias = $1`+raw+`)`, alias,
+	).Scan(&domain, &imported, &stats.Total, &lastAccess)
+	if err != nil {
+		return storage.Stats{}, fmt.Errorf("%s: %w", op, err)
+	}
+	if !domain.Valid {
+		return storage.Stats{}, storage.ErrUrlNotFound
+	}
+	stats.Domain = domain.String
+	stats.Total += imported.Int64
+	stats.LastAccess = lastAccess.Time
+
+	rows, err := s.db.QueryContext(ctx, `
+	SELECT to_char(day, 'YYYY-MM-DD'), SUM(clicks) FROM (
+		SELECT day, `+rolled+` AS clicks FROM click_rollups WHERE alias = $1 AND day >= ($2::timestamptz AT TIME ZONE 'UTC')::date
+		UNION ALL
+		SELECT (clicked_at AT TIME ZONE 'UTC')::date, 1 FROM clicks
+		WHERE alias = $1`+raw+` AND clicked_at >= $2 AND clicked_at >= (SELECT rolled_until FROM click_rollup_state)
+	) AS c
+	GROUP BY day
+	HAVING SUM(clicks) > 0
+	ORDER BY day`, alias, since)
+	if err != nil {
+		return storage.Stats{}, fmt.Errorf("%s: %w", op, err)
+	}
+	defer rows.Close()
+
+	stats.Daily = []storage.DailyClicks{}
+	for rows.Next() {
+		var d storage.DailyClicks
+		if err := rows.Scan(&d.Date, &d.Clicks); err != nil {
+			return storage.Stats{}, fmt.Errorf("%s: scan row %w", op, err)
+		}
+		stats.Daily = append(stats.Daily, d)
+	}
+	if err := rows.Err(); err != nil {
+		return storage.Stats{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	variants, err := s.db.QueryContext(ctx, `
+	SELECT variant, SUM(clicks) FROM (
+		SELECT variant, `+rolled+` AS clicks FROM click_rollups WHERE alias = $1 AND variant != ''
+		UNION ALL
+		SELECT variant, 1 FROM clicks
+		WHERE alias = $1`+raw+` AND variant != '' AND clicked_at >= (SELECT rolled_until FROM click_rollup_state)
+	) AS c
+	GROUP BY variant
+	HAVING SUM(clicks) > 0
+	ORDER BY variant`, alias)
+	if err != nil {
+		return storage.Stats{}, fmt.Errorf("%s: %w", op, err)
+	}
+	defer variants.Close()
+
+	for variants.Next() {
+		var v storage.VariantClicks
+		if err := variants.Scan(&v.Variant, &v.Clicks); err != nil {
+			return storage.Stats{}, fmt.Errorf("%s: scan row %w", op, err)
+		}
+		stats.Variants = append(stats.Variants, v)
+	}
+	if err := variants.Err(); err != nil {
+		return storage.Stats{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return stats, nil
+}
+
+// GetReferrers counts the clicks on alias per referring domain, the most
+// clicked first. Clicks of bots are left out unless includeBots is set.
+func (s *Storage) GetReferrers(ctx context.Context, alias string, includeBots bool) ([]storage.ReferrerClicks, error) {
+	const op = "storage.postgres.GetReferrers"
+
+	var exists bool
+	err := s.db.QueryRowContext(ctx, "SELECT EXISTS(SELECT 1 FROM url WHERE alias = $1 AND deleted_at IS NULL)", alias).Scan(&exists)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	if !exists {
+		return nil, storage.ErrUrlNotFound
+	}
+
+	// Referers of one domain differ in path, so they are grouped by
+	// domain here rather than in SQL.
+	_, raw := clickColumns(includeBots)
+	rows, err := s.db.QueryContext(ctx, "SELECT referrer, COUNT(*) FROM clicks WHERE alias = $1"+raw+" GROUP BY referrer", alias)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	defer rows.Close()
+
+	byDomain := make(map[string]int64)
+	for rows.Next() {
+		var (
+			referrer string
+			clicks   int64
+		)
+		if err := rows.Scan(&referrer, &clicks); err != nil {
+			return nil, fmt.Errorf("%s: %w", op, err)
+		}
+		byDomain[storage.ReferrerDomain(referrer)] += clicks
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return storage.SortReferrers(byDomain), nil
+}
+
+// GetCountries counts the clicks on alias per country, the most clicked
+// first. Clicks of bots are left out unless includeBots is set.
+func (s *Storage) GetCountries(ctx context.Context, alias string, includeBots bool) ([]storage.CountryClicks, error) {
+	const op = "storage.postgres.GetCountries"
+
+	var exists bool
+	err := s.db.QueryRowContext(ctx, "SELECT EXISTS(SELECT 1 FROM url WHERE alias = $1 AND deleted_at IS NULL)", alias).Scan(&exists)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	if !exists {
+		return nil, storage.ErrUrlNotFound
+	}
+
+	_, raw := clickColumns(includeBots)
+	rows, err := s.db.QueryContext(ctx, "SELECT country, COUNT(*) FROM clicks WHERE alias = $1"+raw+" GROUP BY country ORDER BY COUNT(*) DESC, country", alias)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	defer rows.Close()
+
+	countries := []storage.CountryClicks{}
+	for rows.Next() {
+		var c storage.CountryClicks
+		if err := rows.Scan(&c.Country, &c.Clicks); err != nil {
+			return nil, fmt.Errorf("%s: %w", op, err)
+		}
+		countries = append(countries, c)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return countries, nil
+}
+
+// ListClicks returns up to limit clicks on alias with IDs above afterID
+// that pass filter, oldest first. Passing the ID of the last click back
+// as afterID reads the next page.
+func (s *Storage) ListClicks(ctx context.Context, alias string, filter storage.ClickFilter, afterID int64, limit int) ([]storage.Click, error) {
+	const op = "storage.postgres.ListClicks"
+
+	var exists bool
+	err := s.db.QueryRowContext(ctx, "SELECT EXISTS(SELECT 1 FROM url WHERE alias = $1 AND deleted_at IS NULL)", alias).Scan(&exists)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	if !exists {
+		return nil, storage.ErrUrlNotFound
+	}
+
+	var (
+		where []string
+		args  []any
+	)
+	arg := func(cond string, value any) {
+		args = append(args, value)
+		where = append(where, fmt.Sprintf(cond, len(args)))
+	}
+	arg("alias = $%d", alias)
+	arg("id > $%d", afterID)
+	if !filter.From.IsZero() {
+		arg("clicked_at >= $%d", filter.From)
+	}
+	if !filter.To.IsZero() {
+		arg("clicked_at < $%d", filter.To)
+	}
+	if !filter.IncludeBots {
+		where = append(where, "NOT bot")
+	}
+	args = append(args, limit)
+
+	rows, err := s.db.QueryContext(ctx, fmt.Sprintf(`
+	SELECT id, clicked_at, referrer, user_agent, variant, bot, country, city
+	FROM clicks
+	WHERE %s
+	ORDER BY id LIMIT $%d`, strings.Join(where, " AND "), len(args)), args...)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	defer rows.Close()
+
+	clicks := []storage.Click{}
+	for rows.Next() {
+		c := storage.Click{Alias: alias}
+		if err := rows.Scan(&c.ID, &c.ClickedAt, &c.Referrer, &c.UserAgent, &c.Variant, &c.Bot, &c.Country, &c.City); err != nil {
+			return nil, fmt.Errorf("%s: scan row %w", op, err)
+		}
+		clicks = append(clicks, c)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return clicks, nil
+}
+
+// clickColumns returns what stats sum in click_rollups and the condition
+// they add for clicks: all of them, or only those of people.
+func clickColumns(includeBots bool) (rolled string, raw string) {
+	if includeBots {
+		return "clicks", ""
+	}
+	return "clicks - bot_clicks", " AND NOT bot"
+}
+
+// RollupClicks sums up the clicks of the UTC days before the one before
+// falls on into click_rollups, so that GetStats does not count them one by
+// one. Days summed up earlier are skipped; the clicks themselves are kept.
+func (s *Storage) RollupClicks(ctx context.Context, before time.Time) error {
+	const op = "storage.postgres.RollupClicks"
+
+	until := before.UTC().Truncate(24 * time.Hour)
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	defer tx.Rollback()
+
+	// FOR UPDATE keeps two servers from summing up the same days.
+	var from time.Time
+	if err := tx.QueryRowContext(ctx, "SELECT rolled_until FROM click_rollup_state FOR UPDATE").Scan(&from); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	if !until.After(from) {
+		return nil
+	}
+
+	_, err = tx.ExecContext(ctx, `
+	INSERT INTO click_rollups(alias, day, variant, clicks, bot_clicks)
+	SELECT alias, (clicked_at AT TIME ZONE 'UTC')::date, variant, COUNT(*), COUNT(*) FILTER (WHERE bot)
+	FROM clicks
+	WHERE clicked_at >= $1 AND clicked_at < $2
+	GROUP BY 1, 2, 3
+	ON CONFLICT(alias, day, variant) DO UPDATE SET
+		clicks = click_rollups.clicks + excluded.clicks,
+		bot_clicks = click_rollups.bot_clicks + excluded.bot_clicks`,
+		from, until,
+	)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	if _, err := tx.ExecContext(ctx, "UPDATE click_rollup_state SET rolled_until = $1", until); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	return nil
+}
+
+// DeleteExpired removes links that expired before now and returns their
+// aliases.
+func (s *Storage) DeleteExpired(ctx context.Context, now time.Time) ([]string, error) {
+	const op = "storage.postgres.DeleteExpired"
+
+	rows, err := s.db.QueryContext(ctx, "DELETE FROM url WHERE expires_at IS NOT NULL AND expires_at <= $1 AND deleted_at IS NULL RETURNING alias", now)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	defer rows.Close()
+
+	var deleted []string
+	for rows.Next() {
+		var alias string
+		if err := rows.Scan(&alias); err != nil {
+			return nil, fmt.Errorf("%s: %w", op, err)
+		}
+		deleted = append(deleted, alias)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return deleted, nil
+}
+
+// PurgeDeleted removes links deleted before the given time, freeing their
+// aliases, and returns the aliases.
+func (s *Storage) PurgeDeleted(ctx context.Context, before time.Time) ([]string, error) {
+	const op = "storage.postgres.PurgeDeleted"
+
+	rows, err := s.db.QueryContext(ctx, "DELETE FROM url WHERE deleted_at IS NOT NULL AND deleted_at <= $1 RETURNING alias", before)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	defer rows.Close()
+
+	var purged []string
+	for rows.Next() {
+		var alias string
+		if err := rows.Scan(&alias); err != nil {
+			return nil, fmt.Errorf("%s: %w", op, err)
+		}
+		purged = append(purged, alias)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return purged, nil
+}
+
+func nullTime(t time.Time) sql.NullTime {
+	if t.IsZero() {
+		return sql.NullTime{}
+	}
+	return sql.NullTime{Time: t, Valid: true}
+}
+
+// execAffectingAlias runs a statement keyed by alias ($1) and reports
+// storage.ErrUrlNotFound when no row matched.
+func (s *Storage) execAffectingAlias(ctx context.Context, op string, query string, args ...any) error {
+	stmt, err := s.db.PrepareContext(ctx, query)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	defer stmt.Close()
+
+	res, err := stmt.ExecContext(ctx, args...)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	rowsAffected, err := res.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("%s: failed to get rows affected: %w", op, err)
+	}
+
+	if rowsAffected == 0 {
+		return fmt.Errorf("%s: %w", op, storage.ErrUrlNotFound)
+	}
+
+	return nil
+}
@@ -0,0 +1,154 @@
+package redis
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	goredis "github.com/redis/go-redis/v9"
+
+	"url-shortener/internal/storage"
+)
+
+// API keys are hashes under apikey:{id}; apikeys:by_hash maps key hashes to
+// ids and apikeys is a sorted set of ids used for listing.
+const (
+	apiKeyPrefix     = "apikey:"
+	apiKeysIDKey     = "apikeys:id"
+	apiKeysKey       = "apikeys"
+	apiKeysByHashKey = "apikeys:by_hash"
+)
+
+func apiKeyKey(id int64) string {
+	return apiKeyPrefix + strconv.FormatInt(id, 10)
+}
+
+func (s *Storage) SaveAPIKey(ctx context.Context, key storage.APIKey) (int64, error) {
+	const op = "storage.redis.SaveAPIKey"
+
+	id, err := s.client.Incr(ctx, apiKeysIDKey).Result()
+	if err != nil {
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+
+	ok, err := s.client.HSetNX(ctx, apiKeysByHashKey, key.Hash, id).Result()
+	if err != nil {
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+	if !ok {
+		return 0, fmt.Errorf("%s: duplicate key hash", op)
+	}
+
+	_, err = s.client.TxPipelined(ctx, func(pipe goredis.Pipeliner) error {
+		pipe.HSet(ctx, apiKeyKey(id),
+			"name", key.Name,
+			"owner", key.Owner,
+			"hash", key.Hash,
+			"created_at", time.Now().UTC().Format(time.RFC3339Nano),
+		)
+		pipe.ZAdd(ctx, apiKeysKey, goredis.Z{Score: float64(id), Member: id})
+		return nil
+	})
+	if err != nil {
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return id, nil
+}
+
+// GetAPIKeyByHash looks a key up by the hash of its value. Revoked keys are
+// returned as well; callers check APIKey.Revoked.
+func (s *Storage) GetAPIKeyByHash(ctx context.Context, hash string) (storage.APIKey, error) {
+	const op = "storage.redis.GetAPIKeyByHash"
+
+	id, err := s.client.HGet(ctx, apiKeysByHashKey, hash).Int64()
+	if errors.Is(err, goredis.Nil) {
+		return storage.APIKey{}, storage.ErrKeyNotFound
+	}
+	if err != nil {
+		return storage.APIKey{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	key, err := s.getAPIKey(ctx, id)
+	if err != nil {
+		return storage.APIKey{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return key, nil
+}
+
+// ListAPIKeys returns keys ordered by id. A non-empty owner limits the
+// result to that user's keys.
+func (s *Storage) ListAPIKeys(ctx context.Context, owner string) ([]storage.APIKey, error) {
+	const op = "storage.redis.ListAPIKeys"
+
+	ids, err := s.client.ZRange(ctx, apiKeysKey, 0, -1).Result()
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	keys := []storage.APIKey{}
+	for _, v := range ids {
+		id, err := strconv.ParseInt(v, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", op, err)
+		}
+
+		key, err := s.getAPIKey(ctx, id)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", op, err)
+		}
+		if owner != "" && key.Owner != owner {
+			continue
+		}
+		keys = append(keys, key)
+	}
+
+	return keys, nil
+}
+
+// RevokeAPIKey marks a key as revoked. Revoking a revoked key keeps its
+// original revocation time. A non-empty owner restricts revocation to that
+// user's keys.
+func (s *Storage) RevokeAPIKey(ctx context.Context, id int64, owner string) error {
+	const op = "storage.redis.RevokeAPIKey"
+
+	key, err := s.getAPIKey(ctx, id)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	if owner != "" && key.Owner != owner {
+		return fmt.Errorf("%s: %w", op, storage.ErrKeyNotFound)
+	}
+
+	err = s.client.HSetNX(ctx, apiKeyKey(id), "revoked_at", time.Now().UTC().Format(time.RFC3339Nano)).Err()
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	return nil
+}
+
+func (s *Storage) getAPIKey(ctx context.Context, id int64) (storage.APIKey, error) {
+	vals, err := s.client.HMGet(ctx, apiKeyKey(id), "name", "owner", "hash", "created_at", "revoked_at").Result()
+	if err != nil {
+		return storage.APIKey{}, err
+	}
+
+	if _, ok := vals[2].(string); !ok {
+		return storage.APIKey{}, storage.ErrKeyNotFound
+	}
+
+	key := storage.APIKey{
+		ID:        id,
+		CreatedAt: parseTime(vals[3]),
+		RevokedAt: parseTime(vals[4]),
+	}
+	key.Name, _ = vals[0].(string)
+	key.Owner, _ = vals[1].(string)
+	key.Hash, _ = vals[2].(string)
+
+	return key, nil
+}
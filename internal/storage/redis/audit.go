@@ -0,0 +1,76 @@
+package redis
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"url-shortener/internal/storage"
+)
+
+// The audit log is a list of JSON entries under audit_log, newest first;
+// audit_log:id numbers them.
+const (
+	auditLogKey   = "audit_log"
+	auditLogIDKey = "audit_log:id"
+)
+
+func (s *Storage) SaveAuditEntry(ctx context.Context, e storage.AuditEntry) error {
+	const op = "storage.redis.SaveAuditEntry"
+
+	id, err := s.client.Incr(ctx, auditLogIDKey).Result()
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	e.ID = id
+	e.Time = e.Time.UTC()
+
+	data, err := json.Marshal(e)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	if err := s.client.LPush(ctx, auditLogKey, data).Err(); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	return nil
+}
+
+// ListAuditLog returns entries newest first. Filtered lists are read in
+// batches until the page is full.
+func (s *Storage) ListAuditLog(ctx context.Context, filter storage.AuditFilter, limit int, offset int) ([]storage.AuditEntry, error) {
+	const op = "storage.redis.ListAuditLog"
+
+	entries := []storage.AuditEntry{}
+	for start := int64(0); len(entries) < limit; start += listScanBatch {
+		batch, err := s.client.LRange(ctx, auditLogKey, start, start+listScanBatch-1).Result()
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", op, err)
+		}
+
+		for _, data := range batch {
+			var e storage.AuditEntry
+			if err := json.Unmarshal([]byte(data), &e); err != nil {
+				return nil, fmt.Errorf("%s: %w", op, err)
+			}
+			if !filter.Match(e) {
+				continue
+			}
+			if offset > 0 {
+				offset--
+				continue
+			}
+			if len(entries) == limit {
+				break
+			}
+			entries = append(entries, e)
+		}
+
+		if len(batch) < listScanBatch {
+			break
+		}
+	}
+
+	return entries, nil
+}
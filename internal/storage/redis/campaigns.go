@@ -0,0 +1,233 @@
+package redis
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	goredis "github.com/redis/go-redis/v9"
+
+	"url-shortener/internal/storage"
+)
+
+// Campaigns are hashes under campaign:{id}; campaigns:id hands out ids and
+// campaigns is a sorted set of ids used for listing. Links name their
+// campaign in the campaign_id field of their hash.
+const (
+	campaignPrefix = "campaign:"
+	campaignsIDKey = "campaigns:id"
+	campaignsKey   = "campaigns"
+)
+
+func campaignKey(id int64) string {
+	return campaignPrefix + strconv.FormatInt(id, 10)
+}
+
+func (s *Storage) SaveCampaign(ctx context.Context, c storage.Campaign) (int64, error) {
+	const op = "storage.redis.SaveCampaign"
+
+	id, err := s.client.Incr(ctx, campaignsIDKey).Result()
+	if err != nil {
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+
+	_, err = s.client.TxPipelined(ctx, func(pipe goredis.Pipeliner) error {
+		pipe.HSet(ctx, campaignKey(id), campaignFields(c)...)
+		pipe.HSet(ctx, campaignKey(id),
+			"owner", c.Owner,
+			"created_at", time.Now().UTC().Format(time.RFC3339Nano),
+		)
+		pipe.ZAdd(ctx, campaignsKey, goredis.Z{Score: float64(id), Member: id})
+		return nil
+	})
+	if err != nil {
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return id, nil
+}
+
+// GetCampaign returns storage.ErrCampaignNotFound for unknown campaigns and,
+// with a non-empty owner, for those of other users.
+func (s *Storage) GetCampaign(ctx context.Context, id int64, owner string) (storage.Campaign, error) {
+	const op = "storage.redis.GetCampaign"
+
+	c, err := s.getCampaign(ctx, id)
+	if err != nil {
+		return storage.Campaign{}, fmt.Errorf("%s: %w", op, err)
+	}
+	if owner != "" && c.Owner != owner {
+		return storage.Campaign{}, fmt.Errorf("%s: %w", op, storage.ErrCampaignNotFound)
+	}
+
+	return c, nil
+}
+
+// ListCampaigns returns campaigns ordered by id. A non-empty owner limits
+// the result to that user's campaigns.
+func (s *Storage) ListCampaigns(ctx context.Context, owner string) ([]storage.Campaign, error) {
+	const op = "storage.redis.ListCampaigns"
+
+	ids, err := s.client.ZRange(ctx, campaignsKey, 0, -1).Result()
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	campaigns := []storage.Campaign{}
+	for _, v := range ids {
+		id, err := strconv.ParseInt(v, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", op, err)
+		}
+
+		c, err := s.getCampaign(ctx, id)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", op, err)
+		}
+		if owner != "" && c.Owner != owner {
+			continue
+		}
+		campaigns = append(campaigns, c)
+	}
+
+	return campaigns, nil
+}
+
+// UpdateCampaign replaces the name and the settings of campaign c.ID; its
+// owner and creation time are kept. A non-empty owner restricts the update
+// to that user's campaigns.
+func (s *Storage) UpdateCampaign(ctx context.Context, c storage.Campaign, owner string) error {
+	const op = "storage.redis.UpdateCampaign"
+
+	if _, err := s.GetCampaign(ctx, c.ID, owner); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	_, err := s.client.TxPipelined(ctx, func(pipe goredis.Pipeliner) error {
+		pipe.HDel(ctx, campaignKey(c.ID), "expires_at")
+		pipe.HSet(ctx, campaignKey(c.ID), campaignFields(c)...)
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	return nil
+}
+
+// DeleteCampaign removes a campaign. Its links are kept and no longer
+// belong to any campaign. A non-empty owner restricts deletion to that
+// user's campaigns.
+func (s *Storage) DeleteCampaign(ctx context.Context, id int64, owner string) error {
+	const op = "storage.redis.DeleteCampaign"
+
+	if _, err := s.GetCampaign(ctx, id, owner); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	if err := s.removeCampaign(ctx, id); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	return nil
+}
+
+// removeCampaign deletes campaign id and takes its links out of it. Links
+// are not indexed by campaign, so this walks all of them, deleted ones
+// included, page by page.
+func (s *Storage) removeCampaign(ctx context.Context, id int64) error {
+	_, err := s.client.TxPipelined(ctx, func(pipe goredis.Pipeliner) error {
+		pipe.Del(ctx, campaignKey(id))
+		pipe.ZRem(ctx, campaignsKey, id)
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	const pageSize = 500
+
+	campaignID := strconv.FormatInt(id, 10)
+	for _, index := range []string{createdKey, deletedKey} {
+		for start := int64(0); ; start += pageSize {
+			aliases, err := s.client.ZRange(ctx, index, start, start+pageSize-1).Result()
+			if err != nil {
+				return err
+			}
+
+			cmds := make([]*goredis.StringCmd, len(aliases))
+			_, err = s.client.Pipelined(ctx, func(pipe goredis.Pipeliner) error {
+				for i, alias := range aliases {
+					cmds[i] = pipe.HGet(ctx, urlKey(alias), "campaign_id")
+				}
+				return nil
+			})
+			if err != nil && !errors.Is(err, goredis.Nil) {
+				return err
+			}
+
+			_, err = s.client.Pipelined(ctx, func(pipe goredis.Pipeliner) error {
+				for i, alias := range aliases {
+					if cmds[i].Val() == campaignID {
+						pipe.HDel(ctx, urlKey(alias), "campaign_id")
+					}
+				}
+				return nil
+			})
+			if err != nil {
+				return err
+			}
+
+			if len(aliases) < pageSize {
+				break
+			}
+		}
+	}
+
+	return nil
+}
+
+// campaignFields are the hash fields of the name and the settings of c.
+func campaignFields(c storage.Campaign) []any {
+	fields := []any{
+		"name", c.Name,
+		"utm_source", c.UTM.Source,
+		"utm_medium", c.UTM.Medium,
+		"utm_campaign", c.UTM.Campaign,
+		"domain", c.Domain,
+	}
+	if !c.ExpiresAt.IsZero() {
+		fields = append(fields, "expires_at", c.ExpiresAt.UTC().Format(time.RFC3339Nano))
+	}
+
+	return fields
+}
+
+func (s *Storage) getCampaign(ctx context.Context, id int64) (storage.Campaign, error) {
+	vals, err := s.client.HMGet(ctx, campaignKey(id),
+		"name", "owner", "utm_source", "utm_medium", "utm_campaign", "expires_at", "domain", "created_at",
+	).Result()
+	if err != nil {
+		return storage.Campaign{}, err
+	}
+
+	if _, ok := vals[0].(string); !ok {
+		return storage.Campaign{}, storage.ErrCampaignNotFound
+	}
+
+	c := storage.Campaign{
+		ID:        id,
+		ExpiresAt: parseTime(vals[5]),
+		CreatedAt: parseTime(vals[7]),
+	}
+	c.Name, _ = vals[0].(string)
+	c.Owner, _ = vals[1].(string)
+	c.UTM.Source, _ = vals[2].(string)
+	c.UTM.Medium, _ = vals[3].(string)
+	c.UTM.Campaign, _ = vals[4].(string)
+	c.Domain, _ = vals[6].(string)
+
+	return c, nil
+}
@@ -0,0 +1,93 @@
+package redis
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	goredis "github.com/redis/go-redis/v9"
+
+	"url-shortener/internal/storage"
+)
+
+// Idempotency records are JSON under idempotency:{key} and expire with
+// the record.
+const idempotencyKeyPrefix = "idempotency:"
+
+// ReserveIdempotencyKey saves rec unless a record with its key exists, in
+// which case it returns that record and storage.ErrIdempotencyKeyExists.
+func (s *Storage) ReserveIdempotencyKey(ctx context.Context, rec storage.IdempotencyRecord) (storage.IdempotencyRecord, error) {
+	const op = "storage.redis.ReserveIdempotencyKey"
+
+	data, err := json.Marshal(rec)
+	if err != nil {
+		return storage.IdempotencyRecord{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	key := idempotencyKeyPrefix + rec.Key
+	err = s.client.SetArgs(ctx, key, data, goredis.SetArgs{Mode: "NX", ExpireAt: rec.ExpiresAt}).Err()
+	if err == nil {
+		return rec, nil
+	}
+	if !errors.Is(err, goredis.Nil) {
+		return storage.IdempotencyRecord{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	var existing storage.IdempotencyRecord
+	data, err = s.client.Get(ctx, key).Bytes()
+	if errors.Is(err, goredis.Nil) {
+		// Released or expired since the SET.
+		return storage.IdempotencyRecord{}, fmt.Errorf("%s: key released concurrently: %w", op, storage.ErrIdempotencyKeyExists)
+	}
+	if err == nil {
+		err = json.Unmarshal(data, &existing)
+	}
+	if err != nil {
+		return storage.IdempotencyRecord{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return existing, fmt.Errorf("%s: %w", op, storage.ErrIdempotencyKeyExists)
+}
+
+// CompleteIdempotencyKey stores the response to the request that reserved
+// key.
+func (s *Storage) CompleteIdempotencyKey(ctx context.Context, key string, status int, contentType string, body string) error {
+	const op = "storage.redis.CompleteIdempotencyKey"
+
+	data, err := s.client.Get(ctx, idempotencyKeyPrefix+key).Bytes()
+	if errors.Is(err, goredis.Nil) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	var rec storage.IdempotencyRecord
+	if err := json.Unmarshal(data, &rec); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	rec.Status, rec.ContentType, rec.Body = status, contentType, body
+
+	if data, err = json.Marshal(rec); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	err = s.client.SetArgs(ctx, idempotencyKeyPrefix+key, data, goredis.SetArgs{Mode: "XX", KeepTTL: true}).Err()
+	if err != nil && !errors.Is(err, goredis.Nil) {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	return nil
+}
+
+// ReleaseIdempotencyKey removes key, so that the request can be retried.
+func (s *Storage) ReleaseIdempotencyKey(ctx context.Context, key string) error {
+	const op = "storage.redis.ReleaseIdempotencyKey"
+
+	if err := s.client.Del(ctx, idempotencyKeyPrefix+key).Err(); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	return nil
+}
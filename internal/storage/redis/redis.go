@@ -0,0 +1,1443 @@
+package redis
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"maps"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	goredis "github.com/redis/go-redis/v9"
+
+	"url-shortener/internal/storage"
+)
+
+const (
+	urlKeyPrefix    = "url:"
+	idKey           = "urls:id"
+	createdKey      = "urls:created"
+	expiresKey      = "urls:expires"
+	deletedKey      = "urls:deleted"
+	ownerKeyPrefix  = "urls:owner:"
+	clicksKeyPrefix = "clicks:"
+
+	// variantField prefixes the per-variant click counters in the click
+	// summary hash.
+	variantField = "variant:"
+	// botField prefixes the counters of bot clicks, which are also counted
+	// in the counters without it, in the click hashes: "bot:total",
+	// "bot:variant:A", "bot:2025-03-01", "bot:reddit.com" and "bot:DE".
+	botField = "bot:"
+
+	// clickLogMaxLen caps the raw click stream kept per alias.
+	clickLogMaxLen = 10000
+)
+
+// Links are stored as hashes under url:{alias}; urls:created is a sorted
+// set of aliases scored by creation time (unix ms) used for listing, and
+// urls:expires indexes links with an expiry time for the reaper.
+// urls:owner:{owner} is the same index as urls:created restricted to the
+// links of a single user. Deleted links keep their hash, with deleted_at
+// set, but leave those indexes for urls:deleted, scored by deletion time.
+//
+// Clicks are kept under clicks:{alias} (total and last access),
+// clicks:{alias}:daily (clicks per UTC day) and clicks:{alias}:log
+// (a capped stream of raw clicks).
+var (
+	saveScript = goredis.NewScript(`
+if redis.call('EXISTS', KEYS[1]) == 1 then
+	return 0
+end
+redis.call('HSET', KEYS[1], unpack(ARGV, 5))
+if tonumber(ARGV[3]) > 0 then
+	redis.call('PEXPIRE', KEYS[1], ARGV[3])
+end
+redis.call('ZADD', KEYS[2], ARGV[2], ARGV[1])
+if ARGV[4] ~= '' then
+	redis.call('ZADD', KEYS[3], ARGV[4], ARGV[1])
+end
+if #KEYS == 4 then
+	redis.call('ZADD', KEYS[4], ARGV[2], ARGV[1])
+end
+return 1
+`)
+
+	// setIfExistsScript takes the required owner ('' for any) as ARGV[1],
+	// '1' in ARGV[2] to leave deleted links alone, followed by field/value
+	// pairs.
+	setIfExistsScript = goredis.NewScript(`
+if redis.call('EXISTS', KEYS[1]) == 0 then
+	return 0
+end
+if ARGV[1] ~= '' and redis.call('HGET', KEYS[1], 'owner') ~= ARGV[1] then
+	return 0
+end
+if ARGV[2] == '1' and redis.call('HEXISTS', KEYS[1], 'deleted_at') == 1 then
+	return 0
+end
+redis.call('HSET', KEYS[1], unpack(ARGV, 3))
+return 1
+`)
+
+	// deleteScript marks a live link as deleted and moves it from the
+	// listing indexes (KEYS[2], KEYS[3] and the optional owner index
+	// KEYS[5]) to urls:deleted (KEYS[4]). ARGV holds the alias, the deletion
+	// time in unix ms and as RFC 3339. It returns 1 if the link was deleted.
+	deleteScript = goredis.NewScript(`
+if redis.call('EXISTS', KEYS[1]) == 0 or redis.call('HEXISTS', KEYS[1], 'deleted_at') == 1 then
+	return 0
+end
+redis.call('HSET', KEYS[1], 'deleted_at', ARGV[3])
+redis.call('ZREM', KEYS[2], ARGV[1])
+redis.call('ZREM', KEYS[3], ARGV[1])
+redis.call('ZADD', KEYS[4], ARGV[2], ARGV[1])
+if #KEYS == 5 then
+	redis.call('ZREM', KEYS[5], ARGV[1])
+end
+return 1
+`)
+
+	// restoreScript undoes deleteScript for the same keys. ARGV holds the
+	// alias, its creation score and its expiry score ('' for none). It
+	// returns 1 if the link was restored.
+	restoreScript = goredis.NewScript(`
+if redis.call('HEXISTS', KEYS[1], 'deleted_at') == 0 then
+	return 0
+end
+redis.call('HDEL', KEYS[1], 'deleted_at')
+redis.call('ZADD', KEYS[2], ARGV[2], ARGV[1])
+if ARGV[3] ~= '' then
+	redis.call('ZADD', KEYS[3], ARGV[3], ARGV[1])
+end
+redis.call('ZREM', KEYS[4], ARGV[1])
+if #KEYS == 5 then
+	redis.call('ZADD', KEYS[5], ARGV[2], ARGV[1])
+end
+return 1
+`)
+
+	// renameScript moves a live link from KEYS[1] to KEYS[2] together with
+	// its click keys (KEYS[3..7] to KEYS[8..12]) and its entries in the
+	// indexes urls:created, urls:expires and the optional owner index
+	// (KEYS[13..15]). ARGV holds the old alias, the new alias and the
+	// required owner ('' for any), followed, when a forward is left at the
+	// old alias, by saveScript's arguments for it. It returns 1 on success,
+	// 0 if the link is missing and -1 if the new alias is taken.
+	renameScript = goredis.NewScript(`
+if redis.call('EXISTS', KEYS[1]) == 0 or redis.call('HEXISTS', KEYS[1], 'deleted_at') == 1 then
+	return 0
+end
+if ARGV[3] ~= '' and redis.call('HGET', KEYS[1], 'owner') ~= ARGV[3] then
+	return 0
+end
+if redis.call('EXISTS', KEYS[2]) == 1 then
+	return -1
+end
+redis.call('RENAME', KEYS[1], KEYS[2])
+for i = 3, 7 do
+	if redis.call('EXISTS', KEYS[i]) == 1 then
+		redis.call('RENAME', KEYS[i], KEYS[i + 5])
+	end
+end
+for i = 13, #KEYS do
+	local score = redis.call('ZSCORE', KEYS[i], ARGV[1])
+	if score then
+		redis.call('ZREM', KEYS[i], ARGV[1])
+		redis.call('ZADD', KEYS[i], score, ARGV[2])
+	end
+end
+if #ARGV > 3 then
+	redis.call('HSET', KEYS[1], unpack(ARGV, 8))
+	if tonumber(ARGV[6]) > 0 then
+		redis.call('PEXPIRE', KEYS[1], ARGV[6])
+	end
+	redis.call('ZADD', KEYS[13], ARGV[5], ARGV[4])
+	redis.call('ZADD', KEYS[14], ARGV[7], ARGV[4])
+	if #KEYS == 15 then
+		redis.call('ZADD', KEYS[15], ARGV[5], ARGV[4])
+	end
+end
+return 1
+`)
+
+	// flagScript marks a link as unsafe unless it is missing, deleted or
+	// already blocked; it returns 1 if the link was flagged.
+	flagScript = goredis.NewScript(`
+if redis.call('HGET', KEYS[1], 'state') ~= ARGV[1] or redis.call('HEXISTS', KEYS[1], 'deleted_at') == 1 then
+	return 0
+end
+redis.call('HSET', KEYS[1], 'state', ARGV[2], 'block_reason', ARGV[3])
+return 1
+`)
+
+	// consumeClickScript returns -1 for a missing alias, 0 when max_clicks
+	// is used up and 1 when the click was counted.
+	consumeClickScript = goredis.NewScript(`
+if redis.call('EXISTS', KEYS[1]) == 0 or redis.call('HEXISTS', KEYS[1], 'deleted_at') == 1 then
+	return -1
+end
+local max = tonumber(redis.call('HGET', KEYS[1], 'max_clicks') or '0')
+local count = tonumber(redis.call('HGET', KEYS[1], 'click_count') or '0')
+if max > 0 and count >= max then
+	return 0
+end
+redis.call('HINCRBY', KEYS[1], 'click_count', 1)
+return 1
+`)
+)
+
+type Storage struct {
+	client *goredis.Client
+	ttl    time.Duration
+}
+
+type Options struct {
+	Addr     string
+	Password string
+	DB       int
+	PoolSize int
+	// TTL, if positive, is set on every saved alias.
+	TTL time.Duration
+}
+
+func New(opts Options) (*Storage, error) {
+	const op = "storage.redis.New"
+
+	client := goredis.NewClient(&goredis.Options{
+		Addr:     opts.Addr,
+		Password: opts.Password,
+		DB:       opts.DB,
+		PoolSize: opts.PoolSize,
+	})
+
+	if err := client.Ping(context.Background()).Err(); err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return &Storage{client: client, ttl: opts.TTL}, nil
+}
+
+// Ping checks that the Redis server is reachable.
+func (s *Storage) Ping(ctx context.Context) error {
+	const op = "storage.redis.Ping"
+
+	if err := s.client.Ping(ctx).Err(); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	return nil
+}
+
+// Close closes the connection pool.
+func (s *Storage) Close() error {
+	const op = "storage.redis.Close"
+
+	if err := s.client.Close(); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	return nil
+}
+
+func urlKey(alias string) string {
+	return urlKeyPrefix + alias
+}
+
+func ownerKey(owner string) string {
+	return ownerKeyPrefix + owner
+}
+
+func clickKeys(alias string) (summary, daily, referrers, countries, log string) {
+	summary = clicksKeyPrefix + alias
+	return summary, summary + ":daily", summary + ":referrers", summary + ":countries", summary + ":log"
+}
+
+func (s *Storage) SaveURL(ctx context.Context, u storage.URL) (int64, error) {
+	const op = "storage.redis.SaveURL"
+
+	id, err := s.client.Incr(ctx, idKey).Result()
+	if err != nil {
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+
+	keys, args := s.saveArgs(u, id, time.Now().UTC())
+
+	saved, err := saveScript.Run(ctx, s.client, keys, args...).Int()
+	if err != nil {
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+
+	if saved == 0 {
+		return 0, fmt.Errorf("%s: %w", op, storage.ErrUrlExists)
+	}
+
+	return id, nil
+}
+
+// NextURLID takes the next value of the counter link ids come from.
+func (s *Storage) NextURLID(ctx context.Context) (int64, error) {
+	const op = "storage.redis.NextURLID"
+
+	id, err := s.client.Incr(ctx, idKey).Result()
+	if err != nil {
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return id, nil
+}
+
+// SaveURLs saves urls in a single MULTI/EXEC. A taken alias does not abort
+// the batch: storage.ErrUrlExists is reported at its index in the returned
+// slice.
+func (s *Storage) SaveURLs(ctx context.Context, urls []storage.URL) ([]error, error) {
+	const op = "storage.redis.SaveURLs"
+
+	if len(urls) == 0 {
+		return nil, nil
+	}
+
+	lastID, err := s.client.IncrBy(ctx, idKey, int64(len(urls))).Result()
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	now := time.Now().UTC()
+	cmds := make([]*goredis.Cmd, len(urls))
+	_, err = s.client.TxPipelined(ctx, func(pipe goredis.Pipeliner) error {
+		for i, u := range urls {
+			keys, args := s.saveArgs(u, lastID-int64(len(urls)-1-i), now)
+			cmds[i] = saveScript.Eval(ctx, pipe, keys, args...)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	errs := make([]error, len(urls))
+	for i, cmd := range cmds {
+		saved, err := cmd.Int()
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", op, err)
+		}
+		if saved == 0 {
+			errs[i] = fmt.Errorf("%s: %w", op, storage.ErrUrlExists)
+		}
+	}
+
+	return errs, nil
+}
+
+// saveArgs returns the keys and arguments of saveScript for u.
+func (s *Storage) saveArgs(u storage.URL, id int64, now time.Time) ([]string, []any) {
+	expiresScore := ""
+	fields := []any{
+		"id", id,
+		"url", u.URL,
+		"state", storage.StateActive,
+		"created_at", now.Format(time.RFC3339Nano),
+	}
+	if !u.ExpiresAt.IsZero() {
+		expiresScore = strconv.FormatInt(u.ExpiresAt.UnixMilli(), 10)
+		fields = append(fields, "expires_at", u.ExpiresAt.UTC().Format(time.RFC3339Nano))
+	}
+	if !u.ActiveFrom.IsZero() {
+		fields = append(fields, "active_from", u.ActiveFrom.UTC().Format(time.RFC3339Nano))
+	}
+	if !u.ActiveUntil.IsZero() {
+		fields = append(fields, "active_until", u.ActiveUntil.UTC().Format(time.RFC3339Nano))
+	}
+	if u.Domain != "" {
+		fields = append(fields, "domain", u.Domain)
+	}
+	if u.MaxClicks > 0 {
+		fields = append(fields, "max_clicks", u.MaxClicks)
+	}
+	if u.ImportedClicks > 0 {
+		fields = append(fields, "imported_clicks", u.ImportedClicks)
+	}
+	if u.PasswordHash != "" {
+		fields = append(fields, "password_hash", u.PasswordHash)
+	}
+	if u.APIKeyID != 0 {
+		fields = append(fields, "api_key_id", u.APIKeyID)
+	}
+	if u.RedirectType != 0 {
+		fields = append(fields, "redirect_type", u.RedirectType)
+	}
+	if u.CacheMaxAge != 0 {
+		fields = append(fields, "cache_max_age", u.CacheMaxAge)
+	}
+	if u.UTM != (storage.UTM{}) {
+		fields = append(fields,
+			"utm_source", u.UTM.Source,
+			"utm_medium", u.UTM.Medium,
+			"utm_campaign", u.UTM.Campaign,
+		)
+	}
+	if len(u.GeoTargets) > 0 {
+		geoTargets, _ := u.GeoTargets.Value()
+		fields = append(fields, "geo_targets", geoTargets)
+	}
+	if len(u.DeviceTargets) > 0 {
+		deviceTargets, _ := u.DeviceTargets.Value()
+		fields = append(fields, "device_targets", deviceTargets)
+	}
+	if len(u.Variants) > 0 {
+		variants, _ := u.Variants.Value()
+		fields = append(fields, "variants", variants)
+	}
+	if len(u.Tags) > 0 {
+		// Tags cannot contain commas.
+		fields = append(fields, "tags", strings.Join(slices.Sorted(slices.Values(u.Tags)), ","))
+	}
+	if u.Metadata != (storage.Metadata{}) {
+		fields = append(fields,
+			"title", u.Metadata.Title,
+			"description", u.Metadata.Description,
+			"image_url", u.Metadata.Image,
+		)
+	}
+	if u.OpenGraph != (storage.Metadata{}) {
+		fields = append(fields,
+			"og_title", u.OpenGraph.Title,
+			"og_description", u.OpenGraph.Description,
+			"og_image", u.OpenGraph.Image,
+		)
+	}
+	if u.CampaignID != 0 {
+		fields = append(fields, "campaign_id", u.CampaignID)
+	}
+
+	keys := []string{urlKey(u.Alias), createdKey, expiresKey}
+	if u.Owner != "" {
+		fields = append(fields, "owner", u.Owner)
+		keys = append(keys, ownerKey(u.Owner))
+	}
+
+	return keys, append([]any{u.Alias, now.UnixMilli(), s.ttl.Milliseconds(), expiresScore}, fields...)
+}
+
+func (s *Storage) GetURL(ctx context.Context, alias string) (storage.URL, error) {
+	const op = "storage.redis.GetURL"
+
+	vals, err := s.client.HMGet(ctx, urlKey(alias),
+		"url", "state", "created_at", "expires_at", "max_clicks", "click_count", "password_hash", "redirect_type", "deleted_at",
+		"utm_source", "utm_medium", "utm_campaign", "geo_targets", "device_targets", "variants",
+		"active_from", "active_until", "domain", "cache_max_age", "og_title", "og_description", "og_image",
+		"campaign_id",
+	).Result()
+	if err != nil {
+		return storage.URL{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	resURL, ok := vals[0].(string)
+	if !ok || vals[8] != nil {
+		return storage.URL{}, storage.ErrUrlNotFound
+	}
+
+	switch state, _ := vals[1].(string); state {
+	case storage.StateBlockedLegal:
+		return storage.URL{}, storage.ErrUrlBlocked
+	case storage.StateBlockedUnsafe:
+		return storage.URL{}, storage.ErrUrlUnsafe
+	}
+
+	res := storage.URL{
+		Alias:     alias,
+		URL:       resURL,
+		CreatedAt: parseTime(vals[2]),
+		ExpiresAt: parseTime(vals[3]),
+		MaxClicks: parseInt(vals[4]),
+	}
+	res.PasswordHash, _ = vals[6].(string)
+	res.RedirectType = int(parseInt(vals[7]))
+	res.ActiveFrom = parseTime(vals[15])
+	res.ActiveUntil = parseTime(vals[16])
+	res.Domain, _ = vals[17].(string)
+	res.CacheMaxAge = int(parseInt(vals[18]))
+	res.OpenGraph.Title, _ = vals[19].(string)
+	res.OpenGraph.Description, _ = vals[20].(string)
+	res.OpenGraph.Image, _ = vals[21].(string)
+	res.CampaignID = parseInt(vals[22])
+	res.UTM.Source, _ = vals[9].(string)
+	res.UTM.Medium, _ = vals[10].(string)
+	res.UTM.Campaign, _ = vals[11].(string)
+	if err := res.GeoTargets.Scan(vals[12]); err != nil {
+		return storage.URL{}, fmt.Errorf("%s: %w", op, err)
+	}
+	if err := res.DeviceTargets.Scan(vals[13]); err != nil {
+		return storage.URL{}, fmt.Errorf("%s: %w", op, err)
+	}
+	if err := res.Variants.Scan(vals[14]); err != nil {
+		return storage.URL{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	if res.Expired(time.Now()) {
+		return storage.URL{}, storage.ErrUrlExpired
+	}
+
+	if res.MaxClicks > 0 && parseInt(vals[5]) >= res.MaxClicks {
+		return storage.URL{}, storage.ErrUrlExhausted
+	}
+
+	return res, nil
+}
+
+// GetAliasByURL returns the alias of the oldest plain link of owner
+// pointing to url: active, unexpired, without a password, a click limit,
+// a custom redirect type or cache lifetime, UTM parameters, geo or device
+// targets, A/B variants, an activation window, a short domain or an Open
+// Graph preview of its own, and not in a campaign.
+// Unlike elsewhere, owner "" matches only links without an owner. It
+// returns storage.ErrUrlNotFound if there is no such link.
+//
+// Links are not indexed by URL, so this walks the owner's links page by
+// page; it is meant for an opt-in request flag, not for every save.
+func (s *Storage) GetAliasByURL(ctx context.Context, url string, owner string) (string, error) {
+	const op = "storage.redis.GetAliasByURL"
+
+	const pageSize = 500
+
+	index := createdKey
+	if owner != "" {
+		index = ownerKey(owner)
+	}
+
+	now := time.Now()
+	for start := int64(0); ; start += pageSize {
+		aliases, err := s.client.ZRange(ctx, index, start, start+pageSize-1).Result()
+		if err != nil {
+			return "", fmt.Errorf("%s: %w", op, err)
+		}
+
+		cmds := make([]*goredis.SliceCmd, len(aliases))
+		_, err = s.client.Pipelined(ctx, func(pipe goredis.Pipeliner) error {
+			for i, alias := range aliases {
+				cmds[i] = pipe.HMGet(ctx, urlKey(alias),
+					"url", "owner", "state", "expires_at", "max_clicks", "password_hash", "redirect_type",
+					"utm_source", "utm_medium", "utm_campaign", "geo_targets", "device_targets", "variants",
+					"active_from", "active_until", "domain", "cache_max_age", "og_title", "og_description", "og_image",
+					"campaign_id",
+				)
+			}
+			return nil
+		})
+		if err != nil {
+			return "", fmt.Errorf("%s: %w", op, err)
+		}
+
+		for i, cmd := range cmds {
+			vals := cmd.Val()
+			u, _ := vals[0].(string)
+			o, _ := vals[1].(string)
+			state, _ := vals[2].(string)
+			hash, _ := vals[5].(string)
+			utm := vals[7] != nil || vals[8] != nil || vals[9] != nil
+			targets := vals[10] != nil || vals[11] != nil || vals[12] != nil
+			window := vals[13] != nil || vals[14] != nil
+			domain := vals[15] != nil
+			cache := vals[16] != nil
+			openGraph := vals[17] != nil || vals[18] != nil || vals[19] != nil
+			campaign := vals[20] != nil
+
+			expired := storage.URL{ExpiresAt: parseTime(vals[3])}.Expired(now)
+			if u == url && o == owner && state == storage.StateActive &&
+				!expired && parseInt(vals[4]) == 0 && hash == "" && parseInt(vals[6]) == 0 && !utm && !targets && !window && !domain && !cache && !openGraph && !campaign {
+				return aliases[i], nil
+			}
+		}
+
+		if len(aliases) < pageSize {
+			return "", storage.ErrUrlNotFound
+		}
+	}
+}
+
+// ConsumeClick atomically counts a redirect against the link's max_clicks
+// and returns storage.ErrUrlExhausted once the limit has been reached.
+func (s *Storage) ConsumeClick(ctx context.Context, alias string) error {
+	const op = "storage.redis.ConsumeClick"
+
+	res, err := consumeClickScript.Run(ctx, s.client, []string{urlKey(alias)}).Int()
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	switch res {
+	case -1:
+		return fmt.Errorf("%s: %w", op, storage.ErrUrlNotFound)
+	case 0:
+		return fmt.Errorf("%s: %w", op, storage.ErrUrlExhausted)
+	}
+
+	return nil
+}
+
+// DeleteURL marks alias as deleted: it stops resolving but keeps the alias
+// taken until PurgeDeleted removes it, and can be brought back with
+// RestoreURL. A non-empty owner restricts the deletion to that user's
+// links; other links are reported as not found.
+func (s *Storage) DeleteURL(ctx context.Context, alias string, owner string) error {
+	const op = "storage.redis.DeleteURL"
+
+	linkOwner, err := s.client.HGet(ctx, urlKey(alias), "owner").Result()
+	if err != nil && !errors.Is(err, goredis.Nil) {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	if owner != "" && linkOwner != owner {
+		return fmt.Errorf("%s: %w", op, storage.ErrUrlNotFound)
+	}
+
+	keys := []string{urlKey(alias), createdKey, expiresKey, deletedKey}
+	if linkOwner != "" {
+		keys = append(keys, ownerKey(linkOwner))
+	}
+
+	now := time.Now().UTC()
+	deleted, err := deleteScript.Run(ctx, s.client, keys,
+		alias, now.UnixMilli(), now.Format(time.RFC3339Nano),
+	).Int()
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	if deleted == 0 {
+		return fmt.Errorf("%s: %w", op, storage.ErrUrlNotFound)
+	}
+
+	return nil
+}
+
+// RestoreURL brings back a deleted link. A non-empty owner restricts the
+// restore to that user's links; other links, and links that are not
+// deleted, are reported as not found.
+func (s *Storage) RestoreURL(ctx context.Context, alias string, owner string) error {
+	const op = "storage.redis.RestoreURL"
+
+	vals, err := s.client.HMGet(ctx, urlKey(alias), "owner", "created_at", "expires_at").Result()
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	linkOwner, _ := vals[0].(string)
+	if owner != "" && linkOwner != owner {
+		return fmt.Errorf("%s: %w", op, storage.ErrUrlNotFound)
+	}
+
+	keys := []string{urlKey(alias), createdKey, expiresKey, deletedKey}
+	if linkOwner != "" {
+		keys = append(keys, ownerKey(linkOwner))
+	}
+
+	expiresScore := ""
+	if expiresAt := parseTime(vals[2]); !expiresAt.IsZero() {
+		expiresScore = strconv.FormatInt(expiresAt.UnixMilli(), 10)
+	}
+
+	restored, err := restoreScript.Run(ctx, s.client, keys,
+		alias, parseTime(vals[1]).UnixMilli(), expiresScore,
+	).Int()
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	if restored == 0 {
+		return fmt.Errorf("%s: %w", op, storage.ErrUrlNotFound)
+	}
+
+	return nil
+}
+
+// RenameURL moves a live link and its clicks from alias to newAlias and
+// returns storage.ErrUrlExists if newAlias is taken. A non-zero
+// forwardUntil keeps alias until then as a link to storage.ForwardURL of
+// newAlias. A non-empty owner restricts the rename to that user's links;
+// other links are reported as not found.
+func (s *Storage) RenameURL(ctx context.Context, alias string, owner string, newAlias string, forwardUntil time.Time) error {
+	const op = "storage.redis.RenameURL"
+
+	linkOwner, err := s.client.HGet(ctx, urlKey(alias), "owner").Result()
+	if err != nil && !errors.Is(err, goredis.Nil) {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	if owner != "" && linkOwner != owner {
+		return fmt.Errorf("%s: %w", op, storage.ErrUrlNotFound)
+	}
+
+	oldSummary, oldDaily, oldReferrers, oldCountries, oldLog := clickKeys(alias)
+	newSummary, newDaily, newReferrers, newCountries, newLog := clickKeys(newAlias)
+	keys := []string{
+		urlKey(alias), urlKey(newAlias),
+		oldSummary, oldDaily, oldReferrers, oldCountries, oldLog,
+		newSummary, newDaily, newReferrers, newCountries, newLog,
+		createdKey, expiresKey,
+	}
+	if linkOwner != "" {
+		keys = append(keys, ownerKey(linkOwner))
+	}
+
+	args := []any{alias, newAlias, owner}
+	if !forwardUntil.IsZero() {
+		id, err := s.client.Incr(ctx, idKey).Result()
+		if err != nil {
+			return fmt.Errorf("%s: %w", op, err)
+		}
+
+		domain, err := s.client.HGet(ctx, urlKey(alias), "domain").Result()
+		if err != nil && !errors.Is(err, goredis.Nil) {
+			return fmt.Errorf("%s: %w", op, err)
+		}
+
+		// The forward is saved like any other link, with the keys
+		// renameScript already has.
+		_, saveArgs := s.saveArgs(storage.URL{
+			Alias:     alias,
+			URL:       storage.ForwardURL(newAlias),
+			ExpiresAt: forwardUntil,
+			Owner:     linkOwner,
+			Domain:    domain,
+		}, id, time.Now().UTC())
+		args = append(args, saveArgs...)
+	}
+
+	renamed, err := renameScript.Run(ctx, s.client, keys, args...).Int()
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	switch renamed {
+	case 0:
+		return fmt.Errorf("%s: %w", op, storage.ErrUrlNotFound)
+	case -1:
+		return fmt.Errorf("%s: %w", op, storage.ErrUrlExists)
+	}
+
+	return nil
+}
+
+// remove deletes alias for good, together with its clicks and index
+// entries. It reports whether the link's hash was still there.
+func (s *Storage) remove(ctx context.Context, alias string) (bool, error) {
+	linkOwner, err := s.client.HGet(ctx, urlKey(alias), "owner").Result()
+	if err != nil && !errors.Is(err, goredis.Nil) {
+		return false, err
+	}
+
+	summaryKey, dailyKey, referrersKey, countriesKey, logKey := clickKeys(alias)
+
+	var del *goredis.IntCmd
+	_, err = s.client.TxPipelined(ctx, func(pipe goredis.Pipeliner) error {
+		del = pipe.Del(ctx, urlKey(alias))
+		pipe.ZRem(ctx, createdKey, alias)
+		pipe.ZRem(ctx, expiresKey, alias)
+		pipe.ZRem(ctx, deletedKey, alias)
+		if linkOwner != "" {
+			pipe.ZRem(ctx, ownerKey(linkOwner), alias)
+		}
+		pipe.Del(ctx, summaryKey, dailyKey, referrersKey, countriesKey, logKey)
+		return nil
+	})
+	if err != nil {
+		return false, err
+	}
+
+	return del.Val() > 0, nil
+}
+
+// UpdateURL points alias at newURL. A non-empty owner restricts the update
+// to that user's links; other links are reported as not found.
+func (s *Storage) UpdateURL(ctx context.Context, alias string, owner string, newURL string) error {
+	const op = "storage.redis.UpdateURL"
+
+	return s.setIfExists(ctx, op, alias, owner, true,
+		"url", newURL,
+		"title", "",
+		"description", "",
+		"image_url", "",
+	)
+}
+
+func (s *Storage) BlockURL(ctx context.Context, alias string, reason string, reference string) error {
+	const op = "storage.redis.BlockURL"
+
+	return s.setIfExists(ctx, op, alias, "", false,
+		"state", storage.StateBlockedLegal,
+		"block_reason", reason,
+		"block_reference", reference,
+	)
+}
+
+// FlagURL marks an active link as unsafe after a reputation check flagged
+// its target; threat is kept as the block reason. Links that are already
+// blocked are left as they are and reported as storage.ErrUrlNotFound.
+func (s *Storage) FlagURL(ctx context.Context, alias string, threat string) error {
+	const op = "storage.redis.FlagURL"
+
+	flagged, err := flagScript.Run(ctx, s.client,
+		[]string{urlKey(alias)}, storage.StateActive, storage.StateBlockedUnsafe, threat,
+	).Int()
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	if flagged == 0 {
+		return fmt.Errorf("%s: %w", op, storage.ErrUrlNotFound)
+	}
+
+	return nil
+}
+
+// SetURLHealth records the outcome of checking the destination of alias.
+func (s *Storage) SetURLHealth(ctx context.Context, alias string, health storage.Health) error {
+	const op = "storage.redis.SetURLHealth"
+
+	return s.setIfExists(ctx, op, alias, "", true,
+		"health", health.Status,
+		"health_status_code", health.StatusCode,
+		"health_checked_at", health.CheckedAt.UTC().Format(time.RFC3339Nano),
+	)
+}
+
+func (s *Storage) GetLegalBlock(ctx context.Context, alias string) (storage.LegalBlock, error) {
+	const op = "storage.redis.GetLegalBlock"
+
+	vals, err := s.client.HMGet(ctx, urlKey(alias), "state", "block_reason", "block_reference").Result()
+	if err != nil {
+		return storage.LegalBlock{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	if state, _ := vals[0].(string); state != storage.StateBlockedLegal {
+		return storage.LegalBlock{}, storage.ErrUrlNotFound
+	}
+
+	reason, _ := vals[1].(string)
+	reference, _ := vals[2].(string)
+
+	return storage.LegalBlock{Reason: reason, Reference: reference}, nil
+}
+
+// GetOwner returns the owner of alias whatever its state: "" for links
+// created without an owner, storage.ErrUrlNotFound if there is no such
+// link or it is deleted.
+func (s *Storage) GetOwner(ctx context.Context, alias string) (string, error) {
+	const op = "storage.redis.GetOwner"
+
+	vals, err := s.client.HMGet(ctx, urlKey(alias), "url", "owner", "deleted_at").Result()
+	if err != nil {
+		return "", fmt.Errorf("%s: %w", op, err)
+	}
+	if vals[0] == nil || vals[2] != nil {
+		return "", storage.ErrUrlNotFound
+	}
+
+	owner, _ := vals[1].(string)
+	return owner, nil
+}
+
+// AliasExists reports whether a link holds alias, deleted links that were
+// not purged yet included. Expired links are gone once their key expires.
+func (s *Storage) AliasExists(ctx context.Context, alias string) (bool, error) {
+	const op = "storage.redis.AliasExists"
+
+	n, err := s.client.Exists(ctx, urlKey(alias)).Result()
+	if err != nil {
+		return false, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return n > 0, nil
+}
+
+// ListURLs returns a page of the saved links that pass filter, ordered by
+// creation date. The owner and creation time are looked up in the sorted
+// set indexes; a URL substring, a tag or an API key is matched while
+// walking them, as is the health status. Aliases whose keys already expired are dropped from the
+// index as they are found, so a page may come back shorter than limit.
+func (s *Storage) ListURLs(ctx context.Context, filter storage.ListFilter, limit int, offset int, desc bool) ([]storage.URL, error) {
+	const op = "storage.redis.ListURLs"
+
+	byCreated := listRange(filter, desc)
+
+	if !needsScan(filter) {
+		byCreated.Offset, byCreated.Count = int64(offset), int64(limit)
+		urls, _, err := s.listPage(ctx, byCreated.Key, byCreated)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", op, err)
+		}
+		return urls, nil
+	}
+
+	urls := []storage.URL{}
+	skip := offset
+	byCreated.Count = listScanBatch
+	for len(urls) < limit {
+		page, scanned, err := s.listPage(ctx, byCreated.Key, byCreated)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", op, err)
+		}
+
+		for _, u := range page {
+			if !filter.Match(u) {
+				continue
+			}
+			if skip > 0 {
+				skip--
+				continue
+			}
+			if len(urls) < limit {
+				urls = append(urls, u)
+			}
+		}
+
+		if scanned < listScanBatch {
+			break
+		}
+		// Expired aliases were removed from the index, so the next batch
+		// starts right after the live ones.
+		byCreated.Offset += int64(len(page))
+	}
+
+	return urls, nil
+}
+
+// CountURLs counts the saved links that pass filter, the same way ListURLs
+// finds them. Without conditions to match one by one the count comes
+// straight from an index and may include aliases that expired moments ago.
+func (s *Storage) CountURLs(ctx context.Context, filter storage.ListFilter) (int64, error) {
+	const op = "storage.redis.CountURLs"
+
+	byCreated := listRange(filter, false)
+
+	if !needsScan(filter) {
+		n, err := s.client.ZCount(ctx, byCreated.Key, byCreated.Start.(string), byCreated.Stop.(string)).Result()
+		if err != nil {
+			return 0, fmt.Errorf("%s: %w", op, err)
+		}
+		return n, nil
+	}
+
+	var n int64
+	byCreated.Count = listScanBatch
+	for {
+		page, scanned, err := s.listPage(ctx, byCreated.Key, byCreated)
+		if err != nil {
+			return 0, fmt.Errorf("%s: %w", op, err)
+		}
+
+		for _, u := range page {
+			if filter.Match(u) {
+				n++
+			}
+		}
+
+		if scanned < listScanBatch {
+			return n, nil
+		}
+		byCreated.Offset += int64(len(page))
+	}
+}
+
+// listRange selects the index to walk for filter, urls:created or the
+// owner's one, and the range of creation times to read from it. Index
+// scores are creation times in milliseconds.
+func listRange(filter storage.ListFilter, desc bool) goredis.ZRangeArgs {
+	byCreated := goredis.ZRangeArgs{
+		Key:     createdKey,
+		Start:   "-inf",
+		Stop:    "+inf",
+		ByScore: true,
+		Rev:     desc,
+	}
+	if filter.Owner != "" {
+		byCreated.Key = ownerKey(filter.Owner)
+	}
+	if !filter.CreatedFrom.IsZero() {
+		byCreated.Start = strconv.FormatInt(filter.CreatedFrom.UnixMilli(), 10)
+	}
+	if !filter.CreatedTo.IsZero() {
+		byCreated.Stop = "(" + strconv.FormatInt(filter.CreatedTo.UnixMilli(), 10)
+	}
+
+	return byCreated
+}
+
+// needsScan reports whether filter has conditions no index covers, so
+// links have to be matched one by one.
+func needsScan(filter storage.ListFilter) bool {
+	return filter.URLContains != "" || filter.Tag != "" || filter.APIKeyID != 0 || filter.Health != "" ||
+		filter.CampaignID != 0 || filter.Public
+}
+
+// listScanBatch is how many aliases ListURLs and CountURLs read at a time
+// when they have to match links themselves.
+const listScanBatch = 500
+
+// listPage loads the links of the aliases index returns for args; scanned
+// counts the aliases, including expired ones.
+func (s *Storage) listPage(ctx context.Context, index string, args goredis.ZRangeArgs) (_ []storage.URL, scanned int, _ error) {
+	aliases, err := s.client.ZRangeArgs(ctx, args).Result()
+	if err != nil {
+		return nil, 0, err
+	}
+
+	cmds := make([]*goredis.SliceCmd, len(aliases))
+	_, err = s.client.Pipelined(ctx, func(pipe goredis.Pipeliner) error {
+		for i, alias := range aliases {
+			cmds[i] = pipe.HMGet(ctx, urlKey(alias),
+				"url", "created_at", "expires_at", "api_key_id", "owner", "title", "description", "image_url", "tags",
+				"domain", "health", "health_status_code", "health_checked_at", "campaign_id",
+				"state", "password_hash", "active_from", "active_until",
+			)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, 0, err
+	}
+
+	urls := []storage.URL{}
+	var expired []any
+	for i, cmd := range cmds {
+		vals := cmd.Val()
+
+		resURL, ok := vals[0].(string)
+		if !ok {
+			expired = append(expired, aliases[i])
+			continue
+		}
+
+		u := storage.URL{
+			Alias:     aliases[i],
+			URL:       resURL,
+			CreatedAt: parseTime(vals[1]),
+			ExpiresAt: parseTime(vals[2]),
+			APIKeyID:  parseInt(vals[3]),
+		}
+		u.Owner, _ = vals[4].(string)
+		u.Domain, _ = vals[9].(string)
+		u.Metadata.Title, _ = vals[5].(string)
+		u.Metadata.Description, _ = vals[6].(string)
+		u.Metadata.Image, _ = vals[7].(string)
+		if tags, _ := vals[8].(string); tags != "" {
+			u.Tags = strings.Split(tags, ",")
+		}
+		u.Health.Status, _ = vals[10].(string)
+		u.Health.StatusCode = int(parseInt(vals[11]))
+		u.Health.CheckedAt = parseTime(vals[12])
+		u.CampaignID = parseInt(vals[13])
+		u.State, _ = vals[14].(string)
+		u.PasswordHash, _ = vals[15].(string)
+		u.ActiveFrom = parseTime(vals[16])
+		u.ActiveUntil = parseTime(vals[17])
+		urls = append(urls, u)
+	}
+
+	if len(expired) > 0 {
+		if err := s.client.ZRem(ctx, index, expired...).Err(); err != nil {
+			return nil, 0, err
+		}
+	}
+
+	return urls, len(aliases), nil
+}
+
+func (s *Storage) SaveClick(ctx context.Context, click storage.Click) error {
+	const op = "storage.redis.SaveClick"
+
+	summaryKey, dailyKey, referrersKey, countriesKey, logKey := clickKeys(click.Alias)
+	clickedAt := click.ClickedAt.UTC()
+
+	_, err := s.client.TxPipelined(ctx, func(pipe goredis.Pipeliner) error {
+		count := func(key, field string) {
+			pipe.HIncrBy(ctx, key, field, 1)
+			if click.Bot {
+				pipe.HIncrBy(ctx, key, botField+field, 1)
+			}
+		}
+		count(summaryKey, "total")
+		count(dailyKey, clickedAt.Format(time.DateOnly))
+		count(referrersKey, storage.ReferrerDomain(click.Referrer))
+		count(countriesKey, click.Country)
+		if click.Variant != "" {
+			count(summaryKey, variantField+click.Variant)
+		}
+		pipe.HSet(ctx, summaryKey, "last", clickedAt.Format(time.RFC3339Nano))
+		if !click.Bot {
+			pipe.HSet(ctx, summaryKey, "last_human", clickedAt.Format(time.RFC3339Nano))
+		}
+		pipe.XAdd(ctx, &goredis.XAddArgs{
+			Stream: logKey,
+			MaxLen: clickLogMaxLen,
+			Approx: true,
+			Values: map[string]any{
+				"clicked_at": clickedAt.Format(time.RFC3339Nano),
+				"referrer":   click.Referrer,
+				"user_agent": click.UserAgent,
+				"variant":    click.Variant,
+				"bot":        click.Bot,
+				"country":    click.Country,
+				"city":       click.City,
+			},
+		})
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	return nil
+}
+
+// GetStats returns click totals for alias and a per-day breakdown of the
+// clicks made since the given time. Clicks of bots are left out unless
+// includeBots is set.
+func (s *Storage) GetStats(ctx context.Context, alias string, since time.Time, includeBots bool) (storage.Stats, error) {
+	const op = "storage.redis.GetStats"
+
+	summaryKey, dailyKey, _, _, _ := clickKeys(alias)
+
+	var (
+		exists  *goredis.IntCmd
+		deleted *goredis.BoolCmd
+		domain  *goredis.SliceCmd
+		summary *goredis.MapStringStringCmd
+		daily   *goredis.MapStringStringCmd
+	)
+	_, err := s.client.Pipelined(ctx, func(pipe goredis.Pipeliner) error {
+		exists = pipe.Exists(ctx, urlKey(alias))
+		deleted = pipe.HExists(ctx, urlKey(alias), "deleted_at")
+		domain = pipe.HMGet(ctx, urlKey(alias), "domain", "imported_clicks")
+		summary = pipe.HGetAll(ctx, summaryKey)
+		daily = pipe.HGetAll(ctx, dailyKey)
+		return nil
+	})
+	if err != nil {
+		return storage.Stats{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	if exists.Val() == 0 || deleted.Val() {
+		return storage.Stats{}, storage.ErrUrlNotFound
+	}
+
+	stats := storage.Stats{Daily: []storage.DailyClicks{}}
+	stats.Domain, _ = domain.Val()[0].(string)
+
+	imported, _ := domain.Val()[1].(string)
+	importedClicks, _ := strconv.ParseInt(imported, 10, 64)
+
+	sum := counters(summary.Val(), includeBots)
+	stats.Total = sum["total"] + importedClicks
+	for field, n := range sum {
+		if variant, ok := strings.CutPrefix(field, variantField); ok && n > 0 {
+			stats.Variants = append(stats.Variants, storage.VariantClicks{Variant: variant, Clicks: n})
+		}
+	}
+	sort.Slice(stats.Variants, func(i, j int) bool { return stats.Variants[i].Variant < stats.Variants[j].Variant })
+
+	// Links clicked only before last_human was kept have no bot clicks
+	// either.
+	last, ok := summary.Val()["last_human"]
+	if includeBots || (!ok && stats.Total > 0) {
+		last = summary.Val()["last"]
+	}
+	stats.LastAccess = parseTime(last)
+
+	sinceDay := since.UTC().Format(time.DateOnly)
+	for day, n := range counters(daily.Val(), includeBots) {
+		if day < sinceDay || n <= 0 {
+			continue
+		}
+		stats.Daily = append(stats.Daily, storage.DailyClicks{Date: day, Clicks: n})
+	}
+	sort.Slice(stats.Daily, func(i, j int) bool { return stats.Daily[i].Date < stats.Daily[j].Date })
+
+	return stats, nil
+}
+
+// GetReferrers counts the clicks on alias per referring domain, the most
+// clicked first. Clicks saved before the counts were kept are not included,
+// nor clicks of bots unless includeBots is set.
+func (s *Storage) GetReferrers(ctx context.Context, alias string, includeBots bool) ([]storage.ReferrerClicks, error) {
+	const op = "storage.redis.GetReferrers"
+
+	_, _, referrersKey, _, _ := clickKeys(alias)
+
+	var (
+		exists    *goredis.IntCmd
+		deleted   *goredis.BoolCmd
+		referrers *goredis.MapStringStringCmd
+	)
+	_, err := s.client.Pipelined(ctx, func(pipe goredis.Pipeliner) error {
+		exists = pipe.Exists(ctx, urlKey(alias))
+		deleted = pipe.HExists(ctx, urlKey(alias), "deleted_at")
+		referrers = pipe.HGetAll(ctx, referrersKey)
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	if exists.Val() == 0 || deleted.Val() {
+		return nil, storage.ErrUrlNotFound
+	}
+
+	byDomain := counters(referrers.Val(), includeBots)
+	maps.DeleteFunc(byDomain, func(_ string, n int64) bool { return n <= 0 })
+
+	return storage.SortReferrers(byDomain), nil
+}
+
+// GetCountries counts the clicks on alias per country, the most clicked
+// first. Clicks saved before the counts were kept are not included, nor
+// clicks of bots unless includeBots is set.
+func (s *Storage) GetCountries(ctx context.Context, alias string, includeBots bool) ([]storage.CountryClicks, error) {
+	const op = "storage.redis.GetCountries"
+
+	_, _, _, countriesKey, _ := clickKeys(alias)
+
+	var (
+		exists    *goredis.IntCmd
+		deleted   *goredis.BoolCmd
+		countries *goredis.MapStringStringCmd
+	)
+	_, err := s.client.Pipelined(ctx, func(pipe goredis.Pipeliner) error {
+		exists = pipe.Exists(ctx, urlKey(alias))
+		deleted = pipe.HExists(ctx, urlKey(alias), "deleted_at")
+		countries = pipe.HGetAll(ctx, countriesKey)
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	if exists.Val() == 0 || deleted.Val() {
+		return nil, storage.ErrUrlNotFound
+	}
+
+	byCountry := counters(countries.Val(), includeBots)
+	maps.DeleteFunc(byCountry, func(_ string, n int64) bool { return n <= 0 })
+
+	return storage.SortCountries(byCountry), nil
+}
+
+// ListClicks returns up to limit clicks on alias with IDs above afterID
+// that pass filter, oldest first. Only the last clickLogMaxLen clicks of a
+// link are kept; a click's ID is made of its stream entry ID.
+func (s *Storage) ListClicks(ctx context.Context, alias string, filter storage.ClickFilter, afterID int64, limit int) ([]storage.Click, error) {
+	const op = "storage.redis.ListClicks"
+
+	_, _, _, _, logKey := clickKeys(alias)
+
+	var (
+		exists  *goredis.IntCmd
+		deleted *goredis.BoolCmd
+	)
+	_, err := s.client.Pipelined(ctx, func(pipe goredis.Pipeliner) error {
+		exists = pipe.Exists(ctx, urlKey(alias))
+		deleted = pipe.HExists(ctx, urlKey(alias), "deleted_at")
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	if exists.Val() == 0 || deleted.Val() {
+		return nil, storage.ErrUrlNotFound
+	}
+
+	// Entries are added as clicks happen, so their IDs, which start with
+	// the time in milliseconds, bound the click time too.
+	start := "-"
+	if afterID > 0 {
+		start = "(" + streamID(afterID)
+	} else if !filter.From.IsZero() {
+		start = strconv.FormatInt(filter.From.UnixMilli(), 10)
+	}
+	end := "+"
+	if !filter.To.IsZero() {
+		end = strconv.FormatInt(filter.To.UnixMilli(), 10)
+	}
+
+	clicks := []storage.Click{}
+	for len(clicks) < limit {
+		entries, err := s.client.XRangeN(ctx, logKey, start, end, int64(limit)).Result()
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", op, err)
+		}
+
+		for _, e := range entries {
+			c := clickFromEntry(alias, e)
+			if filter.Match(c) && len(clicks) < limit {
+				clicks = append(clicks, c)
+			}
+		}
+
+		if len(entries) < limit {
+			break
+		}
+		start = "(" + entries[len(entries)-1].ID
+	}
+
+	return clicks, nil
+}
+
+// clickIDShift packs the sequence number of a stream entry ID next to its
+// milliseconds in a click ID.
+const clickIDShift = 1_000_000
+
+func clickFromEntry(alias string, e goredis.XMessage) storage.Click {
+	str := func(field string) string {
+		v, _ := e.Values[field].(string)
+		return v
+	}
+
+	c := storage.Click{
+		Alias:     alias,
+		ClickedAt: parseTime(e.Values["clicked_at"]),
+		Referrer:  str("referrer"),
+		UserAgent: str("user_agent"),
+		Variant:   str("variant"),
+		Bot:       str("bot") == "1",
+		Country:   str("country"),
+		City:      str("city"),
+	}
+	if ms, seq, ok := strings.Cut(e.ID, "-"); ok {
+		m, _ := strconv.ParseInt(ms, 10, 64)
+		n, _ := strconv.ParseInt(seq, 10, 64)
+		c.ID = m*clickIDShift + n
+	}
+
+	return c
+}
+
+func streamID(clickID int64) string {
+	return strconv.FormatInt(clickID/clickIDShift, 10) + "-" + strconv.FormatInt(clickID%clickIDShift, 10)
+}
+
+// counters parses the counters of a click hash, other than the bot ones,
+// and takes the bot clicks out of them unless includeBots is set. Fields
+// that are not counters, such as "last", are left out.
+func counters(hash map[string]string, includeBots bool) map[string]int64 {
+	counts := make(map[string]int64, len(hash))
+	for field, val := range hash {
+		if strings.HasPrefix(field, botField) {
+			continue
+		}
+		n, err := strconv.ParseInt(val, 10, 64)
+		if err != nil {
+			continue
+		}
+		if !includeBots {
+			bots, _ := strconv.ParseInt(hash[botField+field], 10, 64)
+			n -= bots
+		}
+		counts[field] = n
+	}
+
+	return counts
+}
+
+// DeleteExpired removes links that expired before now and returns their
+// aliases. Deleted links are not in urls:expires; PurgeDeleted removes them.
+func (s *Storage) DeleteExpired(ctx context.Context, now time.Time) ([]string, error) {
+	const op = "storage.redis.DeleteExpired"
+
+	aliases, err := s.client.ZRangeByScore(ctx, expiresKey, &goredis.ZRangeBy{
+		Min: "-inf",
+		Max: strconv.FormatInt(now.UnixMilli(), 10),
+	}).Result()
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	var deleted []string
+	for _, alias := range aliases {
+		existed, err := s.remove(ctx, alias)
+		if err != nil {
+			return deleted, fmt.Errorf("%s: %w", op, err)
+		}
+		// A missing key was already evicted by the global TTL; remove has
+		// still cleaned up the indexes.
+		if existed {
+			deleted = append(deleted, alias)
+		}
+	}
+
+	return deleted, nil
+}
+
+// PurgeDeleted removes links deleted before the given time, freeing their
+// aliases, and returns the aliases.
+func (s *Storage) PurgeDeleted(ctx context.Context, before time.Time) ([]string, error) {
+	const op = "storage.redis.PurgeDeleted"
+
+	aliases, err := s.client.ZRangeByScore(ctx, deletedKey, &goredis.ZRangeBy{
+		Min: "-inf",
+		Max: strconv.FormatInt(before.UnixMilli(), 10),
+	}).Result()
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	var purged []string
+	for _, alias := range aliases {
+		existed, err := s.remove(ctx, alias)
+		if err != nil {
+			return purged, fmt.Errorf("%s: %w", op, err)
+		}
+		if existed {
+			purged = append(purged, alias)
+		}
+	}
+
+	return purged, nil
+}
+
+// parseInt reads an integer hash field; missing or malformed values yield 0.
+func parseInt(v any) int64 {
+	s, ok := v.(string)
+	if !ok {
+		return 0
+	}
+
+	n, _ := strconv.ParseInt(s, 10, 64)
+	return n
+}
+
+// parseTime reads a timestamp stored as an RFC 3339 hash field; missing or
+// malformed values yield the zero time.
+func parseTime(v any) time.Time {
+	s, ok := v.(string)
+	if !ok {
+		return time.Time{}
+	}
+
+	t, _ := time.Parse(time.RFC3339Nano, s)
+	return t
+}
+
+// setIfExists sets fields of an existing link; live leaves deleted links
+// alone.
+func (s *Storage) setIfExists(ctx context.Context, op string, alias string, owner string, live bool, fieldsAndValues ...any) error {
+	skipDeleted := "0"
+	if live {
+		skipDeleted = "1"
+	}
+
+	updated, err := setIfExistsScript.Run(ctx, s.client,
+		[]string{urlKey(alias)}, append([]any{owner, skipDeleted}, fieldsAndValues...)...,
+	).Int()
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	if updated == 0 {
+		return fmt.Errorf("%s: %w", op, storage.ErrUrlNotFound)
+	}
+
+	return nil
+}
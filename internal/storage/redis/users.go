@@ -0,0 +1,240 @@
+package redis
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	goredis "github.com/redis/go-redis/v9"
+
+	"url-shortener/internal/storage"
+)
+
+// Users are hashes under user:{username}; users:id hands out ids.
+const (
+	userKeyPrefix = "user:"
+	usersIDKey    = "users:id"
+)
+
+func userKey(username string) string {
+	return userKeyPrefix + username
+}
+
+func (s *Storage) SaveUser(ctx context.Context, user storage.User) (int64, error) {
+	const op = "storage.redis.SaveUser"
+
+	id, err := s.client.Incr(ctx, usersIDKey).Result()
+	if err != nil {
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+
+	ok, err := s.client.HSetNX(ctx, userKey(user.Username), "id", id).Result()
+	if err != nil {
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+	if !ok {
+		return 0, fmt.Errorf("%s: %w", op, storage.ErrUserExists)
+	}
+
+	err = s.client.HSet(ctx, userKey(user.Username),
+		"password_hash", user.PasswordHash,
+		"role", user.Role,
+		"created_at", time.Now().UTC().Format(time.RFC3339Nano),
+	).Err()
+	if err != nil {
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return id, nil
+}
+
+func (s *Storage) GetUser(ctx context.Context, username string) (storage.User, error) {
+	const op = "storage.redis.GetUser"
+
+	vals, err := s.client.HMGet(ctx, userKey(username), "id", "password_hash", "role", "created_at").Result()
+	if err != nil {
+		return storage.User{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	if _, ok := vals[1].(string); !ok {
+		return storage.User{}, storage.ErrUserNotFound
+	}
+
+	user := storage.User{
+		ID:        parseInt(vals[0]),
+		Username:  username,
+		CreatedAt: parseTime(vals[3]),
+	}
+	user.PasswordHash, _ = vals[1].(string)
+	user.Role, _ = vals[2].(string)
+
+	return user, nil
+}
+
+// eraseAttempts is how often the audit log is rewritten when entries are
+// added meanwhile.
+const eraseAttempts = 5
+
+// DeleteUserData removes the links of username with their clicks, their API
+// keys, their campaigns and their account, and erases them from the audit
+// log. The steps do not share a transaction; one that failed can be
+// repeated.
+func (s *Storage) DeleteUserData(ctx context.Context, username string) (storage.UserDataReport, error) {
+	const op = "storage.redis.DeleteUserData"
+
+	// Links without an owner would all match.
+	if username == "" {
+		return storage.UserDataReport{}, fmt.Errorf("%s: empty username", op)
+	}
+
+	var report storage.UserDataReport
+
+	aliases, err := s.userAliases(ctx, username)
+	if err != nil {
+		return storage.UserDataReport{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	report.AuditEntries, err = s.eraseAuditLog(ctx, username, aliases)
+	if err != nil {
+		return storage.UserDataReport{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	for _, alias := range aliases {
+		summaryKey, _, _, _, _ := clickKeys(alias)
+		total, err := s.client.HGet(ctx, summaryKey, "total").Int64()
+		if err != nil && !errors.Is(err, goredis.Nil) {
+			return report, fmt.Errorf("%s: %w", op, err)
+		}
+
+		existed, err := s.remove(ctx, alias)
+		if err != nil {
+			return report, fmt.Errorf("%s: %w", op, err)
+		}
+		if existed {
+			report.Aliases = append(report.Aliases, alias)
+			report.Clicks += total
+		}
+	}
+
+	keys, err := s.ListAPIKeys(ctx, username)
+	if err != nil {
+		return report, fmt.Errorf("%s: %w", op, err)
+	}
+	for _, key := range keys {
+		_, err := s.client.TxPipelined(ctx, func(pipe goredis.Pipeliner) error {
+			pipe.Del(ctx, apiKeyKey(key.ID))
+			pipe.ZRem(ctx, apiKeysKey, key.ID)
+			pipe.HDel(ctx, apiKeysByHashKey, key.Hash)
+			return nil
+		})
+		if err != nil {
+			return report, fmt.Errorf("%s: %w", op, err)
+		}
+		report.APIKeys++
+	}
+
+	campaigns, err := s.ListCampaigns(ctx, username)
+	if err != nil {
+		return report, fmt.Errorf("%s: %w", op, err)
+	}
+	for _, c := range campaigns {
+		// Links of other users stay but leave the campaign.
+		if err := s.removeCampaign(ctx, c.ID); err != nil {
+			return report, fmt.Errorf("%s: %w", op, err)
+		}
+		report.Campaigns++
+	}
+
+	n, err := s.client.Del(ctx, userKey(username)).Result()
+	if err != nil {
+		return report, fmt.Errorf("%s: %w", op, err)
+	}
+	report.User = n > 0
+
+	return report, nil
+}
+
+// userAliases returns the links of username, deleted ones included, which
+// have left the owner index.
+func (s *Storage) userAliases(ctx context.Context, username string) ([]string, error) {
+	aliases, err := s.client.ZRange(ctx, ownerKey(username), 0, -1).Result()
+	if err != nil {
+		return nil, err
+	}
+
+	deleted, err := s.client.ZRange(ctx, deletedKey, 0, -1).Result()
+	if err != nil {
+		return nil, err
+	}
+	owners := make([]*goredis.StringCmd, len(deleted))
+	_, err = s.client.Pipelined(ctx, func(pipe goredis.Pipeliner) error {
+		for i, alias := range deleted {
+			owners[i] = pipe.HGet(ctx, urlKey(alias), "owner")
+		}
+		return nil
+	})
+	if err != nil && !errors.Is(err, goredis.Nil) {
+		return nil, err
+	}
+	for i, alias := range deleted {
+		if owners[i].Val() == username {
+			aliases = append(aliases, alias)
+		}
+	}
+
+	return aliases, nil
+}
+
+// eraseAuditLog rewrites the entries about username in place. The list is
+// watched, so entries pushed meanwhile, which shift the indexes, make it
+// start over.
+func (s *Storage) eraseAuditLog(ctx context.Context, username string, aliases []string) (int64, error) {
+	for range eraseAttempts {
+		var erased int64
+		err := s.client.Watch(ctx, func(tx *goredis.Tx) error {
+			erased = 0
+
+			items, err := tx.LRange(ctx, auditLogKey, 0, -1).Result()
+			if err != nil {
+				return err
+			}
+
+			updated := make(map[int64]string)
+			for i, data := range items {
+				var e storage.AuditEntry
+				if err := json.Unmarshal([]byte(data), &e); err != nil {
+					return err
+				}
+				if !e.Erase(username, aliases) {
+					continue
+				}
+				data, err := json.Marshal(e)
+				if err != nil {
+					return err
+				}
+				updated[int64(i)] = string(data)
+			}
+			erased = int64(len(updated))
+			if len(updated) == 0 {
+				return nil
+			}
+
+			_, err = tx.TxPipelined(ctx, func(pipe goredis.Pipeliner) error {
+				for i, data := range updated {
+					pipe.LSet(ctx, auditLogKey, i, data)
+				}
+				return nil
+			})
+			return err
+		}, auditLogKey)
+		if errors.Is(err, goredis.TxFailedErr) {
+			continue
+		}
+
+		return erased, err
+	}
+
+	return 0, errors.New("audit log kept changing")
+}
@@ -0,0 +1,217 @@
+package rediscache
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	goredis "github.com/redis/go-redis/v9"
+
+	"url-shortener/internal/lib/logger/sl"
+	"url-shortener/internal/storage"
+	"url-shortener/internal/storage/instrumented"
+)
+
+// Links are cached as JSON under cache:url:{alias}, so the cache can share
+// a database with the redis storage backend.
+const keyPrefix = "cache:url:"
+
+// Storage is a cache-aside layer shared by every instance: GetURL reads
+// links from Redis and falls back to the backend on a miss, writing the
+// result back with a TTL. Changes made through it delete the cached link,
+// so other instances see them at once.
+//
+// The cache fails open: if Redis is unavailable, calls go to the backend.
+type Storage struct {
+	instrumented.Backend
+	log    *slog.Logger
+	client *goredis.Client
+	ttl    time.Duration
+}
+
+type Options struct {
+	Addr     string
+	Password string
+	DB       int
+	// TTL is the longest a link stays cached.
+	TTL time.Duration
+}
+
+func New(log *slog.Logger, backend instrumented.Backend, opts Options) (*Storage, error) {
+	const op = "storage.rediscache.New"
+
+	client := goredis.NewClient(&goredis.Options{
+		Addr:     opts.Addr,
+		Password: opts.Password,
+		DB:       opts.DB,
+	})
+
+	if err := client.Ping(context.Background()).Err(); err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return &Storage{
+		Backend: backend,
+		log:     log.With(slog.String("component", "rediscache")),
+		client:  client,
+		ttl:     opts.TTL,
+	}, nil
+}
+
+// GetURL caches only links that resolve. Links with a click limit are not
+// cached, as every click changes whether they still resolve.
+func (s *Storage) GetURL(ctx context.Context, alias string) (storage.URL, error) {
+	data, err := s.client.Get(ctx, keyPrefix+alias).Bytes()
+	switch {
+	case err == nil:
+		var u storage.URL
+		if err := json.Unmarshal(data, &u); err == nil {
+			if u.Expired(time.Now()) {
+				s.invalidate(ctx, alias)
+				return storage.URL{}, storage.ErrUrlExpired
+			}
+			return u, nil
+		}
+		s.log.Warn("malformed cached url", slog.String("alias", alias))
+	case !errors.Is(err, goredis.Nil):
+		s.log.Error("failed to read cached url", sl.Err(err))
+	}
+
+	u, err := s.Backend.GetURL(ctx, alias)
+	if err == nil && u.MaxClicks == 0 {
+		s.store(ctx, u)
+	}
+
+	return u, err
+}
+
+func (s *Storage) store(ctx context.Context, u storage.URL) {
+	ttl := s.ttl
+	if !u.ExpiresAt.IsZero() {
+		ttl = min(ttl, time.Until(u.ExpiresAt))
+	}
+	if ttl <= 0 {
+		return
+	}
+
+	data, err := json.Marshal(u)
+	if err != nil {
+		s.log.Error("failed to encode url", sl.Err(err))
+		return
+	}
+
+	if err := s.client.Set(ctx, keyPrefix+u.Alias, data, ttl).Err(); err != nil {
+		s.log.Error("failed to cache url", sl.Err(err))
+	}
+}
+
+func (s *Storage) invalidate(ctx context.Context, aliases ...string) {
+	if len(aliases) == 0 {
+		return
+	}
+
+	keys := make([]string, len(aliases))
+	for i, alias := range aliases {
+		keys[i] = keyPrefix + alias
+	}
+
+	// Deleting is not bound to the request: a link left in the cache after
+	// a change would be served for the whole TTL.
+	if err := s.client.Del(context.WithoutCancel(ctx), keys...).Err(); err != nil {
+		s.log.Error("failed to invalidate cached urls", slog.Any("aliases", aliases), sl.Err(err))
+	}
+}
+
+// Flush deletes every cached link, e.g. after the database was restored
+// from a backup. Only keys under keyPrefix are touched, as the database may
+// be shared with the redis storage backend.
+func (s *Storage) Flush(ctx context.Context) error {
+	const op = "storage.rediscache.Flush"
+
+	iter := s.client.Scan(ctx, 0, keyPrefix+"*", flushBatch).Iterator()
+	keys := make([]string, 0, flushBatch)
+	for iter.Next(ctx) {
+		keys = append(keys, iter.Val())
+		if len(keys) == flushBatch {
+			if err := s.client.Unlink(ctx, keys...).Err(); err != nil {
+				return fmt.Errorf("%s: %w", op, err)
+			}
+			keys = keys[:0]
+		}
+	}
+	if err := iter.Err(); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	if len(keys) > 0 {
+		if err := s.client.Unlink(ctx, keys...).Err(); err != nil {
+			return fmt.Errorf("%s: %w", op, err)
+		}
+	}
+
+	return nil
+}
+
+// flushBatch is how many keys Flush scans for and deletes at a time.
+const flushBatch = 500
+
+func (s *Storage) DeleteURL(ctx context.Context, alias string, owner string) error {
+	err := s.Backend.DeleteURL(ctx, alias, owner)
+	s.invalidate(ctx, alias)
+
+	return err
+}
+
+func (s *Storage) RenameURL(ctx context.Context, alias string, owner string, newAlias string, forwardUntil time.Time) error {
+	err := s.Backend.RenameURL(ctx, alias, owner, newAlias, forwardUntil)
+	s.invalidate(ctx, alias)
+
+	return err
+}
+
+func (s *Storage) UpdateURL(ctx context.Context, alias string, owner string, newURL string) error {
+	err := s.Backend.UpdateURL(ctx, alias, owner, newURL)
+	s.invalidate(ctx, alias)
+
+	return err
+}
+
+func (s *Storage) BlockURL(ctx context.Context, alias string, reason string, reference string) error {
+	err := s.Backend.BlockURL(ctx, alias, reason, reference)
+	s.invalidate(ctx, alias)
+
+	return err
+}
+
+func (s *Storage) FlagURL(ctx context.Context, alias string, threat string) error {
+	err := s.Backend.FlagURL(ctx, alias, threat)
+	s.invalidate(ctx, alias)
+
+	return err
+}
+
+func (s *Storage) DeleteExpired(ctx context.Context, now time.Time) ([]string, error) {
+	deleted, err := s.Backend.DeleteExpired(ctx, now)
+	s.invalidate(ctx, deleted...)
+
+	return deleted, err
+}
+
+func (s *Storage) DeleteUserData(ctx context.Context, username string) (storage.UserDataReport, error) {
+	report, err := s.Backend.DeleteUserData(ctx, username)
+	s.invalidate(ctx, report.Aliases...)
+
+	return report, err
+}
+
+// Close closes the cache connection and then the backend.
+func (s *Storage) Close() error {
+	if err := s.client.Close(); err != nil {
+		s.log.Error("failed to close cache connection", sl.Err(err))
+	}
+
+	return s.Backend.Close()
+}
@@ -0,0 +1,29 @@
+package signed
+
+import (
+	"context"
+
+	"url-shortener/internal/lib/alias"
+	"url-shortener/internal/storage"
+	"url-shortener/internal/storage/instrumented"
+)
+
+// Storage answers lookups of aliases without a valid signature as if they
+// did not exist, before they reach the caches or the database, so scans
+// guessing aliases cost the backend nothing.
+type Storage struct {
+	instrumented.Backend
+	signer *alias.Signer
+}
+
+func New(backend instrumented.Backend, signer *alias.Signer) *Storage {
+	return &Storage{Backend: backend, signer: signer}
+}
+
+func (s *Storage) GetURL(ctx context.Context, alias string) (storage.URL, error) {
+	if !s.signer.Verify(alias) {
+		return storage.URL{}, storage.ErrUrlNotFound
+	}
+
+	return s.Backend.GetURL(ctx, alias)
+}
@@ -0,0 +1,39 @@
+package signed_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"url-shortener/internal/lib/alias"
+	"url-shortener/internal/storage"
+	"url-shortener/internal/storage/memory"
+	"url-shortener/internal/storage/signed"
+)
+
+func TestStorage_GetURL(t *testing.T) {
+	ctx := context.Background()
+	signer, err := alias.NewSigner([]byte("0123456789abcdef"), 6, false)
+	require.NoError(t, err)
+
+	backend := memory.New()
+	s := signed.New(backend, signer)
+
+	promo := signer.Sign("promo")
+	_, err = s.SaveURL(ctx, storage.URL{URL: "https://example.com", Alias: promo})
+	require.NoError(t, err)
+	_, err = backend.SaveURL(ctx, storage.URL{URL: "https://example.org", Alias: "legacy"})
+	require.NoError(t, err)
+
+	u, err := s.GetURL(ctx, promo)
+	require.NoError(t, err)
+	require.Equal(t, "https://example.com", u.URL)
+
+	// Stored, but unsigned: rejected without a lookup.
+	_, err = s.GetURL(ctx, "legacy")
+	require.ErrorIs(t, err, storage.ErrUrlNotFound)
+
+	_, err = s.GetURL(ctx, "promo-aaaaaa")
+	require.ErrorIs(t, err, storage.ErrUrlNotFound)
+}
@@ -0,0 +1,116 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"url-shortener/internal/storage"
+)
+
+func (s *Storage) SaveAPIKey(ctx context.Context, key storage.APIKey) (int64, error) {
+	const op = "storage.sqlite.SaveAPIKey"
+
+	res, err := s.db.ExecContext(ctx,
+		"INSERT INTO api_keys(name, owner, key_hash, created_at) VALUES(?, ?, ?, ?)",
+		key.Name, key.Owner, key.Hash, time.Now().UTC(),
+	)
+	if err != nil {
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+
+	id, err := res.LastInsertId()
+	if err != nil {
+		return 0, fmt.Errorf("%s: faild to get last insert id %w", op, err)
+	}
+
+	return id, nil
+}
+
+// GetAPIKeyByHash looks a key up by the hash of its value. Revoked keys are
+// returned as well; callers check APIKey.Revoked.
+func (s *Storage) GetAPIKeyByHash(ctx context.Context, hash string) (storage.APIKey, error) {
+	const op = "storage.sqlite.GetAPIKeyByHash"
+
+	key, err := scanAPIKey(s.db.QueryRowContext(ctx,
+		"SELECT id, name, owner, key_hash, created_at, revoked_at FROM api_keys WHERE key_hash = ?", hash,
+	))
+	if errors.Is(err, sql.ErrNoRows) {
+		return storage.APIKey{}, storage.ErrKeyNotFound
+	}
+	if err != nil {
+		return storage.APIKey{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return key, nil
+}
+
+// ListAPIKeys returns keys ordered by id. A non-empty owner limits the
+// result to that user's keys.
+func (s *Storage) ListAPIKeys(ctx context.Context, owner string) ([]storage.APIKey, error) {
+	const op = "storage.sqlite.ListAPIKeys"
+
+	rows, err := s.db.QueryContext(ctx,
+		"SELECT id, name, owner, key_hash, created_at, revoked_at FROM api_keys WHERE ? = '' OR owner = ? ORDER BY id",
+		owner, owner,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	defer rows.Close()
+
+	keys := []storage.APIKey{}
+	for rows.Next() {
+		key, err := scanAPIKey(rows)
+		if err != nil {
+			return nil, fmt.Errorf("%s: scan row %w", op, err)
+		}
+		keys = append(keys, key)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return keys, nil
+}
+
+// RevokeAPIKey marks a key as revoked. Revoking a revoked key keeps its
+// original revocation time. A non-empty owner restricts revocation to that
+// user's keys.
+func (s *Storage) RevokeAPIKey(ctx context.Context, id int64, owner string) error {
+	const op = "storage.sqlite.RevokeAPIKey"
+
+	res, err := s.db.ExecContext(ctx,
+		"UPDATE api_keys SET revoked_at = COALESCE(revoked_at, ?) WHERE id = ? AND (? = '' OR owner = ?)",
+		time.Now().UTC(), id, owner, owner,
+	)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	rowsAffected, err := res.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("%s: failed to get rows affected: %w", op, err)
+	}
+
+	if rowsAffected == 0 {
+		return fmt.Errorf("%s: %w", op, storage.ErrKeyNotFound)
+	}
+
+	return nil
+}
+
+func scanAPIKey(row interface{ Scan(dest ...any) error }) (storage.APIKey, error) {
+	var (
+		key       storage.APIKey
+		revokedAt sql.NullTime
+	)
+	if err := row.Scan(&key.ID, &key.Name, &key.Owner, &key.Hash, &key.CreatedAt, &revokedAt); err != nil {
+		return storage.APIKey{}, err
+	}
+	key.RevokedAt = revokedAt.Time
+
+	return key, nil
+}
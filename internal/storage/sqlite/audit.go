@@ -0,0 +1,53 @@
+package sqlite
+
+import (
+	"context"
+	"fmt"
+
+	"url-shortener/internal/storage"
+)
+
+func (s *Storage) SaveAuditEntry(ctx context.Context, e storage.AuditEntry) error {
+	const op = "storage.sqlite.SaveAuditEntry"
+
+	_, err := s.db.ExecContext(ctx,
+		`INSERT INTO audit_log(created_at, action, target, actor, api_key_id, ip, before_value, after_value)
+		VALUES(?, ?, ?, ?, ?, ?, ?, ?)`,
+		e.Time.UTC(), e.Action, e.Target, e.Actor, e.APIKeyID, e.IP, e.Before, e.After,
+	)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	return nil
+}
+
+// ListAuditLog returns entries newest first.
+func (s *Storage) ListAuditLog(ctx context.Context, filter storage.AuditFilter, limit int, offset int) ([]storage.AuditEntry, error) {
+	const op = "storage.sqlite.ListAuditLog"
+
+	rows, err := s.db.QueryContext(ctx,
+		`SELECT id, created_at, action, target, actor, api_key_id, ip, before_value, after_value FROM audit_log
+		WHERE (? = '' OR action = ?) AND (? = '' OR target = ?) AND (? = '' OR actor = ?)
+		ORDER BY id DESC LIMIT ? OFFSET ?`,
+		filter.Action, filter.Action, filter.Target, filter.Target, filter.Actor, filter.Actor, limit, offset,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	defer rows.Close()
+
+	entries := []storage.AuditEntry{}
+	for rows.Next() {
+		var e storage.AuditEntry
+		if err := rows.Scan(&e.ID, &e.Time, &e.Action, &e.Target, &e.Actor, &e.APIKeyID, &e.IP, &e.Before, &e.After); err != nil {
+			return nil, fmt.Errorf("%s: scan row %w", op, err)
+		}
+		entries = append(entries, e)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return entries, nil
+}
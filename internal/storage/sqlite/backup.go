@@ -0,0 +1,147 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/mattn/go-sqlite3"
+)
+
+// Backup writes a consistent snapshot of the database to path with the
+// SQLite online backup API, while the database stays in use. The snapshot
+// appears at path only once it is complete.
+func (s *Storage) Backup(ctx context.Context, path string) error {
+	const op = "storage.sqlite.Backup"
+
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	tmp.Close()
+	defer os.Remove(tmp.Name())
+
+	err = s.withConn(ctx, func(live *sqlite3.SQLiteConn) error {
+		snapshot, err := openConn(tmp.Name())
+		if err != nil {
+			return err
+		}
+		defer snapshot.Close()
+
+		return copyDB(snapshot, live)
+	})
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	return nil
+}
+
+// Restore replaces the contents of the database with the snapshot at path
+// and applies the migrations the snapshot is missing.
+func (s *Storage) Restore(ctx context.Context, path string) error {
+	const op = "storage.sqlite.Restore"
+
+	if _, err := os.Stat(path); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	err := s.withConn(ctx, func(live *sqlite3.SQLiteConn) error {
+		snapshot, err := openConn(path)
+		if err != nil {
+			return err
+		}
+		defer snapshot.Close()
+
+		if err := checkIntegrity(snapshot); err != nil {
+			return err
+		}
+
+		return copyDB(live, snapshot)
+	})
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	m, err := s.Migrator(ctx)
+	if err == nil {
+		_, err = m.Up(ctx)
+	}
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	return nil
+}
+
+// withConn runs fn on a driver connection of the pool.
+func (s *Storage) withConn(ctx context.Context, fn func(conn *sqlite3.SQLiteConn) error) error {
+	conn, err := s.db.Conn(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	return conn.Raw(func(dc any) error {
+		sc, ok := dc.(*sqlite3.SQLiteConn)
+		if !ok {
+			return fmt.Errorf("unexpected driver connection %T", dc)
+		}
+		return fn(sc)
+	})
+}
+
+func openConn(path string) (*sqlite3.SQLiteConn, error) {
+	conn, err := (&sqlite3.SQLiteDriver{}).Open(path)
+	if err != nil {
+		return nil, err
+	}
+
+	return conn.(*sqlite3.SQLiteConn), nil
+}
+
+// copyDB copies every page of src to dst in one step, so that dst is never
+// left half-copied.
+func copyDB(dst, src *sqlite3.SQLiteConn) error {
+	b, err := dst.Backup("main", src, "main")
+	if err != nil {
+		return err
+	}
+
+	done, err := b.Step(-1)
+	if err != nil {
+		b.Finish()
+		return err
+	}
+	if !done {
+		b.Finish()
+		return errors.New("backup did not complete")
+	}
+
+	return b.Finish()
+}
+
+func checkIntegrity(conn *sqlite3.SQLiteConn) error {
+	rows, err := conn.Query("PRAGMA quick_check", nil)
+	if err != nil {
+		return fmt.Errorf("not a database: %w", err)
+	}
+	defer rows.Close()
+
+	dest := make([]driver.Value, 1)
+	if err := rows.Next(dest); err != nil {
+		return fmt.Errorf("not a database: %w", err)
+	}
+	if result, _ := dest[0].(string); result != "ok" {
+		return fmt.Errorf("damaged snapshot: %v", dest[0])
+	}
+
+	return nil
+}
@@ -0,0 +1,110 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"url-shortener/internal/storage"
+)
+
+// ReserveIdempotencyKey saves rec unless a record with its key exists, in
+// which case it returns that record and storage.ErrIdempotencyKeyExists.
+// Records that expired by rec.CreatedAt are removed first.
+func (s *Storage) ReserveIdempotencyKey(ctx context.Context, rec storage.IdempotencyRecord) (storage.IdempotencyRecord, error) {
+	const op = "storage.sqlite.ReserveIdempotencyKey"
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return storage.IdempotencyRecord{}, fmt.Errorf("%s: %w", op, err)
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, `DELETE FROM idempotency_keys WHERE expires_at <= ?`, rec.CreatedAt.UTC()); err != nil {
+		return storage.IdempotencyRecord{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	res, err := tx.ExecContext(ctx,
+		`INSERT INTO idempotency_keys(idempotency_key, request_hash, status, content_type, body, created_at, expires_at)
+		VALUES(?, ?, ?, ?, ?, ?, ?) ON CONFLICT(idempotency_key) DO NOTHING`,
+		rec.Key, rec.RequestHash, rec.Status, rec.ContentType, rec.Body, rec.CreatedAt.UTC(), rec.ExpiresAt.UTC(),
+	)
+	if err != nil {
+		return storage.IdempotencyRecord{}, fmt.Errorf("%s: %w", op, err)
+	}
+	inserted, err := res.RowsAffected()
+	if err != nil {
+		return storage.IdempotencyRecord{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	if inserted > 0 {
+		if err := tx.Commit(); err != nil {
+			return storage.IdempotencyRecord{}, fmt.Errorf("%s: %w", op, err)
+		}
+		return rec, nil
+	}
+
+	var existing storage.IdempotencyRecord
+	err = tx.QueryRowContext(ctx,
+		`SELECT idempotency_key, request_hash, status, content_type, body, created_at, expires_at
+		FROM idempotency_keys WHERE idempotency_key = ?`,
+		rec.Key,
+	).Scan(&existing.Key, &existing.RequestHash, &existing.Status, &existing.ContentType, &existing.Body, &existing.CreatedAt, &existing.ExpiresAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		// Released by the request that held it since the insert.
+		return storage.IdempotencyRecord{}, fmt.Errorf("%s: key released concurrently: %w", op, storage.ErrIdempotencyKeyExists)
+	}
+	if err != nil {
+		return storage.IdempotencyRecord{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return existing, fmt.Errorf("%s: %w", op, storage.ErrIdempotencyKeyExists)
+}
+
+// CompleteIdempotencyKey stores the response to the request that reserved
+// key.
+func (s *Storage) CompleteIdempotencyKey(ctx context.Context, key string, status int, contentType string, body string) error {
+	const op = "storage.sqlite.CompleteIdempotencyKey"
+
+	_, err := s.db.ExecContext(ctx,
+		`UPDATE idempotency_keys SET status = ?, content_type = ?, body = ? WHERE idempotency_key = ?`,
+		status, contentType, body, key,
+	)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	return nil
+}
+
+// ReleaseIdempotencyKey removes key, so that the request can be retried.
+func (s *Storage) ReleaseIdempotencyKey(ctx context.Context, key string) error {
+	const op = "storage.sqlite.ReleaseIdempotencyKey"
+
+	if _, err := s.db.ExecContext(ctx, `DELETE FROM idempotency_keys WHERE idempotency_key = ?`, key); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	return nil
+}
+
+// PruneIdempotencyKeys removes records that expired by now and returns how
+// many there were. Expired records are also removed as new keys come in;
+// this keeps the table small when they stop coming.
+func (s *Storage) PruneIdempotencyKeys(ctx context.Context, now time.Time) (int64, error) {
+	const op = "storage.sqlite.PruneIdempotencyKeys"
+
+	res, err := s.db.ExecContext(ctx, `DELETE FROM idempotency_keys WHERE expires_at <= ?`, now.UTC())
+	if err != nil {
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+
+	n, err := res.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return n, nil
+}
@@ -0,0 +1,84 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+)
+
+// upgradeLegacy adds the columns that were added to existing tables
+// before migrations, so that the first migration, which only creates what
+// is missing, finds the tables complete. Fresh databases are left alone.
+func upgradeLegacy(ctx context.Context, db *sql.DB) error {
+	columns := []struct{ table, name, def string }{
+		{"url", "state", "TEXT NOT NULL DEFAULT 'active'"},
+		{"url", "block_reason", "TEXT NOT NULL DEFAULT ''"},
+		{"url", "block_reference", "TEXT NOT NULL DEFAULT ''"},
+		{"url", "created_at", "TIMESTAMP"},
+		{"url", "expires_at", "TIMESTAMP"},
+		{"url", "max_clicks", "INTEGER NOT NULL DEFAULT 0"},
+		{"url", "click_count", "INTEGER NOT NULL DEFAULT 0"},
+		{"url", "password_hash", "TEXT NOT NULL DEFAULT ''"},
+		{"url", "api_key_id", "INTEGER NOT NULL DEFAULT 0"},
+		{"url", "owner", "TEXT NOT NULL DEFAULT ''"},
+		{"url", "redirect_type", "INTEGER NOT NULL DEFAULT 0"},
+		{"url", "title", "TEXT NOT NULL DEFAULT ''"},
+		{"url", "description", "TEXT NOT NULL DEFAULT ''"},
+		{"url", "image_url", "TEXT NOT NULL DEFAULT ''"},
+		{"url", "deleted_at", "TIMESTAMP"},
+		{"url", "utm_source", "TEXT NOT NULL DEFAULT ''"},
+		{"url", "utm_medium", "TEXT NOT NULL DEFAULT ''"},
+		{"url", "utm_campaign", "TEXT NOT NULL DEFAULT ''"},
+		{"url", "geo_targets", "TEXT NOT NULL DEFAULT ''"},
+		{"url", "device_targets", "TEXT NOT NULL DEFAULT ''"},
+		{"url", "variants", "TEXT NOT NULL DEFAULT ''"},
+		{"url", "active_from", "TIMESTAMP"},
+		{"url", "active_until", "TIMESTAMP"},
+		{"clicks", "variant", "TEXT NOT NULL DEFAULT ''"},
+	}
+	for _, c := range columns {
+		if err := addColumn(ctx, db, c.table, c.name, c.def); err != nil {
+			return fmt.Errorf("add %s.%s: %w", c.table, c.name, err)
+		}
+	}
+
+	return nil
+}
+
+// addColumn adds a column to table unless the table is missing or already
+// has it.
+func addColumn(ctx context.Context, db *sql.DB, table, column, def string) error {
+	rows, err := db.QueryContext(ctx, fmt.Sprintf("PRAGMA table_info(%s)", table))
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	found := false
+	for rows.Next() {
+		var (
+			cid       int
+			name      string
+			colType   string
+			notNull   int
+			dfltValue sql.NullString
+			pk        int
+		)
+		if err := rows.Scan(&cid, &name, &colType, &notNull, &dfltValue, &pk); err != nil {
+			return err
+		}
+		if name == column {
+			return nil
+		}
+		found = true
+	}
+	if err := rows.Err(); err != nil {
+		return err
+	}
+	if !found {
+		return nil
+	}
+
+	_, err = db.ExecContext(ctx, fmt.Sprintf("ALTER TABLE %s ADD COLUMN %s %s", table, column, def))
+	return err
+}
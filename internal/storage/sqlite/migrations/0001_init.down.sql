@@ -0,0 +1,7 @@
+DROP TABLE IF EXISTS sequences;
+DROP TABLE IF EXISTS audit_log;
+DROP TABLE IF EXISTS url_tag;
+DROP TABLE IF EXISTS users;
+DROP TABLE IF EXISTS api_keys;
+DROP TABLE IF EXISTS clicks;
+DROP TABLE IF EXISTS url;
@@ -0,0 +1,94 @@
+-- The schema as it was when migrations were introduced. Every statement is
+-- idempotent so that databases created before then can be adopted.
+CREATE TABLE IF NOT EXISTS url(
+	id INTEGER PRIMARY KEY,
+	alias TEXT NOT NULL UNIQUE,
+	url TEXT NOT NULL,
+	state TEXT NOT NULL DEFAULT 'active',
+	block_reason TEXT NOT NULL DEFAULT '',
+	block_reference TEXT NOT NULL DEFAULT '',
+	created_at TIMESTAMP,
+	expires_at TIMESTAMP,
+	max_clicks INTEGER NOT NULL DEFAULT 0,
+	click_count INTEGER NOT NULL DEFAULT 0,
+	password_hash TEXT NOT NULL DEFAULT '',
+	api_key_id INTEGER NOT NULL DEFAULT 0,
+	owner TEXT NOT NULL DEFAULT '',
+	redirect_type INTEGER NOT NULL DEFAULT 0,
+	title TEXT NOT NULL DEFAULT '',
+	description TEXT NOT NULL DEFAULT '',
+	image_url TEXT NOT NULL DEFAULT '',
+	deleted_at TIMESTAMP,
+	utm_source TEXT NOT NULL DEFAULT '',
+	utm_medium TEXT NOT NULL DEFAULT '',
+	utm_campaign TEXT NOT NULL DEFAULT '',
+	geo_targets TEXT NOT NULL DEFAULT '',
+	device_targets TEXT NOT NULL DEFAULT '',
+	variants TEXT NOT NULL DEFAULT '',
+	active_from TIMESTAMP,
+	active_until TIMESTAMP);
+CREATE INDEX IF NOT EXISTS idx_alias ON url(alias);
+CREATE INDEX IF NOT EXISTS idx_url_owner ON url(owner);
+CREATE INDEX IF NOT EXISTS idx_url_owner_url ON url(owner, url);
+CREATE INDEX IF NOT EXISTS idx_url_deleted_at ON url(deleted_at);
+CREATE INDEX IF NOT EXISTS idx_url_created_at ON url(created_at);
+CREATE INDEX IF NOT EXISTS idx_url_owner_created_at ON url(owner, created_at);
+
+CREATE TABLE IF NOT EXISTS clicks(
+	id INTEGER PRIMARY KEY,
+	alias TEXT NOT NULL,
+	clicked_at TIMESTAMP NOT NULL,
+	referrer TEXT NOT NULL DEFAULT '',
+	user_agent TEXT NOT NULL DEFAULT '',
+	variant TEXT NOT NULL DEFAULT '');
+CREATE INDEX IF NOT EXISTS idx_clicks_alias_clicked_at ON clicks(alias, clicked_at);
+CREATE TRIGGER IF NOT EXISTS trg_url_delete_clicks AFTER DELETE ON url
+BEGIN
+	DELETE FROM clicks WHERE alias = OLD.alias;
+END;
+CREATE TRIGGER IF NOT EXISTS trg_url_rename_clicks AFTER UPDATE OF alias ON url
+BEGIN
+	UPDATE clicks SET alias = NEW.alias WHERE alias = OLD.alias;
+END;
+
+CREATE TABLE IF NOT EXISTS api_keys(
+	id INTEGER PRIMARY KEY,
+	name TEXT NOT NULL,
+	owner TEXT NOT NULL DEFAULT '',
+	key_hash TEXT NOT NULL UNIQUE,
+	created_at TIMESTAMP NOT NULL,
+	revoked_at TIMESTAMP);
+
+CREATE TABLE IF NOT EXISTS users(
+	id INTEGER PRIMARY KEY,
+	username TEXT NOT NULL UNIQUE,
+	password_hash TEXT NOT NULL,
+	role TEXT NOT NULL DEFAULT 'user',
+	created_at TIMESTAMP NOT NULL);
+
+CREATE TABLE IF NOT EXISTS url_tag(
+	url_id INTEGER NOT NULL,
+	tag TEXT NOT NULL,
+	PRIMARY KEY (tag, url_id));
+CREATE INDEX IF NOT EXISTS idx_url_tag_url_id ON url_tag(url_id);
+CREATE TRIGGER IF NOT EXISTS trg_url_delete_tags AFTER DELETE ON url
+BEGIN
+	DELETE FROM url_tag WHERE url_id = OLD.id;
+END;
+
+CREATE TABLE IF NOT EXISTS audit_log(
+	id INTEGER PRIMARY KEY,
+	created_at TIMESTAMP NOT NULL,
+	action TEXT NOT NULL,
+	target TEXT NOT NULL,
+	actor TEXT NOT NULL DEFAULT '',
+	api_key_id INTEGER NOT NULL DEFAULT 0,
+	ip TEXT NOT NULL DEFAULT '',
+	before_value TEXT NOT NULL DEFAULT '',
+	after_value TEXT NOT NULL DEFAULT '');
+CREATE INDEX IF NOT EXISTS idx_audit_log_target ON audit_log(target);
+CREATE INDEX IF NOT EXISTS idx_audit_log_actor ON audit_log(actor);
+
+CREATE TABLE IF NOT EXISTS sequences(
+	name TEXT PRIMARY KEY,
+	value INTEGER NOT NULL);
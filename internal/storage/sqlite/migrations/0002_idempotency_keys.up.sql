@@ -0,0 +1,9 @@
+CREATE TABLE idempotency_keys(
+	idempotency_key TEXT PRIMARY KEY,
+	request_hash TEXT NOT NULL,
+	status INTEGER NOT NULL DEFAULT 0,
+	content_type TEXT NOT NULL DEFAULT '',
+	body TEXT NOT NULL DEFAULT '',
+	created_at TIMESTAMP NOT NULL,
+	expires_at TIMESTAMP NOT NULL);
+CREATE INDEX idx_idempotency_keys_expires_at ON idempotency_keys(expires_at);
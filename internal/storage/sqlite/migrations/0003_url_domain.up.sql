@@ -0,0 +1 @@
+ALTER TABLE url ADD COLUMN domain TEXT NOT NULL DEFAULT '';
@@ -0,0 +1,4 @@
+DROP TRIGGER IF EXISTS trg_url_rename_click_rollups;
+DROP TRIGGER IF EXISTS trg_url_delete_click_rollups;
+DROP TABLE IF EXISTS click_rollup_state;
+DROP TABLE IF EXISTS click_rollups;
@@ -0,0 +1,20 @@
+-- Clicks of the UTC days before rolled_until are summed up in
+-- click_rollups; GetStats counts only later ones from clicks.
+CREATE TABLE click_rollups(
+	alias TEXT NOT NULL,
+	day TEXT NOT NULL,
+	variant TEXT NOT NULL DEFAULT '',
+	clicks INTEGER NOT NULL,
+	PRIMARY KEY (alias, day, variant));
+CREATE TABLE click_rollup_state(
+	id INTEGER PRIMARY KEY CHECK (id = 1),
+	rolled_until TIMESTAMP NOT NULL);
+INSERT INTO click_rollup_state(id, rolled_until) VALUES (1, '1970-01-01 00:00:00+00:00');
+CREATE TRIGGER trg_url_delete_click_rollups AFTER DELETE ON url
+BEGIN
+	DELETE FROM click_rollups WHERE alias = OLD.alias;
+END;
+CREATE TRIGGER trg_url_rename_click_rollups AFTER UPDATE OF alias ON url
+BEGIN
+	UPDATE click_rollups SET alias = NEW.alias WHERE alias = OLD.alias;
+END;
@@ -0,0 +1,2 @@
+ALTER TABLE click_rollups DROP COLUMN bot_clicks;
+ALTER TABLE clicks DROP COLUMN bot;
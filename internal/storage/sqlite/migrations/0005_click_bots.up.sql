@@ -0,0 +1,3 @@
+-- Clicks saved before bots were told apart count as human ones.
+ALTER TABLE clicks ADD COLUMN bot INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE click_rollups ADD COLUMN bot_clicks INTEGER NOT NULL DEFAULT 0;
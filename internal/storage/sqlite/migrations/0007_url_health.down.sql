@@ -0,0 +1,3 @@
+ALTER TABLE url DROP COLUMN health_checked_at;
+ALTER TABLE url DROP COLUMN health_status_code;
+ALTER TABLE url DROP COLUMN health;
@@ -0,0 +1 @@
+ALTER TABLE url DROP COLUMN cache_max_age;
@@ -0,0 +1 @@
+ALTER TABLE url ADD COLUMN cache_max_age INTEGER NOT NULL DEFAULT 0;
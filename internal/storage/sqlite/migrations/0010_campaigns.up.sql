@@ -0,0 +1,14 @@
+CREATE TABLE campaign(
+	id INTEGER PRIMARY KEY,
+	name TEXT NOT NULL,
+	owner TEXT NOT NULL DEFAULT '',
+	utm_source TEXT NOT NULL DEFAULT '',
+	utm_medium TEXT NOT NULL DEFAULT '',
+	utm_campaign TEXT NOT NULL DEFAULT '',
+	expires_at TIMESTAMP,
+	domain TEXT NOT NULL DEFAULT '',
+	created_at TIMESTAMP NOT NULL);
+CREATE INDEX idx_campaign_owner ON campaign(owner);
+-- 0 is a link outside campaigns.
+ALTER TABLE url ADD COLUMN campaign_id INTEGER NOT NULL DEFAULT 0;
+CREATE INDEX idx_url_campaign_id ON url(campaign_id);
@@ -0,0 +1 @@
+ALTER TABLE url ADD COLUMN imported_clicks INTEGER NOT NULL DEFAULT 0;
@@ -0,0 +1,152 @@
+package sqlite_test
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"url-shortener/internal/storage"
+	"url-shortener/internal/storage/sqlite"
+)
+
+func newStorage(t *testing.T) *sqlite.Storage {
+	t.Helper()
+
+	s, err := sqlite.New(filepath.Join(t.TempDir(), "storage.db"), sqlite.Options{})
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = s.Close() })
+
+	return s
+}
+
+// saveClicks stores a link with two clicks, one of them a bot's, on each
+// of the days from March 1st to 3rd 2025. The clicks of the 2nd and 3rd
+// fall exactly on midnight and just before the next one.
+func saveClicks(t *testing.T, s *sqlite.Storage) {
+	t.Helper()
+
+	ctx := context.Background()
+	_, err := s.SaveURL(ctx, storage.URL{URL: "https://example.com", Alias: "promo"})
+	require.NoError(t, err)
+
+	for _, c := range []storage.Click{
+		{ClickedAt: time.Date(2025, 3, 1, 10, 0, 0, 0, time.UTC)},
+		{ClickedAt: time.Date(2025, 3, 1, 12, 0, 0, 0, time.UTC), Bot: true},
+		{ClickedAt: time.Date(2025, 3, 2, 0, 0, 0, 0, time.UTC)},
+		{ClickedAt: time.Date(2025, 3, 2, 23, 59, 59, 999_000_000, time.UTC), Bot: true},
+		{ClickedAt: time.Date(2025, 3, 3, 0, 0, 0, 0, time.UTC), Bot: true},
+		{ClickedAt: time.Date(2025, 3, 3, 8, 0, 0, 0, time.UTC)},
+	} {
+		c.Alias = "promo"
+		require.NoError(t, s.SaveClick(ctx, c))
+	}
+}
+
+var (
+	since = time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC)
+	today = time.Date(2025, 3, 3, 9, 0, 0, 0, time.UTC)
+)
+
+func TestStorage_RollupClicks(t *testing.T) {
+	s := newStorage(t)
+	ctx := context.Background()
+	saveClicks(t, s)
+
+	humans, err := s.GetStats(ctx, "promo", since, false)
+	require.NoError(t, err)
+	require.Equal(t, int64(3), humans.Total)
+	require.Equal(t, []storage.DailyClicks{
+		{Date: "2025-03-01", Clicks: 1},
+		{Date: "2025-03-02", Clicks: 1},
+		{Date: "2025-03-03", Clicks: 1},
+	}, humans.Daily)
+
+	all, err := s.GetStats(ctx, "promo", since, true)
+	require.NoError(t, err)
+	require.Equal(t, int64(6), all.Total)
+	require.Equal(t, []storage.DailyClicks{
+		{Date: "2025-03-01", Clicks: 2},
+		{Date: "2025-03-02", Clicks: 2},
+		{Date: "2025-03-03", Clicks: 2},
+	}, all.Daily)
+
+	// Rolling up the same days again must not count them twice, and the
+	// bot_clicks of the rollups keep bots out of the default counts.
+	for range 2 {
+		require.NoError(t, s.RollupClicks(ctx, today))
+
+		got, err := s.GetStats(ctx, "promo", since, false)
+		require.NoError(t, err)
+		require.Equal(t, humans, got)
+
+		got, err = s.GetStats(ctx, "promo", since, true)
+		require.NoError(t, err)
+		require.Equal(t, all, got)
+	}
+
+	// An earlier day than the one rolled up to is a no-op as well.
+	require.NoError(t, s.RollupClicks(ctx, since))
+	got, err := s.GetStats(ctx, "promo", since, true)
+	require.NoError(t, err)
+	require.Equal(t, all, got)
+}
+
+func TestStorage_RollupClicks_MergesRawClicks(t *testing.T) {
+	s := newStorage(t)
+	ctx := context.Background()
+	saveClicks(t, s)
+
+	require.NoError(t, s.RollupClicks(ctx, today))
+
+	// Clicks of today are not rolled up yet and are counted one by one.
+	require.NoError(t, s.SaveClick(ctx, storage.Click{Alias: "promo", ClickedAt: today}))
+
+	stats, err := s.GetStats(ctx, "promo", since, false)
+	require.NoError(t, err)
+	require.Equal(t, int64(4), stats.Total)
+	require.Equal(t, today, stats.LastAccess.UTC())
+	require.Equal(t, []storage.DailyClicks{
+		{Date: "2025-03-01", Clicks: 1},
+		{Date: "2025-03-02", Clicks: 1},
+		{Date: "2025-03-03", Clicks: 2},
+	}, stats.Daily)
+
+	// since leaves out the rolled-up days before it.
+	stats, err = s.GetStats(ctx, "promo", since.AddDate(0, 0, 1), true)
+	require.NoError(t, err)
+	require.Equal(t, int64(7), stats.Total)
+	require.Equal(t, []storage.DailyClicks{
+		{Date: "2025-03-02", Clicks: 2},
+		{Date: "2025-03-03", Clicks: 3},
+	}, stats.Daily)
+
+	// Once today is rolled up too, the counts stay the same.
+	require.NoError(t, s.RollupClicks(ctx, today.AddDate(0, 0, 1)))
+
+	rolled, err := s.GetStats(ctx, "promo", since.AddDate(0, 0, 1), true)
+	require.NoError(t, err)
+	require.Equal(t, stats, rolled)
+}
+
+func TestStorage_ConsumeClick(t *testing.T) {
+	s := newStorage(t)
+	ctx := context.Background()
+
+	_, err := s.SaveURL(ctx, storage.URL{URL: "https://example.com", Alias: "twice", MaxClicks: 2})
+	require.NoError(t, err)
+
+	require.NoError(t, s.ConsumeClick(ctx, "twice"))
+
+	// A deleted link is gone, as for GetURL, and its counter is kept.
+	require.NoError(t, s.DeleteURL(ctx, "twice", ""))
+	require.ErrorIs(t, s.ConsumeClick(ctx, "twice"), storage.ErrUrlNotFound)
+
+	require.NoError(t, s.RestoreURL(ctx, "twice", ""))
+	require.NoError(t, s.ConsumeClick(ctx, "twice"))
+	require.ErrorIs(t, s.ConsumeClick(ctx, "twice"), storage.ErrUrlExhausted)
+
+	require.ErrorIs(t, s.ConsumeClick(ctx, "missing"), storage.ErrUrlNotFound)
+}
@@ -0,0 +1,154 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/mattn/go-sqlite3"
+
+	"url-shortener/internal/storage"
+)
+
+func (s *Storage) SaveUser(ctx context.Context, user storage.User) (int64, error) {
+	const op = "storage.sqlite.SaveUser"
+
+	res, err := s.db.ExecContext(ctx,
+		"INSERT INTO users(username, password_hash, role, created_at) VALUES(?, ?, ?, ?)",
+		user.Username, user.PasswordHash, user.Role, time.Now().UTC(),
+	)
+	if err != nil {
+		if sqliteErr, ok := err.(sqlite3.Error); ok && sqliteErr.ExtendedCode == sqlite3.ErrConstraintUnique {
+			return 0, fmt.Errorf("%s: %w", op, storage.ErrUserExists)
+		}
+
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+
+	id, err := res.LastInsertId()
+	if err != nil {
+		return 0, fmt.Errorf("%s: faild to get last insert id %w", op, err)
+	}
+
+	return id, nil
+}
+
+func (s *Storage) GetUser(ctx context.Context, username string) (storage.User, error) {
+	const op = "storage.sqlite.GetUser"
+
+	var user storage.User
+	err := s.db.QueryRowContext(ctx,
+		"SELECT id, username, password_hash, role, created_at FROM users WHERE username = ?", username,
+	).Scan(&user.ID, &user.Username, &user.PasswordHash, &user.Role, &user.CreatedAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return storage.User{}, storage.ErrUserNotFound
+	}
+	if err != nil {
+		return storage.User{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return user, nil
+}
+
+// DeleteUserData removes the links of username with their clicks, their API
+// keys, their campaigns and their account, and erases them from the audit
+// log, all in one transaction.
+func (s *Storage) DeleteUserData(ctx context.Context, username string) (storage.UserDataReport, error) {
+	const op = "storage.sqlite.DeleteUserData"
+
+	// Links without an owner would all match.
+	if username == "" {
+		return storage.UserDataReport{}, fmt.Errorf("%s: empty username", op)
+	}
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return storage.UserDataReport{}, fmt.Errorf("%s: %w", op, err)
+	}
+	defer tx.Rollback()
+
+	var report storage.UserDataReport
+
+	// Before the links go, while they still tell which entries are about
+	// them.
+	res, err := tx.ExecContext(ctx, `
+	UPDATE audit_log SET
+		actor = CASE WHEN actor = ?1 THEN '' ELSE actor END,
+		ip = CASE WHEN actor = ?1 THEN '' ELSE ip END,
+		target = CASE WHEN target = ?1 THEN '' ELSE target END,
+		before_value = '',
+		after_value = ''
+	WHERE actor = ?1 OR target = ?1 OR target IN (SELECT alias FROM url WHERE owner = ?1)`, username)
+	if err != nil {
+		return storage.UserDataReport{}, fmt.Errorf("%s: %w", op, err)
+	}
+	if report.AuditEntries, err = res.RowsAffected(); err != nil {
+		return storage.UserDataReport{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	err = tx.QueryRowContext(ctx,
+		"SELECT COUNT(*) FROM clicks WHERE alias IN (SELECT alias FROM url WHERE owner = ?)", username,
+	).Scan(&report.Clicks)
+	if err != nil {
+		return storage.UserDataReport{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	// Triggers take the clicks, rollups and tags along.
+	rows, err := tx.QueryContext(ctx, "DELETE FROM url WHERE owner = ? RETURNING alias", username)
+	if err != nil {
+		return storage.UserDataReport{}, fmt.Errorf("%s: %w", op, err)
+	}
+	for rows.Next() {
+		var alias string
+		if err := rows.Scan(&alias); err != nil {
+			rows.Close()
+			return storage.UserDataReport{}, fmt.Errorf("%s: %w", op, err)
+		}
+		report.Aliases = append(report.Aliases, alias)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return storage.UserDataReport{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	// Links of other users stay but leave the campaigns.
+	_, err = tx.ExecContext(ctx,
+		"UPDATE url SET campaign_id = 0 WHERE campaign_id IN (SELECT id FROM campaign WHERE owner = ?)", username,
+	)
+	if err != nil {
+		return storage.UserDataReport{}, fmt.Errorf("%s: %w", op, err)
+	}
+	res, err = tx.ExecContext(ctx, "DELETE FROM campaign WHERE owner = ?", username)
+	if err != nil {
+		return storage.UserDataReport{}, fmt.Errorf("%s: %w", op, err)
+	}
+	if report.Campaigns, err = res.RowsAffected(); err != nil {
+		return storage.UserDataReport{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	res, err = tx.ExecContext(ctx, "DELETE FROM api_keys WHERE owner = ?", username)
+	if err != nil {
+		return storage.UserDataReport{}, fmt.Errorf("%s: %w", op, err)
+	}
+	if report.APIKeys, err = res.RowsAffected(); err != nil {
+		return storage.UserDataReport{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	res, err = tx.ExecContext(ctx, "DELETE FROM users WHERE username = ?", username)
+	if err != nil {
+		return storage.UserDataReport{}, fmt.Errorf("%s: %w", op, err)
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return storage.UserDataReport{}, fmt.Errorf("%s: %w", op, err)
+	}
+	report.User = n > 0
+
+	if err := tx.Commit(); err != nil {
+		return storage.UserDataReport{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return report, nil
+}
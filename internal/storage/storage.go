@@ -1,10 +1,697 @@
 package storage
 
 import (
+	"cmp"
+	"database/sql/driver"
+	"encoding/json"
 	"errors"
+	"fmt"
+	"net/url"
+	"slices"
+	"strings"
+	"time"
 )
 
 var (
 	ErrUrlNotFound = errors.New("url not found")
 	ErrUrlExists   = errors.New("url exists")
+	ErrUrlBlocked  = errors.New("url blocked for legal reasons")
+	// ErrUrlUnsafe is returned for links whose target was flagged as
+	// malware or phishing.
+	ErrUrlUnsafe  = errors.New("url flagged as unsafe")
+	ErrUrlExpired = errors.New("url expired")
+	// ErrUrlExhausted is returned for one-time links that used up max_clicks.
+	ErrUrlExhausted = errors.New("url click limit reached")
+	ErrKeyNotFound  = errors.New("api key not found")
+	ErrUserNotFound = errors.New("user not found")
+	ErrUserExists   = errors.New("user exists")
+	// ErrCampaignNotFound is also returned for campaigns of other users.
+	ErrCampaignNotFound = errors.New("campaign not found")
+	// ErrIdempotencyKeyExists is returned by ReserveIdempotencyKey for keys
+	// that were already used and have not expired.
+	ErrIdempotencyKeyExists = errors.New("idempotency key exists")
 )
+
+// Expected reports whether err is an outcome of a call rather than a
+// failure, e.g. a missing alias; such errors are not counted or traced as
+// storage errors.
+func Expected(err error) bool {
+	for _, target := range []error{
+		ErrUrlNotFound,
+		ErrUrlExists,
+		ErrUrlBlocked,
+		ErrUrlUnsafe,
+		ErrUrlExpired,
+		ErrUrlExhausted,
+		ErrKeyNotFound,
+		ErrUserNotFound,
+		ErrUserExists,
+		ErrCampaignNotFound,
+		ErrIdempotencyKeyExists,
+	} {
+		if errors.Is(err, target) {
+			return true
+		}
+	}
+
+	return false
+}
+
+const (
+	StateActive       = "active"
+	StateBlockedLegal = "blocked_legal"
+	// StateBlockedUnsafe marks links flagged by a URL reputation check.
+	StateBlockedUnsafe = "blocked_unsafe"
+)
+
+// Health statuses of link destinations, set by the dead-link checker.
+const (
+	HealthOK     = "ok"
+	HealthBroken = "broken"
+	// HealthRedirectLoop marks destinations that redirect in a loop or
+	// through too many hops to ever arrive.
+	HealthRedirectLoop = "redirect_loop"
+)
+
+// Health is the outcome of the last check of a link destination. It is
+// zero for links that were not checked yet.
+type Health struct {
+	Status string
+	// StatusCode is the HTTP status of the response, 0 if there was none.
+	StatusCode int
+	CheckedAt  time.Time
+}
+
+// Roles, from least to most privileged: viewers read every link and its
+// stats, editors create and change their own links, admins manage
+// everything.
+const (
+	RoleViewer = "viewer"
+	RoleEditor = "editor"
+	RoleAdmin  = "admin"
+	// RoleUser is what editors were called before viewers existed; it is
+	// kept for stored accounts and issued tokens and means RoleEditor.
+	RoleUser = "user"
+)
+
+// LegalBlock describes why an alias was taken down.
+type LegalBlock struct {
+	Reason    string
+	Reference string
+}
+
+// URL is a stored short link.
+type URL struct {
+	Alias string
+	URL   string
+	// Domain is the short domain the link was created on; empty means the
+	// default one.
+	Domain    string
+	CreatedAt time.Time
+	// ExpiresAt is zero for links that never expire.
+	ExpiresAt time.Time
+	// ActiveFrom and ActiveUntil bound the window in which the link
+	// redirects; zero leaves that side open. Unlike expired links, links
+	// outside their window are kept.
+	ActiveFrom  time.Time
+	ActiveUntil time.Time
+	// MaxClicks is the number of redirects after which the link stops
+	// working; 0 means unlimited.
+	MaxClicks int64
+	// ImportedClicks is the number of clicks the link had in the shortener
+	// it was imported from. SaveURLs stores it; GetStats adds it to the
+	// total, but not to any day.
+	ImportedClicks int64
+	// PasswordHash is the bcrypt hash of the link password, empty for
+	// links that are not protected.
+	PasswordHash string
+	// APIKeyID is the API key the link was created with, 0 if none.
+	APIKeyID int64
+	// Owner is the name of the user who created the link. Links created
+	// before users were introduced have no owner and are visible to admins
+	// only.
+	Owner string
+	// RedirectType is the HTTP status of the redirect (301, 302, 307 or
+	// 308); 0 means the server default.
+	RedirectType int
+	// CacheMaxAge is how many seconds browsers and CDNs may cache the
+	// redirect; 0 means the server default and -1 forbids caching.
+	CacheMaxAge int
+	// Metadata describes the target page; it is empty unless fetching is
+	// enabled.
+	Metadata Metadata
+	// OpenGraph is the preview social networks show for the link, set by
+	// its owner; crawlers of links with one get a page with these tags
+	// instead of the redirect.
+	OpenGraph Metadata
+	// UTM is appended to URL on redirect.
+	UTM UTM
+	// GeoTargets overrides URL for visitors from the listed countries,
+	// keyed by ISO 3166-1 alpha-2 code.
+	GeoTargets Targets
+	// DeviceTargets overrides URL for visitors on the listed kinds of
+	// devices, keyed by DeviceIOS, DeviceAndroid, DeviceMobile or
+	// DeviceDesktop. It takes precedence over GeoTargets.
+	DeviceTargets Targets
+	// Variants split the visitors getting URL between several weighted
+	// destinations for A/B tests.
+	Variants Variants
+	// CampaignID is the campaign the link was created in, 0 if none.
+	CampaignID int64
+	// Tags group links, e.g. by campaign or team. They are set by SaveURL
+	// and returned by ListURLs, sorted.
+	Tags []string
+	// Health is returned by ListURLs.
+	Health Health
+	// State is StateActive or the reason the link is blocked; it is
+	// returned by ListURLs.
+	State string
+}
+
+// Variant is one destination of a split-test link. Clicks record the Name
+// of the variant they were sent to.
+type Variant struct {
+	Name   string `json:"name"`
+	URL    string `json:"url"`
+	Weight int    `json:"weight"`
+}
+
+// Variants are stored as JSON text like Targets, empty for links that are
+// not split-tested.
+type Variants []Variant
+
+// Pick returns the variant that n falls on when the variants are laid out
+// end to end by weight; n must be in [0, TotalWeight()).
+func (v Variants) Pick(n int) Variant {
+	for _, variant := range v {
+		if n < variant.Weight {
+			return variant
+		}
+		n -= variant.Weight
+	}
+
+	return v[len(v)-1]
+}
+
+func (v Variants) TotalWeight() int {
+	total := 0
+	for _, variant := range v {
+		total += variant.Weight
+	}
+
+	return total
+}
+
+func (v Variants) Value() (driver.Value, error) {
+	if len(v) == 0 {
+		return "", nil
+	}
+
+	data, err := json.Marshal([]Variant(v))
+	if err != nil {
+		return nil, err
+	}
+
+	return string(data), nil
+}
+
+func (v *Variants) Scan(src any) error {
+	var data []byte
+	switch s := src.(type) {
+	case nil:
+	case string:
+		data = []byte(s)
+	case []byte:
+		data = s
+	default:
+		return fmt.Errorf("storage: cannot scan %T into Variants", src)
+	}
+
+	*v = nil
+	if len(data) == 0 {
+		return nil
+	}
+
+	return json.Unmarshal(data, (*[]Variant)(v))
+}
+
+// Targets maps a visitor class, a country code for GeoTargets or a device
+// class for DeviceTargets, to the URL visitors of that class are sent to.
+// SQL backends store it as JSON text, empty for links without targets.
+type Targets map[string]string
+
+func (t Targets) Value() (driver.Value, error) {
+	if len(t) == 0 {
+		return "", nil
+	}
+
+	data, err := json.Marshal(map[string]string(t))
+	if err != nil {
+		return nil, err
+	}
+
+	return string(data), nil
+}
+
+func (t *Targets) Scan(src any) error {
+	var data []byte
+	switch v := src.(type) {
+	case nil:
+	case string:
+		data = []byte(v)
+	case []byte:
+		data = v
+	default:
+		return fmt.Errorf("storage: cannot scan %T into Targets", src)
+	}
+
+	*t = nil
+	if len(data) == 0 {
+		return nil
+	}
+
+	return json.Unmarshal(data, (*map[string]string)(t))
+}
+
+// Device classes of DeviceTargets. iOS and Android devices fall back to
+// the DeviceMobile target when the link has none for their platform.
+const (
+	DeviceIOS     = "ios"
+	DeviceAndroid = "android"
+	DeviceMobile  = "mobile"
+	DeviceDesktop = "desktop"
+)
+
+// UTM holds the utm_* query parameters of a link. Empty values are not
+// added.
+type UTM struct {
+	Source   string
+	Medium   string
+	Campaign string
+}
+
+// Metadata is what the target page says about itself in its <title> and
+// Open Graph tags, captured when the link is saved. Image is an absolute
+// URL.
+type Metadata struct {
+	Title       string
+	Description string
+	Image       string
+}
+
+// ForwardURL is the target of the link RenameURL leaves behind at an old
+// alias: the path of the new alias on this service.
+func ForwardURL(alias string) string {
+	return "/" + url.PathEscape(alias)
+}
+
+// Expired reports whether the link has expired at the given moment.
+func (u URL) Expired(now time.Time) bool {
+	return !u.ExpiresAt.IsZero() && !now.Before(u.ExpiresAt)
+}
+
+// NotActiveYet reports whether the link's activation window has not
+// started at now.
+func (u URL) NotActiveYet(now time.Time) bool {
+	return !u.ActiveFrom.IsZero() && now.Before(u.ActiveFrom)
+}
+
+// Ended reports whether the link's activation window is over at now.
+func (u URL) Ended(now time.Time) bool {
+	return !u.ActiveUntil.IsZero() && !now.Before(u.ActiveUntil)
+}
+
+// public reports whether anyone may follow the link at now, as
+// ListFilter.Public selects it.
+func (u URL) public(now time.Time) bool {
+	return u.PasswordHash == "" && u.State == StateActive &&
+		!u.Expired(now) && !u.NotActiveYet(now) && !u.Ended(now)
+}
+
+// User is an account that owns links.
+type User struct {
+	ID           int64
+	Username     string
+	PasswordHash string
+	Role         string
+	CreatedAt    time.Time
+}
+
+// IsAdmin reports whether the user can see and manage everyone's links.
+func (u User) IsAdmin() bool {
+	return u.Role == RoleAdmin
+}
+
+// CanEdit reports whether the user may create and change links, which
+// everyone but viewers may.
+func (u User) CanEdit() bool {
+	return u.Role == RoleEditor || u.Role == RoleUser || u.Role == RoleAdmin
+}
+
+// CanViewAll reports whether the user can read everyone's links.
+func (u User) CanViewAll() bool {
+	return u.Role == RoleAdmin || u.Role == RoleViewer
+}
+
+// APIKey is a credential for the X-Api-Key header. Only the SHA-256 hash of
+// the key is stored.
+type APIKey struct {
+	ID   int64
+	Name string
+	// Owner is the user who created the key; requests made with the key
+	// act on their behalf.
+	Owner     string
+	Hash      string
+	CreatedAt time.Time
+	// RevokedAt is zero while the key is active.
+	RevokedAt time.Time
+}
+
+// Revoked reports whether the key has been revoked.
+func (k APIKey) Revoked() bool {
+	return !k.RevokedAt.IsZero()
+}
+
+// Campaign groups links, e.g. those of a newsletter or an ad campaign, so
+// that they share settings and their clicks can be counted together.
+type Campaign struct {
+	ID    int64
+	Name  string
+	Owner string
+	// UTM, ExpiresAt and Domain are given to links created in the campaign
+	// that do not set their own. Changing them does not touch links that
+	// already exist.
+	UTM       UTM
+	ExpiresAt time.Time
+	Domain    string
+	CreatedAt time.Time
+}
+
+// Ended reports whether the campaign has expired at now; links can no
+// longer be added to it.
+func (c Campaign) Ended(now time.Time) bool {
+	return !c.ExpiresAt.IsZero() && !now.Before(c.ExpiresAt)
+}
+
+// Click is a single successful redirect.
+type Click struct {
+	// ID orders the clicks of a link; ListClicks sets it.
+	ID        int64
+	Alias     string
+	ClickedAt time.Time
+	Referrer  string
+	UserAgent string
+	// Variant is the name of the split-test variant served, if any.
+	Variant string
+	// Bot is set for clicks of crawlers, link unfurlers and scripts, which
+	// stats leave out by default.
+	Bot bool
+	// Country is the ISO 3166-1 alpha-2 code of the visitor's country and
+	// City the English name of their city; "" if unknown.
+	Country string
+	City    string
+}
+
+// ClickFilter selects the clicks ListClicks returns; zero fields match
+// every click but those of bots.
+type ClickFilter struct {
+	// From and To bound the click time; To is exclusive.
+	From time.Time
+	To   time.Time
+	// IncludeBots returns the clicks of bots too.
+	IncludeBots bool
+}
+
+// Match reports whether c passes the filter.
+func (f ClickFilter) Match(c Click) bool {
+	if c.Bot && !f.IncludeBots {
+		return false
+	}
+	if !f.From.IsZero() && c.ClickedAt.Before(f.From) {
+		return false
+	}
+	if !f.To.IsZero() && !c.ClickedAt.Before(f.To) {
+		return false
+	}
+	return true
+}
+
+// ListFilter selects the links ListURLs returns and CountURLs counts;
+// zero fields match every link.
+type ListFilter struct {
+	// Owner limits the list to the links of one user.
+	Owner string
+	// APIKeyID limits the list to the links created with one API key.
+	APIKeyID int64
+	// Tag limits the list to the links tagged with it.
+	Tag string
+	// URLContains matches links whose original URL contains it, ignoring
+	// case.
+	URLContains string
+	// CreatedFrom and CreatedTo bound the creation time; CreatedTo is
+	// exclusive.
+	CreatedFrom time.Time
+	CreatedTo   time.Time
+	// Health limits the list to links whose last check had this status.
+	Health string
+	// CampaignID limits the list to the links of one campaign.
+	CampaignID int64
+	// Public limits the list to the links anyone may see: not protected by
+	// a password, neither blocked nor flagged as unsafe, not expired and
+	// within their activation window.
+	Public bool
+}
+
+// Match reports whether u passes the filter. Only the fields the filter
+// looks at need to be set.
+func (f ListFilter) Match(u URL) bool {
+	if f.Owner != "" && u.Owner != f.Owner {
+		return false
+	}
+	if f.APIKeyID != 0 && u.APIKeyID != f.APIKeyID {
+		return false
+	}
+	if f.Tag != "" && !slices.Contains(u.Tags, f.Tag) {
+		return false
+	}
+	if f.URLContains != "" && !strings.Contains(strings.ToLower(u.URL), strings.ToLower(f.URLContains)) {
+		return false
+	}
+	if !f.CreatedFrom.IsZero() && u.CreatedAt.Before(f.CreatedFrom) {
+		return false
+	}
+	if !f.CreatedTo.IsZero() && !u.CreatedAt.Before(f.CreatedTo) {
+		return false
+	}
+	if f.Health != "" && u.Health.Status != f.Health {
+		return false
+	}
+	if f.CampaignID != 0 && u.CampaignID != f.CampaignID {
+		return false
+	}
+	if f.Public && !u.public(time.Now()) {
+		return false
+	}
+	return true
+}
+
+// Stats summarises clicks on an alias.
+type Stats struct {
+	// Domain is the short domain of the link, like URL.Domain.
+	Domain string
+	// Total counts the clicks on the link, including its ImportedClicks.
+	Total      int64
+	LastAccess time.Time
+	Daily      []DailyClicks
+	// Variants counts all clicks per split-test variant, by name; it is
+	// empty for links that were never split-tested.
+	Variants []VariantClicks
+}
+
+type VariantClicks struct {
+	Variant string
+	Clicks  int64
+}
+
+// DailyClicks is the number of clicks on a single UTC day (YYYY-MM-DD).
+type DailyClicks struct {
+	Date   string
+	Clicks int64
+}
+
+// ReferrerClicks counts the clicks that came from one referring domain;
+// Domain is empty for clicks without a Referer.
+type ReferrerClicks struct {
+	Domain string
+	Clicks int64
+}
+
+// CountryClicks counts the clicks from one country; Country is empty for
+// clicks whose country is unknown.
+type CountryClicks struct {
+	Country string
+	Clicks  int64
+}
+
+// ReferrerDomain returns the host of a Referer header in lower case and
+// without "www.", or "" if there is no host in it.
+func ReferrerDomain(referrer string) string {
+	u, err := url.Parse(strings.TrimSpace(referrer))
+	if err != nil {
+		return ""
+	}
+
+	return strings.TrimPrefix(strings.ToLower(u.Hostname()), "www.")
+}
+
+// SortReferrers returns the counts per domain ordered from the most clicks
+// to the fewest, then by domain.
+func SortReferrers(byDomain map[string]int64) []ReferrerClicks {
+	referrers := make([]ReferrerClicks, 0, len(byDomain))
+	for domain, clicks := range byDomain {
+		referrers = append(referrers, ReferrerClicks{Domain: domain, Clicks: clicks})
+	}
+	slices.SortFunc(referrers, func(a, b ReferrerClicks) int {
+		if a.Clicks != b.Clicks {
+			return cmp.Compare(b.Clicks, a.Clicks)
+		}
+		return strings.Compare(a.Domain, b.Domain)
+	})
+
+	return referrers
+}
+
+// SortCountries returns the counts per country ordered from the most clicks
+// to the fewest, then by country.
+func SortCountries(byCountry map[string]int64) []CountryClicks {
+	countries := make([]CountryClicks, 0, len(byCountry))
+	for country, clicks := range byCountry {
+		countries = append(countries, CountryClicks{Country: country, Clicks: clicks})
+	}
+	slices.SortFunc(countries, func(a, b CountryClicks) int {
+		if a.Clicks != b.Clicks {
+			return cmp.Compare(b.Clicks, a.Clicks)
+		}
+		return strings.Compare(a.Country, b.Country)
+	})
+
+	return countries
+}
+
+// Audit actions.
+const (
+	AuditLinkCreate  = "link.create"
+	AuditLinkUpdate  = "link.update"
+	AuditLinkDelete  = "link.delete"
+	AuditLinkRestore = "link.restore"
+	AuditLinkRename  = "link.rename"
+	AuditLinkBlock   = "link.block"
+	AuditKeyCreate   = "api_key.create"
+	AuditKeyRevoke   = "api_key.revoke"
+	AuditUserCreate  = "user.create"
+	AuditUserErase   = "user.erase"
+
+	AuditCampaignCreate = "campaign.create"
+	AuditCampaignUpdate = "campaign.update"
+	AuditCampaignDelete = "campaign.delete"
+)
+
+// AuditEntry records who changed what and when.
+type AuditEntry struct {
+	ID     int64
+	Time   time.Time
+	Action string
+	// Target is the alias, API key or campaign id or username the action
+	// applies to.
+	Target string
+	// Actor is the user who acted, empty if no one was authenticated.
+	Actor    string
+	APIKeyID int64
+	IP       string
+	// Before and After are JSON snapshots of the target, empty where
+	// there is nothing to show.
+	Before string
+	After  string
+}
+
+// AuditFilter selects the entries ListAuditLog returns; zero fields match
+// every entry.
+type AuditFilter struct {
+	Action string
+	Target string
+	Actor  string
+}
+
+// Match reports whether e passes the filter.
+func (f AuditFilter) Match(e AuditEntry) bool {
+	return (f.Action == "" || e.Action == f.Action) &&
+		(f.Target == "" || e.Target == f.Target) &&
+		(f.Actor == "" || e.Actor == f.Actor)
+}
+
+// Erase takes username out of e: the actor and IP if the user acted, the
+// target if it was the user and the snapshots if the target was the user or
+// one of aliases, their links. It reports whether e changed.
+func (e *AuditEntry) Erase(username string, aliases []string) bool {
+	byUser := e.Actor == username
+	ofUser := e.Target == username || slices.Contains(aliases, e.Target)
+	if !byUser && !ofUser {
+		return false
+	}
+
+	if byUser {
+		e.Actor, e.IP = "", ""
+	}
+	if e.Target == username {
+		e.Target = ""
+	}
+	e.Before, e.After = "", ""
+
+	return true
+}
+
+// UserDataReport is what DeleteUserData removed.
+type UserDataReport struct {
+	// Aliases are the links of the user, deleted ones included, that were
+	// removed for good.
+	Aliases []string
+	// Clicks counts the raw clicks removed with them.
+	Clicks int64
+	// APIKeys counts the keys of the user, revoked ones included.
+	APIKeys int64
+	// AuditEntries counts the entries the user was erased from.
+	AuditEntries int64
+	// Campaigns counts the campaigns of the user.
+	Campaigns int64
+	// User reports whether a stored account was removed; users from the
+	// config file are not stored.
+	User bool
+}
+
+// Empty reports whether there was no data of the user.
+func (r UserDataReport) Empty() bool {
+	return len(r.Aliases) == 0 && r.Clicks == 0 && r.APIKeys == 0 && r.AuditEntries == 0 && r.Campaigns == 0 && !r.User
+}
+
+// IdempotencyRecord is the outcome of the first request sent with an
+// Idempotency-Key, which retries of the request get instead of running it
+// again.
+type IdempotencyRecord struct {
+	// Key is unique across clients; the key a client sent is only part of
+	// it.
+	Key string
+	// RequestHash identifies the request the key was first sent with.
+	RequestHash string
+	// Status is the HTTP status of the response, 0 while the first request
+	// is still being handled.
+	Status      int
+	ContentType string
+	Body        string
+	CreatedAt   time.Time
+	ExpiresAt   time.Time
+}
+
+// Pending reports whether the first request is still being handled.
+func (r IdempotencyRecord) Pending() bool {
+	return r.Status == 0
+}
@@ -0,0 +1,349 @@
+package traced
+
+import (
+	"context"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+
+	"url-shortener/internal/storage"
+	"url-shortener/internal/storage/instrumented"
+)
+
+// Storage wraps every call to a backend in a span, a child of the span of
+// the request that made it.
+type Storage struct {
+	backend instrumented.Backend
+	tracer  trace.Tracer
+}
+
+func New(backend instrumented.Backend, tracer trace.Tracer) *Storage {
+	return &Storage{backend: backend, tracer: tracer}
+}
+
+func (s *Storage) start(ctx context.Context, query string) (context.Context, trace.Span) {
+	return s.tracer.Start(ctx, "storage."+query,
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(attribute.String("db.operation.name", query)),
+	)
+}
+
+// end finishes span. Expected outcomes such as a missing alias are recorded
+// without marking the span as failed.
+func end(span trace.Span, err *error) {
+	if *err != nil {
+		span.RecordError(*err)
+		if !storage.Expected(*err) {
+			span.SetStatus(codes.Error, (*err).Error())
+		}
+	}
+	span.End()
+}
+
+func (s *Storage) SaveURL(ctx context.Context, u storage.URL) (_ int64, err error) {
+	ctx, span := s.start(ctx, "SaveURL")
+	defer end(span, &err)
+
+	return s.backend.SaveURL(ctx, u)
+}
+
+func (s *Storage) NextURLID(ctx context.Context) (_ int64, err error) {
+	ctx, span := s.start(ctx, "NextURLID")
+	defer end(span, &err)
+
+	return s.backend.NextURLID(ctx)
+}
+
+func (s *Storage) SaveURLs(ctx context.Context, urls []storage.URL) (_ []error, err error) {
+	ctx, span := s.start(ctx, "SaveURLs")
+	defer end(span, &err)
+
+	return s.backend.SaveURLs(ctx, urls)
+}
+
+func (s *Storage) GetURL(ctx context.Context, alias string) (_ storage.URL, err error) {
+	ctx, span := s.start(ctx, "GetURL")
+	defer end(span, &err)
+
+	return s.backend.GetURL(ctx, alias)
+}
+
+func (s *Storage) GetAliasByURL(ctx context.Context, url string, owner string) (_ string, err error) {
+	ctx, span := s.start(ctx, "GetAliasByURL")
+	defer end(span, &err)
+
+	return s.backend.GetAliasByURL(ctx, url, owner)
+}
+
+func (s *Storage) FlagURL(ctx context.Context, alias string, threat string) (err error) {
+	ctx, span := s.start(ctx, "FlagURL")
+	defer end(span, &err)
+
+	return s.backend.FlagURL(ctx, alias, threat)
+}
+
+func (s *Storage) ConsumeClick(ctx context.Context, alias string) (err error) {
+	ctx, span := s.start(ctx, "ConsumeClick")
+	defer end(span, &err)
+
+	return s.backend.ConsumeClick(ctx, alias)
+}
+
+func (s *Storage) DeleteURL(ctx context.Context, alias string, owner string) (err error) {
+	ctx, span := s.start(ctx, "DeleteURL")
+	defer end(span, &err)
+
+	return s.backend.DeleteURL(ctx, alias, owner)
+}
+
+func (s *Storage) RestoreURL(ctx context.Context, alias string, owner string) (err error) {
+	ctx, span := s.start(ctx, "RestoreURL")
+	defer end(span, &err)
+
+	return s.backend.RestoreURL(ctx, alias, owner)
+}
+
+func (s *Storage) RenameURL(ctx context.Context, alias string, owner string, newAlias string, forwardUntil time.Time) (err error) {
+	ctx, span := s.start(ctx, "RenameURL")
+	defer end(span, &err)
+
+	return s.backend.RenameURL(ctx, alias, owner, newAlias, forwardUntil)
+}
+
+func (s *Storage) UpdateURL(ctx context.Context, alias string, owner string, newURL string) (err error) {
+	ctx, span := s.start(ctx, "UpdateURL")
+	defer end(span, &err)
+
+	return s.backend.UpdateURL(ctx, alias, owner, newURL)
+}
+
+func (s *Storage) BlockURL(ctx context.Context, alias string, reason string, reference string) (err error) {
+	ctx, span := s.start(ctx, "BlockURL")
+	defer end(span, &err)
+
+	return s.backend.BlockURL(ctx, alias, reason, reference)
+}
+
+func (s *Storage) GetLegalBlock(ctx context.Context, alias string) (_ storage.LegalBlock, err error) {
+	ctx, span := s.start(ctx, "GetLegalBlock")
+	defer end(span, &err)
+
+	return s.backend.GetLegalBlock(ctx, alias)
+}
+
+func (s *Storage) GetOwner(ctx context.Context, alias string) (_ string, err error) {
+	ctx, span := s.start(ctx, "GetOwner")
+	defer end(span, &err)
+
+	return s.backend.GetOwner(ctx, alias)
+}
+
+func (s *Storage) AliasExists(ctx context.Context, alias string) (_ bool, err error) {
+	ctx, span := s.start(ctx, "AliasExists")
+	defer end(span, &err)
+
+	return s.backend.AliasExists(ctx, alias)
+}
+
+func (s *Storage) SetURLHealth(ctx context.Context, alias string, health storage.Health) (err error) {
+	ctx, span := s.start(ctx, "SetURLHealth")
+	defer end(span, &err)
+
+	return s.backend.SetURLHealth(ctx, alias, health)
+}
+
+func (s *Storage) ListURLs(ctx context.Context, filter storage.ListFilter, limit int, offset int, desc bool) (_ []storage.URL, err error) {
+	ctx, span := s.start(ctx, "ListURLs")
+	defer end(span, &err)
+
+	return s.backend.ListURLs(ctx, filter, limit, offset, desc)
+}
+
+func (s *Storage) CountURLs(ctx context.Context, filter storage.ListFilter) (_ int64, err error) {
+	ctx, span := s.start(ctx, "CountURLs")
+	defer end(span, &err)
+
+	return s.backend.CountURLs(ctx, filter)
+}
+
+func (s *Storage) SaveClick(ctx context.Context, click storage.Click) (err error) {
+	ctx, span := s.start(ctx, "SaveClick")
+	defer end(span, &err)
+
+	return s.backend.SaveClick(ctx, click)
+}
+
+func (s *Storage) GetStats(ctx context.Context, alias string, since time.Time, includeBots bool) (_ storage.Stats, err error) {
+	ctx, span := s.start(ctx, "GetStats")
+	defer end(span, &err)
+
+	return s.backend.GetStats(ctx, alias, since, includeBots)
+}
+
+func (s *Storage) GetReferrers(ctx context.Context, alias string, includeBots bool) (_ []storage.ReferrerClicks, err error) {
+	ctx, span := s.start(ctx, "GetReferrers")
+	defer end(span, &err)
+
+	return s.backend.GetReferrers(ctx, alias, includeBots)
+}
+
+func (s *Storage) GetCountries(ctx context.Context, alias string, includeBots bool) (_ []storage.CountryClicks, err error) {
+	ctx, span := s.start(ctx, "GetCountries")
+	defer end(span, &err)
+
+	return s.backend.GetCountries(ctx, alias, includeBots)
+}
+
+func (s *Storage) ListClicks(ctx context.Context, alias string, filter storage.ClickFilter, afterID int64, limit int) (_ []storage.Click, err error) {
+	ctx, span := s.start(ctx, "ListClicks")
+	defer end(span, &err)
+
+	return s.backend.ListClicks(ctx, alias, filter, afterID, limit)
+}
+
+func (s *Storage) DeleteExpired(ctx context.Context, now time.Time) (_ []string, err error) {
+	ctx, span := s.start(ctx, "DeleteExpired")
+	defer end(span, &err)
+
+	return s.backend.DeleteExpired(ctx, now)
+}
+
+func (s *Storage) PurgeDeleted(ctx context.Context, before time.Time) (_ []string, err error) {
+	ctx, span := s.start(ctx, "PurgeDeleted")
+	defer end(span, &err)
+
+	return s.backend.PurgeDeleted(ctx, before)
+}
+
+func (s *Storage) SaveAPIKey(ctx context.Context, key storage.APIKey) (_ int64, err error) {
+	ctx, span := s.start(ctx, "SaveAPIKey")
+	defer end(span, &err)
+
+	return s.backend.SaveAPIKey(ctx, key)
+}
+
+func (s *Storage) GetAPIKeyByHash(ctx context.Context, hash string) (_ storage.APIKey, err error) {
+	ctx, span := s.start(ctx, "GetAPIKeyByHash")
+	defer end(span, &err)
+
+	return s.backend.GetAPIKeyByHash(ctx, hash)
+}
+
+func (s *Storage) ListAPIKeys(ctx context.Context, owner string) (_ []storage.APIKey, err error) {
+	ctx, span := s.start(ctx, "ListAPIKeys")
+	defer end(span, &err)
+
+	return s.backend.ListAPIKeys(ctx, owner)
+}
+
+func (s *Storage) RevokeAPIKey(ctx context.Context, id int64, owner string) (err error) {
+	ctx, span := s.start(ctx, "RevokeAPIKey")
+	defer end(span, &err)
+
+	return s.backend.RevokeAPIKey(ctx, id, owner)
+}
+
+func (s *Storage) SaveCampaign(ctx context.Context, c storage.Campaign) (_ int64, err error) {
+	ctx, span := s.start(ctx, "SaveCampaign")
+	defer end(span, &err)
+
+	return s.backend.SaveCampaign(ctx, c)
+}
+
+func (s *Storage) GetCampaign(ctx context.Context, id int64, owner string) (_ storage.Campaign, err error) {
+	ctx, span := s.start(ctx, "GetCampaign")
+	defer end(span, &err)
+
+	return s.backend.GetCampaign(ctx, id, owner)
+}
+
+func (s *Storage) ListCampaigns(ctx context.Context, owner string) (_ []storage.Campaign, err error) {
+	ctx, span := s.start(ctx, "ListCampaigns")
+	defer end(span, &err)
+
+	return s.backend.ListCampaigns(ctx, owner)
+}
+
+func (s *Storage) UpdateCampaign(ctx context.Context, c storage.Campaign, owner string) (err error) {
+	ctx, span := s.start(ctx, "UpdateCampaign")
+	defer end(span, &err)
+
+	return s.backend.UpdateCampaign(ctx, c, owner)
+}
+
+func (s *Storage) DeleteCampaign(ctx context.Context, id int64, owner string) (err error) {
+	ctx, span := s.start(ctx, "DeleteCampaign")
+	defer end(span, &err)
+
+	return s.backend.DeleteCampaign(ctx, id, owner)
+}
+
+func (s *Storage) SaveUser(ctx context.Context, user storage.User) (_ int64, err error) {
+	ctx, span := s.start(ctx, "SaveUser")
+	defer end(span, &err)
+
+	return s.backend.SaveUser(ctx, user)
+}
+
+func (s *Storage) GetUser(ctx context.Context, username string) (_ storage.User, err error) {
+	ctx, span := s.start(ctx, "GetUser")
+	defer end(span, &err)
+
+	return s.backend.GetUser(ctx, username)
+}
+
+func (s *Storage) DeleteUserData(ctx context.Context, username string) (_ storage.UserDataReport, err error) {
+	ctx, span := s.start(ctx, "DeleteUserData")
+	defer end(span, &err)
+
+	return s.backend.DeleteUserData(ctx, username)
+}
+
+func (s *Storage) SaveAuditEntry(ctx context.Context, e storage.AuditEntry) (err error) {
+	ctx, span := s.start(ctx, "SaveAuditEntry")
+	defer end(span, &err)
+
+	return s.backend.SaveAuditEntry(ctx, e)
+}
+
+func (s *Storage) ListAuditLog(ctx context.Context, filter storage.AuditFilter, limit int, offset int) (_ []storage.AuditEntry, err error) {
+	ctx, span := s.start(ctx, "ListAuditLog")
+	defer end(span, &err)
+
+	return s.backend.ListAuditLog(ctx, filter, limit, offset)
+}
+
+func (s *Storage) ReserveIdempotencyKey(ctx context.Context, rec storage.IdempotencyRecord) (_ storage.IdempotencyRecord, err error) {
+	ctx, span := s.start(ctx, "ReserveIdempotencyKey")
+	defer end(span, &err)
+
+	return s.backend.ReserveIdempotencyKey(ctx, rec)
+}
+
+func (s *Storage) CompleteIdempotencyKey(ctx context.Context, key string, status int, contentType string, body string) (err error) {
+	ctx, span := s.start(ctx, "CompleteIdempotencyKey")
+	defer end(span, &err)
+
+	return s.backend.CompleteIdempotencyKey(ctx, key, status, contentType, body)
+}
+
+func (s *Storage) ReleaseIdempotencyKey(ctx context.Context, key string) (err error) {
+	ctx, span := s.start(ctx, "ReleaseIdempotencyKey")
+	defer end(span, &err)
+
+	return s.backend.ReleaseIdempotencyKey(ctx, key)
+}
+
+func (s *Storage) Ping(ctx context.Context) (err error) {
+	ctx, span := s.start(ctx, "Ping")
+	defer end(span, &err)
+
+	return s.backend.Ping(ctx)
+}
+
+// Close is not a query, so it is passed through without a span.
+func (s *Storage) Close() error {
+	return s.backend.Close()
+}
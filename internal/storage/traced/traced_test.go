@@ -0,0 +1,42 @@
+package traced_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+
+	"url-shortener/internal/storage"
+	"url-shortener/internal/storage/memory"
+	"url-shortener/internal/storage/traced"
+)
+
+func TestStorage_TracesCalls(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+	tracer := provider.Tracer("test")
+
+	s := traced.New(memory.New(), tracer)
+
+	ctx, parent := tracer.Start(context.Background(), "request")
+	_, err := s.SaveURL(ctx, storage.URL{URL: "https://example.com", Alias: "example"})
+	require.NoError(t, err)
+	_, err = s.GetURL(ctx, "missing")
+	require.ErrorIs(t, err, storage.ErrUrlNotFound)
+	parent.End()
+
+	spans := recorder.Ended()
+	require.Len(t, spans, 3)
+
+	require.Equal(t, "storage.SaveURL", spans[0].Name())
+	require.Equal(t, parent.SpanContext().SpanID(), spans[0].Parent().SpanID())
+	require.Equal(t, codes.Unset, spans[0].Status().Code)
+
+	// A missing alias is an answer, not a failure.
+	require.Equal(t, "storage.GetURL", spans[1].Name())
+	require.Equal(t, codes.Unset, spans[1].Status().Code)
+	require.Len(t, spans[1].Events(), 1)
+}
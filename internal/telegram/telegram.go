@@ -0,0 +1,249 @@
+// Package telegram runs a Telegram bot that shortens the links users send
+// it. Updates are long-polled from the Bot API, so the service needs no
+// public address for the bot.
+package telegram
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"url-shortener/internal/http-server/middleware/auth"
+	"url-shortener/internal/lib/logger/sl"
+	"url-shortener/internal/storage"
+)
+
+// APIURL is the official Bot API server.
+const APIURL = "https://api.telegram.org"
+
+// retryDelay is the pause after a failed poll.
+const retryDelay = 5 * time.Second
+
+const help = "Send me a link and I will shorten it. To choose the alias, send it after the link:\n" +
+	"https://example.com/some/long/page my-alias"
+
+//go:generate go run github.com/vektra/mockery/v2@latest --name=UserGetter
+type UserGetter interface {
+	GetUser(ctx context.Context, username string) (storage.User, error)
+}
+
+type Options struct {
+	// APIURL is the Bot API server; empty means APIURL.
+	APIURL string
+	// Token is the bot token from @BotFather.
+	Token string
+	// Users maps the Telegram user IDs allowed to use the bot to the users
+	// their links are saved for.
+	Users map[int64]string
+	// PollTimeout is how long a poll waits for updates.
+	PollTimeout time.Duration
+}
+
+// Bot answers messages with short links made by saver, the handler of
+// POST /url, as the user the sender is mapped to.
+type Bot struct {
+	log    *slog.Logger
+	client *http.Client
+	users  UserGetter
+	saver  http.Handler
+	opts   Options
+	offset int64
+}
+
+func New(log *slog.Logger, client *http.Client, users UserGetter, saver http.Handler, opts Options) *Bot {
+	if opts.APIURL == "" {
+		opts.APIURL = APIURL
+	}
+
+	return &Bot{
+		log:    log.With(slog.String("component", "telegram")),
+		client: client,
+		users:  users,
+		saver:  saver,
+		opts:   opts,
+	}
+}
+
+type update struct {
+	ID      int64    `json:"update_id"`
+	Message *message `json:"message"`
+}
+
+type message struct {
+	ID   int64 `json:"message_id"`
+	From *struct {
+		ID int64 `json:"id"`
+	} `json:"from"`
+	Chat struct {
+		ID int64 `json:"id"`
+	} `json:"chat"`
+	Text string `json:"text"`
+}
+
+// Run answers messages until ctx is cancelled.
+func (b *Bot) Run(ctx context.Context) {
+	for {
+		updates, err := b.poll(ctx)
+		if ctx.Err() != nil {
+			return
+		}
+		if err != nil {
+			b.log.Error("failed to get telegram updates", sl.Err(err))
+
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(retryDelay):
+			}
+			continue
+		}
+
+		for _, u := range updates {
+			b.offset = u.ID + 1
+			if u.Message != nil && u.Message.From != nil {
+				b.handle(ctx, u.Message)
+			}
+		}
+	}
+}
+
+func (b *Bot) poll(ctx context.Context) ([]update, error) {
+	params := url.Values{
+		"offset":          {strconv.FormatInt(b.offset, 10)},
+		"timeout":         {strconv.Itoa(int(b.opts.PollTimeout.Seconds()))},
+		"allowed_updates": {`["message"]`},
+	}
+
+	var updates []update
+	err := b.call(ctx, "getUpdates", params, &updates)
+	return updates, err
+}
+
+func (b *Bot) handle(ctx context.Context, msg *message) {
+	log := b.log.With(slog.Int64("telegram_user_id", msg.From.ID))
+
+	username, ok := b.opts.Users[msg.From.ID]
+	if !ok {
+		log.Info("telegram user is not allowed")
+		// The ID is what an admin needs to let the user in.
+		b.reply(ctx, log, msg, fmt.Sprintf("You are not allowed to shorten links here. Your Telegram user ID is %d.", msg.From.ID))
+		return
+	}
+
+	fields := strings.Fields(msg.Text)
+	if len(fields) == 0 || len(fields) > 2 || strings.HasPrefix(fields[0], "/") {
+		b.reply(ctx, log, msg, help)
+		return
+	}
+
+	user, err := b.users.GetUser(ctx, username)
+	if err != nil {
+		log.Error("failed to get user", slog.String("username", username), sl.Err(err))
+		b.reply(ctx, log, msg, "Failed to shorten the link, try again later.")
+		return
+	}
+	if !user.CanEdit() {
+		log.Info("viewer cannot shorten links", slog.String("username", username))
+		b.reply(ctx, log, msg, "Your account can only view links.")
+		return
+	}
+
+	req := struct {
+		URL   string `json:"url"`
+		Alias string `json:"alias,omitempty"`
+	}{URL: fields[0]}
+	if len(fields) == 2 {
+		req.Alias = fields[1]
+	}
+
+	b.reply(ctx, log, msg, b.save(ctx, user, req))
+}
+
+// save runs the save handler as if user had sent body to it and returns
+// the plain-text response: the short URL or what is wrong.
+func (b *Bot) save(ctx context.Context, user storage.User, body any) string {
+	data, _ := json.Marshal(body)
+
+	r, _ := http.NewRequestWithContext(auth.WithUser(ctx, user), http.MethodPost, "/url", bytes.NewReader(data))
+	r.Header.Set("Content-Type", "application/json")
+	r.Header.Set("Accept", "text/plain")
+
+	w := &response{header: make(http.Header)}
+	b.saver.ServeHTTP(w, r)
+
+	return strings.TrimSpace(w.body.String())
+}
+
+func (b *Bot) reply(ctx context.Context, log *slog.Logger, msg *message, text string) {
+	params := url.Values{
+		"chat_id":          {strconv.FormatInt(msg.Chat.ID, 10)},
+		"text":             {text},
+		"reply_parameters": {fmt.Sprintf(`{"message_id":%d}`, msg.ID)},
+	}
+	if err := b.call(ctx, "sendMessage", params, nil); err != nil {
+		log.Error("failed to send telegram message", sl.Err(err))
+	}
+}
+
+// call calls a Bot API method and decodes its result into result.
+func (b *Bot) call(ctx context.Context, method string, params url.Values, result any) error {
+	const op = "telegram.call"
+
+	endpoint := b.opts.APIURL + "/bot" + b.opts.Token + "/" + method
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, strings.NewReader(params.Encode()))
+	if err != nil {
+		return fmt.Errorf("%s: %s: %w", op, method, err)
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	res, err := b.client.Do(req)
+	if err != nil {
+		// The URL holds the token, so it is left out of the error.
+		var urlErr *url.Error
+		if errors.As(err, &urlErr) {
+			err = urlErr.Err
+		}
+		return fmt.Errorf("%s: %s: %w", op, method, err)
+	}
+	defer res.Body.Close()
+
+	var body struct {
+		OK          bool            `json:"ok"`
+		Description string          `json:"description"`
+		Result      json.RawMessage `json:"result"`
+	}
+	if err := json.NewDecoder(io.LimitReader(res.Body, 1<<20)).Decode(&body); err != nil {
+		return fmt.Errorf("%s: %s: status %d: %w", op, method, res.StatusCode, err)
+	}
+	if !body.OK {
+		return fmt.Errorf("%s: %s: %s", op, method, body.Description)
+	}
+
+	if result == nil {
+		return nil
+	}
+	if err := json.Unmarshal(body.Result, result); err != nil {
+		return fmt.Errorf("%s: %s: %w", op, method, err)
+	}
+
+	return nil
+}
+
+// response collects what the save handler writes.
+type response struct {
+	header http.Header
+	body   bytes.Buffer
+}
+
+func (r *response) Header() http.Header         { return r.header }
+func (r *response) Write(p []byte) (int, error) { return r.body.Write(p) }
+func (r *response) WriteHeader(int)             {}
@@ -0,0 +1,123 @@
+package telegram_test
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"url-shortener/internal/http-server/middleware/auth"
+	"url-shortener/internal/lib/logger/handlers/slogdiscard"
+	"url-shortener/internal/storage"
+	"url-shortener/internal/telegram"
+	"url-shortener/internal/telegram/mocks"
+)
+
+// botAPI fakes the Bot API: it hands out updates once and records the
+// messages sent.
+type botAPI struct {
+	t       *testing.T
+	updates string
+
+	mu   sync.Mutex
+	sent map[string]string
+	done chan struct{}
+	want int
+}
+
+func (a *botAPI) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	require.NoError(a.t, r.ParseForm())
+
+	switch r.URL.Path {
+	case "/botsecret/getUpdates":
+		if r.Form.Get("offset") == "0" {
+			_, _ = io.WriteString(w, `{"ok":true,"result":`+a.updates+`}`)
+			return
+		}
+		<-r.Context().Done()
+	case "/botsecret/sendMessage":
+		a.mu.Lock()
+		a.sent[r.Form.Get("chat_id")] = r.Form.Get("text")
+		if len(a.sent) == a.want {
+			close(a.done)
+		}
+		a.mu.Unlock()
+		_, _ = io.WriteString(w, `{"ok":true,"result":{}}`)
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+func TestBot(t *testing.T) {
+	api := &botAPI{
+		t: t,
+		updates: `[
+			{"update_id": 1, "message": {"message_id": 10, "from": {"id": 100}, "chat": {"id": 1}, "text": "https://example.com/page my-alias"}},
+			{"update_id": 2, "message": {"message_id": 11, "from": {"id": 200}, "chat": {"id": 2}, "text": "https://example.com"}},
+			{"update_id": 3, "message": {"message_id": 12, "from": {"id": 100}, "chat": {"id": 3}, "text": "/start"}},
+			{"update_id": 4, "message": {"message_id": 13, "from": {"id": 300}, "chat": {"id": 4}, "text": "https://example.com"}}
+		]`,
+		sent: make(map[string]string),
+		done: make(chan struct{}),
+		want: 4,
+	}
+	srv := httptest.NewServer(api)
+	defer srv.Close()
+
+	usersMock := mocks.NewUserGetter(t)
+	usersMock.On("GetUser", mock.Anything, "alice").
+		Return(storage.User{Username: "alice", Role: storage.RoleUser}, nil).
+		Once()
+	usersMock.On("GetUser", mock.Anything, "carol").
+		Return(storage.User{Username: "carol", Role: storage.RoleViewer}, nil).
+		Once()
+
+	saver := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		user, ok := auth.UserFromContext(r.Context())
+		require.True(t, ok)
+		require.Equal(t, "alice", user.Username)
+		require.Equal(t, "text/plain", r.Header.Get("Accept"))
+
+		var req struct{ URL, Alias string }
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+		require.Equal(t, "https://example.com/page", req.URL)
+
+		_, _ = io.WriteString(w, "https://sho.rt/"+req.Alias+"\n")
+	})
+
+	bot := telegram.New(slogdiscard.NewDiscardLogger(), srv.Client(), usersMock, saver, telegram.Options{
+		APIURL:      srv.URL,
+		Token:       "secret",
+		Users:       map[int64]string{100: "alice", 300: "carol"},
+		PollTimeout: time.Second,
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		bot.Run(ctx)
+		close(done)
+	}()
+
+	select {
+	case <-api.done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("bot did not answer")
+	}
+	cancel()
+	<-done
+
+	require.Equal(t, "https://sho.rt/my-alias", api.sent["1"])
+	require.Contains(t, api.sent["2"], "not allowed")
+	require.Contains(t, api.sent["2"], "200")
+	require.True(t, strings.HasPrefix(api.sent["3"], "Send me a link"))
+	require.Equal(t, "Your account can only view links.", api.sent["4"])
+}
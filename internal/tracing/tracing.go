@@ -0,0 +1,59 @@
+package tracing
+
+import (
+	"context"
+	"fmt"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracegrpc"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+)
+
+type Options struct {
+	// Endpoint is the host:port of an OTLP/gRPC collector.
+	Endpoint string
+	// Insecure sends spans without TLS, e.g. to a collector on localhost.
+	Insecure    bool
+	ServiceName string
+	// SampleRatio is the share of new traces that are recorded; traces
+	// started by the caller follow the caller's decision.
+	SampleRatio float64
+}
+
+// New returns a tracer provider that exports spans in batches over OTLP.
+// Spans still queued are sent by Shutdown, which has to be called on exit.
+func New(ctx context.Context, opts Options) (*sdktrace.TracerProvider, error) {
+	const op = "tracing.New"
+
+	exporterOpts := []otlptracegrpc.Option{otlptracegrpc.WithEndpoint(opts.Endpoint)}
+	if opts.Insecure {
+		exporterOpts = append(exporterOpts, otlptracegrpc.WithInsecure())
+	}
+
+	// The exporter connects lazily, so a collector that is down does not
+	// keep the service from starting.
+	exporter, err := otlptracegrpc.New(ctx, exporterOpts...)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	res, err := resource.Merge(resource.Default(), resource.NewSchemaless(
+		attribute.String("service.name", opts.ServiceName),
+	))
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithResource(res),
+		sdktrace.WithSampler(sdktrace.ParentBased(sdktrace.TraceIDRatioBased(opts.SampleRatio))),
+	), nil
+}
+
+// Propagator reads and writes W3C traceparent and baggage headers.
+func Propagator() propagation.TextMapPropagator {
+	return propagation.NewCompositeTextMapPropagator(propagation.TraceContext{}, propagation.Baggage{})
+}
@@ -0,0 +1,61 @@
+package users
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"golang.org/x/crypto/bcrypt"
+
+	"url-shortener/internal/storage"
+)
+
+// LoadHtpasswd reads the accounts of an htpasswd file made with
+// htpasswd -B. The file has no roles, so they are all admins.
+func LoadHtpasswd(path string) ([]Account, error) {
+	const op = "users.LoadHtpasswd"
+
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	defer f.Close()
+
+	accounts, err := ParseHtpasswd(f)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %s: %w", op, path, err)
+	}
+
+	return accounts, nil
+}
+
+// ParseHtpasswd reads name:hash lines, skipping blank lines and comments.
+// Only bcrypt hashes are accepted; the MD5, SHA-1 and crypt ones htpasswd
+// makes without -B are too weak.
+func ParseHtpasswd(r io.Reader) ([]Account, error) {
+	var accounts []Account
+	sc := bufio.NewScanner(r)
+	for line := 1; sc.Scan(); line++ {
+		text := strings.TrimSpace(sc.Text())
+		if text == "" || strings.HasPrefix(text, "#") {
+			continue
+		}
+
+		name, hash, ok := strings.Cut(text, ":")
+		if !ok || name == "" {
+			return nil, fmt.Errorf("line %d: want name:hash", line)
+		}
+		if _, err := bcrypt.Cost([]byte(hash)); err != nil {
+			return nil, fmt.Errorf("line %d: %s: not a bcrypt hash, use htpasswd -B", line, name)
+		}
+
+		accounts = append(accounts, Account{Username: name, PasswordHash: hash, Role: storage.RoleAdmin})
+	}
+	if err := sc.Err(); err != nil {
+		return nil, err
+	}
+
+	return accounts, nil
+}
@@ -0,0 +1,66 @@
+package users_test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"url-shortener/internal/storage"
+	"url-shortener/internal/users"
+)
+
+// bcryptHash is what htpasswd -B makes of secret123.
+const bcryptHash = "$2y$05$lChCwEk8QOieRfOQJF5xuuIXDiuwdBq7PhqPHrzR6yM7bTZ.ZzKAu"
+
+func TestParseHtpasswd(t *testing.T) {
+	accounts, err := users.ParseHtpasswd(strings.NewReader(
+		"# team\n" +
+			"alice:" + bcryptHash + "\n" +
+			"\n" +
+			"bob:" + bcryptHash + "\r\n",
+	))
+	require.NoError(t, err)
+	require.Equal(t, []users.Account{
+		{Username: "alice", PasswordHash: bcryptHash, Role: storage.RoleAdmin},
+		{Username: "bob", PasswordHash: bcryptHash, Role: storage.RoleAdmin},
+	}, accounts)
+}
+
+func TestParseHtpasswd_Invalid(t *testing.T) {
+	cases := []struct {
+		name    string
+		content string
+		wantErr string
+	}{
+		{name: "No hash", content: "alice\n", wantErr: "line 1: want name:hash"},
+		{name: "MD5", content: "# md5\nalice:$apr1$abc$def\n", wantErr: "line 2: alice: not a bcrypt hash, use htpasswd -B"},
+		{name: "SHA-1", content: "alice:{SHA}W6ph5Mm5Pz8GgiULbPgzG37mj9g=\n", wantErr: "line 1: alice: not a bcrypt hash, use htpasswd -B"},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := users.ParseHtpasswd(strings.NewReader(tc.content))
+			require.EqualError(t, err, tc.wantErr)
+		})
+	}
+}
+
+func TestLoadHtpasswd(t *testing.T) {
+	path := filepath.Join(t.TempDir(), ".htpasswd")
+	require.NoError(t, os.WriteFile(path, []byte("alice:"+bcryptHash+"\n"), 0o600))
+
+	accounts, err := users.LoadHtpasswd(path)
+	require.NoError(t, err)
+	require.Len(t, accounts, 1)
+
+	user, err := users.New(accounts, nil).Authenticate(context.Background(), "alice", "secret123")
+	require.NoError(t, err)
+	require.Equal(t, storage.RoleAdmin, user.Role)
+
+	_, err = users.LoadHtpasswd(filepath.Join(t.TempDir(), "missing"))
+	require.Error(t, err)
+}
@@ -0,0 +1,83 @@
+package users
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"golang.org/x/crypto/bcrypt"
+
+	"url-shortener/internal/storage"
+)
+
+var ErrInvalidCredentials = errors.New("invalid credentials")
+
+//go:generate go run github.com/vektra/mockery/v2@latest --name=UserGetter
+type UserGetter interface {
+	GetUser(ctx context.Context, username string) (storage.User, error)
+}
+
+// Account is a user from the config or an htpasswd file rather than the
+// database.
+type Account struct {
+	Username string
+	// PasswordHash is a bcrypt hash.
+	PasswordHash string
+	Role         string
+}
+
+// Authenticator checks user credentials. Accounts from the config are
+// built in and not stored in the database, so a fresh installation can log
+// in and create the other users; they take precedence over database users
+// of the same name.
+type Authenticator struct {
+	accounts map[string]Account
+	users    UserGetter
+	// dummyHash is compared against when the user does not exist, so that
+	// unknown names take as long to reject as wrong passwords.
+	dummyHash []byte
+}
+
+func New(accounts []Account, users UserGetter) *Authenticator {
+	a := &Authenticator{
+		accounts: make(map[string]Account, len(accounts)),
+		users:    users,
+	}
+	for _, acc := range accounts {
+		a.accounts[acc.Username] = acc
+	}
+	a.dummyHash, _ = bcrypt.GenerateFromPassword([]byte("dummy password"), bcrypt.DefaultCost)
+
+	return a
+}
+
+// Authenticate returns the user with the given name if password matches and
+// ErrInvalidCredentials otherwise.
+func (a *Authenticator) Authenticate(ctx context.Context, username string, password string) (storage.User, error) {
+	const op = "users.Authenticate"
+
+	user, err := a.GetUser(ctx, username)
+	if errors.Is(err, storage.ErrUserNotFound) {
+		_ = bcrypt.CompareHashAndPassword(a.dummyHash, []byte(password))
+		return storage.User{}, ErrInvalidCredentials
+	}
+	if err != nil {
+		return storage.User{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	if err := bcrypt.CompareHashAndPassword([]byte(user.PasswordHash), []byte(password)); err != nil {
+		return storage.User{}, ErrInvalidCredentials
+	}
+
+	return user, nil
+}
+
+// GetUser returns the user with the given name, including the built-in
+// accounts.
+func (a *Authenticator) GetUser(ctx context.Context, username string) (storage.User, error) {
+	if acc, ok := a.accounts[username]; ok {
+		return storage.User{Username: acc.Username, PasswordHash: acc.PasswordHash, Role: acc.Role}, nil
+	}
+
+	return a.users.GetUser(ctx, username)
+}
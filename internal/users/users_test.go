@@ -0,0 +1,114 @@
+package users_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/bcrypt"
+
+	"url-shortener/internal/storage"
+	"url-shortener/internal/users"
+	"url-shortener/internal/users/mocks"
+)
+
+func TestAuthenticator_Authenticate(t *testing.T) {
+	hash, err := bcrypt.GenerateFromPassword([]byte("secret123"), bcrypt.MinCost)
+	require.NoError(t, err)
+
+	alice := storage.User{ID: 1, Username: "alice", PasswordHash: string(hash), Role: storage.RoleUser}
+
+	adminHash, err := bcrypt.GenerateFromPassword([]byte("adminpass"), bcrypt.MinCost)
+	require.NoError(t, err)
+	accounts := []users.Account{
+		{Username: "admin", PasswordHash: string(adminHash), Role: storage.RoleAdmin},
+		{Username: "viewer", PasswordHash: string(hash), Role: storage.RoleUser},
+	}
+
+	cases := []struct {
+		name      string
+		username  string
+		password  string
+		lookup    bool
+		user      storage.User
+		mockError error
+		wantRole  string
+		wantErr   error
+	}{
+		{
+			name:     "Config admin",
+			username: "admin",
+			password: "adminpass",
+			wantRole: storage.RoleAdmin,
+		},
+		{
+			name:     "Config admin wrong password",
+			username: "admin",
+			password: "wrong",
+			wantErr:  users.ErrInvalidCredentials,
+		},
+		{
+			name:     "Config user",
+			username: "viewer",
+			password: "secret123",
+			wantRole: storage.RoleUser,
+		},
+		{
+			name:     "Stored user",
+			username: "alice",
+			password: "secret123",
+			lookup:   true,
+			user:     alice,
+			wantRole: storage.RoleUser,
+		},
+		{
+			name:     "Stored user wrong password",
+			username: "alice",
+			password: "wrong",
+			lookup:   true,
+			user:     alice,
+			wantErr:  users.ErrInvalidCredentials,
+		},
+		{
+			name:      "Unknown user",
+			username:  "bob",
+			password:  "secret123",
+			lookup:    true,
+			mockError: storage.ErrUserNotFound,
+			wantErr:   users.ErrInvalidCredentials,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			userGetterMock := mocks.NewUserGetter(t)
+
+			if tc.lookup {
+				userGetterMock.On("GetUser", mock.Anything, tc.username).
+					Return(tc.user, tc.mockError).Once()
+			}
+
+			user, err := users.New(accounts, userGetterMock).
+				Authenticate(context.Background(), tc.username, tc.password)
+			require.ErrorIs(t, err, tc.wantErr)
+
+			if tc.wantErr == nil {
+				require.Equal(t, tc.username, user.Username)
+				require.Equal(t, tc.wantRole, user.Role)
+			}
+		})
+	}
+}
+
+func TestAuthenticator_AuthenticateStorageError(t *testing.T) {
+	userGetterMock := mocks.NewUserGetter(t)
+	userGetterMock.On("GetUser", mock.Anything, "alice").
+		Return(storage.User{}, errors.New("unexpected error")).Once()
+
+	_, err := users.New(nil, userGetterMock).
+		Authenticate(context.Background(), "alice", "secret123")
+	require.Error(t, err)
+	require.NotErrorIs(t, err, users.ErrInvalidCredentials)
+}
@@ -0,0 +1,274 @@
+// Package webhook delivers link events to external HTTP endpoints. Events
+// are queued in memory and POSTed as JSON by background workers, retrying
+// with exponential backoff; events still queued when the service stops are
+// lost.
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"slices"
+	"strconv"
+	"sync"
+	"time"
+
+	"url-shortener/internal/lib/logger/sl"
+)
+
+// Event types.
+const (
+	EventLinkCreated = "link_created"
+	EventLinkDeleted = "link_deleted"
+	EventLinkClicked = "link_clicked"
+	EventLinkExpired = "link_expired"
+	// EventLinkBroken is sent when the destination of a link stops working.
+	EventLinkBroken = "link_broken"
+)
+
+// Events lists every event type.
+var Events = []string{EventLinkCreated, EventLinkDeleted, EventLinkClicked, EventLinkExpired, EventLinkBroken}
+
+// Headers sent with every delivery. The signature is the hex HMAC-SHA256 of
+// the timestamp, a dot and the body, keyed with the endpoint secret.
+const (
+	HeaderEvent     = "X-Webhook-Event"
+	HeaderID        = "X-Webhook-ID"
+	HeaderTimestamp = "X-Webhook-Timestamp"
+	HeaderSignature = "X-Webhook-Signature"
+)
+
+const (
+	workers    = 4
+	maxBackoff = time.Minute
+	userAgent  = "url-shortener-webhook/1.0"
+)
+
+// Event is the JSON body of a delivery. ID stays the same across retries,
+// so receivers can drop duplicates.
+type Event struct {
+	ID    string    `json:"id"`
+	Type  string    `json:"type"`
+	Time  time.Time `json:"time"`
+	Alias string    `json:"alias"`
+	URL   string    `json:"url,omitempty"`
+	Owner string    `json:"owner,omitempty"`
+	// Referrer, UserAgent, Country and Bot are set for link_clicked.
+	Referrer  string `json:"referrer,omitempty"`
+	UserAgent string `json:"user_agent,omitempty"`
+	Country   string `json:"country,omitempty"`
+	Bot       bool   `json:"bot,omitempty"`
+	// Health and StatusCode are set for link_broken.
+	Health     string `json:"health,omitempty"`
+	StatusCode int    `json:"status_code,omitempty"`
+}
+
+type Endpoint struct {
+	URL string
+	// Secret signs deliveries; an empty secret sends them unsigned.
+	Secret string
+	// Events the endpoint is subscribed to; empty means all of them.
+	Events []string
+}
+
+type Options struct {
+	// MaxAttempts is how many times a delivery is tried in total.
+	MaxAttempts int
+	// Backoff is the delay before the first retry; it doubles with every
+	// further retry, up to a minute.
+	Backoff time.Duration
+	// QueueSize is how many deliveries can wait; more are dropped.
+	QueueSize int
+}
+
+type delivery struct {
+	endpoint Endpoint
+	event    Event
+}
+
+// Dispatcher queues events and delivers them to the endpoints subscribed to
+// them.
+type Dispatcher struct {
+	log       *slog.Logger
+	client    *http.Client
+	endpoints []Endpoint
+	opts      Options
+	queue     chan delivery
+}
+
+// New returns a dispatcher for endpoints. It fails if an endpoint has no
+// valid http(s) URL or subscribes to an unknown event.
+func New(log *slog.Logger, client *http.Client, endpoints []Endpoint, opts Options) (*Dispatcher, error) {
+	const op = "webhook.New"
+
+	for _, e := range endpoints {
+		u, err := url.Parse(e.URL)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return nil, fmt.Errorf("%s: invalid endpoint url %q", op, e.URL)
+		}
+		for _, event := range e.Events {
+			if !slices.Contains(Events, event) {
+				return nil, fmt.Errorf("%s: unknown event %q", op, event)
+			}
+		}
+	}
+
+	opts.MaxAttempts = max(opts.MaxAttempts, 1)
+
+	return &Dispatcher{
+		log:       log.With(slog.String("component", "webhook")),
+		client:    client,
+		endpoints: endpoints,
+		opts:      opts,
+		queue:     make(chan delivery, max(opts.QueueSize, 1)),
+	}, nil
+}
+
+// Stamp fills in the ID and Time of e if they are empty.
+func (e *Event) Stamp() {
+	if e.ID == "" {
+		e.ID = newID()
+	}
+	if e.Time.IsZero() {
+		e.Time = time.Now().UTC()
+	}
+}
+
+// Notify queues event for every endpoint subscribed to it without blocking.
+// ID and Time are filled in if empty.
+func (d *Dispatcher) Notify(event Event) {
+	event.Stamp()
+
+	for _, e := range d.endpoints {
+		if len(e.Events) > 0 && !slices.Contains(e.Events, event.Type) {
+			continue
+		}
+
+		select {
+		case d.queue <- delivery{endpoint: e, event: event}:
+		default:
+			d.log.Warn("webhook queue is full, event dropped",
+				slog.String("event", event.Type), slog.String("alias", event.Alias), slog.String("endpoint", e.URL))
+		}
+	}
+}
+
+// Run delivers queued events until ctx is cancelled.
+func (d *Dispatcher) Run(ctx context.Context) {
+	var wg sync.WaitGroup
+	for range workers {
+		wg.Go(func() {
+			for {
+				select {
+				case <-ctx.Done():
+					return
+				case dl := <-d.queue:
+					d.deliver(ctx, dl)
+				}
+			}
+		})
+	}
+	wg.Wait()
+}
+
+// errPermanent marks responses that retrying will not fix.
+var errPermanent = errors.New("permanent failure")
+
+func (d *Dispatcher) deliver(ctx context.Context, dl delivery) {
+	log := d.log.With(
+		slog.String("event", dl.event.Type),
+		slog.String("id", dl.event.ID),
+		slog.String("endpoint", dl.endpoint.URL),
+	)
+
+	body, err := json.Marshal(dl.event)
+	if err != nil {
+		log.Error("failed to encode webhook event", sl.Err(err))
+		return
+	}
+
+	backoff := d.opts.Backoff
+	for attempt := 1; ; attempt++ {
+		err := d.send(ctx, dl, body)
+		if err == nil {
+			return
+		}
+
+		if errors.Is(err, errPermanent) || attempt >= d.opts.MaxAttempts || ctx.Err() != nil {
+			log.Error("failed to deliver webhook", slog.Int("attempts", attempt), sl.Err(err))
+			return
+		}
+
+		log.Info("webhook delivery failed, retrying",
+			slog.Int("attempt", attempt), slog.Duration("backoff", backoff), sl.Err(err))
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(backoff):
+		}
+		backoff = min(backoff*2, maxBackoff)
+	}
+}
+
+func (d *Dispatcher) send(ctx context.Context, dl delivery, body []byte) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, dl.endpoint.URL, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("%w: %w", errPermanent, err)
+	}
+
+	timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", userAgent)
+	req.Header.Set(HeaderEvent, dl.event.Type)
+	req.Header.Set(HeaderID, dl.event.ID)
+	req.Header.Set(HeaderTimestamp, timestamp)
+	if dl.endpoint.Secret != "" {
+		req.Header.Set(HeaderSignature, "sha256="+Sign(dl.endpoint.Secret, timestamp, body))
+	}
+
+	res, err := d.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+	// Drain a little of the body so the connection can be reused.
+	_, _ = io.Copy(io.Discard, io.LimitReader(res.Body, 64<<10))
+
+	switch {
+	case res.StatusCode >= 200 && res.StatusCode <= 299:
+		return nil
+	case res.StatusCode == http.StatusTooManyRequests || res.StatusCode >= 500:
+		return fmt.Errorf("unexpected status %d", res.StatusCode)
+	default:
+		return fmt.Errorf("%w: unexpected status %d", errPermanent, res.StatusCode)
+	}
+}
+
+// Sign returns the signature of a delivery made at timestamp (Unix seconds)
+// with body, as sent after "sha256=" in the X-Webhook-Signature header.
+func Sign(secret string, timestamp string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp))
+	mac.Write([]byte("."))
+	mac.Write(body)
+
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+func newID() string {
+	b := make([]byte, 16)
+	_, _ = rand.Read(b)
+
+	return hex.EncodeToString(b)
+}
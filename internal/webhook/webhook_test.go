@@ -0,0 +1,115 @@
+package webhook_test
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"url-shortener/internal/lib/logger/handlers/slogdiscard"
+	"url-shortener/internal/webhook"
+)
+
+func TestDispatcher_Deliver(t *testing.T) {
+	const secret = "s3cret"
+
+	received := make(chan webhook.Event, 1)
+	var calls atomic.Int32
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// The first attempt fails and has to be retried.
+		if calls.Add(1) == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+
+		body, err := io.ReadAll(r.Body)
+		require.NoError(t, err)
+
+		timestamp := r.Header.Get(webhook.HeaderTimestamp)
+		require.Equal(t, "sha256="+webhook.Sign(secret, timestamp, body), r.Header.Get(webhook.HeaderSignature))
+		require.Equal(t, webhook.EventLinkCreated, r.Header.Get(webhook.HeaderEvent))
+
+		var event webhook.Event
+		require.NoError(t, json.Unmarshal(body, &event))
+		require.Equal(t, event.ID, r.Header.Get(webhook.HeaderID))
+		received <- event
+	}))
+	defer ts.Close()
+
+	d, err := webhook.New(slogdiscard.NewDiscardLogger(), ts.Client(), []webhook.Endpoint{
+		{URL: ts.URL, Secret: secret},
+	}, webhook.Options{MaxAttempts: 3, Backoff: time.Millisecond, QueueSize: 10})
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go d.Run(ctx)
+
+	d.Notify(webhook.Event{Type: webhook.EventLinkCreated, Alias: "abc", URL: "https://example.com"})
+
+	select {
+	case event := <-received:
+		require.Equal(t, "abc", event.Alias)
+		require.Equal(t, "https://example.com", event.URL)
+		require.NotEmpty(t, event.ID)
+		require.False(t, event.Time.IsZero())
+	case <-time.After(5 * time.Second):
+		t.Fatal("event was not delivered")
+	}
+	require.EqualValues(t, 2, calls.Load())
+}
+
+func TestDispatcher_Subscriptions(t *testing.T) {
+	var clicked, other atomic.Int32
+	done := make(chan struct{})
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/clicks":
+			clicked.Add(1)
+		case "/gone":
+			// Client errors are not retried.
+			other.Add(1)
+			w.WriteHeader(http.StatusGone)
+			close(done)
+		}
+	}))
+	defer ts.Close()
+
+	d, err := webhook.New(slogdiscard.NewDiscardLogger(), ts.Client(), []webhook.Endpoint{
+		{URL: ts.URL + "/clicks", Events: []string{webhook.EventLinkClicked}},
+		{URL: ts.URL + "/gone", Events: []string{webhook.EventLinkDeleted}},
+	}, webhook.Options{MaxAttempts: 3, Backoff: time.Millisecond, QueueSize: 10})
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go d.Run(ctx)
+
+	d.Notify(webhook.Event{Type: webhook.EventLinkDeleted, Alias: "abc"})
+
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("event was not delivered")
+	}
+	time.Sleep(50 * time.Millisecond)
+
+	require.Zero(t, clicked.Load())
+	require.EqualValues(t, 1, other.Load())
+}
+
+func TestNew_Invalid(t *testing.T) {
+	_, err := webhook.New(slogdiscard.NewDiscardLogger(), http.DefaultClient,
+		[]webhook.Endpoint{{URL: "ftp://example.com"}}, webhook.Options{})
+	require.Error(t, err)
+
+	_, err = webhook.New(slogdiscard.NewDiscardLogger(), http.DefaultClient,
+		[]webhook.Endpoint{{URL: "https://example.com", Events: []string{"link_renamed"}}}, webhook.Options{})
+	require.Error(t, err)
+}
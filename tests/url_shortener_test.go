@@ -1,21 +1,39 @@
 package tests
 
 import (
+	"bufio"
+	"context"
+	"fmt"
 	"net/http"
 	"net/url"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/brianvoe/gofakeit/v6"
 	"github.com/gavv/httpexpect/v2"
 	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
 
+	shortenerv1 "url-shortener/api/proto/shortener/v1"
+	"url-shortener/internal/http-server/handlers/auth/login"
+	keysCreate "url-shortener/internal/http-server/handlers/keys/create"
+	"url-shortener/internal/http-server/handlers/url/batch"
+	"url-shortener/internal/http-server/handlers/url/block"
 	"url-shortener/internal/http-server/handlers/url/save"
+	"url-shortener/internal/http-server/handlers/url/update"
+	usersCreate "url-shortener/internal/http-server/handlers/users/create"
 	"url-shortener/internal/lib/api"
 	"url-shortener/internal/lib/random"
 )
 
 const (
-	host = "localhost:8082"
+	host     = "localhost:8082"
+	grpcHost = "localhost:44044"
 )
 
 func TestURLShortener_HappyPath(t *testing.T) {
@@ -26,7 +44,7 @@ func TestURLShortener_HappyPath(t *testing.T) {
 
 	e := httpexpect.Default(t, u.String())
 
-	e.POST("/url").
+	e.POST("/api/v1/url").
 		WithJSON(save.Request{
 			URL:   gofakeit.URL(),
 			Alias: random.NewRandomString(10),
@@ -68,7 +86,7 @@ func TestURLShortener_SaveURL(t *testing.T) {
 
 	for _, tc := range testCases {
 		t.Run(tc.name, func(t *testing.T) {
-			resp := e.POST("/url").
+			resp := e.POST("/api/v1/url").
 				WithJSON(save.Request{
 					URL:   tc.url,
 					Alias: tc.alias,
@@ -110,7 +128,7 @@ func TestURLShortener_GetURL(t *testing.T) {
 	testURL := "https://example.com"
 	testAlias := random.NewRandomString(8)
 
-	e.POST("/url").
+	e.POST("/api/v1/url").
 		WithJSON(save.Request{
 			URL:   testURL,
 			Alias: testAlias,
@@ -166,7 +184,7 @@ func TestURLShortener_DuplicateAlias(t *testing.T) {
 	testAlias := random.NewRandomString(8)
 
 	// Создаем первый URL
-	e.POST("/url").
+	e.POST("/api/v1/url").
 		WithJSON(save.Request{
 			URL:   testURL,
 			Alias: testAlias,
@@ -176,7 +194,7 @@ func TestURLShortener_DuplicateAlias(t *testing.T) {
 		Status(http.StatusOK)
 
 	// Пытаемся создать с тем же alias
-	e.POST("/url").
+	e.POST("/api/v1/url").
 		WithJSON(save.Request{
 			URL:   "https://another-url.com",
 			Alias: testAlias,
@@ -212,7 +230,7 @@ func TestURLShortener_RandomAlias(t *testing.T) {
 	testURL := "https://example.com"
 
 	// Создаем URL без указания alias
-	resp := e.POST("/url").
+	resp := e.POST("/api/v1/url").
 		WithJSON(save.Request{
 			URL: testURL,
 		}).
@@ -242,7 +260,7 @@ func TestURLShortener_RedirectFlow(t *testing.T) {
 	testURL := "https://example.com"
 
 	// Создаем короткую ссылку
-	resp := e.POST("/url").
+	resp := e.POST("/api/v1/url").
 		WithJSON(save.Request{
 			URL: testURL,
 		}).
@@ -289,7 +307,7 @@ func TestURLShortener_CollisionHandling(t *testing.T) {
 	}
 
 	for _, testURL := range testURLs {
-		resp := e.POST("/url").
+		resp := e.POST("/api/v1/url").
 			WithJSON(save.Request{
 				URL: testURL,
 			}).
@@ -321,7 +339,7 @@ func TestURLShortener_Authentication(t *testing.T) {
 	testURL := "https://auth-test.com"
 
 	// Тест без аутентификации
-	e.POST("/url").
+	e.POST("/api/v1/url").
 		WithJSON(save.Request{
 			URL: testURL,
 		}).
@@ -329,7 +347,7 @@ func TestURLShortener_Authentication(t *testing.T) {
 		Status(http.StatusUnauthorized)
 
 	// Тест с неправильными данными
-	e.POST("/url").
+	e.POST("/api/v1/url").
 		WithJSON(save.Request{
 			URL: testURL,
 		}).
@@ -338,7 +356,7 @@ func TestURLShortener_Authentication(t *testing.T) {
 		Status(http.StatusUnauthorized)
 
 	// Тест с правильными данными
-	e.POST("/url").
+	e.POST("/api/v1/url").
 		WithJSON(save.Request{
 			URL: testURL,
 		}).
@@ -346,3 +364,1550 @@ func TestURLShortener_Authentication(t *testing.T) {
 		Expect().
 		Status(http.StatusOK)
 }
+
+func TestURLShortener_LegalBlock(t *testing.T) {
+	u := &url.URL{
+		Scheme: "http",
+		Host:   host,
+	}
+
+	e := httpexpect.Default(t, u.String())
+
+	testAlias := random.NewRandomString(8)
+
+	e.POST("/api/v1/url").
+		WithJSON(save.Request{
+			URL:   "https://takedown-test.com",
+			Alias: testAlias,
+		}).
+		WithBasicAuth("myuser", "mypass").
+		Expect().
+		Status(http.StatusOK)
+
+	// Блокируем ссылку по юридическому требованию
+	e.POST("/api/v1/url/"+testAlias+"/block").
+		WithJSON(block.Request{
+			Reason:    "DMCA takedown",
+			Reference: "case-42",
+		}).
+		WithBasicAuth("myuser", "mypass").
+		Expect().
+		Status(http.StatusOK)
+
+	resp := e.GET("/" + testAlias).
+		Expect().
+		Status(http.StatusUnavailableForLegalReasons)
+
+	resp.Header("Link").Contains(`rel="blocked-by"`)
+	resp.JSON().Path("$.reference").String().IsEqual("case-42")
+	resp.JSON().Path("$.notice_url").String().NotEmpty()
+}
+
+func TestURLShortener_DeleteURL(t *testing.T) {
+	u := &url.URL{
+		Scheme: "http",
+		Host:   host,
+	}
+
+	e := httpexpect.Default(t, u.String())
+
+	testAlias := random.NewRandomString(8)
+
+	e.POST("/api/v1/url").
+		WithJSON(save.Request{
+			URL:   "https://delete-test.com",
+			Alias: testAlias,
+		}).
+		WithBasicAuth("myuser", "mypass").
+		Expect().
+		Status(http.StatusOK)
+
+	// Удаление без аутентификации запрещено
+	e.DELETE("/api/v1/url/" + testAlias).
+		Expect().
+		Status(http.StatusUnauthorized)
+
+	e.DELETE("/api/v1/url/"+testAlias).
+		WithBasicAuth("myuser", "mypass").
+		Expect().
+		Status(http.StatusOK).
+		JSON().Path("$.status").String().IsEqual("OK")
+
+	// Повторное удаление возвращает 404
+	e.DELETE("/api/v1/url/"+testAlias).
+		WithBasicAuth("myuser", "mypass").
+		Expect().
+		Status(http.StatusNotFound).
+		JSON().Path("$.error").String().NotEmpty()
+
+	e.GET("/" + testAlias).
+		Expect().
+		JSON().Path("$.error").String().NotEmpty()
+}
+
+func TestURLShortener_UpdateURL(t *testing.T) {
+	u := &url.URL{
+		Scheme: "http",
+		Host:   host,
+	}
+
+	// Создаем HTTP клиент без автоматического следования редиректам
+	client := &http.Client{
+		CheckRedirect: func(req *http.Request, via []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+
+	e := httpexpect.WithConfig(httpexpect.Config{
+		BaseURL:  u.String(),
+		Client:   client,
+		Reporter: httpexpect.NewAssertReporter(t),
+		Printers: []httpexpect.Printer{
+			httpexpect.NewDebugPrinter(t, true),
+		},
+	})
+
+	testAlias := random.NewRandomString(8)
+
+	e.POST("/api/v1/url").
+		WithJSON(save.Request{
+			URL:   "https://update-test.com",
+			Alias: testAlias,
+		}).
+		WithBasicAuth("myuser", "mypass").
+		Expect().
+		Status(http.StatusOK)
+
+	e.PATCH("/api/v1/url/"+testAlias).
+		WithJSON(update.Request{
+			URL: "https://update-test.com/new",
+		}).
+		WithBasicAuth("myuser", "mypass").
+		Expect().
+		Status(http.StatusOK).
+		JSON().Path("$.url").String().IsEqual("https://update-test.com/new")
+
+	e.GET("/" + testAlias).
+		Expect().
+		Status(http.StatusFound).
+		Header("Location").IsEqual("https://update-test.com/new")
+}
+
+func TestURLShortener_ListURLs(t *testing.T) {
+	u := &url.URL{
+		Scheme: "http",
+		Host:   host,
+	}
+
+	e := httpexpect.Default(t, u.String())
+
+	testAlias := random.NewRandomString(8)
+
+	e.POST("/api/v1/url").
+		WithJSON(save.Request{
+			URL:   "https://list-test.com",
+			Alias: testAlias,
+		}).
+		WithBasicAuth("myuser", "mypass").
+		Expect().
+		Status(http.StatusOK)
+
+	e.GET("/api/v1/urls").
+		Expect().
+		Status(http.StatusUnauthorized)
+
+	// Новая ссылка должна быть первой при сортировке по убыванию даты
+	resp := e.GET("/api/v1/urls").
+		WithQuery("limit", 1).
+		WithQuery("order", "desc").
+		WithBasicAuth("myuser", "mypass").
+		Expect().
+		Status(http.StatusOK).
+		JSON()
+
+	resp.Path("$.urls[0].alias").String().IsEqual(testAlias)
+	resp.Path("$.urls[0].created_at").String().NotEmpty()
+
+	e.GET("/api/v1/urls").
+		WithQuery("limit", 0).
+		WithBasicAuth("myuser", "mypass").
+		Expect().
+		Status(http.StatusBadRequest)
+}
+
+func TestURLShortener_Stats(t *testing.T) {
+	u := &url.URL{
+		Scheme: "http",
+		Host:   host,
+	}
+
+	// Создаем HTTP клиент без автоматического следования редиректам
+	client := &http.Client{
+		CheckRedirect: func(req *http.Request, via []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+
+	e := httpexpect.WithConfig(httpexpect.Config{
+		BaseURL:  u.String(),
+		Client:   client,
+		Reporter: httpexpect.NewAssertReporter(t),
+		Printers: []httpexpect.Printer{
+			httpexpect.NewDebugPrinter(t, true),
+		},
+	})
+
+	testAlias := random.NewRandomString(8)
+
+	e.POST("/api/v1/url").
+		WithJSON(save.Request{
+			URL:   "https://stats-test.com",
+			Alias: testAlias,
+		}).
+		WithBasicAuth("myuser", "mypass").
+		Expect().
+		Status(http.StatusOK)
+
+	for i := 0; i < 3; i++ {
+		e.GET("/"+testAlias).
+			WithHeader("Referer", "https://referrer.example").
+			WithHeader("User-Agent", "Mozilla/5.0 (X11; Linux x86_64) Firefox/125.0").
+			Expect().
+			Status(http.StatusFound)
+	}
+
+	// A crawler is redirected, but left out of stats by default.
+	e.GET("/"+testAlias).
+		WithHeader("User-Agent", "Mozilla/5.0 (compatible; Googlebot/2.1)").
+		Expect().
+		Status(http.StatusFound)
+
+	resp := e.GET("/api/v1/url/"+testAlias+"/stats").
+		WithBasicAuth("myuser", "mypass").
+		Expect().
+		Status(http.StatusOK).
+		JSON()
+
+	resp.Path("$.total_clicks").Number().IsEqual(3)
+	resp.Path("$.last_access").String().NotEmpty()
+	resp.Path("$.daily[0].clicks").Number().IsEqual(3)
+
+	e.GET("/api/v1/url/"+testAlias+"/stats").
+		WithQuery("include_bots", "true").
+		WithBasicAuth("myuser", "mypass").
+		Expect().
+		Status(http.StatusOK).
+		JSON().
+		Path("$.total_clicks").Number().IsEqual(4)
+
+	refs := e.GET("/api/v1/url/"+testAlias+"/referrers").
+		WithBasicAuth("myuser", "mypass").
+		Expect().
+		Status(http.StatusOK).
+		JSON()
+
+	refs.Path("$.referrers[0].domain").String().IsEqual("referrer.example")
+	refs.Path("$.referrers[0].clicks").Number().IsEqual(3)
+	refs.Path("$.direct_clicks").Number().IsEqual(0)
+
+	// Without a GeoIP database every country is unknown.
+	geo := e.GET("/api/v1/url/"+testAlias+"/geo").
+		WithBasicAuth("myuser", "mypass").
+		Expect().
+		Status(http.StatusOK).
+		JSON()
+
+	geo.Path("$.unknown_clicks").Number().IsEqual(3)
+	geo.Path("$.countries").Array().IsEmpty()
+
+	export := e.GET("/api/v1/url/"+testAlias+"/stats/export").
+		WithBasicAuth("myuser", "mypass").
+		Expect().
+		Status(http.StatusOK)
+
+	export.Header("Content-Type").HasPrefix("text/csv")
+	// The header row and one row per click of people.
+	require.Len(t, strings.Split(strings.TrimSpace(export.Body().Raw()), "\n"), 4)
+
+	e.GET("/api/v1/url/nonexistent-alias/stats").
+		WithBasicAuth("myuser", "mypass").
+		Expect().
+		Status(http.StatusNotFound)
+}
+
+func TestURLShortener_Expiration(t *testing.T) {
+	u := &url.URL{
+		Scheme: "http",
+		Host:   host,
+	}
+
+	e := httpexpect.Default(t, u.String())
+
+	testAlias := random.NewRandomString(8)
+
+	e.POST("/api/v1/url").
+		WithJSON(save.Request{
+			URL:   "https://expiring-link.com",
+			Alias: testAlias,
+			TTL:   "1s",
+		}).
+		WithBasicAuth("myuser", "mypass").
+		Expect().
+		Status(http.StatusOK).
+		JSON().
+		Path("$.expires_at").String().NotEmpty()
+
+	// Пока срок не истёк, ссылка работает
+	e.GET("/" + testAlias).
+		WithRedirectPolicy(httpexpect.DontFollowRedirects).
+		Expect().
+		Status(http.StatusFound)
+
+	time.Sleep(1500 * time.Millisecond)
+
+	e.GET("/" + testAlias).
+		Expect().
+		Status(http.StatusGone).
+		JSON().
+		Path("$.error").String().IsEqual("url expired")
+}
+
+func TestURLShortener_MaxClicks(t *testing.T) {
+	u := &url.URL{
+		Scheme: "http",
+		Host:   host,
+	}
+
+	e := httpexpect.Default(t, u.String())
+
+	testAlias := random.NewRandomString(8)
+
+	e.POST("/api/v1/url").
+		WithJSON(save.Request{
+			URL:       "https://one-time-link.com",
+			Alias:     testAlias,
+			MaxClicks: 1,
+		}).
+		WithBasicAuth("myuser", "mypass").
+		Expect().
+		Status(http.StatusOK).
+		JSON().
+		Path("$.max_clicks").Number().IsEqual(1)
+
+	e.GET("/" + testAlias).
+		WithRedirectPolicy(httpexpect.DontFollowRedirects).
+		Expect().
+		Status(http.StatusFound)
+
+	e.GET("/" + testAlias).
+		Expect().
+		Status(http.StatusGone).
+		JSON().
+		Path("$.error").String().IsEqual("url click limit reached")
+}
+
+func TestURLShortener_RedirectType(t *testing.T) {
+	u := &url.URL{
+		Scheme: "http",
+		Host:   host,
+	}
+
+	e := httpexpect.Default(t, u.String())
+
+	testAlias := random.NewRandomString(8)
+
+	e.POST("/api/v1/url").
+		WithJSON(save.Request{
+			URL:          "https://permanent-link.com",
+			Alias:        testAlias,
+			RedirectType: http.StatusMovedPermanently,
+		}).
+		WithBasicAuth("myuser", "mypass").
+		Expect().
+		Status(http.StatusOK).
+		JSON().
+		Path("$.redirect_type").Number().IsEqual(http.StatusMovedPermanently)
+
+	e.GET("/" + testAlias).
+		WithRedirectPolicy(httpexpect.DontFollowRedirects).
+		Expect().
+		Status(http.StatusMovedPermanently).
+		Header("Location").IsEqual("https://permanent-link.com")
+
+	e.POST("/api/v1/url").
+		WithJSON(save.Request{URL: "https://permanent-link.com", RedirectType: http.StatusSeeOther}).
+		WithBasicAuth("myuser", "mypass").
+		Expect().
+		Status(http.StatusOK).
+		JSON().
+		Path("$.error").String().IsEqual("field RedirectType is not valid")
+}
+
+func TestURLShortener_CacheControl(t *testing.T) {
+	u := &url.URL{
+		Scheme: "http",
+		Host:   host,
+	}
+
+	e := httpexpect.Default(t, u.String())
+
+	testAlias := random.NewRandomString(8)
+
+	e.POST("/api/v1/url").
+		WithJSON(save.Request{
+			URL:          "https://cached-link.com",
+			Alias:        testAlias,
+			RedirectType: http.StatusMovedPermanently,
+			CacheMaxAge:  86400,
+		}).
+		WithBasicAuth("myuser", "mypass").
+		Expect().
+		Status(http.StatusOK).
+		JSON().
+		Path("$.cache_max_age").Number().IsEqual(86400)
+
+	e.GET("/" + testAlias).
+		WithRedirectPolicy(httpexpect.DontFollowRedirects).
+		Expect().
+		Status(http.StatusMovedPermanently).
+		Header("Cache-Control").IsEqual("public, max-age=86400")
+}
+
+func TestURLShortener_OpenGraph(t *testing.T) {
+	u := &url.URL{
+		Scheme: "http",
+		Host:   host,
+	}
+
+	e := httpexpect.Default(t, u.String())
+
+	testAlias := random.NewRandomString(8)
+
+	e.POST("/api/v1/url").
+		WithJSON(save.Request{
+			URL:   "https://branded-link.com",
+			Alias: testAlias,
+			OG: &save.OpenGraph{
+				Title: "Spring sale",
+				Image: "https://branded-link.com/sale.png",
+			},
+		}).
+		WithBasicAuth("myuser", "mypass").
+		Expect().
+		Status(http.StatusOK).
+		JSON().
+		Path("$.og.title").String().IsEqual("Spring sale")
+
+	body := e.GET("/"+testAlias).
+		WithHeader("User-Agent", "Slackbot-LinkExpanding 1.0 (+https://api.slack.com/robots)").
+		WithRedirectPolicy(httpexpect.DontFollowRedirects).
+		Expect().
+		Status(http.StatusOK).
+		Body()
+	body.Contains(`<meta property="og:title" content="Spring sale">`)
+	body.Contains(`<meta property="og:image" content="https://branded-link.com/sale.png">`)
+	body.NotContains("https://branded-link.com\"")
+
+	e.GET("/"+testAlias).
+		WithHeader("User-Agent", "Mozilla/5.0 (X11; Linux x86_64) Firefox/125.0").
+		WithRedirectPolicy(httpexpect.DontFollowRedirects).
+		Expect().
+		Status(http.StatusFound).
+		Header("Location").IsEqual("https://branded-link.com")
+}
+
+func TestURLShortener_UTM(t *testing.T) {
+	u := &url.URL{
+		Scheme: "http",
+		Host:   host,
+	}
+
+	e := httpexpect.Default(t, u.String())
+
+	testAlias := random.NewRandomString(8)
+
+	e.POST("/api/v1/url").
+		WithJSON(save.Request{
+			URL:   "https://utm-link.com/?ref=1",
+			Alias: testAlias,
+			UTM:   &save.UTM{Source: "newsletter", Medium: "email"},
+		}).
+		WithBasicAuth("myuser", "mypass").
+		Expect().
+		Status(http.StatusOK).
+		JSON().
+		Path("$.utm.source").String().IsEqual("newsletter")
+
+	e.GET("/" + testAlias).
+		WithRedirectPolicy(httpexpect.DontFollowRedirects).
+		Expect().
+		Status(http.StatusFound).
+		Header("Location").IsEqual("https://utm-link.com/?ref=1&utm_medium=email&utm_source=newsletter")
+}
+
+func TestURLShortener_Preview(t *testing.T) {
+	u := &url.URL{
+		Scheme: "http",
+		Host:   host,
+	}
+
+	e := httpexpect.Default(t, u.String())
+
+	testAlias := random.NewRandomString(8)
+
+	e.POST("/api/v1/url").
+		WithJSON(save.Request{
+			URL:       "https://preview-link.com/page",
+			Alias:     testAlias,
+			MaxClicks: 1,
+		}).
+		WithBasicAuth("myuser", "mypass").
+		Expect().
+		Status(http.StatusOK)
+
+	for _, req := range []*httpexpect.Request{
+		e.GET("/" + testAlias + "+"),
+		e.GET("/" + testAlias).WithQuery("preview", 1),
+	} {
+		res := req.
+			WithRedirectPolicy(httpexpect.DontFollowRedirects).
+			Expect().
+			Status(http.StatusOK)
+		res.Header("Content-Type").HasPrefix("text/html")
+		res.Body().Contains("https://preview-link.com/page")
+	}
+
+	// Previews do not use up the only allowed click.
+	e.GET("/" + testAlias).
+		WithRedirectPolicy(httpexpect.DontFollowRedirects).
+		Expect().
+		Status(http.StatusFound)
+}
+
+func TestURLShortener_PasswordProtected(t *testing.T) {
+	u := &url.URL{
+		Scheme: "http",
+		Host:   host,
+	}
+
+	e := httpexpect.Default(t, u.String())
+
+	testAlias := random.NewRandomString(8)
+
+	e.POST("/api/v1/url").
+		WithJSON(save.Request{
+			URL:      "https://protected-link.com",
+			Alias:    testAlias,
+			Password: "secret",
+		}).
+		WithBasicAuth("myuser", "mypass").
+		Expect().
+		Status(http.StatusOK).
+		JSON().
+		Path("$.protected").Boolean().IsTrue()
+
+	e.GET("/" + testAlias).
+		Expect().
+		Status(http.StatusUnauthorized).
+		JSON().
+		Path("$.error").String().IsEqual("password required")
+
+	e.POST("/"+testAlias).
+		WithFormField("password", "wrong").
+		Expect().
+		Status(http.StatusUnauthorized).
+		JSON().
+		Path("$.error").String().IsEqual("invalid password")
+
+	// The password is never taken from the query string.
+	e.GET("/"+testAlias).
+		WithQuery("password", "secret").
+		Expect().
+		Status(http.StatusUnauthorized).
+		JSON().
+		Path("$.error").String().IsEqual("password required")
+
+	e.POST("/"+testAlias).
+		WithFormField("password", "secret").
+		WithRedirectPolicy(httpexpect.DontFollowRedirects).
+		Expect().
+		Status(http.StatusFound).
+		Header("Location").IsEqual("https://protected-link.com")
+}
+
+func TestURLShortener_QR(t *testing.T) {
+	u := &url.URL{
+		Scheme: "http",
+		Host:   host,
+	}
+
+	e := httpexpect.Default(t, u.String())
+
+	testAlias := random.NewRandomString(8)
+
+	e.POST("/api/v1/url").
+		WithJSON(save.Request{
+			URL:   "https://qr-link.com",
+			Alias: testAlias,
+		}).
+		WithBasicAuth("myuser", "mypass").
+		Expect().
+		Status(http.StatusOK)
+
+	e.GET("/api/v1/url/"+testAlias+"/qr").
+		WithBasicAuth("myuser", "mypass").
+		Expect().
+		Status(http.StatusOK).
+		ContentType("image/png")
+
+	e.GET("/api/v1/url/"+testAlias+"/qr").
+		WithQuery("format", "svg").
+		WithBasicAuth("myuser", "mypass").
+		Expect().
+		Status(http.StatusOK).
+		ContentType("image/svg+xml")
+
+	e.GET("/api/v1/url/"+random.NewRandomString(12)+"/qr").
+		WithBasicAuth("myuser", "mypass").
+		Expect().
+		Status(http.StatusNotFound)
+}
+
+func TestURLShortener_Events(t *testing.T) {
+	u := &url.URL{
+		Scheme: "http",
+		Host:   host,
+	}
+
+	e := httpexpect.Default(t, u.String())
+
+	testAlias := random.NewRandomString(8)
+
+	e.POST("/api/v1/url").
+		WithJSON(save.Request{
+			URL:   "https://events-link.com",
+			Alias: testAlias,
+		}).
+		WithBasicAuth("myuser", "mypass").
+		Expect().
+		Status(http.StatusOK)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u.String()+"/api/v1/url/"+testAlias+"/events", nil)
+	require.NoError(t, err)
+	req.SetBasicAuth("myuser", "mypass")
+
+	// The headers are flushed once the stream is subscribed, so clicks
+	// made after Do returns are not missed.
+	res, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	defer res.Body.Close()
+	require.Equal(t, http.StatusOK, res.StatusCode)
+	require.Equal(t, "text/event-stream", res.Header.Get("Content-Type"))
+
+	e.GET("/"+testAlias).
+		WithRedirectPolicy(httpexpect.DontFollowRedirects).
+		WithHeader("User-Agent", "Mozilla/5.0 (X11; Linux x86_64) Firefox/125.0").
+		Expect().
+		Status(http.StatusFound)
+
+	sc := bufio.NewScanner(res.Body)
+	for sc.Scan() && sc.Text() != "event: click" {
+	}
+	require.True(t, sc.Scan(), "no click event")
+	require.Contains(t, sc.Text(), `"alias":"`+testAlias+`"`)
+}
+
+func TestURLShortener_JWTAuthentication(t *testing.T) {
+	u := &url.URL{
+		Scheme: "http",
+		Host:   host,
+	}
+
+	e := httpexpect.Default(t, u.String())
+
+	e.POST("/api/v1/auth/login").
+		WithJSON(login.Request{
+			Username: "myuser",
+			Password: "wrong",
+		}).
+		Expect().
+		Status(http.StatusUnauthorized)
+
+	token := e.POST("/api/v1/auth/login").
+		WithJSON(login.Request{
+			Username: "myuser",
+			Password: "mypass",
+		}).
+		Expect().
+		Status(http.StatusOK).
+		JSON().
+		Path("$.token").String().NotEmpty().Raw()
+
+	e.POST("/api/v1/url").
+		WithJSON(save.Request{
+			URL: "https://jwt-test.com",
+		}).
+		WithHeader("Authorization", "Bearer "+token).
+		Expect().
+		Status(http.StatusOK).
+		JSON().
+		Path("$.alias").String().NotEmpty()
+
+	e.POST("/api/v1/url").
+		WithJSON(save.Request{
+			URL: "https://jwt-test.com",
+		}).
+		WithHeader("Authorization", "Bearer "+token+"x").
+		Expect().
+		Status(http.StatusUnauthorized)
+}
+
+func TestURLShortener_APIKeys(t *testing.T) {
+	u := &url.URL{
+		Scheme: "http",
+		Host:   host,
+	}
+
+	e := httpexpect.Default(t, u.String())
+
+	created := e.POST("/api/v1/keys").
+		WithJSON(keysCreate.Request{Name: "e2e"}).
+		WithBasicAuth("myuser", "mypass").
+		Expect().
+		Status(http.StatusCreated).
+		JSON().Object()
+
+	key := created.Value("key").String().NotEmpty().Raw()
+	keyID := int64(created.Value("id").Number().Raw())
+
+	testAlias := random.NewRandomString(8)
+
+	e.POST("/api/v1/url").
+		WithJSON(save.Request{
+			URL:   "https://api-key-link.com",
+			Alias: testAlias,
+		}).
+		WithHeader("X-Api-Key", key).
+		Expect().
+		Status(http.StatusOK)
+
+	// Ключами нельзя управлять ключами
+	e.GET("/api/v1/keys").
+		WithHeader("X-Api-Key", key).
+		Expect().
+		Status(http.StatusUnauthorized)
+
+	e.DELETE(fmt.Sprintf("/api/v1/keys/%d", keyID)).
+		WithBasicAuth("myuser", "mypass").
+		Expect().
+		Status(http.StatusOK)
+
+	e.POST("/api/v1/url").
+		WithJSON(save.Request{
+			URL: "https://api-key-link.com",
+		}).
+		WithHeader("X-Api-Key", key).
+		Expect().
+		Status(http.StatusUnauthorized)
+
+	// Ссылка, созданная отозванным ключом, продолжает работать
+	e.GET("/" + testAlias).
+		WithRedirectPolicy(httpexpect.DontFollowRedirects).
+		Expect().
+		Status(http.StatusFound)
+}
+
+func TestURLShortener_Ownership(t *testing.T) {
+	u := &url.URL{
+		Scheme: "http",
+		Host:   host,
+	}
+
+	e := httpexpect.Default(t, u.String())
+
+	alice := "alice_" + random.NewRandomString(6)
+	bob := "bob_" + random.NewRandomString(6)
+
+	for _, name := range []string{alice, bob} {
+		e.POST("/api/v1/users").
+			WithJSON(usersCreate.Request{Username: name, Password: "password1"}).
+			WithBasicAuth("myuser", "mypass").
+			Expect().
+			Status(http.StatusCreated).
+			JSON().Object().
+			HasValue("role", "editor")
+	}
+
+	// Только администратор создаёт пользователей
+	e.POST("/api/v1/users").
+		WithJSON(usersCreate.Request{Username: "mallory", Password: "password1"}).
+		WithBasicAuth(alice, "password1").
+		Expect().
+		Status(http.StatusForbidden)
+
+	testAlias := random.NewRandomString(8)
+
+	e.POST("/api/v1/url").
+		WithJSON(save.Request{
+			URL:   "https://alice-link.com",
+			Alias: testAlias,
+		}).
+		WithBasicAuth(alice, "password1").
+		Expect().
+		Status(http.StatusOK)
+
+	e.GET("/api/v1/urls").
+		WithBasicAuth(alice, "password1").
+		Expect().
+		Status(http.StatusOK).
+		JSON().Path("$.urls[*].alias").Array().ContainsAll(testAlias)
+
+	e.GET("/api/v1/urls").
+		WithBasicAuth(bob, "password1").
+		Expect().
+		Status(http.StatusOK).
+		JSON().Path("$.urls").Array().IsEmpty()
+
+	// Чужие ссылки выглядят как несуществующие
+	e.PATCH("/api/v1/url/"+testAlias).
+		WithJSON(update.Request{URL: "https://bob-link.com"}).
+		WithBasicAuth(bob, "password1").
+		Expect().
+		Status(http.StatusNotFound)
+
+	e.DELETE("/api/v1/url/"+testAlias).
+		WithBasicAuth(bob, "password1").
+		Expect().
+		Status(http.StatusNotFound)
+
+	e.GET("/api/v1/urls").
+		WithBasicAuth("myuser", "mypass").
+		WithQuery("limit", 100).
+		Expect().
+		Status(http.StatusOK).
+		JSON().Path("$.urls[*].alias").Array().ContainsAll(testAlias)
+
+	e.DELETE("/api/v1/url/"+testAlias).
+		WithBasicAuth("myuser", "mypass").
+		Expect().
+		Status(http.StatusOK)
+}
+
+func TestURLShortener_ViewerRole(t *testing.T) {
+	u := &url.URL{
+		Scheme: "http",
+		Host:   host,
+	}
+
+	e := httpexpect.Default(t, u.String())
+
+	viewer := "viewer_" + random.NewRandomString(6)
+	e.POST("/api/v1/users").
+		WithJSON(usersCreate.Request{Username: viewer, Password: "password1", Role: "viewer"}).
+		WithBasicAuth("myuser", "mypass").
+		Expect().
+		Status(http.StatusCreated).
+		JSON().Object().
+		HasValue("role", "viewer")
+
+	testAlias := random.NewRandomString(8)
+	e.POST("/api/v1/url").
+		WithJSON(save.Request{URL: "https://viewer-test.com", Alias: testAlias}).
+		WithBasicAuth("myuser", "mypass").
+		Expect().
+		Status(http.StatusOK)
+
+	// Наблюдатель видит все ссылки и их статистику
+	e.GET("/api/v1/urls").
+		WithBasicAuth(viewer, "password1").
+		WithQuery("limit", 100).
+		Expect().
+		Status(http.StatusOK).
+		JSON().Path("$.urls[*].alias").Array().ContainsAll(testAlias)
+
+	e.GET("/api/v1/url/"+testAlias+"/stats").
+		WithBasicAuth(viewer, "password1").
+		Expect().
+		Status(http.StatusOK)
+
+	// но ничего не меняет
+	e.POST("/api/v1/url").
+		WithJSON(save.Request{URL: "https://viewer-test.com/own"}).
+		WithBasicAuth(viewer, "password1").
+		Expect().
+		Status(http.StatusForbidden)
+
+	e.DELETE("/api/v1/url/"+testAlias).
+		WithBasicAuth(viewer, "password1").
+		Expect().
+		Status(http.StatusForbidden)
+
+	e.DELETE("/api/v1/url/"+testAlias).
+		WithBasicAuth("myuser", "mypass").
+		Expect().
+		Status(http.StatusOK)
+}
+
+func TestURLShortener_Health(t *testing.T) {
+	u := url.URL{
+		Scheme: "http",
+		Host:   host,
+	}
+	e := httpexpect.Default(t, u.String())
+
+	e.GET("/healthz").
+		Expect().
+		Status(http.StatusOK).
+		JSON().Object().Value("status").IsEqual("OK")
+
+	e.GET("/readyz").
+		Expect().
+		Status(http.StatusOK).
+		JSON().Object().Value("status").IsEqual("OK")
+}
+
+func TestURLShortener_AliasAvailable(t *testing.T) {
+	u := url.URL{
+		Scheme: "http",
+		Host:   host,
+	}
+	e := httpexpect.Default(t, u.String())
+
+	testAlias := random.NewRandomString(8)
+
+	e.GET("/api/v1/alias/"+testAlias+"/available").
+		WithBasicAuth("myuser", "mypass").
+		Expect().
+		Status(http.StatusOK).
+		JSON().Object().
+		HasValue("available", true)
+
+	e.POST("/api/v1/url").
+		WithJSON(save.Request{
+			URL:   "https://available-link.com",
+			Alias: testAlias,
+		}).
+		WithBasicAuth("myuser", "mypass").
+		Expect().
+		Status(http.StatusOK)
+
+	res := e.GET("/api/v1/alias/"+testAlias+"/available").
+		WithBasicAuth("myuser", "mypass").
+		Expect().
+		Status(http.StatusOK).
+		JSON().Object()
+	res.HasValue("available", false)
+	res.HasValue("reason", "taken")
+}
+
+func TestURLShortener_SuggestAlias(t *testing.T) {
+	u := url.URL{
+		Scheme: "http",
+		Host:   host,
+	}
+	e := httpexpect.Default(t, u.String())
+
+	site := "suggest" + random.NewString(8, "abcdefghijklmnopqrstuvwxyz")
+	target := "https://www." + site + ".com/page"
+
+	suggestions := e.GET("/api/v1/suggest").
+		WithQuery("url", target).
+		WithQuery("count", 3).
+		WithBasicAuth("myuser", "mypass").
+		Expect().
+		Status(http.StatusOK).
+		JSON().Object().
+		Value("suggestions").Array()
+	suggestions.Length().IsEqual(3)
+	suggestions.Value(0).String().IsEqual(site)
+
+	e.POST("/api/v1/url").
+		WithJSON(save.Request{
+			URL:   target,
+			Alias: site,
+		}).
+		WithBasicAuth("myuser", "mypass").
+		Expect().
+		Status(http.StatusOK)
+
+	// Taken aliases are not suggested.
+	e.GET("/api/v1/suggest").
+		WithQuery("url", target).
+		WithBasicAuth("myuser", "mypass").
+		Expect().
+		Status(http.StatusOK).
+		JSON().Object().
+		Value("suggestions").Array().
+		NotContainsAll(site)
+
+	e.GET("/api/v1/suggest").
+		WithQuery("url", target).
+		Expect().
+		Status(http.StatusUnauthorized)
+}
+
+func TestURLShortener_Expand(t *testing.T) {
+	u := url.URL{
+		Scheme: "http",
+		Host:   host,
+	}
+	e := httpexpect.Default(t, u.String())
+
+	testAlias := random.NewRandomString(8)
+
+	e.POST("/api/v1/url").
+		WithJSON(save.Request{
+			URL:   "https://expand-link.com",
+			Alias: testAlias,
+		}).
+		WithBasicAuth("myuser", "mypass").
+		Expect().
+		Status(http.StatusOK)
+
+	res := e.GET("/api/v1/expand/" + testAlias).
+		Expect().
+		Status(http.StatusOK).
+		JSON().Object()
+	res.HasValue("url", "https://expand-link.com")
+	res.HasValue("link_status", "active")
+	res.Value("created_at").String().NotEmpty()
+
+	e.GET("/api/v1/expand/" + random.NewRandomString(12)).
+		Expect().
+		Status(http.StatusNotFound)
+}
+
+func TestURLShortener_WellKnown(t *testing.T) {
+	u := url.URL{
+		Scheme: "http",
+		Host:   host,
+	}
+	e := httpexpect.Default(t, u.String())
+
+	e.GET("/robots.txt").
+		Expect().
+		Status(http.StatusOK).
+		ContentType("text/plain").
+		Body().Contains("User-agent: *")
+
+	e.GET("/favicon.ico").
+		Expect().
+		Status(http.StatusOK).
+		ContentType("image/x-icon")
+}
+
+func TestURLShortener_GRPC(t *testing.T) {
+	u := url.URL{
+		Scheme: "http",
+		Host:   host,
+	}
+	e := httpexpect.Default(t, u.String())
+
+	token := e.POST("/api/v1/auth/login").
+		WithJSON(login.Request{
+			Username: "myuser",
+			Password: "mypass",
+		}).
+		Expect().
+		Status(http.StatusOK).
+		JSON().
+		Path("$.token").String().NotEmpty().Raw()
+
+	conn, err := grpc.NewClient(grpcHost, grpc.WithTransportCredentials(insecure.NewCredentials()))
+	require.NoError(t, err)
+	defer conn.Close()
+
+	client := shortenerv1.NewURLShortenerClient(conn)
+
+	_, err = client.ListURLs(context.Background(), &shortenerv1.ListURLsRequest{})
+	require.Equal(t, codes.Unauthenticated, status.Code(err))
+
+	ctx := metadata.AppendToOutgoingContext(context.Background(), "authorization", "Bearer "+token)
+
+	target := gofakeit.URL()
+	saved, err := client.SaveURL(ctx, &shortenerv1.SaveURLRequest{Url: target})
+	require.NoError(t, err)
+	require.NotEmpty(t, saved.GetAlias())
+
+	got, err := client.GetURL(ctx, &shortenerv1.GetURLRequest{Alias: saved.GetAlias()})
+	require.NoError(t, err)
+	require.Equal(t, target, got.GetUrl().GetUrl())
+
+	// The link is served over HTTP as well.
+	e.GET("/" + saved.GetAlias()).
+		WithRedirectPolicy(httpexpect.DontFollowRedirects).
+		Expect().
+		Status(http.StatusFound).
+		Header("Location").IsEqual(target)
+
+	_, err = client.DeleteURL(ctx, &shortenerv1.DeleteURLRequest{Alias: saved.GetAlias()})
+	require.NoError(t, err)
+
+	_, err = client.GetURL(ctx, &shortenerv1.GetURLRequest{Alias: saved.GetAlias()})
+	require.Equal(t, codes.NotFound, status.Code(err))
+}
+
+func TestURLShortener_Batch(t *testing.T) {
+	u := url.URL{
+		Scheme: "http",
+		Host:   host,
+	}
+	e := httpexpect.Default(t, u.String())
+
+	alias := random.NewRandomString(10)
+	target := gofakeit.URL()
+
+	res := e.POST("/api/v1/url/batch").
+		WithJSON([]batch.Item{
+			{URL: target, Alias: alias},
+			{URL: gofakeit.URL(), Alias: alias},
+			{URL: gofakeit.URL()},
+			{URL: "not a url"},
+		}).
+		WithBasicAuth("myuser", "mypass").
+		Expect().
+		Status(http.StatusOK).
+		JSON().Object()
+
+	res.Value("saved").IsEqual(2)
+	res.Value("failed").IsEqual(2)
+
+	results := res.Value("results").Array()
+	results.Length().IsEqual(4)
+	results.Value(0).Object().Value("alias").IsEqual(alias)
+	results.Value(1).Object().Value("error").IsEqual("url already exists")
+	generated := results.Value(2).Object().Value("alias").String().NotEmpty().Raw()
+	results.Value(3).Object().ContainsKey("error")
+
+	e.GET("/" + alias).
+		WithRedirectPolicy(httpexpect.DontFollowRedirects).
+		Expect().
+		Status(http.StatusFound).
+		Header("Location").IsEqual(target)
+
+	e.GET("/" + generated).
+		WithRedirectPolicy(httpexpect.DontFollowRedirects).
+		Expect().
+		Status(http.StatusFound)
+}
+
+func TestURLShortener_CSV(t *testing.T) {
+	u := url.URL{
+		Scheme: "http",
+		Host:   host,
+	}
+	e := httpexpect.Default(t, u.String())
+
+	alias := random.NewRandomString(10)
+	target := gofakeit.URL()
+
+	csv := fmt.Sprintf("alias,url\n%s,%s\n%s,%s\n,not a url\n", alias, target, alias, gofakeit.URL())
+
+	res := e.POST("/api/v1/urls/import").
+		WithMultipart().
+		WithFileBytes("file", "urls.csv", []byte(csv)).
+		WithBasicAuth("myuser", "mypass").
+		Expect().
+		Status(http.StatusOK).
+		JSON().Object()
+
+	res.Value("saved").IsEqual(1)
+	res.Value("failed").IsEqual(2)
+
+	results := res.Value("results").Array()
+	results.Length().IsEqual(3)
+	results.Value(1).Object().Value("line").IsEqual(3)
+	results.Value(1).Object().Value("error").IsEqual("url already exists")
+
+	e.GET("/" + alias).
+		WithRedirectPolicy(httpexpect.DontFollowRedirects).
+		Expect().
+		Status(http.StatusFound).
+		Header("Location").IsEqual(target)
+
+	export := e.GET("/api/v1/urls/export").
+		WithBasicAuth("myuser", "mypass").
+		Expect().
+		Status(http.StatusOK)
+
+	export.Header("Content-Type").HasPrefix("text/csv")
+	export.Body().HasPrefix("alias,url,owner,created_at,expires_at\n").
+		Contains(alias + "," + target + ",myuser,")
+}
+
+func TestURLShortener_CSVClicks(t *testing.T) {
+	u := url.URL{
+		Scheme: "http",
+		Host:   host,
+	}
+	e := httpexpect.Default(t, u.String())
+
+	alias := random.NewRandomString(10)
+
+	e.POST("/api/v1/urls/import").
+		WithMultipart().
+		WithFileBytes("file", "urls.csv", []byte(fmt.Sprintf("alias,url,clicks\n%s,%s,1500\n", alias, gofakeit.URL()))).
+		WithBasicAuth("myuser", "mypass").
+		Expect().
+		Status(http.StatusOK).
+		JSON().Object().
+		Value("saved").IsEqual(1)
+
+	e.GET("/"+alias).
+		WithHeader("User-Agent", "Mozilla/5.0 (X11; Linux x86_64) Firefox/125.0").
+		WithRedirectPolicy(httpexpect.DontFollowRedirects).
+		Expect().
+		Status(http.StatusFound)
+
+	stats := e.GET("/api/v1/url/"+alias+"/stats").
+		WithBasicAuth("myuser", "mypass").
+		Expect().
+		Status(http.StatusOK).
+		JSON().Object()
+	stats.Value("total_clicks").IsEqual(1501)
+	stats.Value("daily").Array().Value(0).Object().Value("clicks").IsEqual(1)
+}
+
+// TestURLShortener_FeedDisabled relies on the feed being off in
+// config/local.yaml.
+func TestURLShortener_FeedDisabled(t *testing.T) {
+	u := url.URL{
+		Scheme: "http",
+		Host:   host,
+	}
+	e := httpexpect.Default(t, u.String())
+
+	e.GET("/api/v1/urls/feed").
+		WithBasicAuth("myuser", "mypass").
+		Expect().
+		Status(http.StatusNotFound)
+}
+
+func TestURLShortener_OpenAPI(t *testing.T) {
+	u := url.URL{
+		Scheme: "http",
+		Host:   host,
+	}
+	e := httpexpect.Default(t, u.String())
+
+	doc := e.GET("/openapi.json").
+		Expect().
+		Status(http.StatusOK).
+		JSON().Object()
+
+	doc.Value("openapi").String().HasPrefix("3.")
+	doc.Value("paths").Object().ContainsKey("/api/v1/url").ContainsKey("/{alias}")
+
+	e.GET("/docs").
+		Expect().
+		Status(http.StatusOK).
+		ContentType("text/html").
+		Body().Contains("/openapi.json")
+}
+
+func TestURLShortener_Compression(t *testing.T) {
+	u := url.URL{
+		Scheme: "http",
+		Host:   host,
+	}
+	e := httpexpect.Default(t, u.String())
+
+	e.GET("/openapi.json").
+		WithHeader("Accept-Encoding", "gzip").
+		Expect().
+		Status(http.StatusOK).
+		Header("Content-Encoding").IsEqual("gzip")
+
+	e.GET("/openapi.json").
+		Expect().
+		Status(http.StatusOK).
+		JSON().Object().ContainsKey("openapi")
+}
+
+func TestURLShortener_BodyLimit(t *testing.T) {
+	u := url.URL{
+		Scheme: "http",
+		Host:   host,
+	}
+	e := httpexpect.Default(t, u.String())
+
+	e.POST("/api/v1/url").
+		WithJSON(save.Request{URL: "https://example.com/" + strings.Repeat("a", 2<<20)}).
+		WithBasicAuth("myuser", "mypass").
+		Expect().
+		Status(http.StatusRequestEntityTooLarge).
+		JSON().Object().
+		Value("error").IsEqual("request body too large")
+}
+
+func TestURLShortener_AliasValidation(t *testing.T) {
+	u := url.URL{
+		Scheme: "http",
+		Host:   host,
+	}
+	e := httpexpect.Default(t, u.String())
+
+	cases := []struct {
+		alias string
+		rule  string
+	}{
+		{alias: "metrics", rule: "reserved"},
+		{alias: "Admin", rule: "reserved"},
+		{alias: "ab", rule: "min_length"},
+		{alias: "no spaces", rule: "pattern"},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.alias, func(t *testing.T) {
+			res := e.POST("/api/v1/url").
+				WithJSON(save.Request{URL: gofakeit.URL(), Alias: tc.alias}).
+				WithBasicAuth("myuser", "mypass").
+				Expect().
+				Status(http.StatusOK).
+				JSON().Object()
+
+			res.Value("status").IsEqual("Error")
+			detail := res.Value("details").Array().Value(0).Object()
+			detail.Value("field").IsEqual("alias")
+			detail.Value("rule").IsEqual(tc.rule)
+		})
+	}
+}
+
+func TestURLShortener_SelfReference(t *testing.T) {
+	u := url.URL{
+		Scheme: "http",
+		Host:   host,
+	}
+	e := httpexpect.Default(t, u.String())
+
+	res := e.POST("/api/v1/url").
+		WithJSON(save.Request{URL: u.String() + "/" + random.NewRandomString(10)}).
+		WithBasicAuth("myuser", "mypass").
+		Expect().
+		Status(http.StatusOK).
+		JSON().Object()
+
+	res.Value("status").IsEqual("Error")
+	detail := res.Value("details").Array().Value(0).Object()
+	detail.Value("field").IsEqual("url")
+	detail.Value("rule").IsEqual("self_reference")
+}
+
+func TestURLShortener_ReuseExisting(t *testing.T) {
+	u := url.URL{
+		Scheme: "http",
+		Host:   host,
+	}
+	e := httpexpect.Default(t, u.String())
+
+	target := gofakeit.URL()
+
+	first := e.POST("/api/v1/url").
+		WithJSON(save.Request{URL: target, ReuseExisting: true}).
+		WithBasicAuth("myuser", "mypass").
+		Expect().
+		Status(http.StatusOK).
+		JSON().Object()
+	first.NotContainsKey("reused")
+	alias := first.Value("alias").String().Raw()
+
+	second := e.POST("/api/v1/url").
+		WithJSON(save.Request{URL: target, ReuseExisting: true}).
+		WithBasicAuth("myuser", "mypass").
+		Expect().
+		Status(http.StatusOK).
+		JSON().Object()
+	second.Value("alias").IsEqual(alias)
+	second.Value("reused").IsEqual(true)
+
+	// Without the flag a new link is created as before.
+	e.POST("/api/v1/url").
+		WithJSON(save.Request{URL: target}).
+		WithBasicAuth("myuser", "mypass").
+		Expect().
+		Status(http.StatusOK).
+		JSON().Object().
+		Value("alias").NotEqual(alias)
+}
+
+func TestURLShortener_Campaigns(t *testing.T) {
+	u := url.URL{
+		Scheme: "http",
+		Host:   host,
+	}
+	e := httpexpect.Default(t, u.String())
+
+	id := e.POST("/api/v1/campaigns").
+		WithJSON(map[string]any{
+			"name": "spring " + random.NewRandomString(6),
+			"utm":  map[string]string{"source": "newsletter", "campaign": "spring"},
+		}).
+		WithBasicAuth("myuser", "mypass").
+		Expect().
+		Status(http.StatusCreated).
+		JSON().Object().
+		Value("id").Number().Raw()
+
+	testAlias := random.NewRandomString(10)
+	e.POST("/api/v1/url").
+		WithJSON(save.Request{URL: "https://example.com/sale", Alias: testAlias, Campaign: int64(id)}).
+		WithBasicAuth("myuser", "mypass").
+		Expect().
+		Status(http.StatusOK).
+		JSON().Object().
+		HasValue("campaign", id)
+
+	e.GET("/"+testAlias).
+		WithHeader("User-Agent", "Mozilla/5.0 (X11; Linux x86_64) Firefox/125.0").
+		WithRedirectPolicy(httpexpect.DontFollowRedirects).
+		Expect().
+		Status(http.StatusFound).
+		Header("Location").IsEqual("https://example.com/sale?utm_campaign=spring&utm_source=newsletter")
+
+	path := fmt.Sprintf("/api/v1/campaigns/%d", int64(id))
+	stats := e.GET(path+"/stats").
+		WithBasicAuth("myuser", "mypass").
+		Expect().
+		Status(http.StatusOK).
+		JSON().Object()
+	stats.Value("total_clicks").IsEqual(1)
+	stats.Value("links").Array().Value(0).Object().HasValue("alias", testAlias)
+
+	e.DELETE(path).
+		WithBasicAuth("myuser", "mypass").
+		Expect().
+		Status(http.StatusOK)
+
+	// The link outlives its campaign.
+	e.GET(path).
+		WithBasicAuth("myuser", "mypass").
+		Expect().
+		Status(http.StatusNotFound)
+	_, err := api.GetRedirect("http://" + host + "/" + testAlias)
+	require.NoError(t, err)
+}
+
+// TestURLShortener_CORS relies on http://localhost:3000 being allowed in
+// config/local.yaml.
+func TestURLShortener_CORS(t *testing.T) {
+	u := url.URL{
+		Scheme: "http",
+		Host:   host,
+	}
+	e := httpexpect.Default(t, u.String())
+
+	preflight := e.OPTIONS("/api/v1/url").
+		WithHeader("Origin", "http://localhost:3000").
+		WithHeader("Access-Control-Request-Method", http.MethodPost).
+		WithHeader("Access-Control-Request-Headers", "authorization,content-type").
+		Expect().
+		Status(http.StatusOK)
+	preflight.Header("Access-Control-Allow-Origin").IsEqual("http://localhost:3000")
+	preflight.Header("Access-Control-Allow-Methods").IsEqual(http.MethodPost)
+
+	e.POST("/api/v1/url").
+		WithHeader("Origin", "http://localhost:3000").
+		WithJSON(save.Request{URL: gofakeit.URL()}).
+		WithBasicAuth("myuser", "mypass").
+		Expect().
+		Status(http.StatusOK).
+		Header("Access-Control-Allow-Origin").IsEqual("http://localhost:3000")
+
+	e.GET("/healthz").
+		WithHeader("Origin", "https://evil.example.com").
+		Expect().
+		Status(http.StatusOK).
+		Header("Access-Control-Allow-Origin").IsEmpty()
+}
+
+func TestURLShortener_UnversionedAPI(t *testing.T) {
+	u := url.URL{
+		Scheme: "http",
+		Host:   host,
+	}
+	e := httpexpect.Default(t, u.String())
+
+	testAlias := random.NewRandomString(10)
+
+	res := e.POST("/url").
+		WithJSON(save.Request{URL: gofakeit.URL(), Alias: testAlias}).
+		WithBasicAuth("myuser", "mypass").
+		Expect().
+		Status(http.StatusOK)
+	res.Header("Deprecation").NotEmpty()
+	res.Header("Link").IsEqual(`</api/v1/url>; rel="successor-version"`)
+
+	e.GET("/api/v1/url/"+testAlias+"/stats").
+		WithBasicAuth("myuser", "mypass").
+		Expect().
+		Status(http.StatusOK).
+		Header("Deprecation").IsEmpty()
+}
+
+func TestURLShortener_PlainText(t *testing.T) {
+	u := url.URL{
+		Scheme: "http",
+		Host:   host,
+	}
+	e := httpexpect.Default(t, u.String())
+
+	testAlias := random.NewRandomString(10)
+
+	e.POST("/api/v1/url").
+		WithFormField("url", gofakeit.URL()).
+		WithFormField("alias", testAlias).
+		WithHeader("Accept", "text/plain").
+		WithBasicAuth("myuser", "mypass").
+		Expect().
+		Status(http.StatusOK).
+		Body().HasSuffix("/" + testAlias + "\n")
+
+	e.POST("/api/v1/url").
+		WithQuery("format", "txt").
+		WithFormField("url", "not a url").
+		WithBasicAuth("myuser", "mypass").
+		Expect().
+		Status(http.StatusBadRequest).
+		Body().IsEqual("field URL is not valid\n")
+}
+
+func TestURLShortener_GetShorten(t *testing.T) {
+	u := url.URL{
+		Scheme: "http",
+		Host:   host,
+	}
+	e := httpexpect.Default(t, u.String())
+
+	testURL := gofakeit.URL()
+	testAlias := random.NewRandomString(10)
+
+	e.GET("/api/v1/shorten").
+		WithQuery("url", testURL).
+		WithQuery("alias", testAlias).
+		WithBasicAuth("myuser", "mypass").
+		Expect().
+		Status(http.StatusOK).
+		JSON().Object().
+		HasValue("alias", testAlias)
+
+	redirectURL, err := api.GetRedirect("http://" + host + "/" + testAlias)
+	require.NoError(t, err)
+	require.Equal(t, testURL, redirectURL)
+
+	e.GET("/api/v1/shorten").
+		WithQuery("url", testURL).
+		Expect().
+		Status(http.StatusUnauthorized)
+
+	e.GET("/shorten").
+		WithQuery("url", testURL).
+		WithBasicAuth("myuser", "mypass").
+		Expect().
+		Status(http.StatusOK).
+		JSON().Object().
+		HasValue("error", "Url not found")
+}